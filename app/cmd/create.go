@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"os"
+	"path"
+	"regexp"
+	zap_logger "tg_todo_bot/kernel/logger"
+	"time"
+)
+
+var createDir string
+
+var migrationNameRegexp = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// createCmd represents the create command
+var createCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create empty 'up' and 'down' migrations, the binary has to be rebuilt to embed them",
+	Example: "  tg_todo_bot migrate create add_tasks_color\n" +
+		"  tg_todo_bot migrate create add_tasks_color --dir migrations/sqlite",
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		logger := zap_logger.InitLogger()
+		logger.Infof("Start execute '%s %s' command", cmd.Parent().Name(), cmd.Name())
+
+		name := args[0]
+		if !migrationNameRegexp.MatchString(name) {
+			logger.Panicw("the name of a migration may have only lowercase letters, digits and '_'", "name", name)
+		}
+
+		//Версия в том же формате, что у существующих миграций
+		version := time.Now().UTC().Format("20060102150405")
+		for _, direction := range []string{"up", "down"} {
+			filePath := path.Join(createDir, fmt.Sprintf("%s_%s.%s.sql", version, name, direction))
+
+			file, err := os.OpenFile(filePath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+			if err != nil {
+				logger.Panicw("os.OpenFile(filePath)", "error", err.Error(), "filePath", filePath)
+			}
+			file.Close()
+
+			fmt.Fprintln(cmd.OutOrStdout(), filePath)
+		}
+	},
+}
+
+func init() {
+	createCmd.Flags().StringVar(&createDir, "dir", path.Join(".", "migrations"), "directory of the migrations, migrations/sqlite for the sqlite driver")
+	migrateCmd.AddCommand(createCmd)
+}
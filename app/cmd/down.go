@@ -1,19 +1,25 @@
 package cmd
 
 import (
-	"path"
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
 	"tg_todo_bot/config"
-	"tg_todo_bot/kernel/db"
 	zap_logger "tg_todo_bot/kernel/logger"
-	"tg_todo_bot/kernel/migrate"
 
+	gomigrate "github.com/golang-migrate/migrate/v4"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
+var downAll bool
+
 // downCmd represents the down command
 var downCmd = &cobra.Command{
 	Use:   "down",
 	Short: "Apply all 'down' migrations",
+	Long:  "Apply all 'down' migrations. It drops all data, so it asks for confirmation unless --all is given",
 	Run: func(cmd *cobra.Command, args []string) {
 		logger := zap_logger.InitLogger()
 		logger.Infof("Start execute '%s %s' command", cmd.Parent().Name(), cmd.Name())
@@ -23,32 +29,40 @@ var downCmd = &cobra.Command{
 			logger.Panicw("config.GetMigrateConfig()", "error", err.Error())
 		}
 
-		pg := db.NewPG(
-			conf.Database.Host,
-			conf.Database.Port,
-			conf.Database.Database,
-			conf.Database.User,
-			conf.Database.Password,
-		)
-
-		pgUrl := pg.GetConnUrl()
-		migrationDir := path.Join(".", "migrations")
-		m, err := migrate.NewPostgresMigrateInstance(pgUrl, migrationDir)
-		if err != nil {
-			logger.Panicw("migrate.NewPostgresMigrateInstance(pgUrl, migrationDir)",
-				"error", err.Error(), "pgUrl", pgUrl, "migrationDir", migrationDir,
-			)
+		if !downAll && !confirm(cmd, "All migrations will be rolled back and all data will be lost. Continue?") {
+			logger.Info("Rolling back is cancelled")
+			return
 		}
 
+		m := newMigrateInstance(logger, conf)
+
 		err = m.Down()
 		if err != nil {
-			if err.Error() != "no change" {
+			if !errors.Is(err, gomigrate.ErrNoChange) {
 				logger.Panicw("m.Down()", "error", err.Error())
 			}
 		}
 	},
 }
 
+// confirm (cmd, question) -> true if the user answered "y" or "yes", false for anything else and for no input
+func confirm(cmd *cobra.Command, question string) bool {
+	fmt.Fprintf(cmd.OutOrStdout(), "%s [y/N]: ", question)
+
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		return false
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
+
 func init() {
+	downCmd.Flags().BoolVar(&downAll, "all", false, "roll back all migrations without confirmation")
 	migrateCmd.AddCommand(downCmd)
 }
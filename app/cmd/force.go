@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"strconv"
+	"tg_todo_bot/config"
+	zap_logger "tg_todo_bot/kernel/logger"
+)
+
+// forceCmd represents the force command
+var forceCmd = &cobra.Command{
+	Use:   "force <version>",
+	Short: "Set the schema version and clear the dirty flag without applying migrations",
+	Long: "Set the schema version and clear the dirty flag without applying migrations. " +
+		"Use it after a failed migration is fixed by hand, -1 means no migrations are applied",
+	Example: "  tg_todo_bot migrate force 20261019121800\n  tg_todo_bot migrate force -- -1",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		logger := zap_logger.InitLogger()
+		logger.Infof("Start execute '%s %s' command", cmd.Parent().Name(), cmd.Name())
+
+		version, err := strconv.Atoi(args[0])
+		if err != nil {
+			logger.Panicw("strconv.Atoi(version)", "error", err.Error(), "version", args[0])
+		}
+
+		conf, err := config.GetConfig()
+		if err != nil {
+			logger.Panicw("config.GetConfig()", "error", err.Error())
+		}
+
+		m := newMigrateInstance(logger, conf)
+
+		err = m.Force(version)
+		if err != nil {
+			logger.Panicw("m.Force(version)", "error", err.Error(), "version", version)
+		}
+	},
+}
+
+func init() {
+	migrateCmd.AddCommand(forceCmd)
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	gomigrate "github.com/golang-migrate/migrate/v4"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"strconv"
+	"tg_todo_bot/config"
+	zap_logger "tg_todo_bot/kernel/logger"
+)
+
+// gotoCmd represents the goto command
+var gotoCmd = &cobra.Command{
+	Use:   "goto <version>",
+	Short: "Apply 'up' or 'down' migrations to reach the version",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		logger := zap_logger.InitLogger()
+		logger.Infof("Start execute '%s %s' command", cmd.Parent().Name(), cmd.Name())
+
+		version, err := strconv.ParseUint(args[0], 10, 64)
+		if err != nil {
+			logger.Panicw("strconv.ParseUint(version)", "error", err.Error(), "version", args[0])
+		}
+
+		conf, err := config.GetConfig()
+		if err != nil {
+			logger.Panicw("config.GetConfig()", "error", err.Error())
+		}
+
+		m := newMigrateInstance(logger, conf)
+
+		err = m.Migrate(uint(version))
+		if err != nil {
+			if !errors.Is(err, gomigrate.ErrNoChange) {
+				logger.Panicw("m.Migrate(version)", "error", err.Error(), "version", version)
+			}
+		}
+	},
+}
+
+func init() {
+	migrateCmd.AddCommand(gotoCmd)
+}
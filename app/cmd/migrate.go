@@ -1,7 +1,14 @@
 package cmd
 
 import (
+	gomigrate "github.com/golang-migrate/migrate/v4"
 	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+	"io/fs"
+	"tg_todo_bot/config"
+	"tg_todo_bot/kernel/db"
+	"tg_todo_bot/kernel/migrate"
+	"tg_todo_bot/migrations"
 )
 
 // migrateCmd represents the migrate command
@@ -16,3 +23,46 @@ var migrateCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(migrateCmd)
 }
+
+// newMigrateInstance (logger, conf) -> the embedded migrations of the database of DB_DRIVER, panics if it can't be opened
+func newMigrateInstance(logger *zap.SugaredLogger, conf config.Config) *gomigrate.Migrate {
+	if conf.Database.Driver == config.DriverSQLite {
+		sqlite := db.NewSQLite(conf.Database.Path)
+		dbInstance, err := sqlite.Open()
+		if err != nil {
+			logger.Panicw("sqlite.Open()", "error", err.Error())
+		}
+
+		m, err := migrate.NewSQLiteMigrateInstance(dbInstance)
+		if err != nil {
+			logger.Panicw("migrate.NewSQLiteMigrateInstance(dbInstance)", "error", err.Error())
+		}
+
+		return m
+	}
+
+	pg := db.NewPG(
+		conf.Database.Host,
+		conf.Database.Port,
+		conf.Database.Database,
+		conf.Database.User,
+		conf.Database.Password,
+	)
+
+	pgUrl := pg.GetConnUrl()
+	m, err := migrate.NewPostgresMigrateInstance(pgUrl)
+	if err != nil {
+		logger.Panicw("migrate.NewPostgresMigrateInstance(pgUrl)", "error", err.Error(), "pgUrl", pgUrl)
+	}
+
+	return m
+}
+
+// migrationsFS (conf) -> the embedded migrations of DB_DRIVER
+func migrationsFS(conf config.Config) fs.FS {
+	if conf.Database.Driver == config.DriverSQLite {
+		return migrations.SQLite()
+	}
+
+	return migrations.Postgres()
+}
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"context"
+	"tg_todo_bot/config"
+	"tg_todo_bot/kernel/db"
+	"tg_todo_bot/kernel/migrate"
+	repositories "tg_todo_bot/src/repositories/db"
+	"tg_todo_bot/src/repositories/sqlite"
+	"tg_todo_bot/src/services/broadcasts"
+	"tg_todo_bot/src/services/caldav"
+	"tg_todo_bot/src/services/escalations"
+	"tg_todo_bot/src/services/filters"
+	"tg_todo_bot/src/services/notifications"
+	"tg_todo_bot/src/services/outbox"
+	"tg_todo_bot/src/services/ratelimits"
+	"tg_todo_bot/src/services/settings"
+	"tg_todo_bot/src/services/tasks"
+	"tg_todo_bot/src/services/tokens"
+	"tg_todo_bot/src/services/users"
+	"tg_todo_bot/src/services/webhooks"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// migrationsLockKey -> the key of the advisory lock under which replicas check and apply migrations on start
+const migrationsLockKey int64 = 4_206_190_100
+
+// repositoriesSet -> the repositories of DB_DRIVER, a repository shared by several services satisfies all of them
+type repositoriesSet struct {
+	users interface {
+		users.UsersRepositoryI
+	}
+	tasks interface {
+		tasks.TasksRepositoryI
+		escalations.TasksRepositoryI
+	}
+	notifications interface {
+		tasks.NotificationsRepositoryI
+		notifications.NotificationsRepositoryI
+	}
+	taskDependencies   tasks.TaskDependenciesRepositoryI
+	taskTags           tasks.TaskTagsRepositoryI
+	savedFilters       filters.SavedFiltersRepositoryI
+	escalationPolicies escalations.EscalationPoliciesRepositoryI
+	taskEscalations    escalations.TaskEscalationsRepositoryI
+	userSettings       interface {
+		settings.UserSettingsRepositoryI
+		escalations.UserSettingsRepositoryI
+	}
+	apiTokens         tokens.ApiTokensRepositoryI
+	webhooks          webhooks.WebhooksRepositoryI
+	webhookDeliveries webhooks.WebhookDeliveriesRepositoryI
+	calDAVObjects     caldav.CalDAVObjectsRepositoryI
+	broadcasts        broadcasts.BroadcastsRepositoryI
+	rateLimits        ratelimits.RateLimitsRepositoryI
+	outbox            outbox.OutboxRepositoryI
+	unitOfWork        tasks.UnitOfWorkI
+
+	// close -> closes the connections to the database
+	close func()
+}
+
+// newRepositories (logger, conf) -> the repositories of DB_DRIVER on a checked schema, panics if the database can't be opened
+func newRepositories(logger *zap.SugaredLogger, conf config.Config) repositoriesSet {
+	if conf.Database.Driver == config.DriverSQLite {
+		return newSQLiteRepositories(logger, conf)
+	}
+
+	return newPostgresRepositories(logger, conf)
+}
+
+func newPostgresRepositories(logger *zap.SugaredLogger, conf config.Config) repositoriesSet {
+	pg := db.NewPG(
+		conf.Database.Host,
+		conf.Database.Port,
+		conf.Database.Database,
+		conf.Database.User,
+		conf.Database.Password,
+	)
+	pgPool, err := pg.OpenPool()
+	if err != nil {
+		logger.Panicw("pg.OpenPool()", "error", err.Error())
+	}
+
+	//Реплики, запущенные одновременно, проверяют и применяют миграции по очереди
+	checkMigrations(logger, conf, func(fn func() error) error {
+		return db.WithAdvisoryLock(context.Background(), pgPool, migrationsLockKey, fn)
+	})
+
+	timeout := conf.Database.QueryTimeout
+	return repositoriesSet{
+		users:              repositories.NewUsersRepository(logger, pgPool, timeout),
+		tasks:              repositories.NewTasksRepository(logger, pgPool, timeout),
+		notifications:      repositories.NewNotificationsRepository(logger, pgPool, timeout),
+		taskDependencies:   repositories.NewTaskDependenciesRepository(logger, pgPool, timeout),
+		taskTags:           repositories.NewTaskTagsRepository(logger, pgPool, timeout),
+		savedFilters:       repositories.NewSavedFiltersRepository(logger, pgPool, timeout),
+		escalationPolicies: repositories.NewEscalationPoliciesRepository(logger, pgPool, timeout),
+		taskEscalations:    repositories.NewTaskEscalationsRepository(logger, pgPool, timeout),
+		userSettings:       repositories.NewUserSettingsRepository(logger, pgPool, timeout),
+		apiTokens:          repositories.NewApiTokensRepository(logger, pgPool, timeout),
+		webhooks:           repositories.NewWebhooksRepository(logger, pgPool, timeout),
+		webhookDeliveries:  repositories.NewWebhookDeliveriesRepository(logger, pgPool, timeout),
+		calDAVObjects:      repositories.NewCalDAVObjectsRepository(logger, pgPool, timeout),
+		broadcasts:         repositories.NewBroadcastsRepository(logger, pgPool, timeout),
+		rateLimits:         repositories.NewRateLimitsRepository(logger, pgPool, timeout),
+		outbox:             repositories.NewOutboxRepository(logger, pgPool, timeout),
+		unitOfWork:         repositories.NewUnitOfWork(logger, pgPool, timeout),
+		close:              pgPool.Close,
+	}
+}
+
+func newSQLiteRepositories(logger *zap.SugaredLogger, conf config.Config) repositoriesSet {
+	dbInstance, err := db.NewSQLite(conf.Database.Path).Open()
+	if err != nil {
+		logger.Panicw("sqlite.Open()", "error", err.Error())
+	}
+
+	//Файл базы открывает один процесс бота, ждать некого
+	checkMigrations(logger, conf, func(fn func() error) error {
+		return fn()
+	})
+
+	timeout := conf.Database.QueryTimeout
+	return repositoriesSet{
+		users:              sqlite.NewUsersRepository(logger, dbInstance, timeout),
+		tasks:              sqlite.NewTasksRepository(logger, dbInstance, timeout),
+		notifications:      sqlite.NewNotificationsRepository(logger, dbInstance, timeout),
+		taskDependencies:   sqlite.NewTaskDependenciesRepository(logger, dbInstance, timeout),
+		taskTags:           sqlite.NewTaskTagsRepository(logger, dbInstance, timeout),
+		savedFilters:       sqlite.NewSavedFiltersRepository(logger, dbInstance, timeout),
+		escalationPolicies: sqlite.NewEscalationPoliciesRepository(logger, dbInstance, timeout),
+		taskEscalations:    sqlite.NewTaskEscalationsRepository(logger, dbInstance, timeout),
+		userSettings:       sqlite.NewUserSettingsRepository(logger, dbInstance, timeout),
+		apiTokens:          sqlite.NewApiTokensRepository(logger, dbInstance, timeout),
+		webhooks:           sqlite.NewWebhooksRepository(logger, dbInstance, timeout),
+		webhookDeliveries:  sqlite.NewWebhookDeliveriesRepository(logger, dbInstance, timeout),
+		calDAVObjects:      sqlite.NewCalDAVObjectsRepository(logger, dbInstance, timeout),
+		broadcasts:         sqlite.NewBroadcastsRepository(logger, dbInstance, timeout),
+		rateLimits:         sqlite.NewRateLimitsRepository(logger, dbInstance, timeout),
+		outbox:             sqlite.NewOutboxRepository(logger, dbInstance, timeout),
+		unitOfWork:         sqlite.NewUnitOfWork(logger, dbInstance, timeout),
+		close: func() {
+			dbInstance.Close()
+		},
+	}
+}
+
+// checkMigrations (logger, conf, withLock) -> panics unless the schema is at the last embedded migration,
+// pending ones are applied first with --migrate. The check runs under withLock
+func checkMigrations(logger *zap.SugaredLogger, conf config.Config, withLock func(fn func() error) error) {
+	m := newMigrateInstance(logger, conf)
+	defer m.Close()
+
+	err := withLock(func() error {
+		return migrate.Check(m, migrationsFS(conf), runMigrate)
+	})
+	if errors.Is(err, migrate.ErrPending) {
+		logger.Panicw("apply the migrations with 'migrate up' or start with --migrate", "error", err.Error())
+	}
+	if err != nil {
+		logger.Panicw("migrate.Check(m, migrationsFS(conf), runMigrate)", "error", err.Error())
+	}
+}
@@ -1,20 +1,182 @@
 package cmd
 
 import (
-	"fmt"
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"tg_todo_bot/config"
+	zap_logger "tg_todo_bot/kernel/logger"
+	"tg_todo_bot/src/api"
+	"tg_todo_bot/src/bot"
+	"tg_todo_bot/src/scheduler"
+	"tg_todo_bot/src/sendqueue"
+	"tg_todo_bot/src/services/broadcasts"
+	"tg_todo_bot/src/services/caldav"
+	"tg_todo_bot/src/services/escalations"
+	"tg_todo_bot/src/services/filters"
+	"tg_todo_bot/src/services/notifications"
+	"tg_todo_bot/src/services/outbox"
+	"tg_todo_bot/src/services/ratelimits"
+	ratelimits_types "tg_todo_bot/src/services/ratelimits/types"
+	"tg_todo_bot/src/services/settings"
+	"tg_todo_bot/src/services/tasks"
+	"tg_todo_bot/src/services/tokens"
+	"tg_todo_bot/src/services/users"
+	"tg_todo_bot/src/services/webhooks"
+	"time"
 
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/spf13/cobra"
 )
 
+// webhooksTimeout -> a receiver which doesn't answer in time gets the delivery again later
+const webhooksTimeout = 10 * time.Second
+
+var runMigrate bool
+
 // runCmd represents the run command
 var runCmd = &cobra.Command{
 	Use:   "run",
 	Short: "Run telegram bot",
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("run called")
+		logger := zap_logger.InitLogger()
+		logger.Infof("Start execute '%s' command", cmd.Name())
+
+		conf, err := config.GetConfig()
+		if err != nil {
+			logger.Panicw("config.GetConfig()", "error", err.Error())
+		}
+
+		repos := newRepositories(logger, conf)
+		defer repos.close()
+
+		webhooksService := webhooks.NewService(
+			logger,
+			repos.webhooks,
+			repos.webhookDeliveries,
+			webhooks.NewHTTPClient(webhooksTimeout),
+		)
+		rateLimitsService := ratelimits.NewService(logger, repos.rateLimits, map[string]ratelimits_types.Limit{
+			ratelimits_types.BucketCommands: {Burst: conf.RateLimits.CommandsBurst, PerMinute: conf.RateLimits.CommandsPerMinute},
+			ratelimits_types.BucketTasks:    {Burst: conf.RateLimits.TasksBurst, PerMinute: conf.RateLimits.TasksPerMinute},
+		})
+		usersService := users.NewService(logger, repos.users)
+		tasksService := tasks.NewService(
+			logger,
+			repos.tasks,
+			repos.notifications,
+			repos.taskDependencies,
+			repos.taskTags,
+			repos.unitOfWork,
+			webhooksService,
+			rateLimitsService,
+		)
+		notificationsService := notifications.NewService(logger, repos.notifications, webhooksService)
+		filtersService := filters.NewService(logger, repos.savedFilters)
+		escalationsService := escalations.NewService(
+			logger,
+			repos.tasks,
+			repos.escalationPolicies,
+			repos.taskEscalations,
+			repos.userSettings,
+		)
+		settingsService := settings.NewService(logger, repos.userSettings)
+		tokensService := tokens.NewService(logger, repos.apiTokens)
+		calDAVService := caldav.NewService(logger, repos.calDAVObjects)
+		broadcastsService := broadcasts.NewService(logger, repos.broadcasts)
+		outboxService := outbox.NewService(logger, repos.outbox)
+
+		botAPI, err := tgbotapi.NewBotAPI(conf.Telegram.BotToken)
+		if err != nil {
+			logger.Panicw("tgbotapi.NewBotAPI(token)", "error", err.Error())
+		}
+
+		sendQueue := sendqueue.NewQueue(logger, botAPI, sendqueue.DefaultLimits)
+		telegramBot := bot.NewBot(
+			logger,
+			botAPI,
+			sendQueue,
+			usersService,
+			tasksService,
+			filtersService,
+			escalationsService,
+			settingsService,
+			tokensService,
+			webhooksService,
+			broadcastsService,
+			rateLimitsService,
+			conf.Api.PublicURL,
+			conf.Telegram.AdminID,
+		)
+		remindersJob := scheduler.NewRemindersJob(logger, tasksService, notificationsService)
+		outboxJob := scheduler.NewOutboxJob(logger, usersService, tasksService, outboxService, telegramBot)
+		escalationsJob := scheduler.NewEscalationsJob(logger, usersService, escalationsService, telegramBot)
+		digestJob := scheduler.NewDigestJob(logger, usersService, settingsService, telegramBot)
+		webhooksJob := scheduler.NewWebhooksJob(logger, webhooksService)
+		accountDeletionsJob := scheduler.NewAccountDeletionsJob(logger, usersService, telegramBot)
+		broadcastsJob := scheduler.NewBroadcastsJob(logger, usersService, broadcastsService, telegramBot)
+		apiServer := api.NewServer(
+			logger,
+			conf.Api.Port,
+			usersService,
+			tasksService,
+			notificationsService,
+			tokensService,
+			settingsService,
+			calDAVService,
+		)
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		var wg sync.WaitGroup
+		wg.Add(9)
+		go func() {
+			defer wg.Done()
+			remindersJob.Run(ctx)
+		}()
+		go func() {
+			defer wg.Done()
+			outboxJob.Run(ctx)
+		}()
+		go func() {
+			defer wg.Done()
+			escalationsJob.Run(ctx)
+		}()
+		go func() {
+			defer wg.Done()
+			digestJob.Run(ctx)
+		}()
+		go func() {
+			defer wg.Done()
+			webhooksJob.Run(ctx)
+		}()
+		go func() {
+			defer wg.Done()
+			accountDeletionsJob.Run(ctx)
+		}()
+		go func() {
+			defer wg.Done()
+			broadcastsJob.Run(ctx)
+		}()
+		go func() {
+			defer wg.Done()
+			telegramBot.Run(ctx)
+		}()
+		go func() {
+			defer wg.Done()
+			apiServer.Run(ctx)
+		}()
+
+		logger.Infof("Bot @%s started, API listens on port %d", botAPI.Self.UserName, conf.Api.Port)
+		wg.Wait()
+		logger.Info("Bot stopped")
 	},
 }
 
 func init() {
+	runCmd.Flags().BoolVar(&runMigrate, "migrate", false, "apply pending migrations before start")
 	rootCmd.AddCommand(runCmd)
 }
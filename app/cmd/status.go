@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	gomigrate "github.com/golang-migrate/migrate/v4"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"tg_todo_bot/config"
+	zap_logger "tg_todo_bot/kernel/logger"
+	"tg_todo_bot/kernel/migrate"
+)
+
+// statusCmd represents the status command
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the schema version and which migrations are applied",
+	Run: func(cmd *cobra.Command, args []string) {
+		logger := zap_logger.InitLogger()
+		logger.Infof("Start execute '%s %s' command", cmd.Parent().Name(), cmd.Name())
+
+		conf, err := config.GetConfig()
+		if err != nil {
+			logger.Panicw("config.GetConfig()", "error", err.Error())
+		}
+
+		list, err := migrate.List(migrationsFS(conf))
+		if err != nil {
+			logger.Panicw("migrate.List(migrationsFS(conf))", "error", err.Error())
+		}
+
+		m := newMigrateInstance(logger, conf)
+
+		version, dirty, err := m.Version()
+		applied := true
+		switch {
+		case errors.Is(err, gomigrate.ErrNilVersion):
+			applied = false
+			fmt.Fprintln(cmd.OutOrStdout(), "Schema version: none")
+		case err != nil:
+			logger.Panicw("m.Version()", "error", err.Error())
+		case dirty:
+			fmt.Fprintf(cmd.OutOrStdout(), "Schema version: %d (dirty, fix the schema and use 'migrate force')\n", version)
+		default:
+			fmt.Fprintf(cmd.OutOrStdout(), "Schema version: %d\n", version)
+		}
+
+		known := false
+		for _, migration := range list {
+			state := "pending"
+			if applied && migration.Version <= version {
+				state = "applied"
+			}
+			known = known || migration.Version == version
+			fmt.Fprintf(cmd.OutOrStdout(), "  %-8s %d_%s\n", state, migration.Version, migration.Name)
+		}
+
+		if applied && !known {
+			fmt.Fprintln(cmd.OutOrStdout(), "The schema version is unknown to this build, it's newer or was forced")
+		}
+	},
+}
+
+func init() {
+	migrateCmd.AddCommand(statusCmd)
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	gomigrate "github.com/golang-migrate/migrate/v4"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"strconv"
+	"tg_todo_bot/config"
+	zap_logger "tg_todo_bot/kernel/logger"
+)
+
+// stepsCmd represents the steps command
+var stepsCmd = &cobra.Command{
+	Use:     "steps <n>",
+	Short:   "Apply n 'up' migrations, or -n 'down' ones for a negative n",
+	Example: "  tg_todo_bot migrate steps 2\n  tg_todo_bot migrate steps -- -1",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		logger := zap_logger.InitLogger()
+		logger.Infof("Start execute '%s %s' command", cmd.Parent().Name(), cmd.Name())
+
+		steps, err := strconv.Atoi(args[0])
+		if err != nil {
+			logger.Panicw("strconv.Atoi(steps)", "error", err.Error(), "steps", args[0])
+		}
+
+		conf, err := config.GetConfig()
+		if err != nil {
+			logger.Panicw("config.GetConfig()", "error", err.Error())
+		}
+
+		m := newMigrateInstance(logger, conf)
+
+		err = m.Steps(steps)
+		if err != nil {
+			if !errors.Is(err, gomigrate.ErrNoChange) {
+				logger.Panicw("m.Steps(steps)", "error", err.Error(), "steps", steps)
+			}
+		}
+	},
+}
+
+func init() {
+	migrateCmd.AddCommand(stepsCmd)
+}
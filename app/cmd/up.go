@@ -1,12 +1,11 @@
 package cmd
 
 import (
+	gomigrate "github.com/golang-migrate/migrate/v4"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
-	"path"
 	"tg_todo_bot/config"
-	"tg_todo_bot/kernel/db"
 	zap_logger "tg_todo_bot/kernel/logger"
-	"tg_todo_bot/kernel/migrate"
 )
 
 // upCmd represents the up command
@@ -22,26 +21,11 @@ var upCmd = &cobra.Command{
 			logger.Panicw("config.GetMigrateConfig()", "error", err.Error())
 		}
 
-		pg := db.NewPG(
-			conf.Database.Host,
-			conf.Database.Port,
-			conf.Database.Database,
-			conf.Database.User,
-			conf.Database.Password,
-		)
-
-		pgUrl := pg.GetConnUrl()
-		migrationDir := path.Join(".", "migrations")
-		m, err := migrate.NewPostgresMigrateInstance(pgUrl, migrationDir)
-		if err != nil {
-			logger.Panicw("migrate.NewPostgresMigrateInstance(pgUrl, migrationDir)",
-				"error", err.Error(), "pgUrl", pgUrl, "migrationDir", migrationDir,
-			)
-		}
+		m := newMigrateInstance(logger, conf)
 
 		err = m.Up()
 		if err != nil {
-			if err.Error() != "no change" {
+			if !errors.Is(err, gomigrate.ErrNoChange) {
 				logger.Panicw("m.Up()", "error", err.Error())
 			}
 		}
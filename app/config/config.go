@@ -3,11 +3,14 @@ package config
 import (
 	"github.com/caarlos0/env/v6"
 	"github.com/pkg/errors"
+	"time"
 )
 
 type Config struct {
-	Telegram Telegram `envPrefix:"TELEGRAM_"`
-	Database Database `envPrefix:"DB_"`
+	Telegram   Telegram   `envPrefix:"TELEGRAM_"`
+	Database   Database   `envPrefix:"DB_"`
+	Api        Api        `envPrefix:"API_"`
+	RateLimits RateLimits `envPrefix:"RATE_LIMIT_"`
 }
 
 type Telegram struct {
@@ -15,18 +18,62 @@ type Telegram struct {
 	AdminID  int64  `env:"ADMIN_ID,notEmpty"`
 }
 
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// Database -> HOST, PORT, DATABASE, USER and PASSWORD are required for the postgres driver,
+// PATH is the file of the sqlite driver
 type Database struct {
-	Host     string `env:"HOST,notEmpty"`
-	Port     int    `env:"PORT,notEmpty"`
-	Database string `env:"DATABASE,notEmpty"`
-	User     string `env:"USER,notEmpty"`
-	Password string `env:"PASSWORD,notEmpty"`
+	Driver       string        `env:"DRIVER" envDefault:"postgres"`
+	Host         string        `env:"HOST"`
+	Port         int           `env:"PORT"`
+	Database     string        `env:"DATABASE"`
+	User         string        `env:"USER"`
+	Password     string        `env:"PASSWORD"`
+	Path         string        `env:"PATH" envDefault:"tg_todo_bot.db"`
+	QueryTimeout time.Duration `env:"QUERY_TIMEOUT" envDefault:"5s"` //for every query of repositories, 0 turns it off
+}
+
+type Api struct {
+	Port      int    `env:"PORT" envDefault:"8085"`
+	PublicURL string `env:"PUBLIC_URL" envDefault:"http://localhost:8085"` //how users reach the API, used in links sent by the bot
+}
+
+// RateLimits -> per user token buckets: BURST requests at once, then PER_MINUTE requests a minute, 0 turns a limit off
+type RateLimits struct {
+	CommandsBurst     int     `env:"COMMANDS_BURST" envDefault:"20"`
+	CommandsPerMinute float64 `env:"COMMANDS_PER_MINUTE" envDefault:"30"`
+	TasksBurst        int     `env:"TASKS_BURST" envDefault:"30"`
+	TasksPerMinute    float64 `env:"TASKS_PER_MINUTE" envDefault:"10"`
 }
 
 func GetConfig() (Config, error) {
 	config := Config{}
 
 	err := env.Parse(&config, env.Options{})
+	if err != nil {
+		return config, errors.Wrap(err, "env.Parse")
+	}
+
+	return config, errors.Wrap(config.Database.validate(), "config.Database.validate")
+}
+
+func (database Database) validate() error {
+	switch database.Driver {
+	case DriverPostgres:
+		if database.Host == "" || database.Port == 0 || database.Database == "" ||
+			database.User == "" || database.Password == "" {
+			return errors.New(`DB_HOST, DB_PORT, DB_DATABASE, DB_USER and DB_PASSWORD are required for the "postgres" driver`)
+		}
+	case DriverSQLite:
+		if database.Path == "" {
+			return errors.New(`DB_PATH is required for the "sqlite" driver`)
+		}
+	default:
+		return errors.Errorf(`unknown DB_DRIVER "%s", expected "%s" or "%s"`, database.Driver, DriverPostgres, DriverSQLite)
+	}
 
-	return config, errors.Wrap(err, "env.Parse")
+	return nil
 }
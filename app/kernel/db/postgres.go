@@ -56,3 +56,31 @@ func (d *PG) OpenPool() (*pgxpool.Pool, error) {
 
 	return pgPool, nil
 }
+
+// WithAdvisoryLock (ctx, pgPool, key, fn) -> runs fn holding the session advisory lock of key, other sessions wait
+// for it. The lock is released with the session if the process dies
+func WithAdvisoryLock(ctx context.Context, pgPool *pgxpool.Pool, key int64, fn func() error) error {
+	conn, err := pgPool.Acquire(ctx)
+	if err != nil {
+		return errors.Wrap(err, "pgPool.Acquire(ctx)")
+	}
+	defer conn.Release()
+
+	_, err = conn.Exec(ctx, "SELECT pg_advisory_lock($1)", key)
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("pg_advisory_lock(%d)", key))
+	}
+
+	fnErr := fn()
+
+	_, err = conn.Exec(context.Background(), "SELECT pg_advisory_unlock($1)", key)
+	if err != nil {
+		//Соединение с неснятой блокировкой не возвращается в пул
+		conn.Conn().Close(context.Background())
+		if fnErr == nil {
+			return errors.Wrap(err, fmt.Sprintf("pg_advisory_unlock(%d)", key))
+		}
+	}
+
+	return fnErr
+}
@@ -0,0 +1,74 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"github.com/pkg/errors"
+	"modernc.org/sqlite"
+	"time"
+)
+
+// Прагмы применяются к каждому соединению, SQLite не хранит их в файле базы
+var sqlitePragmas = []string{
+	"PRAGMA foreign_keys = ON",
+	"PRAGMA busy_timeout = 5000",
+}
+
+type SQLite struct {
+	path string
+}
+
+func NewSQLite(path string) *SQLite {
+	return &SQLite{
+		path: path,
+	}
+}
+
+func (d *SQLite) GetConnUrl() string {
+	return fmt.Sprintf("sqlite://%s", d.path)
+}
+
+// Open -> the database of the file, created if it doesn't exist. Foreign keys are on for every connection.
+// There is only one connection: SQLite has one writer anyway, and ":memory:" gives every connection its own database
+func (d *SQLite) Open() (*sql.DB, error) {
+	dbInstance := sql.OpenDB(sqliteConnector{path: d.path, driver: &sqlite.Driver{}})
+	dbInstance.SetMaxOpenConns(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	err := dbInstance.PingContext(ctx)
+	if err != nil {
+		dbInstance.Close()
+		return nil, errors.Wrap(err, fmt.Sprintf(`dbInstance.PingContext(path = '%s')`, d.path))
+	}
+
+	return dbInstance, nil
+}
+
+type sqliteConnector struct {
+	path   string
+	driver *sqlite.Driver
+}
+
+func (c sqliteConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.driver.Open(c.path)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pragma := range sqlitePragmas {
+		_, err = conn.(driver.ExecerContext).ExecContext(ctx, pragma, nil)
+		if err != nil {
+			conn.Close()
+			return nil, errors.Wrap(err, pragma)
+		}
+	}
+
+	return conn, nil
+}
+
+func (c sqliteConnector) Driver() driver.Driver {
+	return c.driver
+}
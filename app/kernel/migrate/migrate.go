@@ -4,13 +4,18 @@ import (
 	"database/sql"
 	"fmt"
 	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
 	_ "github.com/lib/pq"
 	"github.com/pkg/errors"
+	"io/fs"
+	"os"
+	"tg_todo_bot/migrations"
 )
 
-func NewPostgresMigrateInstance(pgUrl, migrationsDir string) (*migrate.Migrate, error) {
+func NewPostgresMigrateInstance(pgUrl string) (*migrate.Migrate, error) {
 	dbInstance, err := sql.Open("postgres", pgUrl)
 	if err != nil {
 		return nil, errors.Wrap(err, fmt.Sprintf("sql.Open('postgres', %s)", pgUrl))
@@ -21,15 +26,113 @@ func NewPostgresMigrateInstance(pgUrl, migrationsDir string) (*migrate.Migrate,
 		return nil, errors.Wrap(err, "postgres.WithInstance(dbInstance, &postgres.Config{})")
 	}
 
-	migrationsPathUrl := fmt.Sprintf("file://%s", migrationsDir)
-	m, err := migrate.NewWithDatabaseInstance(
-		migrationsPathUrl,
-		"postgres",
-		driver,
-	)
+	return newMigrateInstance(migrations.Postgres(), "postgres", driver)
+}
+
+// NewSQLiteMigrateInstance (dbInstance) -> migrations of the SQLite schema are kept apart
+// from the PostgreSQL ones, dbInstance is opened by db.SQLite
+func NewSQLiteMigrateInstance(dbInstance *sql.DB) (*migrate.Migrate, error) {
+	driver, err := sqlite.WithInstance(dbInstance, &sqlite.Config{})
+	if err != nil {
+		return nil, errors.Wrap(err, "sqlite.WithInstance(dbInstance, &sqlite.Config{})")
+	}
+
+	return newMigrateInstance(migrations.SQLite(), "sqlite", driver)
+}
+
+// newMigrateInstance (migrationsFS, databaseName, driver) -> the migrations embedded into the binary
+// applied through driver
+func newMigrateInstance(migrationsFS fs.FS, databaseName string, driver database.Driver) (*migrate.Migrate, error) {
+	sourceDriver, err := iofs.New(migrationsFS, ".")
+	if err != nil {
+		return nil, errors.Wrap(err, "iofs.New(migrationsFS, '.')")
+	}
+
+	m, err := migrate.NewWithInstance("iofs", sourceDriver, databaseName, driver)
 	if err != nil {
-		return nil, errors.Wrap(err, fmt.Sprintf("migrate.NewWithDatabaseInstance(%s, 'postgres', driver)", migrationsPathUrl))
+		return nil, errors.Wrap(err, fmt.Sprintf("migrate.NewWithInstance('iofs', sourceDriver, '%s', driver)", databaseName))
 	}
 
 	return m, nil
 }
+
+// Migration -> one migration of the embedded ones, Name is the part of the file name after the version
+type Migration struct {
+	Version uint
+	Name    string
+}
+
+// List (migrationsFS) -> all migrations in ascending order of versions
+func List(migrationsFS fs.FS) ([]Migration, error) {
+	sourceDriver, err := iofs.New(migrationsFS, ".")
+	if err != nil {
+		return nil, errors.Wrap(err, "iofs.New(migrationsFS, '.')")
+	}
+	defer sourceDriver.Close()
+
+	list := []Migration{}
+	version, err := sourceDriver.First()
+	for err == nil {
+		reader, name, readErr := sourceDriver.ReadUp(version)
+		if readErr != nil {
+			return nil, errors.Wrap(readErr, fmt.Sprintf("sourceDriver.ReadUp(%d)", version))
+		}
+		reader.Close()
+
+		list = append(list, Migration{Version: version, Name: name})
+		version, err = sourceDriver.Next(version)
+	}
+	//Следующей миграции нет
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, errors.Wrap(err, "sourceDriver.Next()")
+	}
+
+	return list, nil
+}
+
+var (
+	ErrDirty       = errors.New("the schema is dirty, a migration failed and has to be fixed by hand")
+	ErrNewerSchema = errors.New("the schema version is unknown to this build, it's newer than the embedded migrations")
+	ErrPending     = errors.New("the schema has pending migrations")
+)
+
+// Check (m, migrationsFS, apply) -> nil when the schema version is the last migration of migrationsFS.
+// Pending migrations are applied if apply is true, otherwise ErrPending. A dirty or an unknown version
+// is never touched: ErrDirty or ErrNewerSchema
+func Check(m *migrate.Migrate, migrationsFS fs.FS, apply bool) error {
+	list, err := List(migrationsFS)
+	if err != nil {
+		return errors.Wrap(err, "List(migrationsFS)")
+	}
+
+	//Версия 0 - ни одна миграция не применена
+	var latest uint
+	known := map[uint]bool{0: true}
+	for _, migration := range list {
+		latest = migration.Version
+		known[migration.Version] = true
+	}
+
+	version, dirty, err := m.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return errors.Wrap(err, "m.Version()")
+	}
+
+	switch {
+	case dirty:
+		return errors.Wrap(ErrDirty, fmt.Sprintf("version %d", version))
+	case !known[version]:
+		return errors.Wrap(ErrNewerSchema, fmt.Sprintf("version %d, the last migration %d", version, latest))
+	case version == latest:
+		return nil
+	case !apply:
+		return errors.Wrap(ErrPending, fmt.Sprintf("version %d, the last migration %d", version, latest))
+	}
+
+	err = m.Up()
+	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return errors.Wrap(err, "m.Up()")
+	}
+
+	return nil
+}
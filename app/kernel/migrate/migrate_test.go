@@ -0,0 +1,65 @@
+package migrate
+
+import (
+	"github.com/pkg/errors"
+	"testing"
+	"tg_todo_bot/kernel/db"
+	"tg_todo_bot/migrations"
+)
+
+func TestCheck(t *testing.T) {
+	dbInstance, err := db.NewSQLite(":memory:").Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbInstance.Close()
+
+	m, err := NewSQLiteMigrateInstance(dbInstance)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := List(migrations.SQLite())
+	if err != nil || len(list) == 0 {
+		t.Fatalf("List: got %v, %v, expected the SQLite migrations", list, err)
+	}
+	latest := list[len(list)-1].Version
+
+	err = Check(m, migrations.SQLite(), false)
+	if !errors.Is(err, ErrPending) {
+		t.Fatalf("Check of an empty schema: got %v, expected ErrPending", err)
+	}
+
+	err = Check(m, migrations.SQLite(), true)
+	if err != nil {
+		t.Fatalf("Check with apply: got %v", err)
+	}
+	version, dirty, err := m.Version()
+	if err != nil || dirty || version != latest {
+		t.Fatalf("Version: got %d, %v, %v, expected %d", version, dirty, err, latest)
+	}
+
+	err = Check(m, migrations.SQLite(), false)
+	if err != nil {
+		t.Fatalf("Check of an up to date schema: got %v", err)
+	}
+
+	err = m.Force(int(latest + 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = Check(m, migrations.SQLite(), true)
+	if !errors.Is(err, ErrNewerSchema) {
+		t.Fatalf("Check of a newer schema: got %v, expected ErrNewerSchema", err)
+	}
+
+	//Так схему оставляет прерванная миграция
+	_, err = dbInstance.Exec("UPDATE schema_migrations SET version = ?, dirty = 1", latest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = Check(m, migrations.SQLite(), true)
+	if !errors.Is(err, ErrDirty) {
+		t.Fatalf("Check of a dirty schema: got %v, expected ErrDirty", err)
+	}
+}
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS task_dependencies_index_blocked_by_task_id;
+
+DROP TABLE IF EXISTS task_dependencies;
\ No newline at end of file
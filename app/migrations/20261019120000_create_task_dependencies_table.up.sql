@@ -0,0 +1,12 @@
+CREATE TABLE task_dependencies
+(
+    task_id            INTEGER   NOT NULL,
+    blocked_by_task_id INTEGER   NOT NULL,
+    created_at         TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (task_id, blocked_by_task_id),
+    CONSTRAINT fk_task FOREIGN KEY (task_id) REFERENCES tasks (id) ON DELETE CASCADE,
+    CONSTRAINT fk_blocked_by_task FOREIGN KEY (blocked_by_task_id) REFERENCES tasks (id) ON DELETE CASCADE,
+    CONSTRAINT check_not_self_dependency CHECK (task_id <> blocked_by_task_id)
+);
+
+CREATE INDEX task_dependencies_index_blocked_by_task_id ON task_dependencies (blocked_by_task_id);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS tasks_index_search_vector;
+
+ALTER TABLE tasks
+    DROP COLUMN IF EXISTS search_vector;
\ No newline at end of file
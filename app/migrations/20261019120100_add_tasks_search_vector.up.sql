@@ -0,0 +1,9 @@
+ALTER TABLE tasks
+    ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+        setweight(to_tsvector('russian', title), 'A') ||
+        setweight(to_tsvector('english', title), 'A') ||
+        setweight(to_tsvector('russian', description), 'B') ||
+        setweight(to_tsvector('english', description), 'B')
+    ) STORED;
+
+CREATE INDEX tasks_index_search_vector ON tasks USING GIN (search_vector);
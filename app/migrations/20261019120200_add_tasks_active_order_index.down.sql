@@ -0,0 +1 @@
+DROP INDEX IF EXISTS tasks_index_active_order;
\ No newline at end of file
@@ -0,0 +1,2 @@
+CREATE INDEX tasks_index_active_order ON tasks (user_id, COALESCE(datetime, 'infinity'::TIMESTAMP), title, id)
+    WHERE done = false;
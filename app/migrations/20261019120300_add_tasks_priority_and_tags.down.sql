@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS task_tags_index_tag;
+
+DROP TABLE IF EXISTS task_tags;
+
+ALTER TABLE tasks
+    DROP COLUMN IF EXISTS priority;
\ No newline at end of file
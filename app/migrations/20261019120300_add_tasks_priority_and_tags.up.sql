@@ -0,0 +1,12 @@
+ALTER TABLE tasks
+    ADD COLUMN priority SMALLINT NOT NULL DEFAULT 0;
+
+CREATE TABLE task_tags
+(
+    task_id INTEGER     NOT NULL,
+    tag     VARCHAR(64) NOT NULL,
+    PRIMARY KEY (task_id, tag),
+    CONSTRAINT fk_task FOREIGN KEY (task_id) REFERENCES tasks (id) ON DELETE CASCADE
+);
+
+CREATE INDEX task_tags_index_tag ON task_tags (tag);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS saved_filters;
\ No newline at end of file
@@ -0,0 +1,10 @@
+CREATE TABLE saved_filters
+(
+    id         SERIAL PRIMARY KEY,
+    user_id    INTEGER      NOT NULL,
+    name       VARCHAR(64)  NOT NULL,
+    query      VARCHAR(512) NOT NULL,
+    created_at TIMESTAMP    NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT saved_filters_unique_user_id_name UNIQUE (user_id, name)
+);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS task_escalations;
+
+DROP TABLE IF EXISTS escalation_policies;
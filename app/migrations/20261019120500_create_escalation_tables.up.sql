@@ -0,0 +1,26 @@
+CREATE TABLE escalation_policies
+(
+    id              SERIAL PRIMARY KEY,
+    user_id         INTEGER   NOT NULL,
+    task_id         INTEGER,
+    overdue_after   BIGINT    NOT NULL,
+    repeat_interval BIGINT    NOT NULL,
+    max_reminders   SMALLINT  NOT NULL,
+    created_at      TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT fk_task FOREIGN KEY (task_id) REFERENCES tasks (id) ON DELETE CASCADE
+);
+
+-- Одна политика пользователя по умолчанию и не больше одной на задачу
+CREATE UNIQUE INDEX escalation_policies_unique_user_id ON escalation_policies (user_id) WHERE task_id IS NULL;
+
+CREATE UNIQUE INDEX escalation_policies_unique_task_id ON escalation_policies (task_id);
+
+CREATE TABLE task_escalations
+(
+    task_id      INTEGER PRIMARY KEY,
+    deadline     TIMESTAMP NOT NULL,
+    level        SMALLINT  NOT NULL,
+    escalated_at TIMESTAMP NOT NULL,
+    CONSTRAINT fk_task FOREIGN KEY (task_id) REFERENCES tasks (id) ON DELETE CASCADE
+);
@@ -0,0 +1,16 @@
+CREATE TABLE user_settings
+(
+    user_id         INTEGER PRIMARY KEY,
+    language        VARCHAR(8)  NOT NULL DEFAULT 'ru',
+    timezone        VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    reminder_offset BIGINT      NOT NULL DEFAULT 0,
+    repeat_interval BIGINT      NOT NULL DEFAULT 3600000000000,
+    list_sort       VARCHAR(16) NOT NULL DEFAULT 'due',
+    digest_time     SMALLINT,
+    last_digest_on  DATE,
+    updated_at      TIMESTAMP   NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT check_digest_time CHECK (digest_time >= 0 AND digest_time < 1440)
+);
+
+CREATE INDEX user_settings_index_digest_time ON user_settings (digest_time) WHERE digest_time IS NOT NULL;
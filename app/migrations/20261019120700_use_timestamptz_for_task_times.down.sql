@@ -0,0 +1,14 @@
+ALTER TABLE task_escalations
+    ALTER COLUMN deadline TYPE TIMESTAMP USING deadline AT TIME ZONE 'UTC',
+    ALTER COLUMN escalated_at TYPE TIMESTAMP USING escalated_at AT TIME ZONE 'UTC';
+
+ALTER TABLE notifications
+    ALTER COLUMN notify_at TYPE TIMESTAMP USING notify_at AT TIME ZONE 'UTC';
+
+DROP INDEX IF EXISTS tasks_index_active_order;
+
+ALTER TABLE tasks
+    ALTER COLUMN datetime TYPE TIMESTAMP USING datetime AT TIME ZONE 'UTC';
+
+CREATE INDEX tasks_index_active_order ON tasks (user_id, COALESCE(datetime, 'infinity'::TIMESTAMP), title, id)
+    WHERE done = false;
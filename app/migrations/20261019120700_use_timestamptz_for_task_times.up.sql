@@ -0,0 +1,15 @@
+-- До настроек часового пояса бот работал на сервере в UTC, поэтому существующие значения считаются UTC
+DROP INDEX IF EXISTS tasks_index_active_order;
+
+ALTER TABLE tasks
+    ALTER COLUMN datetime TYPE TIMESTAMPTZ USING datetime AT TIME ZONE 'UTC';
+
+CREATE INDEX tasks_index_active_order ON tasks (user_id, COALESCE(datetime, 'infinity'::TIMESTAMPTZ), title, id)
+    WHERE done = false;
+
+ALTER TABLE notifications
+    ALTER COLUMN notify_at TYPE TIMESTAMPTZ USING notify_at AT TIME ZONE 'UTC';
+
+ALTER TABLE task_escalations
+    ALTER COLUMN deadline TYPE TIMESTAMPTZ USING deadline AT TIME ZONE 'UTC',
+    ALTER COLUMN escalated_at TYPE TIMESTAMPTZ USING escalated_at AT TIME ZONE 'UTC';
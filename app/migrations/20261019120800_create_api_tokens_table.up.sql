@@ -0,0 +1,10 @@
+CREATE TABLE api_tokens
+(
+    id         SERIAL PRIMARY KEY,
+    user_id    INTEGER     NOT NULL,
+    token_hash CHAR(64)    NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT api_tokens_unique_user_id UNIQUE (user_id),
+    CONSTRAINT api_tokens_unique_token_hash UNIQUE (token_hash)
+);
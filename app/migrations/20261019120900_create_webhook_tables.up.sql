@@ -0,0 +1,28 @@
+CREATE TABLE webhooks
+(
+    id         SERIAL PRIMARY KEY,
+    user_id    INTEGER     NOT NULL,
+    url        TEXT        NOT NULL,
+    secret     CHAR(64)    NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX webhooks_index_user_id ON webhooks (user_id);
+
+CREATE TABLE webhook_deliveries
+(
+    id              SERIAL PRIMARY KEY,
+    webhook_id      INTEGER     NOT NULL,
+    event_type      VARCHAR(32) NOT NULL,
+    payload         JSONB       NOT NULL,
+    attempts        SMALLINT    NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ,
+    last_error      TEXT        NOT NULL DEFAULT '',
+    delivered_at    TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_webhook FOREIGN KEY (webhook_id) REFERENCES webhooks (id) ON DELETE CASCADE
+);
+
+-- next_attempt_at равен NULL у доставленных и у исчерпавших все попытки
+CREATE INDEX webhook_deliveries_index_next_attempt_at ON webhook_deliveries (next_attempt_at) WHERE next_attempt_at IS NOT NULL;
@@ -0,0 +1,10 @@
+DELETE FROM api_tokens WHERE scope != 'api';
+
+ALTER TABLE api_tokens
+    DROP CONSTRAINT api_tokens_unique_user_id_scope;
+
+ALTER TABLE api_tokens
+    ADD CONSTRAINT api_tokens_unique_user_id UNIQUE (user_id);
+
+ALTER TABLE api_tokens
+    DROP COLUMN scope;
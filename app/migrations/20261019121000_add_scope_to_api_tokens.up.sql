@@ -0,0 +1,9 @@
+-- У пользователя свой токен на каждую область: REST API, ссылка на календарь
+ALTER TABLE api_tokens
+    ADD COLUMN scope VARCHAR(16) NOT NULL DEFAULT 'api';
+
+ALTER TABLE api_tokens
+    DROP CONSTRAINT api_tokens_unique_user_id;
+
+ALTER TABLE api_tokens
+    ADD CONSTRAINT api_tokens_unique_user_id_scope UNIQUE (user_id, scope);
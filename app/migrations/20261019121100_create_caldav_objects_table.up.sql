@@ -0,0 +1,11 @@
+-- Имена и UID, которые задачам дали CalDAV-клиенты. Задачи из бота доступны как task-ID.ics без записи здесь
+CREATE TABLE caldav_objects
+(
+    task_id INTEGER PRIMARY KEY,
+    user_id INTEGER      NOT NULL,
+    name    VARCHAR(255) NOT NULL,
+    uid     TEXT         NOT NULL,
+    CONSTRAINT fk_task FOREIGN KEY (task_id) REFERENCES tasks (id) ON DELETE CASCADE,
+    CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT caldav_objects_unique_user_id_name UNIQUE (user_id, name)
+);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS users_index_deletion_requested_at;
+
+ALTER TABLE users
+    DROP COLUMN deletion_requested_at;
@@ -0,0 +1,6 @@
+-- Время запроса на удаление аккаунта через /deleteme, аккаунт удаляется по истечении срока ожидания
+ALTER TABLE users
+    ADD COLUMN deletion_requested_at TIMESTAMPTZ;
+
+CREATE INDEX users_index_deletion_requested_at ON users (deletion_requested_at)
+    WHERE deletion_requested_at IS NOT NULL;
@@ -0,0 +1,3 @@
+ALTER TABLE users
+    DROP COLUMN banned_at,
+    DROP COLUMN last_seen_at;
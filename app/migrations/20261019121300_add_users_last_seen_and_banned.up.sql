@@ -0,0 +1,4 @@
+-- Время последнего обращения к боту для статистики активных пользователей, обновляется не чаще раза в 10 минут
+ALTER TABLE users
+    ADD COLUMN last_seen_at TIMESTAMPTZ,
+    ADD COLUMN banned_at    TIMESTAMPTZ;
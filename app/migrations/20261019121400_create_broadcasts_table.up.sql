@@ -0,0 +1,14 @@
+-- Рассылки администратора. last_user_id — последний обработанный пользователь, с него рассылка продолжается после перезапуска
+CREATE TABLE broadcasts
+(
+    id           SERIAL PRIMARY KEY,
+    text         TEXT        NOT NULL,
+    status       VARCHAR(16) NOT NULL DEFAULT 'draft',
+    last_user_id INTEGER     NOT NULL DEFAULT 0,
+    sent         INTEGER     NOT NULL DEFAULT 0,
+    failed       INTEGER     NOT NULL DEFAULT 0,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at  TIMESTAMPTZ
+);
+
+CREATE INDEX broadcasts_index_status ON broadcasts (status) WHERE status = 'running';
@@ -0,0 +1,3 @@
+-- Причина бана видна админу в /user, пользователю она не показывается
+ALTER TABLE users
+    ADD COLUMN ban_reason TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,11 @@
+-- Token bucket на пользователя: tokens пополняются со временем от updated_at, каждый запрос забирает один токен.
+-- Счётчики в базе, поэтому лимит общий для всех реплик бота
+CREATE TABLE rate_limits
+(
+    user_id    INTEGER          NOT NULL,
+    bucket     VARCHAR(32)      NOT NULL,
+    tokens     DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMPTZ      NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    PRIMARY KEY (user_id, bucket)
+);
@@ -0,0 +1,4 @@
+-- Пользователь заблокировал бота (Телеграм ответил 403): напоминания, эскалации и дайджесты ему не отправляются,
+-- пока он снова не напишет боту
+ALTER TABLE users
+    ADD COLUMN bot_blocked_at TIMESTAMPTZ;
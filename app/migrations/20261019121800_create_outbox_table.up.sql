@@ -0,0 +1,20 @@
+-- Сообщения бота, которые пишутся в одной транзакции с изменением данных и отправляются отдельным воркером.
+-- dedup_key не даёт поставить одно и то же сообщение дважды, например одно срабатывание напоминания
+CREATE TABLE outbox
+(
+    id              SERIAL PRIMARY KEY,
+    user_id         INTEGER      NOT NULL,
+    kind            VARCHAR(32)  NOT NULL,
+    dedup_key       VARCHAR(128) NOT NULL,
+    payload         JSONB        NOT NULL,
+    attempts        SMALLINT     NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ,
+    last_error      TEXT         NOT NULL DEFAULT '',
+    sent_at         TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT outbox_unique_dedup_key UNIQUE (dedup_key)
+);
+
+-- next_attempt_at равен NULL у отправленных и у исчерпавших все попытки
+CREATE INDEX outbox_index_next_attempt_at ON outbox (next_attempt_at) WHERE next_attempt_at IS NOT NULL;
@@ -0,0 +1,71 @@
+-- Откат невозможен, если какой-то ID уже больше 2^31 - 1
+ALTER TABLE outbox
+    ALTER COLUMN id TYPE INTEGER,
+    ALTER COLUMN user_id TYPE INTEGER;
+ALTER SEQUENCE outbox_id_seq AS INTEGER;
+
+ALTER TABLE rate_limits
+    ALTER COLUMN user_id TYPE INTEGER;
+
+ALTER TABLE broadcasts
+    ALTER COLUMN id TYPE INTEGER,
+    ALTER COLUMN last_user_id TYPE INTEGER;
+ALTER SEQUENCE broadcasts_id_seq AS INTEGER;
+
+ALTER TABLE caldav_objects
+    ALTER COLUMN task_id TYPE INTEGER,
+    ALTER COLUMN user_id TYPE INTEGER;
+
+ALTER TABLE webhook_deliveries
+    ALTER COLUMN id TYPE INTEGER,
+    ALTER COLUMN webhook_id TYPE INTEGER;
+ALTER SEQUENCE webhook_deliveries_id_seq AS INTEGER;
+
+ALTER TABLE webhooks
+    ALTER COLUMN id TYPE INTEGER,
+    ALTER COLUMN user_id TYPE INTEGER;
+ALTER SEQUENCE webhooks_id_seq AS INTEGER;
+
+ALTER TABLE api_tokens
+    ALTER COLUMN id TYPE INTEGER,
+    ALTER COLUMN user_id TYPE INTEGER;
+ALTER SEQUENCE api_tokens_id_seq AS INTEGER;
+
+ALTER TABLE user_settings
+    ALTER COLUMN user_id TYPE INTEGER;
+
+ALTER TABLE task_escalations
+    ALTER COLUMN task_id TYPE INTEGER;
+
+ALTER TABLE escalation_policies
+    ALTER COLUMN id TYPE INTEGER,
+    ALTER COLUMN user_id TYPE INTEGER,
+    ALTER COLUMN task_id TYPE INTEGER;
+ALTER SEQUENCE escalation_policies_id_seq AS INTEGER;
+
+ALTER TABLE saved_filters
+    ALTER COLUMN id TYPE INTEGER,
+    ALTER COLUMN user_id TYPE INTEGER;
+ALTER SEQUENCE saved_filters_id_seq AS INTEGER;
+
+ALTER TABLE task_tags
+    ALTER COLUMN task_id TYPE INTEGER;
+
+ALTER TABLE task_dependencies
+    ALTER COLUMN task_id TYPE INTEGER,
+    ALTER COLUMN blocked_by_task_id TYPE INTEGER;
+
+ALTER TABLE notifications
+    ALTER COLUMN id TYPE INTEGER,
+    ALTER COLUMN task_id TYPE INTEGER;
+ALTER SEQUENCE notifications_id_seq AS INTEGER;
+
+ALTER TABLE tasks
+    ALTER COLUMN id TYPE INTEGER,
+    ALTER COLUMN user_id TYPE INTEGER;
+ALTER SEQUENCE tasks_id_seq AS INTEGER;
+
+ALTER TABLE users
+    ALTER COLUMN id TYPE INTEGER,
+    ALTER COLUMN telegram_id TYPE INTEGER;
+ALTER SEQUENCE users_id_seq AS INTEGER;
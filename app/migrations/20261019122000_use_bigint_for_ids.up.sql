@@ -0,0 +1,71 @@
+-- Telegram ID уже больше 2^31, а ключи SERIAL упираются в тот же предел, поэтому все ID и ссылки на них - BIGINT
+ALTER TABLE users
+    ALTER COLUMN id TYPE BIGINT,
+    ALTER COLUMN telegram_id TYPE BIGINT;
+ALTER SEQUENCE users_id_seq AS BIGINT;
+
+ALTER TABLE tasks
+    ALTER COLUMN id TYPE BIGINT,
+    ALTER COLUMN user_id TYPE BIGINT;
+ALTER SEQUENCE tasks_id_seq AS BIGINT;
+
+ALTER TABLE notifications
+    ALTER COLUMN id TYPE BIGINT,
+    ALTER COLUMN task_id TYPE BIGINT;
+ALTER SEQUENCE notifications_id_seq AS BIGINT;
+
+ALTER TABLE task_dependencies
+    ALTER COLUMN task_id TYPE BIGINT,
+    ALTER COLUMN blocked_by_task_id TYPE BIGINT;
+
+ALTER TABLE task_tags
+    ALTER COLUMN task_id TYPE BIGINT;
+
+ALTER TABLE saved_filters
+    ALTER COLUMN id TYPE BIGINT,
+    ALTER COLUMN user_id TYPE BIGINT;
+ALTER SEQUENCE saved_filters_id_seq AS BIGINT;
+
+ALTER TABLE escalation_policies
+    ALTER COLUMN id TYPE BIGINT,
+    ALTER COLUMN user_id TYPE BIGINT,
+    ALTER COLUMN task_id TYPE BIGINT;
+ALTER SEQUENCE escalation_policies_id_seq AS BIGINT;
+
+ALTER TABLE task_escalations
+    ALTER COLUMN task_id TYPE BIGINT;
+
+ALTER TABLE user_settings
+    ALTER COLUMN user_id TYPE BIGINT;
+
+ALTER TABLE api_tokens
+    ALTER COLUMN id TYPE BIGINT,
+    ALTER COLUMN user_id TYPE BIGINT;
+ALTER SEQUENCE api_tokens_id_seq AS BIGINT;
+
+ALTER TABLE webhooks
+    ALTER COLUMN id TYPE BIGINT,
+    ALTER COLUMN user_id TYPE BIGINT;
+ALTER SEQUENCE webhooks_id_seq AS BIGINT;
+
+ALTER TABLE webhook_deliveries
+    ALTER COLUMN id TYPE BIGINT,
+    ALTER COLUMN webhook_id TYPE BIGINT;
+ALTER SEQUENCE webhook_deliveries_id_seq AS BIGINT;
+
+ALTER TABLE caldav_objects
+    ALTER COLUMN task_id TYPE BIGINT,
+    ALTER COLUMN user_id TYPE BIGINT;
+
+ALTER TABLE broadcasts
+    ALTER COLUMN id TYPE BIGINT,
+    ALTER COLUMN last_user_id TYPE BIGINT;
+ALTER SEQUENCE broadcasts_id_seq AS BIGINT;
+
+ALTER TABLE rate_limits
+    ALTER COLUMN user_id TYPE BIGINT;
+
+ALTER TABLE outbox
+    ALTER COLUMN id TYPE BIGINT,
+    ALTER COLUMN user_id TYPE BIGINT;
+ALTER SEQUENCE outbox_id_seq AS BIGINT;
@@ -0,0 +1,4 @@
+-- Реплика, которая отправляет рассылку, держит её до locked_until и продлевает срок с каждым пользователем.
+-- Другие реплики берут рассылку, только когда срок истёк
+ALTER TABLE broadcasts
+    ADD COLUMN locked_until TIMESTAMPTZ;
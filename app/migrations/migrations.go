@@ -0,0 +1,29 @@
+// Package migrations -> the migrations are built into the binary, so it doesn't depend on the working directory
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed *.sql
+var postgres embed.FS
+
+//go:embed sqlite/*.sql
+var sqlite embed.FS
+
+// Postgres -> migrations of the PostgreSQL schema
+func Postgres() fs.FS {
+	return postgres
+}
+
+// SQLite -> migrations of the SQLite schema, kept apart from the PostgreSQL ones
+func SQLite() fs.FS {
+	migrations, err := fs.Sub(sqlite, "sqlite")
+	if err != nil {
+		//Каталог встроен при сборке, ошибки быть не может
+		panic(err)
+	}
+
+	return migrations
+}
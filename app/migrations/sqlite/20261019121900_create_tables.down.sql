@@ -0,0 +1,15 @@
+DROP TABLE IF EXISTS outbox;
+
+DROP TABLE IF EXISTS user_settings;
+
+DROP TABLE IF EXISTS task_tags;
+
+DROP TABLE IF EXISTS task_dependencies;
+
+DROP TABLE IF EXISTS notifications;
+
+DROP TABLE IF EXISTS tasks_fts;
+
+DROP TABLE IF EXISTS tasks;
+
+DROP TABLE IF EXISTS users;
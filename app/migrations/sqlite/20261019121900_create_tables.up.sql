@@ -0,0 +1,122 @@
+-- Схема для SQLite повторяет таблицы PostgreSQL, с которыми работают репозитории пользователей, задач и напоминаний.
+-- Время хранится целым числом микросекунд от начала эпохи Unix в UTC, чтобы сравниваться и сортироваться как число
+CREATE TABLE users
+(
+    id                    INTEGER PRIMARY KEY AUTOINCREMENT,
+    telegram_id           INTEGER NOT NULL UNIQUE,
+    created_at            INTEGER NOT NULL,
+    deletion_requested_at INTEGER,
+    last_seen_at          INTEGER,
+    banned_at             INTEGER,
+    ban_reason            TEXT    NOT NULL DEFAULT '',
+    bot_blocked_at        INTEGER
+);
+
+CREATE INDEX users_index_deletion_requested_at ON users (deletion_requested_at)
+    WHERE deletion_requested_at IS NOT NULL;
+
+CREATE TABLE tasks
+(
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    title       TEXT    NOT NULL,
+    description TEXT    NOT NULL,
+    datetime    INTEGER,
+    done        INTEGER NOT NULL DEFAULT 0,
+    priority    INTEGER NOT NULL DEFAULT 0,
+    user_id     INTEGER NOT NULL,
+    created_at  INTEGER NOT NULL,
+    CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX tasks_index_datetime ON tasks (datetime);
+
+-- 9223372036854775807 - наибольшее INTEGER, задачи без datetime идут после всех дат
+CREATE INDEX tasks_index_active_order ON tasks (user_id, COALESCE(datetime, 9223372036854775807), title, id)
+    WHERE done = 0;
+
+-- Полнотекстовый поиск без стемминга, индекс обновляется триггерами
+CREATE VIRTUAL TABLE tasks_fts USING fts5(title, description, content = 'tasks', content_rowid = 'id', tokenize = 'unicode61');
+
+CREATE TRIGGER tasks_fts_insert AFTER INSERT ON tasks
+BEGIN
+    INSERT INTO tasks_fts (rowid, title, description) VALUES (new.id, new.title, new.description);
+END;
+
+CREATE TRIGGER tasks_fts_delete AFTER DELETE ON tasks
+BEGIN
+    INSERT INTO tasks_fts (tasks_fts, rowid, title, description) VALUES ('delete', old.id, old.title, old.description);
+END;
+
+CREATE TRIGGER tasks_fts_update AFTER UPDATE OF title, description ON tasks
+BEGIN
+    INSERT INTO tasks_fts (tasks_fts, rowid, title, description) VALUES ('delete', old.id, old.title, old.description);
+    INSERT INTO tasks_fts (rowid, title, description) VALUES (new.id, new.title, new.description);
+END;
+
+CREATE TABLE notifications
+(
+    id              INTEGER PRIMARY KEY AUTOINCREMENT,
+    task_id         INTEGER NOT NULL UNIQUE,
+    notify_at       INTEGER NOT NULL,
+    repeat_interval INTEGER NOT NULL,
+    created_at      INTEGER NOT NULL,
+    CONSTRAINT fk_task FOREIGN KEY (task_id) REFERENCES tasks (id) ON DELETE CASCADE
+);
+
+CREATE INDEX notifications_index_notify_at ON notifications (notify_at);
+
+CREATE TABLE task_dependencies
+(
+    task_id            INTEGER NOT NULL,
+    blocked_by_task_id INTEGER NOT NULL,
+    created_at         INTEGER NOT NULL,
+    PRIMARY KEY (task_id, blocked_by_task_id),
+    CONSTRAINT fk_task FOREIGN KEY (task_id) REFERENCES tasks (id) ON DELETE CASCADE,
+    CONSTRAINT fk_blocked_by_task FOREIGN KEY (blocked_by_task_id) REFERENCES tasks (id) ON DELETE CASCADE,
+    CONSTRAINT check_not_self_dependency CHECK (task_id <> blocked_by_task_id)
+);
+
+CREATE INDEX task_dependencies_index_blocked_by_task_id ON task_dependencies (blocked_by_task_id);
+
+CREATE TABLE task_tags
+(
+    task_id INTEGER NOT NULL,
+    tag     TEXT    NOT NULL,
+    PRIMARY KEY (task_id, tag),
+    CONSTRAINT fk_task FOREIGN KEY (task_id) REFERENCES tasks (id) ON DELETE CASCADE
+);
+
+CREATE INDEX task_tags_index_tag ON task_tags (tag);
+
+CREATE TABLE user_settings
+(
+    user_id         INTEGER PRIMARY KEY,
+    language        TEXT    NOT NULL DEFAULT 'ru',
+    timezone        TEXT    NOT NULL DEFAULT 'UTC',
+    reminder_offset INTEGER NOT NULL DEFAULT 0,
+    repeat_interval INTEGER NOT NULL DEFAULT 3600000000000,
+    list_sort       TEXT    NOT NULL DEFAULT 'due',
+    digest_time     INTEGER,
+    last_digest_on  TEXT,
+    updated_at      INTEGER NOT NULL,
+    CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT check_digest_time CHECK (digest_time >= 0 AND digest_time < 1440)
+);
+
+CREATE TABLE outbox
+(
+    id              INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id         INTEGER NOT NULL,
+    kind            TEXT    NOT NULL,
+    dedup_key       TEXT    NOT NULL,
+    payload         TEXT    NOT NULL,
+    attempts        INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at INTEGER,
+    last_error      TEXT    NOT NULL DEFAULT '',
+    sent_at         INTEGER,
+    created_at      INTEGER NOT NULL,
+    CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT outbox_unique_dedup_key UNIQUE (dedup_key)
+);
+
+CREATE INDEX outbox_index_next_attempt_at ON outbox (next_attempt_at) WHERE next_attempt_at IS NOT NULL;
@@ -0,0 +1,17 @@
+DROP TABLE IF EXISTS rate_limits;
+
+DROP TABLE IF EXISTS broadcasts;
+
+DROP TABLE IF EXISTS caldav_objects;
+
+DROP TABLE IF EXISTS webhook_deliveries;
+
+DROP TABLE IF EXISTS webhooks;
+
+DROP TABLE IF EXISTS api_tokens;
+
+DROP TABLE IF EXISTS task_escalations;
+
+DROP TABLE IF EXISTS escalation_policies;
+
+DROP TABLE IF EXISTS saved_filters;
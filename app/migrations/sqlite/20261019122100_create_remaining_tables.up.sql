@@ -0,0 +1,114 @@
+-- Остальные таблицы PostgreSQL, чтобы бот целиком работал на SQLite. Время, как и в остальной схеме, в микросекундах
+CREATE TABLE saved_filters
+(
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id    INTEGER NOT NULL,
+    name       TEXT    NOT NULL,
+    query      TEXT    NOT NULL,
+    created_at INTEGER NOT NULL,
+    CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT saved_filters_unique_user_id_name UNIQUE (user_id, name)
+);
+
+CREATE TABLE escalation_policies
+(
+    id              INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id         INTEGER NOT NULL,
+    task_id         INTEGER,
+    overdue_after   INTEGER NOT NULL,
+    repeat_interval INTEGER NOT NULL,
+    max_reminders   INTEGER NOT NULL,
+    created_at      INTEGER NOT NULL,
+    CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT fk_task FOREIGN KEY (task_id) REFERENCES tasks (id) ON DELETE CASCADE
+);
+
+-- Одна политика пользователя по умолчанию и не больше одной на задачу
+CREATE UNIQUE INDEX escalation_policies_unique_user_id ON escalation_policies (user_id) WHERE task_id IS NULL;
+
+CREATE UNIQUE INDEX escalation_policies_unique_task_id ON escalation_policies (task_id);
+
+CREATE TABLE task_escalations
+(
+    task_id      INTEGER PRIMARY KEY,
+    deadline     INTEGER NOT NULL,
+    level        INTEGER NOT NULL,
+    escalated_at INTEGER NOT NULL,
+    CONSTRAINT fk_task FOREIGN KEY (task_id) REFERENCES tasks (id) ON DELETE CASCADE
+);
+
+CREATE TABLE api_tokens
+(
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id    INTEGER NOT NULL,
+    scope      TEXT    NOT NULL DEFAULT 'api',
+    token_hash TEXT    NOT NULL,
+    created_at INTEGER NOT NULL,
+    CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT api_tokens_unique_user_id_scope UNIQUE (user_id, scope),
+    CONSTRAINT api_tokens_unique_token_hash UNIQUE (token_hash)
+);
+
+CREATE TABLE webhooks
+(
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id    INTEGER NOT NULL,
+    url        TEXT    NOT NULL,
+    secret     TEXT    NOT NULL,
+    created_at INTEGER NOT NULL,
+    CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX webhooks_index_user_id ON webhooks (user_id);
+
+CREATE TABLE webhook_deliveries
+(
+    id              INTEGER PRIMARY KEY AUTOINCREMENT,
+    webhook_id      INTEGER NOT NULL,
+    event_type      TEXT    NOT NULL,
+    payload         TEXT    NOT NULL,
+    attempts        INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at INTEGER,
+    last_error      TEXT    NOT NULL DEFAULT '',
+    delivered_at    INTEGER,
+    created_at      INTEGER NOT NULL,
+    CONSTRAINT fk_webhook FOREIGN KEY (webhook_id) REFERENCES webhooks (id) ON DELETE CASCADE
+);
+
+-- next_attempt_at равен NULL у доставленных и у исчерпавших все попытки
+CREATE INDEX webhook_deliveries_index_next_attempt_at ON webhook_deliveries (next_attempt_at) WHERE next_attempt_at IS NOT NULL;
+
+CREATE TABLE caldav_objects
+(
+    task_id INTEGER PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    name    TEXT    NOT NULL,
+    uid     TEXT    NOT NULL,
+    CONSTRAINT fk_task FOREIGN KEY (task_id) REFERENCES tasks (id) ON DELETE CASCADE,
+    CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT caldav_objects_unique_user_id_name UNIQUE (user_id, name)
+);
+
+CREATE TABLE broadcasts
+(
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    text         TEXT    NOT NULL,
+    status       TEXT    NOT NULL DEFAULT 'draft',
+    last_user_id INTEGER NOT NULL DEFAULT 0,
+    sent         INTEGER NOT NULL DEFAULT 0,
+    failed       INTEGER NOT NULL DEFAULT 0,
+    created_at   INTEGER NOT NULL,
+    finished_at  INTEGER
+);
+
+CREATE INDEX broadcasts_index_status ON broadcasts (status) WHERE status = 'running';
+
+CREATE TABLE rate_limits
+(
+    user_id    INTEGER NOT NULL,
+    bucket     TEXT    NOT NULL,
+    tokens     REAL    NOT NULL,
+    updated_at INTEGER NOT NULL,
+    CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    PRIMARY KEY (user_id, bucket)
+);
@@ -0,0 +1,3 @@
+-- Процесс, который отправляет рассылку, держит её до locked_until и продлевает срок с каждым пользователем
+ALTER TABLE broadcasts
+    ADD COLUMN locked_until INTEGER;
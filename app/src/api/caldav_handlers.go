@@ -0,0 +1,443 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/pkg/errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"tg_todo_bot/src/i18n"
+	"tg_todo_bot/src/ical"
+	"tg_todo_bot/src/models"
+	settings_types "tg_todo_bot/src/services/settings/types"
+	tasks_types "tg_todo_bot/src/services/tasks/types"
+	tokens_types "tg_todo_bot/src/services/tokens/types"
+	services_types "tg_todo_bot/src/services/types"
+	"time"
+)
+
+const (
+	calDAVPrincipalPath  = "/caldav/"
+	calDAVCollectionPath = "/caldav/tasks/"
+	calDAVContentType    = "text/calendar; charset=utf-8; component=vtodo"
+	calDAVAllow          = "OPTIONS, GET, HEAD, PUT, DELETE, PROPFIND, REPORT"
+	calDAVRealm          = `Basic realm="tg_todo_bot"`
+	maxCalDAVObjectSize  = 1 << 20
+)
+
+var (
+	errCalDAVUnauthorized = &httpError{status: http.StatusUnauthorized, message: "missing or invalid password, get one with /caldav in the bot"}
+	errPreconditionFailed = &httpError{status: http.StatusPreconditionFailed, message: "the task was changed by someone else"}
+	errReportNotSupported = &httpError{status: http.StatusNotImplemented, message: "only calendar-query and calendar-multiget are supported"}
+)
+
+// calDAVObject -> task as a calendar object resource, names of tasks created in the bot are "task-{id}.ics"
+type calDAVObject struct {
+	task models.Task
+	name string
+	body []byte
+	etag string
+}
+
+func (object calDAVObject) href() string {
+	return calDAVCollectionPath + url.PathEscape(object.name)
+}
+
+// handleCalDAVDiscovery -> /.well-known/caldav, RFC 6764
+func (server *Server) handleCalDAVDiscovery(w http.ResponseWriter, r *http.Request) {
+	http.Redirect(w, r, calDAVPrincipalPath, http.StatusMovedPermanently)
+}
+
+// authenticateCalDAV -> CalDAV clients can only send a login and a password,
+// the login is ignored and the password is the token from /caldav
+func (server *Server) authenticateCalDAV(handler func(w http.ResponseWriter, r *http.Request, user models.User)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		//Клиенты проверяют поддержку CalDAV до того, как спросят пароль
+		if r.Method == http.MethodOptions {
+			w.Header().Set("DAV", "1, 3, calendar-access")
+			w.Header().Set("Allow", calDAVAllow)
+			return
+		}
+
+		_, token, _ := r.BasicAuth()
+		userID, err := server.tokensService.Authenticate(r.Context(), token, tokens_types.ScopeCalDAV)
+		if err == nil {
+			var user models.User
+			user, err = server.findActiveUser(r.Context(), userID)
+			if err == nil {
+				handler(w, r, user)
+				return
+			}
+		}
+
+		if errors.Is(err, services_types.ErrNotFound) {
+			err = errCalDAVUnauthorized
+			w.Header().Set("WWW-Authenticate", calDAVRealm)
+		}
+		server.writeCalDAVError(w, r, err)
+	})
+}
+
+// handleCalDAV -> the principal and the calendar home are /caldav/, the only calendar is /caldav/tasks/
+func (server *Server) handleCalDAV(w http.ResponseWriter, r *http.Request, user models.User) {
+	path := r.URL.Path
+	switch {
+	case path == calDAVPrincipalPath:
+		server.handleCalDAVPrincipal(w, r, user)
+	case path == calDAVCollectionPath || path+"/" == calDAVCollectionPath:
+		server.handleCalDAVCollection(w, r, user)
+	case strings.HasPrefix(path, calDAVCollectionPath) && !strings.Contains(strings.TrimPrefix(path, calDAVCollectionPath), "/"):
+		server.handleCalDAVObject(w, r, user, strings.TrimPrefix(path, calDAVCollectionPath))
+	default:
+		server.writeCalDAVError(w, r, errRouteNotFound)
+	}
+}
+
+func (server *Server) handleCalDAVPrincipal(w http.ResponseWriter, r *http.Request, user models.User) {
+	if r.Method != "PROPFIND" {
+		server.writeCalDAVError(w, r, errMethodNotAllowed)
+		return
+	}
+
+	request, err := readDAVRequest(r)
+	if err != nil {
+		server.writeCalDAVError(w, r, err)
+		return
+	}
+
+	props := []davProp{
+		{XMLName: propResourceType, InnerXML: "<collection/><principal/>"},
+		textProp(propDisplayName, fmt.Sprintf("tg_todo_bot %d", user.TelegramID)),
+		hrefProp(propCurrentUserPrincipal, calDAVPrincipalPath),
+		hrefProp(propPrincipalURL, calDAVPrincipalPath),
+		hrefProp(propCalendarHomeSet, calDAVPrincipalPath),
+	}
+	responses := []davResponse{propResponse(calDAVPrincipalPath, props, request)}
+
+	if r.Header.Get("Depth") != "0" {
+		settings, objects, err := server.calDAVObjects(r.Context(), user)
+		if err != nil {
+			server.writeCalDAVError(w, r, err)
+			return
+		}
+		responses = append(responses, propResponse(calDAVCollectionPath, calDAVCollectionProps(settings, objects), request))
+	}
+
+	writeMultistatus(w, responses)
+}
+
+func (server *Server) handleCalDAVCollection(w http.ResponseWriter, r *http.Request, user models.User) {
+	if r.Method != "PROPFIND" && r.Method != "REPORT" {
+		server.writeCalDAVError(w, r, errMethodNotAllowed)
+		return
+	}
+
+	request, err := readDAVRequest(r)
+	if err != nil {
+		server.writeCalDAVError(w, r, err)
+		return
+	}
+
+	settings, objects, err := server.calDAVObjects(r.Context(), user)
+	if err != nil {
+		server.writeCalDAVError(w, r, err)
+		return
+	}
+
+	var responses []davResponse
+	switch {
+	case r.Method == "PROPFIND":
+		responses = append(responses, propResponse(calDAVCollectionPath, calDAVCollectionProps(settings, objects), request))
+
+		if r.Header.Get("Depth") != "0" {
+			for _, object := range objects {
+				responses = append(responses, propResponse(object.href(), calDAVObjectProps(object), request))
+			}
+		}
+	case request.XMLName == reportCalendarQuery:
+		for _, object := range objects {
+			responses = append(responses, propResponse(object.href(), calDAVObjectProps(object), request))
+		}
+	case request.XMLName == reportCalendarMultiget:
+		objectsByHref := make(map[string]calDAVObject, len(objects))
+		for _, object := range objects {
+			objectsByHref[calDAVCollectionPath+object.name] = object
+		}
+
+		//Клиенты присылают и пути, и полные URL
+		for _, href := range request.Hrefs {
+			hrefURL, err := url.Parse(strings.TrimSpace(href))
+			if err != nil {
+				responses = append(responses, davResponse{Href: href, Status: statusNotFound})
+				continue
+			}
+			object, exist := objectsByHref[hrefURL.Path]
+			if !exist {
+				responses = append(responses, davResponse{Href: href, Status: statusNotFound})
+				continue
+			}
+			responses = append(responses, propResponse(object.href(), calDAVObjectProps(object), request))
+		}
+	default:
+		server.writeCalDAVError(w, r, errReportNotSupported)
+		return
+	}
+
+	writeMultistatus(w, responses)
+}
+
+func (server *Server) handleCalDAVObject(w http.ResponseWriter, r *http.Request, user models.User, name string) {
+	object, err := server.findCalDAVObject(r.Context(), user, name)
+	exist := err == nil
+	if err != nil && !errors.Is(err, services_types.ErrNotFound) {
+		server.writeCalDAVError(w, r, err)
+		return
+	}
+
+	//If-Match защищает от перезаписи задачи, изменённой в боте после синхронизации
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch != "" && (!exist || (ifMatch != "*" && ifMatch != object.etag)) {
+		server.writeCalDAVError(w, r, errPreconditionFailed)
+		return
+	}
+	if r.Header.Get("If-None-Match") == "*" && exist {
+		server.writeCalDAVError(w, r, errPreconditionFailed)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		if !exist {
+			server.writeCalDAVError(w, r, errRouteNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", calDAVContentType)
+		w.Header().Set("ETag", object.etag)
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(object.body)
+		}
+	case http.MethodPut:
+		server.putCalDAVObject(w, r, user, name, object, exist)
+	case http.MethodDelete:
+		if !exist {
+			server.writeCalDAVError(w, r, errRouteNotFound)
+			return
+		}
+		err = server.tasksService.DeleteByID(r.Context(), object.task.ID)
+		if err != nil {
+			server.writeCalDAVError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		server.writeCalDAVError(w, r, errMethodNotAllowed)
+	}
+}
+
+// putCalDAVObject -> creates or updates the task through the tasks service, so events and webhooks fire as for the bot.
+// Alarms of the client are ignored, reminders are set in the bot. There is no ETag in the response,
+// the stored task differs from the client's object and the client has to download it again
+func (server *Server) putCalDAVObject(
+	w http.ResponseWriter,
+	r *http.Request,
+	user models.User,
+	name string,
+	object calDAVObject,
+	exist bool,
+) {
+	settings, err := server.settingsService.Get(r.Context(), user.ID)
+	if err != nil {
+		server.writeCalDAVError(w, r, err)
+		return
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r.Body, maxCalDAVObjectSize))
+	if err != nil {
+		server.writeCalDAVError(w, r, err)
+		return
+	}
+
+	todo, err := ical.ParseTodo(data, settings_types.Location(settings))
+	if err != nil {
+		server.writeCalDAVError(w, r, badRequest("invalid calendar object: "+err.Error()))
+		return
+	}
+
+	task := object.task
+	status := http.StatusNoContent
+	if exist {
+		params := tasks_types.UpdateParams{TaskID: task.ID, Done: task.Done && todo.Completed}
+		params.Title.Value, params.Title.IsSet = todo.Summary, true
+		params.Description.Value, params.Description.IsSet = todo.Description, true
+		params.Datetime.Value, params.Datetime.IsSet = todo.Due, true
+		params.Priority.Value, params.Priority.IsSet = todo.Priority, true
+		params.Tags.Value, params.Tags.IsSet = todo.Categories, true
+		//Имя и UID клиента сохраняются вместе с задачей, иначе он не найдёт её при следующей синхронизации
+		params.CalDAVObject = &tasks_types.CalDAVObjectParams{Name: name, UID: todo.UID}
+
+		err = server.tasksService.Update(r.Context(), params)
+	} else {
+		//Задача создаётся вместе с именем клиента, иначе повтор PUT после сбоя создал бы вторую задачу
+		task, err = server.tasksService.Create(r.Context(), tasks_types.CreateParams{
+			Title:        todo.Summary,
+			Description:  todo.Description,
+			Datetime:     todo.Due,
+			Priority:     todo.Priority,
+			Tags:         todo.Categories,
+			UserID:       user.ID,
+			CalDAVObject: &tasks_types.CalDAVObjectParams{Name: name, UID: todo.UID},
+		})
+		status = http.StatusCreated
+	}
+	if err != nil {
+		server.writeCalDAVError(w, r, err)
+		return
+	}
+
+	if todo.Completed && !task.Done {
+		_, err = server.tasksService.Complete(r.Context(), task.ID)
+		if err != nil {
+			server.writeCalDAVError(w, r, err)
+			return
+		}
+	}
+
+	w.WriteHeader(status)
+}
+
+// calDAVObjects (ctx, user) -> settings and all tasks of the user as calendar objects
+func (server *Server) calDAVObjects(ctx context.Context, user models.User) (models.UserSettings, []calDAVObject, error) {
+	settings, err := server.settingsService.Get(ctx, user.ID)
+	if err != nil {
+		return models.UserSettings{}, nil, err
+	}
+
+	tasks, err := server.tasksService.GetAllForUser(ctx, user.ID)
+	if err != nil {
+		return models.UserSettings{}, nil, err
+	}
+
+	tasksIDs := make([]int64, 0, len(tasks))
+	for _, task := range tasks {
+		tasksIDs = append(tasksIDs, task.ID)
+	}
+
+	storedObjects, err := server.calDAVService.FindByTasksIDs(ctx, tasksIDs)
+	if err != nil {
+		return models.UserSettings{}, nil, err
+	}
+
+	location := settings_types.Location(settings)
+	objects := make([]calDAVObject, 0, len(tasks))
+	for _, task := range tasks {
+		objects = append(objects, newCalDAVObject(task, storedObjects, location))
+	}
+
+	return settings, objects, nil
+}
+
+// findCalDAVObject (ctx, user, name) -> services_types.ErrNotFound if the user has no task with this name
+func (server *Server) findCalDAVObject(ctx context.Context, user models.User, name string) (calDAVObject, error) {
+	var taskID int64
+	storedObject, err := server.calDAVService.FindByName(ctx, user.ID, name)
+	switch {
+	case err == nil:
+		taskID = storedObject.TaskID
+	case errors.Is(err, services_types.ErrNotFound):
+		_, err = fmt.Sscanf(name, "task-%d.ics", &taskID)
+		if err != nil {
+			return calDAVObject{}, services_types.ErrNotFound
+		}
+	default:
+		return calDAVObject{}, err
+	}
+
+	task, err := server.findUserTask(ctx, user, taskID)
+	if err != nil {
+		return calDAVObject{}, err
+	}
+
+	settings, err := server.settingsService.Get(ctx, user.ID)
+	if err != nil {
+		return calDAVObject{}, err
+	}
+
+	storedObjects, err := server.calDAVService.FindByTasksIDs(ctx, []int64{task.ID})
+	if err != nil {
+		return calDAVObject{}, err
+	}
+
+	//Задача, переименованная клиентом, не доступна по имени по умолчанию
+	object := newCalDAVObject(task, storedObjects, settings_types.Location(settings))
+	if object.name != name {
+		return calDAVObject{}, services_types.ErrNotFound
+	}
+
+	return object, nil
+}
+
+// calDAVCollectionProps (settings, objects) -> CTag is a hash of all ETags, tasks have no modification time
+func calDAVCollectionProps(settings models.UserSettings, objects []calDAVObject) []davProp {
+	hash := sha256.New()
+	for _, object := range objects {
+		_, _ = io.WriteString(hash, object.name+object.etag)
+	}
+	ctag := fmt.Sprintf(`"%s"`, hex.EncodeToString(hash.Sum(nil))[:32])
+
+	return []davProp{
+		{XMLName: propResourceType, InnerXML: `<collection/><calendar xmlns="urn:ietf:params:xml:ns:caldav"/>`},
+		textProp(propDisplayName, i18n.New(settings.Language).T("ical.name")),
+		{XMLName: propSupportedComponentSet, InnerXML: `<comp name="VTODO"/>`},
+		{XMLName: propSupportedReportSet, InnerXML: supportedReportsInnerXML},
+		{XMLName: propCurrentUserPrivileges, InnerXML: privilegesInnerXML},
+		hrefProp(propCurrentUserPrincipal, calDAVPrincipalPath),
+		textProp(propGetCTag, ctag),
+		textProp(propGetETag, ctag),
+	}
+}
+
+func calDAVObjectProps(object calDAVObject) []davProp {
+	return []davProp{
+		{XMLName: propResourceType},
+		textProp(propGetETag, object.etag),
+		textProp(propGetContentType, calDAVContentType),
+		textProp(propCalendarData, string(object.body)),
+	}
+}
+
+// newCalDAVObject -> DTSTAMP is the creation time of the task, so the body and the ETag change only with the task
+func newCalDAVObject(task models.Task, storedObjects map[int64]models.CalDAVObject, location *time.Location) calDAVObject {
+	name := fmt.Sprintf("task-%d.ics", task.ID)
+	uid := ical.TaskUID(task.ID)
+	if storedObject, exist := storedObjects[task.ID]; exist {
+		name, uid = storedObject.Name, storedObject.UID
+	}
+
+	body := ical.Todo(task, uid, location, task.CreatedAt)
+	hash := sha256.Sum256(body)
+
+	return calDAVObject{
+		task: task,
+		name: name,
+		body: body,
+		etag: fmt.Sprintf(`"%s"`, hex.EncodeToString(hash[:])[:32]),
+	}
+}
+
+// writeCalDAVError -> CalDAV clients show plain text errors, not JSON
+func (server *Server) writeCalDAVError(w http.ResponseWriter, r *http.Request, err error) {
+	status, message := errorStatus(err)
+	if status == http.StatusInternalServerError {
+		server.logger.Errorw(
+			"API -> writeCalDAVError",
+			"error", err.Error(), "method", r.Method, "path", r.URL.Path,
+		)
+	}
+
+	setRetryAfter(w, err)
+	http.Error(w, message, status)
+}
@@ -0,0 +1,160 @@
+package api
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"tg_todo_bot/src/models"
+	services_types "tg_todo_bot/src/services/types"
+	"time"
+)
+
+type fakeCalDAVService struct {
+	objects map[int64]models.CalDAVObject
+}
+
+func newFakeCalDAVService() fakeCalDAVService {
+	return fakeCalDAVService{objects: map[int64]models.CalDAVObject{}}
+}
+
+func (service fakeCalDAVService) FindByName(ctx context.Context, userID int64, name string) (models.CalDAVObject, error) {
+	for _, object := range service.objects {
+		if object.UserID == userID && object.Name == name {
+			return object, nil
+		}
+	}
+
+	return models.CalDAVObject{}, services_types.ErrNotFound
+}
+
+func (service fakeCalDAVService) FindByTasksIDs(ctx context.Context, tasksIDs []int64) (map[int64]models.CalDAVObject, error) {
+	objects := make(map[int64]models.CalDAVObject)
+	for _, taskID := range tasksIDs {
+		if object, exist := service.objects[taskID]; exist {
+			objects[taskID] = object
+		}
+	}
+
+	return objects, nil
+}
+
+const newTodo = "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nBEGIN:VTODO\r\nUID:abc@client\r\nSUMMARY:Buy milk\r\n" +
+	"DUE;VALUE=DATE:20261021\r\nEND:VTODO\r\nEND:VCALENDAR\r\n"
+
+func TestCalDAV(t *testing.T) {
+	tasks := map[int64]models.Task{
+		1: {ID: 1, Title: "Chores", UserID: 1, CreatedAt: time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)},
+		2: {ID: 2, Title: "Secret", UserID: 2},
+	}
+	calDAV := newFakeCalDAVService()
+	server := NewServer(
+		zap.NewNop().Sugar(),
+		0,
+		fakeUsersService{},
+		fakeTasksService{tasks: tasks, calDAVObjects: calDAV.objects},
+		fakeNotificationsService{},
+		fakeTokensService{},
+		fakeSettingsService{},
+		calDAV,
+	)
+	handler := server.Handler()
+
+	do := func(method, path, password string, headers map[string]string, body string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, path, strings.NewReader(body))
+		if password != "" {
+			request.SetBasicAuth("anyone", password)
+		}
+		for name, value := range headers {
+			request.Header.Set(name, value)
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+
+		return recorder
+	}
+
+	testCases := []struct {
+		name     string
+		method   string
+		path     string
+		password string
+		expected int
+	}{
+		{name: "discovery", method: http.MethodGet, path: "/.well-known/caldav", expected: http.StatusMovedPermanently},
+		{name: "options without password", method: http.MethodOptions, path: "/caldav/", expected: http.StatusOK},
+		{name: "no password", method: "PROPFIND", path: "/caldav/", expected: http.StatusUnauthorized},
+		{name: "API token as password", method: "PROPFIND", path: "/caldav/", password: "user-1", expected: http.StatusUnauthorized},
+		{name: "task of another user", method: http.MethodGet, path: "/caldav/tasks/task-2.ics", password: "caldav-1", expected: http.StatusNotFound},
+		{name: "unknown name", method: http.MethodGet, path: "/caldav/tasks/unknown.ics", password: "caldav-1", expected: http.StatusNotFound},
+		{name: "unsupported report", method: "REPORT", path: "/caldav/tasks/", password: "caldav-1", expected: http.StatusNotImplemented},
+	}
+
+	for _, testCase := range testCases {
+		body := ""
+		if testCase.method == "REPORT" {
+			body = `<D:sync-collection xmlns:D="DAV:"/>`
+		}
+		recorder := do(testCase.method, testCase.path, testCase.password, nil, body)
+		if recorder.Code != testCase.expected {
+			t.Fatalf("%s: got status %d, expected %d, body %s", testCase.name, recorder.Code, testCase.expected, recorder.Body.String())
+		}
+	}
+
+	recorder := do("PROPFIND", "/caldav/", "", nil, "")
+	if recorder.Header().Get("WWW-Authenticate") == "" {
+		t.Fatalf("got no WWW-Authenticate header for 401")
+	}
+
+	recorder = do("PROPFIND", "/caldav/tasks/", "caldav-1", map[string]string{"Depth": "1"},
+		`<propfind xmlns="DAV:" xmlns:CS="http://calendarserver.org/ns/"><prop><getetag/><CS:getctag/><displayname/><quota-used-bytes/></prop></propfind>`)
+	listing := recorder.Body.String()
+	if recorder.Code != http.StatusMultiStatus || !strings.Contains(listing, "/caldav/tasks/task-1.ics") ||
+		strings.Contains(listing, "task-2.ics") || !strings.Contains(listing, "getctag") || !strings.Contains(listing, "404 Not Found") {
+		t.Fatalf("got PROPFIND %d:\n%s", recorder.Code, listing)
+	}
+
+	recorder = do(http.MethodGet, "/caldav/tasks/task-1.ics", "caldav-1", nil, "")
+	etag := recorder.Header().Get("ETag")
+	if recorder.Code != http.StatusOK || etag == "" || !strings.Contains(recorder.Body.String(), "SUMMARY:Chores") ||
+		!strings.Contains(listing, strings.Trim(etag, `"`)) {
+		t.Fatalf("got GET %d with ETag %s:\n%s", recorder.Code, etag, recorder.Body.String())
+	}
+
+	//Клиент создаёт задачу под своим именем и находит её по этому имени
+	recorder = do(http.MethodPut, "/caldav/tasks/milk.ics", "caldav-1", map[string]string{"If-None-Match": "*"}, newTodo)
+	if recorder.Code != http.StatusCreated {
+		t.Fatalf("got PUT %d: %s", recorder.Code, recorder.Body.String())
+	}
+	recorder = do("REPORT", "/caldav/tasks/", "caldav-1", nil,
+		`<C:calendar-multiget xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav"><D:prop><D:getetag/><C:calendar-data/></D:prop>`+
+			`<D:href>http://localhost/caldav/tasks/milk.ics</D:href><D:href>/caldav/tasks/gone.ics</D:href></C:calendar-multiget>`)
+	report := recorder.Body.String()
+	if recorder.Code != http.StatusMultiStatus || !strings.Contains(report, "UID:abc@client") ||
+		!strings.Contains(report, "SUMMARY:Buy milk") || !strings.Contains(report, "404 Not Found") {
+		t.Fatalf("got REPORT %d:\n%s", recorder.Code, report)
+	}
+
+	recorder = do(http.MethodPut, "/caldav/tasks/task-1.ics", "caldav-1", map[string]string{"If-Match": `"stale"`}, newTodo)
+	if recorder.Code != http.StatusPreconditionFailed || tasks[1].Title != "Chores" {
+		t.Fatalf("got PUT with stale ETag %d, task %+v", recorder.Code, tasks[1])
+	}
+
+	completed := strings.Replace(newTodo, "SUMMARY:Buy milk", "SUMMARY:Chores done\r\nSTATUS:COMPLETED", 1)
+	recorder = do(http.MethodPut, "/caldav/tasks/task-1.ics", "caldav-1", map[string]string{"If-Match": etag}, completed)
+	if recorder.Code != http.StatusNoContent || tasks[1].Title != "Chores done" || !tasks[1].Done {
+		t.Fatalf("got PUT %d, task %+v", recorder.Code, tasks[1])
+	}
+
+	recorder = do(http.MethodPut, "/caldav/tasks/task-1.ics", "caldav-1", nil, "BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n")
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("got PUT without VTODO %d, expected 400", recorder.Code)
+	}
+
+	recorder = do(http.MethodDelete, "/caldav/tasks/milk.ics", "caldav-1", nil, "")
+	if recorder.Code != http.StatusNoContent || len(tasks) != 2 {
+		t.Fatalf("got DELETE %d, tasks %+v", recorder.Code, tasks)
+	}
+}
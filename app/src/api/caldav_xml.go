@@ -0,0 +1,164 @@
+package api
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"github.com/pkg/errors"
+	"io"
+	"net/http"
+)
+
+const (
+	namespaceDAV      = "DAV:"
+	namespaceCalDAV   = "urn:ietf:params:xml:ns:caldav"
+	namespaceCalendar = "http://calendarserver.org/ns/"
+
+	statusOK                 = "HTTP/1.1 200 OK"
+	statusNotFound           = "HTTP/1.1 404 Not Found"
+	hrefTemplate             = `<href xmlns="DAV:">%s</href>`
+	privilegesInnerXML       = `<privilege><read/></privilege><privilege><write/></privilege>`
+	supportedReportsInnerXML = `<supported-report><report><calendar-query xmlns="urn:ietf:params:xml:ns:caldav"/></report></supported-report>` +
+		`<supported-report><report><calendar-multiget xmlns="urn:ietf:params:xml:ns:caldav"/></report></supported-report>`
+)
+
+var (
+	propResourceType          = xml.Name{Space: namespaceDAV, Local: "resourcetype"}
+	propDisplayName           = xml.Name{Space: namespaceDAV, Local: "displayname"}
+	propCurrentUserPrincipal  = xml.Name{Space: namespaceDAV, Local: "current-user-principal"}
+	propPrincipalURL          = xml.Name{Space: namespaceDAV, Local: "principal-URL"}
+	propCurrentUserPrivileges = xml.Name{Space: namespaceDAV, Local: "current-user-privilege-set"}
+	propSupportedReportSet    = xml.Name{Space: namespaceDAV, Local: "supported-report-set"}
+	propGetETag               = xml.Name{Space: namespaceDAV, Local: "getetag"}
+	propGetContentType        = xml.Name{Space: namespaceDAV, Local: "getcontenttype"}
+	propCalendarHomeSet       = xml.Name{Space: namespaceCalDAV, Local: "calendar-home-set"}
+	propSupportedComponentSet = xml.Name{Space: namespaceCalDAV, Local: "supported-calendar-component-set"}
+	propCalendarData          = xml.Name{Space: namespaceCalDAV, Local: "calendar-data"}
+	propGetCTag               = xml.Name{Space: namespaceCalendar, Local: "getctag"}
+	reportCalendarQuery       = xml.Name{Space: namespaceCalDAV, Local: "calendar-query"}
+	reportCalendarMultiget    = xml.Name{Space: namespaceCalDAV, Local: "calendar-multiget"}
+)
+
+// davRequest -> body of PROPFIND and REPORT, only the requested properties and hrefs of calendar-multiget are used,
+// filters of calendar-query are ignored and clients get all tasks
+type davRequest struct {
+	XMLName xml.Name
+	AllProp *struct{} `xml:"DAV: allprop"`
+	Prop    struct {
+		Names []davElement `xml:",any"`
+	} `xml:"DAV: prop"`
+	Hrefs []string `xml:"DAV: href"`
+}
+
+type davElement struct {
+	XMLName xml.Name
+}
+
+// davProp -> value of a property as raw XML, nested elements without a namespace inherit the one of the property
+type davProp struct {
+	XMLName  xml.Name
+	InnerXML string `xml:",innerxml"`
+}
+
+type davPropstat struct {
+	Props  []davProp `xml:"prop>prop"`
+	Status string    `xml:"status"`
+}
+
+type davResponse struct {
+	Href      string        `xml:"href"`
+	Propstats []davPropstat `xml:"propstat,omitempty"`
+	Status    string        `xml:"status,omitempty"`
+}
+
+type davMultistatus struct {
+	XMLName   xml.Name      `xml:"DAV: multistatus"`
+	Responses []davResponse `xml:"response"`
+}
+
+// readDAVRequest -> empty body is allprop, as RFC 4918 requires for PROPFIND
+func readDAVRequest(r *http.Request) (davRequest, error) {
+	var request davRequest
+
+	err := xml.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			request.AllProp = &struct{}{}
+			return request, nil
+		}
+		return davRequest{}, badRequest("invalid XML: " + err.Error())
+	}
+
+	if request.AllProp == nil && len(request.Prop.Names) == 0 {
+		request.AllProp = &struct{}{}
+	}
+
+	return request, nil
+}
+
+// propResponse (href, props, request) -> the requested props which the resource has go with 200, the rest with 404.
+// allprop doesn't include calendar-data, RFC 4791 requires to ask for it
+func propResponse(href string, props []davProp, request davRequest) davResponse {
+	found := davPropstat{Status: statusOK}
+	missing := davPropstat{Status: statusNotFound}
+
+	if request.AllProp != nil {
+		for _, prop := range props {
+			if prop.XMLName != propCalendarData {
+				found.Props = append(found.Props, prop)
+			}
+		}
+	}
+
+	for _, name := range request.Prop.Names {
+		prop, exist := findProp(props, name.XMLName)
+		if exist {
+			found.Props = append(found.Props, prop)
+			continue
+		}
+		missing.Props = append(missing.Props, davProp{XMLName: name.XMLName})
+	}
+
+	response := davResponse{Href: href}
+	for _, propstat := range []davPropstat{found, missing} {
+		if len(propstat.Props) > 0 {
+			response.Propstats = append(response.Propstats, propstat)
+		}
+	}
+
+	return response
+}
+
+func findProp(props []davProp, name xml.Name) (davProp, bool) {
+	for _, prop := range props {
+		if prop.XMLName == name {
+			return prop, true
+		}
+	}
+
+	return davProp{}, false
+}
+
+func textProp(name xml.Name, text string) davProp {
+	var escaped bytes.Buffer
+	_ = xml.EscapeText(&escaped, []byte(text))
+
+	return davProp{XMLName: name, InnerXML: escaped.String()}
+}
+
+func hrefProp(name xml.Name, href string) davProp {
+	return davProp{XMLName: name, InnerXML: fmt.Sprintf(hrefTemplate, href)}
+}
+
+func writeMultistatus(w http.ResponseWriter, responses []davResponse) {
+	body, err := xml.Marshal(davMultistatus{Responses: responses})
+	if err != nil {
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", `application/xml; charset="utf-8"`)
+	w.WriteHeader(http.StatusMultiStatus)
+	_, _ = w.Write([]byte(xml.Header))
+	_, _ = w.Write(body)
+}
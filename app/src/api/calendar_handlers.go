@@ -0,0 +1,72 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"tg_todo_bot/src/i18n"
+	"tg_todo_bot/src/ical"
+	settings_types "tg_todo_bot/src/services/settings/types"
+	tokens_types "tg_todo_bot/src/services/tokens/types"
+	"time"
+)
+
+// calendarHistory -> completed and past tasks stay in the feed for this long
+const calendarHistory = 30 * 24 * time.Hour
+
+// handleCalendarFeed -> GET /ical/{token}.ics, calendar apps can't send headers, so the token is in the URL.
+// Tasks are VEVENT by default, ?type=todo gives VTODO for apps which show tasks
+func (server *Server) handleCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		server.writeError(w, r, errMethodNotAllowed)
+		return
+	}
+
+	token := strings.TrimPrefix(r.URL.Path, "/ical/")
+	if !strings.HasSuffix(token, ".ics") {
+		server.writeError(w, r, errRouteNotFound)
+		return
+	}
+
+	//Неизвестная ссылка для клиента выглядит как несуществующая
+	userID, err := server.tokensService.Authenticate(r.Context(), strings.TrimSuffix(token, ".ics"), tokens_types.ScopeCalendar)
+	if err != nil {
+		server.writeError(w, r, err)
+		return
+	}
+
+	_, err = server.findActiveUser(r.Context(), userID)
+	if err != nil {
+		server.writeError(w, r, err)
+		return
+	}
+
+	settings, err := server.settingsService.Get(r.Context(), userID)
+	if err != nil {
+		server.writeError(w, r, err)
+		return
+	}
+
+	now := time.Now()
+	tasks, err := server.tasksService.GetDatedForUser(r.Context(), userID, now.Add(-calendarHistory))
+	if err != nil {
+		server.writeError(w, r, err)
+		return
+	}
+
+	component := ical.ComponentEvent
+	if r.URL.Query().Get("type") == "todo" {
+		component = ical.ComponentTodo
+	}
+
+	feed := ical.Feed(tasks, ical.FeedParams{
+		Name:      i18n.New(settings.Language).T("ical.name"),
+		Location:  settings_types.Location(settings),
+		Component: component,
+		Now:       now,
+	})
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="tasks.ics"`)
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	_, _ = w.Write(feed)
+}
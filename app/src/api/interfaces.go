@@ -0,0 +1,46 @@
+package api
+
+import (
+	"context"
+	"tg_todo_bot/src/models"
+	notifications_types "tg_todo_bot/src/services/notifications/types"
+	tasks_types "tg_todo_bot/src/services/tasks/types"
+	"time"
+)
+
+type UsersServiceI interface {
+	FindByID(ctx context.Context, userID int64) (models.User, error)
+	RequestDeletion(ctx context.Context, userID int64, now time.Time) error
+}
+
+type TasksServiceI interface {
+	Create(ctx context.Context, params tasks_types.CreateParams) (models.Task, error)
+	Update(ctx context.Context, params tasks_types.UpdateParams) error
+	FindByID(ctx context.Context, taskID int64) (models.Task, error)
+	DeleteByID(ctx context.Context, taskID int64) error
+	Complete(ctx context.Context, taskID int64) ([]models.Task, error)
+	GetActiveForUserPage(ctx context.Context, params tasks_types.PageParams) (tasks_types.TasksPage, error)
+	Filter(ctx context.Context, params tasks_types.FilterParams) (tasks_types.TasksPage, error)
+	GetDatedForUser(ctx context.Context, userID int64, from time.Time) ([]models.Task, error)
+	GetAllForUser(ctx context.Context, userID int64) ([]models.Task, error)
+}
+
+type NotificationsServiceI interface {
+	Create(ctx context.Context, params notifications_types.CreateParams) error
+	Update(ctx context.Context, params notifications_types.UpdateParams) error
+	DeleteByID(ctx context.Context, notificationID int64) error
+	FindByTaskID(ctx context.Context, taskID int64) (models.Notification, error)
+}
+
+type TokensServiceI interface {
+	Authenticate(ctx context.Context, token, scope string) (int64, error)
+}
+
+type SettingsServiceI interface {
+	Get(ctx context.Context, userID int64) (models.UserSettings, error)
+}
+
+type CalDAVServiceI interface {
+	FindByName(ctx context.Context, userID int64, name string) (models.CalDAVObject, error)
+	FindByTasksIDs(ctx context.Context, tasksIDs []int64) (map[int64]models.CalDAVObject, error)
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"github.com/pkg/errors"
+	"net/http"
+	"tg_todo_bot/src/models"
+	notifications_types "tg_todo_bot/src/services/notifications/types"
+	services_types "tg_todo_bot/src/services/types"
+	"time"
+)
+
+// notificationRequest -> RepeatInterval is in seconds, 0 keeps the current interval or sets the one from the user's settings
+type notificationRequest struct {
+	NotifyAt       *time.Time `json:"notify_at"`
+	RepeatInterval int64      `json:"repeat_interval"`
+}
+
+// handleNotification -> GET, PUT and DELETE /api/v1/tasks/{id}/notification, a task has at most one notification
+func (server *Server) handleNotification(w http.ResponseWriter, r *http.Request, task models.Task) {
+	switch r.Method {
+	case http.MethodGet:
+		server.writeNotification(w, r, task.ID)
+	case http.MethodPut:
+		server.putNotification(w, r, task)
+	case http.MethodDelete:
+		server.deleteNotification(w, r, task)
+	default:
+		server.writeError(w, r, errMethodNotAllowed)
+	}
+}
+
+func (server *Server) putNotification(w http.ResponseWriter, r *http.Request, task models.Task) {
+	var request notificationRequest
+	err := readJSON(r, &request)
+	if err != nil {
+		server.writeError(w, r, err)
+		return
+	}
+	if request.NotifyAt == nil {
+		server.writeError(w, r, badRequest("notify_at is required"))
+		return
+	}
+	if request.RepeatInterval < 0 {
+		server.writeError(w, r, badRequest("repeat_interval can't be negative"))
+		return
+	}
+	repeatInterval := time.Duration(request.RepeatInterval) * time.Second
+
+	notification, err := server.notificationsService.FindByTaskID(r.Context(), task.ID)
+	switch {
+	case errors.Is(err, services_types.ErrNotFound):
+		//Без интервала в запросе новое уведомление повторяется с интервалом из настроек пользователя
+		if repeatInterval == 0 {
+			repeatInterval, err = server.userRepeatInterval(r, task.UserID)
+			if err != nil {
+				break
+			}
+		}
+		err = server.notificationsService.Create(r.Context(), notifications_types.CreateParams{
+			TaskID:         task.ID,
+			NotifyAt:       *request.NotifyAt,
+			RepeatInterval: repeatInterval,
+		})
+	case err == nil:
+		params := notifications_types.UpdateParams{NotificationID: notification.ID}
+		params.NotifyAt.Value, params.NotifyAt.IsSet = *request.NotifyAt, true
+		if repeatInterval > 0 {
+			params.RepeatInterval.Value, params.RepeatInterval.IsSet = repeatInterval, true
+		}
+		err = server.notificationsService.Update(r.Context(), params)
+	}
+	if err != nil {
+		server.writeError(w, r, err)
+		return
+	}
+
+	server.writeNotification(w, r, task.ID)
+}
+
+func (server *Server) userRepeatInterval(r *http.Request, userID int64) (time.Duration, error) {
+	settings, err := server.settingsService.Get(r.Context(), userID)
+	if err != nil {
+		return 0, err
+	}
+
+	return settings.RepeatInterval, nil
+}
+
+func (server *Server) deleteNotification(w http.ResponseWriter, r *http.Request, task models.Task) {
+	notification, err := server.notificationsService.FindByTaskID(r.Context(), task.ID)
+	if err != nil {
+		server.writeError(w, r, err)
+		return
+	}
+
+	err = server.notificationsService.DeleteByID(r.Context(), notification.ID)
+	if err != nil {
+		server.writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (server *Server) writeNotification(w http.ResponseWriter, r *http.Request, taskID int64) {
+	notification, err := server.notificationsService.FindByTaskID(r.Context(), taskID)
+	if err != nil {
+		server.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newNotificationResponse(notification))
+}
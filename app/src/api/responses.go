@@ -0,0 +1,194 @@
+package api
+
+import (
+	"encoding/json"
+	"github.com/pkg/errors"
+	"math"
+	"net/http"
+	"strconv"
+	"tg_todo_bot/src/models"
+	services_types "tg_todo_bot/src/services/types"
+	users_types "tg_todo_bot/src/services/users/types"
+	"time"
+)
+
+// httpError -> error of the API layer itself, its message is shown to the client as is
+type httpError struct {
+	status  int
+	message string
+}
+
+func (err *httpError) Error() string {
+	return err.message
+}
+
+var (
+	errUnauthorized     = &httpError{status: http.StatusUnauthorized, message: "missing or invalid token, get one with /token in the bot"}
+	errBanned           = &httpError{status: http.StatusForbidden, message: "the account is banned"}
+	errMethodNotAllowed = &httpError{status: http.StatusMethodNotAllowed, message: "method not allowed"}
+	errRouteNotFound    = &httpError{status: http.StatusNotFound, message: "not found"}
+)
+
+func badRequest(message string) error {
+	return &httpError{status: http.StatusBadRequest, message: message}
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// errorStatus (err) -> HTTP status and the message for the client, internal errors aren't disclosed
+func errorStatus(err error) (int, string) {
+	var apiErr *httpError
+	switch {
+	case errors.As(err, &apiErr):
+		return apiErr.status, apiErr.message
+	case errors.Is(err, services_types.ErrNotFound):
+		return http.StatusNotFound, "not found"
+	case errors.Is(err, services_types.ErrInvalidParams):
+		return http.StatusBadRequest, err.Error()
+	case errors.Is(err, services_types.ErrDependencyCycle):
+		return http.StatusConflict, err.Error()
+	case errors.Is(err, services_types.ErrRateLimited):
+		return http.StatusTooManyRequests, "too many requests, retry later"
+	case errors.Is(err, services_types.ErrTimeout):
+		return http.StatusServiceUnavailable, "the request took too long, retry later"
+	default:
+		return http.StatusInternalServerError, "internal error"
+	}
+}
+
+// writeError -> JSON error with the status from errorStatus, internal errors and timeouts are logged
+func (server *Server) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	status, message := errorStatus(err)
+	if status >= http.StatusInternalServerError {
+		server.logger.Errorw(
+			"API -> writeError",
+			"error", err.Error(), "method", r.Method, "path", r.URL.Path,
+		)
+	}
+
+	setRetryAfter(w, err)
+	writeJSON(w, status, errorResponse{Error: message})
+}
+
+// setRetryAfter -> the Retry-After header in whole seconds for rate limited requests
+func setRetryAfter(w http.ResponseWriter, err error) {
+	var rateLimitedErr *services_types.RateLimitedError
+	if !errors.As(err, &rateLimitedErr) {
+		return
+	}
+
+	seconds := int(math.Ceil(rateLimitedErr.RetryAfter.Seconds()))
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// readJSON -> unknown fields are rejected, so typos in field names don't pass silently
+func readJSON(r *http.Request, body interface{}) error {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(body)
+	if err != nil {
+		return badRequest("invalid JSON: " + err.Error())
+	}
+
+	return nil
+}
+
+type userResponse struct {
+	ID         int64      `json:"id"`
+	TelegramID int64      `json:"telegram_id"`
+	CreatedAt  time.Time  `json:"created_at"`
+	PurgeAt    *time.Time `json:"purge_at,omitempty"` //the deletion is requested, the account is purged at this time
+}
+
+func newUserResponse(user models.User) userResponse {
+	return userResponse{
+		ID:         user.ID,
+		TelegramID: user.TelegramID,
+		CreatedAt:  user.CreatedAt,
+		PurgeAt:    users_types.PurgeAt(user),
+	}
+}
+
+type notificationResponse struct {
+	ID             int64     `json:"id"`
+	TaskID         int64     `json:"task_id"`
+	NotifyAt       time.Time `json:"notify_at"`
+	RepeatInterval int64     `json:"repeat_interval"` //seconds
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+func newNotificationResponse(notification models.Notification) notificationResponse {
+	return notificationResponse{
+		ID:             notification.ID,
+		TaskID:         notification.TaskID,
+		NotifyAt:       notification.NotifyAt,
+		RepeatInterval: int64(notification.RepeatInterval / time.Second),
+		CreatedAt:      notification.CreatedAt,
+	}
+}
+
+type taskResponse struct {
+	ID           int64                 `json:"id"`
+	Title        string                `json:"title"`
+	Description  string                `json:"description"`
+	Datetime     *time.Time            `json:"datetime"`
+	Done         bool                  `json:"done"`
+	Priority     int                   `json:"priority"`
+	Tags         []string              `json:"tags"`
+	BlockedBy    []int64               `json:"blocked_by"`
+	Notification *notificationResponse `json:"notification"`
+	CreatedAt    time.Time             `json:"created_at"`
+}
+
+func newTaskResponse(task models.Task) taskResponse {
+	response := taskResponse{
+		ID:          task.ID,
+		Title:       task.Title,
+		Description: task.Description,
+		Datetime:    task.Datetime,
+		Done:        task.Done,
+		Priority:    task.Priority,
+		Tags:        task.Tags,
+		BlockedBy:   task.BlockedBy,
+		CreatedAt:   task.CreatedAt,
+	}
+
+	//Пустые списки отдаются как [], а не null
+	if response.Tags == nil {
+		response.Tags = []string{}
+	}
+	if response.BlockedBy == nil {
+		response.BlockedBy = []int64{}
+	}
+
+	if task.Notification != nil {
+		notification := newNotificationResponse(*task.Notification)
+		response.Notification = &notification
+	}
+
+	return response
+}
+
+func newTasksResponse(tasks []models.Task) []taskResponse {
+	responses := []taskResponse{}
+	for _, task := range tasks {
+		responses = append(responses, newTaskResponse(task))
+	}
+
+	return responses
+}
+
+// tasksPageResponse -> NextAfter is passed as ?after= to get the next page
+type tasksPageResponse struct {
+	Tasks     []taskResponse `json:"tasks"`
+	NextAfter *int64         `json:"next_after"`
+}
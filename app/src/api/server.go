@@ -0,0 +1,162 @@
+package api
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"net/http"
+	"strings"
+	"tg_todo_bot/src/models"
+	tokens_types "tg_todo_bot/src/services/tokens/types"
+	services_types "tg_todo_bot/src/services/types"
+	"time"
+)
+
+const shutdownTimeout = 10 * time.Second
+
+//go:embed openapi.yaml
+var openAPISpec []byte
+
+// Server -> JSON API over the same services as the bot, users authenticate with tokens from /token
+type Server struct {
+	logger               *zap.SugaredLogger
+	port                 int
+	usersService         UsersServiceI
+	tasksService         TasksServiceI
+	notificationsService NotificationsServiceI
+	tokensService        TokensServiceI
+	settingsService      SettingsServiceI
+	calDAVService        CalDAVServiceI
+}
+
+func NewServer(
+	logger *zap.SugaredLogger,
+	port int,
+	usersService UsersServiceI,
+	tasksService TasksServiceI,
+	notificationsService NotificationsServiceI,
+	tokensService TokensServiceI,
+	settingsService SettingsServiceI,
+	calDAVService CalDAVServiceI,
+) *Server {
+	return &Server{
+		logger:               logger,
+		port:                 port,
+		usersService:         usersService,
+		tasksService:         tasksService,
+		notificationsService: notificationsService,
+		tokensService:        tokensService,
+		settingsService:      settingsService,
+		calDAVService:        calDAVService,
+	}
+}
+
+// Run serves requests until ctx is cancelled
+func (server *Server) Run(ctx context.Context) {
+	httpServer := &http.Server{
+		Addr:              fmt.Sprintf(":%d", server.port),
+		Handler:           server.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		err := httpServer.Shutdown(shutdownCtx)
+		if err != nil {
+			server.logger.Errorw("API -> Run -> httpServer.Shutdown(ctx)", "error", err.Error())
+		}
+	}()
+
+	err := httpServer.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		server.logger.Errorw("API -> Run -> httpServer.ListenAndServe()", "error", err.Error())
+	}
+}
+
+// Handler -> routes of the API, exported for tests
+func (server *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/openapi.yaml", server.handleOpenAPISpec)
+	mux.HandleFunc("/ical/", server.handleCalendarFeed)
+	mux.HandleFunc("/.well-known/caldav", server.handleCalDAVDiscovery)
+	mux.Handle("/caldav/", server.authenticateCalDAV(server.handleCalDAV))
+	mux.Handle("/api/v1/me", server.authenticate(server.handleMe))
+	mux.Handle("/api/v1/tasks", server.authenticate(server.handleTasks))
+	mux.Handle("/api/v1/tasks/", server.authenticate(server.handleTask))
+
+	return mux
+}
+
+func (server *Server) handleOpenAPISpec(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		server.writeError(w, r, errMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/yaml")
+	_, _ = w.Write(openAPISpec)
+}
+
+// authenticate -> passes the owner of the Bearer token to the handler
+func (server *Server) authenticate(handler func(w http.ResponseWriter, r *http.Request, user models.User)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+		userID, err := server.tokensService.Authenticate(r.Context(), token, tokens_types.ScopeApi)
+		if err != nil {
+			if errors.Is(err, services_types.ErrNotFound) {
+				server.writeError(w, r, errUnauthorized)
+				return
+			}
+			server.writeError(w, r, err)
+			return
+		}
+
+		user, err := server.findActiveUser(r.Context(), userID)
+		if err != nil {
+			//Пользователь удалён, а токен ещё не успел удалиться каскадом
+			if errors.Is(err, services_types.ErrNotFound) {
+				server.writeError(w, r, errUnauthorized)
+				return
+			}
+			server.writeError(w, r, err)
+			return
+		}
+
+		handler(w, r, user)
+	})
+}
+
+// findActiveUser (ctx, userID) -> errBanned for a banned user, their tokens stay but give access to nothing
+func (server *Server) findActiveUser(ctx context.Context, userID int64) (models.User, error) {
+	user, err := server.usersService.FindByID(ctx, userID)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	if user.BannedAt != nil {
+		return models.User{}, errBanned
+	}
+
+	return user, nil
+}
+
+// findUserTask (ctx, user, taskID) -> services_types.ErrNotFound if the task belongs to another user
+func (server *Server) findUserTask(ctx context.Context, user models.User, taskID int64) (models.Task, error) {
+	task, err := server.tasksService.FindByID(ctx, taskID)
+	if err != nil {
+		return models.Task{}, err
+	}
+
+	if task.UserID != user.ID {
+		return models.Task{}, services_types.ErrNotFound
+	}
+
+	return task, nil
+}
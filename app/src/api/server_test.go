@@ -0,0 +1,317 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"tg_todo_bot/src/ical"
+	"tg_todo_bot/src/models"
+	repositories_types "tg_todo_bot/src/repositories/types"
+	notifications_types "tg_todo_bot/src/services/notifications/types"
+	tasks_types "tg_todo_bot/src/services/tasks/types"
+	tokens_types "tg_todo_bot/src/services/tokens/types"
+	services_types "tg_todo_bot/src/services/types"
+	"time"
+)
+
+// bannedUserID -> fakeUsersService returns this user as banned
+const bannedUserID = 9
+
+type fakeUsersService struct{}
+
+func (fakeUsersService) FindByID(ctx context.Context, userID int64) (models.User, error) {
+	user := models.User{ID: userID, TelegramID: userID * 10}
+	if userID == bannedUserID {
+		bannedAt := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+		user.BannedAt = &bannedAt
+	}
+
+	return user, nil
+}
+
+func (fakeUsersService) RequestDeletion(ctx context.Context, userID int64, now time.Time) error {
+	return nil
+}
+
+type fakeTasksService struct {
+	tasks         map[int64]models.Task
+	calDAVObjects map[int64]models.CalDAVObject //shared with fakeCalDAVService, nil if not needed
+}
+
+func (service fakeTasksService) Create(ctx context.Context, params tasks_types.CreateParams) (models.Task, error) {
+	if params.Title == "" {
+		return models.Task{}, services_types.InvalidParams(errors.New("title is required"))
+	}
+	task := models.Task{ID: int64(len(service.tasks) + 1), Title: params.Title, Datetime: params.Datetime, UserID: params.UserID}
+	service.tasks[task.ID] = task
+	if params.CalDAVObject != nil && service.calDAVObjects != nil {
+		uid := params.CalDAVObject.UID
+		if uid == "" {
+			uid = ical.TaskUID(task.ID)
+		}
+		service.calDAVObjects[task.ID] = models.CalDAVObject{TaskID: task.ID, UserID: task.UserID, Name: params.CalDAVObject.Name, UID: uid}
+	}
+
+	return task, nil
+}
+
+func (service fakeTasksService) Update(ctx context.Context, params tasks_types.UpdateParams) error {
+	task, exist := service.tasks[params.TaskID]
+	if !exist {
+		return services_types.ErrNotFound
+	}
+	if params.Title.IsSet {
+		task.Title = params.Title.Value
+	}
+	if params.Datetime.IsSet {
+		task.Datetime = params.Datetime.Value
+	}
+	task.Done = params.Done
+	service.tasks[task.ID] = task
+	if params.CalDAVObject != nil && service.calDAVObjects != nil {
+		uid := params.CalDAVObject.UID
+		if uid == "" {
+			uid = ical.TaskUID(task.ID)
+		}
+		service.calDAVObjects[task.ID] = models.CalDAVObject{TaskID: task.ID, UserID: task.UserID, Name: params.CalDAVObject.Name, UID: uid}
+	}
+
+	return nil
+}
+
+func (service fakeTasksService) FindByID(ctx context.Context, taskID int64) (models.Task, error) {
+	task, exist := service.tasks[taskID]
+	if !exist {
+		return models.Task{}, services_types.ErrNotFound
+	}
+
+	return task, nil
+}
+
+func (service fakeTasksService) DeleteByID(ctx context.Context, taskID int64) error {
+	delete(service.tasks, taskID)
+	return nil
+}
+
+func (service fakeTasksService) Complete(ctx context.Context, taskID int64) ([]models.Task, error) {
+	task := service.tasks[taskID]
+	task.Done = true
+	service.tasks[taskID] = task
+
+	return nil, nil
+}
+
+func (service fakeTasksService) GetActiveForUserPage(ctx context.Context, params tasks_types.PageParams) (tasks_types.TasksPage, error) {
+	return tasks_types.TasksPage{}, errors.New("connection refused")
+}
+
+func (service fakeTasksService) GetDatedForUser(ctx context.Context, userID int64, from time.Time) ([]models.Task, error) {
+	var tasks []models.Task
+	for _, task := range service.tasks {
+		if task.UserID == userID && task.Datetime != nil {
+			tasks = append(tasks, task)
+		}
+	}
+
+	return tasks, nil
+}
+
+func (service fakeTasksService) GetAllForUser(ctx context.Context, userID int64) ([]models.Task, error) {
+	var tasks []models.Task
+	for _, task := range service.tasks {
+		if task.UserID == userID {
+			tasks = append(tasks, task)
+		}
+	}
+
+	return tasks, nil
+}
+
+func (service fakeTasksService) Filter(ctx context.Context, params tasks_types.FilterParams) (tasks_types.TasksPage, error) {
+	return tasks_types.TasksPage{}, services_types.InvalidParams(&tasks_types.FilterSyntaxError{Token: "foo:", Reason: "unknown key"})
+}
+
+type fakeNotificationsService struct{}
+
+func (fakeNotificationsService) Create(ctx context.Context, params notifications_types.CreateParams) error {
+	return nil
+}
+
+func (fakeNotificationsService) Update(ctx context.Context, params notifications_types.UpdateParams) error {
+	return nil
+}
+
+func (fakeNotificationsService) DeleteByID(ctx context.Context, notificationID int64) error {
+	return nil
+}
+
+func (fakeNotificationsService) FindByTaskID(ctx context.Context, taskID int64) (models.Notification, error) {
+	return models.Notification{}, services_types.ErrNotFound
+}
+
+// fakeTokensService -> token "user-N" is the API token of the user N, "calendar-N" and "caldav-N" are the calendar ones
+type fakeTokensService struct{}
+
+func (fakeTokensService) Authenticate(ctx context.Context, token, scope string) (int64, error) {
+	prefixes := map[string]string{
+		tokens_types.ScopeApi:      "user",
+		tokens_types.ScopeCalendar: "calendar",
+		tokens_types.ScopeCalDAV:   "caldav",
+	}
+
+	var userID int64
+	_, err := fmt.Sscanf(token, prefixes[scope]+"-%d", &userID)
+	if err != nil {
+		return 0, services_types.ErrNotFound
+	}
+
+	return userID, nil
+}
+
+type fakeSettingsService struct{}
+
+func (fakeSettingsService) Get(ctx context.Context, userID int64) (models.UserSettings, error) {
+	return models.UserSettings{UserID: userID, Language: "en", Timezone: "Europe/Moscow"}, nil
+}
+
+func TestHandler(t *testing.T) {
+	dentistAt := time.Date(2026, 10, 20, 15, 0, 0, 0, time.UTC)
+	server := NewServer(
+		zap.NewNop().Sugar(),
+		0,
+		fakeUsersService{},
+		fakeTasksService{tasks: map[int64]models.Task{
+			1: {ID: 1, Title: "Chores", UserID: 1},
+			2: {ID: 2, Title: "Dentist", UserID: 1, Datetime: &dentistAt},
+		}},
+		fakeNotificationsService{},
+		fakeTokensService{},
+		fakeSettingsService{},
+		newFakeCalDAVService(),
+	)
+	handler := server.Handler()
+
+	testCases := []struct {
+		name     string
+		method   string
+		path     string
+		token    string
+		body     string
+		expected int
+	}{
+		{name: "spec without token", method: http.MethodGet, path: "/openapi.yaml", expected: http.StatusOK},
+		{name: "no token", method: http.MethodGet, path: "/api/v1/me", expected: http.StatusUnauthorized},
+		{name: "invalid token", method: http.MethodGet, path: "/api/v1/me", token: "secret", expected: http.StatusUnauthorized},
+		{name: "me", method: http.MethodGet, path: "/api/v1/me", token: "user-1", expected: http.StatusOK},
+		{name: "deletion of the account", method: http.MethodDelete, path: "/api/v1/me", token: "user-1", expected: http.StatusAccepted},
+		{name: "own task", method: http.MethodGet, path: "/api/v1/tasks/1", token: "user-1", expected: http.StatusOK},
+		{name: "task of another user", method: http.MethodGet, path: "/api/v1/tasks/1", token: "user-2", expected: http.StatusNotFound},
+		{name: "missing task", method: http.MethodGet, path: "/api/v1/tasks/42", token: "user-1", expected: http.StatusNotFound},
+		{name: "unknown subresource", method: http.MethodGet, path: "/api/v1/tasks/1/comments", token: "user-1", expected: http.StatusNotFound},
+		{name: "wrong method", method: http.MethodPut, path: "/api/v1/tasks/1", token: "user-1", expected: http.StatusMethodNotAllowed},
+		{name: "missing notification", method: http.MethodGet, path: "/api/v1/tasks/1/notification", token: "user-1", expected: http.StatusNotFound},
+		{name: "invalid params", method: http.MethodPost, path: "/api/v1/tasks", token: "user-1", body: `{"title": ""}`, expected: http.StatusBadRequest},
+		{name: "unknown field", method: http.MethodPost, path: "/api/v1/tasks", token: "user-1", body: `{"name": "Chores"}`, expected: http.StatusBadRequest},
+		{name: "create", method: http.MethodPost, path: "/api/v1/tasks", token: "user-1", body: `{"title": "Dishes"}`, expected: http.StatusCreated},
+		{name: "filter syntax error", method: http.MethodGet, path: "/api/v1/tasks?q=foo:bar", token: "user-1", expected: http.StatusBadRequest},
+		{name: "invalid limit", method: http.MethodGet, path: "/api/v1/tasks?limit=1000", token: "user-1", expected: http.StatusBadRequest},
+		{name: "calendar feed", method: http.MethodGet, path: "/ical/calendar-1.ics", expected: http.StatusOK},
+		{name: "API token for calendar", method: http.MethodGet, path: "/ical/user-1.ics", expected: http.StatusNotFound},
+		{name: "calendar token for API", method: http.MethodGet, path: "/api/v1/me", token: "calendar-1", expected: http.StatusUnauthorized},
+		{name: "internal error", method: http.MethodGet, path: "/api/v1/tasks", token: "user-1", expected: http.StatusInternalServerError},
+	}
+
+	for _, testCase := range testCases {
+		request := httptest.NewRequest(testCase.method, testCase.path, strings.NewReader(testCase.body))
+		if testCase.token != "" {
+			request.Header.Set("Authorization", "Bearer "+testCase.token)
+		}
+		recorder := httptest.NewRecorder()
+
+		handler.ServeHTTP(recorder, request)
+
+		if recorder.Code != testCase.expected {
+			t.Fatalf("%s: got status %d, expected %d, body %s", testCase.name, recorder.Code, testCase.expected, recorder.Body.String())
+		}
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/ical/calendar-1.ics", nil))
+	if !strings.HasPrefix(recorder.Header().Get("Content-Type"), "text/calendar") ||
+		!strings.Contains(recorder.Body.String(), "SUMMARY:Dentist") || strings.Contains(recorder.Body.String(), "Chores") {
+		t.Fatalf("got calendar feed %s:\n%s", recorder.Header().Get("Content-Type"), recorder.Body.String())
+	}
+}
+
+func TestBannedUserIsForbidden(t *testing.T) {
+	server := NewServer(
+		zap.NewNop().Sugar(),
+		0,
+		fakeUsersService{},
+		fakeTasksService{tasks: map[int64]models.Task{}},
+		fakeNotificationsService{},
+		fakeTokensService{},
+		fakeSettingsService{},
+		newFakeCalDAVService(),
+	)
+	handler := server.Handler()
+
+	apiRequest := httptest.NewRequest(http.MethodGet, "/api/v1/me", nil)
+	apiRequest.Header.Set("Authorization", fmt.Sprintf("Bearer user-%d", bannedUserID))
+
+	calDAVRequest := httptest.NewRequest("PROPFIND", "/caldav/tasks/", nil)
+	calDAVRequest.SetBasicAuth("login", fmt.Sprintf("caldav-%d", bannedUserID))
+
+	calendarRequest := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/ical/calendar-%d.ics", bannedUserID), nil)
+
+	for name, request := range map[string]*http.Request{
+		"API":      apiRequest,
+		"CalDAV":   calDAVRequest,
+		"calendar": calendarRequest,
+	} {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+
+		if recorder.Code != http.StatusForbidden {
+			t.Fatalf("%s: got status %d, expected %d, body %s", name, recorder.Code, http.StatusForbidden, recorder.Body.String())
+		}
+	}
+}
+
+func TestErrorStatusHidesInternalErrors(t *testing.T) {
+	status, message := errorStatus(errors.Wrap(errors.New("password authentication failed"), "pgPool.Query"))
+	if status != http.StatusInternalServerError || message != "internal error" {
+		t.Fatalf("got %d %q, expected 500 \"internal error\"", status, message)
+	}
+
+	status, _ = errorStatus(errors.Wrap(services_types.ErrDependencyCycle, "tasksService.AddDependency"))
+	if status != http.StatusConflict {
+		t.Fatalf("got %d, expected 409", status)
+	}
+}
+
+func TestErrorStatusTimeout(t *testing.T) {
+	err := errors.Wrap(&repositories_types.TimeoutError{Err: context.DeadlineExceeded}, "tasksService.FindByID")
+
+	status, _ := errorStatus(err)
+	if status != http.StatusServiceUnavailable {
+		t.Fatalf("got %d, expected 503", status)
+	}
+}
+
+func TestWriteErrorRateLimited(t *testing.T) {
+	server := &Server{logger: zap.NewNop().Sugar()}
+	recorder := httptest.NewRecorder()
+
+	server.writeError(recorder, httptest.NewRequest(http.MethodPost, "/api/v1/tasks", nil),
+		&services_types.RateLimitedError{RetryAfter: 1500 * time.Millisecond})
+
+	if recorder.Code != http.StatusTooManyRequests || recorder.Header().Get("Retry-After") != "2" {
+		t.Fatalf("got %d with Retry-After %q, expected 429 with Retry-After \"2\"", recorder.Code, recorder.Header().Get("Retry-After"))
+	}
+}
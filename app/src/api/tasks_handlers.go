@@ -0,0 +1,256 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"tg_todo_bot/src/models"
+	settings_types "tg_todo_bot/src/services/settings/types"
+	tasks_types "tg_todo_bot/src/services/tasks/types"
+	"time"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+type createTaskRequest struct {
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Datetime    *time.Time `json:"datetime"`
+	Priority    int        `json:"priority"`
+	Tags        []string   `json:"tags"`
+}
+
+// optionalDatetime -> tells a missing "datetime" from "datetime": null which removes the date
+type optionalDatetime struct {
+	Value *time.Time
+	IsSet bool
+}
+
+func (datetime *optionalDatetime) UnmarshalJSON(data []byte) error {
+	datetime.IsSet = true
+	return json.Unmarshal(data, &datetime.Value)
+}
+
+type updateTaskRequest struct {
+	Title       *string          `json:"title"`
+	Description *string          `json:"description"`
+	Datetime    optionalDatetime `json:"datetime"`
+	Priority    *int             `json:"priority"`
+	Tags        *[]string        `json:"tags"`
+}
+
+type completeTaskResponse struct {
+	Task           taskResponse   `json:"task"`
+	UnblockedTasks []taskResponse `json:"unblocked_tasks"`
+}
+
+// handleTasks -> GET /api/v1/tasks, POST /api/v1/tasks
+func (server *Server) handleTasks(w http.ResponseWriter, r *http.Request, user models.User) {
+	switch r.Method {
+	case http.MethodGet:
+		server.listTasks(w, r, user)
+	case http.MethodPost:
+		server.createTask(w, r, user)
+	default:
+		server.writeError(w, r, errMethodNotAllowed)
+	}
+}
+
+// handleTask -> /api/v1/tasks/{id}, /api/v1/tasks/{id}/complete and /api/v1/tasks/{id}/notification
+func (server *Server) handleTask(w http.ResponseWriter, r *http.Request, user models.User) {
+	rawTaskID, subresource, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/v1/tasks/"), "/")
+	taskID, err := strconv.ParseInt(rawTaskID, 10, 64)
+	if err != nil {
+		server.writeError(w, r, errRouteNotFound)
+		return
+	}
+
+	//Чужая задача для клиента выглядит так же, как несуществующая
+	task, err := server.findUserTask(r.Context(), user, taskID)
+	if err != nil {
+		server.writeError(w, r, err)
+		return
+	}
+
+	switch {
+	case subresource == "" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, newTaskResponse(task))
+	case subresource == "" && r.Method == http.MethodPatch:
+		server.updateTask(w, r, task)
+	case subresource == "" && r.Method == http.MethodDelete:
+		server.deleteTask(w, r, task)
+	case subresource == "complete" && r.Method == http.MethodPost:
+		server.completeTask(w, r, task)
+	case subresource == "notification":
+		server.handleNotification(w, r, task)
+	case subresource == "" || subresource == "complete":
+		server.writeError(w, r, errMethodNotAllowed)
+	default:
+		server.writeError(w, r, errRouteNotFound)
+	}
+}
+
+// listTasks -> active tasks page by page with ?after=ID&limit=N, or tasks matching ?q=filter query
+func (server *Server) listTasks(w http.ResponseWriter, r *http.Request, user models.User) {
+	limit := defaultPageSize
+	if rawLimit := r.URL.Query().Get("limit"); rawLimit != "" {
+		var err error
+		limit, err = strconv.Atoi(rawLimit)
+		if err != nil || limit < 1 || limit > maxPageSize {
+			server.writeError(w, r, badRequest("limit must be between 1 and "+strconv.Itoa(maxPageSize)))
+			return
+		}
+	}
+
+	if query := r.URL.Query().Get("q"); query != "" {
+		//Относительные даты запроса (due:today) считаются в часовом поясе пользователя, как в боте
+		settings, err := server.settingsService.Get(r.Context(), user.ID)
+		if err != nil {
+			server.writeError(w, r, err)
+			return
+		}
+
+		page, err := server.tasksService.Filter(r.Context(), tasks_types.FilterParams{
+			UserID:      user.ID,
+			Query:       query,
+			Location:    settings_types.Location(settings),
+			DefaultSort: settings.ListSort,
+			Limit:       uint(limit),
+		})
+		if err != nil {
+			server.writeError(w, r, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, tasksPageResponse{Tasks: newTasksResponse(page.Tasks)})
+		return
+	}
+
+	params := tasks_types.PageParams{
+		UserID: user.ID,
+		Limit:  uint(limit),
+	}
+	if rawAfter := r.URL.Query().Get("after"); rawAfter != "" {
+		var err error
+		params.AfterTaskID, err = strconv.ParseInt(rawAfter, 10, 64)
+		if err != nil {
+			server.writeError(w, r, badRequest("after must be a task ID"))
+			return
+		}
+	}
+
+	page, err := server.tasksService.GetActiveForUserPage(r.Context(), params)
+	if err != nil {
+		server.writeError(w, r, err)
+		return
+	}
+
+	response := tasksPageResponse{Tasks: newTasksResponse(page.Tasks)}
+	if page.HasNext {
+		response.NextAfter = &page.Tasks[len(page.Tasks)-1].ID
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (server *Server) createTask(w http.ResponseWriter, r *http.Request, user models.User) {
+	var request createTaskRequest
+	err := readJSON(r, &request)
+	if err != nil {
+		server.writeError(w, r, err)
+		return
+	}
+
+	task, err := server.tasksService.Create(r.Context(), tasks_types.CreateParams{
+		Title:       request.Title,
+		Description: request.Description,
+		Datetime:    request.Datetime,
+		Priority:    request.Priority,
+		Tags:        request.Tags,
+		UserID:      user.ID,
+	})
+	if err != nil {
+		server.writeError(w, r, err)
+		return
+	}
+
+	server.writeTask(w, r, task.ID, http.StatusCreated)
+}
+
+func (server *Server) updateTask(w http.ResponseWriter, r *http.Request, task models.Task) {
+	var request updateTaskRequest
+	err := readJSON(r, &request)
+	if err != nil {
+		server.writeError(w, r, err)
+		return
+	}
+
+	//Done меняется только через /complete, чтобы разблокировать зависимые задачи
+	params := tasks_types.UpdateParams{TaskID: task.ID, Done: task.Done}
+	if request.Title != nil {
+		params.Title.Value, params.Title.IsSet = *request.Title, true
+	}
+	if request.Description != nil {
+		params.Description.Value, params.Description.IsSet = *request.Description, true
+	}
+	if request.Datetime.IsSet {
+		params.Datetime.Value, params.Datetime.IsSet = request.Datetime.Value, true
+	}
+	if request.Priority != nil {
+		params.Priority.Value, params.Priority.IsSet = *request.Priority, true
+	}
+	if request.Tags != nil {
+		params.Tags.Value, params.Tags.IsSet = *request.Tags, true
+	}
+
+	err = server.tasksService.Update(r.Context(), params)
+	if err != nil {
+		server.writeError(w, r, err)
+		return
+	}
+
+	server.writeTask(w, r, task.ID, http.StatusOK)
+}
+
+func (server *Server) deleteTask(w http.ResponseWriter, r *http.Request, task models.Task) {
+	err := server.tasksService.DeleteByID(r.Context(), task.ID)
+	if err != nil {
+		server.writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (server *Server) completeTask(w http.ResponseWriter, r *http.Request, task models.Task) {
+	unblockedTasks, err := server.tasksService.Complete(r.Context(), task.ID)
+	if err != nil {
+		server.writeError(w, r, err)
+		return
+	}
+
+	task, err = server.tasksService.FindByID(r.Context(), task.ID)
+	if err != nil {
+		server.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, completeTaskResponse{
+		Task:           newTaskResponse(task),
+		UnblockedTasks: newTasksResponse(unblockedTasks),
+	})
+}
+
+// writeTask -> the task is read again, so the response has its tags and notification
+func (server *Server) writeTask(w http.ResponseWriter, r *http.Request, taskID int64, status int) {
+	task, err := server.tasksService.FindByID(r.Context(), taskID)
+	if err != nil {
+		server.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, status, newTaskResponse(task))
+}
@@ -0,0 +1,30 @@
+package api
+
+import (
+	"net/http"
+	"tg_todo_bot/src/models"
+	"time"
+)
+
+// handleMe -> GET /api/v1/me, DELETE /api/v1/me requests the deletion of the account like /deleteme:
+// the account is purged after the grace period with the final export, /deleteme in the bot cancels it
+func (server *Server) handleMe(w http.ResponseWriter, r *http.Request, user models.User) {
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, newUserResponse(user))
+	case http.MethodDelete:
+		//Повторный запрос не должен отодвигать срок удаления
+		if user.DeletionRequestedAt == nil {
+			now := time.Now()
+			err := server.usersService.RequestDeletion(r.Context(), user.ID, now)
+			if err != nil {
+				server.writeError(w, r, err)
+				return
+			}
+			user.DeletionRequestedAt = &now
+		}
+		writeJSON(w, http.StatusAccepted, newUserResponse(user))
+	default:
+		server.writeError(w, r, errMethodNotAllowed)
+	}
+}
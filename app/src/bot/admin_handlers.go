@@ -0,0 +1,303 @@
+package bot
+
+import (
+	"context"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/pkg/errors"
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
+	"tg_todo_bot/src/i18n"
+	"tg_todo_bot/src/models"
+	services_types "tg_todo_bot/src/services/types"
+	"time"
+)
+
+const broadcastCallbackPrefix = "broadcast"
+
+// adminOnly (handler) -> the handler for the admin, other users get the same answer as for an unknown command,
+// so admin commands aren't revealed. Admin commands work only in the private chat with the bot
+func (bot *Bot) adminOnly(handler commandHandler) commandHandler {
+	return func(ctx context.Context, message *tgbotapi.Message, user models.User) error {
+		if user.TelegramID != bot.adminID || !message.Chat.IsPrivate() {
+			bot.reply(ctx, message, userLocalizer(user).T("error.unknown_command"))
+			return nil
+		}
+
+		return handler(ctx, message, user)
+	}
+}
+
+// adminOnlyCallback (handler) -> buttons of admin messages are ignored for other users
+func (bot *Bot) adminOnlyCallback(handler callbackHandler) callbackHandler {
+	return func(ctx context.Context, callback *tgbotapi.CallbackQuery, user models.User, data string) error {
+		if user.TelegramID != bot.adminID {
+			return nil
+		}
+
+		return handler(ctx, callback, user, data)
+	}
+}
+
+func (bot *Bot) handleAdmin(ctx context.Context, message *tgbotapi.Message, user models.User) error {
+	bot.reply(ctx, message, userLocalizer(user).T("admin.help"))
+	return nil
+}
+
+// handleStats -> numbers of users for the last day, week and month and the error rate of updates
+func (bot *Bot) handleStats(ctx context.Context, message *tgbotapi.Message, user models.User) error {
+	localizer := userLocalizer(user)
+	now := time.Now()
+
+	stats, err := bot.usersService.GetStats(ctx, now)
+	if err != nil {
+		return errors.Wrap(err, "bot.usersService.GetStats(ctx, now)")
+	}
+
+	total, failed := bot.updatesStats.sum(now)
+	errorRate := 0.0
+	if total > 0 {
+		errorRate = float64(failed) * 100 / float64(total)
+	}
+
+	text := localizer.T("admin.stats",
+		stats.Total, stats.Banned, stats.BotBlocked, stats.DeletionRequested,
+		stats.New[0], stats.New[1], stats.New[2],
+		stats.Active[0], stats.Active[1], stats.Active[2],
+		total, failed, errorRate,
+	)
+	bot.reply(ctx, message, text)
+
+	return nil
+}
+
+// handleBroadcast -> "/broadcast text" shows a preview to confirm, "/broadcast" shows the state of the last broadcast,
+// "/broadcast cancel" stops it
+func (bot *Bot) handleBroadcast(ctx context.Context, message *tgbotapi.Message, user models.User) error {
+	localizer := userLocalizer(user)
+	text := strings.TrimSpace(message.CommandArguments())
+
+	switch text {
+	case "":
+		broadcast, err := bot.broadcastsService.FindLatest(ctx)
+		if err != nil {
+			if errors.Is(err, services_types.ErrNotFound) {
+				bot.reply(ctx, message, localizer.T("admin.broadcast.usage"))
+				return nil
+			}
+			return errors.Wrap(err, "bot.broadcastsService.FindLatest()")
+		}
+		bot.reply(ctx, message, formatBroadcast(localizer, broadcast, userNow(user)))
+		return nil
+	case "cancel":
+		broadcast, err := bot.broadcastsService.FindLatest(ctx)
+		if err == nil {
+			err = bot.broadcastsService.Cancel(ctx, broadcast.ID, time.Now())
+		}
+		if err != nil {
+			if errors.Is(err, services_types.ErrNotFound) {
+				bot.reply(ctx, message, localizer.T("admin.broadcast.nothing_to_cancel"))
+				return nil
+			}
+			return errors.Wrap(err, "bot.broadcastsService.Cancel(broadcastID, now)")
+		}
+		bot.reply(ctx, message, localizer.T("admin.broadcast.cancelled", broadcast.ID))
+		return nil
+	}
+
+	broadcast, err := bot.broadcastsService.Create(ctx, text)
+	if err != nil {
+		if errors.Is(err, services_types.ErrInvalidParams) {
+			bot.reply(ctx, message, localizer.T("admin.broadcast.usage"))
+			return nil
+		}
+		return errors.Wrap(err, "bot.broadcastsService.Create(text)")
+	}
+
+	//Превью отправляется тем же способом, что и рассылка: если Телеграм не принял разметку, не примет и у других
+	idText := strconv.FormatInt(broadcast.ID, 10)
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(localizer.T("admin.broadcast.button.start"), broadcastCallbackPrefix+":start:"+idText),
+		tgbotapi.NewInlineKeyboardButtonData(localizer.T("admin.broadcast.button.cancel"), broadcastCallbackPrefix+":cancel:"+idText),
+	))
+	err = bot.sendWithKeyboard(ctx, message.Chat.ID, broadcast.Text, keyboard)
+
+	var apiErr *tgbotapi.Error
+	if errors.As(err, &apiErr) && apiErr.Code == http.StatusBadRequest {
+		cancelErr := bot.broadcastsService.Cancel(ctx, broadcast.ID, time.Now())
+		if cancelErr != nil {
+			return errors.Wrap(cancelErr, "bot.broadcastsService.Cancel(broadcastID, now)")
+		}
+		bot.reply(ctx, message, localizer.T("admin.broadcast.invalid", html.EscapeString(apiErr.Message)))
+		return nil
+	}
+
+	return err
+}
+
+// handleBroadcastCallback handles "broadcast:start:ID" and "broadcast:cancel:ID" buttons of the preview
+func (bot *Bot) handleBroadcastCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, user models.User, data string) error {
+	localizer := userLocalizer(user)
+	chatID, messageID := callback.Message.Chat.ID, callback.Message.MessageID
+
+	action, idText, _ := strings.Cut(data, ":")
+	broadcastID, err := strconv.ParseInt(idText, 10, 64)
+	if err != nil {
+		return nil
+	}
+
+	var text string
+	switch action {
+	case "start":
+		err = bot.broadcastsService.Start(ctx, broadcastID)
+		text = localizer.T("admin.broadcast.started", broadcastID)
+	case "cancel":
+		err = bot.broadcastsService.Cancel(ctx, broadcastID, time.Now())
+		text = localizer.T("admin.broadcast.cancelled", broadcastID)
+	default:
+		return nil
+	}
+	if errors.Is(err, services_types.ErrNotFound) {
+		//Кнопку нажали повторно или рассылку уже отменили командой
+		text, err = localizer.T("admin.broadcast.not_draft", broadcastID), nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "bot.broadcastsService."+action+"(broadcastID)")
+	}
+
+	//Текст превью остаётся как есть, убираются только кнопки
+	err = bot.send(ctx, chatID, tgbotapi.NewEditMessageReplyMarkup(
+		chatID, messageID, tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}},
+	))
+	if err != nil {
+		return errors.Wrap(err, "bot.send(ctx, chatID, editReplyMarkup)")
+	}
+
+	return bot.SendMessage(ctx, chatID, text)
+}
+
+// SendBroadcast sends the text of the admin's broadcast as is, it's the same for all languages
+func (bot *Bot) SendBroadcast(ctx context.Context, user models.User, text string) error {
+	return bot.SendMessage(ctx, user.TelegramID, text)
+}
+
+// handleBan -> "/ban TELEGRAM_ID [reason]", the reason is shown only in /user
+func (bot *Bot) handleBan(ctx context.Context, message *tgbotapi.Message, user models.User) error {
+	localizer := userLocalizer(user)
+	_, reason, _ := strings.Cut(strings.TrimSpace(message.CommandArguments()), " ")
+
+	target, found, err := bot.findAdminTarget(ctx, message, user)
+	if err != nil || !found {
+		return err
+	}
+	if target.TelegramID == bot.adminID {
+		bot.reply(ctx, message, localizer.T("admin.ban.self"))
+		return nil
+	}
+
+	err = bot.usersService.Ban(ctx, target.ID, strings.TrimSpace(reason), time.Now())
+	if err != nil {
+		return errors.Wrap(err, "bot.usersService.Ban(ctx, userID, reason, now)")
+	}
+	bot.reply(ctx, message, localizer.T("admin.ban.done", target.TelegramID))
+
+	return nil
+}
+
+func (bot *Bot) handleUnban(ctx context.Context, message *tgbotapi.Message, user models.User) error {
+	localizer := userLocalizer(user)
+
+	target, found, err := bot.findAdminTarget(ctx, message, user)
+	if err != nil || !found {
+		return err
+	}
+
+	err = bot.usersService.Unban(ctx, target.ID)
+	if err != nil {
+		return errors.Wrap(err, "bot.usersService.Unban(ctx, userID)")
+	}
+	bot.reply(ctx, message, localizer.T("admin.unban.done", target.TelegramID))
+
+	return nil
+}
+
+// handleUser -> the account of the user and numbers of the user's tasks, without titles of the tasks
+func (bot *Bot) handleUser(ctx context.Context, message *tgbotapi.Message, user models.User) error {
+	localizer, now := userLocalizer(user), userNow(user)
+
+	target, found, err := bot.findAdminTarget(ctx, message, user)
+	if err != nil || !found {
+		return err
+	}
+
+	counts, err := bot.tasksService.CountForUser(ctx, target.ID)
+	if err != nil {
+		return errors.Wrap(err, "bot.tasksService.CountForUser(ctx, userID)")
+	}
+	overdue, err := bot.tasksService.GetOverdueForUser(ctx, target.ID, now)
+	if err != nil {
+		return errors.Wrap(err, "bot.tasksService.GetOverdueForUser(ctx, userID, now)")
+	}
+
+	lines := []string{
+		localizer.T("admin.user.title", target.TelegramID, target.ID),
+		localizer.T("admin.user.created", formatDatetime(localizer, target.CreatedAt, now)),
+		localizer.T("admin.user.last_seen", formatOptionalDatetime(localizer, target.LastSeenAt, now)),
+		localizer.T("admin.user.tasks", counts.Active, counts.Completed, len(overdue)),
+	}
+	if target.BannedAt != nil {
+		lines = append(lines, localizer.T("admin.user.banned", formatDatetime(localizer, *target.BannedAt, now)))
+	}
+	if target.BanReason != "" {
+		lines = append(lines, localizer.T("admin.user.ban_reason", html.EscapeString(target.BanReason)))
+	}
+	if target.DeletionRequestedAt != nil {
+		lines = append(lines, localizer.T("admin.user.deletion_requested", formatDatetime(localizer, *target.DeletionRequestedAt, now)))
+	}
+	bot.reply(ctx, message, strings.Join(lines, "\n"))
+
+	return nil
+}
+
+// findAdminTarget (ctx, message, admin) -> the user by the telegram ID from the first command argument,
+// found is false if the usage or "not found" has been already replied
+func (bot *Bot) findAdminTarget(ctx context.Context, message *tgbotapi.Message, admin models.User) (models.User, bool, error) {
+	localizer := userLocalizer(admin)
+
+	idText, _, _ := strings.Cut(strings.TrimSpace(message.CommandArguments()), " ")
+	telegramID, err := strconv.ParseInt(idText, 10, 64)
+	if err != nil {
+		bot.reply(ctx, message, localizer.T("admin.user_usage", message.Command()))
+		return models.User{}, false, nil
+	}
+
+	target, err := bot.usersService.FindByTelegramID(ctx, telegramID)
+	if err != nil {
+		if errors.Is(err, services_types.ErrNotFound) {
+			bot.reply(ctx, message, localizer.T("admin.user_not_found", telegramID))
+			return models.User{}, false, nil
+		}
+		return models.User{}, false, errors.Wrap(err, "bot.usersService.FindByTelegramID(ctx, telegramID)")
+	}
+
+	return target, true, nil
+}
+
+func formatBroadcast(localizer *i18n.Localizer, broadcast models.Broadcast, now time.Time) string {
+	return localizer.T("admin.broadcast.status",
+		broadcast.ID,
+		localizer.T("admin.broadcast.status."+broadcast.Status),
+		broadcast.Sent, broadcast.Failed,
+		formatDatetime(localizer, broadcast.CreatedAt, now),
+		formatOptionalDatetime(localizer, broadcast.FinishedAt, now),
+	)
+}
+
+func formatOptionalDatetime(localizer *i18n.Localizer, datetime *time.Time, now time.Time) string {
+	if datetime == nil {
+		return "—"
+	}
+
+	return formatDatetime(localizer, *datetime, now)
+}
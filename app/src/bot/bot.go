@@ -0,0 +1,358 @@
+package bot
+
+import (
+	"context"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"strings"
+	"tg_todo_bot/src/i18n"
+	"tg_todo_bot/src/models"
+	services_types "tg_todo_bot/src/services/types"
+	users_types "tg_todo_bot/src/services/users/types"
+	"time"
+)
+
+const updatesTimeout = 60 // seconds
+
+type commandHandler func(ctx context.Context, message *tgbotapi.Message, user models.User) error
+
+// callbackHandler gets callback data without the "prefix:" part
+type callbackHandler func(ctx context.Context, callback *tgbotapi.CallbackQuery, user models.User, data string) error
+
+type Bot struct {
+	logger             *zap.SugaredLogger
+	api                *tgbotapi.BotAPI //only for updates and answers to callbacks and inline queries, messages go through sendQueue
+	sendQueue          SendQueueI
+	usersService       UsersServiceI
+	tasksService       TasksServiceI
+	filtersService     FiltersServiceI
+	escalationsService EscalationsServiceI
+	settingsService    SettingsServiceI
+	tokensService      TokensServiceI
+	webhooksService    WebhooksServiceI
+	broadcastsService  BroadcastsServiceI
+	rateLimiter        RateLimiterI
+	apiURL             string //public URL of the API server, links to calendar feeds are built from it
+	adminID            int64  //telegram ID of the only user allowed to run admin commands
+	updatesStats       *updatesStats
+}
+
+func NewBot(
+	logger *zap.SugaredLogger,
+	api *tgbotapi.BotAPI,
+	sendQueue SendQueueI,
+	usersService UsersServiceI,
+	tasksService TasksServiceI,
+	filtersService FiltersServiceI,
+	escalationsService EscalationsServiceI,
+	settingsService SettingsServiceI,
+	tokensService TokensServiceI,
+	webhooksService WebhooksServiceI,
+	broadcastsService BroadcastsServiceI,
+	rateLimiter RateLimiterI,
+	apiURL string,
+	adminID int64,
+) *Bot {
+	return &Bot{
+		logger:             logger,
+		api:                api,
+		sendQueue:          sendQueue,
+		usersService:       usersService,
+		tasksService:       tasksService,
+		filtersService:     filtersService,
+		escalationsService: escalationsService,
+		settingsService:    settingsService,
+		tokensService:      tokensService,
+		webhooksService:    webhooksService,
+		broadcastsService:  broadcastsService,
+		rateLimiter:        rateLimiter,
+		apiURL:             apiURL,
+		adminID:            adminID,
+		updatesStats:       &updatesStats{},
+	}
+}
+
+func (bot *Bot) commands() map[string]commandHandler {
+	return map[string]commandHandler{
+		"start":    bot.handleStart,
+		"help":     bot.handleStart,
+		"add":      bot.handleAdd,
+		"today":    bot.handleToday,
+		"done":     bot.handleDone,
+		"block":    bot.handleBlock,
+		"unblock":  bot.handleUnblock,
+		"search":   bot.handleSearch,
+		"list":     bot.handleList,
+		"f":        bot.handleFilter,
+		"fsave":    bot.handleFilterSave,
+		"fdel":     bot.handleFilterDelete,
+		"overdue":  bot.handleOverdue,
+		"escalate": bot.handleEscalate,
+		"settings": bot.handleSettings,
+		"token":    bot.handleToken,
+		"webhook":  bot.handleWebhook,
+		"ical":     bot.handleCalendar,
+		"caldav":   bot.handleCalDAV,
+		"import":   bot.handleImport,
+		"export":   bot.handleExport,
+		"deleteme": bot.handleDeleteMe,
+
+		"admin":     bot.adminOnly(bot.handleAdmin),
+		"stats":     bot.adminOnly(bot.handleStats),
+		"broadcast": bot.adminOnly(bot.handleBroadcast),
+		"ban":       bot.adminOnly(bot.handleBan),
+		"unban":     bot.adminOnly(bot.handleUnban),
+		"user":      bot.adminOnly(bot.handleUser),
+	}
+}
+
+func (bot *Bot) callbacks() map[string]callbackHandler {
+	return map[string]callbackHandler{
+		listCallbackPrefix:     bot.handleListCallback,
+		settingsCallbackPrefix: bot.handleSettingsCallback,
+		importCallbackPrefix:   bot.handleImportCallback,
+		exportCallbackPrefix:   bot.handleExportCallback,
+		deleteMeCallbackPrefix: bot.handleDeleteMeCallback,
+
+		broadcastCallbackPrefix: bot.adminOnlyCallback(bot.handleBroadcastCallback),
+	}
+}
+
+// Run reads updates until ctx is cancelled
+func (bot *Bot) Run(ctx context.Context) {
+	updateConfig := tgbotapi.NewUpdate(0)
+	updateConfig.Timeout = updatesTimeout
+
+	updates := bot.api.GetUpdatesChan(updateConfig)
+	for {
+		select {
+		case <-ctx.Done():
+			bot.api.StopReceivingUpdates()
+			return
+		case update := <-updates:
+			bot.handleUpdate(ctx, update)
+		}
+	}
+}
+
+func (bot *Bot) handleUpdate(ctx context.Context, update tgbotapi.Update) {
+	if update.InlineQuery != nil {
+		bot.handleInlineQuery(ctx, update.InlineQuery)
+		return
+	}
+
+	if update.CallbackQuery != nil {
+		bot.handleCallbackQuery(ctx, update.CallbackQuery)
+		return
+	}
+
+	message := update.Message
+	if message == nil || message.From == nil {
+		return
+	}
+
+	command := message.Command()
+	//Файл, присланный боту в личку, импортируется как задачи
+	if command == "" && message.Document != nil && message.Chat.IsPrivate() {
+		command = "import"
+	}
+	if command == "" {
+		return
+	}
+
+	bot.logger.Infow("Bot -> handleUpdate", "command", command, "telegramID", message.From.ID)
+
+	//Пока пользователь не загружен, язык берётся из клиента Телеграма
+	clientLocalizer := i18n.New(i18n.FromTelegram(message.From.LanguageCode))
+
+	handler, exist := bot.commands()[command]
+	if !exist {
+		bot.reply(ctx, message, clientLocalizer.T("error.unknown_command"))
+		return
+	}
+
+	user, err := bot.getOrCreateUser(ctx, message.From.ID)
+	if err != nil {
+		bot.logger.Errorw(
+			"Bot -> handleUpdate -> bot.getOrCreateUser(ctx, telegramID)",
+			"error", err.Error(), "telegramID", message.From.ID,
+		)
+		bot.updatesStats.record(time.Now(), true)
+		bot.reply(ctx, message, clientLocalizer.T(errorKey(err)))
+		return
+	}
+
+	run := func(ctx context.Context, user models.User) error {
+		return handler(ctx, message, user)
+	}
+	reply := func(text string) {
+		bot.reply(ctx, message, text)
+	}
+	err = bot.withMiddlewares(run, reply)(ctx, user)
+	bot.updatesStats.record(time.Now(), err != nil)
+	if err != nil {
+		bot.logger.Errorw(
+			"Bot -> handleUpdate -> handler(message, user)",
+			"error", err.Error(), "command", command, "userID", user.ID,
+		)
+		bot.reply(ctx, message, userLocalizer(user).T(errorKey(err)))
+	}
+}
+
+func (bot *Bot) handleCallbackQuery(ctx context.Context, callback *tgbotapi.CallbackQuery) {
+	//Телеграм показывает часики на кнопке, пока не получит ответ. Ответ middleware показывается всплывающим текстом
+	answer := ""
+	defer func() {
+		bot.answerCallback(callback, answer)
+	}()
+
+	if callback.From == nil || callback.Message == nil {
+		return
+	}
+
+	prefix, data, _ := strings.Cut(callback.Data, ":")
+	handler, exist := bot.callbacks()[prefix]
+	if !exist {
+		return
+	}
+
+	user, err := bot.getOrCreateUser(ctx, callback.From.ID)
+	if err != nil {
+		bot.logger.Errorw(
+			"Bot -> handleCallbackQuery -> bot.getOrCreateUser(ctx, telegramID)",
+			"error", err.Error(), "telegramID", callback.From.ID,
+		)
+		bot.updatesStats.record(time.Now(), true)
+		return
+	}
+
+	run := func(ctx context.Context, user models.User) error {
+		return handler(ctx, callback, user, data)
+	}
+	reply := func(text string) {
+		answer = text
+	}
+	err = bot.withMiddlewares(run, reply)(ctx, user)
+	bot.updatesStats.record(time.Now(), err != nil)
+	if err != nil {
+		bot.logger.Errorw(
+			"Bot -> handleCallbackQuery -> handler(callback, user, data)",
+			"error", err.Error(), "data", callback.Data, "userID", user.ID,
+		)
+	}
+}
+
+// errorKey (err) -> the answer to an update which failed, a timeout of the database is worth retrying soon
+func errorKey(err error) string {
+	if errors.Is(err, services_types.ErrTimeout) {
+		return "error.timeout"
+	}
+
+	return "error.internal"
+}
+
+func (bot *Bot) answerCallback(callback *tgbotapi.CallbackQuery, text string) {
+	_, err := bot.api.Request(tgbotapi.NewCallback(callback.ID, text))
+	if err != nil {
+		bot.logger.Errorw(
+			"Bot -> answerCallback -> bot.api.Request(callbackConfig)",
+			"error", err.Error(), "callbackID", callback.ID,
+		)
+	}
+}
+
+// getOrCreateUser (ctx, telegramID) -> the user with loaded settings
+func (bot *Bot) getOrCreateUser(ctx context.Context, telegramID int64) (models.User, error) {
+	user, err := bot.usersService.FindByTelegramID(ctx, telegramID)
+	if errors.Is(err, services_types.ErrNotFound) {
+		err = bot.usersService.Create(ctx, users_types.CreateParams{TelegramID: telegramID})
+		if err != nil {
+			return models.User{}, err
+		}
+
+		user, err = bot.usersService.FindByTelegramID(ctx, telegramID)
+	}
+	if err != nil {
+		return models.User{}, err
+	}
+
+	settings, err := bot.settingsService.Get(ctx, user.ID)
+	if err != nil {
+		return models.User{}, err
+	}
+	user.Settings = &settings
+
+	return user, nil
+}
+
+// findUserTask (ctx, user, taskID) -> return services_types.ErrNotFound if the task belongs to another user
+func (bot *Bot) findUserTask(ctx context.Context, user models.User, taskID int64) (models.Task, error) {
+	task, err := bot.tasksService.FindByID(ctx, taskID)
+	if err != nil {
+		return models.Task{}, err
+	}
+
+	if task.UserID != user.ID {
+		return models.Task{}, services_types.ErrNotFound
+	}
+
+	return task, nil
+}
+
+func (bot *Bot) reply(ctx context.Context, message *tgbotapi.Message, text string) {
+	err := bot.SendMessage(ctx, message.Chat.ID, text)
+	if err != nil {
+		bot.logger.Errorw(
+			"Bot -> reply -> bot.SendMessage(ctx, chatID, text)",
+			"error", err.Error(), "chatID", message.Chat.ID,
+		)
+	}
+}
+
+// sendWithKeyboard sends HTML formatted text with inline keyboard
+func (bot *Bot) sendWithKeyboard(ctx context.Context, chatID int64, text string, keyboard tgbotapi.InlineKeyboardMarkup) error {
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = tgbotapi.ModeHTML
+	if len(keyboard.InlineKeyboard) > 0 {
+		msg.ReplyMarkup = keyboard
+	}
+
+	err := bot.send(ctx, chatID, msg)
+	if err != nil {
+		return errors.Wrap(err, "bot.send(ctx, chatID, msg)")
+	}
+
+	return nil
+}
+
+// editWithKeyboard replaces text and inline keyboard of the already sent message
+func (bot *Bot) editWithKeyboard(ctx context.Context, chatID int64, messageID int, text string, keyboard tgbotapi.InlineKeyboardMarkup) error {
+	if keyboard.InlineKeyboard == nil {
+		//null вместо пустого массива Телеграм не принимает
+		keyboard.InlineKeyboard = [][]tgbotapi.InlineKeyboardButton{}
+	}
+
+	edit := tgbotapi.NewEditMessageTextAndMarkup(chatID, messageID, text, keyboard)
+	edit.ParseMode = tgbotapi.ModeHTML
+
+	err := bot.send(ctx, chatID, edit)
+	if err != nil {
+		return errors.Wrap(err, "bot.send(ctx, chatID, edit)")
+	}
+
+	return nil
+}
+
+// SendMessage sends HTML formatted text, for private chats chatID is equal to the user's telegram ID
+func (bot *Bot) SendMessage(ctx context.Context, chatID int64, text string) error {
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = tgbotapi.ModeHTML
+
+	err := bot.send(ctx, chatID, msg)
+	if err != nil {
+		return errors.Wrap(err, "bot.send(ctx, chatID, msg)")
+	}
+
+	return nil
+}
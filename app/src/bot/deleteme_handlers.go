@@ -0,0 +1,101 @@
+package bot
+
+import (
+	"context"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/pkg/errors"
+	"tg_todo_bot/src/exporters"
+	"tg_todo_bot/src/models"
+	"tg_todo_bot/src/sendqueue"
+	users_types "tg_todo_bot/src/services/users/types"
+	"time"
+)
+
+const deleteMeCallbackPrefix = "deleteme"
+
+// handleDeleteMe -> "/deleteme" asks to confirm the deletion of the account,
+// during the grace period it shows when the account will be deleted and lets cancel the deletion
+func (bot *Bot) handleDeleteMe(ctx context.Context, message *tgbotapi.Message, user models.User) error {
+	localizer := userLocalizer(user)
+
+	//Подтверждение в группе может нажать кто угодно из участников
+	if !message.Chat.IsPrivate() {
+		bot.reply(ctx, message, localizer.T("deleteme.private_only"))
+		return nil
+	}
+
+	purgeAt := users_types.PurgeAt(user)
+	if purgeAt != nil {
+		text := localizer.T("deleteme.pending", formatDatetime(localizer, *purgeAt, userNow(user)))
+		return bot.sendWithKeyboard(ctx, message.Chat.ID, text, deleteMeCancelKeyboard(user))
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(localizer.T("deleteme.button.confirm"), deleteMeCallbackPrefix+":confirm"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(localizer.T("deleteme.button.keep"), deleteMeCallbackPrefix+":keep"),
+		),
+	)
+	text := localizer.N("deleteme.confirm", int(users_types.DeletionGracePeriod/(24*time.Hour)))
+
+	return bot.sendWithKeyboard(ctx, message.Chat.ID, text, keyboard)
+}
+
+// handleDeleteMeCallback handles "deleteme:confirm", "deleteme:keep" and "deleteme:cancel" buttons
+func (bot *Bot) handleDeleteMeCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, user models.User, data string) error {
+	localizer := userLocalizer(user)
+	chatID, messageID := callback.Message.Chat.ID, callback.Message.MessageID
+	noKeyboard := tgbotapi.InlineKeyboardMarkup{}
+
+	switch data {
+	case "confirm":
+		//Повторное нажатие не должно отодвигать срок удаления
+		if user.DeletionRequestedAt == nil {
+			now := time.Now()
+			err := bot.usersService.RequestDeletion(ctx, user.ID, now)
+			if err != nil {
+				return errors.Wrap(err, "bot.usersService.RequestDeletion(ctx, userID, now)")
+			}
+			user.DeletionRequestedAt = &now
+		}
+
+		purgeAt := users_types.PurgeAt(user)
+		text := localizer.T("deleteme.requested", formatDatetime(localizer, *purgeAt, userNow(user)))
+		return bot.editWithKeyboard(ctx, chatID, messageID, text, deleteMeCancelKeyboard(user))
+	case "cancel":
+		err := bot.usersService.CancelDeletion(ctx, user.ID)
+		if err != nil {
+			return errors.Wrap(err, "bot.usersService.CancelDeletion(ctx, userID)")
+		}
+		return bot.editWithKeyboard(ctx, chatID, messageID, localizer.T("deleteme.cancelled"), noKeyboard)
+	default:
+		return bot.editWithKeyboard(ctx, chatID, messageID, localizer.T("deleteme.kept"), noKeyboard)
+	}
+}
+
+func deleteMeCancelKeyboard(user models.User) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(userLocalizer(user).T("deleteme.button.cancel"), deleteMeCallbackPrefix+":cancel"),
+	))
+}
+
+// SendFinalExport sends the JSON export of all tasks before the account is purged.
+// If the user blocked the bot, there is nobody to send it to and the account is purged anyway
+func (bot *Bot) SendFinalExport(ctx context.Context, user models.User) error {
+	user, err := bot.withSettings(ctx, user)
+	if err != nil {
+		return err
+	}
+
+	caption := userLocalizer(user).T("deleteme.final_export")
+	err = bot.sendExport(ctx, user.TelegramID, user, exporters.FormatJSON, caption)
+
+	if errors.Is(err, sendqueue.ErrBlocked) {
+		bot.logger.Infow("Bot -> SendFinalExport -> the bot is blocked by the user", "userID", user.ID)
+		return nil
+	}
+
+	return err
+}
@@ -0,0 +1,88 @@
+package bot
+
+import (
+	"context"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/pkg/errors"
+	"html"
+	"strings"
+	"tg_todo_bot/src/models"
+	tasks_types "tg_todo_bot/src/services/tasks/types"
+	services_types "tg_todo_bot/src/services/types"
+)
+
+// parseDependencyArgs parses "ID BLOCKER_ID" and checks that both tasks belong to the user
+func (bot *Bot) parseDependencyArgs(ctx context.Context, message *tgbotapi.Message, user models.User) (tasks_types.DependencyParams, bool, error) {
+	localizer := userLocalizer(user)
+	args := strings.Fields(message.CommandArguments())
+	if len(args) != 2 {
+		bot.reply(ctx, message, localizer.T("dependency.usage", message.Command()))
+		return tasks_types.DependencyParams{}, false, nil
+	}
+
+	var tasksIDs [2]int64
+	for i, arg := range args {
+		taskID, err := parseTaskID(arg)
+		if err != nil {
+			bot.reply(ctx, message, localizer.T("dependency.wrong_id", html.EscapeString(arg)))
+			return tasks_types.DependencyParams{}, false, nil
+		}
+
+		_, err = bot.findUserTask(ctx, user, taskID)
+		if err != nil {
+			if errors.Is(err, services_types.ErrNotFound) {
+				bot.reply(ctx, message, localizer.T("dependency.not_found", taskID))
+				return tasks_types.DependencyParams{}, false, nil
+			}
+			return tasks_types.DependencyParams{}, false, errors.Wrap(err, "bot.findUserTask(ctx, user, taskID)")
+		}
+
+		tasksIDs[i] = taskID
+	}
+
+	if tasksIDs[0] == tasksIDs[1] {
+		bot.reply(ctx, message, localizer.T("dependency.self"))
+		return tasks_types.DependencyParams{}, false, nil
+	}
+
+	return tasks_types.DependencyParams{
+		TaskID:          tasksIDs[0],
+		BlockedByTaskID: tasksIDs[1],
+	}, true, nil
+}
+
+func (bot *Bot) handleBlock(ctx context.Context, message *tgbotapi.Message, user models.User) error {
+	params, ok, err := bot.parseDependencyArgs(ctx, message, user)
+	if !ok || err != nil {
+		return err
+	}
+
+	err = bot.tasksService.AddDependency(ctx, params)
+	if err != nil {
+		if errors.Is(err, services_types.ErrDependencyCycle) {
+			bot.reply(ctx, message, userLocalizer(user).T("dependency.cycle", params.BlockedByTaskID, params.TaskID))
+			return nil
+		}
+		return errors.Wrap(err, "bot.tasksService.AddDependency(ctx, params)")
+	}
+
+	bot.reply(ctx, message, userLocalizer(user).T("dependency.blocked", params.TaskID, params.BlockedByTaskID))
+
+	return nil
+}
+
+func (bot *Bot) handleUnblock(ctx context.Context, message *tgbotapi.Message, user models.User) error {
+	params, ok, err := bot.parseDependencyArgs(ctx, message, user)
+	if !ok || err != nil {
+		return err
+	}
+
+	err = bot.tasksService.RemoveDependency(ctx, params)
+	if err != nil {
+		return errors.Wrap(err, "bot.tasksService.RemoveDependency(ctx, params)")
+	}
+
+	bot.reply(ctx, message, userLocalizer(user).T("dependency.unblocked", params.TaskID, params.BlockedByTaskID))
+
+	return nil
+}
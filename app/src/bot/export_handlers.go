@@ -0,0 +1,127 @@
+package bot
+
+import (
+	"context"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/pkg/errors"
+	"io"
+	"strings"
+	"tg_todo_bot/src/exporters"
+	"tg_todo_bot/src/models"
+	"time"
+)
+
+const exportCallbackPrefix = "export"
+
+// handleExport -> "/export" shows the formats, "/export json|csv|markdown" sends the file right away
+func (bot *Bot) handleExport(ctx context.Context, message *tgbotapi.Message, user models.User) error {
+	localizer := userLocalizer(user)
+
+	//В файле все задачи пользователя
+	if !message.Chat.IsPrivate() {
+		bot.reply(ctx, message, localizer.T("export.private_only"))
+		return nil
+	}
+
+	format := strings.ToLower(strings.TrimSpace(message.CommandArguments()))
+	if format == "md" {
+		format = exporters.FormatMarkdown
+	}
+	if format == "" {
+		var buttons []tgbotapi.InlineKeyboardButton
+		for _, format := range exporters.Formats {
+			buttons = append(buttons, tgbotapi.NewInlineKeyboardButtonData(
+				localizer.T("export.format."+format), exportCallbackPrefix+":"+format,
+			))
+		}
+		return bot.sendWithKeyboard(ctx, message.Chat.ID, localizer.T("export.usage"), tgbotapi.NewInlineKeyboardMarkup(buttons))
+	}
+	if !isExportFormat(format) {
+		bot.reply(ctx, message, localizer.T("export.usage"))
+		return nil
+	}
+
+	return bot.sendExport(ctx, message.Chat.ID, user, format, localizer.T("export.caption."+format))
+}
+
+// handleExportCallback handles "export:<format>" buttons of "/export"
+func (bot *Bot) handleExportCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, user models.User, data string) error {
+	localizer := userLocalizer(user)
+	chatID, messageID := callback.Message.Chat.ID, callback.Message.MessageID
+	noKeyboard := tgbotapi.InlineKeyboardMarkup{}
+
+	if !isExportFormat(data) {
+		return nil
+	}
+
+	//Кнопки убираются до выгрузки, чтобы двойное нажатие не прислало файл дважды
+	err := bot.editWithKeyboard(ctx, chatID, messageID, localizer.T("export.in_progress"), noKeyboard)
+	if err != nil {
+		return err
+	}
+
+	err = bot.sendExport(ctx, chatID, user, data, localizer.T("export.caption."+data))
+	if err != nil {
+		_ = bot.editWithKeyboard(ctx, chatID, messageID, localizer.T("error.internal"), noKeyboard)
+		return err
+	}
+
+	return bot.editWithKeyboard(ctx, chatID, messageID, localizer.T("export.done"), noKeyboard)
+}
+
+// sendExport (ctx, chatID, user, format, caption) -> sends all tasks of the user as a document.
+// The file is written into a pipe while it's uploaded, so only one page of tasks is in memory
+func (bot *Bot) sendExport(ctx context.Context, chatID int64, user models.User, format, caption string) error {
+	now := userNow(user)
+	reader, writer := io.Pipe()
+
+	exportErr := make(chan error, 1)
+	go func() {
+		err := bot.writeExport(ctx, writer, user, format, now)
+		writer.CloseWithError(err)
+		exportErr <- err
+	}()
+
+	document := tgbotapi.NewDocument(chatID, tgbotapi.FileReader{Name: exporters.FileName(format, now), Reader: reader})
+	document.Caption = caption
+
+	//Файл читается из pipe, повторить загрузку нельзя
+	err := bot.sendOnce(ctx, chatID, document)
+	//Если загрузка оборвалась, запись в канал завершится ошибкой, а не зависнет
+	_ = reader.Close()
+
+	writeErr := <-exportErr
+	if writeErr != nil && !errors.Is(writeErr, io.ErrClosedPipe) {
+		return writeErr
+	}
+	if err != nil {
+		return errors.Wrap(err, "bot.sendOnce(ctx, chatID, document)")
+	}
+
+	return nil
+}
+
+// writeExport (ctx, w, user, format, now) -> writes tasks of the user page by page as they are loaded
+func (bot *Bot) writeExport(ctx context.Context, w io.Writer, user models.User, format string, now time.Time) error {
+	writer, err := exporters.NewWriter(format, w, exporters.Params{Location: now.Location(), Now: now})
+	if err != nil {
+		return errors.Wrap(err, "exporters.NewWriter(format, w, params)")
+	}
+
+	err = bot.tasksService.ForEachPageForUser(ctx, user.ID, writer.Write)
+	if err != nil {
+		return errors.Wrap(err, "bot.tasksService.ForEachPageForUser(ctx, userID, handle)")
+	}
+
+	return writer.Close()
+}
+
+func isExportFormat(format string) bool {
+	for _, exportFormat := range exporters.Formats {
+		if format == exportFormat {
+			return true
+		}
+	}
+
+	return false
+}
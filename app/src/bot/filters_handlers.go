@@ -0,0 +1,156 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/pkg/errors"
+	"html"
+	"strings"
+	"tg_todo_bot/src/i18n"
+	"tg_todo_bot/src/models"
+	filters_types "tg_todo_bot/src/services/filters/types"
+	tasks_types "tg_todo_bot/src/services/tasks/types"
+	services_types "tg_todo_bot/src/services/types"
+)
+
+const filterResultsLimit = 30
+
+// handleFilter -> "/f" lists saved filters, "/f name" shows tasks matching the saved filter
+func (bot *Bot) handleFilter(ctx context.Context, message *tgbotapi.Message, user models.User) error {
+	localizer := userLocalizer(user)
+	name := strings.TrimSpace(message.CommandArguments())
+	if name == "" {
+		return bot.replyWithSavedFilters(ctx, message, user)
+	}
+
+	savedFilter, err := bot.filtersService.FindByName(ctx, user.ID, name)
+	if err != nil {
+		if errors.Is(err, services_types.ErrNotFound) {
+			bot.reply(ctx, message, localizer.T("filter.not_found", html.EscapeString(name)))
+			return nil
+		}
+		return errors.Wrap(err, "bot.filtersService.FindByName(userID, name)")
+	}
+
+	return bot.replyWithFilteredTasks(ctx, message, user, savedFilter.Query)
+}
+
+func (bot *Bot) handleFilterSave(ctx context.Context, message *tgbotapi.Message, user models.User) error {
+	localizer := userLocalizer(user)
+	name, query, _ := strings.Cut(strings.TrimSpace(message.CommandArguments()), " ")
+	query = strings.TrimSpace(query)
+	if name == "" || query == "" {
+		bot.reply(ctx, message, localizer.T("filter.save_usage"))
+		return nil
+	}
+
+	err := bot.filtersService.Save(ctx, filters_types.SaveParams{
+		UserID: user.ID,
+		Name:   name,
+		Query:  query,
+	})
+	if err != nil {
+		var syntaxErr *tasks_types.FilterSyntaxError
+		if errors.As(err, &syntaxErr) {
+			bot.reply(ctx, message, formatFilterSyntaxError(localizer, syntaxErr))
+			return nil
+		}
+		//Остальные ошибки валидации касаются имени
+		bot.reply(ctx, message, localizer.T("filter.wrong_name"))
+		return nil
+	}
+
+	bot.reply(ctx, message, localizer.T("filter.saved", html.EscapeString(strings.ToLower(name))))
+
+	return nil
+}
+
+func (bot *Bot) handleFilterDelete(ctx context.Context, message *tgbotapi.Message, user models.User) error {
+	localizer := userLocalizer(user)
+	name := strings.TrimSpace(message.CommandArguments())
+	if name == "" {
+		bot.reply(ctx, message, localizer.T("filter.delete_usage"))
+		return nil
+	}
+
+	err := bot.filtersService.DeleteByName(ctx, user.ID, name)
+	if err != nil {
+		return errors.Wrap(err, "bot.filtersService.DeleteByName(userID, name)")
+	}
+
+	bot.reply(ctx, message, localizer.T("filter.deleted", html.EscapeString(name)))
+
+	return nil
+}
+
+func (bot *Bot) replyWithSavedFilters(ctx context.Context, message *tgbotapi.Message, user models.User) error {
+	localizer := userLocalizer(user)
+	savedFilters, err := bot.filtersService.GetAllForUser(ctx, user.ID)
+	if err != nil {
+		return errors.Wrap(err, "bot.filtersService.GetAllForUser(userID)")
+	}
+
+	if len(savedFilters) == 0 {
+		bot.reply(ctx, message, localizer.T("filter.list_empty"))
+		return nil
+	}
+
+	lines := []string{localizer.T("filter.list_title")}
+	for _, savedFilter := range savedFilters {
+		lines = append(lines, fmt.Sprintf(
+			"/f %s — <code>%s</code>",
+			html.EscapeString(savedFilter.Name),
+			html.EscapeString(savedFilter.Query),
+		))
+	}
+	bot.reply(ctx, message, strings.Join(lines, "\n"))
+
+	return nil
+}
+
+func (bot *Bot) replyWithFilteredTasks(ctx context.Context, message *tgbotapi.Message, user models.User, query string) error {
+	localizer, now := userLocalizer(user), userNow(user)
+	page, err := bot.tasksService.Filter(ctx, tasks_types.FilterParams{
+		UserID:      user.ID,
+		Query:       query,
+		Location:    now.Location(),
+		DefaultSort: user.Settings.ListSort,
+		Limit:       filterResultsLimit,
+	})
+	if err != nil {
+		var syntaxErr *tasks_types.FilterSyntaxError
+		if errors.As(err, &syntaxErr) {
+			bot.reply(ctx, message, formatFilterSyntaxError(localizer, syntaxErr))
+			return nil
+		}
+		return errors.Wrap(err, "bot.tasksService.Filter(ctx, params)")
+	}
+
+	if len(page.Tasks) == 0 {
+		bot.reply(ctx, message, localizer.T("filter.empty"))
+		return nil
+	}
+
+	lines := []string{localizer.T("filter.title", html.EscapeString(query))}
+	for _, task := range page.Tasks {
+		switch {
+		case task.Done:
+			lines = append(lines, "✅ <s>"+formatTask(localizer, task, now)+"</s>")
+		case len(task.BlockedBy) > 0:
+			lines = append(lines, formatBlockedTask(localizer, task, now))
+		default:
+			lines = append(lines, formatTask(localizer, task, now))
+		}
+	}
+	if page.HasNext {
+		lines = append(lines, localizer.N("filter.more", len(page.Tasks)))
+	}
+	bot.reply(ctx, message, strings.Join(lines, "\n"))
+
+	return nil
+}
+
+func formatFilterSyntaxError(localizer *i18n.Localizer, syntaxErr *tasks_types.FilterSyntaxError) string {
+	return localizer.T("filter.syntax_error", html.EscapeString(syntaxErr.Token), html.EscapeString(syntaxErr.Reason))
+}
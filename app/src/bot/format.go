@@ -0,0 +1,210 @@
+package bot
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"tg_todo_bot/src/i18n"
+	"tg_todo_bot/src/models"
+	settings_types "tg_todo_bot/src/services/settings/types"
+	tasks_types "tg_todo_bot/src/services/tasks/types"
+	"time"
+)
+
+// Layouts of dates in command arguments, output is localized with i18n
+const (
+	dateLayout     = "02.01.2006"
+	datetimeLayout = "02.01.2006 15:04"
+	timeLayout     = "15:04"
+)
+
+// userNow (user) -> current time in the user's timezone
+func userNow(user models.User) time.Time {
+	if user.Settings == nil {
+		return time.Now().In(time.UTC)
+	}
+
+	return time.Now().In(settings_types.Location(*user.Settings))
+}
+
+// userLocalizer (user) -> localizer of the language from the user's settings
+func userLocalizer(user models.User) *i18n.Localizer {
+	if user.Settings == nil {
+		return i18n.New(i18n.DefaultLanguage)
+	}
+
+	return i18n.New(user.Settings.Language)
+}
+
+// formatTask (localizer, task, now) -> now is in the user's timezone, the task's time is shown in it
+func formatTask(localizer *i18n.Localizer, task models.Task, now time.Time) string {
+	var builder strings.Builder
+
+	if tasks_types.IsOverdue(task, now) {
+		builder.WriteString("⚠️ ")
+	}
+	builder.WriteString(fmt.Sprintf("#%d ", task.ID))
+	if task.Priority > 0 {
+		builder.WriteString(fmt.Sprintf("<b>!%d</b> ", task.Priority))
+	}
+	if task.Datetime != nil {
+		builder.WriteString(formatDatetime(localizer, *task.Datetime, now))
+		builder.WriteString(" ")
+	}
+	builder.WriteString(html.EscapeString(task.Title))
+	for _, tag := range task.Tags {
+		builder.WriteString(" #" + html.EscapeString(tag))
+	}
+	if task.Notification != nil {
+		builder.WriteString(" 🔔")
+	}
+
+	return builder.String()
+}
+
+// formatDatetime (localizer, datetime, now) -> only time for today, date and time otherwise,
+// without time for date-only tasks and without year for the current year
+func formatDatetime(localizer *i18n.Localizer, datetime time.Time, now time.Time) string {
+	datetime = datetime.In(now.Location())
+
+	date := localizer.Date(datetime)
+	if datetime.Year() == now.Year() {
+		date = localizer.ShortDate(datetime)
+	}
+
+	hour, minute, second := datetime.Clock()
+	if hour == 0 && minute == 0 && second == 0 {
+		return date
+	}
+
+	if datetime.Year() == now.Year() && datetime.YearDay() == now.YearDay() {
+		return localizer.Time(datetime)
+	}
+
+	return date + " " + localizer.Time(datetime)
+}
+
+func formatBlockedTask(localizer *i18n.Localizer, task models.Task, now time.Time) string {
+	var blockers []string
+	for _, blockerID := range task.BlockedBy {
+		blockers = append(blockers, "#"+strconv.FormatInt(blockerID, 10))
+	}
+
+	return localizer.T("task.blocked", formatTask(localizer, task, now), strings.Join(blockers, ", "))
+}
+
+// parseTaskID parses "#12" or "12"
+func parseTaskID(arg string) (int64, error) {
+	return strconv.ParseInt(strings.TrimPrefix(arg, "#"), 10, 64)
+}
+
+// parseDatetimePrefix (args) -> splits "20.10.2026 15:00 Title" into datetime, hasTime and the rest,
+// datetime is nil if args don't start with a date
+func parseDatetimePrefix(args string, location *time.Location) (*time.Time, bool, string) {
+	fields := strings.Fields(args)
+
+	if len(fields) >= 2 {
+		datetime, err := time.ParseInLocation(datetimeLayout, fields[0]+" "+fields[1], location)
+		if err == nil {
+			return &datetime, true, strings.Join(fields[2:], " ")
+		}
+	}
+
+	if len(fields) >= 1 {
+		date, err := time.ParseInLocation(dateLayout, fields[0], location)
+		if err == nil {
+			return &date, false, strings.Join(fields[1:], " ")
+		}
+	}
+
+	return nil, false, strings.Join(fields, " ")
+}
+
+// parseTitleMarks ("Купить молоко #дом !1") -> ("Купить молоко", ["дом"], 1),
+// "!N" outside of 1-3 stays in the title
+func parseTitleMarks(args string) (string, []string, int) {
+	var (
+		words    []string
+		tags     []string
+		priority int
+	)
+
+	for _, word := range strings.Fields(args) {
+		if len(word) > 1 && strings.HasPrefix(word, "#") {
+			tags = append(tags, word[1:])
+			continue
+		}
+
+		if len(word) == 2 && word[0] == '!' && word[1] >= '1' && word[1] <= '3' {
+			priority = int(word[1] - '0')
+			continue
+		}
+
+		words = append(words, word)
+	}
+
+	return strings.Join(words, " "), tags, priority
+}
+
+var durationUnits = map[byte]time.Duration{
+	'm': time.Minute,
+	'h': time.Hour,
+	'd': 24 * time.Hour,
+	'w': 7 * 24 * time.Hour,
+}
+
+// parseDuration ("30m") -> 30 * time.Minute, units: m, h, d, w
+func parseDuration(value string) (time.Duration, error) {
+	if len(value) < 2 {
+		return 0, fmt.Errorf("expected duration like 1h")
+	}
+
+	unit, exist := durationUnits[value[len(value)-1]]
+	if !exist {
+		return 0, fmt.Errorf("unknown duration unit, expected m, h, d or w")
+	}
+
+	amount, err := strconv.Atoi(value[:len(value)-1])
+	if err != nil || amount < 0 {
+		return 0, fmt.Errorf("expected duration like 1h")
+	}
+
+	return time.Duration(amount) * unit, nil
+}
+
+// sortTasks (tasks, listSort) -> sorts in place by the user's list sort order, see settings_types.ListSorts
+func sortTasks(tasks []models.Task, listSort string) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		switch listSort {
+		case "prio":
+			return priorityRank(tasks[i]) < priorityRank(tasks[j])
+		case "created":
+			return tasks[i].CreatedAt.Before(tasks[j].CreatedAt)
+		case "title":
+			return strings.ToLower(tasks[i].Title) < strings.ToLower(tasks[j].Title)
+		default:
+			return datetimeRank(tasks[i]).Before(datetimeRank(tasks[j]))
+		}
+	})
+}
+
+// priorityRank -> tasks without priority go last
+func priorityRank(task models.Task) int {
+	if task.Priority == 0 {
+		return math.MaxInt32
+	}
+
+	return task.Priority
+}
+
+// datetimeRank -> tasks without datetime go last
+func datetimeRank(task models.Task) time.Time {
+	if task.Datetime == nil {
+		return time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+	}
+
+	return *task.Datetime
+}
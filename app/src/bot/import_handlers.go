@@ -0,0 +1,226 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/pkg/errors"
+	"html"
+	"io"
+	"net/http"
+	"strings"
+	"tg_todo_bot/src/i18n"
+	"tg_todo_bot/src/importers"
+	"tg_todo_bot/src/models"
+	settings_types "tg_todo_bot/src/services/settings/types"
+	tasks_types "tg_todo_bot/src/services/tasks/types"
+	"time"
+)
+
+const (
+	importCallbackPrefix = "import"
+	maxImportFileSize    = 1 << 20
+	importPreviewTasks   = 10
+)
+
+var errImportFileTooBig = fmt.Errorf("file is bigger than %d bytes", maxImportFileSize)
+
+// downloadClient -> files are downloaded from the Telegram servers, the timeout keeps updates flowing
+var downloadClient = &http.Client{Timeout: 30 * time.Second}
+
+// handleImport -> a document sent to the bot is previewed with the "Import" button, "/import" shows the formats.
+// The preview replies to the document, so the button reads the same file again and nothing is kept between them
+func (bot *Bot) handleImport(ctx context.Context, message *tgbotapi.Message, user models.User) error {
+	localizer := userLocalizer(user)
+
+	if message.Document == nil {
+		bot.reply(ctx, message, localizer.T("import.usage", importers.MaxTasks))
+		return nil
+	}
+
+	result, err := bot.parseImport(message.Document, user)
+	if err != nil {
+		text, handled := importErrorText(user, err)
+		if !handled {
+			return err
+		}
+		bot.reply(ctx, message, text)
+		return nil
+	}
+
+	lines := []string{localizer.T("import.preview", localizer.T("import.format."+result.Format), len(result.Tasks))}
+	if result.Skipped > 0 {
+		lines = append(lines, localizer.T("import.skipped", result.Skipped))
+	}
+	lines = append(lines, "")
+	now := userNow(user)
+	for i, task := range result.Tasks {
+		if i == importPreviewTasks {
+			lines = append(lines, localizer.T("import.more", len(result.Tasks)-importPreviewTasks))
+			break
+		}
+		lines = append(lines, formatImportedTask(localizer, task, now))
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(localizer.T("import.confirm", len(result.Tasks)), importCallbackPrefix+":confirm"),
+		tgbotapi.NewInlineKeyboardButtonData(localizer.T("import.cancel"), importCallbackPrefix+":cancel"),
+	))
+	msg := tgbotapi.NewMessage(message.Chat.ID, strings.Join(lines, "\n"))
+	msg.ParseMode = tgbotapi.ModeHTML
+	msg.ReplyToMessageID = message.MessageID
+	msg.ReplyMarkup = keyboard
+
+	err = bot.send(ctx, message.Chat.ID, msg)
+	if err != nil {
+		return errors.Wrap(err, "bot.send(ctx, chatID, msg)")
+	}
+
+	return nil
+}
+
+// handleImportCallback handles "import:confirm" and "import:cancel" buttons of the preview
+func (bot *Bot) handleImportCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, user models.User, data string) error {
+	localizer := userLocalizer(user)
+	chatID, messageID := callback.Message.Chat.ID, callback.Message.MessageID
+	noKeyboard := tgbotapi.InlineKeyboardMarkup{}
+
+	if data != "confirm" {
+		return bot.editWithKeyboard(ctx, chatID, messageID, localizer.T("import.cancelled"), noKeyboard)
+	}
+
+	//Файл могли удалить, а кнопку в группе может нажать не тот, кто его прислал
+	document := callback.Message.ReplyToMessage
+	if document == nil || document.Document == nil || document.From == nil || document.From.ID != callback.From.ID {
+		return bot.editWithKeyboard(ctx, chatID, messageID, localizer.T("import.expired"), noKeyboard)
+	}
+
+	//Кнопка убирается до импорта, чтобы двойное нажатие не создало задачи дважды
+	err := bot.editWithKeyboard(ctx, chatID, messageID, localizer.T("import.in_progress"), noKeyboard)
+	if err != nil {
+		return err
+	}
+
+	result, err := bot.parseImport(document.Document, user)
+	if err != nil {
+		text, handled := importErrorText(user, err)
+		if !handled {
+			_ = bot.editWithKeyboard(ctx, chatID, messageID, localizer.T("error.internal"), noKeyboard)
+			return err
+		}
+		return bot.editWithKeyboard(ctx, chatID, messageID, text, noKeyboard)
+	}
+
+	tasks, err := bot.tasksService.CreateMany(ctx, result.Tasks)
+	if err != nil {
+		_ = bot.editWithKeyboard(ctx, chatID, messageID, localizer.T("error.internal"), noKeyboard)
+		return errors.Wrap(err, "bot.tasksService.CreateMany(ctx, params)")
+	}
+
+	return bot.editWithKeyboard(ctx, chatID, messageID, localizer.T("import.done", len(tasks)), noKeyboard)
+}
+
+// parseImport (document, user) -> tasks of the file, dates without a timezone are in the user's timezone
+func (bot *Bot) parseImport(document *tgbotapi.Document, user models.User) (importers.Result, error) {
+	if document.FileSize > maxImportFileSize {
+		return importers.Result{}, errImportFileTooBig
+	}
+
+	data, err := bot.downloadFile(document.FileID)
+	if err != nil {
+		return importers.Result{}, err
+	}
+
+	location := time.UTC
+	if user.Settings != nil {
+		location = settings_types.Location(*user.Settings)
+	}
+
+	result, err := importers.Parse(document.FileName, data, importers.Params{UserID: user.ID, Location: location})
+	if err != nil {
+		return importers.Result{}, &importParseError{err: err}
+	}
+
+	return result, nil
+}
+
+// importParseError -> the file can't be imported, its message is shown to the user
+type importParseError struct {
+	err error
+}
+
+func (err *importParseError) Error() string {
+	return err.err.Error()
+}
+
+func (err *importParseError) Unwrap() error {
+	return err.err
+}
+
+// importErrorText (user, err) -> message for errors caused by the file, false for internal errors
+func importErrorText(user models.User, err error) (string, bool) {
+	localizer := userLocalizer(user)
+
+	var parseErr *importParseError
+	switch {
+	case errors.Is(err, errImportFileTooBig):
+		return localizer.T("import.too_big", maxImportFileSize>>10), true
+	case errors.Is(err, importers.ErrNoTasks):
+		return localizer.T("import.empty"), true
+	case errors.Is(err, importers.ErrTooManyTasks):
+		return localizer.T("import.too_many", importers.MaxTasks), true
+	case errors.As(err, &parseErr):
+		return localizer.T("import.invalid", html.EscapeString(parseErr.Error())), true
+	default:
+		return "", false
+	}
+}
+
+// downloadFile (fileID) -> content of a file sent to the bot, at most maxImportFileSize bytes
+func (bot *Bot) downloadFile(fileID string) ([]byte, error) {
+	fileURL, err := bot.api.GetFileDirectURL(fileID)
+	if err != nil {
+		return nil, errors.Wrap(err, "bot.api.GetFileDirectURL(fileID)")
+	}
+
+	response, err := downloadClient.Get(fileURL)
+	if err != nil {
+		//В ошибке URL с токеном бота
+		return nil, errors.New("downloadClient.Get(fileURL): request failed")
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("downloadClient.Get(fileURL): status %d", response.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(response.Body, maxImportFileSize+1))
+	if err != nil {
+		return nil, errors.Wrap(err, "io.ReadAll(body)")
+	}
+	if len(data) > maxImportFileSize {
+		return nil, errImportFileTooBig
+	}
+
+	return data, nil
+}
+
+// formatImportedTask (localizer, task, now) -> line of the preview, like formatTask but without ID
+func formatImportedTask(localizer *i18n.Localizer, task tasks_types.CreateParams, now time.Time) string {
+	var builder strings.Builder
+
+	builder.WriteString("• ")
+	if task.Priority > 0 {
+		builder.WriteString(fmt.Sprintf("<b>!%d</b> ", task.Priority))
+	}
+	if task.Datetime != nil {
+		builder.WriteString(formatDatetime(localizer, *task.Datetime, now))
+		builder.WriteString(" ")
+	}
+	builder.WriteString(html.EscapeString(task.Title))
+	for _, tag := range task.Tags {
+		builder.WriteString(" #" + html.EscapeString(tag))
+	}
+
+	return builder.String()
+}
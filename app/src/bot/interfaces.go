@@ -0,0 +1,87 @@
+package bot
+
+import (
+	"context"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"tg_todo_bot/src/models"
+	escalations_types "tg_todo_bot/src/services/escalations/types"
+	filters_types "tg_todo_bot/src/services/filters/types"
+	settings_types "tg_todo_bot/src/services/settings/types"
+	tasks_types "tg_todo_bot/src/services/tasks/types"
+	users_types "tg_todo_bot/src/services/users/types"
+	webhooks_types "tg_todo_bot/src/services/webhooks/types"
+	"time"
+)
+
+type UsersServiceI interface {
+	Create(ctx context.Context, params users_types.CreateParams) error
+	FindByTelegramID(ctx context.Context, telegramID int64) (models.User, error)
+	RequestDeletion(ctx context.Context, userID int64, now time.Time) error
+	CancelDeletion(ctx context.Context, userID int64) error
+	TouchLastSeen(ctx context.Context, user models.User, now time.Time) error
+	MarkBotBlocked(ctx context.Context, telegramID int64, now time.Time) error
+	Ban(ctx context.Context, userID int64, reason string, now time.Time) error
+	Unban(ctx context.Context, userID int64) error
+	GetStats(ctx context.Context, now time.Time) (users_types.Stats, error)
+}
+
+type TasksServiceI interface {
+	Create(ctx context.Context, params tasks_types.CreateParams) (models.Task, error)
+	CreateMany(ctx context.Context, params []tasks_types.CreateParams) ([]models.Task, error)
+	FindByID(ctx context.Context, taskID int64) (models.Task, error)
+	SearchByDateForUser(ctx context.Context, params tasks_types.SearchByDateForUserParams) (map[time.Time][]models.Task, error)
+	Complete(ctx context.Context, taskID int64) ([]models.Task, error)
+	AddDependency(ctx context.Context, params tasks_types.DependencyParams) error
+	RemoveDependency(ctx context.Context, params tasks_types.DependencyParams) error
+	Search(ctx context.Context, params tasks_types.SearchParams) ([]models.Task, error)
+	GetActiveForUserPage(ctx context.Context, params tasks_types.PageParams) (tasks_types.TasksPage, error)
+	Filter(ctx context.Context, params tasks_types.FilterParams) (tasks_types.TasksPage, error)
+	GetOverdueForUser(ctx context.Context, userID int64, now time.Time) ([]models.Task, error)
+	ForEachPageForUser(ctx context.Context, userID int64, handle func(tasks []models.Task) error) error
+	CountForUser(ctx context.Context, userID int64) (tasks_types.Counts, error)
+}
+
+type FiltersServiceI interface {
+	Save(ctx context.Context, params filters_types.SaveParams) error
+	FindByName(ctx context.Context, userID int64, name string) (models.SavedFilter, error)
+	GetAllForUser(ctx context.Context, userID int64) ([]models.SavedFilter, error)
+	DeleteByName(ctx context.Context, userID int64, name string) error
+}
+
+type EscalationsServiceI interface {
+	SetPolicy(ctx context.Context, params escalations_types.PolicyParams) error
+	ResetPolicy(ctx context.Context, userID, taskID int64) error
+	GetPolicy(ctx context.Context, userID, taskID int64) (models.EscalationPolicy, error)
+}
+
+type SettingsServiceI interface {
+	Get(ctx context.Context, userID int64) (models.UserSettings, error)
+	Update(ctx context.Context, params settings_types.UpdateParams) (models.UserSettings, error)
+}
+
+type TokensServiceI interface {
+	Issue(ctx context.Context, userID int64, scope string) (string, error)
+	Revoke(ctx context.Context, userID int64, scope string) error
+}
+
+type WebhooksServiceI interface {
+	Register(ctx context.Context, params webhooks_types.RegisterParams) (models.Webhook, error)
+	GetAllForUser(ctx context.Context, userID int64) ([]models.Webhook, error)
+	DeleteForUser(ctx context.Context, userID, webhookID int64) error
+}
+
+type BroadcastsServiceI interface {
+	Create(ctx context.Context, text string) (models.Broadcast, error)
+	FindLatest(ctx context.Context) (models.Broadcast, error)
+	Start(ctx context.Context, broadcastID int64) error
+	Cancel(ctx context.Context, broadcastID int64, now time.Time) error
+}
+
+type SendQueueI interface {
+	Send(ctx context.Context, chatID int64, chattable tgbotapi.Chattable) (tgbotapi.Message, error)
+	SendOnce(ctx context.Context, chatID int64, chattable tgbotapi.Chattable) (tgbotapi.Message, error)
+}
+
+type RateLimiterI interface {
+	Take(ctx context.Context, userID int64, bucket string) error
+}
@@ -0,0 +1,119 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/pkg/errors"
+	"strings"
+	"tg_todo_bot/src/i18n"
+	"tg_todo_bot/src/models"
+	tasks_types "tg_todo_bot/src/services/tasks/types"
+	"time"
+)
+
+const (
+	listPageSize       = 10
+	listCallbackPrefix = "list"
+)
+
+// handleList -> paginated active tasks, "/list query" shows tasks matching the filter query
+func (bot *Bot) handleList(ctx context.Context, message *tgbotapi.Message, user models.User) error {
+	query := strings.TrimSpace(message.CommandArguments())
+	if query != "" {
+		return bot.replyWithFilteredTasks(ctx, message, user, query)
+	}
+
+	page, err := bot.tasksService.GetActiveForUserPage(ctx, tasks_types.PageParams{
+		UserID: user.ID,
+		Limit:  listPageSize,
+	})
+	if err != nil {
+		return errors.Wrap(err, "bot.tasksService.GetActiveForUserPage(ctx, params)")
+	}
+
+	text, keyboard := renderTasksPage(userLocalizer(user), page, userNow(user))
+
+	return bot.sendWithKeyboard(ctx, message.Chat.ID, text, keyboard)
+}
+
+// handleListCallback handles "list:next:ID" and "list:prev:ID" buttons by editing the same message
+func (bot *Bot) handleListCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, user models.User, data string) error {
+	direction, rawTaskID, _ := strings.Cut(data, ":")
+	taskID, err := parseTaskID(rawTaskID)
+	if err != nil {
+		return errors.Wrap(err, "parseTaskID(rawTaskID)")
+	}
+
+	params := tasks_types.PageParams{
+		UserID: user.ID,
+		Limit:  listPageSize,
+	}
+	if direction == "prev" {
+		params.BeforeTaskID = taskID
+	} else {
+		params.AfterTaskID = taskID
+	}
+
+	page, err := bot.tasksService.GetActiveForUserPage(ctx, params)
+	if err != nil {
+		return errors.Wrap(err, "bot.tasksService.GetActiveForUserPage(ctx, params)")
+	}
+
+	text, keyboard := renderTasksPage(userLocalizer(user), page, userNow(user))
+
+	return bot.editWithKeyboard(ctx, callback.Message.Chat.ID, callback.Message.MessageID, text, keyboard)
+}
+
+func renderTasksPage(localizer *i18n.Localizer, page tasks_types.TasksPage, now time.Time) (string, tgbotapi.InlineKeyboardMarkup) {
+	if len(page.Tasks) == 0 {
+		return localizer.T("list.empty"), tgbotapi.InlineKeyboardMarkup{}
+	}
+
+	//Просроченные идут первыми, так как список отсортирован по дате
+	var overdueLines, activeLines []string
+	for _, task := range page.Tasks {
+		switch {
+		case len(task.BlockedBy) > 0:
+			activeLines = append(activeLines, formatBlockedTask(localizer, task, now))
+		case tasks_types.IsOverdue(task, now):
+			overdueLines = append(overdueLines, formatTask(localizer, task, now))
+		default:
+			activeLines = append(activeLines, formatTask(localizer, task, now))
+		}
+	}
+
+	var lines []string
+	if len(overdueLines) > 0 {
+		lines = append(lines, localizer.T("section.overdue"))
+		lines = append(lines, overdueLines...)
+	}
+	if len(activeLines) > 0 {
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, localizer.T("section.active"))
+		lines = append(lines, activeLines...)
+	}
+
+	var buttons []tgbotapi.InlineKeyboardButton
+	if page.HasPrev {
+		buttons = append(buttons, tgbotapi.NewInlineKeyboardButtonData(
+			localizer.T("button.prev"),
+			fmt.Sprintf("%s:prev:%d", listCallbackPrefix, page.Tasks[0].ID),
+		))
+	}
+	if page.HasNext {
+		buttons = append(buttons, tgbotapi.NewInlineKeyboardButtonData(
+			localizer.T("button.next"),
+			fmt.Sprintf("%s:next:%d", listCallbackPrefix, page.Tasks[len(page.Tasks)-1].ID),
+		))
+	}
+
+	keyboard := tgbotapi.InlineKeyboardMarkup{}
+	if len(buttons) > 0 {
+		keyboard = tgbotapi.NewInlineKeyboardMarkup(buttons)
+	}
+
+	return strings.Join(lines, "\n"), keyboard
+}
@@ -0,0 +1,98 @@
+package bot
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"math"
+	"tg_todo_bot/src/models"
+	ratelimits_types "tg_todo_bot/src/services/ratelimits/types"
+	services_types "tg_todo_bot/src/services/types"
+	"time"
+)
+
+// updateHandler -> a command or a callback handler with its update already bound
+type updateHandler func(ctx context.Context, user models.User) error
+
+// middleware (next, reply) -> runs before handlers of all commands and callbacks,
+// it may answer with reply and stop the update instead of calling next
+type middleware func(next updateHandler, reply func(text string)) updateHandler
+
+// middlewares -> the update pipeline, the first middleware runs first
+func (bot *Bot) middlewares() []middleware {
+	return []middleware{
+		bot.dropBanned,
+		bot.touchLastSeen,
+		bot.rateLimit,
+	}
+}
+
+// withMiddlewares (handler, reply) -> the handler wrapped into all middlewares
+func (bot *Bot) withMiddlewares(handler updateHandler, reply func(text string)) updateHandler {
+	middlewares := bot.middlewares()
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler, reply)
+	}
+
+	return handler
+}
+
+// dropBanned -> updates of banned users are dropped without any answer
+func (bot *Bot) dropBanned(next updateHandler, reply func(text string)) updateHandler {
+	return func(ctx context.Context, user models.User) error {
+		if user.BannedAt != nil {
+			bot.logger.Debugw("Bot -> dropBanned -> the user is banned", "userID", user.ID)
+			return nil
+		}
+
+		return next(ctx, user)
+	}
+}
+
+func (bot *Bot) touchLastSeen(next updateHandler, reply func(text string)) updateHandler {
+	return func(ctx context.Context, user models.User) error {
+		err := bot.usersService.TouchLastSeen(ctx, user, time.Now())
+		if err != nil {
+			//Время последней активности нужно для статистики и снятия паузы с напоминаний, ответ пользователю важнее
+			bot.logger.Errorw(
+				"Bot -> touchLastSeen -> bot.usersService.TouchLastSeen(ctx, user, now)",
+				"error", err.Error(), "userID", user.ID,
+			)
+		}
+
+		return next(ctx, user)
+	}
+}
+
+// rateLimit -> commands and buttons are limited by the commands bucket of the user, the admin isn't limited.
+// A flood gets one throttle notice a minute, the rest is dropped silently
+func (bot *Bot) rateLimit(next updateHandler, reply func(text string)) updateHandler {
+	return func(ctx context.Context, user models.User) error {
+		if user.TelegramID == bot.adminID {
+			return next(ctx, user)
+		}
+
+		err := bot.rateLimiter.Take(ctx, user.ID, ratelimits_types.BucketCommands)
+		if errors.Is(err, services_types.ErrRateLimited) {
+			if bot.rateLimiter.Take(ctx, user.ID, ratelimits_types.BucketThrottleNotices) == nil {
+				reply(throttledText(user, err))
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		return next(ctx, user)
+	}
+}
+
+// throttledText (user, err) -> how long to wait, err is *services_types.RateLimitedError
+func throttledText(user models.User, err error) string {
+	retryAfter := time.Second
+	var rateLimitedErr *services_types.RateLimitedError
+	if errors.As(err, &rateLimitedErr) {
+		retryAfter = rateLimitedErr.RetryAfter
+	}
+
+	return userLocalizer(user).N("throttled", int(math.Ceil(retryAfter.Seconds())))
+}
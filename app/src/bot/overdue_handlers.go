@@ -0,0 +1,189 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/pkg/errors"
+	"html"
+	"strings"
+	"tg_todo_bot/src/i18n"
+	"tg_todo_bot/src/models"
+	escalations_types "tg_todo_bot/src/services/escalations/types"
+	services_types "tg_todo_bot/src/services/types"
+	"time"
+)
+
+func (bot *Bot) handleOverdue(ctx context.Context, message *tgbotapi.Message, user models.User) error {
+	localizer, now := userLocalizer(user), userNow(user)
+	tasks, err := bot.tasksService.GetOverdueForUser(ctx, user.ID, now)
+	if err != nil {
+		return errors.Wrap(err, "bot.tasksService.GetOverdueForUser(ctx, userID, now)")
+	}
+
+	if len(tasks) == 0 {
+		bot.reply(ctx, message, localizer.T("overdue.empty"))
+		return nil
+	}
+
+	lines := []string{localizer.T("section.overdue")}
+	for _, task := range tasks {
+		if len(task.BlockedBy) > 0 {
+			lines = append(lines, formatBlockedTask(localizer, task, now))
+		} else {
+			lines = append(lines, formatTask(localizer, task, now))
+		}
+	}
+	bot.reply(ctx, message, strings.Join(lines, "\n"))
+
+	return nil
+}
+
+// handleEscalate -> "/escalate [#ID] [after repeat count | off | reset]", without a task ID the user's default policy is changed
+func (bot *Bot) handleEscalate(ctx context.Context, message *tgbotapi.Message, user models.User) error {
+	localizer := userLocalizer(user)
+	args := strings.Fields(message.CommandArguments())
+
+	var taskID int64
+	if len(args) > 0 && strings.HasPrefix(args[0], "#") {
+		parsedTaskID, err := parseTaskID(args[0])
+		if err != nil {
+			bot.reply(ctx, message, localizer.T("escalate.usage"))
+			return nil
+		}
+
+		task, err := bot.findUserTask(ctx, user, parsedTaskID)
+		if err != nil {
+			if errors.Is(err, services_types.ErrNotFound) {
+				bot.reply(ctx, message, localizer.T("error.task_not_found"))
+				return nil
+			}
+			return errors.Wrap(err, "bot.findUserTask(ctx, user, taskID)")
+		}
+		taskID, args = task.ID, args[1:]
+	}
+
+	switch {
+	case len(args) == 0:
+		policy, err := bot.escalationsService.GetPolicy(ctx, user.ID, taskID)
+		if err != nil {
+			return errors.Wrap(err, "bot.escalationsService.GetPolicy(ctx, userID, taskID)")
+		}
+		bot.reply(ctx, message, formatEscalationPolicy(localizer, policy)+"\n\n"+localizer.T("escalate.usage"))
+		return nil
+	case len(args) == 1 && args[0] == "reset":
+		err := bot.escalationsService.ResetPolicy(ctx, user.ID, taskID)
+		if err != nil {
+			return errors.Wrap(err, "bot.escalationsService.ResetPolicy(ctx, userID, taskID)")
+		}
+		bot.reply(ctx, message, localizer.T("escalate.reset"))
+		return nil
+	}
+
+	params := escalations_types.PolicyParams{
+		UserID:         user.ID,
+		TaskID:         taskID,
+		RepeatInterval: 24 * time.Hour,
+	}
+	if len(args) == 1 && args[0] == "off" {
+		params.MaxReminders = 0
+	} else {
+		var err error
+		params.OverdueAfter, params.RepeatInterval, params.MaxReminders, err = parseEscalationArgs(args)
+		if err != nil {
+			bot.reply(ctx, message, localizer.T("escalate.usage"))
+			return nil
+		}
+	}
+
+	err := bot.escalationsService.SetPolicy(ctx, params)
+	if err != nil {
+		//Остальное не зависит от пользователя, поэтому ошибка валидации
+		bot.reply(ctx, message, localizer.T("escalate.invalid"))
+		return nil
+	}
+
+	policy := models.EscalationPolicy{
+		OverdueAfter:   params.OverdueAfter,
+		RepeatInterval: params.RepeatInterval,
+		MaxReminders:   params.MaxReminders,
+	}
+	bot.reply(ctx, message, localizer.T("escalate.saved", formatEscalationPolicy(localizer, policy)))
+
+	return nil
+}
+
+// parseEscalationArgs (["1h", "1d", "3"]) -> (1h, 24h, 3)
+func parseEscalationArgs(args []string) (time.Duration, time.Duration, int, error) {
+	if len(args) != 3 {
+		return 0, 0, 0, fmt.Errorf("expected 3 arguments")
+	}
+
+	overdueAfter, err := parseDuration(args[0])
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	repeatInterval, err := parseDuration(args[1])
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	var count int
+	_, err = fmt.Sscanf(args[2], "%d", &count)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	return overdueAfter, repeatInterval, count, nil
+}
+
+func formatEscalationPolicy(localizer *i18n.Localizer, policy models.EscalationPolicy) string {
+	if policy.MaxReminders == 0 {
+		return localizer.T("escalate.off")
+	}
+
+	return localizer.N(
+		"escalate.policy",
+		policy.MaxReminders, localizer.Duration(policy.OverdueAfter), localizer.Duration(policy.RepeatInterval),
+	)
+}
+
+// SendEscalation sends the reminder about the overdue task, the tone gets stronger with each level
+func (bot *Bot) SendEscalation(ctx context.Context, user models.User, escalation escalations_types.Escalation) error {
+	user, err := bot.withSettings(ctx, user)
+	if err != nil {
+		return err
+	}
+
+	return bot.SendMessage(ctx, user.TelegramID, escalationText(userLocalizer(user), escalation))
+}
+
+func escalationText(localizer *i18n.Localizer, escalation escalations_types.Escalation) string {
+	icon := "⏰"
+	switch {
+	case escalation.Final:
+		icon = "🚨"
+	case escalation.Level > 1:
+		icon = "❗️"
+	}
+
+	text := localizer.T(
+		"escalation",
+		icon, escalation.Task.ID, formatOverdue(localizer, escalation.OverdueBy), html.EscapeString(escalation.Task.Title),
+	)
+	if escalation.Final {
+		text += "\n" + localizer.T("escalation.final", escalation.Task.ID)
+	}
+
+	return text
+}
+
+// formatOverdue (localizer, duration) -> "2 д 3 ч", "45 мин", minutes are dropped after the first hour
+func formatOverdue(localizer *i18n.Localizer, duration time.Duration) string {
+	if duration >= time.Hour {
+		duration = duration.Truncate(time.Hour)
+	}
+
+	return localizer.Duration(duration)
+}
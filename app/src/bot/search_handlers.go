@@ -0,0 +1,117 @@
+package bot
+
+import (
+	"context"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/pkg/errors"
+	"html"
+	"strconv"
+	"strings"
+	"tg_todo_bot/src/models"
+	tasks_types "tg_todo_bot/src/services/tasks/types"
+)
+
+const (
+	searchResultsLimit       = 20
+	inlineSearchResultsLimit = 20
+)
+
+func (bot *Bot) handleSearch(ctx context.Context, message *tgbotapi.Message, user models.User) error {
+	localizer := userLocalizer(user)
+	query := strings.TrimSpace(message.CommandArguments())
+	if query == "" {
+		bot.reply(ctx, message, localizer.T("search.usage"))
+		return nil
+	}
+
+	tasks, err := bot.tasksService.Search(ctx, tasks_types.SearchParams{
+		UserID: user.ID,
+		Query:  query,
+		Limit:  searchResultsLimit,
+	})
+	if err != nil {
+		return errors.Wrap(err, "bot.tasksService.Search(ctx, params)")
+	}
+
+	if len(tasks) == 0 {
+		bot.reply(ctx, message, localizer.T("search.empty"))
+		return nil
+	}
+
+	now := userNow(user)
+	lines := []string{localizer.N("search.found", len(tasks), html.EscapeString(query))}
+	for _, task := range tasks {
+		line := formatTask(localizer, task, now)
+		if task.Done {
+			line = "✅ <s>" + line + "</s>"
+		}
+		lines = append(lines, line)
+	}
+	bot.reply(ctx, message, strings.Join(lines, "\n"))
+
+	return nil
+}
+
+// handleInlineQuery answers "@bot query" with the user's matching tasks
+func (bot *Bot) handleInlineQuery(ctx context.Context, inlineQuery *tgbotapi.InlineQuery) {
+	results := []interface{}{}
+
+	query := strings.TrimSpace(inlineQuery.Query)
+	if query != "" && inlineQuery.From != nil {
+		tasks, user, err := bot.searchForInlineQuery(ctx, inlineQuery.From.ID, query)
+		if err != nil {
+			bot.logger.Errorw(
+				"Bot -> handleInlineQuery -> bot.searchForInlineQuery(ctx, telegramID, query)",
+				"error", err.Error(), "telegramID", inlineQuery.From.ID, "query", query,
+			)
+		}
+
+		localizer, now := userLocalizer(user), userNow(user)
+		for _, task := range tasks {
+			article := tgbotapi.NewInlineQueryResultArticleHTML(
+				strconv.FormatInt(task.ID, 10),
+				task.Title,
+				formatTask(localizer, task, now),
+			)
+			article.Description = task.Description
+			if task.Datetime != nil {
+				article.Description = strings.TrimSpace(formatDatetime(localizer, *task.Datetime, now) + " " + task.Description)
+			}
+			results = append(results, article)
+		}
+	}
+
+	_, err := bot.api.Request(tgbotapi.InlineConfig{
+		InlineQueryID: inlineQuery.ID,
+		Results:       results,
+		IsPersonal:    true,
+	})
+	if err != nil {
+		bot.logger.Errorw(
+			"Bot -> handleInlineQuery -> bot.api.Request(inlineConfig)",
+			"error", err.Error(), "inlineQueryID", inlineQuery.ID,
+		)
+	}
+}
+
+// searchForInlineQuery (ctx, telegramID, query) -> found tasks and the user with loaded settings
+func (bot *Bot) searchForInlineQuery(ctx context.Context, telegramID int64, query string) ([]models.Task, models.User, error) {
+	user, err := bot.getOrCreateUser(ctx, telegramID)
+	if err != nil {
+		return []models.Task{}, models.User{}, err
+	}
+	//Инлайн-запросы идут мимо middleware, поэтому бан проверяется здесь
+	if user.BannedAt != nil {
+		return []models.Task{}, user, nil
+	}
+
+	done := false
+	tasks, err := bot.tasksService.Search(ctx, tasks_types.SearchParams{
+		UserID: user.ID,
+		Query:  query,
+		Done:   &done,
+		Limit:  inlineSearchResultsLimit,
+	})
+
+	return tasks, user, err
+}
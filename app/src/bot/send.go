@@ -0,0 +1,42 @@
+package bot
+
+import (
+	"context"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/pkg/errors"
+	"tg_todo_bot/src/sendqueue"
+	"time"
+)
+
+// send (ctx, chatID, chattable) -> messages and edits go through the queue, so the bot stays within limits of Telegram
+func (bot *Bot) send(ctx context.Context, chatID int64, chattable tgbotapi.Chattable) error {
+	_, err := bot.sendQueue.Send(ctx, chatID, chattable)
+	bot.checkBlocked(ctx, chatID, err)
+
+	return err
+}
+
+// sendOnce (ctx, chatID, chattable) -> like send, but without retries, for uploads from a reader
+func (bot *Bot) sendOnce(ctx context.Context, chatID int64, chattable tgbotapi.Chattable) error {
+	_, err := bot.sendQueue.SendOnce(ctx, chatID, chattable)
+	bot.checkBlocked(ctx, chatID, err)
+
+	return err
+}
+
+// checkBlocked (ctx, chatID, err) -> pauses reminders of the user if Telegram answered that the bot is blocked
+func (bot *Bot) checkBlocked(ctx context.Context, chatID int64, err error) {
+	//Отрицательные ID у групп, там 403 означает, что бота удалили из группы, а не пользователя
+	if !errors.Is(err, sendqueue.ErrBlocked) || chatID <= 0 {
+		return
+	}
+
+	//Для личных чатов ID чата совпадает с telegram ID пользователя
+	markErr := bot.usersService.MarkBotBlocked(ctx, chatID, time.Now())
+	if markErr != nil {
+		bot.logger.Errorw(
+			"Bot -> checkBlocked -> bot.usersService.MarkBotBlocked(ctx, telegramID, now)",
+			"error", markErr.Error(), "telegramID", chatID,
+		)
+	}
+}
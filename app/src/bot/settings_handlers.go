@@ -0,0 +1,337 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/pkg/errors"
+	"html"
+	"strconv"
+	"strings"
+	"tg_todo_bot/src/i18n"
+	"tg_todo_bot/src/models"
+	settings_types "tg_todo_bot/src/services/settings/types"
+	"time"
+)
+
+const settingsCallbackPrefix = "settings"
+
+type settingOption struct {
+	Label string
+	Value string
+}
+
+// setting -> one line of the /settings menu in the user's language, Value is stored in the callback data, so it must be short
+type setting struct {
+	Key     string
+	Title   string
+	Options []settingOption
+	Current func(settings models.UserSettings) string
+	Params  func(value string) (settings_types.UpdateParams, error)
+}
+
+// languageLabels -> each language is named in itself, so it can be found in the menu in an unfamiliar language
+var languageLabels = map[string]string{
+	settings_types.LanguageRu: "Русский",
+	settings_types.LanguageEn: "English",
+}
+
+var timezones = []string{
+	"UTC",
+	"Europe/Kaliningrad",
+	"Europe/Moscow",
+	"Europe/Samara",
+	"Asia/Yekaterinburg",
+	"Asia/Omsk",
+	"Asia/Novosibirsk",
+	"Asia/Irkutsk",
+	"Asia/Yakutsk",
+	"Asia/Vladivostok",
+	"Asia/Magadan",
+	"Asia/Kamchatka",
+}
+
+var digestTimes = []string{"07:00", "08:00", "09:00", "10:00", "20:00", "21:00"}
+
+func durationOptions(localizer *i18n.Localizer, durations ...time.Duration) []settingOption {
+	var options []settingOption
+	for _, duration := range durations {
+		options = append(options, settingOption{
+			Label: localizer.Duration(duration),
+			Value: strconv.Itoa(int(duration / time.Minute)),
+		})
+	}
+
+	return options
+}
+
+func parseMinutes(value string) (time.Duration, error) {
+	minutes, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, err
+	}
+
+	return time.Duration(minutes) * time.Minute, nil
+}
+
+// parseDigestTime ("07:30") -> 450 minutes since midnight, nil for "off"
+func parseDigestTime(value string) (*int, error) {
+	if value == "off" {
+		return nil, nil
+	}
+
+	clock, err := time.Parse(timeLayout, value)
+	if err != nil {
+		return nil, err
+	}
+
+	minute := clock.Hour()*60 + clock.Minute()
+
+	return &minute, nil
+}
+
+func formatDigestTime(localizer *i18n.Localizer, digestMinute *int) string {
+	if digestMinute == nil {
+		return localizer.T("settings.digest.off")
+	}
+
+	return fmt.Sprintf("%02d:%02d", *digestMinute/60, *digestMinute%60)
+}
+
+func settingsList(localizer *i18n.Localizer) []setting {
+	var timezoneOptions []settingOption
+	for _, timezone := range timezones {
+		timezoneOptions = append(timezoneOptions, settingOption{Label: localizer.T("tz." + timezone), Value: timezone})
+	}
+
+	var sortOptions []settingOption
+	for _, listSort := range settings_types.ListSorts {
+		sortOptions = append(sortOptions, settingOption{Label: localizer.T("settings.sort." + listSort), Value: listSort})
+	}
+
+	digestOptions := []settingOption{{Label: localizer.T("settings.digest.off"), Value: "off"}}
+	for _, digestTime := range digestTimes {
+		digestOptions = append(digestOptions, settingOption{Label: digestTime, Value: digestTime})
+	}
+
+	return []setting{
+		{
+			Key:   "lang",
+			Title: localizer.T("settings.lang"),
+			Options: []settingOption{
+				{Label: languageLabels[settings_types.LanguageRu], Value: settings_types.LanguageRu},
+				{Label: languageLabels[settings_types.LanguageEn], Value: settings_types.LanguageEn},
+			},
+			Current: func(settings models.UserSettings) string {
+				return languageLabels[settings.Language]
+			},
+			Params: func(value string) (settings_types.UpdateParams, error) {
+				params := settings_types.UpdateParams{}
+				params.Language.Value, params.Language.IsSet = value, true
+				return params, nil
+			},
+		},
+		{
+			Key:     "tz",
+			Title:   localizer.T("settings.tz"),
+			Options: timezoneOptions,
+			Current: func(settings models.UserSettings) string {
+				return settings.Timezone
+			},
+			Params: func(value string) (settings_types.UpdateParams, error) {
+				params := settings_types.UpdateParams{}
+				params.Timezone.Value, params.Timezone.IsSet = value, true
+				return params, nil
+			},
+		},
+		{
+			Key:   "offset",
+			Title: localizer.T("settings.offset"),
+			Options: append(
+				[]settingOption{{Label: localizer.T("settings.offset.none"), Value: "0"}},
+				durationOptions(localizer, 5*time.Minute, 15*time.Minute, 30*time.Minute, time.Hour, 24*time.Hour)...,
+			),
+			Current: func(settings models.UserSettings) string {
+				if settings.ReminderOffset == 0 {
+					return localizer.T("settings.offset.none")
+				}
+				return localizer.T("settings.offset.value", localizer.Duration(settings.ReminderOffset))
+			},
+			Params: func(value string) (settings_types.UpdateParams, error) {
+				params := settings_types.UpdateParams{}
+				offset, err := parseMinutes(value)
+				params.ReminderOffset.Value, params.ReminderOffset.IsSet = offset, true
+				return params, err
+			},
+		},
+		{
+			Key:     "repeat",
+			Title:   localizer.T("settings.repeat"),
+			Options: durationOptions(localizer, 5*time.Minute, 15*time.Minute, 30*time.Minute, time.Hour, 3*time.Hour, 24*time.Hour),
+			Current: func(settings models.UserSettings) string {
+				return localizer.T("settings.repeat.value", localizer.Duration(settings.RepeatInterval))
+			},
+			Params: func(value string) (settings_types.UpdateParams, error) {
+				params := settings_types.UpdateParams{}
+				interval, err := parseMinutes(value)
+				params.RepeatInterval.Value, params.RepeatInterval.IsSet = interval, true
+				return params, err
+			},
+		},
+		{
+			Key:     "sort",
+			Title:   localizer.T("settings.sort"),
+			Options: sortOptions,
+			Current: func(settings models.UserSettings) string {
+				return localizer.T("settings.sort." + settings.ListSort)
+			},
+			Params: func(value string) (settings_types.UpdateParams, error) {
+				params := settings_types.UpdateParams{}
+				params.ListSort.Value, params.ListSort.IsSet = value, true
+				return params, nil
+			},
+		},
+		{
+			Key:     "digest",
+			Title:   localizer.T("settings.digest"),
+			Options: digestOptions,
+			Current: func(settings models.UserSettings) string {
+				return formatDigestTime(localizer, settings.DigestMinute)
+			},
+			Params: func(value string) (settings_types.UpdateParams, error) {
+				params := settings_types.UpdateParams{}
+				digestMinute, err := parseDigestTime(value)
+				params.DigestMinute.Value, params.DigestMinute.IsSet = digestMinute, true
+				return params, err
+			},
+		},
+	}
+}
+
+func findSetting(localizer *i18n.Localizer, key string) (setting, bool) {
+	for _, s := range settingsList(localizer) {
+		if s.Key == key {
+			return s, true
+		}
+	}
+
+	return setting{}, false
+}
+
+// handleSettings -> "/settings" shows the menu, "/settings key value" changes the setting without the menu
+func (bot *Bot) handleSettings(ctx context.Context, message *tgbotapi.Message, user models.User) error {
+	localizer := userLocalizer(user)
+	key, value, _ := strings.Cut(strings.TrimSpace(message.CommandArguments()), " ")
+	if key == "" {
+		text, keyboard := renderSettingsMenu(localizer, *user.Settings)
+		return bot.sendWithKeyboard(ctx, message.Chat.ID, text, keyboard)
+	}
+
+	s, exist := findSetting(localizer, key)
+	if !exist || strings.TrimSpace(value) == "" {
+		bot.reply(ctx, message, localizer.T("settings.usage"))
+		return nil
+	}
+
+	settings, err := bot.updateSetting(ctx, user, s, strings.TrimSpace(value))
+	if err != nil {
+		bot.reply(ctx, message, localizer.T("settings.invalid", s.Title)+"\n\n"+localizer.T("settings.usage"))
+		return nil
+	}
+
+	//Язык мог поменяться, меню показывается уже на новом
+	text, keyboard := renderSettingsMenu(i18n.New(settings.Language), settings)
+
+	return bot.sendWithKeyboard(ctx, message.Chat.ID, text, keyboard)
+}
+
+// handleSettingsCallback handles "settings:menu", "settings:open:key" and "settings:set:key:value" buttons
+func (bot *Bot) handleSettingsCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, user models.User, data string) error {
+	localizer := userLocalizer(user)
+	action, rest, _ := strings.Cut(data, ":")
+	chatID, messageID := callback.Message.Chat.ID, callback.Message.MessageID
+
+	switch action {
+	case "open":
+		s, exist := findSetting(localizer, rest)
+		if !exist {
+			return nil
+		}
+		text, keyboard := renderSettingOptions(localizer, s, *user.Settings)
+		return bot.editWithKeyboard(ctx, chatID, messageID, text, keyboard)
+	case "set":
+		key, value, _ := strings.Cut(rest, ":")
+		s, exist := findSetting(localizer, key)
+		if !exist {
+			return nil
+		}
+		settings, err := bot.updateSetting(ctx, user, s, value)
+		if err != nil {
+			return err
+		}
+		text, keyboard := renderSettingsMenu(i18n.New(settings.Language), settings)
+		return bot.editWithKeyboard(ctx, chatID, messageID, text, keyboard)
+	default:
+		text, keyboard := renderSettingsMenu(localizer, *user.Settings)
+		return bot.editWithKeyboard(ctx, chatID, messageID, text, keyboard)
+	}
+}
+
+func (bot *Bot) updateSetting(ctx context.Context, user models.User, s setting, value string) (models.UserSettings, error) {
+	params, err := s.Params(value)
+	if err != nil {
+		return models.UserSettings{}, err
+	}
+	params.UserID = user.ID
+
+	settings, err := bot.settingsService.Update(ctx, params)
+	if err != nil {
+		return models.UserSettings{}, errors.Wrap(err, "bot.settingsService.Update(params)")
+	}
+
+	return settings, nil
+}
+
+func renderSettingsMenu(localizer *i18n.Localizer, settings models.UserSettings) (string, tgbotapi.InlineKeyboardMarkup) {
+	lines := []string{localizer.T("settings.title")}
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, s := range settingsList(localizer) {
+		lines = append(lines, fmt.Sprintf("%s: %s", s.Title, html.EscapeString(s.Current(settings))))
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(
+			s.Title,
+			fmt.Sprintf("%s:open:%s", settingsCallbackPrefix, s.Key),
+		)))
+	}
+
+	return strings.Join(lines, "\n"), tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+func renderSettingOptions(localizer *i18n.Localizer, s setting, settings models.UserSettings) (string, tgbotapi.InlineKeyboardMarkup) {
+	text := fmt.Sprintf("<b>%s</b>: %s", s.Title, html.EscapeString(s.Current(settings)))
+
+	//По две кнопки в ряд, чтобы длинные списки не растягивали сообщение
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i := 0; i < len(s.Options); i += 2 {
+		var row []tgbotapi.InlineKeyboardButton
+		for _, option := range s.Options[i:minInt(i+2, len(s.Options))] {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(
+				option.Label,
+				fmt.Sprintf("%s:set:%s:%s", settingsCallbackPrefix, s.Key, option.Value),
+			))
+		}
+		rows = append(rows, row)
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(localizer.T("button.back"), settingsCallbackPrefix+":menu"),
+	))
+
+	return text, tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,199 @@
+package bot
+
+import (
+	"context"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/pkg/errors"
+	"html"
+	"strings"
+	"tg_todo_bot/src/models"
+	tasks_types "tg_todo_bot/src/services/tasks/types"
+	services_types "tg_todo_bot/src/services/types"
+	"time"
+)
+
+func (bot *Bot) handleAdd(ctx context.Context, message *tgbotapi.Message, user models.User) error {
+	localizer, now := userLocalizer(user), userNow(user)
+	datetime, hasTime, rest := parseDatetimePrefix(message.CommandArguments(), now.Location())
+	title, tags, priority := parseTitleMarks(rest)
+	if title == "" {
+		bot.reply(ctx, message, localizer.T("add.usage"))
+		return nil
+	}
+
+	params := tasks_types.CreateParams{
+		Title:    title,
+		Datetime: datetime,
+		Priority: priority,
+		Tags:     tags,
+		UserID:   user.ID,
+	}
+	if hasTime && datetime.After(now) {
+		//Если заранее напомнить уже поздно, напоминаем ко времени задачи
+		notifyAt := datetime.Add(-user.Settings.ReminderOffset)
+		if notifyAt.Before(now) {
+			notifyAt = *datetime
+		}
+
+		params.Notification = &tasks_types.NotificationParams{
+			NotifyAt:       notifyAt,
+			RepeatInterval: user.Settings.RepeatInterval,
+		}
+	}
+
+	task, err := bot.tasksService.Create(ctx, params)
+	if errors.Is(err, services_types.ErrRateLimited) {
+		bot.reply(ctx, message, throttledText(user, err))
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "bot.tasksService.Create(ctx, params)")
+	}
+
+	bot.reply(ctx, message, localizer.T("add.done", formatTask(localizer, task, now)))
+
+	return nil
+}
+
+func (bot *Bot) handleToday(ctx context.Context, message *tgbotapi.Message, user models.User) error {
+	text, err := bot.todayText(ctx, user)
+	if err != nil {
+		return err
+	}
+
+	bot.reply(ctx, message, text)
+
+	return nil
+}
+
+// todayText (ctx, user) -> overdue, today's and blocked tasks of the user, sorted according to the user's settings
+func (bot *Bot) todayText(ctx context.Context, user models.User) (string, error) {
+	localizer, now := userLocalizer(user), userNow(user)
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	to := from.AddDate(0, 0, 1).Add(-time.Nanosecond)
+
+	dateTasksMap, err := bot.tasksService.SearchByDateForUser(ctx, tasks_types.SearchByDateForUserParams{
+		From:   &from,
+		To:     &to,
+		UserID: user.ID,
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "bot.tasksService.SearchByDateForUser(ctx, params)")
+	}
+
+	overdueTasks, err := bot.tasksService.GetOverdueForUser(ctx, user.ID, now)
+	if err != nil {
+		return "", errors.Wrap(err, "bot.tasksService.GetOverdueForUser(ctx, userID, now)")
+	}
+
+	var todayTasks []models.Task
+	for _, tasks := range dateTasksMap {
+		todayTasks = append(todayTasks, tasks...)
+	}
+	sortTasks(todayTasks, user.Settings.ListSort)
+
+	var overdueLines, activeLines, blockedLines []string
+	for _, task := range overdueTasks {
+		//Сегодняшние просроченные задачи остаются в своём разделе
+		if !task.Datetime.Before(from) {
+			continue
+		}
+		overdueLines = append(overdueLines, formatTask(localizer, task, now))
+	}
+
+	for _, task := range todayTasks {
+		if len(task.BlockedBy) > 0 {
+			blockedLines = append(blockedLines, formatBlockedTask(localizer, task, now))
+		} else {
+			activeLines = append(activeLines, formatTask(localizer, task, now))
+		}
+	}
+
+	if len(overdueLines) == 0 && len(activeLines) == 0 && len(blockedLines) == 0 {
+		return localizer.T("today.empty"), nil
+	}
+
+	var sections []string
+	if len(overdueLines) > 0 {
+		sections = append(sections, localizer.T("section.overdue")+"\n"+strings.Join(overdueLines, "\n"))
+	}
+	if len(activeLines) > 0 {
+		sections = append(sections, localizer.T("section.today")+"\n"+strings.Join(activeLines, "\n"))
+	}
+	if len(blockedLines) > 0 {
+		sections = append(sections, localizer.T("section.blocked")+"\n"+strings.Join(blockedLines, "\n"))
+	}
+
+	return strings.Join(sections, "\n\n"), nil
+}
+
+// SendDigest sends the list for today to the user, for private chats chatID is equal to the user's telegram ID
+func (bot *Bot) SendDigest(ctx context.Context, user models.User) error {
+	user, err := bot.withSettings(ctx, user)
+	if err != nil {
+		return err
+	}
+
+	text, err := bot.todayText(ctx, user)
+	if err != nil {
+		return err
+	}
+
+	return bot.SendMessage(ctx, user.TelegramID, userLocalizer(user).T("digest.title")+"\n\n"+text)
+}
+
+// SendReminder sends the reminder about the task to its owner
+func (bot *Bot) SendReminder(ctx context.Context, user models.User, task models.Task) error {
+	user, err := bot.withSettings(ctx, user)
+	if err != nil {
+		return err
+	}
+
+	return bot.SendMessage(ctx, user.TelegramID, userLocalizer(user).T("reminder", task.ID, html.EscapeString(task.Title)))
+}
+
+// withSettings (ctx, user) -> the user with loaded settings, scheduler jobs get users without them
+func (bot *Bot) withSettings(ctx context.Context, user models.User) (models.User, error) {
+	if user.Settings != nil {
+		return user, nil
+	}
+
+	settings, err := bot.settingsService.Get(ctx, user.ID)
+	if err != nil {
+		return models.User{}, errors.Wrap(err, "bot.settingsService.Get(userID)")
+	}
+	user.Settings = &settings
+
+	return user, nil
+}
+
+func (bot *Bot) handleDone(ctx context.Context, message *tgbotapi.Message, user models.User) error {
+	localizer := userLocalizer(user)
+	taskID, err := parseTaskID(message.CommandArguments())
+	if err != nil {
+		bot.reply(ctx, message, localizer.T("done.usage"))
+		return nil
+	}
+
+	task, err := bot.findUserTask(ctx, user, taskID)
+	if err != nil {
+		if errors.Is(err, services_types.ErrNotFound) {
+			bot.reply(ctx, message, localizer.T("error.task_not_found"))
+			return nil
+		}
+		return errors.Wrap(err, "bot.findUserTask(ctx, user, taskID)")
+	}
+
+	unblockedTasks, err := bot.tasksService.Complete(ctx, task.ID)
+	if err != nil {
+		return errors.Wrap(err, "bot.tasksService.Complete(ctx, taskID)")
+	}
+
+	text := localizer.T("done.done", html.EscapeString(task.Title))
+	for _, unblockedTask := range unblockedTasks {
+		text += "\n" + localizer.T("done.unblocked", formatTask(localizer, unblockedTask, userNow(user)))
+	}
+	bot.reply(ctx, message, text)
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package bot
+
+import (
+	"context"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/pkg/errors"
+	"strings"
+	"tg_todo_bot/src/models"
+	tokens_types "tg_todo_bot/src/services/tokens/types"
+)
+
+// handleToken -> "/token" issues a new API token instead of the previous one, "/token revoke" revokes it
+func (bot *Bot) handleToken(ctx context.Context, message *tgbotapi.Message, user models.User) error {
+	localizer := userLocalizer(user)
+
+	//Токен в групповом чате увидят все участники
+	if !message.Chat.IsPrivate() {
+		bot.reply(ctx, message, localizer.T("token.private_only"))
+		return nil
+	}
+
+	if strings.TrimSpace(message.CommandArguments()) == "revoke" {
+		err := bot.tokensService.Revoke(ctx, user.ID, tokens_types.ScopeApi)
+		if err != nil {
+			return errors.Wrap(err, "bot.tokensService.Revoke(userID, scope)")
+		}
+		bot.reply(ctx, message, localizer.T("token.revoked"))
+		return nil
+	}
+
+	token, err := bot.tokensService.Issue(ctx, user.ID, tokens_types.ScopeApi)
+	if err != nil {
+		return errors.Wrap(err, "bot.tokensService.Issue(userID, scope)")
+	}
+
+	bot.reply(ctx, message, localizer.T("token.issued", token))
+
+	return nil
+}
+
+// handleCalendar -> "/ical" issues a new link to the calendar feed instead of the previous one, "/ical revoke" revokes it
+func (bot *Bot) handleCalendar(ctx context.Context, message *tgbotapi.Message, user models.User) error {
+	localizer := userLocalizer(user)
+
+	//По ссылке видны все задачи с датами
+	if !message.Chat.IsPrivate() {
+		bot.reply(ctx, message, localizer.T("ical.private_only"))
+		return nil
+	}
+
+	if strings.TrimSpace(message.CommandArguments()) == "revoke" {
+		err := bot.tokensService.Revoke(ctx, user.ID, tokens_types.ScopeCalendar)
+		if err != nil {
+			return errors.Wrap(err, "bot.tokensService.Revoke(userID, scope)")
+		}
+		bot.reply(ctx, message, localizer.T("ical.revoked"))
+		return nil
+	}
+
+	token, err := bot.tokensService.Issue(ctx, user.ID, tokens_types.ScopeCalendar)
+	if err != nil {
+		return errors.Wrap(err, "bot.tokensService.Issue(userID, scope)")
+	}
+
+	bot.reply(ctx, message, localizer.T("ical.issued", calendarFeedURL(bot.apiURL, token)))
+
+	return nil
+}
+
+// handleCalDAV -> "/caldav" issues a new CalDAV password instead of the previous one, "/caldav revoke" revokes it
+func (bot *Bot) handleCalDAV(ctx context.Context, message *tgbotapi.Message, user models.User) error {
+	localizer := userLocalizer(user)
+
+	//Пароль даёт доступ на изменение всех задач
+	if !message.Chat.IsPrivate() {
+		bot.reply(ctx, message, localizer.T("caldav.private_only"))
+		return nil
+	}
+
+	if strings.TrimSpace(message.CommandArguments()) == "revoke" {
+		err := bot.tokensService.Revoke(ctx, user.ID, tokens_types.ScopeCalDAV)
+		if err != nil {
+			return errors.Wrap(err, "bot.tokensService.Revoke(userID, scope)")
+		}
+		bot.reply(ctx, message, localizer.T("caldav.revoked"))
+		return nil
+	}
+
+	token, err := bot.tokensService.Issue(ctx, user.ID, tokens_types.ScopeCalDAV)
+	if err != nil {
+		return errors.Wrap(err, "bot.tokensService.Issue(userID, scope)")
+	}
+
+	bot.reply(ctx, message, localizer.T("caldav.issued", strings.TrimSuffix(bot.apiURL, "/")+"/caldav/", token))
+
+	return nil
+}
+
+// calendarFeedURL ("https://todo.example.com/", token) -> "https://todo.example.com/ical/token.ics"
+func calendarFeedURL(apiURL, token string) string {
+	return strings.TrimSuffix(apiURL, "/") + "/ical/" + token + ".ics"
+}
@@ -0,0 +1,55 @@
+package bot
+
+import (
+	"sync"
+	"time"
+)
+
+const updatesStatsHours = 24
+
+// updatesStats counts handled updates and failed ones by hour for /stats.
+// The counters live in memory, after a restart the error rate is counted anew
+type updatesStats struct {
+	mutex   sync.Mutex
+	buckets [updatesStatsHours]updatesBucket
+}
+
+type updatesBucket struct {
+	hour   time.Time
+	total  int
+	failed int
+}
+
+func (stats *updatesStats) record(now time.Time, failed bool) {
+	stats.mutex.Lock()
+	defer stats.mutex.Unlock()
+
+	hour := now.Truncate(time.Hour)
+	bucket := &stats.buckets[hour.Unix()/3600%updatesStatsHours]
+	//В ячейке могут лежать счётчики суточной давности
+	if !bucket.hour.Equal(hour) {
+		*bucket = updatesBucket{hour: hour}
+	}
+
+	bucket.total++
+	if failed {
+		bucket.failed++
+	}
+}
+
+// sum (now) -> numbers of updates and errors for the last updatesStatsHours hours
+func (stats *updatesStats) sum(now time.Time) (total, failed int) {
+	stats.mutex.Lock()
+	defer stats.mutex.Unlock()
+
+	from := now.Truncate(time.Hour).Add(-(updatesStatsHours - 1) * time.Hour)
+	for _, bucket := range stats.buckets {
+		if bucket.hour.Before(from) {
+			continue
+		}
+		total += bucket.total
+		failed += bucket.failed
+	}
+
+	return total, failed
+}
@@ -0,0 +1,30 @@
+package bot
+
+import (
+	"context"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/pkg/errors"
+	"tg_todo_bot/src/i18n"
+	"tg_todo_bot/src/models"
+	settings_types "tg_todo_bot/src/services/settings/types"
+)
+
+// handleStart -> help text, on the first /start the language is taken from the Telegram client,
+// after that only /settings changes it
+func (bot *Bot) handleStart(ctx context.Context, message *tgbotapi.Message, user models.User) error {
+	//Настройки без даты изменения ещё не сохранялись, это значения по умолчанию
+	if message.Command() == "start" && user.Settings.UpdatedAt.IsZero() {
+		params := settings_types.UpdateParams{UserID: user.ID}
+		params.Language.Value, params.Language.IsSet = i18n.FromTelegram(message.From.LanguageCode), true
+
+		settings, err := bot.settingsService.Update(ctx, params)
+		if err != nil {
+			return errors.Wrap(err, "bot.settingsService.Update(params)")
+		}
+		user.Settings = &settings
+	}
+
+	bot.reply(ctx, message, userLocalizer(user).T("help"))
+
+	return nil
+}
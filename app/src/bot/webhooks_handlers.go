@@ -0,0 +1,75 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/pkg/errors"
+	"html"
+	"strconv"
+	"strings"
+	"tg_todo_bot/src/models"
+	services_types "tg_todo_bot/src/services/types"
+	webhooks_types "tg_todo_bot/src/services/webhooks/types"
+)
+
+// handleWebhook -> "/webhook" lists webhooks, "/webhook add URL" registers one, "/webhook del ID" deletes it
+func (bot *Bot) handleWebhook(ctx context.Context, message *tgbotapi.Message, user models.User) error {
+	localizer := userLocalizer(user)
+
+	//Секрет вебхука в групповом чате увидят все участники
+	if !message.Chat.IsPrivate() {
+		bot.reply(ctx, message, localizer.T("webhook.private_only"))
+		return nil
+	}
+
+	action, argument, _ := strings.Cut(strings.TrimSpace(message.CommandArguments()), " ")
+	argument = strings.TrimSpace(argument)
+
+	switch {
+	case action == "":
+		webhooks, err := bot.webhooksService.GetAllForUser(ctx, user.ID)
+		if err != nil {
+			return errors.Wrap(err, "bot.webhooksService.GetAllForUser(userID)")
+		}
+		if len(webhooks) == 0 {
+			bot.reply(ctx, message, localizer.T("webhook.list_empty"))
+			return nil
+		}
+
+		lines := []string{localizer.T("webhook.list_title")}
+		for _, webhook := range webhooks {
+			lines = append(lines, fmt.Sprintf("%d. <code>%s</code>", webhook.ID, html.EscapeString(webhook.URL)))
+		}
+		bot.reply(ctx, message, strings.Join(lines, "\n"))
+	case action == "add" && argument != "":
+		webhook, err := bot.webhooksService.Register(ctx, webhooks_types.RegisterParams{UserID: user.ID, URL: argument})
+		if errors.Is(err, services_types.ErrInvalidParams) {
+			bot.reply(ctx, message, localizer.T("webhook.invalid", html.EscapeString(err.Error())))
+			return nil
+		}
+		if err != nil {
+			return errors.Wrap(err, "bot.webhooksService.Register(params)")
+		}
+		bot.reply(ctx, message, localizer.T("webhook.added", webhook.ID, html.EscapeString(webhook.URL), webhook.Secret))
+	case action == "del" && argument != "":
+		webhookID, err := strconv.ParseInt(argument, 10, 64)
+		if err != nil {
+			bot.reply(ctx, message, localizer.T("webhook.usage"))
+			return nil
+		}
+		err = bot.webhooksService.DeleteForUser(ctx, user.ID, webhookID)
+		if errors.Is(err, services_types.ErrNotFound) {
+			bot.reply(ctx, message, localizer.T("webhook.not_found"))
+			return nil
+		}
+		if err != nil {
+			return errors.Wrap(err, "bot.webhooksService.DeleteForUser(userID, webhookID)")
+		}
+		bot.reply(ctx, message, localizer.T("webhook.deleted", webhookID))
+	default:
+		bot.reply(ctx, message, localizer.T("webhook.usage"))
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package exporters
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"tg_todo_bot/src/models"
+	"time"
+)
+
+// csvHeader -> names of the first columns are the ones the CSV importer looks for
+var csvHeader = []string{
+	"title",
+	"description",
+	"due",
+	"priority",
+	"tags",
+	"done",
+	"id",
+	"blocked_by",
+	"notify_at",
+	"repeat_interval_minutes",
+	"created_at",
+}
+
+type csvWriter struct {
+	writer *csv.Writer
+	params Params
+}
+
+func newCSVWriter(w io.Writer, params Params) (*csvWriter, error) {
+	writer := &csvWriter{writer: csv.NewWriter(w), params: params}
+
+	err := writer.writer.Write(csvHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	return writer, nil
+}
+
+func (writer *csvWriter) Write(tasks []models.Task) error {
+	for _, task := range tasks {
+		var blockers []string
+		for _, blockerID := range task.BlockedBy {
+			blockers = append(blockers, strconv.FormatInt(blockerID, 10))
+		}
+
+		var notifyAt, repeatInterval string
+		if task.Notification != nil {
+			notifyAt = writer.formatTime(&task.Notification.NotifyAt)
+			repeatInterval = strconv.FormatInt(int64(task.Notification.RepeatInterval/time.Minute), 10)
+		}
+
+		err := writer.writer.Write([]string{
+			task.Title,
+			task.Description,
+			writer.formatTime(task.Datetime),
+			strconv.Itoa(task.Priority),
+			strings.Join(task.Tags, " "),
+			strconv.FormatBool(task.Done),
+			strconv.FormatInt(task.ID, 10),
+			strings.Join(blockers, " "),
+			notifyAt,
+			repeatInterval,
+			writer.formatTime(&task.CreatedAt),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	//Страница уходит клиенту сразу, а не копится в буфере
+	writer.writer.Flush()
+
+	return writer.writer.Error()
+}
+
+func (writer *csvWriter) Close() error {
+	writer.writer.Flush()
+	return writer.writer.Error()
+}
+
+// formatTime (datetime) -> RFC 3339 in the user's timezone, empty for nil
+func (writer *csvWriter) formatTime(datetime *time.Time) string {
+	if datetime == nil {
+		return ""
+	}
+
+	return datetime.In(writer.params.Location).Format(time.RFC3339)
+}
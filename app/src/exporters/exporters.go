@@ -0,0 +1,74 @@
+package exporters
+
+import (
+	"fmt"
+	"io"
+	"tg_todo_bot/src/models"
+	"time"
+)
+
+const (
+	FormatJSON     = "json"     //all fields, the importer reads it back
+	FormatCSV      = "csv"      //for spreadsheets, the CSV importer reads it back
+	FormatMarkdown = "markdown" //checklist for notes apps in the syntax of /add
+
+	// JSONFormatName -> value of "format" in JSON exports, the importer recognizes exports of the bot by it
+	JSONFormatName = "tg_todo_bot"
+	JSONVersion    = 1
+
+	dateLayout     = "02.01.2006"
+	datetimeLayout = "02.01.2006 15:04"
+)
+
+var Formats = []string{FormatJSON, FormatCSV, FormatMarkdown}
+
+var ErrUnknownFormat = fmt.Errorf("unknown export format")
+
+var extensions = map[string]string{
+	FormatJSON:     ".json",
+	FormatCSV:      ".csv",
+	FormatMarkdown: ".md",
+}
+
+// Params -> times are written in Location, Now is the time of the export
+type Params struct {
+	Location *time.Location
+	Now      time.Time
+}
+
+// Writer -> writes tasks page by page as they are loaded, nothing is kept in memory between pages.
+// Close writes the end of the document, it doesn't close the underlying writer
+type Writer interface {
+	Write(tasks []models.Task) error
+	Close() error
+}
+
+// NewWriter (format, w, params) -> ErrUnknownFormat for formats not in Formats
+func NewWriter(format string, w io.Writer, params Params) (Writer, error) {
+	if params.Location == nil {
+		params.Location = time.UTC
+	}
+	params.Now = params.Now.In(params.Location)
+
+	switch format {
+	case FormatJSON:
+		return newJSONWriter(w, params)
+	case FormatCSV:
+		return newCSVWriter(w, params)
+	case FormatMarkdown:
+		return newMarkdownWriter(w, params)
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+// FileName (format, now) -> "tasks-2026-10-19.json"
+func FileName(format string, now time.Time) string {
+	return "tasks-" + now.Format("2006-01-02") + extensions[format]
+}
+
+// isDateOnly (datetime) -> tasks without time are 00:00 in the user's timezone
+func isDateOnly(datetime time.Time) bool {
+	hour, minute, second := datetime.Clock()
+	return hour == 0 && minute == 0 && second == 0
+}
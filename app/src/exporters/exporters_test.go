@@ -0,0 +1,112 @@
+package exporters
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"tg_todo_bot/src/models"
+	"time"
+)
+
+func testTasks(location *time.Location) []models.Task {
+	due := time.Date(2026, 10, 20, 15, 0, 0, 0, location)
+	day := time.Date(2026, 10, 21, 0, 0, 0, 0, location)
+	createdAt := time.Date(2026, 10, 1, 9, 30, 0, 0, time.UTC)
+
+	return []models.Task{
+		{
+			ID:          1,
+			Title:       "Dentist",
+			Description: "Bring the card\nSecond floor",
+			Datetime:    &due,
+			Priority:    1,
+			Tags:        []string{"health"},
+			CreatedAt:   createdAt,
+			Notification: &models.Notification{
+				NotifyAt:       due.Add(-time.Hour),
+				RepeatInterval: 24 * time.Hour,
+			},
+		},
+		{ID: 2, Title: "Report, \"Q4\"", Datetime: &day, BlockedBy: []int64{1}, CreatedAt: createdAt},
+		{ID: 3, Title: "Old", Done: true, CreatedAt: createdAt},
+	}
+}
+
+func TestWriters(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := Params{Location: moscow, Now: time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)}
+
+	testCases := []struct {
+		format   string
+		expected string
+	}{
+		{
+			format: FormatJSON,
+			expected: `{"format":"tg_todo_bot","version":1,"exported_at":"2026-10-19T12:00:00+03:00","tasks":[` + "\n" +
+				`{"id":1,"title":"Dentist","description":"Bring the card\nSecond floor","due":"2026-10-20T15:00:00+03:00","done":false,"priority":1,"tags":["health"],"blocked_by":[],"notification":{"notify_at":"2026-10-20T14:00:00+03:00","repeat_interval_minutes":1440},"created_at":"2026-10-01T12:30:00+03:00"},` + "\n" +
+				`{"id":2,"title":"Report, \"Q4\"","description":"","due":"2026-10-21T00:00:00+03:00","done":false,"priority":0,"tags":[],"blocked_by":[1],"notification":null,"created_at":"2026-10-01T12:30:00+03:00"},` + "\n" +
+				`{"id":3,"title":"Old","description":"","due":null,"done":true,"priority":0,"tags":[],"blocked_by":[],"notification":null,"created_at":"2026-10-01T12:30:00+03:00"}` + "\n" +
+				"]}\n",
+		},
+		{
+			format: FormatCSV,
+			expected: "title,description,due,priority,tags,done,id,blocked_by,notify_at,repeat_interval_minutes,created_at\n" +
+				"Dentist,\"Bring the card\nSecond floor\",2026-10-20T15:00:00+03:00,1,health,false,1,,2026-10-20T14:00:00+03:00,1440,2026-10-01T12:30:00+03:00\n" +
+				"\"Report, \"\"Q4\"\"\",,2026-10-21T00:00:00+03:00,0,,false,2,1,,,2026-10-01T12:30:00+03:00\n" +
+				"Old,,,0,,true,3,,,,2026-10-01T12:30:00+03:00\n",
+		},
+		{
+			format: FormatMarkdown,
+			expected: "# Tasks 19.10.2026 12:00\n\n" +
+				"- [ ] 20.10.2026 15:00 Dentist #health !1\n" +
+				"  > Bring the card\n" +
+				"  > Second floor\n" +
+				"  > 🔔 20.10.2026 14:00\n" +
+				"- [ ] 21.10.2026 Report, \"Q4\"\n" +
+				"  > ⛔ #1\n" +
+				"- [x] Old\n",
+		},
+	}
+
+	for _, testCase := range testCases {
+		var buffer bytes.Buffer
+		writer, err := NewWriter(testCase.format, &buffer, params)
+		if err != nil {
+			t.Fatalf("%s: %v", testCase.format, err)
+		}
+
+		//Задачи пишутся страницами, как их отдаёт сервис
+		tasks := testTasks(moscow)
+		for _, page := range [][]models.Task{tasks[:2], tasks[2:]} {
+			err = writer.Write(page)
+			if err != nil {
+				t.Fatalf("%s: %v", testCase.format, err)
+			}
+		}
+		err = writer.Close()
+		if err != nil {
+			t.Fatalf("%s: %v", testCase.format, err)
+		}
+
+		if buffer.String() != testCase.expected {
+			t.Fatalf("%s: got %q, expected %q", testCase.format, buffer.String(), testCase.expected)
+		}
+	}
+}
+
+func TestNewWriterUnknownFormat(t *testing.T) {
+	_, err := NewWriter("xml", &bytes.Buffer{}, Params{})
+	if !errors.Is(err, ErrUnknownFormat) {
+		t.Fatalf("got %v, expected %v", err, ErrUnknownFormat)
+	}
+}
+
+func TestFileName(t *testing.T) {
+	got := FileName(FormatMarkdown, time.Date(2026, 10, 19, 23, 0, 0, 0, time.UTC))
+	if got != "tasks-2026-10-19.md" {
+		t.Fatalf("got %s, expected tasks-2026-10-19.md", got)
+	}
+}
@@ -0,0 +1,107 @@
+package exporters
+
+import (
+	"encoding/json"
+	"io"
+	"tg_todo_bot/src/models"
+	"time"
+)
+
+type jsonTask struct {
+	ID           int64             `json:"id"`
+	Title        string            `json:"title"`
+	Description  string            `json:"description"`
+	Due          *time.Time        `json:"due"`
+	Done         bool              `json:"done"`
+	Priority     int               `json:"priority"`
+	Tags         []string          `json:"tags"`
+	BlockedBy    []int64           `json:"blocked_by"`
+	Notification *jsonNotification `json:"notification"`
+	CreatedAt    time.Time         `json:"created_at"`
+}
+
+type jsonNotification struct {
+	NotifyAt              time.Time `json:"notify_at"`
+	RepeatIntervalMinutes int64     `json:"repeat_interval_minutes"`
+}
+
+// jsonWriter -> {"format": "tg_todo_bot", "version": 1, "exported_at": ..., "tasks": [...]},
+// the header is written by hand, so tasks can be streamed into the array
+type jsonWriter struct {
+	w        io.Writer
+	params   Params
+	hasTasks bool
+}
+
+func newJSONWriter(w io.Writer, params Params) (*jsonWriter, error) {
+	header, err := json.Marshal(struct {
+		Format     string    `json:"format"`
+		Version    int       `json:"version"`
+		ExportedAt time.Time `json:"exported_at"`
+	}{JSONFormatName, JSONVersion, params.Now})
+	if err != nil {
+		return nil, err
+	}
+
+	//Заголовок без закрывающей скобки, массив задач дописывается следом
+	_, err = io.WriteString(w, string(header[:len(header)-1])+`,"tasks":[`)
+	if err != nil {
+		return nil, err
+	}
+
+	return &jsonWriter{w: w, params: params}, nil
+}
+
+func (writer *jsonWriter) Write(tasks []models.Task) error {
+	for _, task := range tasks {
+		item := jsonTask{
+			ID:          task.ID,
+			Title:       task.Title,
+			Description: task.Description,
+			Done:        task.Done,
+			Priority:    task.Priority,
+			Tags:        task.Tags,
+			BlockedBy:   task.BlockedBy,
+			CreatedAt:   task.CreatedAt.In(writer.params.Location),
+		}
+		if item.Tags == nil {
+			item.Tags = []string{}
+		}
+		if item.BlockedBy == nil {
+			item.BlockedBy = []int64{}
+		}
+		if task.Datetime != nil {
+			due := task.Datetime.In(writer.params.Location)
+			item.Due = &due
+		}
+		if task.Notification != nil {
+			item.Notification = &jsonNotification{
+				NotifyAt:              task.Notification.NotifyAt.In(writer.params.Location),
+				RepeatIntervalMinutes: int64(task.Notification.RepeatInterval / time.Minute),
+			}
+		}
+
+		data, err := json.Marshal(item)
+		if err != nil {
+			return err
+		}
+
+		separator := "\n"
+		if writer.hasTasks {
+			separator = ",\n"
+		}
+		writer.hasTasks = true
+
+		_, err = io.WriteString(writer.w, separator+string(data))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (writer *jsonWriter) Close() error {
+	_, err := io.WriteString(writer.w, "\n]}\n")
+	return err
+}
@@ -0,0 +1,90 @@
+package exporters
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"tg_todo_bot/src/models"
+	"time"
+)
+
+// markdownWriter -> "- [ ] 20.10.2026 15:00 Title #tag !1" like /add, the description, the reminder and blockers
+// are quoted under the task. The text importer reads the file back, quotes become the description
+type markdownWriter struct {
+	w      io.Writer
+	params Params
+}
+
+func newMarkdownWriter(w io.Writer, params Params) (*markdownWriter, error) {
+	//Дата выгрузки в заголовке, импорт пропускает заголовки
+	_, err := fmt.Fprintf(w, "# Tasks %s\n\n", params.Now.Format(datetimeLayout))
+	if err != nil {
+		return nil, err
+	}
+
+	return &markdownWriter{w: w, params: params}, nil
+}
+
+func (writer *markdownWriter) Write(tasks []models.Task) error {
+	var builder strings.Builder
+
+	for _, task := range tasks {
+		checkbox := "- [ ] "
+		if task.Done {
+			checkbox = "- [x] "
+		}
+		builder.WriteString(checkbox)
+		if task.Datetime != nil {
+			builder.WriteString(writer.formatTime(*task.Datetime) + " ")
+		}
+		builder.WriteString(strings.Join(strings.Fields(task.Title), " "))
+		for _, tag := range task.Tags {
+			builder.WriteString(" #" + tag)
+		}
+		if task.Priority > 0 {
+			builder.WriteString(" !" + strconv.Itoa(task.Priority))
+		}
+		builder.WriteString("\n")
+
+		var quotes []string
+		if task.Description != "" {
+			quotes = append(quotes, strings.Split(strings.TrimSpace(task.Description), "\n")...)
+		}
+		if task.Notification != nil {
+			quotes = append(quotes, "🔔 "+writer.formatTime(task.Notification.NotifyAt))
+		}
+		if len(task.BlockedBy) > 0 {
+			var blockers []string
+			for _, blockerID := range task.BlockedBy {
+				blockers = append(blockers, "#"+strconv.FormatInt(blockerID, 10))
+			}
+			quotes = append(quotes, "⛔ "+strings.Join(blockers, ", "))
+		}
+		for _, quote := range quotes {
+			builder.WriteString("  > " + strings.TrimRight(quote, "\r") + "\n")
+		}
+
+		_, err := io.WriteString(writer.w, builder.String())
+		if err != nil {
+			return err
+		}
+		builder.Reset()
+	}
+
+	return nil
+}
+
+func (writer *markdownWriter) Close() error {
+	return nil
+}
+
+// formatTime (datetime) -> date and time of the user, only the date for tasks without time
+func (writer *markdownWriter) formatTime(datetime time.Time) string {
+	datetime = datetime.In(writer.params.Location)
+	if isDateOnly(datetime) {
+		return datetime.Format(dateLayout)
+	}
+
+	return datetime.Format(datetimeLayout)
+}
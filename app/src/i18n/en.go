@@ -0,0 +1,243 @@
+package i18n
+
+var enCatalog = catalog{
+	pluralForms: 2,
+	pluralForm:  enPluralForm,
+	months:      [12]string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+	messages: map[string]string{
+		"format.date":       "%[2]s %[1]d, %[3]d",
+		"format.short_date": "%[2]s %[1]d",
+		"format.time":       "3:04 PM",
+		"duration.days":     "%d d",
+		"duration.hours":    "%d h",
+		"duration.minutes":  "%d min",
+
+		"help": `I'll help you keep track of things to do.
+
+/add [dd.mm.yyyy [hh:mm]] title [#tag] [!1-3] — add a task
+/today — tasks for today
+/overdue — overdue tasks
+/list — all active tasks
+/list query — tasks matching a filter, e.g.: /list due:&lt;7d tag:work prio:1 -done sort:created
+/f — saved filters, /f name — tasks matching a saved filter
+/fsave name query — save a filter
+/fdel name — delete a filter
+/search text — search tasks, also works as @bot text in any chat
+/done ID — mark a task as done
+/block ID BLOCKER_ID — the task waits for another one
+/unblock ID BLOCKER_ID — remove the dependency
+/escalate — reminders about overdue tasks
+/settings — language, timezone, reminders and daily plan
+/token — token for the REST API
+/webhook — webhooks for task events
+/ical — calendar link for phone calendars
+/caldav — sync tasks with CalDAV apps
+/import — import tasks from Todoist, Trello, CSV or a text file
+/export — export all tasks as JSON, CSV or Markdown
+/deleteme — delete the account and all data`,
+
+		"error.unknown_command":             "Unknown command. List of commands: /help",
+		"error.internal":                    "Something went wrong, please try again later",
+		"error.timeout":                     "The server took too long to answer, please try again in a minute",
+		"error.task_not_found":              "Task not found",
+		"ical.private_only":                 "A calendar link can only be issued in a private chat with the bot",
+		"ical.revoked":                      "The calendar link is revoked, calendars subscribed to it no longer get updates",
+		"ical.issued":                       "Link to the calendar of your tasks with dates, the previous link no longer works:\n<code>%s</code>\n\nAdd it as a calendar subscription by URL in the phone or Google Calendar. Add <code>?type=todo</code> to get tasks instead of events. Revoke: /ical revoke",
+		"ical.name":                         "Tasks",
+		"import.usage":                      "Send a file to the bot in a private chat to import tasks from it:\n• Todoist — CSV export of a project\n• Trello — JSON export of a board\n• CSV with a header: title, description, due, priority (1-3), tags, done\n• text — one task per line like in /add: <code>20.10.2026 15:00 Title #tag !1</code>\n• files of /export\n\nBefore the import you'll see what is found in the file. At most %d tasks, files up to 1 MB.",
+		"import.format.todoist":             "Todoist",
+		"import.format.trello":              "Trello",
+		"import.format.csv":                 "CSV",
+		"import.format.text":                "text",
+		"import.format.export":              "export of the bot",
+		"import.preview":                    "<b>Import from %s</b>: %d tasks",
+		"import.skipped":                    "Completed and archived entries skipped: %d",
+		"import.more":                       "…and %d more",
+		"import.confirm":                    "Import %d",
+		"import.cancel":                     "Cancel",
+		"import.cancelled":                  "Import cancelled",
+		"import.expired":                    "The file of this import is no longer available, send it again",
+		"import.in_progress":                "Importing…",
+		"import.done":                       "Imported tasks: %d. List of tasks: /list",
+		"import.too_big":                    "The file is too big, at most %d KB",
+		"import.empty":                      "No tasks found in the file. Supported formats: /import",
+		"import.too_many":                   "There are more than %d tasks in the file, split it into several files",
+		"import.invalid":                    "Can't import the file: %s",
+		"export.usage":                      "Choose the format of the file with all your tasks, reminders and tags:\n• JSON — everything, send the file back to the bot to restore tasks\n• CSV — for spreadsheets\n• Markdown — checklist for notes apps\n\nOr right away: /export json, /export csv, /export md",
+		"export.private_only":               "Tasks can only be exported in a private chat with the bot",
+		"export.format.json":                "JSON",
+		"export.format.csv":                 "CSV",
+		"export.format.markdown":            "Markdown",
+		"export.in_progress":                "Exporting…",
+		"export.done":                       "Export is ready ⬇️",
+		"export.caption.json":               "All tasks. Send this file to the bot to import active tasks back",
+		"export.caption.csv":                "All tasks for spreadsheets",
+		"export.caption.markdown":           "All tasks as a checklist",
+		"deleteme.private_only":             "An account can only be deleted in a private chat with the bot",
+		"deleteme.button.confirm":           "🗑 Delete my account",
+		"deleteme.button.keep":              "Keep",
+		"deleteme.button.cancel":            "Cancel the deletion",
+		"deleteme.requested":                "The account will be deleted after %s. Until then the deletion can be cancelled: /deleteme",
+		"deleteme.pending":                  "The account will be deleted after %s. Cancel the deletion?",
+		"deleteme.cancelled":                "Deletion cancelled, the account stays",
+		"deleteme.kept":                     "The account stays",
+		"deleteme.final_export":             "Your account is deleted as you asked with /deleteme. Here are all your tasks, send this file to the bot to import them into a new account",
+		"admin.user_usage":                  "Specify the telegram ID: /%s TELEGRAM_ID",
+		"admin.user_not_found":              "User with telegram ID %d not found",
+		"admin.ban.self":                    "You can't ban yourself",
+		"admin.ban.done":                    "User %d is banned, the bot ignores them",
+		"admin.unban.done":                  "User %d is unbanned",
+		"admin.user.title":                  "<b>User %d</b> (ID %d)",
+		"admin.user.created":                "Registered: %s",
+		"admin.user.last_seen":              "Last seen: %s",
+		"admin.user.tasks":                  "Tasks: %d active, %d completed, %d overdue",
+		"admin.user.banned":                 "⛔ Banned: %s",
+		"admin.user.ban_reason":             "Reason: %s",
+		"admin.user.deletion_requested":     "🗑 Deletion requested: %s",
+		"admin.broadcast.usage":             "/broadcast text — send HTML text to all users after a preview\n/broadcast — state of the last broadcast\n/broadcast cancel — stop it",
+		"admin.broadcast.invalid":           "Telegram doesn't accept the text: %s",
+		"admin.broadcast.button.start":      "📣 Send to everyone",
+		"admin.broadcast.button.cancel":     "Cancel",
+		"admin.broadcast.started":           "Broadcast %d started, about 20 messages per second. State: /broadcast",
+		"admin.broadcast.cancelled":         "Broadcast %d cancelled",
+		"admin.broadcast.not_draft":         "Broadcast %d is already started or cancelled. State: /broadcast",
+		"admin.broadcast.nothing_to_cancel": "No broadcast to cancel",
+		"admin.broadcast.status":            "<b>Broadcast %d</b>: %s\nSent: %d, failed: %d\nCreated: %s\nFinished: %s",
+		"admin.broadcast.status.draft":      "waits for confirmation",
+		"admin.broadcast.status.running":    "in progress",
+		"admin.broadcast.status.done":       "finished",
+		"admin.broadcast.status.cancelled":  "cancelled",
+		"caldav.private_only":               "A CalDAV password can only be issued in a private chat with the bot",
+		"caldav.revoked":                    "The CalDAV password is revoked, apps using it can no longer sync tasks",
+		"caldav.issued":                     "Add a CalDAV account in the app (Tasks.org, DAVx⁵, Apple Reminders, Thunderbird), the previous password no longer works:\nServer: <code>%s</code>\nLogin: any\nPassword: <code>%s</code>\n\nTasks changed in the app are changed in the bot too. Reminders are only set in the bot. Revoke: /caldav revoke",
+		"button.prev":                       "◀️ Back",
+		"button.next":                       "Next ▶️",
+		"button.back":                       "« Back",
+		"section.overdue":                   "<b>Overdue</b>",
+		"section.today":                     "<b>Today</b>",
+		"section.blocked":                   "<b>Blocked</b>",
+		"section.active":                    "<b>Active tasks</b>",
+		"task.blocked":                      "🔒 <i>%s</i> (waits for %s)",
+		"add.usage":                         "Specify the task title: /add [dd.mm.yyyy [hh:mm]] title [#tag] [!1-3]",
+		"add.done":                          "Task added: %s",
+		"today.empty":                       "No tasks for today",
+		"digest.title":                      "☀️ <b>Plan for the day</b>",
+		"reminder":                          "🔔 #%d %s",
+		"done.usage":                        "Specify the task number: /done ID",
+		"done.done":                         "✅ Done: %s",
+		"done.unblocked":                    "🔓 Unblocked: %s",
+		"list.empty":                        "No active tasks",
+		"search.usage":                      "Specify what to search for: /search text",
+		"search.empty":                      "Nothing found",
+		"dependency.usage":                  "Usage: /%s ID BLOCKER_ID",
+		"dependency.wrong_id":               "Invalid task number: %s",
+		"dependency.not_found":              "Task #%d not found",
+		"dependency.self":                   "A task can't block itself",
+		"dependency.cycle":                  "Not allowed: task #%d already waits for task #%d, that would be a cycle",
+		"dependency.blocked":                "🔒 Task #%d waits for task #%d",
+		"dependency.unblocked":              "🔓 Task #%d no longer waits for task #%d",
+		"filter.not_found":                  "Filter «%s» not found. List of filters: /f",
+		"filter.save_usage":                 "Specify a name and a query: /fsave name query, e.g.: /fsave work tag:work prio:&lt;=2",
+		"filter.wrong_name":                 "A filter name may contain only letters, digits, «_» and «-», up to 64 characters",
+		"filter.saved":                      "Filter saved, show tasks: /f %s",
+		"filter.delete_usage":               "Specify the filter name: /fdel name",
+		"filter.deleted":                    "Filter «%s» deleted",
+		"filter.list_empty":                 "No saved filters. Save one: /fsave name query",
+		"filter.list_title":                 "<b>Saved filters</b>",
+		"filter.empty":                      "No tasks match the filter",
+		"filter.title":                      "<b>Tasks matching</b> <code>%s</code>",
+		"filter.syntax_error":               "Can't understand «%s»: %s\nExamples: due:&lt;7d, due:today, tag:work, -tag:home, prio:&lt;=2, done, done:any, sort:-created",
+		"overdue.empty":                     "No overdue tasks 👍",
+		"escalate.reset":                    "Reminder settings reset",
+		"escalate.invalid":                  "The repeat interval must be at least 10 minutes, reminders — at most 10",
+		"escalate.saved":                    "Saved. %s",
+		"escalate.off":                      "Reminders about overdue tasks are off",
+		"escalation":                        "%s Task #%d is overdue by %s: %s",
+		"escalation.final":                  "This is the last reminder. Complete it: /done %d",
+		"settings.title":                    "<b>Settings</b>",
+		"settings.invalid":                  "Couldn't change «%s»",
+		"settings.lang":                     "Language",
+		"settings.tz":                       "Timezone",
+		"settings.offset":                   "Remind in advance",
+		"settings.offset.none":              "at the task time",
+		"settings.offset.value":             "%s before",
+		"settings.repeat":                   "Repeat reminder",
+		"settings.repeat.value":             "every %s",
+		"settings.sort":                     "Sorting",
+		"settings.sort.due":                 "by due date",
+		"settings.sort.prio":                "by priority",
+		"settings.sort.created":             "by creation date",
+		"settings.sort.title":               "by title",
+		"settings.digest":                   "Plan for the day",
+		"settings.digest.off":               "off",
+		"token.private_only":                "A token can only be issued in a private chat with the bot",
+		"token.revoked":                     "The token is revoked, requests with it no longer work",
+		"token.issued":                      "Your API token, the previous one no longer works:\n<code>%s</code>\n\nPass it in the <code>Authorization: Bearer token</code> header, the API is described in /openapi.yaml on the bot's server. Revoke: /token revoke",
+		"webhook.private_only":              "Webhooks can only be managed in a private chat with the bot",
+		"webhook.usage":                     "/webhook — list of webhooks\n/webhook add URL — send task events to the URL\n/webhook del ID — delete a webhook",
+		"webhook.list_empty":                "No webhooks. Add one: /webhook add URL",
+		"webhook.list_title":                "<b>Webhooks</b>",
+		"webhook.added":                     "Webhook %d added, events will be sent to <code>%s</code>.\n\nSecret:\n<code>%s</code>\n\nThe <code>X-Webhook-Signature</code> header is <code>sha256=</code> and the hex HMAC-SHA256 of the body with this secret. Failed deliveries are retried with increasing intervals.",
+		"webhook.invalid":                   "Can't add the webhook: %s",
+		"webhook.not_found":                 "Webhook not found. List of webhooks: /webhook",
+		"webhook.deleted":                   "Webhook %d deleted",
+		"tz.UTC":                            "UTC",
+		"tz.Europe/Kaliningrad":             "Kaliningrad",
+		"tz.Europe/Moscow":                  "Moscow",
+		"tz.Europe/Samara":                  "Samara",
+		"tz.Asia/Yekaterinburg":             "Yekaterinburg",
+		"tz.Asia/Omsk":                      "Omsk",
+		"tz.Asia/Novosibirsk":               "Novosibirsk",
+		"tz.Asia/Irkutsk":                   "Irkutsk",
+		"tz.Asia/Yakutsk":                   "Yakutsk",
+		"tz.Asia/Vladivostok":               "Vladivostok",
+		"tz.Asia/Magadan":                   "Magadan",
+		"tz.Asia/Kamchatka":                 "Kamchatka",
+
+		"admin.help": `<b>Admin commands</b>
+/stats — users and the error rate
+/broadcast — message to all users
+/ban TELEGRAM_ID [reason] — ignore the user
+/unban TELEGRAM_ID — stop ignoring
+/user TELEGRAM_ID — the account and numbers of tasks`,
+
+		"admin.stats": `<b>Users</b>: %d, banned %d, blocked the bot %d, waiting for deletion %d
+New for 24 h / 7 d / 30 d: %d / %d / %d
+Active for 24 h / 7 d / 30 d: %d / %d / %d
+
+<b>Updates for 24 h</b>: %d, errors %d (%.1f%%)`,
+
+		"escalate.usage": `Reminders about overdue tasks:
+/escalate — current settings
+/escalate 1h 1d 3 — the first 1 h after the deadline, then daily, 3 in total
+/escalate off — don't remind
+/escalate reset — default settings
+For a single task: /escalate #ID 30m 2h 5`,
+
+		"settings.usage": `Timezone and digest time can be set exactly:
+/settings tz Europe/Berlin
+/settings digest 07:30`,
+	},
+	plurals: map[string][]string{
+		"throttled": {
+			"Too many requests, please wait %d second",
+			"Too many requests, please wait %d seconds",
+		},
+		"search.found": {
+			"<b>Found %d task for «%s»</b>",
+			"<b>Found %d tasks for «%s»</b>",
+		},
+		"filter.more": {
+			"…showing the first %d task, refine the query",
+			"…showing the first %d tasks, refine the query",
+		},
+		"escalate.policy": {
+			"%d overdue reminder: the first %s after the deadline, then every %s",
+			"%d overdue reminders: the first %s after the deadline, then every %s",
+		},
+		"deleteme.confirm": {
+			"<b>Delete your account?</b>\n\nAll tasks, reminders, tags, filters, settings, API tokens, calendar links and webhooks will be deleted %d day after the confirmation. Until then everything works as usual and the deletion can be cancelled: /deleteme\n\nRight before the deletion the bot sends you a file with all tasks, send it back to the bot to restore them.",
+			"<b>Delete your account?</b>\n\nAll tasks, reminders, tags, filters, settings, API tokens, calendar links and webhooks will be deleted %d days after the confirmation. Until then everything works as usual and the deletion can be cancelled: /deleteme\n\nRight before the deletion the bot sends you a file with all tasks, send it back to the bot to restore them.",
+		},
+	},
+}
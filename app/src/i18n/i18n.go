@@ -0,0 +1,151 @@
+package i18n
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	LanguageRu = "ru"
+	LanguageEn = "en"
+
+	DefaultLanguage = LanguageRu
+)
+
+// catalog -> messages of one language, plurals keep a form per pluralForm result
+type catalog struct {
+	pluralForms int
+	pluralForm  func(n int) int
+	months      [12]string
+	messages    map[string]string
+	plurals     map[string][]string
+}
+
+var catalogs = map[string]catalog{
+	LanguageRu: ruCatalog,
+	LanguageEn: enCatalog,
+}
+
+// ruPluralForm (n) -> 0 for 1, 21, 101 ("задача"), 1 for 2-4, 22 ("задачи"), 2 for the rest ("задач")
+func ruPluralForm(n int) int {
+	if n < 0 {
+		n = -n
+	}
+
+	switch {
+	case n%10 == 1 && n%100 != 11:
+		return 0
+	case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+		return 1
+	default:
+		return 2
+	}
+}
+
+// enPluralForm (n) -> 0 for 1 ("task"), 1 for the rest ("tasks")
+func enPluralForm(n int) int {
+	if n == 1 || n == -1 {
+		return 0
+	}
+
+	return 1
+}
+
+// Supported (language) -> whether there is a catalog for the language
+func Supported(language string) bool {
+	_, exist := catalogs[language]
+	return exist
+}
+
+// FromTelegram (languageCode) -> language for the Telegram language_code like "en-US",
+// English for unsupported languages, DefaultLanguage if the code is unknown
+func FromTelegram(languageCode string) string {
+	if languageCode == "" {
+		return DefaultLanguage
+	}
+
+	language, _, _ := strings.Cut(strings.ToLower(languageCode), "-")
+	if Supported(language) {
+		return language
+	}
+
+	return LanguageEn
+}
+
+// Localizer -> texts, plurals and dates in the user's language
+type Localizer struct {
+	Language string
+	catalog  catalog
+}
+
+// New (language) -> localizer of the language, DefaultLanguage if it isn't supported
+func New(language string) *Localizer {
+	if !Supported(language) {
+		language = DefaultLanguage
+	}
+
+	return &Localizer{
+		Language: language,
+		catalog:  catalogs[language],
+	}
+}
+
+// T (key, args) -> the message formatted with fmt.Sprintf, the key itself if the message is missing
+func (localizer *Localizer) T(key string, args ...interface{}) string {
+	message, exist := localizer.catalog.messages[key]
+	if !exist {
+		return key
+	}
+
+	if len(args) == 0 {
+		return message
+	}
+
+	return fmt.Sprintf(message, args...)
+}
+
+// N (key, n, args) -> the plural form for n formatted with n and args
+func (localizer *Localizer) N(key string, n int, args ...interface{}) string {
+	forms, exist := localizer.catalog.plurals[key]
+	if !exist {
+		return key
+	}
+
+	return fmt.Sprintf(forms[localizer.catalog.pluralForm(n)], append([]interface{}{n}, args...)...)
+}
+
+// Date (t) -> "19 окт 2026", "Oct 19, 2026"
+func (localizer *Localizer) Date(t time.Time) string {
+	return localizer.T("format.date", t.Day(), localizer.catalog.months[t.Month()-1], t.Year())
+}
+
+// ShortDate (t) -> date without the year: "19 окт", "Oct 19"
+func (localizer *Localizer) ShortDate(t time.Time) string {
+	return localizer.T("format.short_date", t.Day(), localizer.catalog.months[t.Month()-1])
+}
+
+// Time (t) -> "15:04", "3:04 PM"
+func (localizer *Localizer) Time(t time.Time) string {
+	return t.Format(localizer.T("format.time"))
+}
+
+// Duration (26h) -> "1 д 2 ч", "1 d 2 h", rounded down to minutes
+func (localizer *Localizer) Duration(duration time.Duration) string {
+	days := int(duration / (24 * time.Hour))
+	hours := int(duration % (24 * time.Hour) / time.Hour)
+	minutes := int(duration % time.Hour / time.Minute)
+
+	var parts []string
+	if days > 0 {
+		parts = append(parts, localizer.T("duration.days", days))
+	}
+	if hours > 0 {
+		parts = append(parts, localizer.T("duration.hours", hours))
+	}
+	if minutes > 0 || len(parts) == 0 {
+		parts = append(parts, localizer.T("duration.minutes", minutes))
+	}
+
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,109 @@
+package i18n
+
+import (
+	"strings"
+	"testing"
+	settings_types "tg_todo_bot/src/services/settings/types"
+	"time"
+)
+
+func TestCatalogsHaveAllKeys(t *testing.T) {
+	for language, languageCatalog := range catalogs {
+		for otherLanguage, otherCatalog := range catalogs {
+			for key := range otherCatalog.messages {
+				if _, exist := languageCatalog.messages[key]; !exist {
+					t.Errorf("message %q from %q catalog is missing in %q catalog", key, otherLanguage, language)
+				}
+			}
+			for key := range otherCatalog.plurals {
+				if _, exist := languageCatalog.plurals[key]; !exist {
+					t.Errorf("plural %q from %q catalog is missing in %q catalog", key, otherLanguage, language)
+				}
+			}
+		}
+
+		for key, forms := range languageCatalog.plurals {
+			if len(forms) != languageCatalog.pluralForms {
+				t.Errorf("plural %q of %q catalog has %d forms, expected %d", key, language, len(forms), languageCatalog.pluralForms)
+			}
+		}
+
+		for _, month := range languageCatalog.months {
+			if month == "" {
+				t.Errorf("%q catalog has an empty month name", language)
+			}
+		}
+	}
+}
+
+func TestSettingsLanguagesAreSupported(t *testing.T) {
+	for _, language := range settings_types.Languages {
+		if !Supported(language) {
+			t.Errorf("no catalog for the language %q", language)
+		}
+	}
+}
+
+func TestRuPluralForm(t *testing.T) {
+	testCases := map[int]int{0: 2, 1: 0, 2: 1, 4: 1, 5: 2, 11: 2, 12: 2, 14: 2, 21: 0, 22: 1, 25: 2, 101: 0, 111: 2, 112: 2, 122: 1}
+
+	for n, expected := range testCases {
+		form := ruPluralForm(n)
+		if form != expected {
+			t.Fatalf("%d: got form %d, expected %d", n, form, expected)
+		}
+	}
+}
+
+func TestLocalizer(t *testing.T) {
+	ru, en := New(LanguageRu), New(LanguageEn)
+	datetime := time.Date(2026, 10, 19, 15, 4, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		actual   string
+		expected string
+	}{
+		{name: "ru date", actual: ru.Date(datetime), expected: "19 окт 2026"},
+		{name: "en date", actual: en.Date(datetime), expected: "Oct 19, 2026"},
+		{name: "ru short date", actual: ru.ShortDate(datetime), expected: "19 окт"},
+		{name: "en time", actual: en.Time(datetime), expected: "3:04 PM"},
+		{name: "ru duration", actual: ru.Duration(26*time.Hour + 5*time.Minute), expected: "1 д 2 ч 5 мин"},
+		{name: "en zero duration", actual: en.Duration(0), expected: "0 min"},
+		{name: "ru plural", actual: ru.N("search.found", 3, "молоко"), expected: "<b>Найдено 3 задачи по запросу «молоко»</b>"},
+		{name: "en plural", actual: en.N("search.found", 1, "milk"), expected: "<b>Found 1 task for «milk»</b>"},
+		{name: "unknown language", actual: New("de").T("today.empty"), expected: ru.T("today.empty")},
+		{name: "missing key", actual: en.T("no.such.key"), expected: "no.such.key"},
+	}
+
+	for _, testCase := range testCases {
+		if testCase.actual != testCase.expected {
+			t.Fatalf("%s: got %q, expected %q", testCase.name, testCase.actual, testCase.expected)
+		}
+	}
+}
+
+func TestFromTelegram(t *testing.T) {
+	testCases := map[string]string{"": DefaultLanguage, "ru": LanguageRu, "en-US": LanguageEn, "EN": LanguageEn, "de": LanguageEn}
+
+	for languageCode, expected := range testCases {
+		language := FromTelegram(languageCode)
+		if language != expected {
+			t.Fatalf("%q: got %q, expected %q", languageCode, language, expected)
+		}
+	}
+}
+
+func TestMessagesHaveNoRawLessThan(t *testing.T) {
+	//Ответы уходят в HTML-режиме, «<» без тега Телеграм не примет
+	for language, languageCatalog := range catalogs {
+		for key, message := range languageCatalog.messages {
+			for _, part := range strings.Split(message, "<")[1:] {
+				if !strings.HasPrefix(part, "b>") && !strings.HasPrefix(part, "/") &&
+					!strings.HasPrefix(part, "i>") && !strings.HasPrefix(part, "code>") {
+					t.Errorf("message %q of %q catalog has unescaped «<»", key, language)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,248 @@
+package i18n
+
+var ruCatalog = catalog{
+	pluralForms: 3,
+	pluralForm:  ruPluralForm,
+	months:      [12]string{"янв", "фев", "мар", "апр", "мая", "июн", "июл", "авг", "сен", "окт", "ноя", "дек"},
+	messages: map[string]string{
+		"format.date":       "%[1]d %[2]s %[3]d",
+		"format.short_date": "%[1]d %[2]s",
+		"format.time":       "15:04",
+		"duration.days":     "%d д",
+		"duration.hours":    "%d ч",
+		"duration.minutes":  "%d мин",
+
+		"help": `Я помогу не забыть о делах.
+
+/add [дд.мм.гггг [чч:мм]] название [#тег] [!1-3] — добавить задачу
+/today — задачи на сегодня
+/overdue — просроченные задачи
+/list — все активные задачи
+/list запрос — задачи по фильтру, например: /list due:&lt;7d tag:work prio:1 -done sort:created
+/f — сохранённые фильтры, /f имя — задачи по сохранённому фильтру
+/fsave имя запрос — сохранить фильтр
+/fdel имя — удалить фильтр
+/search текст — поиск по задачам, также работает как @бот текст в любом чате
+/done ID — отметить задачу выполненной
+/block ID ID_блокирующей — задача ждёт выполнения другой
+/unblock ID ID_блокирующей — убрать зависимость
+/escalate — напоминания о просроченных задачах
+/settings — язык, часовой пояс, напоминания и план на день
+/token — токен для REST API
+/webhook — вебхуки для событий задач
+/ical — ссылка на календарь для телефона
+/caldav — синхронизация задач с CalDAV-приложениями
+/import — импорт задач из Todoist, Trello, CSV или текстового файла
+/export — выгрузить все задачи в JSON, CSV или Markdown
+/deleteme — удалить аккаунт и все данные`,
+
+		"error.unknown_command":             "Неизвестная команда. Список команд: /help",
+		"error.internal":                    "Что-то пошло не так, попробуйте позже",
+		"error.timeout":                     "Сервер не успел ответить, попробуйте ещё раз через минуту",
+		"error.task_not_found":              "Задача не найдена",
+		"ical.private_only":                 "Ссылку на календарь можно получить только в личном чате с ботом",
+		"ical.revoked":                      "Ссылка на календарь отозвана, подписанные на неё календари больше не обновляются",
+		"ical.issued":                       "Ссылка на календарь задач с датами, предыдущая больше не работает:\n<code>%s</code>\n\nДобавьте её как подписку на календарь по URL в телефоне или Google Календаре. Добавьте <code>?type=todo</code>, чтобы получить задачи вместо событий. Отозвать: /ical revoke",
+		"ical.name":                         "Задачи",
+		"import.usage":                      "Пришлите боту файл в личном чате, чтобы импортировать из него задачи:\n• Todoist — CSV-экспорт проекта\n• Trello — JSON-экспорт доски\n• CSV с заголовком: title, description, due, priority (1-3), tags, done или задача, описание, срок, приоритет, теги, выполнено\n• текст — по задаче в строке, как в /add: <code>20.10.2026 15:00 Название #тег !1</code>\n• файлы /export\n\nПеред импортом бот покажет, что нашёл в файле. Не больше %d задач, файлы до 1 МБ.",
+		"import.format.todoist":             "Todoist",
+		"import.format.trello":              "Trello",
+		"import.format.csv":                 "CSV",
+		"import.format.text":                "текста",
+		"import.format.export":              "выгрузки бота",
+		"import.preview":                    "<b>Импорт из %s</b>: задач — %d",
+		"import.skipped":                    "Пропущено выполненных и архивных записей: %d",
+		"import.more":                       "…и ещё %d",
+		"import.confirm":                    "Импортировать %d",
+		"import.cancel":                     "Отмена",
+		"import.cancelled":                  "Импорт отменён",
+		"import.expired":                    "Файл этого импорта больше не доступен, пришлите его ещё раз",
+		"import.in_progress":                "Импортирую…",
+		"import.done":                       "Импортировано задач: %d. Список задач: /list",
+		"import.too_big":                    "Файл слишком большой, максимум %d КБ",
+		"import.empty":                      "В файле не найдено задач. Поддерживаемые форматы: /import",
+		"import.too_many":                   "В файле больше %d задач, разбейте его на несколько файлов",
+		"import.invalid":                    "Не удалось импортировать файл: %s",
+		"export.usage":                      "Выберите формат файла со всеми задачами, напоминаниями и тегами:\n• JSON — всё целиком, пришлите файл боту, чтобы восстановить задачи\n• CSV — для таблиц\n• Markdown — чек-лист для заметок\n\nИли сразу: /export json, /export csv, /export md",
+		"export.private_only":               "Выгрузить задачи можно только в личном чате с ботом",
+		"export.format.json":                "JSON",
+		"export.format.csv":                 "CSV",
+		"export.format.markdown":            "Markdown",
+		"export.in_progress":                "Выгружаю…",
+		"export.done":                       "Выгрузка готова ⬇️",
+		"export.caption.json":               "Все задачи. Пришлите этот файл боту, чтобы импортировать активные задачи обратно",
+		"export.caption.csv":                "Все задачи для таблиц",
+		"export.caption.markdown":           "Все задачи чек-листом",
+		"deleteme.private_only":             "Аккаунт можно удалить только в личном чате с ботом",
+		"deleteme.button.confirm":           "🗑 Удалить аккаунт",
+		"deleteme.button.keep":              "Оставить",
+		"deleteme.button.cancel":            "Отменить удаление",
+		"deleteme.requested":                "Аккаунт будет удалён после %s. До этого удаление можно отменить: /deleteme",
+		"deleteme.pending":                  "Аккаунт будет удалён после %s. Отменить удаление?",
+		"deleteme.cancelled":                "Удаление отменено, аккаунт остаётся",
+		"deleteme.kept":                     "Аккаунт остаётся",
+		"deleteme.final_export":             "Аккаунт удалён по запросу /deleteme. Здесь все ваши задачи, пришлите этот файл боту, чтобы импортировать их в новый аккаунт",
+		"admin.user_usage":                  "Укажите telegram ID: /%s TELEGRAM_ID",
+		"admin.user_not_found":              "Пользователь с telegram ID %d не найден",
+		"admin.ban.self":                    "Себя забанить нельзя",
+		"admin.ban.done":                    "Пользователь %d забанен, бот его игнорирует",
+		"admin.unban.done":                  "Пользователь %d разбанен",
+		"admin.user.title":                  "<b>Пользователь %d</b> (ID %d)",
+		"admin.user.created":                "Зарегистрирован: %s",
+		"admin.user.last_seen":              "Последняя активность: %s",
+		"admin.user.tasks":                  "Задачи: активных %d, выполненных %d, просроченных %d",
+		"admin.user.banned":                 "⛔ Забанен: %s",
+		"admin.user.ban_reason":             "Причина: %s",
+		"admin.user.deletion_requested":     "🗑 Запрошено удаление: %s",
+		"admin.broadcast.usage":             "/broadcast текст — отправить HTML-текст всем пользователям после превью\n/broadcast — состояние последней рассылки\n/broadcast cancel — остановить её",
+		"admin.broadcast.invalid":           "Телеграм не принимает текст: %s",
+		"admin.broadcast.button.start":      "📣 Отправить всем",
+		"admin.broadcast.button.cancel":     "Отмена",
+		"admin.broadcast.started":           "Рассылка %d запущена, около 20 сообщений в секунду. Состояние: /broadcast",
+		"admin.broadcast.cancelled":         "Рассылка %d отменена",
+		"admin.broadcast.not_draft":         "Рассылка %d уже запущена или отменена. Состояние: /broadcast",
+		"admin.broadcast.nothing_to_cancel": "Нет рассылки, которую можно отменить",
+		"admin.broadcast.status":            "<b>Рассылка %d</b>: %s\nОтправлено: %d, не доставлено: %d\nСоздана: %s\nЗавершена: %s",
+		"admin.broadcast.status.draft":      "ждёт подтверждения",
+		"admin.broadcast.status.running":    "идёт",
+		"admin.broadcast.status.done":       "завершена",
+		"admin.broadcast.status.cancelled":  "отменена",
+		"caldav.private_only":               "Пароль CalDAV можно получить только в личном чате с ботом",
+		"caldav.revoked":                    "Пароль CalDAV отозван, приложения с ним больше не синхронизируют задачи",
+		"caldav.issued":                     "Добавьте аккаунт CalDAV в приложении (Tasks.org, DAVx⁵, Напоминания Apple, Thunderbird), предыдущий пароль больше не работает:\nСервер: <code>%s</code>\nЛогин: любой\nПароль: <code>%s</code>\n\nЗадачи, изменённые в приложении, меняются и в боте. Напоминания ставятся только в боте. Отозвать: /caldav revoke",
+		"button.prev":                       "◀️ Назад",
+		"button.next":                       "Вперёд ▶️",
+		"button.back":                       "« Назад",
+		"section.overdue":                   "<b>Просрочены</b>",
+		"section.today":                     "<b>Сегодня</b>",
+		"section.blocked":                   "<b>Заблокированы</b>",
+		"section.active":                    "<b>Активные задачи</b>",
+		"task.blocked":                      "🔒 <i>%s</i> (ждёт %s)",
+		"add.usage":                         "Укажите название задачи: /add [дд.мм.гггг [чч:мм]] название [#тег] [!1-3]",
+		"add.done":                          "Задача добавлена: %s",
+		"today.empty":                       "На сегодня задач нет",
+		"digest.title":                      "☀️ <b>План на день</b>",
+		"reminder":                          "🔔 #%d %s",
+		"done.usage":                        "Укажите номер задачи: /done ID",
+		"done.done":                         "✅ Выполнено: %s",
+		"done.unblocked":                    "🔓 Разблокирована: %s",
+		"list.empty":                        "Активных задач нет",
+		"search.usage":                      "Укажите, что искать: /search текст",
+		"search.empty":                      "Ничего не найдено",
+		"dependency.usage":                  "Использование: /%s ID ID_блокирующей",
+		"dependency.wrong_id":               "Некорректный номер задачи: %s",
+		"dependency.not_found":              "Задача #%d не найдена",
+		"dependency.self":                   "Задача не может блокировать сама себя",
+		"dependency.cycle":                  "Нельзя: задача #%d уже ждёт задачу #%d, получится цикл",
+		"dependency.blocked":                "🔒 Задача #%d ждёт выполнения задачи #%d",
+		"dependency.unblocked":              "🔓 Задача #%d больше не ждёт задачу #%d",
+		"filter.not_found":                  "Фильтр «%s» не найден. Список фильтров: /f",
+		"filter.save_usage":                 "Укажите имя и запрос: /fsave имя запрос, например: /fsave work tag:work prio:&lt;=2",
+		"filter.wrong_name":                 "Имя фильтра может содержать только буквы, цифры, «_» и «-», не длиннее 64 символов",
+		"filter.saved":                      "Фильтр сохранён, показать задачи: /f %s",
+		"filter.delete_usage":               "Укажите имя фильтра: /fdel имя",
+		"filter.deleted":                    "Фильтр «%s» удалён",
+		"filter.list_empty":                 "Сохранённых фильтров нет. Сохранить: /fsave имя запрос",
+		"filter.list_title":                 "<b>Сохранённые фильтры</b>",
+		"filter.empty":                      "Под фильтр не подходит ни одна задача",
+		"filter.title":                      "<b>Задачи по фильтру</b> <code>%s</code>",
+		"filter.syntax_error":               "Не понял «%s»: %s\nПримеры: due:&lt;7d, due:today, tag:work, -tag:home, prio:&lt;=2, done, done:any, sort:-created",
+		"overdue.empty":                     "Просроченных задач нет 👍",
+		"escalate.reset":                    "Настройки напоминаний сброшены",
+		"escalate.invalid":                  "Интервал повтора — не меньше 10 минут, напоминаний — не больше 10",
+		"escalate.saved":                    "Сохранено. %s",
+		"escalate.off":                      "Напоминания о просроченных задачах выключены",
+		"escalation":                        "%s Задача #%d просрочена на %s: %s",
+		"escalation.final":                  "Это последнее напоминание. Выполнить: /done %d",
+		"settings.title":                    "<b>Настройки</b>",
+		"settings.invalid":                  "Не получилось изменить «%s»",
+		"settings.lang":                     "Язык",
+		"settings.tz":                       "Часовой пояс",
+		"settings.offset":                   "Напоминать заранее",
+		"settings.offset.none":              "ко времени задачи",
+		"settings.offset.value":             "за %s",
+		"settings.repeat":                   "Повторять напоминание",
+		"settings.repeat.value":             "каждые %s",
+		"settings.sort":                     "Сортировка",
+		"settings.sort.due":                 "по сроку",
+		"settings.sort.prio":                "по приоритету",
+		"settings.sort.created":             "по дате создания",
+		"settings.sort.title":               "по названию",
+		"settings.digest":                   "План на день",
+		"settings.digest.off":               "выключен",
+		"token.private_only":                "Токен можно получить только в личном чате с ботом",
+		"token.revoked":                     "Токен отозван, запросы с ним больше не работают",
+		"token.issued":                      "Ваш токен для API, предыдущий больше не работает:\n<code>%s</code>\n\nПередавайте его в заголовке <code>Authorization: Bearer токен</code>, описание API — /openapi.yaml на сервере бота. Отозвать: /token revoke",
+		"webhook.private_only":              "Вебхуками можно управлять только в личном чате с ботом",
+		"webhook.usage":                     "/webhook — список вебхуков\n/webhook add URL — отправлять события задач на URL\n/webhook del ID — удалить вебхук",
+		"webhook.list_empty":                "Вебхуков нет. Добавить: /webhook add URL",
+		"webhook.list_title":                "<b>Вебхуки</b>",
+		"webhook.added":                     "Вебхук %d добавлен, события будут отправляться на <code>%s</code>.\n\nСекрет:\n<code>%s</code>\n\nЗаголовок <code>X-Webhook-Signature</code> — это <code>sha256=</code> и hex HMAC-SHA256 тела запроса с этим секретом. Неудачные доставки повторяются с растущими интервалами.",
+		"webhook.invalid":                   "Не удалось добавить вебхук: %s",
+		"webhook.not_found":                 "Вебхук не найден. Список вебхуков: /webhook",
+		"webhook.deleted":                   "Вебхук %d удалён",
+		"tz.UTC":                            "UTC",
+		"tz.Europe/Kaliningrad":             "Калининград",
+		"tz.Europe/Moscow":                  "Москва",
+		"tz.Europe/Samara":                  "Самара",
+		"tz.Asia/Yekaterinburg":             "Екатеринбург",
+		"tz.Asia/Omsk":                      "Омск",
+		"tz.Asia/Novosibirsk":               "Новосибирск",
+		"tz.Asia/Irkutsk":                   "Иркутск",
+		"tz.Asia/Yakutsk":                   "Якутск",
+		"tz.Asia/Vladivostok":               "Владивосток",
+		"tz.Asia/Magadan":                   "Магадан",
+		"tz.Asia/Kamchatka":                 "Камчатка",
+
+		"admin.help": `<b>Команды администратора</b>
+/stats — пользователи и доля ошибок
+/broadcast — сообщение всем пользователям
+/ban TELEGRAM_ID [причина] — игнорировать пользователя
+/unban TELEGRAM_ID — перестать игнорировать
+/user TELEGRAM_ID — аккаунт и число задач`,
+
+		"admin.stats": `<b>Пользователи</b>: %d, забанено %d, заблокировали бота %d, ждут удаления %d
+Новые за 24 ч / 7 д / 30 д: %d / %d / %d
+Активные за 24 ч / 7 д / 30 д: %d / %d / %d
+
+<b>Обновления за 24 ч</b>: %d, ошибок %d (%.1f%%)`,
+
+		"escalate.usage": `Напоминания о просроченных задачах:
+/escalate — текущие настройки
+/escalate 1h 1d 3 — первое через 1 ч после срока, затем раз в день, всего 3
+/escalate off — не напоминать
+/escalate reset — настройки по умолчанию
+Для одной задачи: /escalate #ID 30m 2h 5`,
+
+		"settings.usage": `Часовой пояс и время дайджеста можно указать точно:
+/settings tz Europe/Berlin
+/settings digest 07:30`,
+	},
+	plurals: map[string][]string{
+		"throttled": {
+			"Слишком много запросов, подождите %d секунду",
+			"Слишком много запросов, подождите %d секунды",
+			"Слишком много запросов, подождите %d секунд",
+		},
+		"search.found": {
+			"<b>Найдена %d задача по запросу «%s»</b>",
+			"<b>Найдено %d задачи по запросу «%s»</b>",
+			"<b>Найдено %d задач по запросу «%s»</b>",
+		},
+		"filter.more": {
+			"…показана первая %d задача, уточните запрос",
+			"…показаны первые %d задачи, уточните запрос",
+			"…показаны первые %d задач, уточните запрос",
+		},
+		"escalate.policy": {
+			"%d напоминание о просрочке: первое через %s после срока, затем каждые %s",
+			"%d напоминания о просрочке: первое через %s после срока, затем каждые %s",
+			"%d напоминаний о просрочке: первое через %s после срока, затем каждые %s",
+		},
+		"deleteme.confirm": {
+			"<b>Удалить аккаунт?</b>\n\nВсе задачи, напоминания, теги, фильтры, настройки, токены API, ссылки на календарь и вебхуки будут удалены через %d день после подтверждения. До этого бот работает как обычно, удаление можно отменить: /deleteme\n\nПеред удалением бот пришлёт файл со всеми задачами, пришлите его боту, чтобы их восстановить.",
+			"<b>Удалить аккаунт?</b>\n\nВсе задачи, напоминания, теги, фильтры, настройки, токены API, ссылки на календарь и вебхуки будут удалены через %d дня после подтверждения. До этого бот работает как обычно, удаление можно отменить: /deleteme\n\nПеред удалением бот пришлёт файл со всеми задачами, пришлите его боту, чтобы их восстановить.",
+			"<b>Удалить аккаунт?</b>\n\nВсе задачи, напоминания, теги, фильтры, настройки, токены API, ссылки на календарь и вебхуки будут удалены через %d дней после подтверждения. До этого бот работает как обычно, удаление можно отменить: /deleteme\n\nПеред удалением бот пришлёт файл со всеми задачами, пришлите его боту, чтобы их восстановить.",
+		},
+	},
+}
@@ -0,0 +1,177 @@
+package ical
+
+import (
+	"fmt"
+	"strings"
+	"tg_todo_bot/src/models"
+	"time"
+)
+
+const (
+	ComponentEvent = "VEVENT"
+	ComponentTodo  = "VTODO"
+)
+
+const (
+	productID = "-//tg_todo_bot//Tasks//EN"
+	//Календари обновляют подписку не чаще, чем просит фид
+	refreshInterval = 15 * time.Minute
+	//Напоминание повторяется, пока задача не выполнена, а в VALARM число повторов конечно
+	alarmRepeats = 3
+)
+
+// FeedParams -> Location is the user's timezone, it decides which tasks are all-day ones.
+// Component is ComponentEvent or ComponentTodo, Now is written to DTSTAMP
+type FeedParams struct {
+	Name      string
+	Location  *time.Location
+	Component string
+	Now       time.Time
+}
+
+// Feed (tasks, params) -> VCALENDAR with a component for every task with datetime.
+// Times are written in UTC, so calendars show them in their own timezone,
+// tasks without time (00:00 in Location) become all-day ones of that date
+func Feed(tasks []models.Task, params FeedParams) []byte {
+	location := params.Location
+	if location == nil {
+		location = time.UTC
+	}
+
+	w := &writer{}
+	w.line("BEGIN", "VCALENDAR")
+	w.line("VERSION", "2.0")
+	w.line("PRODID", productID)
+	w.line("CALSCALE", "GREGORIAN")
+	w.line("METHOD", "PUBLISH")
+	w.text("X-WR-CALNAME", params.Name)
+	w.text("X-WR-TIMEZONE", location.String())
+	w.line("REFRESH-INTERVAL;VALUE=DURATION", formatDuration(refreshInterval))
+	w.line("X-PUBLISHED-TTL", formatDuration(refreshInterval))
+
+	for _, task := range tasks {
+		if task.Datetime == nil {
+			continue
+		}
+		writeTask(w, task, params.Component, TaskUID(task.ID), location, params.Now)
+	}
+
+	w.line("END", "VCALENDAR")
+
+	return w.bytes()
+}
+
+// Todo (task, uid, location, now) -> calendar object resource of CalDAV, VCALENDAR with a single VTODO.
+// Unlike Feed it has no METHOD, RFC 4791 forbids it in stored objects
+func Todo(task models.Task, uid string, location *time.Location, now time.Time) []byte {
+	if location == nil {
+		location = time.UTC
+	}
+
+	w := &writer{}
+	w.line("BEGIN", "VCALENDAR")
+	w.line("VERSION", "2.0")
+	w.line("PRODID", productID)
+	writeTask(w, task, ComponentTodo, uid, location, now)
+	w.line("END", "VCALENDAR")
+
+	return w.bytes()
+}
+
+// TaskUID (7) -> "task-7@tg_todo_bot", UID of tasks created in the bot
+func TaskUID(taskID int64) string {
+	return fmt.Sprintf("task-%d@tg_todo_bot", taskID)
+}
+
+// writeTask -> tasks without datetime are written as VTODO only
+func writeTask(w *writer, task models.Task, component, uid string, location *time.Location, now time.Time) {
+	if component != ComponentTodo {
+		component = ComponentEvent
+	}
+
+	var datetime time.Time
+	var allDay bool
+	if task.Datetime != nil {
+		datetime = task.Datetime.In(location)
+		hour, minute, second := datetime.Clock()
+		allDay = hour == 0 && minute == 0 && second == 0
+	}
+
+	w.line("BEGIN", component)
+	w.line("UID", escapeText(uid))
+	w.utc("DTSTAMP", now)
+	w.utc("CREATED", task.CreatedAt)
+	summary := task.Title
+	if task.Done && component == ComponentEvent {
+		//У VEVENT нет статуса выполнения
+		summary = "✓ " + summary
+	}
+	w.text("SUMMARY", summary)
+	if task.Description != "" {
+		w.text("DESCRIPTION", task.Description)
+	}
+	if len(task.Tags) > 0 {
+		var categories []string
+		for _, tag := range task.Tags {
+			categories = append(categories, escapeText(tag))
+		}
+		w.line("CATEGORIES", strings.Join(categories, ","))
+	}
+	if task.Priority > 0 {
+		w.line("PRIORITY", fmt.Sprint(priority(task.Priority)))
+	}
+
+	switch {
+	case task.Datetime == nil:
+	case component == ComponentTodo && allDay:
+		w.date("DUE", datetime)
+	case component == ComponentTodo:
+		w.utc("DUE", datetime)
+	case allDay:
+		w.date("DTSTART", datetime)
+		w.date("DTEND", datetime.AddDate(0, 0, 1))
+	default:
+		w.utc("DTSTART", datetime)
+	}
+
+	if component == ComponentTodo {
+		if task.Done {
+			w.line("STATUS", "COMPLETED")
+		} else {
+			w.line("STATUS", "NEEDS-ACTION")
+		}
+	} else {
+		//Задача не занимает время в календаре
+		w.line("TRANSP", "TRANSPARENT")
+	}
+
+	if task.Notification != nil && !task.Done {
+		writeAlarm(w, task, *task.Notification)
+	}
+
+	w.line("END", component)
+}
+
+func writeAlarm(w *writer, task models.Task, notification models.Notification) {
+	w.line("BEGIN", "VALARM")
+	w.line("ACTION", "DISPLAY")
+	w.text("DESCRIPTION", task.Title)
+	w.line("TRIGGER;VALUE=DATE-TIME", notification.NotifyAt.UTC().Format(utcLayout))
+	if notification.RepeatInterval >= time.Minute {
+		w.line("REPEAT", fmt.Sprint(alarmRepeats))
+		w.line("DURATION", formatDuration(notification.RepeatInterval))
+	}
+	w.line("END", "VALARM")
+}
+
+// priority (1) -> 1, priorities of the bot are 1-3 and 1 is the highest, in iCalendar they are 1-9
+func priority(taskPriority int) int {
+	switch taskPriority {
+	case 1:
+		return 1
+	case 2:
+		return 5
+	default:
+		return 9
+	}
+}
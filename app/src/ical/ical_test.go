@@ -0,0 +1,159 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"tg_todo_bot/src/models"
+	"time"
+)
+
+func TestEscapeText(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected string
+	}{
+		{value: "Buy milk", expected: "Buy milk"},
+		{value: "a, b; c", expected: `a\, b\; c`},
+		{value: `C:\temp`, expected: `C:\\temp`},
+		{value: "first\r\nsecond\nthird", expected: `first\nsecond\nthird`},
+		{value: "bell\a", expected: "bell"},
+	}
+
+	for _, testCase := range testCases {
+		escaped := escapeText(testCase.value)
+		if escaped != testCase.expected {
+			t.Fatalf("%q: got %q, expected %q", testCase.value, escaped, testCase.expected)
+		}
+	}
+}
+
+func TestLineFolding(t *testing.T) {
+	w := &writer{}
+	w.text("SUMMARY", strings.Repeat("Задача ", 30))
+
+	lines := strings.Split(strings.TrimSuffix(string(w.bytes()), lineSeparator), lineSeparator)
+	if len(lines) < 2 {
+		t.Fatalf("got %d lines, expected the long line to be folded", len(lines))
+	}
+
+	var unfolded string
+	for i, line := range lines {
+		if len(line) > maxLineOctets {
+			t.Fatalf("line %d: got %d octets, expected at most %d", i, len(line), maxLineOctets)
+		}
+		if i > 0 {
+			if !strings.HasPrefix(line, foldingPrefix) {
+				t.Fatalf("line %d: got %q, expected a continuation line", i, line)
+			}
+			line = strings.TrimPrefix(line, foldingPrefix)
+		}
+		unfolded += line
+	}
+
+	if unfolded != "SUMMARY:"+strings.Repeat("Задача ", 30) {
+		t.Fatalf("got %q after unfolding", unfolded)
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	testCases := []struct {
+		duration time.Duration
+		expected string
+	}{
+		{duration: 0, expected: "PT0M"},
+		{duration: 15 * time.Minute, expected: "PT15M"},
+		{duration: 90 * time.Minute, expected: "PT1H30M"},
+		{duration: 3 * time.Hour, expected: "PT3H"},
+		{duration: 48 * time.Hour, expected: "P2D"},
+		{duration: 25 * time.Hour, expected: "PT25H"},
+	}
+
+	for _, testCase := range testCases {
+		formatted := formatDuration(testCase.duration)
+		if formatted != testCase.expected {
+			t.Fatalf("%s: got %s, expected %s", testCase.duration, formatted, testCase.expected)
+		}
+	}
+}
+
+func TestFeed(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	timed := time.Date(2026, 10, 20, 15, 30, 0, 0, moscow)
+	allDay := time.Date(2026, 10, 21, 0, 0, 0, 0, moscow)
+
+	tasks := []models.Task{
+		{
+			ID:       1,
+			Title:    "Call, mom",
+			Datetime: &timed,
+			Priority: 1,
+			Tags:     []string{"family"},
+			Notification: &models.Notification{
+				NotifyAt:       timed.Add(-15 * time.Minute),
+				RepeatInterval: 30 * time.Minute,
+			},
+		},
+		{ID: 2, Title: "Pay rent", Datetime: &allDay, Done: true},
+		{ID: 3, Title: "Without date"},
+	}
+
+	testCases := []struct {
+		component string
+		contains  []string
+		excludes  []string
+	}{
+		{
+			component: ComponentEvent,
+			contains: []string{
+				"X-WR-TIMEZONE:Europe/Moscow\r\n",
+				"UID:task-1@tg_todo_bot\r\n",
+				"SUMMARY:Call\\, mom\r\n",
+				"DTSTART:20261020T123000Z\r\n",
+				"PRIORITY:1\r\n",
+				"CATEGORIES:family\r\n",
+				"TRIGGER;VALUE=DATE-TIME:20261020T121500Z\r\nREPEAT:3\r\nDURATION:PT30M\r\n",
+				"SUMMARY:✓ Pay rent\r\n",
+				"DTSTART;VALUE=DATE:20261021\r\nDTEND;VALUE=DATE:20261022\r\n",
+			},
+			excludes: []string{"task-3@", "VTODO"},
+		},
+		{
+			component: ComponentTodo,
+			contains: []string{
+				"BEGIN:VTODO\r\n",
+				"DUE:20261020T123000Z\r\n",
+				"STATUS:NEEDS-ACTION\r\n",
+				"SUMMARY:Pay rent\r\n",
+				"DUE;VALUE=DATE:20261021\r\n",
+				"STATUS:COMPLETED\r\n",
+			},
+			excludes: []string{"task-3@", "VEVENT", "DTSTART"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		feed := string(Feed(tasks, FeedParams{Name: "Tasks", Location: moscow, Component: testCase.component, Now: now}))
+
+		if !strings.HasPrefix(feed, "BEGIN:VCALENDAR\r\n") || !strings.HasSuffix(feed, "END:VCALENDAR\r\n") {
+			t.Fatalf("%s: got feed without VCALENDAR:\n%s", testCase.component, feed)
+		}
+		for _, expected := range testCase.contains {
+			if !strings.Contains(feed, expected) {
+				t.Fatalf("%s: expected %q in the feed:\n%s", testCase.component, expected, feed)
+			}
+		}
+		for _, unexpected := range testCase.excludes {
+			if strings.Contains(feed, unexpected) {
+				t.Fatalf("%s: unexpected %q in the feed:\n%s", testCase.component, unexpected, feed)
+			}
+		}
+		//У выполненной задачи напоминаний нет
+		if strings.Count(feed, "BEGIN:VALARM") != 1 {
+			t.Fatalf("%s: got %d alarms, expected 1", testCase.component, strings.Count(feed, "BEGIN:VALARM"))
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package ical
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const localLayout = "20060102T150405"
+
+var ErrNoTodo = fmt.Errorf("no VTODO in the calendar object")
+
+// ParsedTodo -> fields of a VTODO which tasks have, the rest (alarms, recurrence, attendees) is ignored
+type ParsedTodo struct {
+	UID         string
+	Summary     string
+	Description string
+	Due         *time.Time
+	Completed   bool
+	Priority    int //priority of the bot, 0 - without priority, 1 - the highest
+	Categories  []string
+}
+
+type contentLine struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// ParseTodo (data, location) -> the first VTODO of the calendar object.
+// Floating times and dates are in location, dates become 00:00 like tasks without time in the bot
+func ParseTodo(data []byte, location *time.Location) (ParsedTodo, error) {
+	if location == nil {
+		location = time.UTC
+	}
+
+	var todo ParsedTodo
+	var found, inTodo bool
+	var start *time.Time
+	nested := 0
+	for _, line := range unfold(string(data)) {
+		parsed, ok := parseContentLine(line)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case parsed.name == "BEGIN" && strings.EqualFold(parsed.value, ComponentTodo) && !found:
+			inTodo, found = true, true
+			continue
+		case !inTodo:
+			continue
+		case parsed.name == "BEGIN":
+			//Вложенные компоненты (VALARM) пропускаются целиком
+			nested++
+			continue
+		case parsed.name == "END" && nested > 0:
+			nested--
+			continue
+		case parsed.name == "END":
+			inTodo = false
+			continue
+		case nested > 0:
+			continue
+		}
+
+		var err error
+		switch parsed.name {
+		case "UID":
+			todo.UID = unescapeText(parsed.value)
+		case "SUMMARY":
+			todo.Summary = unescapeText(parsed.value)
+		case "DESCRIPTION":
+			todo.Description = unescapeText(parsed.value)
+		case "DUE":
+			todo.Due, err = parseDatetime(parsed, location)
+		case "DTSTART":
+			start, err = parseDatetime(parsed, location)
+		case "STATUS":
+			todo.Completed = todo.Completed || strings.EqualFold(parsed.value, "COMPLETED")
+		case "COMPLETED":
+			todo.Completed = true
+		case "PRIORITY":
+			todo.Priority, err = parsePriority(parsed.value)
+		case "CATEGORIES":
+			for _, category := range splitUnescaped(parsed.value, ',') {
+				if category = strings.TrimSpace(unescapeText(category)); category != "" {
+					todo.Categories = append(todo.Categories, category)
+				}
+			}
+		}
+		if err != nil {
+			return ParsedTodo{}, err
+		}
+	}
+
+	if !found {
+		return ParsedTodo{}, ErrNoTodo
+	}
+
+	//Без срока задача привязывается к началу, если оно есть
+	if todo.Due == nil {
+		todo.Due = start
+	}
+
+	return todo, nil
+}
+
+// unfold (data) -> content lines with continuation lines joined
+func unfold(data string) []string {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n") {
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+// parseContentLine ("DUE;TZID=Europe/Moscow:20261020T153000") -> name, parameters and value,
+// quoted parameter values may contain ":" and ";"
+func parseContentLine(line string) (contentLine, bool) {
+	inQuotes := false
+	for i, r := range line {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+		case r == ':' && !inQuotes:
+			parts := splitUnquoted(line[:i], ';')
+			parsed := contentLine{
+				name:   strings.ToUpper(parts[0]),
+				params: map[string]string{},
+				value:  line[i+1:],
+			}
+			for _, param := range parts[1:] {
+				key, value, _ := strings.Cut(param, "=")
+				parsed.params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+			}
+			return parsed, parsed.name != ""
+		}
+	}
+
+	return contentLine{}, false
+}
+
+func splitUnquoted(value string, separator rune) []string {
+	var parts []string
+	inQuotes, start := false, 0
+	for i, r := range value {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+		case r == separator && !inQuotes:
+			parts = append(parts, value[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(parts, value[start:])
+}
+
+// splitUnescaped (`a\,b,c`, ',') -> [`a\,b`, "c"]
+func splitUnescaped(value string, separator byte) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			i++
+		case separator:
+			parts = append(parts, value[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(parts, value[start:])
+}
+
+// unescapeText (`a\, b\nc`) -> "a, b\nc"
+func unescapeText(value string) string {
+	var unescaped strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' || i == len(value)-1 {
+			unescaped.WriteByte(value[i])
+			continue
+		}
+
+		i++
+		switch value[i] {
+		case 'n', 'N':
+			unescaped.WriteByte('\n')
+		default:
+			unescaped.WriteByte(value[i])
+		}
+	}
+
+	return unescaped.String()
+}
+
+// parseDatetime -> UTC ("...Z"), with TZID, floating (in location) or a date (00:00 in location)
+func parseDatetime(parsed contentLine, location *time.Location) (*time.Time, error) {
+	value := strings.TrimSpace(parsed.value)
+
+	if tzid, exist := parsed.params["TZID"]; exist {
+		//Нестандартные TZID (например, из Outlook) заменяются часовым поясом пользователя
+		if tzLocation, err := time.LoadLocation(tzid); err == nil {
+			location = tzLocation
+		}
+	}
+
+	var datetime time.Time
+	var err error
+	switch {
+	case strings.EqualFold(parsed.params["VALUE"], "DATE") || len(value) == len(dateLayout):
+		datetime, err = time.ParseInLocation(dateLayout, value, location)
+	case strings.HasSuffix(value, "Z"):
+		datetime, err = time.Parse(utcLayout, value)
+	default:
+		datetime, err = time.ParseInLocation(localLayout, value, location)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s '%s'", parsed.name, value)
+	}
+
+	return &datetime, nil
+}
+
+// parsePriority ("5") -> 2, iCalendar priorities 1-4 are high, 5 is medium and 6-9 are low
+func parsePriority(value string) (int, error) {
+	priority, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || priority < 0 || priority > 9 {
+		return 0, fmt.Errorf("invalid PRIORITY '%s'", value)
+	}
+
+	switch {
+	case priority == 0:
+		return 0, nil
+	case priority < 5:
+		return 1, nil
+	case priority == 5:
+		return 2, nil
+	default:
+		return 3, nil
+	}
+}
@@ -0,0 +1,133 @@
+package ical
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"tg_todo_bot/src/models"
+	"time"
+)
+
+func TestParseTodo(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	date := func(t time.Time) *time.Time {
+		return &t
+	}
+
+	testCases := []struct {
+		name     string
+		data     string
+		expected ParsedTodo
+	}{
+		{
+			name: "apple reminders",
+			data: "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nBEGIN:VTODO\r\nUID:4F1C-AB\r\nSUMMARY:Buy milk\\, eggs\r\n" +
+				"DUE;TZID=America/New_York:20261020T090000\r\nPRIORITY:5\r\nSTATUS:NEEDS-ACTION\r\n" +
+				"BEGIN:VALARM\r\nACTION:DISPLAY\r\nSUMMARY:Alarm\r\nEND:VALARM\r\nEND:VTODO\r\nEND:VCALENDAR\r\n",
+			expected: ParsedTodo{
+				UID:      "4F1C-AB",
+				Summary:  "Buy milk, eggs",
+				Due:      date(time.Date(2026, 10, 20, 9, 0, 0, 0, newYork)),
+				Priority: 2,
+			},
+		},
+		{
+			name: "completed with a date and folded description",
+			data: "BEGIN:VCALENDAR\nBEGIN:VTODO\nUID:x\nSUMMARY:Report\nDESCRIPTION:first line\\nsecond\n  line\n" +
+				"DUE;VALUE=DATE:20261021\nSTATUS:COMPLETED\nCATEGORIES:work,q\\,4\nEND:VTODO\nEND:VCALENDAR\n",
+			expected: ParsedTodo{
+				UID:         "x",
+				Summary:     "Report",
+				Description: "first line\nsecond line",
+				Due:         date(time.Date(2026, 10, 21, 0, 0, 0, 0, moscow)),
+				Completed:   true,
+				Categories:  []string{"work", "q,4"},
+			},
+		},
+		{
+			name: "floating start without due",
+			data: "BEGIN:VTODO\nUID:y\nSUMMARY:Call\nDTSTART:20261022T180000\nCOMPLETED:20261022T190000Z\nPRIORITY:1\nEND:VTODO\n",
+			expected: ParsedTodo{
+				UID:       "y",
+				Summary:   "Call",
+				Due:       date(time.Date(2026, 10, 22, 18, 0, 0, 0, moscow)),
+				Completed: true,
+				Priority:  1,
+			},
+		},
+		{
+			name: "utc and unknown timezone",
+			data: "BEGIN:VTODO\nUID:z\nSUMMARY:Sync\nDUE;TZID=\"Customized Time Zone\":20261023T100000\nEND:VTODO\n",
+			expected: ParsedTodo{
+				UID:     "z",
+				Summary: "Sync",
+				Due:     date(time.Date(2026, 10, 23, 10, 0, 0, 0, moscow)),
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		todo, err := ParseTodo([]byte(testCase.data), moscow)
+		if err != nil {
+			t.Fatalf("%s: %v", testCase.name, err)
+		}
+
+		if (todo.Due == nil) != (testCase.expected.Due == nil) || (todo.Due != nil && !todo.Due.Equal(*testCase.expected.Due)) {
+			t.Fatalf("%s: got due %v, expected %v", testCase.name, todo.Due, testCase.expected.Due)
+		}
+		todo.Due, testCase.expected.Due = nil, nil
+		if !reflect.DeepEqual(todo, testCase.expected) {
+			t.Fatalf("%s: got %+v, expected %+v", testCase.name, todo, testCase.expected)
+		}
+	}
+}
+
+func TestParseTodoErrors(t *testing.T) {
+	_, err := ParseTodo([]byte("BEGIN:VCALENDAR\nBEGIN:VEVENT\nUID:e\nEND:VEVENT\nEND:VCALENDAR\n"), time.UTC)
+	if !errors.Is(err, ErrNoTodo) {
+		t.Fatalf("got %v, expected ErrNoTodo", err)
+	}
+
+	for _, data := range []string{
+		"BEGIN:VTODO\nDUE:tomorrow\nEND:VTODO\n",
+		"BEGIN:VTODO\nPRIORITY:high\nEND:VTODO\n",
+	} {
+		_, err = ParseTodo([]byte(data), time.UTC)
+		if err == nil {
+			t.Fatalf("%q: expected an error", data)
+		}
+	}
+}
+
+func TestTodoRoundTrip(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	due := time.Date(2026, 10, 20, 15, 30, 0, 0, moscow)
+
+	for _, task := range []models.Task{
+		{ID: 1, Title: "Call; mom, later", Description: "line\nline", Datetime: &due, Priority: 2, Tags: []string{"family", "a,b"}},
+		{ID: 2, Title: "Someday", Done: true, Priority: 3},
+	} {
+		todo, err := ParseTodo(Todo(task, TaskUID(task.ID), moscow, due), moscow)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if todo.UID != TaskUID(task.ID) || todo.Summary != task.Title || todo.Description != task.Description ||
+			todo.Completed != task.Done || todo.Priority != task.Priority || !reflect.DeepEqual(todo.Categories, task.Tags) {
+			t.Fatalf("task %d: got %+v after the round trip", task.ID, todo)
+		}
+		if (todo.Due == nil) != (task.Datetime == nil) || (todo.Due != nil && !todo.Due.Equal(*task.Datetime)) {
+			t.Fatalf("task %d: got due %v, expected %v", task.ID, todo.Due, task.Datetime)
+		}
+	}
+}
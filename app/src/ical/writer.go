@@ -0,0 +1,100 @@
+package ical
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	maxLineOctets  = 75
+	utcLayout      = "20060102T150405Z"
+	dateLayout     = "20060102"
+	lineSeparator  = "\r\n"
+	foldingPrefix  = " "
+	textEscapeList = `\;,`
+)
+
+// writer -> content lines of RFC 5545, folded to 75 octets and separated with CRLF
+type writer struct {
+	buffer bytes.Buffer
+}
+
+// line ("SUMMARY", "value") -> the value must be already escaped if it's a text
+func (w *writer) line(name, value string) {
+	content := name + ":" + value
+
+	//Длинные строки переносятся без разрыва UTF-8 символов
+	octets := 0
+	for _, r := range content {
+		size := utf8.RuneLen(r)
+		if octets+size > maxLineOctets {
+			w.buffer.WriteString(lineSeparator + foldingPrefix)
+			octets = len(foldingPrefix)
+		}
+		w.buffer.WriteRune(r)
+		octets += size
+	}
+	w.buffer.WriteString(lineSeparator)
+}
+
+func (w *writer) text(name, value string) {
+	w.line(name, escapeText(value))
+}
+
+func (w *writer) utc(name string, t time.Time) {
+	w.line(name, t.UTC().Format(utcLayout))
+}
+
+func (w *writer) date(name string, t time.Time) {
+	w.line(name+";VALUE=DATE", t.Format(dateLayout))
+}
+
+func (w *writer) bytes() []byte {
+	return w.buffer.Bytes()
+}
+
+// escapeText ("a, b;\nc") -> "a\, b\;\nc"
+func escapeText(value string) string {
+	var escaped strings.Builder
+	for _, r := range strings.ReplaceAll(value, "\r\n", "\n") {
+		switch {
+		case r == '\n':
+			escaped.WriteString(`\n`)
+		case strings.ContainsRune(textEscapeList, r):
+			escaped.WriteRune('\\')
+			escaped.WriteRune(r)
+		case r < ' ' && r != '\t':
+			//Управляющие символы запрещены в значениях
+		default:
+			escaped.WriteRune(r)
+		}
+	}
+
+	return escaped.String()
+}
+
+// formatDuration (90 * time.Minute) -> "PT1H30M", seconds are dropped
+func formatDuration(duration time.Duration) string {
+	minutes := int64(duration / time.Minute)
+	if minutes <= 0 {
+		return "PT0M"
+	}
+
+	value := "P"
+	if days := minutes / (24 * 60); days > 0 && minutes%(24*60) == 0 {
+		return value + strconv.FormatInt(days, 10) + "D"
+	}
+
+	value += "T"
+	if hours := minutes / 60; hours > 0 {
+		value += strconv.FormatInt(hours, 10) + "H"
+	}
+	if minutes%60 > 0 {
+		value += strconv.FormatInt(minutes%60, 10) + "M"
+	}
+
+	return value
+}
@@ -0,0 +1,109 @@
+package importers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// csvColumns -> names of the columns of parseCSV in lower case, the first matching column of the header is used
+var csvColumns = map[string][]string{
+	"title":       {"title", "name", "task", "content", "задача", "название"},
+	"description": {"description", "desc", "notes", "описание", "заметки"},
+	"due":         {"due", "date", "datetime", "deadline", "срок", "дата"},
+	"priority":    {"priority", "приоритет"},
+	"tags":        {"tags", "labels", "теги", "метки"},
+	"done":        {"done", "completed", "выполнено"},
+}
+
+// parseCSV -> the header is required, only the title column is. Priority is 1-3 like in the bot,
+// tags are separated by commas or spaces, rows with done "1", "true", "yes", "x" or "да" are skipped
+func parseCSV(data []byte, params Params) (Result, error) {
+	rows, err := readCSV(data)
+	if err != nil {
+		return Result{}, err
+	}
+	if len(rows) == 0 {
+		return Result{}, nil
+	}
+
+	columns := map[string]int{}
+	for i, name := range rows[0] {
+		name = strings.ToLower(strings.TrimSpace(name))
+		for column, aliases := range csvColumns {
+			if _, exist := columns[column]; !exist && containsString(aliases, name) {
+				columns[column] = i
+			}
+		}
+	}
+	if _, exist := columns["title"]; !exist {
+		return Result{}, fmt.Errorf("no title column in the header, expected one of: %s", strings.Join(csvColumns["title"], ", "))
+	}
+
+	var result Result
+	for _, row := range rows[1:] {
+		cell := func(column string) string {
+			i, exist := columns[column]
+			if !exist || i >= len(row) {
+				return ""
+			}
+			return strings.TrimSpace(row[i])
+		}
+
+		if cell("title") == "" || isTruthy(cell("done")) {
+			result.Skipped++
+			continue
+		}
+
+		task := newTask(cell("title"), params)
+		task.Description = cell("description")
+		task.Datetime = parseDate(cell("due"), params.Location)
+		if priority, err := strconv.Atoi(cell("priority")); err == nil && priority >= 1 && priority <= 3 {
+			task.Priority = priority
+		}
+		for _, tag := range strings.FieldsFunc(cell("tags"), func(r rune) bool { return r == ',' || r == ' ' || r == ';' }) {
+			task.Tags = append(task.Tags, tagFromName(tag))
+		}
+		result.Tasks = append(result.Tasks, task)
+	}
+
+	return result, nil
+}
+
+// readCSV (data) -> rows of the file, the separator is guessed from the header,
+// spreadsheets in many locales save CSV with ";"
+func readCSV(data []byte) ([][]string, error) {
+	header, _, _ := bytes.Cut(data, []byte("\n"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	for _, separator := range []rune{';', '\t'} {
+		if bytes.Count(header, []byte(string(separator))) > bytes.Count(header, []byte(",")) {
+			reader.Comma = separator
+		}
+	}
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %w", err)
+	}
+
+	return rows, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isTruthy(value string) bool {
+	return containsString([]string{"1", "true", "yes", "x", "да"}, strings.ToLower(value))
+}
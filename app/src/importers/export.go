@@ -0,0 +1,53 @@
+package importers
+
+import (
+	"encoding/json"
+	"fmt"
+	"tg_todo_bot/src/exporters"
+	"time"
+)
+
+type botExport struct {
+	Format  string `json:"format"`
+	Version int    `json:"version"`
+	Tasks   []struct {
+		Title       string     `json:"title"`
+		Description string     `json:"description"`
+		Due         *time.Time `json:"due"`
+		Done        bool       `json:"done"`
+		Priority    int        `json:"priority"`
+		Tags        []string   `json:"tags"`
+	} `json:"tasks"`
+}
+
+// parseExport -> active tasks of a JSON export of the bot, completed ones are skipped.
+// Reminders and blockers aren't restored: IDs of blockers belong to the old account
+func parseExport(data []byte, params Params) (Result, error) {
+	var export botExport
+	err := json.Unmarshal(data, &export)
+	if err != nil {
+		return Result{}, fmt.Errorf("invalid export of the bot: %w", err)
+	}
+	if export.Version > exporters.JSONVersion {
+		return Result{}, fmt.Errorf("export version %d is not supported", export.Version)
+	}
+
+	var result Result
+	for _, item := range export.Tasks {
+		if item.Done || item.Title == "" {
+			result.Skipped++
+			continue
+		}
+
+		task := newTask(item.Title, params)
+		task.Description = item.Description
+		task.Datetime = item.Due
+		task.Priority = item.Priority
+		for _, tag := range item.Tags {
+			task.Tags = append(task.Tags, tagFromName(tag))
+		}
+		result.Tasks = append(result.Tasks, task)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,171 @@
+package importers
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"strings"
+	"tg_todo_bot/src/exporters"
+	tasks_types "tg_todo_bot/src/services/tasks/types"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	FormatTodoist = "todoist" //CSV export of a Todoist project
+	FormatTrello  = "trello"  //JSON export of a Trello board
+	FormatCSV     = "csv"     //any CSV with a header, see parseCSV for the columns
+	FormatText    = "text"    //one task per line in the syntax of /add
+	FormatExport  = "export"  //JSON export of the bot, see exporters
+
+	MaxTasks = 500
+
+	maxTitleLength = 255 //tasks.title is VARCHAR(255)
+	maxTagLength   = 64  //task_tags.tag is VARCHAR(64)
+)
+
+var (
+	ErrNoTasks      = fmt.Errorf("no tasks in the file")
+	ErrTooManyTasks = fmt.Errorf("more than %d tasks in the file", MaxTasks)
+)
+
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// Экспорт бота начинается с поля format, см. exporters.newJSONWriter
+var exportPrefix = []byte(`{"format":"` + exporters.JSONFormatName + `"`)
+
+// Params -> dates without a timezone are in Location, UserID is copied to every task
+type Params struct {
+	UserID   int64
+	Location *time.Location
+}
+
+// Result -> Skipped are completed, archived and empty entries of the file
+type Result struct {
+	Format  string
+	Tasks   []tasks_types.CreateParams
+	Skipped int
+}
+
+type parser func(data []byte, params Params) (Result, error)
+
+var parsers = map[string]parser{
+	FormatTodoist: parseTodoist,
+	FormatTrello:  parseTrello,
+	FormatCSV:     parseCSV,
+	FormatText:    parseText,
+	FormatExport:  parseExport,
+}
+
+// Detect (fileName, data) -> format of the file by its extension and content, FormatText if nothing else fits
+func Detect(fileName string, data []byte) string {
+	data = bytes.TrimPrefix(data, utf8BOM)
+
+	if bytes.HasPrefix(data, exportPrefix) {
+		return FormatExport
+	}
+
+	switch strings.ToLower(path.Ext(fileName)) {
+	case ".json":
+		return FormatTrello
+	case ".csv":
+		header, _, _ := bytes.Cut(data, []byte("\n"))
+		header = bytes.ToUpper(header)
+		if bytes.HasPrefix(header, []byte("TYPE")) && bytes.Contains(header, []byte("CONTENT")) {
+			return FormatTodoist
+		}
+		return FormatCSV
+	}
+
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		return FormatTrello
+	}
+
+	return FormatText
+}
+
+// Parse (fileName, data, params) -> tasks of the file ready for tasks.Service.CreateMany,
+// ErrNoTasks and ErrTooManyTasks are returned as is, other errors describe what is wrong with the file
+func Parse(fileName string, data []byte, params Params) (Result, error) {
+	if params.Location == nil {
+		params.Location = time.UTC
+	}
+
+	if !utf8.Valid(data) {
+		return Result{}, fmt.Errorf("the file is not in UTF-8")
+	}
+	data = bytes.TrimPrefix(data, utf8BOM)
+
+	format := Detect(fileName, data)
+	result, err := parsers[format](data, params)
+	if err != nil {
+		return Result{}, err
+	}
+	result.Format = format
+
+	if len(result.Tasks) == 0 {
+		return Result{}, ErrNoTasks
+	}
+	if len(result.Tasks) > MaxTasks {
+		return Result{}, ErrTooManyTasks
+	}
+
+	return result, nil
+}
+
+// newTask (title, params) -> task of the user with the title cut to the length of the column
+func newTask(title string, params Params) tasks_types.CreateParams {
+	return tasks_types.CreateParams{
+		Title:  truncate(strings.TrimSpace(title), maxTitleLength),
+		UserID: params.UserID,
+	}
+}
+
+// tagFromName ("In Progress") -> "in_progress", tags can't have spaces in filters
+func tagFromName(name string) string {
+	tag := strings.Join(strings.Fields(strings.ToLower(name)), "_")
+	return truncate(strings.TrimPrefix(tag, "#"), maxTagLength)
+}
+
+func truncate(value string, maxLength int) string {
+	if utf8.RuneCountInString(value) <= maxLength {
+		return value
+	}
+
+	return string([]rune(value)[:maxLength])
+}
+
+// dateLayouts -> layouts of dates in files, dates without time become 00:00 like tasks without time in the bot
+var dateLayouts = []string{
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+	"02.01.2006 15:04",
+	"Jan 2 2006 15:04",
+	"2 Jan 2006 15:04",
+	"2006-01-02",
+	"02.01.2006",
+	"Jan 2 2006",
+	"2 Jan 2006",
+}
+
+// parseDate (value, location) -> nil if the value is not a date, e.g. "every monday" of Todoist
+func parseDate(value string, location *time.Location) *time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+
+	datetime, err := time.Parse(time.RFC3339, value)
+	if err == nil {
+		return &datetime
+	}
+
+	for _, layout := range dateLayouts {
+		datetime, err = time.ParseInLocation(layout, value, location)
+		if err == nil {
+			return &datetime
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,267 @@
+package importers
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"tg_todo_bot/src/exporters"
+	"tg_todo_bot/src/models"
+	tasks_types "tg_todo_bot/src/services/tasks/types"
+	"time"
+)
+
+func TestDetect(t *testing.T) {
+	testCases := []struct {
+		fileName string
+		data     string
+		expected string
+	}{
+		{fileName: "Inbox.csv", data: "\xef\xbb\xbfTYPE,CONTENT,DESCRIPTION,PRIORITY\n", expected: FormatTodoist},
+		{fileName: "tasks.CSV", data: "title;due\n", expected: FormatCSV},
+		{fileName: "board.json", data: "{}", expected: FormatTrello},
+		{fileName: "export", data: " {\"cards\": []}", expected: FormatTrello},
+		{fileName: "todo.txt", data: "Buy milk\n", expected: FormatText},
+		{fileName: "tasks-2026-10-19.json", data: `{"format":"tg_todo_bot","version":1,"tasks":[]}`, expected: FormatExport},
+	}
+
+	for _, testCase := range testCases {
+		got := Detect(testCase.fileName, []byte(testCase.data))
+		if got != testCase.expected {
+			t.Fatalf("%s: got %s, expected %s", testCase.fileName, got, testCase.expected)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	date := func(t time.Time) *time.Time {
+		return &t
+	}
+	params := Params{UserID: 7, Location: moscow}
+
+	testCases := []struct {
+		name     string
+		fileName string
+		data     string
+		expected Result
+	}{
+		{
+			name:     "text",
+			fileName: "todo.txt",
+			data: "# Home\n- [ ] 20.10.2026 15:00 Dentist #health !1\n- [x] Old task\n\n* 2026-10-21 Report\n" +
+				"Call mom\n",
+			expected: Result{
+				Format: FormatText,
+				Tasks: []tasks_types.CreateParams{
+					{Title: "Dentist", Datetime: date(time.Date(2026, 10, 20, 15, 0, 0, 0, moscow)), Priority: 1, Tags: []string{"health"}, UserID: 7},
+					{Title: "Report", Datetime: date(time.Date(2026, 10, 21, 0, 0, 0, 0, moscow)), UserID: 7},
+					{Title: "Call mom", UserID: 7},
+				},
+				Skipped: 1,
+			},
+		},
+		{
+			name:     "todoist",
+			fileName: "Work.csv",
+			data: "TYPE,CONTENT,DESCRIPTION,PRIORITY,INDENT,AUTHOR,RESPONSIBLE,DATE,DATE_LANG,TIMEZONE\n" +
+				"meta,view_style=list,,,,,,,,\n" +
+				"task,Quarterly report @work,Numbers for Q4,4,1,Ann,,2026-10-25,en,Europe/Moscow\n" +
+				"note,Ask finance,,,,,,,,\n" +
+				",,,,,,,,,\n" +
+				"section,In Progress,,,,,,,,\n" +
+				"task,Standup,,1,1,Ann,,every monday,en,Europe/Moscow\n",
+			expected: Result{
+				Format: FormatTodoist,
+				Tasks: []tasks_types.CreateParams{
+					{
+						Title:       "Quarterly report",
+						Description: "Numbers for Q4\n\nAsk finance",
+						Datetime:    date(time.Date(2026, 10, 25, 0, 0, 0, 0, moscow)),
+						Priority:    1,
+						Tags:        []string{"work"},
+						UserID:      7,
+					},
+					{Title: "Standup", Tags: []string{"in_progress"}, UserID: 7},
+				},
+			},
+		},
+		{
+			name:     "trello",
+			fileName: "board.json",
+			data: `{"lists": [{"id": "l1", "name": "To Do"}, {"id": "l2", "name": "Old", "closed": true}],
+				"cards": [
+					{"id": "c1", "name": "Launch", "desc": "Landing page", "due": "2026-10-30T12:00:00.000Z", "idList": "l1",
+						"labels": [{"name": "Marketing", "color": "green"}, {"name": "", "color": "red"}]},
+					{"id": "c2", "name": "Archived", "closed": true, "idList": "l1"},
+					{"id": "c3", "name": "In closed list", "idList": "l2"},
+					{"id": "c4", "name": "Done", "due": "2026-10-01T12:00:00.000Z", "dueComplete": true, "idList": "l1"}
+				],
+				"checklists": [{"idCard": "c1", "checkItems": [{"name": "Copy", "state": "complete"}, {"name": "Design", "state": "incomplete"}]}]}`,
+			expected: Result{
+				Format: FormatTrello,
+				Tasks: []tasks_types.CreateParams{
+					{
+						Title:       "Launch",
+						Description: "Landing page\n\n- Design",
+						Datetime:    date(time.Date(2026, 10, 30, 12, 0, 0, 0, time.UTC)),
+						Tags:        []string{"to_do", "marketing", "red"},
+						UserID:      7,
+					},
+				},
+				Skipped: 3,
+			},
+		},
+		{
+			name:     "markdown export",
+			fileName: "tasks-2026-10-19.md",
+			data: "# Tasks 19.10.2026 12:00\n\n- [ ] 20.10.2026 15:00 Dentist #health !1\n  > Bring the card\n  >\n  > 🔔 20.10.2026 14:00\n" +
+				"- [x] Old\n  > Not a description of Dentist\n- [ ] Report\n",
+			expected: Result{
+				Format: FormatText,
+				Tasks: []tasks_types.CreateParams{
+					{
+						Title:       "Dentist",
+						Description: "Bring the card\n\n🔔 20.10.2026 14:00",
+						Datetime:    date(time.Date(2026, 10, 20, 15, 0, 0, 0, moscow)),
+						Priority:    1,
+						Tags:        []string{"health"},
+						UserID:      7,
+					},
+					{Title: "Report", UserID: 7},
+				},
+				Skipped: 1,
+			},
+		},
+		{
+			name:     "json export",
+			fileName: "tasks-2026-10-19.json",
+			data: `{"format":"tg_todo_bot","version":1,"exported_at":"2026-10-19T12:00:00+03:00","tasks":[
+				{"id":1,"title":"Dentist","description":"Second floor","due":"2026-10-20T15:00:00+03:00","done":false,"priority":1,"tags":["health"],"blocked_by":[],"notification":null},
+				{"id":2,"title":"Old","description":"","due":null,"done":true,"priority":0,"tags":[],"blocked_by":[],"notification":null}]}`,
+			expected: Result{
+				Format: FormatExport,
+				Tasks: []tasks_types.CreateParams{
+					{
+						Title:       "Dentist",
+						Description: "Second floor",
+						Datetime:    date(time.Date(2026, 10, 20, 15, 0, 0, 0, moscow)),
+						Priority:    1,
+						Tags:        []string{"health"},
+						UserID:      7,
+					},
+				},
+				Skipped: 1,
+			},
+		},
+		{
+			name:     "csv with semicolons",
+			fileName: "export.csv",
+			data:     "Задача;Срок;Приоритет;Теги;Выполнено\nКупить молоко;21.10.2026 18:00;2;дом, магазин;\nСтарое;;;;да\n",
+			expected: Result{
+				Format: FormatCSV,
+				Tasks: []tasks_types.CreateParams{
+					{Title: "Купить молоко", Datetime: date(time.Date(2026, 10, 21, 18, 0, 0, 0, moscow)), Priority: 2, Tags: []string{"дом", "магазин"}, UserID: 7},
+				},
+				Skipped: 1,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		got, err := Parse(testCase.fileName, []byte(testCase.data), params)
+		if err != nil {
+			t.Fatalf("%s: %v", testCase.name, err)
+		}
+
+		//Время сравнивается как момент, у распарсенного может быть другая локация
+		for i := range got.Tasks {
+			if i < len(testCase.expected.Tasks) && got.Tasks[i].Datetime != nil && testCase.expected.Tasks[i].Datetime != nil &&
+				got.Tasks[i].Datetime.Equal(*testCase.expected.Tasks[i].Datetime) {
+				got.Tasks[i].Datetime = testCase.expected.Tasks[i].Datetime
+			}
+		}
+		if !reflect.DeepEqual(got, testCase.expected) {
+			t.Fatalf("%s: got %+v, expected %+v", testCase.name, got, testCase.expected)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		fileName string
+		data     string
+		expected error
+	}{
+		{name: "only completed", fileName: "todo.txt", data: "- [x] Done\n\n", expected: ErrNoTasks},
+		{name: "too many", fileName: "todo.txt", data: strings.Repeat("Task\n", MaxTasks+1), expected: ErrTooManyTasks},
+	}
+
+	for _, testCase := range testCases {
+		_, err := Parse(testCase.fileName, []byte(testCase.data), Params{})
+		if !errors.Is(err, testCase.expected) {
+			t.Fatalf("%s: got %v, expected %v", testCase.name, err, testCase.expected)
+		}
+	}
+
+	_, err := Parse("export.csv", []byte("due\n2026-10-20\n"), Params{})
+	if err == nil {
+		t.Fatal("got no error for CSV without the title column")
+	}
+
+	_, err = Parse("board.json", []byte(`{"cards": "none"}`), Params{})
+	if err == nil {
+		t.Fatal("got no error for invalid Trello export")
+	}
+
+	_, err = Parse("todo.txt", []byte("\xff\xfe"), Params{})
+	if err == nil {
+		t.Fatal("got no error for a file not in UTF-8")
+	}
+}
+
+func TestParseCSVExport(t *testing.T) {
+	due := time.Date(2026, 10, 20, 15, 0, 0, 0, time.UTC)
+	tasks := []models.Task{
+		{ID: 1, Title: "Dentist", Description: "Second floor", Datetime: &due, Priority: 1, Tags: []string{"health", "city"}},
+		{ID: 2, Title: "Old", Done: true},
+	}
+
+	var buffer bytes.Buffer
+	writer, err := exporters.NewWriter(exporters.FormatCSV, &buffer, exporters.Params{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = writer.Write(tasks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = writer.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Parse(exporters.FileName(exporters.FormatCSV, due), buffer.Bytes(), Params{UserID: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := Result{
+		Format: FormatCSV,
+		Tasks: []tasks_types.CreateParams{
+			{Title: "Dentist", Description: "Second floor", Datetime: &due, Priority: 1, Tags: []string{"health", "city"}, UserID: 7},
+		},
+		Skipped: 1,
+	}
+	if len(got.Tasks) == 1 && got.Tasks[0].Datetime != nil && got.Tasks[0].Datetime.Equal(due) {
+		got.Tasks[0].Datetime = &due
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("got %+v, expected %+v", got, expected)
+	}
+}
@@ -0,0 +1,124 @@
+package importers
+
+import (
+	"strings"
+	"time"
+)
+
+// listMarkers -> prefixes of list items in notes apps and Markdown, they aren't a part of the title
+var listMarkers = []string{"- [ ] ", "* [ ] ", "[ ] ", "- ", "* ", "• ", "– "}
+
+var doneMarkers = []string{"- [x] ", "* [x] ", "[x] ", "- [X] ", "* [X] ", "[X] ", "✓ ", "✔ "}
+
+// parseText -> one task per line like in /add: "20.10.2026 15:00 Title #tag !1", the date is optional.
+// Checked items of checklists are skipped, Markdown headings are ignored,
+// quotes ("> text") under a task are its description like in Markdown exports of the bot
+func parseText(data []byte, params Params) (Result, error) {
+	var result Result
+
+	//Индекс задачи, к которой относятся цитаты, -1 если предыдущий пункт пропущен
+	lastTask := -1
+
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "# ") || strings.HasPrefix(line, "## ") {
+			continue
+		}
+
+		if line == ">" || strings.HasPrefix(line, "> ") {
+			if lastTask >= 0 {
+				task := &result.Tasks[lastTask]
+				quote := strings.TrimSpace(line[1:])
+				if task.Description != "" {
+					quote = task.Description + "\n" + quote
+				}
+				task.Description = quote
+			}
+			continue
+		}
+		lastTask = -1
+
+		if hasAnyPrefix(line, doneMarkers) {
+			result.Skipped++
+			continue
+		}
+		for _, marker := range listMarkers {
+			if strings.HasPrefix(line, marker) {
+				line = strings.TrimSpace(strings.TrimPrefix(line, marker))
+				break
+			}
+		}
+
+		datetime, rest := splitDatePrefix(line, params.Location)
+		title, tags, priority := splitTitleMarks(rest)
+		if title == "" {
+			result.Skipped++
+			continue
+		}
+
+		task := newTask(title, params)
+		task.Datetime = datetime
+		task.Tags = tags
+		task.Priority = priority
+		result.Tasks = append(result.Tasks, task)
+		lastTask = len(result.Tasks) - 1
+	}
+
+	return result, nil
+}
+
+func hasAnyPrefix(value string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(value, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// splitDatePrefix ("2026-10-20 15:00 Title") -> datetime and "Title", the datetime is nil if the line doesn't start with a date
+func splitDatePrefix(line string, location *time.Location) (*time.Time, string) {
+	fields := strings.Fields(line)
+
+	if len(fields) >= 2 {
+		datetime := parseDate(fields[0]+" "+fields[1], location)
+		if datetime != nil {
+			return datetime, strings.Join(fields[2:], " ")
+		}
+	}
+
+	if len(fields) >= 1 {
+		date := parseDate(fields[0], location)
+		if date != nil {
+			return date, strings.Join(fields[1:], " ")
+		}
+	}
+
+	return nil, strings.Join(fields, " ")
+}
+
+// splitTitleMarks ("Buy milk #home !1") -> ("Buy milk", ["home"], 1), "!N" outside of 1-3 stays in the title
+func splitTitleMarks(line string) (string, []string, int) {
+	var (
+		words    []string
+		tags     []string
+		priority int
+	)
+
+	for _, word := range strings.Fields(line) {
+		if len(word) > 1 && strings.HasPrefix(word, "#") {
+			tags = append(tags, tagFromName(word))
+			continue
+		}
+
+		if len(word) == 2 && word[0] == '!' && word[1] >= '1' && word[1] <= '3' {
+			priority = int(word[1] - '0')
+			continue
+		}
+
+		words = append(words, word)
+	}
+
+	return strings.Join(words, " "), tags, priority
+}
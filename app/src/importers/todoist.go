@@ -0,0 +1,80 @@
+package importers
+
+import (
+	"fmt"
+	"strings"
+)
+
+// todoistPriorities -> Todoist exports p1 as 4 and tasks without priority as 1
+var todoistPriorities = map[string]int{"4": 1, "3": 2, "2": 3}
+
+// parseTodoist -> rows of the "task" type become tasks, "section" rows become a tag of the following tasks
+// and "note" rows are added to the description of the previous task. "@labels" of the content become tags.
+// Recurring dates like "every monday" can't be kept, such tasks are imported without a date
+func parseTodoist(data []byte, params Params) (Result, error) {
+	rows, err := readCSV(data)
+	if err != nil {
+		return Result{}, err
+	}
+	if len(rows) == 0 {
+		return Result{}, nil
+	}
+
+	columns := map[string]int{}
+	for i, name := range rows[0] {
+		columns[strings.ToUpper(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"TYPE", "CONTENT"} {
+		if _, exist := columns[required]; !exist {
+			return Result{}, fmt.Errorf("no %s column in the Todoist export", required)
+		}
+	}
+
+	var result Result
+	var section string
+	for _, row := range rows[1:] {
+		cell := func(column string) string {
+			i, exist := columns[column]
+			if !exist || i >= len(row) {
+				return ""
+			}
+			return strings.TrimSpace(row[i])
+		}
+
+		//Остальные строки экспорта (meta) служебные
+		switch strings.ToLower(cell("TYPE")) {
+		case "section":
+			section = tagFromName(cell("CONTENT"))
+		case "note":
+			if len(result.Tasks) > 0 && cell("CONTENT") != "" {
+				last := &result.Tasks[len(result.Tasks)-1]
+				last.Description = strings.TrimSpace(last.Description + "\n\n" + cell("CONTENT"))
+			}
+		case "task":
+			var words, tags []string
+			for _, word := range strings.Fields(cell("CONTENT")) {
+				if len(word) > 1 && strings.HasPrefix(word, "@") {
+					tags = append(tags, tagFromName(word[1:]))
+					continue
+				}
+				words = append(words, word)
+			}
+			if len(words) == 0 {
+				result.Skipped++
+				continue
+			}
+			if section != "" {
+				tags = append(tags, section)
+			}
+
+			task := newTask(strings.Join(words, " "), params)
+			task.Description = cell("DESCRIPTION")
+			task.Datetime = parseDate(cell("DATE"), params.Location)
+			task.Priority = todoistPriorities[cell("PRIORITY")]
+			task.Tags = tags
+			result.Tasks = append(result.Tasks, task)
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,91 @@
+package importers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type trelloBoard struct {
+	Lists []struct {
+		ID     string `json:"id"`
+		Name   string `json:"name"`
+		Closed bool   `json:"closed"`
+	} `json:"lists"`
+	Cards []struct {
+		ID          string     `json:"id"`
+		Name        string     `json:"name"`
+		Desc        string     `json:"desc"`
+		Due         *time.Time `json:"due"`
+		DueComplete bool       `json:"dueComplete"`
+		Closed      bool       `json:"closed"`
+		IDList      string     `json:"idList"`
+		Labels      []struct {
+			Name  string `json:"name"`
+			Color string `json:"color"`
+		} `json:"labels"`
+	} `json:"cards"`
+	Checklists []struct {
+		IDCard     string `json:"idCard"`
+		Name       string `json:"name"`
+		CheckItems []struct {
+			Name  string `json:"name"`
+			State string `json:"state"`
+		} `json:"checkItems"`
+	} `json:"checklists"`
+}
+
+// parseTrello -> open cards of open lists become tasks, the list and labels become tags.
+// Archived cards and cards with a completed due date are skipped, unchecked checklist items are added to the description
+func parseTrello(data []byte, params Params) (Result, error) {
+	var board trelloBoard
+	err := json.Unmarshal(data, &board)
+	if err != nil {
+		return Result{}, fmt.Errorf("invalid Trello export: %w", err)
+	}
+
+	listTags := map[string]string{}
+	closedLists := map[string]bool{}
+	for _, list := range board.Lists {
+		listTags[list.ID] = tagFromName(list.Name)
+		closedLists[list.ID] = list.Closed
+	}
+
+	checklists := map[string][]string{}
+	for _, checklist := range board.Checklists {
+		for _, item := range checklist.CheckItems {
+			if item.State != "complete" {
+				checklists[checklist.IDCard] = append(checklists[checklist.IDCard], "- "+item.Name)
+			}
+		}
+	}
+
+	var result Result
+	for _, card := range board.Cards {
+		if card.Closed || card.DueComplete || closedLists[card.IDList] || strings.TrimSpace(card.Name) == "" {
+			result.Skipped++
+			continue
+		}
+
+		task := newTask(card.Name, params)
+		task.Description = strings.TrimSpace(card.Desc + "\n\n" + strings.Join(checklists[card.ID], "\n"))
+		task.Datetime = card.Due
+		if tag := listTags[card.IDList]; tag != "" {
+			task.Tags = append(task.Tags, tag)
+		}
+		for _, label := range card.Labels {
+			//У меток Трелло может быть только цвет
+			name := label.Name
+			if name == "" {
+				name = label.Color
+			}
+			if name != "" {
+				task.Tags = append(task.Tags, tagFromName(name))
+			}
+		}
+		result.Tasks = append(result.Tasks, task)
+	}
+
+	return result, nil
+}
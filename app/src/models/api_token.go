@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// ApiToken -> only the SHA-256 hash of the token is stored, the token itself is shown to the user once
+type ApiToken struct {
+	ID        int64
+	UserID    int64
+	Scope     string //what the token gives access to, a user has one token per scope
+	TokenHash string
+	CreatedAt time.Time
+}
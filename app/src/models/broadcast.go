@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// Broadcast -> a message of the admin to all users, sent in the order of user IDs.
+// LastUserID is the last user it was sent to, so a stopped broadcast continues after it
+type Broadcast struct {
+	ID         int64
+	Text       string
+	Status     string
+	LastUserID int64
+	Sent       int
+	Failed     int
+	CreatedAt  time.Time
+	FinishedAt *time.Time
+}
@@ -0,0 +1,9 @@
+package models
+
+// CalDAVObject -> name of the task's resource and its UID given by a CalDAV client
+type CalDAVObject struct {
+	TaskID int64
+	UserID int64
+	Name   string
+	UID    string
+}
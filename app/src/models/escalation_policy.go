@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// EscalationPolicy -> reminders about an overdue task: the first one after OverdueAfter,
+// then every RepeatInterval, MaxReminders in total. TaskID is nil for the user's default policy
+type EscalationPolicy struct {
+	ID             int64
+	UserID         int64
+	TaskID         *int64
+	OverdueAfter   time.Duration
+	RepeatInterval time.Duration
+	MaxReminders   int
+	CreatedAt      time.Time
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// OutboxMessage -> a message of the bot saved in the same transaction as the change it's about,
+// Payload depends on Kind
+type OutboxMessage struct {
+	ID            int64
+	UserID        int64
+	Kind          string
+	DedupKey      string //the same message isn't saved twice
+	Payload       []byte
+	Attempts      int
+	NextAttemptAt *time.Time //nil when sent, skipped or out of attempts
+	LastError     string
+	SentAt        *time.Time
+	CreatedAt     time.Time
+}
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+type SavedFilter struct {
+	ID        int64
+	UserID    int64
+	Name      string
+	Query     string
+	CreatedAt time.Time
+}
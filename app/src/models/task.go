@@ -8,9 +8,12 @@ type Task struct {
 	Description string
 	Datetime    *time.Time
 	Done        bool
+	Priority    int //0 - without priority, 1 - the highest
 	UserID      int64
 	CreatedAt   time.Time
 
 	User         *User         //relation OneToOne
 	Notification *Notification //relation OneToOne
+	BlockedBy    []int64       //IDs of not completed tasks which block this one
+	Tags         []string      //relation OneToMany
 }
@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+// TaskDependency -> task TaskID is blocked by task BlockedByTaskID
+type TaskDependency struct {
+	TaskID          int64
+	BlockedByTaskID int64
+	CreatedAt       time.Time
+}
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// TaskEscalation -> the last sent escalation of the task, Deadline is the task's deadline at that moment
+type TaskEscalation struct {
+	TaskID      int64
+	Deadline    time.Time
+	Level       int
+	EscalatedAt time.Time
+}
@@ -3,7 +3,14 @@ package models
 import "time"
 
 type User struct {
-	ID         int64
-	TelegramID int64
-	CreatedAt  time.Time
+	ID                  int64
+	TelegramID          int64
+	CreatedAt           time.Time
+	DeletionRequestedAt *time.Time //set by /deleteme, the account is purged after the grace period
+	LastSeenAt          *time.Time //the last update from the user, updated at most every few minutes
+	BannedAt            *time.Time //updates of banned users are ignored
+	BanReason           string     //for the admin, empty if the user isn't banned
+	BotBlockedAt        *time.Time //the user blocked the bot, reminders are paused until the next update from them
+
+	Settings *UserSettings //relation OneToOne
 }
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+type UserSettings struct {
+	UserID         int64
+	Language       string
+	Timezone       string        //IANA name, e.g. Europe/Moscow
+	ReminderOffset time.Duration //how long before the task's time the reminder is sent
+	RepeatInterval time.Duration
+	ListSort       string
+	DigestMinute   *int //minutes since midnight, nil - without digest
+	LastDigestOn   *time.Time
+	UpdatedAt      time.Time
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// Webhook -> Secret signs the payloads, so it's stored as is, unlike API tokens
+type Webhook struct {
+	ID        int64
+	UserID    int64
+	URL       string
+	Secret    string
+	CreatedAt time.Time
+}
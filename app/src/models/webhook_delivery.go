@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+type WebhookDelivery struct {
+	ID            int64
+	WebhookID     int64
+	EventType     string
+	Payload       []byte
+	Attempts      int
+	NextAttemptAt *time.Time //nil when delivered or out of attempts
+	LastError     string
+	DeliveredAt   *time.Time
+	CreatedAt     time.Time
+
+	Webhook *Webhook //relation ManyToOne
+}
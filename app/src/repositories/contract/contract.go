@@ -0,0 +1,520 @@
+// Package contract -> tests every implementation of the repositories has to pass, so the in-memory ones
+// can stand in for the db ones. The tests create their own users and purge them at the end,
+// so they also run against a database with other data
+package contract
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"reflect"
+	"testing"
+	"tg_todo_bot/src/models"
+	"tg_todo_bot/src/repositories/types"
+	"time"
+)
+
+type TasksRepositoryI interface {
+	Create(ctx context.Context, task models.Task) (models.Task, error)
+	CreateMany(ctx context.Context, tasks []models.Task) ([]models.Task, error)
+	SearchActiveByDatetimeForUser(ctx context.Context, from, to *time.Time, userID int64) ([]models.Task, error)
+	GetAllActiveForUser(ctx context.Context, userID int64) ([]models.Task, error)
+	GetPageForUser(ctx context.Context, userID, afterTaskID int64, limit uint) ([]models.Task, error)
+	CountForUser(ctx context.Context, userID int64) (int64, int64, error)
+	Update(ctx context.Context, model models.Task) error
+	DeleteByID(ctx context.Context, ID int64) error
+	FindByID(ctx context.Context, ID int64) (models.Task, error)
+	GetActiveTasksWithoutDatetimeForUser(ctx context.Context, userID int64) ([]models.Task, error)
+	Search(ctx context.Context, userID int64, query string, filters types.TasksSearchFilters) ([]models.Task, error)
+	GetActiveForUserPage(ctx context.Context, userID int64, page types.TasksPageParams) ([]models.Task, error)
+	Filter(ctx context.Context, userID int64, filter types.TasksFilter) ([]models.Task, error)
+	GetOverdue(ctx context.Context, now time.Time, filters types.TasksOverdueFilters) ([]models.Task, error)
+}
+
+type NotificationsRepositoryI interface {
+	Create(ctx context.Context, notification models.Notification) (models.Notification, error)
+	Update(ctx context.Context, notification models.Notification) error
+	Fire(ctx context.Context, notification models.Notification, nextNotifyAt time.Time, message models.OutboxMessage) error
+	DeleteByID(ctx context.Context, ID int64) error
+	GetUpcoming(ctx context.Context, upcomingTo time.Time) ([]models.Notification, error)
+	FindByID(ctx context.Context, ID int64) (models.Notification, error)
+	FindByTasksIDs(ctx context.Context, tasksIDs []int64) (map[int64]models.Notification, error)
+}
+
+type UsersRepositoryI interface {
+	Create(ctx context.Context, user models.User) (models.User, error)
+	FindByTelegramID(ctx context.Context, telegramID int64) (models.User, error)
+	FindByID(ctx context.Context, ID int64) (models.User, error)
+	SetDeletionRequestedAt(ctx context.Context, userID int64, requestedAt *time.Time) error
+	GetDeletionRequestedBefore(ctx context.Context, requestedBefore time.Time) ([]models.User, error)
+	Purge(ctx context.Context, userID int64) (map[string]int64, error)
+	PurgeRequestedBefore(ctx context.Context, userID int64, requestedBefore time.Time) (map[string]int64, error)
+	SetLastSeenAt(ctx context.Context, userID int64, seenAt time.Time) error
+	SetBan(ctx context.Context, userID int64, bannedAt *time.Time, reason string) error
+	SetBotBlockedAt(ctx context.Context, userID int64, blockedAt *time.Time) error
+	GetPage(ctx context.Context, afterUserID int64, limit uint) ([]models.User, error)
+	Count(ctx context.Context, filter types.UsersCountFilter) (int64, error)
+}
+
+// Repositories -> one implementation of the repositories sharing the same data
+type Repositories struct {
+	Tasks         TasksRepositoryI
+	Notifications NotificationsRepositoryI
+	Users         UsersRepositoryI
+}
+
+// createUser -> a user with a telegram ID no other test uses, purged at the end of the test
+func createUser(t *testing.T, repositories Repositories) models.User {
+	t.Helper()
+
+	user, err := repositories.Users.Create(context.Background(), models.User{TelegramID: -time.Now().UnixNano()})
+	if err != nil {
+		t.Fatalf("Users.Create: %v", err)
+	}
+	t.Cleanup(func() {
+		_, err := repositories.Users.Purge(context.Background(), user.ID)
+		if err != nil && !errors.Is(err, types.ErrNotFound) {
+			t.Errorf("Users.Purge: %v", err)
+		}
+	})
+
+	return user
+}
+
+// createTask -> the task of the user with the title and datetime
+func createTask(t *testing.T, repositories Repositories, userID int64, title string, datetime *time.Time) models.Task {
+	t.Helper()
+
+	task, err := repositories.Tasks.Create(context.Background(), models.Task{Title: title, Datetime: datetime, UserID: userID})
+	if err != nil {
+		t.Fatalf("Tasks.Create: %v", err)
+	}
+
+	return task
+}
+
+// at (hours) -> a time in hours from a fixed day, in microseconds like the timestamps of the database
+func at(hours float64) *time.Time {
+	datetime := time.Date(2026, 10, 20, 10, 0, 0, 0, time.UTC).Add(time.Duration(hours * float64(time.Hour)))
+	return &datetime
+}
+
+func titles(tasks []models.Task) []string {
+	result := []string{}
+	for _, task := range tasks {
+		result = append(result, task.Title)
+	}
+
+	return result
+}
+
+func assertTitles(t *testing.T, method string, tasks []models.Task, expected ...string) {
+	t.Helper()
+
+	if expected == nil {
+		expected = []string{}
+	}
+	if !reflect.DeepEqual(titles(tasks), expected) {
+		t.Fatalf("%s: got %v, expected %v", method, titles(tasks), expected)
+	}
+}
+
+func TestUsersRepository(t *testing.T, repositories Repositories) {
+	ctx := context.Background()
+	user := createUser(t, repositories)
+
+	_, err := repositories.Users.Create(ctx, models.User{TelegramID: user.TelegramID})
+	if !errors.Is(err, types.ErrAlreadyExist) {
+		t.Fatalf("Create with the same telegram ID: got %v, expected ErrAlreadyExist", err)
+	}
+
+	found, err := repositories.Users.FindByTelegramID(ctx, user.TelegramID)
+	if err != nil || found.ID != user.ID {
+		t.Fatalf("FindByTelegramID: got %+v, %v, expected user %d", found, err, user.ID)
+	}
+	_, err = repositories.Users.FindByTelegramID(ctx, 0)
+	if !errors.Is(err, types.ErrNotFound) {
+		t.Fatalf("FindByTelegramID of a missing user: got %v, expected ErrNotFound", err)
+	}
+
+	bannedAt := at(0)
+	err = repositories.Users.SetBan(ctx, user.ID, bannedAt, "spam")
+	if err != nil {
+		t.Fatalf("SetBan: %v", err)
+	}
+	found, err = repositories.Users.FindByID(ctx, user.ID)
+	if err != nil || found.BannedAt == nil || !found.BannedAt.Equal(*bannedAt) || found.BanReason != "spam" {
+		t.Fatalf("FindByID after SetBan: got %+v, %v", found, err)
+	}
+	err = repositories.Users.SetBan(ctx, user.ID, nil, "spam")
+	if err != nil {
+		t.Fatalf("SetBan(nil): %v", err)
+	}
+	found, err = repositories.Users.FindByID(ctx, user.ID)
+	if err != nil || found.BannedAt != nil || found.BanReason != "" {
+		t.Fatalf("FindByID after SetBan(nil): got %+v, %v, expected no ban and no reason", found, err)
+	}
+
+	err = repositories.Users.SetLastSeenAt(ctx, user.ID, *at(1))
+	if err != nil {
+		t.Fatalf("SetLastSeenAt: %v", err)
+	}
+	found, err = repositories.Users.FindByID(ctx, user.ID)
+	if err != nil || found.LastSeenAt == nil || !found.LastSeenAt.Equal(*at(1)) {
+		t.Fatalf("FindByID after SetLastSeenAt: got %+v, %v", found, err)
+	}
+
+	deletionRequestedAt := time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC)
+	err = repositories.Users.SetDeletionRequestedAt(ctx, user.ID, &deletionRequestedAt)
+	if err != nil {
+		t.Fatalf("SetDeletionRequestedAt: %v", err)
+	}
+	users, err := repositories.Users.GetDeletionRequestedBefore(ctx, deletionRequestedAt)
+	if err != nil || len(users) == 0 || users[0].ID != user.ID {
+		t.Fatalf("GetDeletionRequestedBefore: got %+v, %v, expected user %d first", users, err, user.ID)
+	}
+
+	users, err = repositories.Users.GetPage(ctx, user.ID-1, 1)
+	if err != nil || len(users) != 1 || users[0].ID != user.ID {
+		t.Fatalf("GetPage: got %+v, %v, expected only user %d", users, err, user.ID)
+	}
+
+	count, err := repositories.Users.Count(ctx, types.UsersCountFilter{DeletionRequested: true})
+	if err != nil || count < 1 {
+		t.Fatalf("Count: got %d, %v, expected at least 1", count, err)
+	}
+
+	_, err = repositories.Users.PurgeRequestedBefore(ctx, user.ID, deletionRequestedAt.Add(-time.Hour))
+	if !errors.Is(err, types.ErrNotFound) {
+		t.Fatalf("PurgeRequestedBefore before the grace period is over: got %v, expected ErrNotFound", err)
+	}
+	err = repositories.Users.SetDeletionRequestedAt(ctx, user.ID, nil)
+	if err != nil {
+		t.Fatalf("SetDeletionRequestedAt(nil): %v", err)
+	}
+	_, err = repositories.Users.PurgeRequestedBefore(ctx, user.ID, deletionRequestedAt)
+	if !errors.Is(err, types.ErrNotFound) {
+		t.Fatalf("PurgeRequestedBefore of a cancelled deletion: got %v, expected ErrNotFound", err)
+	}
+	_, err = repositories.Users.FindByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("FindByID after PurgeRequestedBefore of a cancelled deletion: %v", err)
+	}
+
+	task := createTask(t, repositories, user.ID, "purged", nil)
+	createTask(t, repositories, user.ID, "purged too", nil)
+	deleted, err := repositories.Users.Purge(ctx, user.ID)
+	if err != nil || deleted["users"] != 1 || deleted["tasks"] != 2 {
+		t.Fatalf("Purge: got %v, %v, expected 1 user and 2 tasks", deleted, err)
+	}
+	_, err = repositories.Tasks.FindByID(ctx, task.ID)
+	if !errors.Is(err, types.ErrNotFound) {
+		t.Fatalf("FindByID of a task of the purged user: got %v, expected ErrNotFound", err)
+	}
+	_, err = repositories.Users.Purge(ctx, user.ID)
+	if !errors.Is(err, types.ErrNotFound) {
+		t.Fatalf("Purge of a purged user: got %v, expected ErrNotFound", err)
+	}
+	err = repositories.Users.SetBotBlockedAt(ctx, user.ID, nil)
+	if !errors.Is(err, types.ErrNotFound) {
+		t.Fatalf("SetBotBlockedAt of a purged user: got %v, expected ErrNotFound", err)
+	}
+
+	requested := createUser(t, repositories)
+	err = repositories.Users.SetDeletionRequestedAt(ctx, requested.ID, &deletionRequestedAt)
+	if err != nil {
+		t.Fatalf("SetDeletionRequestedAt: %v", err)
+	}
+	deleted, err = repositories.Users.PurgeRequestedBefore(ctx, requested.ID, deletionRequestedAt)
+	if err != nil || deleted["users"] != 1 {
+		t.Fatalf("PurgeRequestedBefore: got %v, %v, expected 1 user", deleted, err)
+	}
+}
+
+func TestTasksRepository(t *testing.T, repositories Repositories) {
+	ctx := context.Background()
+	user := createUser(t, repositories)
+
+	later := createTask(t, repositories, user.ID, "bravo", at(2))
+	sameTime := createTask(t, repositories, user.ID, "charlie", at(1))
+	withoutDatetime := createTask(t, repositories, user.ID, "alpha", nil)
+	first := createTask(t, repositories, user.ID, "alpha", at(1))
+	done := createTask(t, repositories, user.ID, "delta zebra", at(0))
+	done.Done = true
+	err := repositories.Tasks.Update(ctx, done)
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	found, err := repositories.Tasks.FindByID(ctx, later.ID)
+	if err != nil || found.Title != "bravo" || !found.Datetime.Equal(*at(2)) || found.UserID != user.ID {
+		t.Fatalf("FindByID: got %+v, %v", found, err)
+	}
+
+	tasks, err := repositories.Tasks.GetAllActiveForUser(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetAllActiveForUser: %v", err)
+	}
+	assertTitles(t, "GetAllActiveForUser", tasks, "alpha", "charlie", "bravo", "alpha")
+	if tasks[0].ID != first.ID || tasks[3].ID != withoutDatetime.ID {
+		t.Fatalf("GetAllActiveForUser: got %+v, tasks without datetime are expected last", tasks)
+	}
+
+	tasks, err = repositories.Tasks.SearchActiveByDatetimeForUser(ctx, at(1), at(1), user.ID)
+	if err != nil {
+		t.Fatalf("SearchActiveByDatetimeForUser: %v", err)
+	}
+	assertTitles(t, "SearchActiveByDatetimeForUser", tasks, "alpha", "charlie")
+	_, err = repositories.Tasks.SearchActiveByDatetimeForUser(ctx, nil, nil, user.ID)
+	if err == nil {
+		t.Fatalf("SearchActiveByDatetimeForUser without from and to: expected an error")
+	}
+
+	tasks, err = repositories.Tasks.GetActiveTasksWithoutDatetimeForUser(ctx, user.ID)
+	if err != nil || len(tasks) != 1 || tasks[0].ID != withoutDatetime.ID {
+		t.Fatalf("GetActiveTasksWithoutDatetimeForUser: got %+v, %v", tasks, err)
+	}
+
+	tasks, err = repositories.Tasks.GetPageForUser(ctx, user.ID, later.ID, 2)
+	if err != nil || len(tasks) != 2 || tasks[0].ID != sameTime.ID || tasks[1].ID != withoutDatetime.ID {
+		t.Fatalf("GetPageForUser: got %+v, %v", tasks, err)
+	}
+
+	cursor := types.TasksCursor{Datetime: sameTime.Datetime, Title: sameTime.Title, ID: sameTime.ID}
+	tasks, err = repositories.Tasks.GetActiveForUserPage(ctx, user.ID, types.TasksPageParams{After: &cursor, Limit: 10})
+	if err != nil {
+		t.Fatalf("GetActiveForUserPage after: %v", err)
+	}
+	assertTitles(t, "GetActiveForUserPage after", tasks, "bravo", "alpha")
+	laterCursor := types.TasksCursor{Datetime: later.Datetime, Title: later.Title, ID: later.ID}
+	tasks, err = repositories.Tasks.GetActiveForUserPage(ctx, user.ID, types.TasksPageParams{Before: &laterCursor, Limit: 1})
+	if err != nil {
+		t.Fatalf("GetActiveForUserPage before: %v", err)
+	}
+	assertTitles(t, "GetActiveForUserPage before", tasks, "charlie")
+
+	tasks, err = repositories.Tasks.Search(ctx, user.ID, "zeb", types.TasksSearchFilters{})
+	if err != nil || len(tasks) != 1 || tasks[0].ID != done.ID {
+		t.Fatalf("Search: got %+v, %v, expected only task %d", tasks, err, done.ID)
+	}
+	notDone := false
+	tasks, err = repositories.Tasks.Search(ctx, user.ID, "zebra", types.TasksSearchFilters{Done: &notDone})
+	if err != nil {
+		t.Fatalf("Search of not completed tasks: %v", err)
+	}
+	assertTitles(t, "Search of not completed tasks", tasks)
+	tasks, err = repositories.Tasks.Search(ctx, user.ID, "", types.TasksSearchFilters{})
+	if err != nil || len(tasks) != 0 {
+		t.Fatalf("Search without query: got %+v, %v, expected nothing", tasks, err)
+	}
+
+	tasks, err = repositories.Tasks.Filter(ctx, user.ID, types.TasksFilter{Done: &notDone, WithDue: true, SortBy: types.TasksSortByTitle, SortDesc: true})
+	if err != nil {
+		t.Fatalf("Filter: %v", err)
+	}
+	assertTitles(t, "Filter", tasks, "charlie", "bravo", "alpha")
+
+	tasks, err = repositories.Tasks.GetOverdue(ctx, *at(1.5), types.TasksOverdueFilters{UserID: user.ID})
+	if err != nil {
+		t.Fatalf("GetOverdue: %v", err)
+	}
+	assertTitles(t, "GetOverdue", tasks, "charlie", "alpha")
+
+	//Задача без времени хранится на 00:00 и просрочена только после конца дня
+	midnight := createTask(t, repositories, user.ID, "echo", at(-10))
+	tasks, err = repositories.Tasks.GetOverdue(ctx, *at(-9), types.TasksOverdueFilters{UserID: user.ID})
+	if err != nil {
+		t.Fatalf("GetOverdue of a task without time: %v", err)
+	}
+	assertTitles(t, "GetOverdue of a task without time", tasks)
+	err = repositories.Tasks.DeleteByID(ctx, midnight.ID)
+	if err != nil {
+		t.Fatalf("DeleteByID: %v", err)
+	}
+
+	active, completed, err := repositories.Tasks.CountForUser(ctx, user.ID)
+	if err != nil || active != 4 || completed != 1 {
+		t.Fatalf("CountForUser: got %d, %d, %v, expected 4 and 1", active, completed, err)
+	}
+
+	_, err = repositories.Tasks.FindByID(ctx, midnight.ID)
+	if !errors.Is(err, types.ErrNotFound) {
+		t.Fatalf("FindByID of a deleted task: got %v, expected ErrNotFound", err)
+	}
+
+	created, err := repositories.Tasks.CreateMany(ctx, []models.Task{
+		{Title: "foxtrot", UserID: user.ID, Tags: []string{"home", "work"}, Priority: 2},
+		{Title: "golf", UserID: user.ID, Priority: 1},
+		{Title: "hotel", UserID: user.ID, Tags: []string{"home"}},
+	})
+	if err != nil || len(created) != 3 || created[0].ID == 0 || created[0].CreatedAt.IsZero() {
+		t.Fatalf("CreateMany: got %+v, %v", created, err)
+	}
+	tasks, err = repositories.Tasks.Filter(ctx, user.ID, types.TasksFilter{Tags: []string{"home"}})
+	if err != nil {
+		t.Fatalf("Filter by tag: %v", err)
+	}
+	assertTitles(t, "Filter by tag", tasks, "foxtrot", "hotel")
+	tasks, err = repositories.Tasks.Filter(ctx, user.ID, types.TasksFilter{Tags: []string{"home", "work"}})
+	if err != nil {
+		t.Fatalf("Filter by all tags: %v", err)
+	}
+	assertTitles(t, "Filter by all tags", tasks, "foxtrot")
+	tasks, err = repositories.Tasks.Filter(ctx, user.ID, types.TasksFilter{Tags: []string{"home"}, ExcludedTags: []string{"work"}})
+	if err != nil {
+		t.Fatalf("Filter by excluded tag: %v", err)
+	}
+	assertTitles(t, "Filter by excluded tag", tasks, "hotel")
+
+	priorityFrom, priorityTo := 1, 2
+	tasks, err = repositories.Tasks.Filter(ctx, user.ID, types.TasksFilter{PriorityFrom: &priorityFrom, PriorityTo: &priorityTo, SortBy: types.TasksSortByPriority})
+	if err != nil {
+		t.Fatalf("Filter by priority: %v", err)
+	}
+	assertTitles(t, "Filter by priority", tasks, "golf", "foxtrot")
+	//Задачи без приоритета идут после всех остальных, при убывании - первыми
+	tasks, err = repositories.Tasks.Filter(ctx, user.ID, types.TasksFilter{WithoutDue: true, SortBy: types.TasksSortByPriority})
+	if err != nil {
+		t.Fatalf("Filter sorted by priority: %v", err)
+	}
+	assertTitles(t, "Filter sorted by priority", tasks, "golf", "foxtrot", "alpha", "hotel")
+	tasks, err = repositories.Tasks.Filter(ctx, user.ID, types.TasksFilter{WithoutDue: true, SortBy: types.TasksSortByPriority, SortDesc: true})
+	if err != nil {
+		t.Fatalf("Filter sorted by priority descending: %v", err)
+	}
+	assertTitles(t, "Filter sorted by priority descending", tasks, "hotel", "alpha", "foxtrot", "golf")
+
+	//При равном времени порядок определяет ID
+	tasks, err = repositories.Tasks.Filter(ctx, user.ID, types.TasksFilter{Done: &notDone, WithDue: true, Limit: 2})
+	if err != nil {
+		t.Fatalf("Filter sorted by due: %v", err)
+	}
+	assertTitles(t, "Filter sorted by due", tasks, "charlie", "alpha")
+
+	blocked := createUser(t, repositories)
+	createTask(t, repositories, blocked.ID, "overdue", at(0))
+	err = repositories.Users.SetBotBlockedAt(ctx, blocked.ID, at(0))
+	if err != nil {
+		t.Fatalf("SetBotBlockedAt: %v", err)
+	}
+	tasks, err = repositories.Tasks.GetOverdue(ctx, *at(1), types.TasksOverdueFilters{UserID: blocked.ID, WithoutBotBlocked: true})
+	if err != nil {
+		t.Fatalf("GetOverdue without users who blocked the bot: %v", err)
+	}
+	assertTitles(t, "GetOverdue without users who blocked the bot", tasks)
+	tasks, err = repositories.Tasks.GetOverdue(ctx, *at(1), types.TasksOverdueFilters{UserID: blocked.ID})
+	if err != nil {
+		t.Fatalf("GetOverdue of a user who blocked the bot: %v", err)
+	}
+	assertTitles(t, "GetOverdue of a user who blocked the bot", tasks, "overdue")
+
+	err = repositories.Users.SetBotBlockedAt(ctx, blocked.ID, nil)
+	if err != nil {
+		t.Fatalf("SetBotBlockedAt(nil): %v", err)
+	}
+	tasks, err = repositories.Tasks.GetOverdue(ctx, *at(1), types.TasksOverdueFilters{UserID: blocked.ID, WithoutBotBlocked: true})
+	if err != nil {
+		t.Fatalf("GetOverdue after the bot is unblocked: %v", err)
+	}
+	assertTitles(t, "GetOverdue after the bot is unblocked", tasks, "overdue")
+}
+
+func TestNotificationsRepository(t *testing.T, repositories Repositories) {
+	ctx := context.Background()
+	user := createUser(t, repositories)
+	task := createTask(t, repositories, user.ID, "alpha", at(1))
+
+	notification, err := repositories.Notifications.Create(ctx, models.Notification{TaskID: task.ID, NotifyAt: *at(0)})
+	if err != nil || notification.ID == 0 {
+		t.Fatalf("Create: got %+v, %v", notification, err)
+	}
+	_, err = repositories.Notifications.Create(ctx, models.Notification{TaskID: task.ID, NotifyAt: *at(0)})
+	if !errors.Is(err, types.ErrAlreadyExist) {
+		t.Fatalf("Create for the same task: got %v, expected ErrAlreadyExist", err)
+	}
+
+	notifications, err := repositories.Notifications.FindByTasksIDs(ctx, []int64{task.ID, 0})
+	if err != nil || len(notifications) != 1 || notifications[task.ID].ID != notification.ID {
+		t.Fatalf("FindByTasksIDs: got %+v, %v", notifications, err)
+	}
+	notifications, err = repositories.Notifications.FindByTasksIDs(ctx, []int64{0})
+	if err != nil || notifications == nil || len(notifications) != 0 {
+		t.Fatalf("FindByTasksIDs of tasks without notifications: got %#v, %v, expected an empty map", notifications, err)
+	}
+
+	upcoming, err := repositories.Notifications.GetUpcoming(ctx, *at(0))
+	if err != nil || !containsNotification(upcoming, notification.ID) {
+		t.Fatalf("GetUpcoming: got %+v, %v, expected notification %d", upcoming, err, notification.ID)
+	}
+	err = repositories.Users.SetBotBlockedAt(ctx, user.ID, at(0))
+	if err != nil {
+		t.Fatalf("SetBotBlockedAt: %v", err)
+	}
+	upcoming, err = repositories.Notifications.GetUpcoming(ctx, *at(0))
+	if err != nil || containsNotification(upcoming, notification.ID) {
+		t.Fatalf("GetUpcoming of a user who blocked the bot: got %+v, %v", upcoming, err)
+	}
+
+	message := models.OutboxMessage{
+		UserID:        user.ID,
+		Kind:          "reminder",
+		DedupKey:      "contract-" + time.Now().Format(time.RFC3339Nano),
+		Payload:       []byte(`{}`),
+		NextAttemptAt: at(0),
+	}
+	stale := notification
+	stale.NotifyAt = *at(-1)
+	err = repositories.Notifications.Fire(ctx, stale, *at(24), message)
+	if !errors.Is(err, types.ErrNotFound) {
+		t.Fatalf("Fire of a changed notification: got %v, expected ErrNotFound", err)
+	}
+	err = repositories.Notifications.Fire(ctx, notification, *at(24), message)
+	if err != nil {
+		t.Fatalf("Fire: %v", err)
+	}
+	found, err := repositories.Notifications.FindByID(ctx, notification.ID)
+	if err != nil || !found.NotifyAt.Equal(*at(24)) {
+		t.Fatalf("FindByID after Fire: got %+v, %v", found, err)
+	}
+
+	found.RepeatInterval = time.Hour
+	err = repositories.Notifications.Update(ctx, found)
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	found, err = repositories.Notifications.FindByID(ctx, notification.ID)
+	if err != nil || found.RepeatInterval != time.Hour {
+		t.Fatalf("FindByID after Update: got %+v, %v", found, err)
+	}
+
+	err = repositories.Notifications.DeleteByID(ctx, notification.ID)
+	if err != nil {
+		t.Fatalf("DeleteByID: %v", err)
+	}
+	_, err = repositories.Notifications.FindByID(ctx, notification.ID)
+	if !errors.Is(err, types.ErrNotFound) {
+		t.Fatalf("FindByID of a deleted notification: got %v, expected ErrNotFound", err)
+	}
+
+	notification, err = repositories.Notifications.Create(ctx, models.Notification{TaskID: task.ID, NotifyAt: *at(0)})
+	if err != nil {
+		t.Fatalf("Create after DeleteByID: %v", err)
+	}
+	err = repositories.Tasks.DeleteByID(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("Tasks.DeleteByID: %v", err)
+	}
+	_, err = repositories.Notifications.FindByID(ctx, notification.ID)
+	if !errors.Is(err, types.ErrNotFound) {
+		t.Fatalf("FindByID of a notification of a deleted task: got %v, expected ErrNotFound", err)
+	}
+}
+
+func containsNotification(notifications []models.Notification, ID int64) bool {
+	for _, notification := range notifications {
+		if notification.ID == ID {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,129 @@
+package db
+
+import (
+	"context"
+	"github.com/doug-martin/goqu/v9"
+	_ "github.com/doug-martin/goqu/v9/dialect/postgres"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"tg_todo_bot/src/models"
+	"tg_todo_bot/src/repositories/types"
+	"time"
+)
+
+type ApiTokensRepository struct {
+	logger     *zap.SugaredLogger
+	dbInstance querier
+}
+
+func NewApiTokensRepository(
+	logger *zap.SugaredLogger,
+	dbInstance *pgxpool.Pool,
+	queryTimeout time.Duration,
+) *ApiTokensRepository {
+	return &ApiTokensRepository{
+		logger:     logger,
+		dbInstance: newTimeoutQuerier(dbInstance, queryTimeout),
+	}
+}
+
+// Save (ctx, token) -> the user has one token per scope, the new one replaces the previous
+func (repository *ApiTokensRepository) Save(ctx context.Context, token models.ApiToken) (models.ApiToken, error) {
+	now := time.Now()
+	query := goqu.Dialect("postgres").
+		Insert("api_tokens").
+		Rows(
+			goqu.Record{
+				"user_id":    token.UserID,
+				"scope":      token.Scope,
+				"token_hash": token.TokenHash,
+				"created_at": now,
+			},
+		).
+		OnConflict(
+			goqu.DoUpdate("user_id, scope", goqu.Record{
+				"token_hash": goqu.I("excluded.token_hash"),
+				"created_at": goqu.I("excluded.created_at"),
+			}),
+		).
+		Returning("id", "created_at")
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	row := repository.dbInstance.QueryRow(ctx, sql, args...)
+
+	err := row.Scan(&token.ID, &token.CreatedAt)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> ApiTokensRepository -> Save -> row.Scan()`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return models.ApiToken{}, err
+	}
+
+	return token, nil
+}
+
+func (repository *ApiTokensRepository) FindByHash(ctx context.Context, tokenHash string) (models.ApiToken, error) {
+	query := goqu.Dialect("postgres").
+		From("api_tokens").
+		Select(
+			goqu.C("id"),
+			goqu.C("user_id"),
+			goqu.C("scope"),
+			goqu.C("token_hash"),
+			goqu.C("created_at"),
+		).
+		Where(
+			goqu.C("token_hash").Eq(tokenHash),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	row := repository.dbInstance.QueryRow(ctx, sql, args...)
+
+	var token models.ApiToken
+	err := row.Scan(
+		&token.ID,
+		&token.UserID,
+		&token.Scope,
+		&token.TokenHash,
+		&token.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = types.ErrNotFound
+		}
+		repository.logger.Debugw(
+			`Repositories -> DB -> ApiTokensRepository -> FindByHash -> row.Scan()`,
+			"error", err.Error(), "SQL", sql,
+		)
+		return models.ApiToken{}, err
+	}
+
+	return token, nil
+}
+
+func (repository *ApiTokensRepository) DeleteForUser(ctx context.Context, userID int64, scope string) error {
+	query := goqu.Dialect("postgres").
+		Delete("api_tokens").
+		Where(
+			goqu.C("user_id").Eq(userID),
+			goqu.C("scope").Eq(scope),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	_, err := repository.dbInstance.Exec(ctx, sql, args...)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> ApiTokensRepository -> DeleteForUser -> repository.dbInstance.Exec(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return err
+	}
+
+	return nil
+}
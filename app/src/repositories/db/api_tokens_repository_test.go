@@ -0,0 +1,113 @@
+package db
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"strings"
+	"testing"
+	"tg_todo_bot/config"
+	"tg_todo_bot/kernel/db"
+	zap_logger "tg_todo_bot/kernel/logger"
+	"tg_todo_bot/src/models"
+	"tg_todo_bot/src/repositories/types"
+)
+
+func getApiTokensRepository() (*ApiTokensRepository, error) {
+	logger := zap_logger.InitLogger()
+
+	conf, err := config.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	pg := db.NewPG(
+		conf.Database.Host,
+		conf.Database.Port,
+		conf.Database.Database,
+		conf.Database.User,
+		conf.Database.Password,
+	)
+	pgInstance, err := pg.OpenPool()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewApiTokensRepository(logger, pgInstance, conf.Database.QueryTimeout), nil
+}
+
+func TestSaveApiToken(t *testing.T) {
+	repository, err := getApiTokensRepository()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := createUserForTest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer deleteUserAfterTest(user)
+
+	firstHash, secondHash := strings.Repeat("a", 64), strings.Repeat("b", 64)
+	_, err = repository.Save(context.Background(), models.ApiToken{UserID: user.ID, Scope: "api", TokenHash: firstHash})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//Новый токен заменяет старый
+	_, err = repository.Save(context.Background(), models.ApiToken{UserID: user.ID, Scope: "api", TokenHash: secondHash})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = repository.FindByHash(context.Background(), firstHash)
+	if !errors.Is(err, types.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for the replaced token, got %v", err)
+	}
+
+	token, err := repository.FindByHash(context.Background(), secondHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.UserID != user.ID || token.Scope != "api" {
+		t.Fatalf("got user %d and scope %s, expected %d and api", token.UserID, token.Scope, user.ID)
+	}
+
+	//Токен другой области не заменяет токен API
+	_, err = repository.Save(context.Background(), models.ApiToken{UserID: user.ID, Scope: "calendar", TokenHash: firstHash})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = repository.FindByHash(context.Background(), secondHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDeleteApiTokenForUser(t *testing.T) {
+	repository, err := getApiTokensRepository()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := createUserForTest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer deleteUserAfterTest(user)
+
+	tokenHash := strings.Repeat("c", 64)
+	_, err = repository.Save(context.Background(), models.ApiToken{UserID: user.ID, Scope: "api", TokenHash: tokenHash})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = repository.DeleteForUser(context.Background(), user.ID, "api")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = repository.FindByHash(context.Background(), tokenHash)
+	if !errors.Is(err, types.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
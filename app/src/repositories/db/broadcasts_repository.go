@@ -0,0 +1,253 @@
+package db
+
+import (
+	"context"
+	"github.com/doug-martin/goqu/v9"
+	_ "github.com/doug-martin/goqu/v9/dialect/postgres"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"tg_todo_bot/src/models"
+	"tg_todo_bot/src/repositories/types"
+	"time"
+)
+
+type BroadcastsRepository struct {
+	logger     *zap.SugaredLogger
+	dbInstance querier
+}
+
+func NewBroadcastsRepository(
+	logger *zap.SugaredLogger,
+	dbInstance *pgxpool.Pool,
+	queryTimeout time.Duration,
+) *BroadcastsRepository {
+	return &BroadcastsRepository{
+		logger:     logger,
+		dbInstance: newTimeoutQuerier(dbInstance, queryTimeout),
+	}
+}
+
+func (repository *BroadcastsRepository) Create(ctx context.Context, broadcast models.Broadcast) (models.Broadcast, error) {
+	now := time.Now()
+	query := goqu.Dialect("postgres").
+		Insert("broadcasts").
+		Rows(
+			goqu.Record{
+				"text":       broadcast.Text,
+				"status":     broadcast.Status,
+				"created_at": now,
+			},
+		).
+		Returning("id", "created_at")
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	row := repository.dbInstance.QueryRow(ctx, sql, args...)
+
+	err := row.Scan(&broadcast.ID, &broadcast.CreatedAt)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> BroadcastsRepository -> Create -> row.Scan()`,
+			"error", err.Error(), "SQL", sql,
+		)
+		return models.Broadcast{}, err
+	}
+
+	return broadcast, nil
+}
+
+func (repository *BroadcastsRepository) selectAllCols() *goqu.SelectDataset {
+	return goqu.Dialect("postgres").
+		From("broadcasts").
+		Select(
+			goqu.C("id"),
+			goqu.C("text"),
+			goqu.C("status"),
+			goqu.C("last_user_id"),
+			goqu.C("sent"),
+			goqu.C("failed"),
+			goqu.C("created_at"),
+			goqu.C("finished_at"),
+		)
+}
+
+func (repository *BroadcastsRepository) FindByID(ctx context.Context, broadcastID int64) (models.Broadcast, error) {
+	query := repository.selectAllCols().
+		Where(
+			goqu.C("id").Eq(broadcastID),
+		)
+
+	return repository.findOne(ctx, "FindByID", query)
+}
+
+// FindLatest -> the last created broadcast, types.ErrNotFound if there were none
+func (repository *BroadcastsRepository) FindLatest(ctx context.Context) (models.Broadcast, error) {
+	query := repository.selectAllCols().
+		Order(
+			goqu.C("id").Desc(),
+		).
+		Limit(1)
+
+	return repository.findOne(ctx, "FindLatest", query)
+}
+
+func (repository *BroadcastsRepository) findOne(ctx context.Context, method string, query *goqu.SelectDataset) (models.Broadcast, error) {
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	row := repository.dbInstance.QueryRow(ctx, sql, args...)
+
+	broadcast, err := scanBroadcast(row)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = types.ErrNotFound
+		}
+		repository.logger.Debugw(
+			`Repositories -> DB -> BroadcastsRepository -> `+method+` -> row.Scan()`,
+			"error", err.Error(),
+		)
+		return models.Broadcast{}, err
+	}
+
+	return broadcast, nil
+}
+
+func scanBroadcast(row pgx.Row) (models.Broadcast, error) {
+	var broadcast models.Broadcast
+	err := row.Scan(
+		&broadcast.ID,
+		&broadcast.Text,
+		&broadcast.Status,
+		&broadcast.LastUserID,
+		&broadcast.Sent,
+		&broadcast.Failed,
+		&broadcast.CreatedAt,
+		&broadcast.FinishedAt,
+	)
+
+	return broadcast, err
+}
+
+// Claim (ctx, status, now, lease) -> the oldest broadcast in the status which nobody sends, it's leased till
+// now+lease. types.ErrNotFound if there is none. Rows locked by other replicas are skipped
+func (repository *BroadcastsRepository) Claim(ctx context.Context, status string, now time.Time, lease time.Duration) (models.Broadcast, error) {
+	freeBroadcast := goqu.Dialect("postgres").
+		From("broadcasts").
+		Select("id").
+		Where(
+			goqu.C("status").Eq(status),
+			goqu.Or(
+				goqu.C("locked_until").IsNull(),
+				goqu.C("locked_until").Lte(now),
+			),
+		).
+		Order(
+			goqu.C("id").Asc(),
+		).
+		Limit(1).
+		ForUpdate(exp.SkipLocked)
+
+	query := goqu.Dialect("postgres").
+		Update("broadcasts").
+		Set(
+			goqu.Record{
+				"locked_until": now.Add(lease),
+			},
+		).
+		Where(
+			goqu.C("id").In(freeBroadcast),
+		).
+		Returning(
+			goqu.C("id"),
+			goqu.C("text"),
+			goqu.C("status"),
+			goqu.C("last_user_id"),
+			goqu.C("sent"),
+			goqu.C("failed"),
+			goqu.C("created_at"),
+			goqu.C("finished_at"),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	broadcast, err := scanBroadcast(repository.dbInstance.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Broadcast{}, types.ErrNotFound
+		}
+		repository.logger.Debugw(
+			`Repositories -> DB -> BroadcastsRepository -> Claim -> row.Scan()`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return models.Broadcast{}, err
+	}
+
+	return broadcast, nil
+}
+
+// UpdateProgress (ctx, broadcast, lockedUntil) -> saves LastUserID, Sent and Failed and extends the lease,
+// the status isn't touched, so a broadcast cancelled meanwhile stays cancelled
+func (repository *BroadcastsRepository) UpdateProgress(ctx context.Context, broadcast models.Broadcast, lockedUntil time.Time) error {
+	query := goqu.Dialect("postgres").
+		Update("broadcasts").
+		Set(
+			goqu.Record{
+				"last_user_id": broadcast.LastUserID,
+				"sent":         broadcast.Sent,
+				"failed":       broadcast.Failed,
+				"locked_until": lockedUntil,
+			},
+		).
+		Where(
+			goqu.C("id").Eq(broadcast.ID),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	_, err := repository.dbInstance.Exec(ctx, sql, args...)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> BroadcastsRepository -> UpdateProgress -> repository.dbInstance.Exec(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return err
+	}
+
+	return nil
+}
+
+// UpdateStatus (ctx, broadcastID, fromStatuses, status, finishedAt) -> changes the status only if the current one
+// is one of fromStatuses, types.ErrNotFound otherwise
+func (repository *BroadcastsRepository) UpdateStatus(ctx context.Context, broadcastID int64, fromStatuses []string, status string, finishedAt *time.Time) error {
+	query := goqu.Dialect("postgres").
+		Update("broadcasts").
+		Set(
+			goqu.Record{
+				"status":      status,
+				"finished_at": finishedAt,
+			},
+		).
+		Where(
+			goqu.C("id").Eq(broadcastID),
+			goqu.C("status").In(fromStatuses),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	result, err := repository.dbInstance.Exec(ctx, sql, args...)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> BroadcastsRepository -> UpdateStatus -> repository.dbInstance.Exec(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return types.ErrNotFound
+	}
+
+	return nil
+}
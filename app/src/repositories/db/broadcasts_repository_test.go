@@ -0,0 +1,110 @@
+package db
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"testing"
+	"tg_todo_bot/config"
+	"tg_todo_bot/kernel/db"
+	zap_logger "tg_todo_bot/kernel/logger"
+	"tg_todo_bot/src/models"
+	"tg_todo_bot/src/repositories/types"
+	"time"
+)
+
+func getBroadcastsRepository() (*BroadcastsRepository, error) {
+	logger := zap_logger.InitLogger()
+
+	conf, err := config.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	pg := db.NewPG(
+		conf.Database.Host,
+		conf.Database.Port,
+		conf.Database.Database,
+		conf.Database.User,
+		conf.Database.Password,
+	)
+	pgInstance, err := pg.OpenPool()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewBroadcastsRepository(logger, pgInstance, conf.Database.QueryTimeout), nil
+}
+
+// deleteBroadcastAfterTest -> broadcasts don't belong to users, so they aren't deleted with the test user
+func deleteBroadcastAfterTest(repository *BroadcastsRepository, broadcast models.Broadcast) {
+	_, _ = repository.dbInstance.Exec(context.Background(), "DELETE FROM broadcasts WHERE id = $1", broadcast.ID)
+}
+
+func TestBroadcastLifecycle(t *testing.T) {
+	repository, err := getBroadcastsRepository()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	broadcast, err := repository.Create(context.Background(), models.Broadcast{Text: "<b>News</b>", Status: "draft"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer deleteBroadcastAfterTest(repository, broadcast)
+
+	latest, err := repository.FindLatest(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest.ID != broadcast.ID || latest.Text != broadcast.Text || latest.Status != "draft" {
+		t.Fatalf("expected %+v, got %+v", broadcast, latest)
+	}
+
+	err = repository.UpdateStatus(context.Background(), broadcast.ID, []string{"running"}, "done", nil)
+	if !errors.Is(err, types.ErrNotFound) {
+		t.Fatalf("a draft can't be done, expected ErrNotFound, got %v", err)
+	}
+
+	err = repository.UpdateStatus(context.Background(), broadcast.ID, []string{"draft"}, "running", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	//Running broadcasts left by other tests are claimed too, until this one is found
+	now := time.Now()
+	for {
+		claimed, err := repository.Claim(context.Background(), "running", now, time.Minute)
+		if err != nil {
+			t.Fatalf("broadcast %d isn't claimed: %v", broadcast.ID, err)
+		}
+		if claimed.ID == broadcast.ID {
+			break
+		}
+	}
+	claimed, err := repository.Claim(context.Background(), "running", now, time.Minute)
+	if err == nil && claimed.ID == broadcast.ID {
+		t.Fatal("claimed broadcast is returned again before the lease ends")
+	}
+	if err != nil && !errors.Is(err, types.ErrNotFound) {
+		t.Fatal(err)
+	}
+
+	broadcast.LastUserID, broadcast.Sent, broadcast.Failed = 42, 10, 2
+	err = repository.UpdateProgress(context.Background(), broadcast, now.Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	finishedAt := time.Now()
+	err = repository.UpdateStatus(context.Background(), broadcast.ID, []string{"running"}, "done", &finishedAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	broadcast, err = repository.FindByID(context.Background(), broadcast.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if broadcast.Status != "done" || broadcast.LastUserID != 42 || broadcast.Sent != 10 || broadcast.Failed != 2 || broadcast.FinishedAt == nil {
+		t.Fatalf("unexpected state of the broadcast %+v", broadcast)
+	}
+}
@@ -0,0 +1,147 @@
+package db
+
+import (
+	"context"
+	"github.com/doug-martin/goqu/v9"
+	_ "github.com/doug-martin/goqu/v9/dialect/postgres"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"tg_todo_bot/src/models"
+	"tg_todo_bot/src/repositories/types"
+	"time"
+)
+
+type CalDAVObjectsRepository struct {
+	logger     *zap.SugaredLogger
+	dbInstance querier
+}
+
+func NewCalDAVObjectsRepository(
+	logger *zap.SugaredLogger,
+	dbInstance *pgxpool.Pool,
+	queryTimeout time.Duration,
+) *CalDAVObjectsRepository {
+	return &CalDAVObjectsRepository{
+		logger:     logger,
+		dbInstance: newTimeoutQuerier(dbInstance, queryTimeout),
+	}
+}
+
+// Save (ctx, object) -> creates the object or replaces the name and UID of the task's object
+func (repository *CalDAVObjectsRepository) Save(ctx context.Context, object models.CalDAVObject) error {
+	query := goqu.Dialect("postgres").
+		Insert("caldav_objects").
+		Rows(
+			goqu.Record{
+				"task_id": object.TaskID,
+				"user_id": object.UserID,
+				"name":    object.Name,
+				"uid":     object.UID,
+			},
+		).
+		OnConflict(
+			goqu.DoUpdate("task_id", goqu.Record{
+				"name": goqu.I("excluded.name"),
+				"uid":  goqu.I("excluded.uid"),
+			}),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	_, err := repository.dbInstance.Exec(ctx, sql, args...)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> CalDAVObjectsRepository -> Save -> repository.dbInstance.Exec(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return err
+	}
+
+	return nil
+}
+
+func (repository *CalDAVObjectsRepository) selectAllCols() *goqu.SelectDataset {
+	return goqu.Dialect("postgres").
+		From("caldav_objects").
+		Select(
+			goqu.C("task_id"),
+			goqu.C("user_id"),
+			goqu.C("name"),
+			goqu.C("uid"),
+		)
+}
+
+func (repository *CalDAVObjectsRepository) FindByName(ctx context.Context, userID int64, name string) (models.CalDAVObject, error) {
+	query := repository.selectAllCols().
+		Where(
+			goqu.C("user_id").Eq(userID),
+			goqu.C("name").Eq(name),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	row := repository.dbInstance.QueryRow(ctx, sql, args...)
+
+	var object models.CalDAVObject
+	err := row.Scan(
+		&object.TaskID,
+		&object.UserID,
+		&object.Name,
+		&object.UID,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = types.ErrNotFound
+		}
+		repository.logger.Debugw(
+			`Repositories -> DB -> CalDAVObjectsRepository -> FindByName -> row.Scan()`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return models.CalDAVObject{}, err
+	}
+
+	return object, nil
+}
+
+func (repository *CalDAVObjectsRepository) FindByTasksIDs(ctx context.Context, tasksIDs []int64) (map[int64]models.CalDAVObject, error) {
+	query := repository.selectAllCols().
+		Where(
+			goqu.C("task_id").In(tasksIDs),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	rows, err := repository.dbInstance.Query(ctx, sql, args...)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> CalDAVObjectsRepository -> FindByTasksIDs -> repository.dbInstance.Query(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return map[int64]models.CalDAVObject{}, err
+	}
+	defer rows.Close()
+
+	objects := map[int64]models.CalDAVObject{}
+	for rows.Next() {
+		var object models.CalDAVObject
+		err = rows.Scan(
+			&object.TaskID,
+			&object.UserID,
+			&object.Name,
+			&object.UID,
+		)
+		if err != nil {
+			repository.logger.Debugw(
+				`Repositories -> DB -> CalDAVObjectsRepository -> FindByTasksIDs -> rows.Scan()`,
+				"error", err.Error(),
+			)
+			return map[int64]models.CalDAVObject{}, err
+		}
+
+		objects[object.TaskID] = object
+	}
+
+	return objects, nil
+}
@@ -0,0 +1,82 @@
+package db
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"testing"
+	"tg_todo_bot/config"
+	"tg_todo_bot/kernel/db"
+	zap_logger "tg_todo_bot/kernel/logger"
+	"tg_todo_bot/src/models"
+	"tg_todo_bot/src/repositories/types"
+)
+
+func getCalDAVObjectsRepository() (*CalDAVObjectsRepository, error) {
+	logger := zap_logger.InitLogger()
+
+	conf, err := config.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	pg := db.NewPG(
+		conf.Database.Host,
+		conf.Database.Port,
+		conf.Database.Database,
+		conf.Database.User,
+		conf.Database.Password,
+	)
+	pgInstance, err := pg.OpenPool()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewCalDAVObjectsRepository(logger, pgInstance, conf.Database.QueryTimeout), nil
+}
+
+func TestSaveCalDAVObject(t *testing.T) {
+	repository, err := getCalDAVObjectsRepository()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	task, err := createTaskForTest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer deleteUserAfterTest(*task.User)
+
+	object := models.CalDAVObject{TaskID: task.ID, UserID: task.UserID, Name: "milk.ics", UID: "abc@client"}
+	err = repository.Save(context.Background(), object)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//Повторное сохранение меняет имя, а не создаёт второй объект
+	object.Name = "renamed.ics"
+	err = repository.Save(context.Background(), object)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := repository.FindByName(context.Background(), task.UserID, "renamed.ics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found != object {
+		t.Fatalf("got %+v, expected %+v", found, object)
+	}
+
+	_, err = repository.FindByName(context.Background(), task.UserID, "milk.ics")
+	if !errors.Is(err, types.ErrNotFound) {
+		t.Fatalf("got %v, expected types.ErrNotFound", err)
+	}
+
+	objects, err := repository.FindByTasksIDs(context.Background(), []int64{task.ID, task.ID + 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 1 || objects[task.ID] != object {
+		t.Fatalf("got %+v, expected only %+v", objects, object)
+	}
+}
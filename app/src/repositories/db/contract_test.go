@@ -0,0 +1,48 @@
+package db
+
+import (
+	"testing"
+	"tg_todo_bot/config"
+	"tg_todo_bot/kernel/db"
+	zap_logger "tg_todo_bot/kernel/logger"
+	"tg_todo_bot/src/repositories/contract"
+)
+
+func getContractRepositories(t *testing.T) contract.Repositories {
+	logger := zap_logger.InitLogger()
+
+	conf, err := config.GetConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pg := db.NewPG(
+		conf.Database.Host,
+		conf.Database.Port,
+		conf.Database.Database,
+		conf.Database.User,
+		conf.Database.Password,
+	)
+	pgInstance, err := pg.OpenPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return contract.Repositories{
+		Tasks:         NewTasksRepository(logger, pgInstance, conf.Database.QueryTimeout),
+		Notifications: NewNotificationsRepository(logger, pgInstance, conf.Database.QueryTimeout),
+		Users:         NewUsersRepository(logger, pgInstance, conf.Database.QueryTimeout),
+	}
+}
+
+func TestTasksRepositoryContract(t *testing.T) {
+	contract.TestTasksRepository(t, getContractRepositories(t))
+}
+
+func TestNotificationsRepositoryContract(t *testing.T) {
+	contract.TestNotificationsRepository(t, getContractRepositories(t))
+}
+
+func TestUsersRepositoryContract(t *testing.T) {
+	contract.TestUsersRepository(t, getContractRepositories(t))
+}
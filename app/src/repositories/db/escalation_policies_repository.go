@@ -0,0 +1,217 @@
+package db
+
+import (
+	"context"
+	"github.com/doug-martin/goqu/v9"
+	_ "github.com/doug-martin/goqu/v9/dialect/postgres"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"go.uber.org/zap"
+	"tg_todo_bot/src/models"
+	"time"
+)
+
+type EscalationPoliciesRepository struct {
+	logger     *zap.SugaredLogger
+	dbInstance querier
+}
+
+func NewEscalationPoliciesRepository(
+	logger *zap.SugaredLogger,
+	dbInstance *pgxpool.Pool,
+	queryTimeout time.Duration,
+) *EscalationPoliciesRepository {
+	return &EscalationPoliciesRepository{
+		logger:     logger,
+		dbInstance: newTimeoutQuerier(dbInstance, queryTimeout),
+	}
+}
+
+// Save (ctx, policy) -> replaces the user's default policy or the task's policy if TaskID is set
+func (repository *EscalationPoliciesRepository) Save(ctx context.Context, policy models.EscalationPolicy) (models.EscalationPolicy, error) {
+	var err error
+	if policy.TaskID != nil {
+		err = repository.DeleteForTask(ctx, *policy.TaskID)
+	} else {
+		err = repository.DeleteForUser(ctx, policy.UserID)
+	}
+	if err != nil {
+		return models.EscalationPolicy{}, err
+	}
+
+	now := time.Now()
+	query := goqu.Dialect("postgres").
+		Insert("escalation_policies").
+		Rows(
+			goqu.Record{
+				"user_id":         policy.UserID,
+				"task_id":         policy.TaskID,
+				"overdue_after":   policy.OverdueAfter,
+				"repeat_interval": policy.RepeatInterval,
+				"max_reminders":   policy.MaxReminders,
+				"created_at":      now,
+			},
+		).
+		Returning("id")
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	row := repository.dbInstance.QueryRow(ctx, sql, args...)
+
+	err = row.Scan(&policy.ID)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> EscalationPoliciesRepository -> Save -> row.Scan()`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return models.EscalationPolicy{}, err
+	}
+	policy.CreatedAt = now
+
+	return policy, nil
+}
+
+func (repository *EscalationPoliciesRepository) selectAllCols() *goqu.SelectDataset {
+	return goqu.Dialect("postgres").
+		From("escalation_policies").
+		Select(
+			goqu.C("id"),
+			goqu.C("user_id"),
+			goqu.C("task_id"),
+			goqu.C("overdue_after"),
+			goqu.C("repeat_interval"),
+			goqu.C("max_reminders"),
+			goqu.C("created_at"),
+		)
+}
+
+func (repository *EscalationPoliciesRepository) findAll(ctx context.Context, query *goqu.SelectDataset) ([]models.EscalationPolicy, error) {
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	rows, err := repository.dbInstance.Query(ctx, sql, args...)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> EscalationPoliciesRepository -> findAll -> repository.dbInstance.Query(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return []models.EscalationPolicy{}, err
+	}
+	defer rows.Close()
+
+	var policies []models.EscalationPolicy
+	for rows.Next() {
+		var policy models.EscalationPolicy
+		err = rows.Scan(
+			&policy.ID,
+			&policy.UserID,
+			&policy.TaskID,
+			&policy.OverdueAfter,
+			&policy.RepeatInterval,
+			&policy.MaxReminders,
+			&policy.CreatedAt,
+		)
+		if err != nil {
+			repository.logger.Debugw(
+				`Repositories -> DB -> EscalationPoliciesRepository -> findAll -> rows.Scan()`,
+				"error", err.Error(),
+			)
+			return []models.EscalationPolicy{}, err
+		}
+
+		policies = append(policies, policy)
+	}
+
+	return policies, nil
+}
+
+// FindForUsers (ctx, usersIDs) -> return map[UserID]DefaultPolicy
+func (repository *EscalationPoliciesRepository) FindForUsers(ctx context.Context, usersIDs []int64) (map[int64]models.EscalationPolicy, error) {
+	//Пустой IN () невалиден в PostgreSQL
+	if len(usersIDs) == 0 {
+		return map[int64]models.EscalationPolicy{}, nil
+	}
+
+	policies, err := repository.findAll(ctx, repository.selectAllCols().
+		Where(
+			goqu.C("user_id").In(usersIDs),
+			goqu.C("task_id").IsNull(),
+		),
+	)
+	if err != nil {
+		return map[int64]models.EscalationPolicy{}, err
+	}
+
+	usersPoliciesMap := map[int64]models.EscalationPolicy{}
+	for _, policy := range policies {
+		usersPoliciesMap[policy.UserID] = policy
+	}
+
+	return usersPoliciesMap, nil
+}
+
+// FindForTasks (ctx, tasksIDs) -> return map[TaskID]Policy, only for tasks with their own policy
+func (repository *EscalationPoliciesRepository) FindForTasks(ctx context.Context, tasksIDs []int64) (map[int64]models.EscalationPolicy, error) {
+	//Пустой IN () невалиден в PostgreSQL
+	if len(tasksIDs) == 0 {
+		return map[int64]models.EscalationPolicy{}, nil
+	}
+
+	policies, err := repository.findAll(ctx, repository.selectAllCols().
+		Where(
+			goqu.C("task_id").In(tasksIDs),
+		),
+	)
+	if err != nil {
+		return map[int64]models.EscalationPolicy{}, err
+	}
+
+	tasksPoliciesMap := map[int64]models.EscalationPolicy{}
+	for _, policy := range policies {
+		tasksPoliciesMap[*policy.TaskID] = policy
+	}
+
+	return tasksPoliciesMap, nil
+}
+
+// DeleteForUser (ctx, userID) -> deletes the user's default policy, policies of tasks stay
+func (repository *EscalationPoliciesRepository) DeleteForUser(ctx context.Context, userID int64) error {
+	query := goqu.Dialect("postgres").
+		Delete("escalation_policies").
+		Where(
+			goqu.C("user_id").Eq(userID),
+			goqu.C("task_id").IsNull(),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	_, err := repository.dbInstance.Exec(ctx, sql, args...)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> EscalationPoliciesRepository -> DeleteForUser -> repository.dbInstance.Exec(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return err
+	}
+
+	return nil
+}
+
+func (repository *EscalationPoliciesRepository) DeleteForTask(ctx context.Context, taskID int64) error {
+	query := goqu.Dialect("postgres").
+		Delete("escalation_policies").
+		Where(
+			goqu.C("task_id").Eq(taskID),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	_, err := repository.dbInstance.Exec(ctx, sql, args...)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> EscalationPoliciesRepository -> DeleteForTask -> repository.dbInstance.Exec(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"tg_todo_bot/config"
+	"tg_todo_bot/kernel/db"
+	zap_logger "tg_todo_bot/kernel/logger"
+	"tg_todo_bot/src/models"
+	"time"
+)
+
+func getEscalationPoliciesRepository() (*EscalationPoliciesRepository, error) {
+	logger := zap_logger.InitLogger()
+
+	conf, err := config.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	pg := db.NewPG(
+		conf.Database.Host,
+		conf.Database.Port,
+		conf.Database.Database,
+		conf.Database.User,
+		conf.Database.Password,
+	)
+	pgInstance, err := pg.OpenPool()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewEscalationPoliciesRepository(logger, pgInstance, conf.Database.QueryTimeout), nil
+}
+
+func TestSaveEscalationPolicy(t *testing.T) {
+	repository, err := getEscalationPoliciesRepository()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	task, err := createTaskForTest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer deleteTaskAfterTest(task)
+
+	userPolicy := models.EscalationPolicy{
+		UserID:         task.UserID,
+		OverdueAfter:   time.Hour,
+		RepeatInterval: 24 * time.Hour,
+		MaxReminders:   3,
+	}
+	_, err = repository.Save(context.Background(), userPolicy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//Повторное сохранение заменяет политику
+	userPolicy.MaxReminders = 5
+	_, err = repository.Save(context.Background(), userPolicy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	taskPolicy := userPolicy
+	taskPolicy.TaskID = &task.ID
+	taskPolicy.MaxReminders = 0
+	_, err = repository.Save(context.Background(), taskPolicy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	usersPolicies, err := repository.FindForUsers(context.Background(), []int64{task.UserID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usersPolicies[task.UserID].MaxReminders != 5 || usersPolicies[task.UserID].TaskID != nil {
+		t.Fatalf("wrong user policy %+v", usersPolicies[task.UserID])
+	}
+
+	tasksPolicies, err := repository.FindForTasks(context.Background(), []int64{task.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policy, exist := tasksPolicies[task.ID]; !exist || policy.MaxReminders != 0 {
+		t.Fatalf("wrong task policy %+v", policy)
+	}
+
+	err = repository.DeleteForTask(context.Background(), task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tasksPolicies, err = repository.FindForTasks(context.Background(), []int64{task.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasksPolicies) != 0 {
+		t.Fatal("task policy isn't deleted")
+	}
+}
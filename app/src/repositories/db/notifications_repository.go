@@ -16,20 +16,21 @@ import (
 
 type NotificationsRepository struct {
 	logger     *zap.SugaredLogger
-	dbInstance *pgxpool.Pool
+	dbInstance querier
 }
 
 func NewNotificationsRepository(
 	logger *zap.SugaredLogger,
 	dbInstance *pgxpool.Pool,
+	queryTimeout time.Duration,
 ) *NotificationsRepository {
 	return &NotificationsRepository{
 		logger:     logger,
-		dbInstance: dbInstance,
+		dbInstance: newTimeoutQuerier(dbInstance, queryTimeout),
 	}
 }
 
-func (repository *NotificationsRepository) Create(notification models.Notification) (models.Notification, error) {
+func (repository *NotificationsRepository) Create(ctx context.Context, notification models.Notification) (models.Notification, error) {
 	now := time.Now()
 	query := goqu.Dialect("postgres").
 		Insert("notifications").
@@ -45,7 +46,7 @@ func (repository *NotificationsRepository) Create(notification models.Notificati
 
 	sql, args, _ := query.Prepared(true).ToSQL()
 
-	row := repository.dbInstance.QueryRow(context.Background(), sql, args...)
+	row := repository.dbInstance.QueryRow(ctx, sql, args...)
 
 	err := row.Scan(&notification.ID)
 	if err != nil {
@@ -66,7 +67,7 @@ func (repository *NotificationsRepository) Create(notification models.Notificati
 	return notification, nil
 }
 
-func (repository *NotificationsRepository) Update(notification models.Notification) error {
+func (repository *NotificationsRepository) Update(ctx context.Context, notification models.Notification) error {
 	query := goqu.Dialect("postgres").
 		Update("notifications").
 		Set(
@@ -75,11 +76,14 @@ func (repository *NotificationsRepository) Update(notification models.Notificati
 				"notify_at":       notification.NotifyAt,
 				"repeat_interval": notification.RepeatInterval,
 			},
+		).
+		Where(
+			goqu.C("id").Eq(notification.ID),
 		)
 
 	sql, args, _ := query.Prepared(true).ToSQL()
 
-	_, err := repository.dbInstance.Exec(context.Background(), sql, args...)
+	_, err := repository.dbInstance.Exec(ctx, sql, args...)
 	if err != nil {
 		repository.logger.Debugw(
 			`Repositories -> DB -> NotificationsRepository -> Update -> repository.dbInstance.Exec(sql, args...)`,
@@ -91,7 +95,77 @@ func (repository *NotificationsRepository) Update(notification models.Notificati
 	return nil
 }
 
-func (repository *NotificationsRepository) DeleteByID(ID int64) error {
+// Fire (ctx, notification, nextNotifyAt, message) -> moves the notification to nextNotifyAt and saves the reminder
+// to the outbox in one transaction, so a reminder is neither lost nor sent twice if the process dies in between.
+// The notification is moved only from notification.NotifyAt: types.ErrNotFound if it's deleted, changed or
+// fired by another replica meanwhile
+func (repository *NotificationsRepository) Fire(ctx context.Context, notification models.Notification, nextNotifyAt time.Time, message models.OutboxMessage) error {
+	tx, err := repository.dbInstance.Begin(ctx)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> NotificationsRepository -> Fire -> repository.dbInstance.Begin()`,
+			"error", err.Error(),
+		)
+		return err
+	}
+	//После Commit откат ничего не делает
+	defer tx.Rollback(ctx)
+
+	query := goqu.Dialect("postgres").
+		Update("notifications").
+		Set(
+			goqu.Record{
+				"notify_at": nextNotifyAt,
+			},
+		).
+		Where(
+			goqu.C("id").Eq(notification.ID),
+			goqu.C("notify_at").Eq(notification.NotifyAt),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	result, err := tx.Exec(ctx, sql, args...)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> NotificationsRepository -> Fire -> tx.Exec(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return types.ErrNotFound
+	}
+
+	inserted, err := insertOutboxMessage(ctx, tx, message)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> NotificationsRepository -> Fire -> insertOutboxMessage(tx, message)`,
+			"error", err.Error(), "dedupKey", message.DedupKey,
+		)
+		return err
+	}
+	if !inserted {
+		//Такое срабатывание уже в outbox, напоминание всё равно переносится
+		repository.logger.Debugw(
+			`Repositories -> DB -> NotificationsRepository -> Fire -> the message is already in the outbox`,
+			"dedupKey", message.DedupKey,
+		)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> NotificationsRepository -> Fire -> tx.Commit()`,
+			"error", err.Error(),
+		)
+		return err
+	}
+
+	return nil
+}
+
+func (repository *NotificationsRepository) DeleteByID(ctx context.Context, ID int64) error {
 	query := goqu.Dialect("postgres").
 		Delete("notifications").
 		Where(
@@ -100,7 +174,7 @@ func (repository *NotificationsRepository) DeleteByID(ID int64) error {
 
 	sql, args, _ := query.Prepared(true).ToSQL()
 
-	_, err := repository.dbInstance.Exec(context.Background(), sql, args...)
+	_, err := repository.dbInstance.Exec(ctx, sql, args...)
 	if err != nil {
 		repository.logger.Debugw(
 			`Repositories -> DB -> NotificationsRepository -> DeleteByID -> repository.dbInstance.Exec(sql, args...)`,
@@ -124,7 +198,7 @@ func (repository *NotificationsRepository) selectAllCols() *goqu.SelectDataset {
 		)
 }
 
-func (repository *NotificationsRepository) FindByTasksIDs(tasksIds []int64) (map[int64]models.Notification, error) {
+func (repository *NotificationsRepository) FindByTasksIDs(ctx context.Context, tasksIds []int64) (map[int64]models.Notification, error) {
 	query := repository.selectAllCols().
 		Where(
 			goqu.C("task_id").In(tasksIds),
@@ -132,7 +206,7 @@ func (repository *NotificationsRepository) FindByTasksIDs(tasksIds []int64) (map
 
 	sql, args, _ := query.Prepared(true).ToSQL()
 
-	rows, err := repository.dbInstance.Query(context.Background(), sql, args...)
+	rows, err := repository.dbInstance.Query(ctx, sql, args...)
 	if err != nil {
 		repository.logger.Debugw(
 			`Repositories -> DB -> NotificationsRepository -> FindByTasksIDs -> repository.dbInstance.Query(sql, args...)`,
@@ -169,10 +243,32 @@ func (repository *NotificationsRepository) FindByTasksIDs(tasksIds []int64) (map
 	return tasksNotificationsMap, nil
 }
 
-func (repository *NotificationsRepository) GetUpcoming(upcomingTo time.Time) ([]models.Notification, error) {
+// GetUpcoming (ctx, upcomingTo) -> return notifications, except ones for tasks blocked by not completed tasks
+// and ones of users who blocked the bot
+func (repository *NotificationsRepository) GetUpcoming(ctx context.Context, upcomingTo time.Time) ([]models.Notification, error) {
+	activeBlockers := goqu.Dialect("postgres").
+		From(goqu.T("task_dependencies").As("d")).
+		InnerJoin(
+			goqu.T("tasks").As("t"),
+			goqu.On(goqu.I("t.id").Eq(goqu.I("d.blocked_by_task_id"))),
+		).
+		Select(goqu.L("1")).
+		Where(
+			goqu.I("d.task_id").Eq(goqu.I("notifications.task_id")),
+			goqu.I("t.done").IsFalse(),
+		)
+
 	query := repository.selectAllCols().
 		Where(
 			goqu.C("notify_at").Lte(upcomingTo),
+			goqu.L("NOT EXISTS ?", activeBlockers),
+			//Напоминания тем, кто заблокировал бота, не отправляются, пока он снова не напишет
+			goqu.C("task_id").NotIn(
+				goqu.Dialect("postgres").
+					From("tasks").
+					Select("id").
+					Where(goqu.C("user_id").In(botBlockedUsers())),
+			),
 		).
 		Order(
 			goqu.C("notify_at").Asc(),
@@ -180,7 +276,7 @@ func (repository *NotificationsRepository) GetUpcoming(upcomingTo time.Time) ([]
 
 	sql, args, _ := query.Prepared(true).ToSQL()
 
-	rows, err := repository.dbInstance.Query(context.Background(), sql, args...)
+	rows, err := repository.dbInstance.Query(ctx, sql, args...)
 	if err != nil {
 		repository.logger.Debugw(
 			`Repositories -> DB -> NotificationsRepository -> GetUpcoming -> repository.dbInstance.Query(sql, args...)`,
@@ -213,7 +309,7 @@ func (repository *NotificationsRepository) GetUpcoming(upcomingTo time.Time) ([]
 	return notifications, nil
 }
 
-func (repository *NotificationsRepository) FindByID(ID int64) (models.Notification, error) {
+func (repository *NotificationsRepository) FindByID(ctx context.Context, ID int64) (models.Notification, error) {
 	query := repository.selectAllCols().
 		Where(
 			goqu.C("id").Eq(ID),
@@ -221,7 +317,7 @@ func (repository *NotificationsRepository) FindByID(ID int64) (models.Notificati
 
 	sql, args, _ := query.Prepared(true).ToSQL()
 
-	row := repository.dbInstance.QueryRow(context.Background(), sql, args...)
+	row := repository.dbInstance.QueryRow(ctx, sql, args...)
 
 	var notification models.Notification
 	err := row.Scan(
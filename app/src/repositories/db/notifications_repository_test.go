@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"github.com/pkg/errors"
 	"testing"
 	"tg_todo_bot/config"
@@ -31,7 +32,7 @@ func getNotificationRepository() (*NotificationsRepository, error) {
 		return nil, err
 	}
 
-	notificationRepository := NewNotificationsRepository(logger, pgInstance)
+	notificationRepository := NewNotificationsRepository(logger, pgInstance, conf.Database.QueryTimeout)
 
 	return notificationRepository, nil
 }
@@ -62,7 +63,7 @@ func TestCreateNotification(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	notificationModel, err = repository.Create(notificationModel)
+	notificationModel, err = repository.Create(context.Background(), notificationModel)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -71,7 +72,7 @@ func TestCreateNotification(t *testing.T) {
 		t.Fatal("errors occurred during model creation")
 	}
 
-	err = repository.DeleteByID(notificationModel.ID)
+	err = repository.DeleteByID(context.Background(), notificationModel.ID)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -92,18 +93,18 @@ func TestUpdateNotification(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	notificationModel, err = repository.Create(notificationModel)
+	notificationModel, err = repository.Create(context.Background(), notificationModel)
 	if err != nil {
 		t.Fatal(err)
 	}
 
 	notificationModel.NotifyAt.Add(time.Hour)
-	err = repository.Update(notificationModel)
+	err = repository.Update(context.Background(), notificationModel)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	findResult, err := repository.FindByID(notificationModel.ID)
+	findResult, err := repository.FindByID(context.Background(), notificationModel.ID)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -112,7 +113,7 @@ func TestUpdateNotification(t *testing.T) {
 		t.Fatal("models not equal")
 	}
 
-	err = repository.DeleteByID(notificationModel.ID)
+	err = repository.DeleteByID(context.Background(), notificationModel.ID)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -133,22 +134,22 @@ func TestDeleteNotificationByID(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	notificationModel, err = repository.Create(notificationModel)
+	notificationModel, err = repository.Create(context.Background(), notificationModel)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	_, err = repository.FindByID(notificationModel.ID)
+	_, err = repository.FindByID(context.Background(), notificationModel.ID)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	err = repository.DeleteByID(notificationModel.ID)
+	err = repository.DeleteByID(context.Background(), notificationModel.ID)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	_, err = repository.FindByID(notificationModel.ID)
+	_, err = repository.FindByID(context.Background(), notificationModel.ID)
 	if err != nil {
 		if !errors.Is(err, types.ErrNotFound) {
 			t.Fatal(err)
@@ -174,12 +175,12 @@ func TestGetTaskNotification(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	notificationModel, err = repository.Create(notificationModel)
+	notificationModel, err = repository.Create(context.Background(), notificationModel)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	tasksNotificationsMap, err := repository.FindByTasksIDs([]int64{notificationModel.TaskID})
+	tasksNotificationsMap, err := repository.FindByTasksIDs(context.Background(), []int64{notificationModel.TaskID})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -194,7 +195,7 @@ func TestGetTaskNotification(t *testing.T) {
 		t.Fatal("models not equal")
 	}
 
-	err = repository.DeleteByID(notificationModel.ID)
+	err = repository.DeleteByID(context.Background(), notificationModel.ID)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -215,12 +216,12 @@ func TestGetUpcomingNotifications(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	notificationModel, err = repository.Create(notificationModel)
+	notificationModel, err = repository.Create(context.Background(), notificationModel)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	upcomingNotifications, err := repository.GetUpcoming(time.Now())
+	upcomingNotifications, err := repository.GetUpcoming(context.Background(), time.Now())
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -236,7 +237,7 @@ func TestGetUpcomingNotifications(t *testing.T) {
 		t.Fatal("error in logic")
 	}
 
-	upcomingNotifications, err = repository.GetUpcoming(time.Now().Add(time.Hour * 2))
+	upcomingNotifications, err = repository.GetUpcoming(context.Background(), time.Now().Add(time.Hour*2))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -251,7 +252,7 @@ func TestGetUpcomingNotifications(t *testing.T) {
 		t.Fatal("model not found")
 	}
 
-	err = repository.DeleteByID(notificationModel.ID)
+	err = repository.DeleteByID(context.Background(), notificationModel.ID)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -272,7 +273,7 @@ func TestFindNotificationByID(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	_, err = repository.FindByID(notificationModel.ID)
+	_, err = repository.FindByID(context.Background(), notificationModel.ID)
 	if err != nil {
 		if !errors.Is(err, types.ErrNotFound) {
 			t.Fatal(err)
@@ -281,17 +282,17 @@ func TestFindNotificationByID(t *testing.T) {
 		t.Fatal("must return error")
 	}
 
-	notificationModel, err = repository.Create(notificationModel)
+	notificationModel, err = repository.Create(context.Background(), notificationModel)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	_, err = repository.FindByID(notificationModel.ID)
+	_, err = repository.FindByID(context.Background(), notificationModel.ID)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	err = repository.DeleteByID(notificationModel.ID)
+	err = repository.DeleteByID(context.Background(), notificationModel.ID)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -300,3 +301,56 @@ func TestFindNotificationByID(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestGetUpcomingNotificationsOfBotBlockedUser(t *testing.T) {
+	repository, err := getNotificationRepository()
+	if err != nil {
+		t.Fatal(err)
+	}
+	usersRepository, err := getUsersRepository()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	notificationModel, err := getNotificationModelForCreation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer deleteTaskAfterTest(*notificationModel.Task)
+
+	notificationModel, err = repository.Create(context.Background(), notificationModel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repository.DeleteByID(context.Background(), notificationModel.ID)
+
+	isUpcoming := func() bool {
+		upcomingNotifications, err := repository.GetUpcoming(context.Background(), time.Now().Add(time.Hour*2))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, notification := range upcomingNotifications {
+			if notification.ID == notificationModel.ID {
+				return true
+			}
+		}
+		return false
+	}
+
+	blockedAt := time.Now()
+	err = usersRepository.SetBotBlockedAt(context.Background(), notificationModel.Task.UserID, &blockedAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if isUpcoming() {
+		t.Fatal("the user blocked the bot, the notification must be paused")
+	}
+
+	err = usersRepository.SetBotBlockedAt(context.Background(), notificationModel.Task.UserID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isUpcoming() {
+		t.Fatal("the user unblocked the bot, the notification must be resumed")
+	}
+}
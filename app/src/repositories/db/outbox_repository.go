@@ -0,0 +1,172 @@
+package db
+
+import (
+	"context"
+	"github.com/doug-martin/goqu/v9"
+	_ "github.com/doug-martin/goqu/v9/dialect/postgres"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"go.uber.org/zap"
+	"sort"
+	"tg_todo_bot/src/models"
+	"time"
+)
+
+type OutboxRepository struct {
+	logger     *zap.SugaredLogger
+	dbInstance querier
+}
+
+func NewOutboxRepository(
+	logger *zap.SugaredLogger,
+	dbInstance *pgxpool.Pool,
+	queryTimeout time.Duration,
+) *OutboxRepository {
+	return &OutboxRepository{
+		logger:     logger,
+		dbInstance: newTimeoutQuerier(dbInstance, queryTimeout),
+	}
+}
+
+// insertOutboxMessage (ctx, tx, message) -> saves the message in the transaction of the change it's about,
+// false if a message with the same DedupKey is already saved
+func insertOutboxMessage(ctx context.Context, tx pgx.Tx, message models.OutboxMessage) (bool, error) {
+	query := goqu.Dialect("postgres").
+		Insert("outbox").
+		Rows(
+			goqu.Record{
+				"user_id":         message.UserID,
+				"kind":            message.Kind,
+				"dedup_key":       message.DedupKey,
+				"payload":         string(message.Payload),
+				"next_attempt_at": message.NextAttemptAt,
+				"created_at":      time.Now(),
+			},
+		).
+		OnConflict(goqu.DoNothing())
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	result, err := tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return false, err
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// Claim (ctx, now, lease, limit) -> due messages, the oldest first. Their next attempt is moved by lease,
+// so other replicas don't take them, and if the process dies before Update they are sent again after the lease
+func (repository *OutboxRepository) Claim(ctx context.Context, now time.Time, lease time.Duration, limit uint) ([]models.OutboxMessage, error) {
+	dueMessages := goqu.Dialect("postgres").
+		From("outbox").
+		Select("id").
+		Where(
+			goqu.C("next_attempt_at").Lte(now),
+		).
+		Order(
+			goqu.C("next_attempt_at").Asc(),
+			goqu.C("id").Asc(),
+		).
+		Limit(limit).
+		ForUpdate(exp.SkipLocked)
+
+	query := goqu.Dialect("postgres").
+		Update("outbox").
+		Set(
+			goqu.Record{
+				"next_attempt_at": now.Add(lease),
+			},
+		).
+		Where(
+			goqu.C("id").In(dueMessages),
+		).
+		Returning(
+			goqu.C("id"),
+			goqu.C("user_id"),
+			goqu.C("kind"),
+			goqu.C("dedup_key"),
+			goqu.C("payload"),
+			goqu.C("attempts"),
+			goqu.C("next_attempt_at"),
+			goqu.C("last_error"),
+			goqu.C("sent_at"),
+			goqu.C("created_at"),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	rows, err := repository.dbInstance.Query(ctx, sql, args...)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> OutboxRepository -> Claim -> repository.dbInstance.Query(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return []models.OutboxMessage{}, err
+	}
+	defer rows.Close()
+
+	var messages []models.OutboxMessage
+	for rows.Next() {
+		var message models.OutboxMessage
+		err = rows.Scan(
+			&message.ID,
+			&message.UserID,
+			&message.Kind,
+			&message.DedupKey,
+			&message.Payload,
+			&message.Attempts,
+			&message.NextAttemptAt,
+			&message.LastError,
+			&message.SentAt,
+			&message.CreatedAt,
+		)
+		if err != nil {
+			repository.logger.Debugw(
+				`Repositories -> DB -> OutboxRepository -> Claim -> rows.Scan()`,
+				"error", err.Error(),
+			)
+			return []models.OutboxMessage{}, err
+		}
+
+		messages = append(messages, message)
+	}
+
+	//RETURNING не сохраняет порядок подзапроса, а id растёт в порядке записи
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].ID < messages[j].ID
+	})
+
+	return messages, nil
+}
+
+// Update (ctx, message) -> saves the result of a send attempt
+func (repository *OutboxRepository) Update(ctx context.Context, message models.OutboxMessage) error {
+	query := goqu.Dialect("postgres").
+		Update("outbox").
+		Set(
+			goqu.Record{
+				"attempts":        message.Attempts,
+				"next_attempt_at": message.NextAttemptAt,
+				"last_error":      message.LastError,
+				"sent_at":         message.SentAt,
+			},
+		).
+		Where(
+			goqu.C("id").Eq(message.ID),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	_, err := repository.dbInstance.Exec(ctx, sql, args...)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> OutboxRepository -> Update -> repository.dbInstance.Exec(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,141 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"testing"
+	"tg_todo_bot/config"
+	"tg_todo_bot/kernel/db"
+	zap_logger "tg_todo_bot/kernel/logger"
+	"tg_todo_bot/src/models"
+	"tg_todo_bot/src/repositories/types"
+	"time"
+)
+
+func getOutboxRepository() (*OutboxRepository, error) {
+	logger := zap_logger.InitLogger()
+
+	conf, err := config.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	pg := db.NewPG(
+		conf.Database.Host,
+		conf.Database.Port,
+		conf.Database.Database,
+		conf.Database.User,
+		conf.Database.Password,
+	)
+	pgInstance, err := pg.OpenPool()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewOutboxRepository(logger, pgInstance, conf.Database.QueryTimeout), nil
+}
+
+func TestFireNotificationToOutbox(t *testing.T) {
+	notificationsRepository, err := getNotificationRepository()
+	if err != nil {
+		t.Fatal(err)
+	}
+	repository, err := getOutboxRepository()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	notificationModel, err := getNotificationModelForCreation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer deleteTaskAfterTest(*notificationModel.Task)
+
+	notificationModel, err = notificationsRepository.Create(context.Background(), notificationModel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	//Время из базы, Fire сравнивает его с точностью до микросекунд
+	notification, err := notificationsRepository.FindByID(context.Background(), notificationModel.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	message := models.OutboxMessage{
+		UserID:        notificationModel.Task.UserID,
+		Kind:          "reminder",
+		DedupKey:      fmt.Sprintf("test:%d:%d", notification.ID, notification.NotifyAt.UnixNano()),
+		Payload:       []byte(`{"task_id": 1}`),
+		NextAttemptAt: &now,
+	}
+	nextNotifyAt := notification.NotifyAt.Add(time.Hour)
+
+	err = notificationsRepository.Fire(context.Background(), notification, nextNotifyAt, message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//Вторая реплика с тем же срабатыванием ничего не меняет
+	err = notificationsRepository.Fire(context.Background(), notification, nextNotifyAt.Add(time.Hour), message)
+	if !errors.Is(err, types.ErrNotFound) {
+		t.Fatalf("the notification is already fired, expected ErrNotFound, got %v", err)
+	}
+	fired, err := notificationsRepository.FindByID(context.Background(), notification.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fired.NotifyAt.Equal(nextNotifyAt.Truncate(time.Microsecond)) {
+		t.Fatalf("expected the next notify at %s, got %s", nextNotifyAt, fired.NotifyAt)
+	}
+
+	//Если напоминание вернули назад, то же срабатывание не попадает в outbox второй раз
+	err = notificationsRepository.Update(context.Background(), notification)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = notificationsRepository.Fire(context.Background(), notification, nextNotifyAt, message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claimed := claimOutboxMessages(t, repository, now, message.DedupKey)
+	if len(claimed) != 1 {
+		t.Fatalf("expected one message, got %+v", claimed)
+	}
+	if claimed[0].UserID != message.UserID || claimed[0].Kind != message.Kind || claimed[0].Attempts != 0 {
+		t.Fatalf("unexpected message %+v", claimed[0])
+	}
+
+	if len(claimOutboxMessages(t, repository, now, message.DedupKey)) != 0 {
+		t.Fatal("the claimed message must not be claimed again until the lease expires")
+	}
+
+	sentAt := time.Now()
+	claimed[0].Attempts, claimed[0].SentAt, claimed[0].NextAttemptAt = 1, &sentAt, nil
+	err = repository.Update(context.Background(), claimed[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(claimOutboxMessages(t, repository, now.Add(time.Hour), message.DedupKey)) != 0 {
+		t.Fatal("the sent message must not be claimed")
+	}
+}
+
+// claimOutboxMessages -> claimed messages with the dedup key, other tests may have their messages in the outbox
+func claimOutboxMessages(t *testing.T, repository *OutboxRepository, now time.Time, dedupKey string) []models.OutboxMessage {
+	messages, err := repository.Claim(context.Background(), now, time.Minute, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var found []models.OutboxMessage
+	for _, message := range messages {
+		if message.DedupKey == dedupKey {
+			found = append(found, message)
+		}
+	}
+
+	return found
+}
@@ -0,0 +1,134 @@
+package db
+
+import (
+	"context"
+	"github.com/doug-martin/goqu/v9"
+	_ "github.com/doug-martin/goqu/v9/dialect/postgres"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"go.uber.org/zap"
+	"math"
+	"tg_todo_bot/src/repositories/types"
+	"time"
+)
+
+type RateLimitsRepository struct {
+	logger     *zap.SugaredLogger
+	dbInstance querier
+}
+
+func NewRateLimitsRepository(
+	logger *zap.SugaredLogger,
+	dbInstance *pgxpool.Pool,
+	queryTimeout time.Duration,
+) *RateLimitsRepository {
+	return &RateLimitsRepository{
+		logger:     logger,
+		dbInstance: newTimeoutQuerier(dbInstance, queryTimeout),
+	}
+}
+
+// Take (ctx, userID, bucket, limit) -> takes a token from the bucket of the user if there is a whole one,
+// returns whether it's taken and the tokens left. The row is locked for the transaction and the time is taken
+// from the database, so several replicas of the bot share the bucket
+func (repository *RateLimitsRepository) Take(ctx context.Context, userID int64, bucket string, limit types.RateLimit) (bool, float64, error) {
+	tx, err := repository.dbInstance.Begin(ctx)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> RateLimitsRepository -> Take -> repository.dbInstance.Begin()`,
+			"error", err.Error(),
+		)
+		return false, 0, err
+	}
+	//После Commit откат ничего не делает
+	defer tx.Rollback(ctx)
+
+	//Новое ведро создаётся полным
+	insertQuery := goqu.Dialect("postgres").
+		Insert("rate_limits").
+		Rows(
+			goqu.Record{
+				"user_id":    userID,
+				"bucket":     bucket,
+				"tokens":     limit.Capacity,
+				"updated_at": goqu.L("NOW()"),
+			},
+		).
+		OnConflict(goqu.DoNothing())
+
+	sql, args, _ := insertQuery.Prepared(true).ToSQL()
+
+	_, err = tx.Exec(ctx, sql, args...)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> RateLimitsRepository -> Take -> tx.Exec(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return false, 0, err
+	}
+
+	selectQuery := goqu.Dialect("postgres").
+		From("rate_limits").
+		Select(
+			goqu.C("tokens"),
+			goqu.L("EXTRACT(EPOCH FROM NOW() - updated_at)::DOUBLE PRECISION"),
+		).
+		Where(
+			goqu.C("user_id").Eq(userID),
+			goqu.C("bucket").Eq(bucket),
+		).
+		ForUpdate(exp.Wait)
+
+	sql, args, _ = selectQuery.Prepared(true).ToSQL()
+
+	var tokens, elapsedSeconds float64
+	err = tx.QueryRow(ctx, sql, args...).Scan(&tokens, &elapsedSeconds)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> RateLimitsRepository -> Take -> tx.QueryRow(sql, args...).Scan()`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return false, 0, err
+	}
+
+	tokens = math.Min(limit.Capacity, tokens+math.Max(0, elapsedSeconds)*limit.PerSecond)
+	taken := tokens >= 1
+	if taken {
+		tokens--
+	}
+
+	updateQuery := goqu.Dialect("postgres").
+		Update("rate_limits").
+		Set(
+			goqu.Record{
+				"tokens":     tokens,
+				"updated_at": goqu.L("NOW()"),
+			},
+		).
+		Where(
+			goqu.C("user_id").Eq(userID),
+			goqu.C("bucket").Eq(bucket),
+		)
+
+	sql, args, _ = updateQuery.Prepared(true).ToSQL()
+
+	_, err = tx.Exec(ctx, sql, args...)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> RateLimitsRepository -> Take -> tx.Exec(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return false, 0, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> RateLimitsRepository -> Take -> tx.Commit()`,
+			"error", err.Error(),
+		)
+		return false, 0, err
+	}
+
+	return taken, tokens, nil
+}
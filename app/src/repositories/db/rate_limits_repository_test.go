@@ -0,0 +1,75 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"tg_todo_bot/config"
+	"tg_todo_bot/kernel/db"
+	zap_logger "tg_todo_bot/kernel/logger"
+	"tg_todo_bot/src/repositories/types"
+)
+
+func getRateLimitsRepository() (*RateLimitsRepository, error) {
+	logger := zap_logger.InitLogger()
+
+	conf, err := config.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	pg := db.NewPG(
+		conf.Database.Host,
+		conf.Database.Port,
+		conf.Database.Database,
+		conf.Database.User,
+		conf.Database.Password,
+	)
+	pgInstance, err := pg.OpenPool()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewRateLimitsRepository(logger, pgInstance, conf.Database.QueryTimeout), nil
+}
+
+func TestTakeRateLimit(t *testing.T) {
+	repository, err := getRateLimitsRepository()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := createUserForTest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer deleteUserAfterTest(user)
+
+	//Пополнение настолько медленное, что за время теста не добавится ни одного токена
+	limit := types.RateLimit{Capacity: 2, PerSecond: 0.0001}
+
+	for i := 0; i < 2; i++ {
+		taken, _, err := repository.Take(context.Background(), user.ID, "test", limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !taken {
+			t.Fatalf("request %d: expected a token to be taken", i+1)
+		}
+	}
+
+	taken, tokens, err := repository.Take(context.Background(), user.ID, "test", limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if taken || tokens >= 1 {
+		t.Fatalf("expected the bucket to be empty, got taken %v, tokens %f", taken, tokens)
+	}
+
+	taken, _, err = repository.Take(context.Background(), user.ID, "other", limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !taken {
+		t.Fatal("expected buckets to be independent")
+	}
+}
@@ -0,0 +1,176 @@
+package db
+
+import (
+	"context"
+	"github.com/doug-martin/goqu/v9"
+	_ "github.com/doug-martin/goqu/v9/dialect/postgres"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"tg_todo_bot/src/models"
+	"tg_todo_bot/src/repositories/types"
+	"time"
+)
+
+type SavedFiltersRepository struct {
+	logger     *zap.SugaredLogger
+	dbInstance querier
+}
+
+func NewSavedFiltersRepository(
+	logger *zap.SugaredLogger,
+	dbInstance *pgxpool.Pool,
+	queryTimeout time.Duration,
+) *SavedFiltersRepository {
+	return &SavedFiltersRepository{
+		logger:     logger,
+		dbInstance: newTimeoutQuerier(dbInstance, queryTimeout),
+	}
+}
+
+// Save (ctx, filter) -> creates the filter or replaces the query of the user's filter with the same name
+func (repository *SavedFiltersRepository) Save(ctx context.Context, filter models.SavedFilter) (models.SavedFilter, error) {
+	now := time.Now()
+	query := goqu.Dialect("postgres").
+		Insert("saved_filters").
+		Rows(
+			goqu.Record{
+				"user_id":    filter.UserID,
+				"name":       filter.Name,
+				"query":      filter.Query,
+				"created_at": now,
+			},
+		).
+		OnConflict(
+			goqu.DoUpdate("user_id, name", goqu.Record{"query": goqu.I("excluded.query")}),
+		).
+		Returning("id", "created_at")
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	row := repository.dbInstance.QueryRow(ctx, sql, args...)
+
+	err := row.Scan(&filter.ID, &filter.CreatedAt)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> SavedFiltersRepository -> Save -> row.Scan()`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return models.SavedFilter{}, err
+	}
+
+	return filter, nil
+}
+
+func (repository *SavedFiltersRepository) selectAllCols() *goqu.SelectDataset {
+	return goqu.Dialect("postgres").
+		From("saved_filters").
+		Select(
+			goqu.C("id"),
+			goqu.C("user_id"),
+			goqu.C("name"),
+			goqu.C("query"),
+			goqu.C("created_at"),
+		)
+}
+
+func (repository *SavedFiltersRepository) FindByName(ctx context.Context, userID int64, name string) (models.SavedFilter, error) {
+	query := repository.selectAllCols().
+		Where(
+			goqu.C("user_id").Eq(userID),
+			goqu.C("name").Eq(name),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	row := repository.dbInstance.QueryRow(ctx, sql, args...)
+
+	var filter models.SavedFilter
+	err := row.Scan(
+		&filter.ID,
+		&filter.UserID,
+		&filter.Name,
+		&filter.Query,
+		&filter.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = types.ErrNotFound
+		}
+		repository.logger.Debugw(
+			`Repositories -> DB -> SavedFiltersRepository -> FindByName -> row.Scan()`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return models.SavedFilter{}, err
+	}
+
+	return filter, nil
+}
+
+func (repository *SavedFiltersRepository) GetAllForUser(ctx context.Context, userID int64) ([]models.SavedFilter, error) {
+	query := repository.selectAllCols().
+		Where(
+			goqu.C("user_id").Eq(userID),
+		).
+		Order(
+			goqu.C("name").Asc(),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	rows, err := repository.dbInstance.Query(ctx, sql, args...)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> SavedFiltersRepository -> GetAllForUser -> repository.dbInstance.Query(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return []models.SavedFilter{}, err
+	}
+	defer rows.Close()
+
+	var filters []models.SavedFilter
+	for rows.Next() {
+		var filter models.SavedFilter
+		err = rows.Scan(
+			&filter.ID,
+			&filter.UserID,
+			&filter.Name,
+			&filter.Query,
+			&filter.CreatedAt,
+		)
+		if err != nil {
+			repository.logger.Debugw(
+				`Repositories -> DB -> SavedFiltersRepository -> GetAllForUser -> rows.Scan()`,
+				"error", err.Error(),
+			)
+			return []models.SavedFilter{}, err
+		}
+
+		filters = append(filters, filter)
+	}
+
+	return filters, nil
+}
+
+func (repository *SavedFiltersRepository) DeleteByName(ctx context.Context, userID int64, name string) error {
+	query := goqu.Dialect("postgres").
+		Delete("saved_filters").
+		Where(
+			goqu.C("user_id").Eq(userID),
+			goqu.C("name").Eq(name),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	_, err := repository.dbInstance.Exec(ctx, sql, args...)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> SavedFiltersRepository -> DeleteByName -> repository.dbInstance.Exec(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return err
+	}
+
+	return nil
+}
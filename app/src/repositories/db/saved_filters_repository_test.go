@@ -0,0 +1,103 @@
+package db
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"testing"
+	"tg_todo_bot/config"
+	"tg_todo_bot/kernel/db"
+	zap_logger "tg_todo_bot/kernel/logger"
+	"tg_todo_bot/src/models"
+	"tg_todo_bot/src/repositories/types"
+)
+
+func getSavedFiltersRepository() (*SavedFiltersRepository, error) {
+	logger := zap_logger.InitLogger()
+
+	conf, err := config.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	pg := db.NewPG(
+		conf.Database.Host,
+		conf.Database.Port,
+		conf.Database.Database,
+		conf.Database.User,
+		conf.Database.Password,
+	)
+	pgInstance, err := pg.OpenPool()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewSavedFiltersRepository(logger, pgInstance, conf.Database.QueryTimeout), nil
+}
+
+func TestSaveFilter(t *testing.T) {
+	repository, err := getSavedFiltersRepository()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := createUserForTest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer deleteUserAfterTest(user)
+
+	filter, err := repository.Save(context.Background(), models.SavedFilter{UserID: user.ID, Name: "work", Query: "tag:work"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//Повторное сохранение заменяет запрос
+	_, err = repository.Save(context.Background(), models.SavedFilter{UserID: user.ID, Name: "work", Query: "tag:work prio:1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	foundFilter, err := repository.FindByName(context.Background(), user.ID, "work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if foundFilter.ID != filter.ID || foundFilter.Query != "tag:work prio:1" {
+		t.Fatalf("wrong filter %+v", foundFilter)
+	}
+
+	filters, err := repository.GetAllForUser(context.Background(), user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filters) != 1 {
+		t.Fatalf("expected 1 filter, got %d", len(filters))
+	}
+}
+
+func TestDeleteFilterByName(t *testing.T) {
+	repository, err := getSavedFiltersRepository()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := createUserForTest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer deleteUserAfterTest(user)
+
+	_, err = repository.Save(context.Background(), models.SavedFilter{UserID: user.ID, Name: "home", Query: "tag:home"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = repository.DeleteByName(context.Background(), user.ID, "home")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = repository.FindByName(context.Background(), user.ID, "home")
+	if !errors.Is(err, types.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
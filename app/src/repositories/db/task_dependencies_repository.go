@@ -0,0 +1,214 @@
+package db
+
+import (
+	"context"
+	"github.com/doug-martin/goqu/v9"
+	_ "github.com/doug-martin/goqu/v9/dialect/postgres"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"tg_todo_bot/src/models"
+	"tg_todo_bot/src/repositories/types"
+	"time"
+)
+
+type TaskDependenciesRepository struct {
+	logger     *zap.SugaredLogger
+	dbInstance querier
+}
+
+func NewTaskDependenciesRepository(
+	logger *zap.SugaredLogger,
+	dbInstance *pgxpool.Pool,
+	queryTimeout time.Duration,
+) *TaskDependenciesRepository {
+	return &TaskDependenciesRepository{
+		logger:     logger,
+		dbInstance: newTimeoutQuerier(dbInstance, queryTimeout),
+	}
+}
+
+func (repository *TaskDependenciesRepository) Create(ctx context.Context, dependency models.TaskDependency) (models.TaskDependency, error) {
+	now := time.Now()
+	query := goqu.Dialect("postgres").
+		Insert("task_dependencies").
+		Rows(
+			goqu.Record{
+				"task_id":            dependency.TaskID,
+				"blocked_by_task_id": dependency.BlockedByTaskID,
+				"created_at":         now,
+			},
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	_, err := repository.dbInstance.Exec(ctx, sql, args...)
+	if err != nil {
+		var pgError *pgconn.PgError
+		if errors.As(err, &pgError) {
+			//Нарушение уникальности
+			if pgError.Code == "23505" {
+				err = types.ErrAlreadyExist
+			}
+		}
+		repository.logger.Debugw(
+			`Repositories -> DB -> TaskDependenciesRepository -> Create -> repository.dbInstance.Exec(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return models.TaskDependency{}, err
+	}
+
+	dependency.CreatedAt = now
+
+	return dependency, nil
+}
+
+func (repository *TaskDependenciesRepository) Delete(ctx context.Context, taskID, blockedByTaskID int64) error {
+	query := goqu.Dialect("postgres").
+		Delete("task_dependencies").
+		Where(
+			goqu.C("task_id").Eq(taskID),
+			goqu.C("blocked_by_task_id").Eq(blockedByTaskID),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	_, err := repository.dbInstance.Exec(ctx, sql, args...)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> TaskDependenciesRepository -> Delete -> repository.dbInstance.Exec(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return err
+	}
+
+	return nil
+}
+
+// FindBlockersIDs (ctx, tasksIDs) -> return map[TaskID][]BlockedByTaskID including completed blockers
+func (repository *TaskDependenciesRepository) FindBlockersIDs(ctx context.Context, tasksIDs []int64) (map[int64][]int64, error) {
+	//Пустой IN () невалиден в PostgreSQL
+	if len(tasksIDs) == 0 {
+		return map[int64][]int64{}, nil
+	}
+
+	query := goqu.Dialect("postgres").
+		From("task_dependencies").
+		Select(
+			goqu.C("task_id"),
+			goqu.C("blocked_by_task_id"),
+		).
+		Where(
+			goqu.C("task_id").In(tasksIDs),
+		).
+		Order(
+			goqu.C("blocked_by_task_id").Asc(),
+		)
+
+	return repository.queryBlockersIDs(ctx, "FindBlockersIDs", query)
+}
+
+// FindActiveBlockersIDs (ctx, tasksIDs) -> return map[TaskID][]BlockedByTaskID only for not completed blockers
+func (repository *TaskDependenciesRepository) FindActiveBlockersIDs(ctx context.Context, tasksIDs []int64) (map[int64][]int64, error) {
+	//Пустой IN () невалиден в PostgreSQL
+	if len(tasksIDs) == 0 {
+		return map[int64][]int64{}, nil
+	}
+
+	query := goqu.Dialect("postgres").
+		From(goqu.T("task_dependencies").As("d")).
+		InnerJoin(
+			goqu.T("tasks").As("t"),
+			goqu.On(goqu.I("t.id").Eq(goqu.I("d.blocked_by_task_id"))),
+		).
+		Select(
+			goqu.I("d.task_id"),
+			goqu.I("d.blocked_by_task_id"),
+		).
+		Where(
+			goqu.I("d.task_id").In(tasksIDs),
+			goqu.I("t.done").IsFalse(),
+		).
+		Order(
+			goqu.I("d.blocked_by_task_id").Asc(),
+		)
+
+	return repository.queryBlockersIDs(ctx, "FindActiveBlockersIDs", query)
+}
+
+func (repository *TaskDependenciesRepository) queryBlockersIDs(ctx context.Context, method string, query *goqu.SelectDataset) (map[int64][]int64, error) {
+	blockersMap := map[int64][]int64{}
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	rows, err := repository.dbInstance.Query(ctx, sql, args...)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> TaskDependenciesRepository -> `+method+` -> repository.dbInstance.Query(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return map[int64][]int64{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var taskID, blockedByTaskID int64
+		err = rows.Scan(&taskID, &blockedByTaskID)
+		if err != nil {
+			repository.logger.Debugw(
+				`Repositories -> DB -> TaskDependenciesRepository -> `+method+` -> rows.Scan()`,
+				"error", err.Error(),
+			)
+			return map[int64][]int64{}, err
+		}
+
+		blockersMap[taskID] = append(blockersMap[taskID], blockedByTaskID)
+	}
+
+	return blockersMap, nil
+}
+
+// FindDependentTasksIDs (ctx, blockedByTaskID) -> return IDs of tasks blocked by the given one
+func (repository *TaskDependenciesRepository) FindDependentTasksIDs(ctx context.Context, blockedByTaskID int64) ([]int64, error) {
+	query := goqu.Dialect("postgres").
+		From("task_dependencies").
+		Select(
+			goqu.C("task_id"),
+		).
+		Where(
+			goqu.C("blocked_by_task_id").Eq(blockedByTaskID),
+		).
+		Order(
+			goqu.C("task_id").Asc(),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	rows, err := repository.dbInstance.Query(ctx, sql, args...)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> TaskDependenciesRepository -> FindDependentTasksIDs -> repository.dbInstance.Query(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return []int64{}, err
+	}
+	defer rows.Close()
+
+	var tasksIDs []int64
+	for rows.Next() {
+		var taskID int64
+		err = rows.Scan(&taskID)
+		if err != nil {
+			repository.logger.Debugw(
+				`Repositories -> DB -> TaskDependenciesRepository -> FindDependentTasksIDs -> rows.Scan()`,
+				"error", err.Error(),
+			)
+			return []int64{}, err
+		}
+
+		tasksIDs = append(tasksIDs, taskID)
+	}
+
+	return tasksIDs, nil
+}
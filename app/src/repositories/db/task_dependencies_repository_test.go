@@ -0,0 +1,187 @@
+package db
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"testing"
+	"tg_todo_bot/config"
+	"tg_todo_bot/kernel/db"
+	zap_logger "tg_todo_bot/kernel/logger"
+	"tg_todo_bot/src/models"
+	"tg_todo_bot/src/repositories/types"
+)
+
+func getTaskDependenciesRepository() (*TaskDependenciesRepository, error) {
+	logger := zap_logger.InitLogger()
+
+	conf, err := config.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	pg := db.NewPG(
+		conf.Database.Host,
+		conf.Database.Port,
+		conf.Database.Database,
+		conf.Database.User,
+		conf.Database.Password,
+	)
+	pgInstance, err := pg.OpenPool()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewTaskDependenciesRepository(logger, pgInstance, conf.Database.QueryTimeout), nil
+}
+
+// createTasksPairForTest creates two tasks of the same user
+func createTasksPairForTest() (models.Task, models.Task, error) {
+	tasksRepository, err := getTaskRepository()
+	if err != nil {
+		return models.Task{}, models.Task{}, err
+	}
+
+	first, err := createTaskForTest()
+	if err != nil {
+		return models.Task{}, models.Task{}, err
+	}
+
+	second := first
+	second.ID = 0
+	second.Title = "Blocking test task title"
+	second, err = tasksRepository.Create(context.Background(), second)
+	if err != nil {
+		return models.Task{}, models.Task{}, err
+	}
+
+	return first, second, nil
+}
+
+func TestCreateTaskDependency(t *testing.T) {
+	repository, err := getTaskDependenciesRepository()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	task, blocker, err := createTasksPairForTest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer deleteTaskAfterTest(task)
+
+	dependency := models.TaskDependency{
+		TaskID:          task.ID,
+		BlockedByTaskID: blocker.ID,
+	}
+	_, err = repository.Create(context.Background(), dependency)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = repository.Create(context.Background(), dependency)
+	if err == nil {
+		t.Fatal("dependency already exist but there are no errors")
+	} else {
+		if !errors.Is(err, types.ErrAlreadyExist) {
+			t.Fatal(err)
+		}
+	}
+
+	blockersMap, err := repository.FindBlockersIDs(context.Background(), []int64{task.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blockersMap[task.ID]) != 1 || blockersMap[task.ID][0] != blocker.ID {
+		t.Fatal("dependency not found")
+	}
+
+	dependentTasksIDs, err := repository.FindDependentTasksIDs(context.Background(), blocker.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dependentTasksIDs) != 1 || dependentTasksIDs[0] != task.ID {
+		t.Fatal("dependent task not found")
+	}
+}
+
+func TestFindActiveTaskBlockers(t *testing.T) {
+	repository, err := getTaskDependenciesRepository()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tasksRepository, err := getTaskRepository()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	task, blocker, err := createTasksPairForTest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer deleteTaskAfterTest(task)
+
+	_, err = repository.Create(context.Background(), models.TaskDependency{
+		TaskID:          task.ID,
+		BlockedByTaskID: blocker.ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	activeBlockersMap, err := repository.FindActiveBlockersIDs(context.Background(), []int64{task.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(activeBlockersMap[task.ID]) != 1 {
+		t.Fatal("active blocker not found")
+	}
+
+	blocker.Done = true
+	err = tasksRepository.Update(context.Background(), blocker)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	activeBlockersMap, err = repository.FindActiveBlockersIDs(context.Background(), []int64{task.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(activeBlockersMap[task.ID]) != 0 {
+		t.Fatal("completed task is still blocking")
+	}
+}
+
+func TestDeleteTaskDependency(t *testing.T) {
+	repository, err := getTaskDependenciesRepository()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	task, blocker, err := createTasksPairForTest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer deleteTaskAfterTest(task)
+
+	_, err = repository.Create(context.Background(), models.TaskDependency{
+		TaskID:          task.ID,
+		BlockedByTaskID: blocker.ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = repository.Delete(context.Background(), task.ID, blocker.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	blockersMap, err := repository.FindBlockersIDs(context.Background(), []int64{task.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blockersMap[task.ID]) != 0 {
+		t.Fatal("dependency still exists after deletion")
+	}
+}
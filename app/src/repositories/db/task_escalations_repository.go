@@ -0,0 +1,115 @@
+package db
+
+import (
+	"context"
+	"github.com/doug-martin/goqu/v9"
+	_ "github.com/doug-martin/goqu/v9/dialect/postgres"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"go.uber.org/zap"
+	"tg_todo_bot/src/models"
+	"time"
+)
+
+type TaskEscalationsRepository struct {
+	logger     *zap.SugaredLogger
+	dbInstance querier
+}
+
+func NewTaskEscalationsRepository(
+	logger *zap.SugaredLogger,
+	dbInstance *pgxpool.Pool,
+	queryTimeout time.Duration,
+) *TaskEscalationsRepository {
+	return &TaskEscalationsRepository{
+		logger:     logger,
+		dbInstance: newTimeoutQuerier(dbInstance, queryTimeout),
+	}
+}
+
+// Save (ctx, escalation) -> creates or replaces the last escalation of the task
+func (repository *TaskEscalationsRepository) Save(ctx context.Context, escalation models.TaskEscalation) error {
+	query := goqu.Dialect("postgres").
+		Insert("task_escalations").
+		Rows(
+			goqu.Record{
+				"task_id":      escalation.TaskID,
+				"deadline":     escalation.Deadline,
+				"level":        escalation.Level,
+				"escalated_at": escalation.EscalatedAt,
+			},
+		).
+		OnConflict(
+			goqu.DoUpdate("task_id", goqu.Record{
+				"deadline":     goqu.I("excluded.deadline"),
+				"level":        goqu.I("excluded.level"),
+				"escalated_at": goqu.I("excluded.escalated_at"),
+			}),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	_, err := repository.dbInstance.Exec(ctx, sql, args...)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> TaskEscalationsRepository -> Save -> repository.dbInstance.Exec(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return err
+	}
+
+	return nil
+}
+
+// FindByTasksIDs (ctx, tasksIDs) -> return map[TaskID]TaskEscalation
+func (repository *TaskEscalationsRepository) FindByTasksIDs(ctx context.Context, tasksIDs []int64) (map[int64]models.TaskEscalation, error) {
+	//Пустой IN () невалиден в PostgreSQL
+	if len(tasksIDs) == 0 {
+		return map[int64]models.TaskEscalation{}, nil
+	}
+
+	query := goqu.Dialect("postgres").
+		From("task_escalations").
+		Select(
+			goqu.C("task_id"),
+			goqu.C("deadline"),
+			goqu.C("level"),
+			goqu.C("escalated_at"),
+		).
+		Where(
+			goqu.C("task_id").In(tasksIDs),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	rows, err := repository.dbInstance.Query(ctx, sql, args...)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> TaskEscalationsRepository -> FindByTasksIDs -> repository.dbInstance.Query(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return map[int64]models.TaskEscalation{}, err
+	}
+	defer rows.Close()
+
+	escalationsMap := map[int64]models.TaskEscalation{}
+	for rows.Next() {
+		var escalation models.TaskEscalation
+		err = rows.Scan(
+			&escalation.TaskID,
+			&escalation.Deadline,
+			&escalation.Level,
+			&escalation.EscalatedAt,
+		)
+		if err != nil {
+			repository.logger.Debugw(
+				`Repositories -> DB -> TaskEscalationsRepository -> FindByTasksIDs -> rows.Scan()`,
+				"error", err.Error(),
+			)
+			return map[int64]models.TaskEscalation{}, err
+		}
+
+		escalationsMap[escalation.TaskID] = escalation
+	}
+
+	return escalationsMap, nil
+}
@@ -0,0 +1,72 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"tg_todo_bot/config"
+	"tg_todo_bot/kernel/db"
+	zap_logger "tg_todo_bot/kernel/logger"
+	"tg_todo_bot/src/models"
+	"time"
+)
+
+func getTaskEscalationsRepository() (*TaskEscalationsRepository, error) {
+	logger := zap_logger.InitLogger()
+
+	conf, err := config.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	pg := db.NewPG(
+		conf.Database.Host,
+		conf.Database.Port,
+		conf.Database.Database,
+		conf.Database.User,
+		conf.Database.Password,
+	)
+	pgInstance, err := pg.OpenPool()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewTaskEscalationsRepository(logger, pgInstance, conf.Database.QueryTimeout), nil
+}
+
+func TestSaveTaskEscalation(t *testing.T) {
+	repository, err := getTaskEscalationsRepository()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	task, err := createTaskForTest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer deleteTaskAfterTest(task)
+
+	escalation := models.TaskEscalation{
+		TaskID:      task.ID,
+		Deadline:    task.Datetime.Truncate(time.Microsecond),
+		Level:       1,
+		EscalatedAt: time.Now().Truncate(time.Microsecond),
+	}
+	err = repository.Save(context.Background(), escalation)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	escalation.Level = 2
+	err = repository.Save(context.Background(), escalation)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	escalations, err := repository.FindByTasksIDs(context.Background(), []int64{task.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if escalations[task.ID].Level != 2 {
+		t.Fatalf("wrong escalation %+v", escalations[task.ID])
+	}
+}
@@ -0,0 +1,131 @@
+package db
+
+import (
+	"context"
+	"github.com/doug-martin/goqu/v9"
+	_ "github.com/doug-martin/goqu/v9/dialect/postgres"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"go.uber.org/zap"
+	"time"
+)
+
+type TaskTagsRepository struct {
+	logger     *zap.SugaredLogger
+	dbInstance querier
+}
+
+func NewTaskTagsRepository(
+	logger *zap.SugaredLogger,
+	dbInstance *pgxpool.Pool,
+	queryTimeout time.Duration,
+) *TaskTagsRepository {
+	return &TaskTagsRepository{
+		logger:     logger,
+		dbInstance: newTimeoutQuerier(dbInstance, queryTimeout),
+	}
+}
+
+// SetForTask (ctx, taskID, tags) -> replaces all tags of the task
+func (repository *TaskTagsRepository) SetForTask(ctx context.Context, taskID int64, tags []string) error {
+	deleteQuery := goqu.Dialect("postgres").
+		Delete("task_tags").
+		Where(
+			goqu.C("task_id").Eq(taskID),
+		)
+	if len(tags) > 0 {
+		deleteQuery = deleteQuery.Where(
+			goqu.C("tag").NotIn(tags),
+		)
+	}
+
+	sql, args, _ := deleteQuery.Prepared(true).ToSQL()
+
+	_, err := repository.dbInstance.Exec(ctx, sql, args...)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> TaskTagsRepository -> SetForTask -> repository.dbInstance.Exec(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return err
+	}
+
+	if len(tags) == 0 {
+		return nil
+	}
+
+	var rows []interface{}
+	for _, tag := range tags {
+		rows = append(rows, goqu.Record{
+			"task_id": taskID,
+			"tag":     tag,
+		})
+	}
+	insertQuery := goqu.Dialect("postgres").
+		Insert("task_tags").
+		Rows(rows...).
+		OnConflict(goqu.DoNothing())
+
+	sql, args, _ = insertQuery.Prepared(true).ToSQL()
+
+	_, err = repository.dbInstance.Exec(ctx, sql, args...)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> TaskTagsRepository -> SetForTask -> repository.dbInstance.Exec(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return err
+	}
+
+	return nil
+}
+
+// FindByTasksIDs (ctx, tasksIDs) -> return map[TaskID][]Tag
+func (repository *TaskTagsRepository) FindByTasksIDs(ctx context.Context, tasksIDs []int64) (map[int64][]string, error) {
+	//Пустой IN () невалиден в PostgreSQL
+	if len(tasksIDs) == 0 {
+		return map[int64][]string{}, nil
+	}
+
+	query := goqu.Dialect("postgres").
+		From("task_tags").
+		Select(
+			goqu.C("task_id"),
+			goqu.C("tag"),
+		).
+		Where(
+			goqu.C("task_id").In(tasksIDs),
+		).
+		Order(
+			goqu.C("tag").Asc(),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	rows, err := repository.dbInstance.Query(ctx, sql, args...)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> TaskTagsRepository -> FindByTasksIDs -> repository.dbInstance.Query(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return map[int64][]string{}, err
+	}
+	defer rows.Close()
+
+	tasksTagsMap := map[int64][]string{}
+	for rows.Next() {
+		var taskID int64
+		var tag string
+		err = rows.Scan(&taskID, &tag)
+		if err != nil {
+			repository.logger.Debugw(
+				`Repositories -> DB -> TaskTagsRepository -> FindByTasksIDs -> rows.Scan()`,
+				"error", err.Error(),
+			)
+			return map[int64][]string{}, err
+		}
+
+		tasksTagsMap[taskID] = append(tasksTagsMap[taskID], tag)
+	}
+
+	return tasksTagsMap, nil
+}
@@ -0,0 +1,77 @@
+package db
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"tg_todo_bot/config"
+	"tg_todo_bot/kernel/db"
+	zap_logger "tg_todo_bot/kernel/logger"
+)
+
+func getTaskTagsRepository() (*TaskTagsRepository, error) {
+	logger := zap_logger.InitLogger()
+
+	conf, err := config.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	pg := db.NewPG(
+		conf.Database.Host,
+		conf.Database.Port,
+		conf.Database.Database,
+		conf.Database.User,
+		conf.Database.Password,
+	)
+	pgInstance, err := pg.OpenPool()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewTaskTagsRepository(logger, pgInstance, conf.Database.QueryTimeout), nil
+}
+
+func TestSetTagsForTask(t *testing.T) {
+	repository, err := getTaskTagsRepository()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	task, err := createTaskForTest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer deleteTaskAfterTest(task)
+
+	err = repository.SetForTask(context.Background(), task.ID, []string{"home", "work"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = repository.SetForTask(context.Background(), task.ID, []string{"work", "urgent"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tags, err := repository.FindByTasksIDs(context.Background(), []int64{task.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tags[task.ID], []string{"urgent", "work"}) {
+		t.Fatalf("wrong tags %v", tags[task.ID])
+	}
+
+	err = repository.SetForTask(context.Background(), task.ID, []string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tags, err = repository.FindByTasksIDs(context.Background(), []int64{task.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags[task.ID]) != 0 {
+		t.Fatal("tags aren't removed")
+	}
+}
@@ -5,30 +5,35 @@ import (
 	"fmt"
 	"github.com/doug-martin/goqu/v9"
 	_ "github.com/doug-martin/goqu/v9/dialect/postgres"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
 	"go.uber.org/zap"
+	"strings"
 	"tg_todo_bot/src/models"
+	"tg_todo_bot/src/repositories/types"
 	"time"
+	"unicode"
 )
 
-//TODO: Добавить метод поиска задач пользователя
-
 type TasksRepository struct {
 	logger     *zap.SugaredLogger
-	dbInstance *pgxpool.Pool
+	dbInstance querier
 }
 
 func NewTasksRepository(
 	logger *zap.SugaredLogger,
 	dbInstance *pgxpool.Pool,
+	queryTimeout time.Duration,
 ) *TasksRepository {
 	return &TasksRepository{
 		logger:     logger,
-		dbInstance: dbInstance,
+		dbInstance: newTimeoutQuerier(dbInstance, queryTimeout),
 	}
 }
 
-func (repository *TasksRepository) Create(task models.Task) (models.Task, error) {
+func (repository *TasksRepository) Create(ctx context.Context, task models.Task) (models.Task, error) {
 	now := time.Now()
 	query := goqu.Dialect("postgres").
 		Insert("tasks").
@@ -38,6 +43,7 @@ func (repository *TasksRepository) Create(task models.Task) (models.Task, error)
 				"description": task.Description,
 				"datetime":    task.Datetime,
 				"done":        task.Done,
+				"priority":    task.Priority,
 				"user_id":     task.UserID,
 				"created_at":  now,
 			},
@@ -46,7 +52,7 @@ func (repository *TasksRepository) Create(task models.Task) (models.Task, error)
 
 	sql, args, _ := query.Prepared(true).ToSQL()
 
-	row := repository.dbInstance.QueryRow(context.Background(), sql, args...)
+	row := repository.dbInstance.QueryRow(ctx, sql, args...)
 
 	err := row.Scan(&task.ID)
 	if err != nil {
@@ -60,6 +66,89 @@ func (repository *TasksRepository) Create(task models.Task) (models.Task, error)
 	return task, nil
 }
 
+// CreateMany (ctx, tasks) -> creates the tasks with their tags in one transaction, nothing is created if any insert fails
+func (repository *TasksRepository) CreateMany(ctx context.Context, tasks []models.Task) ([]models.Task, error) {
+	tx, err := repository.dbInstance.Begin(ctx)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> TasksRepository -> CreateMany -> repository.dbInstance.Begin()`,
+			"error", err.Error(),
+		)
+		return []models.Task{}, err
+	}
+	//После Commit откат ничего не делает
+	defer tx.Rollback(ctx)
+
+	now := time.Now()
+	created := make([]models.Task, 0, len(tasks))
+	for _, task := range tasks {
+		query := goqu.Dialect("postgres").
+			Insert("tasks").
+			Rows(
+				goqu.Record{
+					"title":       task.Title,
+					"description": task.Description,
+					"datetime":    task.Datetime,
+					"done":        task.Done,
+					"priority":    task.Priority,
+					"user_id":     task.UserID,
+					"created_at":  now,
+				},
+			).
+			Returning("id")
+
+		sql, args, _ := query.Prepared(true).ToSQL()
+
+		err = tx.QueryRow(ctx, sql, args...).Scan(&task.ID)
+		if err != nil {
+			repository.logger.Debugw(
+				`Repositories -> DB -> TasksRepository -> CreateMany -> row.Scan()`,
+				"error", err.Error(), "SQL", sql, "args", args,
+			)
+			return []models.Task{}, err
+		}
+		task.CreatedAt = now
+
+		if len(task.Tags) > 0 {
+			var rows []interface{}
+			for _, tag := range task.Tags {
+				rows = append(rows, goqu.Record{
+					"task_id": task.ID,
+					"tag":     tag,
+				})
+			}
+			tagsQuery := goqu.Dialect("postgres").
+				Insert("task_tags").
+				Rows(rows...).
+				OnConflict(goqu.DoNothing())
+
+			sql, args, _ = tagsQuery.Prepared(true).ToSQL()
+
+			_, err = tx.Exec(ctx, sql, args...)
+			if err != nil {
+				repository.logger.Debugw(
+					`Repositories -> DB -> TasksRepository -> CreateMany -> tx.Exec(sql, args...)`,
+					"error", err.Error(), "SQL", sql, "args", args,
+				)
+				return []models.Task{}, err
+			}
+		}
+
+		created = append(created, task)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> TasksRepository -> CreateMany -> tx.Commit()`,
+			"error", err.Error(),
+		)
+		return []models.Task{}, err
+	}
+
+	return created, nil
+}
+
 func (repository *TasksRepository) selectAllCols() *goqu.SelectDataset {
 	return goqu.Dialect("postgres").
 		From("tasks").
@@ -69,12 +158,13 @@ func (repository *TasksRepository) selectAllCols() *goqu.SelectDataset {
 			goqu.C("description"),
 			goqu.C("datetime"),
 			goqu.C("done"),
+			goqu.C("priority"),
 			goqu.C("user_id"),
 			goqu.C("created_at"),
 		)
 }
 
-func (repository *TasksRepository) SearchActiveByDatetimeForUser(from, to *time.Time, userID int64) ([]models.Task, error) {
+func (repository *TasksRepository) SearchActiveByDatetimeForUser(ctx context.Context, from, to *time.Time, userID int64) ([]models.Task, error) {
 	if from == nil && to == nil {
 		err := fmt.Errorf(`"from" and "to" are empty`)
 		repository.logger.Debugw(
@@ -107,7 +197,7 @@ func (repository *TasksRepository) SearchActiveByDatetimeForUser(from, to *time.
 
 	sql, args, _ := query.Prepared(true).ToSQL()
 
-	rows, err := repository.dbInstance.Query(context.Background(), sql, args...)
+	rows, err := repository.dbInstance.Query(ctx, sql, args...)
 	if err != nil {
 		repository.logger.Debugw(
 			`Repositories -> DB -> TasksRepository -> SearchByDeadline -> repository.dbInstance.Query(sql, args...)`,
@@ -126,6 +216,7 @@ func (repository *TasksRepository) SearchActiveByDatetimeForUser(from, to *time.
 			&task.Description,
 			&task.Datetime,
 			&task.Done,
+			&task.Priority,
 			&task.UserID,
 			&task.CreatedAt,
 		)
@@ -143,7 +234,58 @@ func (repository *TasksRepository) SearchActiveByDatetimeForUser(from, to *time.
 	return tasks, nil
 }
 
-func (repository *TasksRepository) GetAllActiveForUser(userID int64) ([]models.Task, error) {
+// GetPageForUser (ctx, userID, afterTaskID, limit) -> active and completed tasks with ID greater than afterTaskID, by ID
+func (repository *TasksRepository) GetPageForUser(ctx context.Context, userID, afterTaskID int64, limit uint) ([]models.Task, error) {
+	query := repository.selectAllCols().
+		Order(
+			goqu.C("id").Asc(),
+		).
+		Where(
+			goqu.C("user_id").Eq(userID),
+			goqu.C("id").Gt(afterTaskID),
+		).
+		Limit(limit)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	rows, err := repository.dbInstance.Query(ctx, sql, args...)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> TasksRepository -> GetPageForUser -> repository.dbInstance.Query(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return []models.Task{}, err
+	}
+	defer rows.Close()
+
+	var tasks []models.Task
+	for rows.Next() {
+		var task models.Task
+		err = rows.Scan(
+			&task.ID,
+			&task.Title,
+			&task.Description,
+			&task.Datetime,
+			&task.Done,
+			&task.Priority,
+			&task.UserID,
+			&task.CreatedAt,
+		)
+		if err != nil {
+			repository.logger.Debugw(
+				`Repositories -> DB -> TasksRepository -> GetPageForUser -> rows.Scan()`,
+				"error", err.Error(),
+			)
+			return []models.Task{}, err
+		}
+
+		tasks = append(tasks, task)
+	}
+
+	return tasks, nil
+}
+
+func (repository *TasksRepository) GetAllActiveForUser(ctx context.Context, userID int64) ([]models.Task, error) {
 	query := repository.selectAllCols().
 		Order(
 			goqu.C("datetime").Asc(),
@@ -156,7 +298,7 @@ func (repository *TasksRepository) GetAllActiveForUser(userID int64) ([]models.T
 
 	sql, args, _ := query.Prepared(true).ToSQL()
 
-	rows, err := repository.dbInstance.Query(context.Background(), sql, args...)
+	rows, err := repository.dbInstance.Query(ctx, sql, args...)
 	if err != nil {
 		repository.logger.Debugw(
 			`Repositories -> DB -> TasksRepository -> SearchByDeadline -> repository.dbInstance.Query(sql, args...)`,
@@ -175,6 +317,7 @@ func (repository *TasksRepository) GetAllActiveForUser(userID int64) ([]models.T
 			&task.Description,
 			&task.Datetime,
 			&task.Done,
+			&task.Priority,
 			&task.UserID,
 			&task.CreatedAt,
 		)
@@ -192,7 +335,7 @@ func (repository *TasksRepository) GetAllActiveForUser(userID int64) ([]models.T
 	return tasks, nil
 }
 
-func (repository *TasksRepository) Update(model models.Task) error {
+func (repository *TasksRepository) Update(ctx context.Context, model models.Task) error {
 	query := goqu.Dialect("postgres").
 		Update("tasks").
 		Set(
@@ -201,13 +344,17 @@ func (repository *TasksRepository) Update(model models.Task) error {
 				"description": model.Description,
 				"datetime":    model.Datetime,
 				"done":        model.Done,
+				"priority":    model.Priority,
 				"user_id":     model.UserID,
 			},
+		).
+		Where(
+			goqu.C("id").Eq(model.ID),
 		)
 
 	sql, args, _ := query.Prepared(true).ToSQL()
 
-	_, err := repository.dbInstance.Exec(context.Background(), sql, args...)
+	_, err := repository.dbInstance.Exec(ctx, sql, args...)
 	if err != nil {
 		repository.logger.Debugw(
 			`Repositories -> DB -> TasksRepository -> Update -> repository.dbInstance.Exec(sql, args...)`,
@@ -219,7 +366,7 @@ func (repository *TasksRepository) Update(model models.Task) error {
 	return nil
 }
 
-func (repository *TasksRepository) DeleteByID(ID int64) error {
+func (repository *TasksRepository) DeleteByID(ctx context.Context, ID int64) error {
 	query := goqu.Dialect("postgres").
 		Delete("tasks").
 		Where(
@@ -228,7 +375,7 @@ func (repository *TasksRepository) DeleteByID(ID int64) error {
 
 	sql, args, _ := query.Prepared(true).ToSQL()
 
-	_, err := repository.dbInstance.Exec(context.Background(), sql, args...)
+	_, err := repository.dbInstance.Exec(ctx, sql, args...)
 	if err != nil {
 		repository.logger.Debugw(
 			`Repositories -> DB -> TasksRepository -> deleteByID -> repository.dbInstance.Exec(sql, args...)`,
@@ -240,7 +387,7 @@ func (repository *TasksRepository) DeleteByID(ID int64) error {
 	return nil
 }
 
-func (repository *TasksRepository) DeleteCompleted() error {
+func (repository *TasksRepository) DeleteCompleted(ctx context.Context) error {
 	query := goqu.Dialect("postgres").
 		Delete("tasks").
 		Where(
@@ -249,7 +396,7 @@ func (repository *TasksRepository) DeleteCompleted() error {
 
 	sql, args, _ := query.Prepared(true).ToSQL()
 
-	_, err := repository.dbInstance.Exec(context.Background(), sql, args...)
+	_, err := repository.dbInstance.Exec(ctx, sql, args...)
 	if err != nil {
 		repository.logger.Debugw(
 			`Repositories -> DB -> TasksRepository -> DeleteCompleted -> repository.dbInstance.Exec(sql, args...)`,
@@ -261,15 +408,30 @@ func (repository *TasksRepository) DeleteCompleted() error {
 	return nil
 }
 
-func (repository *TasksRepository) FindByID(ID int64) (models.Task, error) {
+func (repository *TasksRepository) FindByID(ctx context.Context, ID int64) (models.Task, error) {
 	query := repository.selectAllCols().
 		Where(
 			goqu.C("id").Eq(ID),
 		)
 
+	return repository.findOne(ctx, "FindByID", query)
+}
+
+// FindByIDForUpdate (ctx, ID) -> the task locked with FOR UPDATE till the end of the transaction
+func (repository *TasksRepository) FindByIDForUpdate(ctx context.Context, ID int64) (models.Task, error) {
+	query := repository.selectAllCols().
+		Where(
+			goqu.C("id").Eq(ID),
+		).
+		ForUpdate(exp.Wait)
+
+	return repository.findOne(ctx, "FindByIDForUpdate", query)
+}
+
+func (repository *TasksRepository) findOne(ctx context.Context, method string, query *goqu.SelectDataset) (models.Task, error) {
 	sql, args, _ := query.Prepared(true).ToSQL()
 
-	row := repository.dbInstance.QueryRow(context.Background(), sql, args...)
+	row := repository.dbInstance.QueryRow(ctx, sql, args...)
 
 	var task models.Task
 	err := row.Scan(
@@ -278,12 +440,16 @@ func (repository *TasksRepository) FindByID(ID int64) (models.Task, error) {
 		&task.Description,
 		&task.Datetime,
 		&task.Done,
+		&task.Priority,
 		&task.UserID,
 		&task.CreatedAt,
 	)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = types.ErrNotFound
+		}
 		repository.logger.Debugw(
-			`Repositories -> DB -> TasksRepository -> FindByID -> row.Scan()`,
+			`Repositories -> DB -> TasksRepository -> `+method+` -> row.Scan()`,
 			"error", err.Error(), "SQL", sql, "args", args,
 		)
 		return models.Task{}, err
@@ -292,7 +458,7 @@ func (repository *TasksRepository) FindByID(ID int64) (models.Task, error) {
 	return task, nil
 }
 
-func (repository *TasksRepository) GetActiveTasksWithoutDatetimeForUser(userID int64) ([]models.Task, error) {
+func (repository *TasksRepository) GetActiveTasksWithoutDatetimeForUser(ctx context.Context, userID int64) ([]models.Task, error) {
 	query := repository.selectAllCols().
 		Where(
 			goqu.C("user_id").Eq(userID),
@@ -306,7 +472,7 @@ func (repository *TasksRepository) GetActiveTasksWithoutDatetimeForUser(userID i
 
 	sql, args, _ := query.Prepared(true).ToSQL()
 
-	rows, err := repository.dbInstance.Query(context.Background(), sql, args...)
+	rows, err := repository.dbInstance.Query(ctx, sql, args...)
 	if err != nil {
 		repository.logger.Debugw(
 			`Repositories -> DB -> TasksRepository -> FindByIDs -> repository.dbInstance.Query(sql, args...)`,
@@ -324,6 +490,7 @@ func (repository *TasksRepository) GetActiveTasksWithoutDatetimeForUser(userID i
 			&task.Description,
 			&task.Datetime,
 			&task.Done,
+			&task.Priority,
 			&task.UserID,
 			&task.CreatedAt,
 		)
@@ -340,3 +507,417 @@ func (repository *TasksRepository) GetActiveTasksWithoutDatetimeForUser(userID i
 
 	return tasks, nil
 }
+
+// prefixTsQuery ("купить мол") -> "купить:* & мол:*", only letters and digits are kept
+func prefixTsQuery(query string) string {
+	words := strings.FieldsFunc(query, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for i, word := range words {
+		words[i] = word + ":*"
+	}
+
+	return strings.Join(words, " & ")
+}
+
+// Search (ctx, userID, query, filters) -> full-text search over title and description, the most relevant first
+func (repository *TasksRepository) Search(ctx context.Context, userID int64, query string, filters types.TasksSearchFilters) ([]models.Task, error) {
+	tsQueryString := prefixTsQuery(query)
+	if tsQueryString == "" {
+		return []models.Task{}, nil
+	}
+
+	tsQuery := goqu.L(
+		"to_tsquery('russian', ?) || to_tsquery('english', ?)",
+		tsQueryString, tsQueryString,
+	)
+
+	selectQuery := repository.selectAllCols().
+		Where(
+			goqu.C("user_id").Eq(userID),
+			goqu.L("search_vector @@ (?)", tsQuery),
+		).
+		Order(
+			goqu.L("ts_rank(search_vector, ?)", tsQuery).Desc(),
+			goqu.C("id").Desc(),
+		)
+
+	if filters.Done != nil {
+		selectQuery = selectQuery.Where(
+			goqu.C("done").Eq(*filters.Done),
+		)
+	}
+	if filters.From != nil {
+		selectQuery = selectQuery.Where(
+			goqu.C("datetime").Gte(*filters.From),
+		)
+	}
+	if filters.To != nil {
+		selectQuery = selectQuery.Where(
+			goqu.C("datetime").Lte(*filters.To),
+		)
+	}
+	if filters.Limit > 0 {
+		selectQuery = selectQuery.Limit(filters.Limit)
+	}
+
+	sql, args, _ := selectQuery.Prepared(true).ToSQL()
+
+	rows, err := repository.dbInstance.Query(ctx, sql, args...)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> TasksRepository -> Search -> repository.dbInstance.Query(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return []models.Task{}, err
+	}
+	defer rows.Close()
+
+	var tasks []models.Task
+	for rows.Next() {
+		var task models.Task
+		err = rows.Scan(
+			&task.ID,
+			&task.Title,
+			&task.Description,
+			&task.Datetime,
+			&task.Done,
+			&task.Priority,
+			&task.UserID,
+			&task.CreatedAt,
+		)
+		if err != nil {
+			repository.logger.Debugw(
+				`Repositories -> DB -> TasksRepository -> Search -> rows.Scan()`,
+				"error", err.Error(),
+			)
+			return []models.Task{}, err
+		}
+
+		tasks = append(tasks, task)
+	}
+
+	return tasks, nil
+}
+
+// NULL в datetime сортируется после всех дат
+const tasksOrderDatetime = "COALESCE(datetime, 'infinity'::timestamptz)"
+
+// GetActiveForUserPage (ctx, userID, page) -> keyset pagination over active tasks in the (datetime, title, id) order.
+// Tasks are always returned in ascending order, for page.Before too.
+func (repository *TasksRepository) GetActiveForUserPage(ctx context.Context, userID int64, page types.TasksPageParams) ([]models.Task, error) {
+	query := repository.selectAllCols().
+		Where(
+			goqu.C("done").IsFalse(),
+			goqu.C("user_id").Eq(userID),
+		)
+
+	cursor, backward := page.After, false
+	if page.Before != nil {
+		cursor, backward = page.Before, true
+	}
+
+	if cursor != nil {
+		operator := ">"
+		if backward {
+			operator = "<"
+		}
+		query = query.Where(
+			goqu.L(
+				"("+tasksOrderDatetime+", title, id) "+operator+" (COALESCE(?::timestamptz, 'infinity'::timestamptz), ?, ?)",
+				cursor.Datetime, cursor.Title, cursor.ID,
+			),
+		)
+	}
+
+	if backward {
+		query = query.Order(
+			goqu.L(tasksOrderDatetime).Desc(),
+			goqu.C("title").Desc(),
+			goqu.C("id").Desc(),
+		)
+	} else {
+		query = query.Order(
+			goqu.L(tasksOrderDatetime).Asc(),
+			goqu.C("title").Asc(),
+			goqu.C("id").Asc(),
+		)
+	}
+
+	if page.Limit > 0 {
+		query = query.Limit(page.Limit)
+	}
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	rows, err := repository.dbInstance.Query(ctx, sql, args...)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> TasksRepository -> GetActiveForUserPage -> repository.dbInstance.Query(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return []models.Task{}, err
+	}
+	defer rows.Close()
+
+	var tasks []models.Task
+	for rows.Next() {
+		var task models.Task
+		err = rows.Scan(
+			&task.ID,
+			&task.Title,
+			&task.Description,
+			&task.Datetime,
+			&task.Done,
+			&task.Priority,
+			&task.UserID,
+			&task.CreatedAt,
+		)
+		if err != nil {
+			repository.logger.Debugw(
+				`Repositories -> DB -> TasksRepository -> GetActiveForUserPage -> rows.Scan()`,
+				"error", err.Error(),
+			)
+			return []models.Task{}, err
+		}
+
+		tasks = append(tasks, task)
+	}
+
+	if backward {
+		for i, j := 0, len(tasks)-1; i < j; i, j = i+1, j-1 {
+			tasks[i], tasks[j] = tasks[j], tasks[i]
+		}
+	}
+
+	return tasks, nil
+}
+
+func (repository *TasksRepository) tagExists(tags []string) exp.LiteralExpression {
+	tagsQuery := goqu.Dialect("postgres").
+		From("task_tags").
+		Select(goqu.L("1")).
+		Where(
+			goqu.I("task_tags.task_id").Eq(goqu.I("tasks.id")),
+			goqu.I("task_tags.tag").In(tags),
+		)
+
+	return goqu.L("EXISTS ?", tagsQuery)
+}
+
+func (repository *TasksRepository) filterQuery(userID int64, filter types.TasksFilter) *goqu.SelectDataset {
+	query := repository.selectAllCols().
+		Where(
+			goqu.C("user_id").Eq(userID),
+		)
+
+	if filter.Done != nil {
+		query = query.Where(goqu.C("done").Eq(*filter.Done))
+	}
+	if filter.DueFrom != nil {
+		query = query.Where(goqu.C("datetime").Gte(*filter.DueFrom))
+	}
+	if filter.DueTo != nil {
+		query = query.Where(goqu.C("datetime").Lte(*filter.DueTo))
+	}
+	if filter.WithoutDue {
+		query = query.Where(goqu.C("datetime").IsNull())
+	}
+	if filter.WithDue {
+		query = query.Where(goqu.C("datetime").IsNotNull())
+	}
+	for _, tag := range filter.Tags {
+		query = query.Where(repository.tagExists([]string{tag}))
+	}
+	if len(filter.ExcludedTags) > 0 {
+		query = query.Where(goqu.L("NOT ?", repository.tagExists(filter.ExcludedTags)))
+	}
+	if filter.PriorityFrom != nil {
+		query = query.Where(goqu.C("priority").Gte(*filter.PriorityFrom))
+	}
+	if filter.PriorityTo != nil {
+		query = query.Where(goqu.C("priority").Lte(*filter.PriorityTo))
+	}
+	if tsQueryString := prefixTsQuery(filter.Text); tsQueryString != "" {
+		query = query.Where(goqu.L(
+			"search_vector @@ (to_tsquery('russian', ?) || to_tsquery('english', ?))",
+			tsQueryString, tsQueryString,
+		))
+	}
+
+	var sortExpression exp.Orderable
+	switch filter.SortBy {
+	case types.TasksSortByCreated:
+		sortExpression = goqu.C("created_at")
+	case types.TasksSortByTitle:
+		sortExpression = goqu.C("title")
+	case types.TasksSortByPriority:
+		//Задачи без приоритета в конце
+		sortExpression = goqu.L("COALESCE(NULLIF(priority, 0), 32767)")
+	default:
+		sortExpression = goqu.L(tasksOrderDatetime)
+	}
+	if filter.SortDesc {
+		query = query.Order(sortExpression.Desc(), goqu.C("id").Desc())
+	} else {
+		query = query.Order(sortExpression.Asc(), goqu.C("id").Asc())
+	}
+
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	return query
+}
+
+// Filter (ctx, userID, filter) -> tasks matching all conditions of the filter
+func (repository *TasksRepository) Filter(ctx context.Context, userID int64, filter types.TasksFilter) ([]models.Task, error) {
+	query := repository.filterQuery(userID, filter)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	rows, err := repository.dbInstance.Query(ctx, sql, args...)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> TasksRepository -> Filter -> repository.dbInstance.Query(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return []models.Task{}, err
+	}
+	defer rows.Close()
+
+	var tasks []models.Task
+	for rows.Next() {
+		var task models.Task
+		err = rows.Scan(
+			&task.ID,
+			&task.Title,
+			&task.Description,
+			&task.Datetime,
+			&task.Done,
+			&task.Priority,
+			&task.UserID,
+			&task.CreatedAt,
+		)
+		if err != nil {
+			repository.logger.Debugw(
+				`Repositories -> DB -> TasksRepository -> Filter -> rows.Scan()`,
+				"error", err.Error(),
+			)
+			return []models.Task{}, err
+		}
+
+		tasks = append(tasks, task)
+	}
+
+	return tasks, nil
+}
+
+// Часовой пояс владельца задачи, UTC без настроек
+const tasksUserTimezone = "COALESCE((SELECT timezone FROM user_settings WHERE user_settings.user_id = tasks.user_id), 'UTC')"
+
+// Задачи без времени хранятся с 00:00 по времени пользователя и просрочены только после окончания дня
+const tasksDeadline = "CASE WHEN (datetime AT TIME ZONE " + tasksUserTimezone + ")::TIME = '00:00' " +
+	"THEN datetime + INTERVAL '1 day' ELSE datetime END"
+
+// GetOverdue (ctx, now, filters) -> not completed tasks with the deadline before now, the most overdue first
+func (repository *TasksRepository) GetOverdue(ctx context.Context, now time.Time, filters types.TasksOverdueFilters) ([]models.Task, error) {
+	query := repository.selectAllCols().
+		Where(
+			goqu.C("done").IsFalse(),
+			//Дедлайн не раньше datetime, условие нужно для индекса
+			goqu.C("datetime").Lt(now),
+			goqu.L(tasksDeadline+" < ?", now),
+		).
+		Order(
+			goqu.C("datetime").Asc(),
+			goqu.C("id").Asc(),
+		)
+
+	if filters.UserID != 0 {
+		query = query.Where(goqu.C("user_id").Eq(filters.UserID))
+	}
+	if filters.WithoutActiveBlockers {
+		activeBlockers := goqu.Dialect("postgres").
+			From(goqu.T("task_dependencies").As("d")).
+			InnerJoin(
+				goqu.T("tasks").As("t"),
+				goqu.On(goqu.I("t.id").Eq(goqu.I("d.blocked_by_task_id"))),
+			).
+			Select(goqu.L("1")).
+			Where(
+				goqu.I("d.task_id").Eq(goqu.I("tasks.id")),
+				goqu.I("t.done").IsFalse(),
+			)
+		query = query.Where(goqu.L("NOT EXISTS ?", activeBlockers))
+	}
+	if filters.WithoutBotBlocked {
+		query = query.Where(goqu.C("user_id").NotIn(botBlockedUsers()))
+	}
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	rows, err := repository.dbInstance.Query(ctx, sql, args...)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> TasksRepository -> GetOverdue -> repository.dbInstance.Query(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return []models.Task{}, err
+	}
+	defer rows.Close()
+
+	var tasks []models.Task
+	for rows.Next() {
+		var task models.Task
+		err = rows.Scan(
+			&task.ID,
+			&task.Title,
+			&task.Description,
+			&task.Datetime,
+			&task.Done,
+			&task.Priority,
+			&task.UserID,
+			&task.CreatedAt,
+		)
+		if err != nil {
+			repository.logger.Debugw(
+				`Repositories -> DB -> TasksRepository -> GetOverdue -> rows.Scan()`,
+				"error", err.Error(),
+			)
+			return []models.Task{}, err
+		}
+
+		tasks = append(tasks, task)
+	}
+
+	return tasks, nil
+}
+
+// CountForUser (ctx, userID) -> numbers of active and completed tasks of the user
+func (repository *TasksRepository) CountForUser(ctx context.Context, userID int64) (int64, int64, error) {
+	query := goqu.Dialect("postgres").
+		From("tasks").
+		Select(
+			goqu.L("COUNT(*) FILTER (WHERE done = false)"),
+			goqu.L("COUNT(*) FILTER (WHERE done = true)"),
+		).
+		Where(
+			goqu.C("user_id").Eq(userID),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	var active, completed int64
+	err := repository.dbInstance.QueryRow(ctx, sql, args...).Scan(&active, &completed)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> TasksRepository -> CountForUser -> row.Scan()`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return 0, 0, err
+	}
+
+	return active, completed, nil
+}
@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"github.com/pkg/errors"
 	"reflect"
 	"testing"
@@ -32,7 +33,7 @@ func getTaskRepository() (*TasksRepository, error) {
 		return nil, err
 	}
 
-	taskRepository := NewTasksRepository(logger, pgInstance)
+	taskRepository := NewTasksRepository(logger, pgInstance, conf.Database.QueryTimeout)
 
 	return taskRepository, nil
 }
@@ -65,7 +66,7 @@ func createTaskForTest() (models.Task, error) {
 		return models.Task{}, err
 	}
 
-	task, err := repository.Create(taskModel)
+	task, err := repository.Create(context.Background(), taskModel)
 	if err != nil {
 		return models.Task{}, err
 	}
@@ -79,7 +80,7 @@ func deleteTaskAfterTest(task models.Task) error {
 		return err
 	}
 
-	err = repository.DeleteByID(task.ID)
+	err = repository.DeleteByID(context.Background(), task.ID)
 	if err != nil {
 		return err
 	}
@@ -103,7 +104,7 @@ func TestCreateTask(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	taskModel, err = repository.Create(taskModel)
+	taskModel, err = repository.Create(context.Background(), taskModel)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -112,7 +113,7 @@ func TestCreateTask(t *testing.T) {
 		t.Fatal("task wasn't created but there are no errors")
 	}
 
-	err = repository.DeleteByID(taskModel.ID)
+	err = repository.DeleteByID(context.Background(), taskModel.ID)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -123,6 +124,60 @@ func TestCreateTask(t *testing.T) {
 	}
 }
 
+func TestCreateManyTasks(t *testing.T) {
+	repository, err := getTaskRepository()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tagsRepository, err := getTaskTagsRepository()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	taskModel, err := getTaskModelForCreation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer deleteUserAfterTest(*taskModel.User)
+
+	second := taskModel
+	second.Title = "Second imported task"
+	second.Datetime = nil
+	taskModel.Tags = []string{"import", "work"}
+
+	tasks, err := repository.CreateMany(context.Background(), []models.Task{taskModel, second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 2 || tasks[0].ID == 0 || tasks[1].ID == 0 {
+		t.Fatalf("got %+v, expected 2 created tasks", tasks)
+	}
+
+	tagsMap, err := tagsRepository.FindByTasksIDs(context.Background(), []int64{tasks[0].ID, tasks[1].ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tagsMap[tasks[0].ID]) != 2 || len(tagsMap[tasks[1].ID]) != 0 {
+		t.Fatalf("got tags %+v, expected [import work] for the first task only", tagsMap)
+	}
+
+	//Задача несуществующего пользователя откатывает весь импорт
+	broken := second
+	broken.UserID = -1
+	_, err = repository.CreateMany(context.Background(), []models.Task{second, broken})
+	if err == nil {
+		t.Fatal("expected error for the task of a missing user")
+	}
+
+	userTasks, err := repository.GetAllActiveForUser(context.Background(), taskModel.UserID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(userTasks) != 2 {
+		t.Fatalf("got %d tasks, expected 2, the failed import must be rolled back", len(userTasks))
+	}
+}
+
 func TestFindTaskByID(t *testing.T) {
 	repository, err := getTaskRepository()
 	if err != nil {
@@ -134,12 +189,12 @@ func TestFindTaskByID(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	taskModel, err = repository.Create(taskModel)
+	taskModel, err = repository.Create(context.Background(), taskModel)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	findByIdResult, err := repository.FindByID(taskModel.ID)
+	findByIdResult, err := repository.FindByID(context.Background(), taskModel.ID)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -149,7 +204,7 @@ func TestFindTaskByID(t *testing.T) {
 		t.Fatal("models not equal")
 	}
 
-	err = repository.DeleteByID(taskModel.ID)
+	err = repository.DeleteByID(context.Background(), taskModel.ID)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -171,16 +226,16 @@ func TestUpdateTask(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	taskModel, err = repository.Create(taskModel)
+	taskModel, err = repository.Create(context.Background(), taskModel)
 	if err != nil {
 		t.Fatal(err)
 	}
 
 	taskModel.Title = "Updated task title"
 	taskModel.Done = true
-	err = repository.Update(taskModel)
+	err = repository.Update(context.Background(), taskModel)
 
-	updatedTask, err := repository.FindByID(taskModel.ID)
+	updatedTask, err := repository.FindByID(context.Background(), taskModel.ID)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -190,7 +245,7 @@ func TestUpdateTask(t *testing.T) {
 		t.Fatal("models not equal")
 	}
 
-	err = repository.DeleteByID(taskModel.ID)
+	err = repository.DeleteByID(context.Background(), taskModel.ID)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -212,22 +267,22 @@ func TestDeleteTaskByID(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	taskModel, err = repository.Create(taskModel)
+	taskModel, err = repository.Create(context.Background(), taskModel)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	_, err = repository.FindByID(taskModel.ID)
+	_, err = repository.FindByID(context.Background(), taskModel.ID)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	err = repository.DeleteByID(taskModel.ID)
+	err = repository.DeleteByID(context.Background(), taskModel.ID)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	_, err = repository.FindByID(taskModel.ID)
+	_, err = repository.FindByID(context.Background(), taskModel.ID)
 	if err == nil {
 		t.Fatal("model still exists after delete operation")
 	} else {
@@ -253,21 +308,21 @@ func TestGetAllActiveTasks(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	allActive, err := repository.GetAllActiveForUser(taskModel.UserID)
+	allActive, err := repository.GetAllActiveForUser(context.Background(), taskModel.UserID)
 	allActiveBeforeCreationCount := len(allActive)
 
-	taskModel, err = repository.Create(taskModel)
+	taskModel, err = repository.Create(context.Background(), taskModel)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	allActive, err = repository.GetAllActiveForUser(taskModel.UserID)
+	allActive, err = repository.GetAllActiveForUser(context.Background(), taskModel.UserID)
 
 	if len(allActive) == allActiveBeforeCreationCount || len(allActive) == 0 {
 		t.Fatal("errors occurred during GetAllActive logic")
 	}
 
-	err = repository.DeleteByID(taskModel.ID)
+	err = repository.DeleteByID(context.Background(), taskModel.ID)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -289,7 +344,7 @@ func TestSearchActiveTasksByDatetime(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	taskModel, err = repository.Create(taskModel)
+	taskModel, err = repository.Create(context.Background(), taskModel)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -297,7 +352,7 @@ func TestSearchActiveTasksByDatetime(t *testing.T) {
 	from := taskModel.Datetime.Add(-time.Hour)
 	to := taskModel.Datetime.Add(time.Hour)
 
-	searchResult, err := repository.SearchActiveByDatetimeForUser(&from, nil, taskModel.UserID)
+	searchResult, err := repository.SearchActiveByDatetimeForUser(context.Background(), &from, nil, taskModel.UserID)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -317,7 +372,7 @@ func TestSearchActiveTasksByDatetime(t *testing.T) {
 		t.Fatal("model not found")
 	}
 
-	searchResult, err = repository.SearchActiveByDatetimeForUser(nil, &to, taskModel.UserID)
+	searchResult, err = repository.SearchActiveByDatetimeForUser(context.Background(), nil, &to, taskModel.UserID)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -325,7 +380,7 @@ func TestSearchActiveTasksByDatetime(t *testing.T) {
 		t.Fatal("model not found")
 	}
 
-	searchResult, err = repository.SearchActiveByDatetimeForUser(&from, &to, taskModel.UserID)
+	searchResult, err = repository.SearchActiveByDatetimeForUser(context.Background(), &from, &to, taskModel.UserID)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -333,12 +388,12 @@ func TestSearchActiveTasksByDatetime(t *testing.T) {
 		t.Fatal("model not found")
 	}
 
-	_, err = repository.SearchActiveByDatetimeForUser(nil, nil, taskModel.UserID)
+	_, err = repository.SearchActiveByDatetimeForUser(context.Background(), nil, nil, taskModel.UserID)
 	if err == nil {
 		t.Fatal("from and to is nil but there are no errors")
 	}
 
-	err = repository.DeleteByID(taskModel.ID)
+	err = repository.DeleteByID(context.Background(), taskModel.ID)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -360,35 +415,35 @@ func TestDeleteCompletedTasks(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	taskModel, err = repository.Create(taskModel)
+	taskModel, err = repository.Create(context.Background(), taskModel)
 	if err != nil {
 		t.Fatal(err)
 	}
 
 	taskModel.Done = true
 
-	err = repository.Update(taskModel)
+	err = repository.Update(context.Background(), taskModel)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	err = repository.DeleteCompleted()
+	err = repository.DeleteCompleted(context.Background())
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	_, err = repository.FindByID(taskModel.ID)
+	_, err = repository.FindByID(context.Background(), taskModel.ID)
 	if err == nil {
-		repository.DeleteByID(taskModel.ID)
+		repository.DeleteByID(context.Background(), taskModel.ID)
 		t.Fatal("model still exists after deletion")
 	} else {
 		if !errors.Is(err, types.ErrNotFound) {
-			repository.DeleteByID(taskModel.ID)
+			repository.DeleteByID(context.Background(), taskModel.ID)
 			t.Fatal(err)
 		}
 	}
 
-	err = repository.DeleteByID(taskModel.ID)
+	err = repository.DeleteByID(context.Background(), taskModel.ID)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -411,12 +466,12 @@ func TestGetActiveTasksWithoutDatetimeForUser(t *testing.T) {
 	}
 	taskModel.Datetime = nil
 
-	task, err := repository.Create(taskModel)
+	task, err := repository.Create(context.Background(), taskModel)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	tasksWithoutDatetime, err := repository.GetActiveTasksWithoutDatetimeForUser(task.UserID)
+	tasksWithoutDatetime, err := repository.GetActiveTasksWithoutDatetimeForUser(context.Background(), task.UserID)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -433,3 +488,307 @@ func TestGetActiveTasksWithoutDatetimeForUser(t *testing.T) {
 		t.Fatal("task not found")
 	}
 }
+
+func TestSearchTasks(t *testing.T) {
+	repository, err := getTaskRepository()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	taskModel, err := getTaskModelForCreation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	taskModel.Title = "Купить молоко"
+	taskModel.Description = "Buy oat milk for breakfast"
+
+	taskModel, err = repository.Create(context.Background(), taskModel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer deleteTaskAfterTest(taskModel)
+
+	for _, query := range []string{"молоко", "молок", "breakfast", "oat milk", "КУПИТЬ"} {
+		searchResult, err := repository.Search(context.Background(), taskModel.UserID, query, types.TasksSearchFilters{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(searchResult) != 1 || searchResult[0].ID != taskModel.ID {
+			t.Fatalf("task not found by query '%s'", query)
+		}
+	}
+
+	searchResult, err := repository.Search(context.Background(), taskModel.UserID, "хлеб", types.TasksSearchFilters{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(searchResult) != 0 {
+		t.Fatal("found task which doesn't match the query")
+	}
+
+	done := true
+	searchResult, err = repository.Search(context.Background(), taskModel.UserID, "молоко", types.TasksSearchFilters{Done: &done})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(searchResult) != 0 {
+		t.Fatal("done filter isn't applied")
+	}
+}
+
+func TestGetActiveTasksPage(t *testing.T) {
+	repository, err := getTaskRepository()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	taskModel, err := getTaskModelForCreation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer deleteUserAfterTest(*taskModel.User)
+
+	var created []models.Task
+	for i, title := range []string{"A", "B", "C"} {
+		task := taskModel
+		task.Title = title
+		if i == 2 {
+			task.Datetime = nil
+		}
+		task, err = repository.Create(context.Background(), task)
+		if err != nil {
+			t.Fatal(err)
+		}
+		created = append(created, task)
+	}
+
+	var pageIDs = func(tasks []models.Task) []int64 {
+		var IDs []int64
+		for _, task := range tasks {
+			IDs = append(IDs, task.ID)
+		}
+		return IDs
+	}
+
+	firstPage, err := repository.GetActiveForUserPage(context.Background(), taskModel.UserID, types.TasksPageParams{Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(pageIDs(firstPage), []int64{created[0].ID, created[1].ID}) {
+		t.Fatal("wrong first page")
+	}
+
+	last := firstPage[len(firstPage)-1]
+	secondPage, err := repository.GetActiveForUserPage(context.Background(), taskModel.UserID, types.TasksPageParams{
+		After: &types.TasksCursor{Datetime: last.Datetime, Title: last.Title, ID: last.ID},
+		Limit: 2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	//Задача без даты идёт последней
+	if !reflect.DeepEqual(pageIDs(secondPage), []int64{created[2].ID}) {
+		t.Fatal("wrong second page")
+	}
+
+	first := secondPage[0]
+	prevPage, err := repository.GetActiveForUserPage(context.Background(), taskModel.UserID, types.TasksPageParams{
+		Before: &types.TasksCursor{Datetime: first.Datetime, Title: first.Title, ID: first.ID},
+		Limit:  2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(pageIDs(prevPage), pageIDs(firstPage)) {
+		t.Fatal("wrong previous page")
+	}
+}
+
+func TestFilterTasks(t *testing.T) {
+	repository, err := getTaskRepository()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tagsRepository, err := getTaskTagsRepository()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	taskModel, err := getTaskModelForCreation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer deleteUserAfterTest(*taskModel.User)
+
+	work := taskModel
+	work.Title = "Отчёт"
+	work.Priority = 1
+	work, err = repository.Create(context.Background(), work)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tagsRepository.SetForTask(context.Background(), work.ID, []string{"work"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	home := taskModel
+	home.Title = "Полить цветы"
+	home.Datetime = nil
+	home, err = repository.Create(context.Background(), home)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tagsRepository.SetForTask(context.Background(), home.ID, []string{"home"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	one := 1
+	testCases := []struct {
+		filter   types.TasksFilter
+		expected []int64
+	}{
+		{filter: types.TasksFilter{}, expected: []int64{work.ID, home.ID}},
+		{filter: types.TasksFilter{Tags: []string{"work"}}, expected: []int64{work.ID}},
+		{filter: types.TasksFilter{ExcludedTags: []string{"work"}}, expected: []int64{home.ID}},
+		{filter: types.TasksFilter{WithoutDue: true}, expected: []int64{home.ID}},
+		{filter: types.TasksFilter{PriorityTo: &one, PriorityFrom: &one}, expected: []int64{work.ID}},
+		{filter: types.TasksFilter{Text: "цветы"}, expected: []int64{home.ID}},
+		{
+			filter:   types.TasksFilter{SortBy: types.TasksSortByTitle, SortDesc: true},
+			expected: []int64{home.ID, work.ID},
+		},
+	}
+
+	for _, testCase := range testCases {
+		tasks, err := repository.Filter(context.Background(), taskModel.UserID, testCase.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var IDs []int64
+		for _, task := range tasks {
+			IDs = append(IDs, task.ID)
+		}
+		if !reflect.DeepEqual(IDs, testCase.expected) {
+			t.Fatalf("filter %+v: got %v, expected %v", testCase.filter, IDs, testCase.expected)
+		}
+	}
+}
+
+func TestGetOverdueTasks(t *testing.T) {
+	repository, err := getTaskRepository()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	taskModel, err := getTaskModelForCreation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer deleteUserAfterTest(*taskModel.User)
+
+	now := time.Now()
+	hourAgo := now.Add(-time.Hour)
+	overdue := taskModel
+	overdue.Datetime = &hourAgo
+	overdue, err = repository.Create(context.Background(), overdue)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//Задача без времени на сегодня ещё не просрочена
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	withoutTime := taskModel
+	withoutTime.Datetime = &today
+	_, err = repository.Create(context.Background(), withoutTime)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = repository.Create(context.Background(), taskModel)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tasks, err := repository.GetOverdue(context.Background(), now, types.TasksOverdueFilters{UserID: taskModel.UserID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 1 || tasks[0].ID != overdue.ID {
+		t.Fatalf("expected only task %d, got %+v", overdue.ID, tasks)
+	}
+}
+
+func TestGetTasksPageForUser(t *testing.T) {
+	repository, err := getTaskRepository()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	taskModel, err := getTaskModelForCreation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer deleteUserAfterTest(*taskModel.User)
+
+	var created []models.Task
+	for i := 0; i < 3; i++ {
+		task := taskModel
+		task.Done = i == 0
+		task, err = repository.Create(context.Background(), task)
+		if err != nil {
+			t.Fatal(err)
+		}
+		created = append(created, task)
+	}
+
+	//Выполненные задачи тоже попадают в выгрузку
+	tasks, err := repository.GetPageForUser(context.Background(), taskModel.UserID, 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 2 || tasks[0].ID != created[0].ID || tasks[1].ID != created[1].ID {
+		t.Fatalf("expected tasks %d and %d, got %+v", created[0].ID, created[1].ID, tasks)
+	}
+
+	tasks, err = repository.GetPageForUser(context.Background(), taskModel.UserID, tasks[1].ID, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 1 || tasks[0].ID != created[2].ID {
+		t.Fatalf("expected only task %d, got %+v", created[2].ID, tasks)
+	}
+}
+
+func TestCountTasksForUser(t *testing.T) {
+	repository, err := getTaskRepository()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	taskModel, err := getTaskModelForCreation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer deleteUserAfterTest(*taskModel.User)
+
+	for _, done := range []bool{false, false, true} {
+		task := taskModel
+		task.Done = done
+		_, err = repository.Create(context.Background(), task)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	active, completed, err := repository.CountForUser(context.Background(), taskModel.UserID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if active != 2 || completed != 1 {
+		t.Fatalf("expected 2 active and 1 completed tasks, got %d and %d", active, completed)
+	}
+}
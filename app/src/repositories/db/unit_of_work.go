@@ -0,0 +1,207 @@
+package db
+
+import (
+	"context"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"tg_todo_bot/src/repositories/types"
+	"time"
+)
+
+// querier -> both *pgxpool.Pool and pgx.Tx, so a repository works the same in and out of a transaction.
+// Begin in a transaction starts a savepoint, so methods with their own transactions can run in a unit of work
+type querier interface {
+	Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// timeoutQuerier -> every statement gets its own deadline of timeout, an expired one is returned as
+// types.TimeoutError. Transactions started by it are wrapped the same way, 0 timeout means no deadline
+type timeoutQuerier struct {
+	querier querier
+	timeout time.Duration
+}
+
+func newTimeoutQuerier(querier querier, timeout time.Duration) timeoutQuerier {
+	return timeoutQuerier{querier: querier, timeout: timeout}
+}
+
+func (q timeoutQuerier) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if q.timeout <= 0 {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, q.timeout)
+}
+
+func (q timeoutQuerier) Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error) {
+	ctx, cancel := q.withTimeout(ctx)
+	defer cancel()
+
+	tag, err := q.querier.Exec(ctx, sql, arguments...)
+	return tag, queryError(err)
+}
+
+func (q timeoutQuerier) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	ctx, cancel := q.withTimeout(ctx)
+
+	rows, err := q.querier.Query(ctx, sql, args...)
+	if err != nil {
+		cancel()
+		return nil, queryError(err)
+	}
+
+	//Строки читаются после возврата, поэтому дедлайн снимается только при их закрытии
+	return &timeoutRows{Rows: rows, cancel: cancel}, nil
+}
+
+func (q timeoutQuerier) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	ctx, cancel := q.withTimeout(ctx)
+
+	return timeoutRow{row: q.querier.QueryRow(ctx, sql, args...), cancel: cancel}
+}
+
+func (q timeoutQuerier) Begin(ctx context.Context) (pgx.Tx, error) {
+	beginCtx, cancel := q.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := q.querier.Begin(beginCtx)
+	if err != nil {
+		return nil, queryError(err)
+	}
+
+	return &timeoutTx{Tx: tx, statements: newTimeoutQuerier(tx, q.timeout)}, nil
+}
+
+// timeoutTx -> statements and the commit of the transaction get deadlines like the ones of timeoutQuerier
+type timeoutTx struct {
+	pgx.Tx
+	statements timeoutQuerier
+}
+
+func (tx *timeoutTx) Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error) {
+	return tx.statements.Exec(ctx, sql, arguments...)
+}
+
+func (tx *timeoutTx) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return tx.statements.Query(ctx, sql, args...)
+}
+
+func (tx *timeoutTx) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	return tx.statements.QueryRow(ctx, sql, args...)
+}
+
+func (tx *timeoutTx) Begin(ctx context.Context) (pgx.Tx, error) {
+	return tx.statements.Begin(ctx)
+}
+
+func (tx *timeoutTx) Commit(ctx context.Context) error {
+	ctx, cancel := tx.statements.withTimeout(ctx)
+	defer cancel()
+
+	return queryError(tx.Tx.Commit(ctx))
+}
+
+type timeoutRows struct {
+	pgx.Rows
+	cancel context.CancelFunc
+}
+
+func (rows *timeoutRows) Next() bool {
+	if rows.Rows.Next() {
+		return true
+	}
+
+	//Строки закончились или чтение прервалось, соединение уже свободно
+	rows.cancel()
+	return false
+}
+
+func (rows *timeoutRows) Close() {
+	rows.Rows.Close()
+	rows.cancel()
+}
+
+func (rows *timeoutRows) Err() error {
+	return queryError(rows.Rows.Err())
+}
+
+type timeoutRow struct {
+	row    pgx.Row
+	cancel context.CancelFunc
+}
+
+func (row timeoutRow) Scan(dest ...interface{}) error {
+	defer row.cancel()
+
+	return queryError(row.row.Scan(dest...))
+}
+
+// queryError (err) -> types.TimeoutError if the deadline of the query expired, err as is otherwise
+func queryError(err error) error {
+	if err != nil && (pgconn.Timeout(err) || errors.Is(err, context.DeadlineExceeded)) {
+		return &types.TimeoutError{Err: err}
+	}
+
+	return err
+}
+
+// UnitOfWork runs calls of several repositories in one transaction
+type UnitOfWork struct {
+	logger     *zap.SugaredLogger
+	dbInstance timeoutQuerier
+}
+
+func NewUnitOfWork(
+	logger *zap.SugaredLogger,
+	dbInstance *pgxpool.Pool,
+	queryTimeout time.Duration,
+) *UnitOfWork {
+	return &UnitOfWork{
+		logger:     logger,
+		dbInstance: newTimeoutQuerier(dbInstance, queryTimeout),
+	}
+}
+
+// Do (ctx, fn) -> fn gets repositories bound to one transaction, it's committed if fn returns nil
+// and rolled back otherwise. The error of fn is returned as is
+func (unitOfWork *UnitOfWork) Do(ctx context.Context, fn func(repositories types.TxRepositories) error) error {
+	tx, err := unitOfWork.dbInstance.Begin(ctx)
+	if err != nil {
+		unitOfWork.logger.Debugw(
+			`Repositories -> DB -> UnitOfWork -> Do -> unitOfWork.dbInstance.Begin()`,
+			"error", err.Error(),
+		)
+		return err
+	}
+	//После Commit откат ничего не делает
+	defer tx.Rollback(ctx)
+
+	//Запросы в транзакции получают дедлайны от timeoutTx
+	err = fn(types.TxRepositories{
+		Tasks:         &TasksRepository{logger: unitOfWork.logger, dbInstance: tx},
+		TaskTags:      &TaskTagsRepository{logger: unitOfWork.logger, dbInstance: tx},
+		Notifications: &NotificationsRepository{logger: unitOfWork.logger, dbInstance: tx},
+		Users:         &UsersRepository{logger: unitOfWork.logger, dbInstance: tx},
+		CalDAVObjects: &CalDAVObjectsRepository{logger: unitOfWork.logger, dbInstance: tx},
+	})
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		unitOfWork.logger.Debugw(
+			`Repositories -> DB -> UnitOfWork -> Do -> tx.Commit()`,
+			"error", err.Error(),
+		)
+		return err
+	}
+
+	return nil
+}
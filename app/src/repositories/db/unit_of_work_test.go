@@ -0,0 +1,210 @@
+package db
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"testing"
+	"tg_todo_bot/config"
+	"tg_todo_bot/kernel/db"
+	zap_logger "tg_todo_bot/kernel/logger"
+	"tg_todo_bot/src/models"
+	"tg_todo_bot/src/repositories/types"
+	"time"
+)
+
+func getUnitOfWork() (*UnitOfWork, error) {
+	logger := zap_logger.InitLogger()
+
+	conf, err := config.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	pg := db.NewPG(
+		conf.Database.Host,
+		conf.Database.Port,
+		conf.Database.Database,
+		conf.Database.User,
+		conf.Database.Password,
+	)
+	pgInstance, err := pg.OpenPool()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewUnitOfWork(logger, pgInstance, conf.Database.QueryTimeout), nil
+}
+
+func TestUnitOfWorkRollback(t *testing.T) {
+	unitOfWork, err := getUnitOfWork()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tasksRepository, err := getTaskRepository()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := createUserForTest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer deleteUserAfterTest(user)
+
+	var task models.Task
+	errFailed := errors.New("the notification isn't saved")
+	err = unitOfWork.Do(context.Background(), func(repositories types.TxRepositories) error {
+		task, err = repositories.Tasks.Create(context.Background(), models.Task{Title: "Rolled back", UserID: user.ID})
+		if err != nil {
+			return err
+		}
+
+		//Задача видна внутри транзакции
+		_, err = repositories.Tasks.FindByID(context.Background(), task.ID)
+		if err != nil {
+			return err
+		}
+
+		return errFailed
+	})
+	if !errors.Is(err, errFailed) {
+		t.Fatalf("expected the error of fn, got %v", err)
+	}
+
+	_, err = tasksRepository.FindByID(context.Background(), task.ID)
+	if !errors.Is(err, types.ErrNotFound) {
+		t.Fatalf("the task must be rolled back, got %v", err)
+	}
+}
+
+func TestUnitOfWorkCommit(t *testing.T) {
+	unitOfWork, err := getUnitOfWork()
+	if err != nil {
+		t.Fatal(err)
+	}
+	notificationsRepository, err := getNotificationRepository()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := createUserForTest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer deleteUserAfterTest(user)
+
+	var task models.Task
+	err = unitOfWork.Do(context.Background(), func(repositories types.TxRepositories) error {
+		task, err = repositories.Tasks.Create(context.Background(), models.Task{Title: "Committed", UserID: user.ID})
+		if err != nil {
+			return err
+		}
+
+		err = repositories.TaskTags.SetForTask(context.Background(), task.ID, []string{"work"})
+		if err != nil {
+			return err
+		}
+
+		_, err = repositories.Notifications.Create(context.Background(), models.Notification{
+			TaskID:   task.ID,
+			NotifyAt: time.Now().Add(time.Hour),
+		})
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	notifications, err := notificationsRepository.FindByTasksIDs(context.Background(), []int64{task.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, exist := notifications[task.ID]; !exist {
+		t.Fatalf("the notification of the task %d must be committed", task.ID)
+	}
+}
+
+func TestUnitOfWorkLocksTask(t *testing.T) {
+	unitOfWork, err := getUnitOfWork()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	task, err := createTaskForTest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer deleteTaskAfterTest(task)
+
+	err = unitOfWork.Do(context.Background(), func(repositories types.TxRepositories) error {
+		_, err := repositories.Tasks.FindByIDForUpdate(context.Background(), task.ID)
+		if err != nil {
+			return err
+		}
+
+		//Вторая транзакция ждёт, пока первая не закончится
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		err = unitOfWork.Do(ctx, func(repositories types.TxRepositories) error {
+			_, err := repositories.Tasks.FindByIDForUpdate(ctx, task.ID)
+			return err
+		})
+		if err == nil {
+			t.Fatal("the task locked by another transaction must not be read for update")
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestQueryErrorMapsDeadlines(t *testing.T) {
+	err := queryError(errors.Wrap(context.DeadlineExceeded, "pgconn.Exec"))
+	if !errors.Is(err, types.ErrTimeout) {
+		t.Fatalf("got %v, expected types.ErrTimeout", err)
+	}
+
+	err = queryError(context.Canceled)
+	if errors.Is(err, types.ErrTimeout) {
+		t.Fatal("a cancelled query isn't a timeout")
+	}
+
+	if queryError(nil) != nil {
+		t.Fatal("no error must stay nil")
+	}
+}
+
+func TestTimeoutQuerier(t *testing.T) {
+	conf, err := config.GetConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pg := db.NewPG(
+		conf.Database.Host,
+		conf.Database.Port,
+		conf.Database.Database,
+		conf.Database.User,
+		conf.Database.Password,
+	)
+	pgInstance, err := pg.OpenPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pgInstance.Close()
+
+	querier := newTimeoutQuerier(pgInstance, 50*time.Millisecond)
+
+	_, err = querier.Exec(context.Background(), "SELECT pg_sleep(1)")
+	if !errors.Is(err, types.ErrTimeout) {
+		t.Fatalf("got %v, expected types.ErrTimeout", err)
+	}
+
+	var one int
+	err = querier.QueryRow(context.Background(), "SELECT 1").Scan(&one)
+	if err != nil || one != 1 {
+		t.Fatalf("got %d, %v, a fast query must pass", one, err)
+	}
+}
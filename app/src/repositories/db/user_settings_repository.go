@@ -0,0 +1,230 @@
+package db
+
+import (
+	"context"
+	"github.com/doug-martin/goqu/v9"
+	_ "github.com/doug-martin/goqu/v9/dialect/postgres"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"tg_todo_bot/src/models"
+	"tg_todo_bot/src/repositories/types"
+	"time"
+)
+
+type UserSettingsRepository struct {
+	logger     *zap.SugaredLogger
+	dbInstance querier
+}
+
+func NewUserSettingsRepository(
+	logger *zap.SugaredLogger,
+	dbInstance *pgxpool.Pool,
+	queryTimeout time.Duration,
+) *UserSettingsRepository {
+	return &UserSettingsRepository{
+		logger:     logger,
+		dbInstance: newTimeoutQuerier(dbInstance, queryTimeout),
+	}
+}
+
+// Save (ctx, settings) -> creates or replaces settings of the user, LastDigestOn isn't changed
+func (repository *UserSettingsRepository) Save(ctx context.Context, settings models.UserSettings) (models.UserSettings, error) {
+	now := time.Now()
+	record := goqu.Record{
+		"language":        settings.Language,
+		"timezone":        settings.Timezone,
+		"reminder_offset": settings.ReminderOffset,
+		"repeat_interval": settings.RepeatInterval,
+		"list_sort":       settings.ListSort,
+		"digest_time":     settings.DigestMinute,
+		"updated_at":      now,
+	}
+
+	insertRecord := goqu.Record{"user_id": settings.UserID}
+	updateRecord := goqu.Record{}
+	for column, value := range record {
+		insertRecord[column] = value
+		updateRecord[column] = goqu.I("excluded." + column)
+	}
+
+	query := goqu.Dialect("postgres").
+		Insert("user_settings").
+		Rows(insertRecord).
+		OnConflict(
+			goqu.DoUpdate("user_id", updateRecord),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	_, err := repository.dbInstance.Exec(ctx, sql, args...)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> UserSettingsRepository -> Save -> repository.dbInstance.Exec(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return models.UserSettings{}, err
+	}
+	settings.UpdatedAt = now
+
+	return settings, nil
+}
+
+func (repository *UserSettingsRepository) selectAllCols() *goqu.SelectDataset {
+	return goqu.Dialect("postgres").
+		From("user_settings").
+		Select(
+			goqu.C("user_id"),
+			goqu.C("language"),
+			goqu.C("timezone"),
+			goqu.C("reminder_offset"),
+			goqu.C("repeat_interval"),
+			goqu.C("list_sort"),
+			goqu.C("digest_time"),
+			goqu.C("last_digest_on"),
+			goqu.C("updated_at"),
+		)
+}
+
+func scanUserSettings(row pgx.Row) (models.UserSettings, error) {
+	var settings models.UserSettings
+	var digestMinute *int16
+
+	err := row.Scan(
+		&settings.UserID,
+		&settings.Language,
+		&settings.Timezone,
+		&settings.ReminderOffset,
+		&settings.RepeatInterval,
+		&settings.ListSort,
+		&digestMinute,
+		&settings.LastDigestOn,
+		&settings.UpdatedAt,
+	)
+	if err != nil {
+		return models.UserSettings{}, err
+	}
+
+	if digestMinute != nil {
+		minute := int(*digestMinute)
+		settings.DigestMinute = &minute
+	}
+
+	return settings, nil
+}
+
+func (repository *UserSettingsRepository) FindByUserID(ctx context.Context, userID int64) (models.UserSettings, error) {
+	query := repository.selectAllCols().
+		Where(
+			goqu.C("user_id").Eq(userID),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	row := repository.dbInstance.QueryRow(ctx, sql, args...)
+
+	settings, err := scanUserSettings(row)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = types.ErrNotFound
+		}
+		repository.logger.Debugw(
+			`Repositories -> DB -> UserSettingsRepository -> FindByUserID -> row.Scan()`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return models.UserSettings{}, err
+	}
+
+	return settings, nil
+}
+
+func (repository *UserSettingsRepository) findAll(ctx context.Context, query *goqu.SelectDataset) ([]models.UserSettings, error) {
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	rows, err := repository.dbInstance.Query(ctx, sql, args...)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> UserSettingsRepository -> findAll -> repository.dbInstance.Query(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return []models.UserSettings{}, err
+	}
+	defer rows.Close()
+
+	var settingsList []models.UserSettings
+	for rows.Next() {
+		settings, err := scanUserSettings(rows)
+		if err != nil {
+			repository.logger.Debugw(
+				`Repositories -> DB -> UserSettingsRepository -> findAll -> rows.Scan()`,
+				"error", err.Error(),
+			)
+			return []models.UserSettings{}, err
+		}
+
+		settingsList = append(settingsList, settings)
+	}
+
+	return settingsList, nil
+}
+
+// FindByUsersIDs (ctx, usersIDs) -> return map[UserID]UserSettings, users without settings are missed
+func (repository *UserSettingsRepository) FindByUsersIDs(ctx context.Context, usersIDs []int64) (map[int64]models.UserSettings, error) {
+	//Пустой IN () невалиден в PostgreSQL
+	if len(usersIDs) == 0 {
+		return map[int64]models.UserSettings{}, nil
+	}
+
+	settingsList, err := repository.findAll(ctx, repository.selectAllCols().
+		Where(
+			goqu.C("user_id").In(usersIDs),
+		),
+	)
+	if err != nil {
+		return map[int64]models.UserSettings{}, err
+	}
+
+	usersSettingsMap := map[int64]models.UserSettings{}
+	for _, settings := range settingsList {
+		usersSettingsMap[settings.UserID] = settings
+	}
+
+	return usersSettingsMap, nil
+}
+
+// GetWithDigest -> settings of users who turned the digest on and didn't block the bot
+func (repository *UserSettingsRepository) GetWithDigest(ctx context.Context) ([]models.UserSettings, error) {
+	return repository.findAll(ctx, repository.selectAllCols().
+		Where(
+			goqu.C("digest_time").IsNotNull(),
+			goqu.C("user_id").NotIn(botBlockedUsers()),
+		),
+	)
+}
+
+func (repository *UserSettingsRepository) SetLastDigestOn(ctx context.Context, userID int64, date time.Time) error {
+	query := goqu.Dialect("postgres").
+		Update("user_settings").
+		Set(
+			goqu.Record{
+				"last_digest_on": date.Format("2006-01-02"),
+			},
+		).
+		Where(
+			goqu.C("user_id").Eq(userID),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	_, err := repository.dbInstance.Exec(ctx, sql, args...)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> UserSettingsRepository -> SetLastDigestOn -> repository.dbInstance.Exec(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,140 @@
+package db
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"testing"
+	"tg_todo_bot/config"
+	"tg_todo_bot/kernel/db"
+	zap_logger "tg_todo_bot/kernel/logger"
+	"tg_todo_bot/src/models"
+	"tg_todo_bot/src/repositories/types"
+	"time"
+)
+
+func getUserSettingsRepository() (*UserSettingsRepository, error) {
+	logger := zap_logger.InitLogger()
+
+	conf, err := config.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	pg := db.NewPG(
+		conf.Database.Host,
+		conf.Database.Port,
+		conf.Database.Database,
+		conf.Database.User,
+		conf.Database.Password,
+	)
+	pgInstance, err := pg.OpenPool()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewUserSettingsRepository(logger, pgInstance, conf.Database.QueryTimeout), nil
+}
+
+func TestSaveUserSettings(t *testing.T) {
+	repository, err := getUserSettingsRepository()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := createUserForTest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer deleteUserAfterTest(user)
+
+	_, err = repository.FindByUserID(context.Background(), user.ID)
+	if !errors.Is(err, types.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	digestMinute := 9 * 60
+	settings := models.UserSettings{
+		UserID:         user.ID,
+		Language:       "en",
+		Timezone:       "Europe/Moscow",
+		ReminderOffset: 15 * time.Minute,
+		RepeatInterval: 30 * time.Minute,
+		ListSort:       "prio",
+		DigestMinute:   &digestMinute,
+	}
+	_, err = repository.Save(context.Background(), settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//Повторное сохранение обновляет настройки
+	settings.Timezone = "Asia/Novosibirsk"
+	_, err = repository.Save(context.Background(), settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	foundSettings, err := repository.FindByUserID(context.Background(), user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if foundSettings.Timezone != "Asia/Novosibirsk" || foundSettings.ReminderOffset != 15*time.Minute ||
+		foundSettings.DigestMinute == nil || *foundSettings.DigestMinute != digestMinute {
+		t.Fatalf("wrong settings %+v", foundSettings)
+	}
+
+	usersSettings, err := repository.FindByUsersIDs(context.Background(), []int64{user.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, exist := usersSettings[user.ID]; !exist {
+		t.Fatal("settings of the user not found")
+	}
+}
+
+func TestSetLastDigestOn(t *testing.T) {
+	repository, err := getUserSettingsRepository()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := createUserForTest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer deleteUserAfterTest(user)
+
+	digestMinute := 8 * 60
+	_, err = repository.Save(context.Background(), models.UserSettings{
+		UserID:         user.ID,
+		Language:       "ru",
+		Timezone:       "UTC",
+		RepeatInterval: time.Hour,
+		ListSort:       "due",
+		DigestMinute:   &digestMinute,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	date := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	err = repository.SetLastDigestOn(context.Background(), user.ID, date)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	settingsList, err := repository.GetWithDigest(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, settings := range settingsList {
+		if settings.UserID != user.ID {
+			continue
+		}
+		if settings.LastDigestOn == nil || !settings.LastDigestOn.Equal(time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)) {
+			t.Fatalf("wrong last digest date %v", settings.LastDigestOn)
+		}
+		return
+	}
+	t.Fatal("settings with digest not found")
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/doug-martin/goqu/v9"
 	_ "github.com/doug-martin/goqu/v9/dialect/postgres"
+	"github.com/doug-martin/goqu/v9/exp"
 	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
@@ -16,20 +17,21 @@ import (
 
 type UsersRepository struct {
 	logger     *zap.SugaredLogger
-	dbInstance *pgxpool.Pool
+	dbInstance querier
 }
 
 func NewUsersRepository(
 	logger *zap.SugaredLogger,
 	dbInstance *pgxpool.Pool,
+	queryTimeout time.Duration,
 ) *UsersRepository {
 	return &UsersRepository{
 		logger:     logger,
-		dbInstance: dbInstance,
+		dbInstance: newTimeoutQuerier(dbInstance, queryTimeout),
 	}
 }
 
-func (repository *UsersRepository) Create(user models.User) (models.User, error) {
+func (repository *UsersRepository) Create(ctx context.Context, user models.User) (models.User, error) {
 	now := time.Now()
 	query := goqu.Dialect("postgres").
 		Insert("users").
@@ -43,7 +45,7 @@ func (repository *UsersRepository) Create(user models.User) (models.User, error)
 
 	sql, args, _ := query.Prepared(true).ToSQL()
 
-	row := repository.dbInstance.QueryRow(context.Background(), sql, args...)
+	row := repository.dbInstance.QueryRow(ctx, sql, args...)
 	err := row.Scan(&user.ID)
 	if err != nil {
 		var pgError *pgconn.PgError
@@ -63,21 +65,30 @@ func (repository *UsersRepository) Create(user models.User) (models.User, error)
 	return user, nil
 }
 
-func (repository *UsersRepository) FindByTelegramID(telegramID int64) (models.User, error) {
-	query := goqu.Dialect("postgres").
+func (repository *UsersRepository) selectAllCols() *goqu.SelectDataset {
+	return goqu.Dialect("postgres").
 		From("users").
 		Select(
 			goqu.C("id"),
 			goqu.C("telegram_id"),
 			goqu.C("created_at"),
-		).
+			goqu.C("deletion_requested_at"),
+			goqu.C("last_seen_at"),
+			goqu.C("banned_at"),
+			goqu.C("ban_reason"),
+			goqu.C("bot_blocked_at"),
+		)
+}
+
+func (repository *UsersRepository) FindByTelegramID(ctx context.Context, telegramID int64) (models.User, error) {
+	query := repository.selectAllCols().
 		Where(
 			goqu.C("telegram_id").Eq(telegramID),
 		)
 
 	sql, args, _ := query.Prepared(true).ToSQL()
 
-	row := repository.dbInstance.QueryRow(context.Background(), sql, args...)
+	row := repository.dbInstance.QueryRow(ctx, sql, args...)
 
 	var user models.User
 
@@ -85,6 +96,11 @@ func (repository *UsersRepository) FindByTelegramID(telegramID int64) (models.Us
 		&user.ID,
 		&user.TelegramID,
 		&user.CreatedAt,
+		&user.DeletionRequestedAt,
+		&user.LastSeenAt,
+		&user.BannedAt,
+		&user.BanReason,
+		&user.BotBlockedAt,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -100,7 +116,43 @@ func (repository *UsersRepository) FindByTelegramID(telegramID int64) (models.Us
 	return user, nil
 }
 
-func (repository *UsersRepository) DeleteByTelegramID(telegramID int64) error {
+func (repository *UsersRepository) FindByID(ctx context.Context, ID int64) (models.User, error) {
+	query := repository.selectAllCols().
+		Where(
+			goqu.C("id").Eq(ID),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	row := repository.dbInstance.QueryRow(ctx, sql, args...)
+
+	var user models.User
+
+	err := row.Scan(
+		&user.ID,
+		&user.TelegramID,
+		&user.CreatedAt,
+		&user.DeletionRequestedAt,
+		&user.LastSeenAt,
+		&user.BannedAt,
+		&user.BanReason,
+		&user.BotBlockedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = types.ErrNotFound
+		}
+		repository.logger.Debugw(
+			`Repositories -> DB -> UsersRepository -> FindByID -> row.Scan()`,
+			"error", err.Error(),
+		)
+		return models.User{}, err
+	}
+
+	return user, nil
+}
+
+func (repository *UsersRepository) DeleteByTelegramID(ctx context.Context, telegramID int64) error {
 	query := goqu.Dialect("postgres").
 		Delete("users").
 		Where(
@@ -109,7 +161,7 @@ func (repository *UsersRepository) DeleteByTelegramID(telegramID int64) error {
 
 	sql, args, _ := query.Prepared(true).ToSQL()
 
-	_, err := repository.dbInstance.Exec(context.Background(), sql, args...)
+	_, err := repository.dbInstance.Exec(ctx, sql, args...)
 	if err != nil {
 		repository.logger.Debugw(
 			`Repositories -> DB -> UsersRepository -> DeleteByTelegramID -> repository.dbInstance.Exec(sql, args...)`,
@@ -120,3 +172,375 @@ func (repository *UsersRepository) DeleteByTelegramID(telegramID int64) error {
 
 	return nil
 }
+
+// SetDeletionRequestedAt (ctx, userID, requestedAt) -> nil requestedAt cancels the deletion, types.ErrNotFound if there is no user
+func (repository *UsersRepository) SetDeletionRequestedAt(ctx context.Context, userID int64, requestedAt *time.Time) error {
+	return repository.setTime(ctx, "SetDeletionRequestedAt", userID, "deletion_requested_at", requestedAt)
+}
+
+// GetDeletionRequestedBefore (ctx, requestedBefore) -> users who requested the deletion before the time, the oldest first
+func (repository *UsersRepository) GetDeletionRequestedBefore(ctx context.Context, requestedBefore time.Time) ([]models.User, error) {
+	query := repository.selectAllCols().
+		Where(
+			goqu.C("deletion_requested_at").Lte(requestedBefore),
+		).
+		Order(
+			goqu.C("deletion_requested_at").Asc(),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	rows, err := repository.dbInstance.Query(ctx, sql, args...)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> UsersRepository -> GetDeletionRequestedBefore -> repository.dbInstance.Query(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return []models.User{}, err
+	}
+	defer rows.Close()
+
+	var users []models.User
+	for rows.Next() {
+		var user models.User
+		err = rows.Scan(
+			&user.ID,
+			&user.TelegramID,
+			&user.CreatedAt,
+			&user.DeletionRequestedAt,
+			&user.LastSeenAt,
+			&user.BannedAt,
+			&user.BanReason,
+			&user.BotBlockedAt,
+		)
+		if err != nil {
+			repository.logger.Debugw(
+				`Repositories -> DB -> UsersRepository -> GetDeletionRequestedBefore -> rows.Scan()`,
+				"error", err.Error(),
+			)
+			return []models.User{}, err
+		}
+		users = append(users, user)
+	}
+
+	return users, nil
+}
+
+// SetLastSeenAt (ctx, userID, seenAt) -> types.ErrNotFound if there is no user
+func (repository *UsersRepository) SetLastSeenAt(ctx context.Context, userID int64, seenAt time.Time) error {
+	return repository.setTime(ctx, "SetLastSeenAt", userID, "last_seen_at", &seenAt)
+}
+
+// SetBotBlockedAt (ctx, userID, blockedAt) -> nil blockedAt makes the user reachable again,
+// types.ErrNotFound if there is no user
+func (repository *UsersRepository) SetBotBlockedAt(ctx context.Context, userID int64, blockedAt *time.Time) error {
+	return repository.setTime(ctx, "SetBotBlockedAt", userID, "bot_blocked_at", blockedAt)
+}
+
+// botBlockedUsers -> IDs of users who blocked the bot, scheduled messages aren't sent to them
+func botBlockedUsers() *goqu.SelectDataset {
+	return goqu.Dialect("postgres").
+		From("users").
+		Select("id").
+		Where(goqu.C("bot_blocked_at").IsNotNull())
+}
+
+// SetBan (ctx, userID, bannedAt, reason) -> nil bannedAt unbans the user and clears the reason,
+// types.ErrNotFound if there is no user
+func (repository *UsersRepository) SetBan(ctx context.Context, userID int64, bannedAt *time.Time, reason string) error {
+	if bannedAt == nil {
+		reason = ""
+	}
+
+	query := goqu.Dialect("postgres").
+		Update("users").
+		Set(
+			goqu.Record{
+				"banned_at":  bannedAt,
+				"ban_reason": reason,
+			},
+		).
+		Where(
+			goqu.C("id").Eq(userID),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	result, err := repository.dbInstance.Exec(ctx, sql, args...)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> UsersRepository -> SetBan -> repository.dbInstance.Exec(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return types.ErrNotFound
+	}
+
+	return nil
+}
+
+func (repository *UsersRepository) setTime(ctx context.Context, method string, userID int64, column string, value *time.Time) error {
+	query := goqu.Dialect("postgres").
+		Update("users").
+		Set(
+			goqu.Record{
+				column: value,
+			},
+		).
+		Where(
+			goqu.C("id").Eq(userID),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	result, err := repository.dbInstance.Exec(ctx, sql, args...)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> UsersRepository -> `+method+` -> repository.dbInstance.Exec(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return types.ErrNotFound
+	}
+
+	return nil
+}
+
+// GetPage (ctx, afterUserID, limit) -> users with ID greater than afterUserID, by ID
+func (repository *UsersRepository) GetPage(ctx context.Context, afterUserID int64, limit uint) ([]models.User, error) {
+	query := repository.selectAllCols().
+		Where(
+			goqu.C("id").Gt(afterUserID),
+		).
+		Order(
+			goqu.C("id").Asc(),
+		).
+		Limit(limit)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	rows, err := repository.dbInstance.Query(ctx, sql, args...)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> UsersRepository -> GetPage -> repository.dbInstance.Query(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return []models.User{}, err
+	}
+	defer rows.Close()
+
+	var users []models.User
+	for rows.Next() {
+		var user models.User
+		err = rows.Scan(
+			&user.ID,
+			&user.TelegramID,
+			&user.CreatedAt,
+			&user.DeletionRequestedAt,
+			&user.LastSeenAt,
+			&user.BannedAt,
+			&user.BanReason,
+			&user.BotBlockedAt,
+		)
+		if err != nil {
+			repository.logger.Debugw(
+				`Repositories -> DB -> UsersRepository -> GetPage -> rows.Scan()`,
+				"error", err.Error(),
+			)
+			return []models.User{}, err
+		}
+		users = append(users, user)
+	}
+
+	return users, nil
+}
+
+// Count (ctx, filter) -> number of users matching the filter
+func (repository *UsersRepository) Count(ctx context.Context, filter types.UsersCountFilter) (int64, error) {
+	query := goqu.Dialect("postgres").
+		From("users").
+		Select(goqu.COUNT("*"))
+
+	if filter.CreatedFrom != nil {
+		query = query.Where(goqu.C("created_at").Gte(*filter.CreatedFrom))
+	}
+	if filter.SeenFrom != nil {
+		query = query.Where(goqu.C("last_seen_at").Gte(*filter.SeenFrom))
+	}
+	if filter.Banned {
+		query = query.Where(goqu.C("banned_at").IsNotNull())
+	}
+	if filter.DeletionRequested {
+		query = query.Where(goqu.C("deletion_requested_at").IsNotNull())
+	}
+	if filter.BotBlocked {
+		query = query.Where(goqu.C("bot_blocked_at").IsNotNull())
+	}
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	var count int64
+	err := repository.dbInstance.QueryRow(ctx, sql, args...).Scan(&count)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> UsersRepository -> Count -> row.Scan()`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return 0, err
+	}
+
+	return count, nil
+}
+
+type userDataTable struct {
+	name  string
+	where exp.Expression
+}
+
+// userDataTables (userID) -> tables with rows of the user, children before parents:
+// a row deleted by a cascade wouldn't be counted in the table it belongs to
+func userDataTables(userID int64) []userDataTable {
+	userTasks := goqu.Dialect("postgres").
+		From("tasks").
+		Select("id").
+		Where(goqu.C("user_id").Eq(userID))
+	userWebhooks := goqu.Dialect("postgres").
+		From("webhooks").
+		Select("id").
+		Where(goqu.C("user_id").Eq(userID))
+
+	return []userDataTable{
+		{name: "notifications", where: goqu.C("task_id").In(userTasks)},
+		{name: "task_tags", where: goqu.C("task_id").In(userTasks)},
+		{name: "task_dependencies", where: goqu.Or(
+			goqu.C("task_id").In(userTasks),
+			goqu.C("blocked_by_task_id").In(userTasks),
+		)},
+		{name: "task_escalations", where: goqu.C("task_id").In(userTasks)},
+		{name: "escalation_policies", where: goqu.C("user_id").Eq(userID)},
+		{name: "caldav_objects", where: goqu.C("user_id").Eq(userID)},
+		{name: "tasks", where: goqu.C("user_id").Eq(userID)},
+		{name: "saved_filters", where: goqu.C("user_id").Eq(userID)},
+		{name: "user_settings", where: goqu.C("user_id").Eq(userID)},
+		{name: "rate_limits", where: goqu.C("user_id").Eq(userID)},
+		{name: "outbox", where: goqu.C("user_id").Eq(userID)},
+		{name: "api_tokens", where: goqu.C("user_id").Eq(userID)},
+		{name: "webhook_deliveries", where: goqu.C("webhook_id").In(userWebhooks)},
+		{name: "webhooks", where: goqu.C("user_id").Eq(userID)},
+		{name: "users", where: goqu.C("id").Eq(userID)},
+	}
+}
+
+// Purge (ctx, userID) -> deletes the user and all rows of the user table by table in one transaction and
+// returns the number of deleted rows by table. Before the commit every table is checked to have no rows
+// of the user left, otherwise nothing is deleted. ErrNotFound if there is no such user
+func (repository *UsersRepository) Purge(ctx context.Context, userID int64) (map[string]int64, error) {
+	return repository.purge(ctx, userID, nil)
+}
+
+// PurgeRequestedBefore (ctx, userID, requestedBefore) -> like Purge, but only if the user requested the deletion
+// before the time. The row of the user is locked first, so a deletion cancelled meanwhile either is seen here
+// or waits for the purge. ErrNotFound if there is no such user or the deletion isn't requested anymore
+func (repository *UsersRepository) PurgeRequestedBefore(ctx context.Context, userID int64, requestedBefore time.Time) (map[string]int64, error) {
+	return repository.purge(ctx, userID, &requestedBefore)
+}
+
+func (repository *UsersRepository) purge(ctx context.Context, userID int64, requestedBefore *time.Time) (map[string]int64, error) {
+	tx, err := repository.dbInstance.Begin(ctx)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> UsersRepository -> Purge -> repository.dbInstance.Begin()`,
+			"error", err.Error(),
+		)
+		return nil, err
+	}
+	//После Commit откат ничего не делает
+	defer tx.Rollback(ctx)
+
+	if requestedBefore != nil {
+		query := goqu.Dialect("postgres").
+			From("users").
+			Select("id").
+			Where(
+				goqu.C("id").Eq(userID),
+				goqu.C("deletion_requested_at").Lte(*requestedBefore),
+			).
+			ForUpdate(exp.Wait)
+
+		sql, args, _ := query.Prepared(true).ToSQL()
+
+		var lockedID int64
+		err = tx.QueryRow(ctx, sql, args...).Scan(&lockedID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, types.ErrNotFound
+		}
+		if err != nil {
+			repository.logger.Debugw(
+				`Repositories -> DB -> UsersRepository -> Purge -> tx.QueryRow(sql, args...).Scan()`,
+				"error", err.Error(), "SQL", sql, "args", args,
+			)
+			return nil, err
+		}
+	}
+
+	deleted := map[string]int64{}
+	for _, table := range userDataTables(userID) {
+		query := goqu.Dialect("postgres").
+			Delete(table.name).
+			Where(table.where)
+
+		sql, args, _ := query.Prepared(true).ToSQL()
+
+		result, err := tx.Exec(ctx, sql, args...)
+		if err != nil {
+			repository.logger.Debugw(
+				`Repositories -> DB -> UsersRepository -> Purge -> tx.Exec(sql, args...)`,
+				"error", err.Error(), "SQL", sql, "args", args,
+			)
+			return nil, err
+		}
+		deleted[table.name] = result.RowsAffected()
+	}
+	if deleted["users"] == 0 {
+		return nil, types.ErrNotFound
+	}
+
+	for _, table := range userDataTables(userID) {
+		query := goqu.Dialect("postgres").
+			From(table.name).
+			Select(goqu.COUNT("*")).
+			Where(table.where)
+
+		sql, args, _ := query.Prepared(true).ToSQL()
+
+		var left int64
+		err = tx.QueryRow(ctx, sql, args...).Scan(&left)
+		if err != nil {
+			repository.logger.Debugw(
+				`Repositories -> DB -> UsersRepository -> Purge -> tx.QueryRow(sql, args...).Scan()`,
+				"error", err.Error(), "SQL", sql, "args", args,
+			)
+			return nil, err
+		}
+		if left > 0 {
+			return nil, errors.Errorf("%d rows of the user are left in %s", left, table.name)
+		}
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> UsersRepository -> Purge -> tx.Commit()`,
+			"error", err.Error(),
+		)
+		return nil, err
+	}
+
+	return deleted, nil
+}
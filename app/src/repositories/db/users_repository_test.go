@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"github.com/pkg/errors"
 	"math/rand"
 	"testing"
@@ -32,7 +33,7 @@ func getUsersRepository() (*UsersRepository, error) {
 		return nil, err
 	}
 
-	usersRepository := NewUsersRepository(logger, pgInstance)
+	usersRepository := NewUsersRepository(logger, pgInstance, conf.Database.QueryTimeout)
 	return usersRepository, nil
 }
 
@@ -53,7 +54,7 @@ func createUserForTest() (models.User, error) {
 		TelegramID: generateRandomTelegramID(),
 	}
 
-	user, err = repository.Create(user)
+	user, err = repository.Create(context.Background(), user)
 	if err != nil {
 		return models.User{}, err
 	}
@@ -67,7 +68,7 @@ func deleteUserAfterTest(user models.User) error {
 		return err
 	}
 
-	err = repository.DeleteByTelegramID(user.TelegramID)
+	err = repository.DeleteByTelegramID(context.Background(), user.TelegramID)
 	if err != nil {
 		return err
 	}
@@ -85,13 +86,13 @@ func TestCreateUser(t *testing.T) {
 		TelegramID: generateRandomTelegramID(),
 	}
 
-	user, err = repository.Create(user)
+	user, err = repository.Create(context.Background(), user)
 	if err != nil {
 		t.Fatal(err)
 	}
-	defer repository.DeleteByTelegramID(user.TelegramID)
+	defer repository.DeleteByTelegramID(context.Background(), user.TelegramID)
 
-	findUserResult, err := repository.FindByTelegramID(user.TelegramID)
+	findUserResult, err := repository.FindByTelegramID(context.Background(), user.TelegramID)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -100,7 +101,7 @@ func TestCreateUser(t *testing.T) {
 		t.Fatal("models are not equal")
 	}
 
-	user, err = repository.Create(user)
+	user, err = repository.Create(context.Background(), user)
 	if err == nil {
 		t.Fatal("user already exist by there are no errors")
 	} else {
@@ -120,7 +121,7 @@ func TestFindUserByID(t *testing.T) {
 		TelegramID: generateRandomTelegramID(),
 	}
 
-	_, err = repository.FindByTelegramID(user.TelegramID)
+	_, err = repository.FindByTelegramID(context.Background(), user.TelegramID)
 	if err == nil {
 		t.Fatal("user not exist but there are no errors")
 	} else {
@@ -129,13 +130,13 @@ func TestFindUserByID(t *testing.T) {
 		}
 	}
 
-	user, err = repository.Create(user)
+	user, err = repository.Create(context.Background(), user)
 	if err != nil {
 		t.Fatal(err)
 	}
-	defer repository.DeleteByTelegramID(user.TelegramID)
+	defer repository.DeleteByTelegramID(context.Background(), user.TelegramID)
 
-	findResult, err := repository.FindByTelegramID(user.TelegramID)
+	findResult, err := repository.FindByTelegramID(context.Background(), user.TelegramID)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -145,6 +146,64 @@ func TestFindUserByID(t *testing.T) {
 	}
 }
 
+// TestIDsAbove32Bits -> Telegram IDs already exceed 2^31, so do the keys of a long running bot
+func TestIDsAbove32Bits(t *testing.T) {
+	repository, err := getUsersRepository()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//Ключи больше 2^32 задаются явно, последовательности не меняются. Всё откатывается в конце теста
+	tx, err := repository.dbInstance.Begin(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback(context.Background())
+
+	usersRepository := &UsersRepository{logger: repository.logger, dbInstance: tx}
+	tasksRepository := &TasksRepository{logger: repository.logger, dbInstance: tx}
+
+	const above32Bits = int64(1) << 32
+	userID := above32Bits + generateRandomTelegramID()
+	telegramID := above32Bits*2 + generateRandomTelegramID()
+	_, err = tx.Exec(
+		context.Background(),
+		"INSERT INTO users (id, telegram_id, created_at) VALUES ($1, $2, NOW())",
+		userID, telegramID,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	foundUser, err := usersRepository.FindByTelegramID(context.Background(), telegramID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if foundUser.ID != userID || foundUser.TelegramID != telegramID {
+		t.Fatalf("got user %d with Telegram ID %d, expected %d with %d",
+			foundUser.ID, foundUser.TelegramID, userID, telegramID,
+		)
+	}
+
+	taskID := above32Bits + generateRandomTelegramID()
+	_, err = tx.Exec(
+		context.Background(),
+		"INSERT INTO tasks (id, title, description, user_id, created_at) VALUES ($1, 'Test task title', '', $2, NOW())",
+		taskID, userID,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	foundTask, err := tasksRepository.FindByID(context.Background(), taskID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if foundTask.ID != taskID || foundTask.UserID != userID {
+		t.Fatalf("got task %d of user %d, expected %d of user %d", foundTask.ID, foundTask.UserID, taskID, userID)
+	}
+}
+
 func TestDeleteUserByTelegramID(t *testing.T) {
 	repository, err := getUsersRepository()
 	if err != nil {
@@ -155,22 +214,22 @@ func TestDeleteUserByTelegramID(t *testing.T) {
 		TelegramID: generateRandomTelegramID(),
 	}
 
-	user, err = repository.Create(user)
+	user, err = repository.Create(context.Background(), user)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	_, err = repository.FindByTelegramID(user.TelegramID)
+	_, err = repository.FindByTelegramID(context.Background(), user.TelegramID)
 	if err != nil {
 		t.Fatal(err.Error())
 	}
 
-	err = repository.DeleteByTelegramID(user.TelegramID)
+	err = repository.DeleteByTelegramID(context.Background(), user.TelegramID)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	_, err = repository.FindByTelegramID(user.TelegramID)
+	_, err = repository.FindByTelegramID(context.Background(), user.TelegramID)
 	if err == nil {
 		t.Fatal("user still exist after deletion")
 	} else {
@@ -179,3 +238,201 @@ func TestDeleteUserByTelegramID(t *testing.T) {
 		}
 	}
 }
+
+func TestUserDeletionRequest(t *testing.T) {
+	repository, err := getUsersRepository()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := createUserForTest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer deleteUserAfterTest(user)
+
+	requestedAt := time.Now().Add(-time.Hour)
+	err = repository.SetDeletionRequestedAt(context.Background(), user.ID, &requestedAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	users, err := repository.GetDeletionRequestedBefore(context.Background(), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, dueUser := range users {
+		if dueUser.ID == user.ID {
+			found = dueUser.DeletionRequestedAt != nil && dueUser.DeletionRequestedAt.Equal(requestedAt.Truncate(time.Microsecond))
+		}
+	}
+	if !found {
+		t.Fatalf("user %d with the deletion requested at %v is not found in %+v", user.ID, requestedAt, users)
+	}
+
+	users, err = repository.GetDeletionRequestedBefore(context.Background(), requestedAt.Add(-time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, dueUser := range users {
+		if dueUser.ID == user.ID {
+			t.Fatal("the grace period of the user is not over yet")
+		}
+	}
+
+	err = repository.SetDeletionRequestedAt(context.Background(), user.ID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	findResult, err := repository.FindByID(context.Background(), user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if findResult.DeletionRequestedAt != nil {
+		t.Fatalf("expected the deletion to be cancelled, got %v", findResult.DeletionRequestedAt)
+	}
+
+	err = repository.SetDeletionRequestedAt(context.Background(), -1, &requestedAt)
+	if !errors.Is(err, types.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestPurgeUser(t *testing.T) {
+	repository, err := getUsersRepository()
+	if err != nil {
+		t.Fatal(err)
+	}
+	notificationsRepository, err := getNotificationRepository()
+	if err != nil {
+		t.Fatal(err)
+	}
+	taskTagsRepository, err := getTaskTagsRepository()
+	if err != nil {
+		t.Fatal(err)
+	}
+	userSettingsRepository, err := getUserSettingsRepository()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	notification, err := getNotificationModelForCreation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := *notification.Task.User
+	defer deleteUserAfterTest(user)
+
+	_, err = notificationsRepository.Create(context.Background(), notification)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = taskTagsRepository.SetForTask(context.Background(), notification.TaskID, []string{"work", "home"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = userSettingsRepository.Save(context.Background(), models.UserSettings{UserID: user.ID, Language: "en", Timezone: "UTC", ListSort: "due"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = createWebhookForTest(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deleted, err := repository.Purge(context.Background(), user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]int64{"users": 1, "tasks": 1, "notifications": 1, "task_tags": 2, "user_settings": 1, "webhooks": 1}
+	for table, count := range expected {
+		if deleted[table] != count {
+			t.Fatalf("%s: expected %d deleted rows, got %d", table, count, deleted[table])
+		}
+	}
+
+	_, err = repository.FindByID(context.Background(), user.ID)
+	if !errors.Is(err, types.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	_, err = repository.Purge(context.Background(), user.ID)
+	if !errors.Is(err, types.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestBanAndCountUsers(t *testing.T) {
+	repository, err := getUsersRepository()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	before, err := repository.Count(context.Background(), types.UsersCountFilter{Banned: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := createUserForTest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer deleteUserAfterTest(user)
+
+	now := time.Now()
+	err = repository.SetBan(context.Background(), user.ID, &now, "spam")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = repository.SetLastSeenAt(context.Background(), user.ID, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	findResult, err := repository.FindByID(context.Background(), user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if findResult.BannedAt == nil || findResult.BanReason != "spam" || findResult.LastSeenAt == nil {
+		t.Fatalf("expected the user to be banned and seen, got %+v", findResult)
+	}
+
+	after, err := repository.Count(context.Background(), types.UsersCountFilter{Banned: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after != before+1 {
+		t.Fatalf("expected %d banned users, got %d", before+1, after)
+	}
+
+	hourAgo := now.Add(-time.Hour)
+	seen, err := repository.Count(context.Background(), types.UsersCountFilter{SeenFrom: &hourAgo, CreatedFrom: &hourAgo})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seen < 1 {
+		t.Fatalf("expected at least 1 user seen in the last hour, got %d", seen)
+	}
+
+	users, err := repository.GetPage(context.Background(), user.ID-1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 1 || users[0].ID != user.ID {
+		t.Fatalf("expected only user %d, got %+v", user.ID, users)
+	}
+
+	err = repository.SetBan(context.Background(), user.ID, nil, "spam")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	findResult, err = repository.FindByID(context.Background(), user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if findResult.BannedAt != nil || findResult.BanReason != "" {
+		t.Fatalf("expected the ban and its reason to be cleared, got %+v", findResult)
+	}
+}
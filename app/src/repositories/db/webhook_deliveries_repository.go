@@ -0,0 +1,194 @@
+package db
+
+import (
+	"context"
+	"github.com/doug-martin/goqu/v9"
+	_ "github.com/doug-martin/goqu/v9/dialect/postgres"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"go.uber.org/zap"
+	"sort"
+	"tg_todo_bot/src/models"
+	"time"
+)
+
+type WebhookDeliveriesRepository struct {
+	logger     *zap.SugaredLogger
+	dbInstance querier
+}
+
+func NewWebhookDeliveriesRepository(
+	logger *zap.SugaredLogger,
+	dbInstance *pgxpool.Pool,
+	queryTimeout time.Duration,
+) *WebhookDeliveriesRepository {
+	return &WebhookDeliveriesRepository{
+		logger:     logger,
+		dbInstance: newTimeoutQuerier(dbInstance, queryTimeout),
+	}
+}
+
+func (repository *WebhookDeliveriesRepository) Create(ctx context.Context, delivery models.WebhookDelivery) (models.WebhookDelivery, error) {
+	now := time.Now()
+	query := goqu.Dialect("postgres").
+		Insert("webhook_deliveries").
+		Rows(
+			goqu.Record{
+				"webhook_id":      delivery.WebhookID,
+				"event_type":      delivery.EventType,
+				"payload":         string(delivery.Payload),
+				"attempts":        delivery.Attempts,
+				"next_attempt_at": delivery.NextAttemptAt,
+				"last_error":      delivery.LastError,
+				"delivered_at":    delivery.DeliveredAt,
+				"created_at":      now,
+			},
+		).
+		Returning("id", "created_at")
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	row := repository.dbInstance.QueryRow(ctx, sql, args...)
+
+	err := row.Scan(&delivery.ID, &delivery.CreatedAt)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> WebhookDeliveriesRepository -> Create -> row.Scan()`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return models.WebhookDelivery{}, err
+	}
+
+	return delivery, nil
+}
+
+// Update (ctx, delivery) -> saves the result of a delivery attempt
+func (repository *WebhookDeliveriesRepository) Update(ctx context.Context, delivery models.WebhookDelivery) error {
+	query := goqu.Dialect("postgres").
+		Update("webhook_deliveries").
+		Set(
+			goqu.Record{
+				"attempts":        delivery.Attempts,
+				"next_attempt_at": delivery.NextAttemptAt,
+				"last_error":      delivery.LastError,
+				"delivered_at":    delivery.DeliveredAt,
+			},
+		).
+		Where(
+			goqu.C("id").Eq(delivery.ID),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	_, err := repository.dbInstance.Exec(ctx, sql, args...)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> WebhookDeliveriesRepository -> Update -> repository.dbInstance.Exec(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return err
+	}
+
+	return nil
+}
+
+// Claim (ctx, now, lease, limit) -> due deliveries, the oldest first, with their webhooks. Their next attempt is moved
+// by lease, so other replicas don't take them, and if the process dies before Update they are sent again after the lease
+func (repository *WebhookDeliveriesRepository) Claim(
+	ctx context.Context,
+	now time.Time,
+	lease time.Duration,
+	limit uint,
+) ([]models.WebhookDelivery, error) {
+	dueDeliveries := goqu.Dialect("postgres").
+		From("webhook_deliveries").
+		Select("id").
+		Where(
+			goqu.C("next_attempt_at").Lte(now),
+		).
+		Order(
+			goqu.C("next_attempt_at").Asc(),
+			goqu.C("id").Asc(),
+		).
+		Limit(limit).
+		ForUpdate(exp.SkipLocked)
+
+	query := goqu.Dialect("postgres").
+		Update(goqu.T("webhook_deliveries").As("d")).
+		Set(
+			goqu.Record{
+				"next_attempt_at": now.Add(lease),
+			},
+		).
+		From(goqu.T("webhooks").As("w")).
+		Where(
+			goqu.I("w.id").Eq(goqu.I("d.webhook_id")),
+			goqu.I("d.id").In(dueDeliveries),
+		).
+		Returning(
+			goqu.I("d.id"),
+			goqu.I("d.webhook_id"),
+			goqu.I("d.event_type"),
+			goqu.I("d.payload"),
+			goqu.I("d.attempts"),
+			goqu.I("d.next_attempt_at"),
+			goqu.I("d.last_error"),
+			goqu.I("d.delivered_at"),
+			goqu.I("d.created_at"),
+			goqu.I("w.user_id"),
+			goqu.I("w.url"),
+			goqu.I("w.secret"),
+			goqu.I("w.created_at"),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	rows, err := repository.dbInstance.Query(ctx, sql, args...)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> WebhookDeliveriesRepository -> Claim -> repository.dbInstance.Query(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return []models.WebhookDelivery{}, err
+	}
+	defer rows.Close()
+
+	var deliveries []models.WebhookDelivery
+	for rows.Next() {
+		var delivery models.WebhookDelivery
+		var webhook models.Webhook
+		err = rows.Scan(
+			&delivery.ID,
+			&delivery.WebhookID,
+			&delivery.EventType,
+			&delivery.Payload,
+			&delivery.Attempts,
+			&delivery.NextAttemptAt,
+			&delivery.LastError,
+			&delivery.DeliveredAt,
+			&delivery.CreatedAt,
+			&webhook.UserID,
+			&webhook.URL,
+			&webhook.Secret,
+			&webhook.CreatedAt,
+		)
+		if err != nil {
+			repository.logger.Debugw(
+				`Repositories -> DB -> WebhookDeliveriesRepository -> Claim -> rows.Scan()`,
+				"error", err.Error(),
+			)
+			return []models.WebhookDelivery{}, err
+		}
+
+		webhook.ID = delivery.WebhookID
+		delivery.Webhook = &webhook
+		deliveries = append(deliveries, delivery)
+	}
+
+	//RETURNING не сохраняет порядок подзапроса, а id растёт в порядке записи
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].ID < deliveries[j].ID
+	})
+
+	return deliveries, nil
+}
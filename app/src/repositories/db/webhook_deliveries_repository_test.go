@@ -0,0 +1,126 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"tg_todo_bot/config"
+	"tg_todo_bot/kernel/db"
+	zap_logger "tg_todo_bot/kernel/logger"
+	"tg_todo_bot/src/models"
+	"time"
+)
+
+func getWebhookDeliveriesRepository() (*WebhookDeliveriesRepository, error) {
+	logger := zap_logger.InitLogger()
+
+	conf, err := config.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	pg := db.NewPG(
+		conf.Database.Host,
+		conf.Database.Port,
+		conf.Database.Database,
+		conf.Database.User,
+		conf.Database.Password,
+	)
+	pgInstance, err := pg.OpenPool()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewWebhookDeliveriesRepository(logger, pgInstance, conf.Database.QueryTimeout), nil
+}
+
+func TestClaimWebhookDeliveries(t *testing.T) {
+	repository, err := getWebhookDeliveriesRepository()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := createUserForTest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer deleteUserAfterTest(user)
+
+	webhook, err := createWebhookForTest(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//Далёкое будущее, чтобы не мешали доставки других тестов
+	now := time.Date(2100, 1, 1, 12, 0, 0, 0, time.UTC)
+	later := now.Add(time.Hour)
+	due, err := repository.Create(context.Background(), models.WebhookDelivery{
+		WebhookID:     webhook.ID,
+		EventType:     "task.created",
+		Payload:       []byte(`{"event": "task.created"}`),
+		NextAttemptAt: &now,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = repository.Create(context.Background(), models.WebhookDelivery{
+		WebhookID:     webhook.ID,
+		EventType:     "task.deleted",
+		Payload:       []byte(`{"event": "task.deleted"}`),
+		NextAttemptAt: &later,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deliveries, err := repository.Claim(context.Background(), now, time.Minute, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var found *models.WebhookDelivery
+	for i, delivery := range deliveries {
+		if delivery.WebhookID != webhook.ID {
+			continue
+		}
+		if delivery.ID != due.ID {
+			t.Fatalf("got delivery %d which isn't due yet", delivery.ID)
+		}
+		found = &deliveries[i]
+	}
+	if found == nil {
+		t.Fatalf("delivery %d isn't due", due.ID)
+	}
+	if found.Webhook == nil || found.Webhook.URL != webhook.URL || found.Webhook.Secret != webhook.Secret {
+		t.Fatalf("got webhook %+v, expected %+v", found.Webhook, webhook)
+	}
+
+	//Взятая доставка не возвращается до конца аренды
+	deliveries, err = repository.Claim(context.Background(), now, time.Minute, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, delivery := range deliveries {
+		if delivery.ID == due.ID {
+			t.Fatal("claimed delivery is returned again before the lease ends")
+		}
+	}
+
+	//Доставленное больше не возвращается
+	found.Attempts = 1
+	found.DeliveredAt = &now
+	found.NextAttemptAt = nil
+	err = repository.Update(context.Background(), *found)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deliveries, err = repository.Claim(context.Background(), later, time.Minute, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, delivery := range deliveries {
+		if delivery.ID == due.ID {
+			t.Fatal("delivered delivery is still due")
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package db
+
+import (
+	"context"
+	"github.com/doug-martin/goqu/v9"
+	_ "github.com/doug-martin/goqu/v9/dialect/postgres"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"go.uber.org/zap"
+	"tg_todo_bot/src/models"
+	"tg_todo_bot/src/repositories/types"
+	"time"
+)
+
+type WebhooksRepository struct {
+	logger     *zap.SugaredLogger
+	dbInstance querier
+}
+
+func NewWebhooksRepository(
+	logger *zap.SugaredLogger,
+	dbInstance *pgxpool.Pool,
+	queryTimeout time.Duration,
+) *WebhooksRepository {
+	return &WebhooksRepository{
+		logger:     logger,
+		dbInstance: newTimeoutQuerier(dbInstance, queryTimeout),
+	}
+}
+
+func (repository *WebhooksRepository) Create(ctx context.Context, webhook models.Webhook) (models.Webhook, error) {
+	now := time.Now()
+	query := goqu.Dialect("postgres").
+		Insert("webhooks").
+		Rows(
+			goqu.Record{
+				"user_id":    webhook.UserID,
+				"url":        webhook.URL,
+				"secret":     webhook.Secret,
+				"created_at": now,
+			},
+		).
+		Returning("id", "created_at")
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	row := repository.dbInstance.QueryRow(ctx, sql, args...)
+
+	err := row.Scan(&webhook.ID, &webhook.CreatedAt)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> WebhooksRepository -> Create -> row.Scan()`,
+			"error", err.Error(), "SQL", sql,
+		)
+		return models.Webhook{}, err
+	}
+
+	return webhook, nil
+}
+
+func (repository *WebhooksRepository) GetAllForUser(ctx context.Context, userID int64) ([]models.Webhook, error) {
+	query := goqu.Dialect("postgres").
+		From("webhooks").
+		Select(
+			goqu.C("id"),
+			goqu.C("user_id"),
+			goqu.C("url"),
+			goqu.C("secret"),
+			goqu.C("created_at"),
+		).
+		Where(
+			goqu.C("user_id").Eq(userID),
+		).
+		Order(
+			goqu.C("id").Asc(),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	rows, err := repository.dbInstance.Query(ctx, sql, args...)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> WebhooksRepository -> GetAllForUser -> repository.dbInstance.Query(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return []models.Webhook{}, err
+	}
+	defer rows.Close()
+
+	var webhooks []models.Webhook
+	for rows.Next() {
+		var webhook models.Webhook
+		err = rows.Scan(
+			&webhook.ID,
+			&webhook.UserID,
+			&webhook.URL,
+			&webhook.Secret,
+			&webhook.CreatedAt,
+		)
+		if err != nil {
+			repository.logger.Debugw(
+				`Repositories -> DB -> WebhooksRepository -> GetAllForUser -> rows.Scan()`,
+				"error", err.Error(),
+			)
+			return []models.Webhook{}, err
+		}
+
+		webhooks = append(webhooks, webhook)
+	}
+
+	return webhooks, nil
+}
+
+// DeleteForUser (ctx, userID, webhookID) -> types.ErrNotFound if the user has no such webhook
+func (repository *WebhooksRepository) DeleteForUser(ctx context.Context, userID, webhookID int64) error {
+	query := goqu.Dialect("postgres").
+		Delete("webhooks").
+		Where(
+			goqu.C("id").Eq(webhookID),
+			goqu.C("user_id").Eq(userID),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	result, err := repository.dbInstance.Exec(ctx, sql, args...)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> DB -> WebhooksRepository -> DeleteForUser -> repository.dbInstance.Exec(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return types.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package db
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"strings"
+	"testing"
+	"tg_todo_bot/config"
+	"tg_todo_bot/kernel/db"
+	zap_logger "tg_todo_bot/kernel/logger"
+	"tg_todo_bot/src/models"
+	"tg_todo_bot/src/repositories/types"
+)
+
+func getWebhooksRepository() (*WebhooksRepository, error) {
+	logger := zap_logger.InitLogger()
+
+	conf, err := config.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	pg := db.NewPG(
+		conf.Database.Host,
+		conf.Database.Port,
+		conf.Database.Database,
+		conf.Database.User,
+		conf.Database.Password,
+	)
+	pgInstance, err := pg.OpenPool()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewWebhooksRepository(logger, pgInstance, conf.Database.QueryTimeout), nil
+}
+
+func createWebhookForTest(user models.User) (models.Webhook, error) {
+	repository, err := getWebhooksRepository()
+	if err != nil {
+		return models.Webhook{}, err
+	}
+
+	return repository.Create(context.Background(), models.Webhook{
+		UserID: user.ID,
+		URL:    "https://example.com/hook",
+		Secret: strings.Repeat("s", 64),
+	})
+}
+
+func TestCreateWebhook(t *testing.T) {
+	repository, err := getWebhooksRepository()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := createUserForTest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer deleteUserAfterTest(user)
+
+	webhook, err := createWebhookForTest(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if webhook.ID == 0 {
+		t.Fatal("expected ID of the created webhook")
+	}
+
+	webhooks, err := repository.GetAllForUser(context.Background(), user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(webhooks) != 1 || webhooks[0].URL != webhook.URL || webhooks[0].Secret != webhook.Secret {
+		t.Fatalf("got %+v, expected [%+v]", webhooks, webhook)
+	}
+}
+
+func TestDeleteWebhookForUser(t *testing.T) {
+	repository, err := getWebhooksRepository()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := createUserForTest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer deleteUserAfterTest(user)
+
+	webhook, err := createWebhookForTest(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//Чужой вебхук не удаляется
+	err = repository.DeleteForUser(context.Background(), user.ID+1, webhook.ID)
+	if !errors.Is(err, types.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	err = repository.DeleteForUser(context.Background(), user.ID, webhook.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	webhooks, err := repository.GetAllForUser(context.Background(), user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(webhooks) != 0 {
+		t.Fatalf("got %d webhooks, expected 0", len(webhooks))
+	}
+}
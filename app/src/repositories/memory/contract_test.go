@@ -0,0 +1,30 @@
+package memory
+
+import (
+	"go.uber.org/zap"
+	"testing"
+	"tg_todo_bot/src/repositories/contract"
+)
+
+func getRepositories() contract.Repositories {
+	logger := zap.NewNop().Sugar()
+	store := NewStore()
+
+	return contract.Repositories{
+		Tasks:         NewTasksRepository(logger, store),
+		Notifications: NewNotificationsRepository(logger, store),
+		Users:         NewUsersRepository(logger, store),
+	}
+}
+
+func TestTasksRepositoryContract(t *testing.T) {
+	contract.TestTasksRepository(t, getRepositories())
+}
+
+func TestNotificationsRepositoryContract(t *testing.T) {
+	contract.TestNotificationsRepository(t, getRepositories())
+}
+
+func TestUsersRepositoryContract(t *testing.T) {
+	contract.TestUsersRepository(t, getRepositories())
+}
@@ -0,0 +1,228 @@
+package memory
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"sort"
+	"tg_todo_bot/src/models"
+	"tg_todo_bot/src/repositories/types"
+	"time"
+)
+
+type NotificationsRepository struct {
+	logger *zap.SugaredLogger
+	store  *Store
+}
+
+func NewNotificationsRepository(
+	logger *zap.SugaredLogger,
+	store *Store,
+) *NotificationsRepository {
+	return &NotificationsRepository{
+		logger: logger,
+		store:  store,
+	}
+}
+
+// checkNotification (notification, ID) -> the error the notifications table would give for the row, the mutex must be held
+func (repository *NotificationsRepository) checkNotification(notification models.Notification, ID int64) error {
+	if _, exist := repository.store.tasks[notification.TaskID]; !exist {
+		return errors.Errorf("task %d doesn't exist", notification.TaskID)
+	}
+	for _, stored := range repository.store.notifications {
+		//Нарушение уникальности task_id
+		if stored.TaskID == notification.TaskID && stored.ID != ID {
+			return types.ErrAlreadyExist
+		}
+	}
+
+	return nil
+}
+
+func (repository *NotificationsRepository) Create(ctx context.Context, notification models.Notification) (models.Notification, error) {
+	err := contextError(ctx)
+	if err != nil {
+		return models.Notification{}, err
+	}
+
+	repository.store.mutex.Lock()
+	defer repository.store.mutex.Unlock()
+
+	err = repository.checkNotification(notification, 0)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> Memory -> NotificationsRepository -> Create`,
+			"error", err.Error(),
+		)
+		return models.Notification{}, err
+	}
+
+	repository.store.lastNotificationID++
+	notification.ID = repository.store.lastNotificationID
+
+	row := notification
+	row.Task = nil
+	row.CreatedAt = time.Now()
+	repository.store.notifications[notification.ID] = row
+
+	return notification, nil
+}
+
+func (repository *NotificationsRepository) Update(ctx context.Context, notification models.Notification) error {
+	err := contextError(ctx)
+	if err != nil {
+		return err
+	}
+
+	repository.store.mutex.Lock()
+	defer repository.store.mutex.Unlock()
+
+	stored, exist := repository.store.notifications[notification.ID]
+	if !exist {
+		//UPDATE без подходящих строк не ошибка
+		return nil
+	}
+
+	err = repository.checkNotification(notification, notification.ID)
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> Memory -> NotificationsRepository -> Update`,
+			"error", err.Error(),
+		)
+		return err
+	}
+
+	stored.TaskID = notification.TaskID
+	stored.NotifyAt = notification.NotifyAt
+	stored.RepeatInterval = notification.RepeatInterval
+	repository.store.notifications[notification.ID] = stored
+
+	return nil
+}
+
+// Fire (ctx, notification, nextNotifyAt, message) -> moves the notification to nextNotifyAt and saves the reminder
+// to the outbox. The notification is moved only from notification.NotifyAt: types.ErrNotFound if it's deleted or changed meanwhile
+func (repository *NotificationsRepository) Fire(ctx context.Context, notification models.Notification, nextNotifyAt time.Time, message models.OutboxMessage) error {
+	err := contextError(ctx)
+	if err != nil {
+		return err
+	}
+
+	repository.store.mutex.Lock()
+	defer repository.store.mutex.Unlock()
+
+	stored, exist := repository.store.notifications[notification.ID]
+	if !exist || !stored.NotifyAt.Equal(notification.NotifyAt) {
+		return types.ErrNotFound
+	}
+
+	stored.NotifyAt = nextNotifyAt
+	repository.store.notifications[notification.ID] = stored
+
+	if _, exist = repository.store.outbox[message.DedupKey]; exist {
+		//Такое срабатывание уже в outbox, напоминание всё равно переносится
+		repository.logger.Debugw(
+			`Repositories -> Memory -> NotificationsRepository -> Fire -> the message is already in the outbox`,
+			"dedupKey", message.DedupKey,
+		)
+		return nil
+	}
+
+	repository.store.lastOutboxID++
+	message.ID = repository.store.lastOutboxID
+	message.CreatedAt = time.Now()
+	repository.store.outbox[message.DedupKey] = message
+
+	return nil
+}
+
+func (repository *NotificationsRepository) DeleteByID(ctx context.Context, ID int64) error {
+	err := contextError(ctx)
+	if err != nil {
+		return err
+	}
+
+	repository.store.mutex.Lock()
+	defer repository.store.mutex.Unlock()
+
+	delete(repository.store.notifications, ID)
+
+	return nil
+}
+
+func (repository *NotificationsRepository) FindByTasksIDs(ctx context.Context, tasksIds []int64) (map[int64]models.Notification, error) {
+	err := contextError(ctx)
+	if err != nil {
+		return map[int64]models.Notification{}, err
+	}
+
+	repository.store.mutex.Lock()
+	defer repository.store.mutex.Unlock()
+
+	tasksNotificationsMap := map[int64]models.Notification{}
+	for _, notification := range repository.store.notifications {
+		for _, taskID := range tasksIds {
+			if notification.TaskID == taskID {
+				tasksNotificationsMap[taskID] = notification
+				break
+			}
+		}
+	}
+
+	return tasksNotificationsMap, nil
+}
+
+// GetUpcoming (ctx, upcomingTo) -> return notifications, except ones of users who blocked the bot.
+// The store has no dependencies, so no task is blocked
+func (repository *NotificationsRepository) GetUpcoming(ctx context.Context, upcomingTo time.Time) ([]models.Notification, error) {
+	err := contextError(ctx)
+	if err != nil {
+		return []models.Notification{}, err
+	}
+
+	repository.store.mutex.Lock()
+	defer repository.store.mutex.Unlock()
+
+	var notifications []models.Notification
+	for _, notification := range repository.store.notifications {
+		if notification.NotifyAt.After(upcomingTo) {
+			continue
+		}
+		if repository.store.isBotBlocked(repository.store.tasks[notification.TaskID].UserID) {
+			continue
+		}
+
+		notifications = append(notifications, notification)
+	}
+
+	sort.Slice(notifications, func(i, j int) bool {
+		if !notifications[i].NotifyAt.Equal(notifications[j].NotifyAt) {
+			return notifications[i].NotifyAt.Before(notifications[j].NotifyAt)
+		}
+		return notifications[i].ID < notifications[j].ID
+	})
+
+	return notifications, nil
+}
+
+func (repository *NotificationsRepository) FindByID(ctx context.Context, ID int64) (models.Notification, error) {
+	err := contextError(ctx)
+	if err != nil {
+		return models.Notification{}, err
+	}
+
+	repository.store.mutex.Lock()
+	defer repository.store.mutex.Unlock()
+
+	notification, exist := repository.store.notifications[ID]
+	if !exist {
+		repository.logger.Debugw(
+			`Repositories -> Memory -> NotificationsRepository -> FindByID`,
+			"error", types.ErrNotFound.Error(), "ID", ID,
+		)
+		return models.Notification{}, types.ErrNotFound
+	}
+
+	return notification, nil
+}
@@ -0,0 +1,85 @@
+package memory
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"sync"
+	"tg_todo_bot/src/models"
+	"tg_todo_bot/src/repositories/types"
+	"time"
+)
+
+// Store -> tables of the in-memory repositories. Repositories of one store see the changes of each other
+// and foreign keys cascade like in PostgreSQL: a deleted user takes its tasks, a deleted task its notification and tags.
+// Tables of other repositories (settings, dependencies) aren't kept, so all users are in UTC and no task is blocked
+type Store struct {
+	mutex sync.Mutex
+
+	users         map[int64]models.User
+	tasks         map[int64]models.Task
+	tags          map[int64][]string //by task ID
+	notifications map[int64]models.Notification
+	outbox        map[string]models.OutboxMessage //by dedup key
+
+	lastUserID         int64
+	lastTaskID         int64
+	lastNotificationID int64
+	lastOutboxID       int64
+}
+
+func NewStore() *Store {
+	return &Store{
+		users:         map[int64]models.User{},
+		tasks:         map[int64]models.Task{},
+		tags:          map[int64][]string{},
+		notifications: map[int64]models.Notification{},
+		outbox:        map[string]models.OutboxMessage{},
+	}
+}
+
+// deleteTask -> the task with its notification and tags, the mutex must be held
+func (store *Store) deleteTask(taskID int64) {
+	delete(store.tasks, taskID)
+	delete(store.tags, taskID)
+	for ID, notification := range store.notifications {
+		if notification.TaskID == taskID {
+			delete(store.notifications, ID)
+		}
+	}
+}
+
+// isBotBlocked (userID) -> the user blocked the bot, the mutex must be held
+func (store *Store) isBotBlocked(userID int64) bool {
+	return store.users[userID].BotBlockedAt != nil
+}
+
+// contextError (ctx) -> the error a query of the db repositories would get with this context
+func contextError(ctx context.Context) error {
+	err := ctx.Err()
+	if errors.Is(err, context.DeadlineExceeded) {
+		return &types.TimeoutError{Err: err}
+	}
+
+	return err
+}
+
+// timeBefore (a, b) -> nil is after all times, like COALESCE(datetime, 'infinity')
+func timeBefore(a, b *time.Time) bool {
+	switch {
+	case a == nil:
+		return false
+	case b == nil:
+		return true
+	default:
+		return a.Before(*b)
+	}
+}
+
+// timeEqual (a, b) -> both nil or the same instant
+func timeEqual(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return a.Equal(*b)
+}
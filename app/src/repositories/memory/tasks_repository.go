@@ -0,0 +1,615 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"sort"
+	"strings"
+	"tg_todo_bot/src/models"
+	"tg_todo_bot/src/repositories/types"
+	"time"
+	"unicode"
+)
+
+type TasksRepository struct {
+	logger *zap.SugaredLogger
+	store  *Store
+}
+
+func NewTasksRepository(
+	logger *zap.SugaredLogger,
+	store *Store,
+) *TasksRepository {
+	return &TasksRepository{
+		logger: logger,
+		store:  store,
+	}
+}
+
+// taskRow (task) -> only columns of the tasks table, relations aren't stored with the task
+func taskRow(task models.Task) models.Task {
+	task.User = nil
+	task.Notification = nil
+	task.BlockedBy = nil
+	task.Tags = nil
+
+	return task
+}
+
+func (repository *TasksRepository) Create(ctx context.Context, task models.Task) (models.Task, error) {
+	err := contextError(ctx)
+	if err != nil {
+		return models.Task{}, err
+	}
+
+	repository.store.mutex.Lock()
+	defer repository.store.mutex.Unlock()
+
+	if _, exist := repository.store.users[task.UserID]; !exist {
+		err = errors.Errorf("user %d doesn't exist", task.UserID)
+		repository.logger.Debugw(
+			`Repositories -> Memory -> TasksRepository -> Create`,
+			"error", err.Error(),
+		)
+		return models.Task{}, err
+	}
+
+	repository.store.lastTaskID++
+	task.ID = repository.store.lastTaskID
+
+	row := taskRow(task)
+	row.CreatedAt = time.Now()
+	repository.store.tasks[task.ID] = row
+
+	return task, nil
+}
+
+// CreateMany (ctx, tasks) -> creates the tasks with their tags, nothing is created if any task can't be created
+func (repository *TasksRepository) CreateMany(ctx context.Context, tasks []models.Task) ([]models.Task, error) {
+	err := contextError(ctx)
+	if err != nil {
+		return []models.Task{}, err
+	}
+
+	repository.store.mutex.Lock()
+	defer repository.store.mutex.Unlock()
+
+	for _, task := range tasks {
+		if _, exist := repository.store.users[task.UserID]; !exist {
+			err = errors.Errorf("user %d doesn't exist", task.UserID)
+			repository.logger.Debugw(
+				`Repositories -> Memory -> TasksRepository -> CreateMany`,
+				"error", err.Error(),
+			)
+			return []models.Task{}, err
+		}
+	}
+
+	now := time.Now()
+	created := make([]models.Task, 0, len(tasks))
+	for _, task := range tasks {
+		repository.store.lastTaskID++
+		task.ID = repository.store.lastTaskID
+		task.CreatedAt = now
+
+		repository.store.tasks[task.ID] = taskRow(task)
+
+		//Повторяющиеся теги сохраняются один раз, как с ON CONFLICT DO NOTHING
+		var tags []string
+		for _, tag := range task.Tags {
+			if !containsString(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+		if len(tags) > 0 {
+			repository.store.tags[task.ID] = tags
+		}
+
+		created = append(created, task)
+	}
+
+	return created, nil
+}
+
+// selectTasks (match, less, limit) -> copies of the stored tasks, the mutex must be held.
+// Ties of less are ordered by ID, 0 limit means without limit
+func (repository *TasksRepository) selectTasks(match func(task models.Task) bool, less func(a, b models.Task) bool, limit uint) []models.Task {
+	var tasks []models.Task
+	for _, task := range repository.store.tasks {
+		if match(task) {
+			tasks = append(tasks, task)
+		}
+	}
+
+	sort.Slice(tasks, func(i, j int) bool {
+		if less(tasks[i], tasks[j]) {
+			return true
+		}
+		if less(tasks[j], tasks[i]) {
+			return false
+		}
+		return tasks[i].ID < tasks[j].ID
+	})
+
+	if limit > 0 && uint(len(tasks)) > limit {
+		tasks = tasks[:limit]
+	}
+
+	return tasks
+}
+
+// byDatetimeAndTitle -> datetime NULLS LAST, title
+func byDatetimeAndTitle(a, b models.Task) bool {
+	if !timeEqual(a.Datetime, b.Datetime) {
+		return timeBefore(a.Datetime, b.Datetime)
+	}
+
+	return a.Title < b.Title
+}
+
+func (repository *TasksRepository) SearchActiveByDatetimeForUser(ctx context.Context, from, to *time.Time, userID int64) ([]models.Task, error) {
+	if from == nil && to == nil {
+		err := fmt.Errorf(`"from" and "to" are empty`)
+		repository.logger.Debugw(
+			`Repositories -> Memory -> TasksRepository -> SearchActiveByDatetimeForUser`,
+			"error", err.Error(),
+		)
+		return []models.Task{}, err
+	}
+
+	err := contextError(ctx)
+	if err != nil {
+		return []models.Task{}, err
+	}
+
+	repository.store.mutex.Lock()
+	defer repository.store.mutex.Unlock()
+
+	return repository.selectTasks(func(task models.Task) bool {
+		if task.Done || task.UserID != userID || task.Datetime == nil {
+			return false
+		}
+		if from != nil && task.Datetime.Before(*from) {
+			return false
+		}
+		if to != nil && task.Datetime.After(*to) {
+			return false
+		}
+		return true
+	}, byDatetimeAndTitle, 0), nil
+}
+
+// GetPageForUser (ctx, userID, afterTaskID, limit) -> active and completed tasks with ID greater than afterTaskID, by ID
+func (repository *TasksRepository) GetPageForUser(ctx context.Context, userID, afterTaskID int64, limit uint) ([]models.Task, error) {
+	err := contextError(ctx)
+	if err != nil {
+		return []models.Task{}, err
+	}
+
+	repository.store.mutex.Lock()
+	defer repository.store.mutex.Unlock()
+
+	return repository.selectTasks(func(task models.Task) bool {
+		return task.UserID == userID && task.ID > afterTaskID
+	}, func(a, b models.Task) bool {
+		return false
+	}, limit), nil
+}
+
+func (repository *TasksRepository) GetAllActiveForUser(ctx context.Context, userID int64) ([]models.Task, error) {
+	err := contextError(ctx)
+	if err != nil {
+		return []models.Task{}, err
+	}
+
+	repository.store.mutex.Lock()
+	defer repository.store.mutex.Unlock()
+
+	return repository.selectTasks(func(task models.Task) bool {
+		return !task.Done && task.UserID == userID
+	}, byDatetimeAndTitle, 0), nil
+}
+
+func (repository *TasksRepository) Update(ctx context.Context, model models.Task) error {
+	err := contextError(ctx)
+	if err != nil {
+		return err
+	}
+
+	repository.store.mutex.Lock()
+	defer repository.store.mutex.Unlock()
+
+	stored, exist := repository.store.tasks[model.ID]
+	if !exist {
+		//UPDATE без подходящих строк не ошибка
+		return nil
+	}
+	if _, exist = repository.store.users[model.UserID]; !exist {
+		err = errors.Errorf("user %d doesn't exist", model.UserID)
+		repository.logger.Debugw(
+			`Repositories -> Memory -> TasksRepository -> Update`,
+			"error", err.Error(),
+		)
+		return err
+	}
+
+	row := taskRow(model)
+	row.CreatedAt = stored.CreatedAt
+	repository.store.tasks[model.ID] = row
+
+	return nil
+}
+
+func (repository *TasksRepository) DeleteByID(ctx context.Context, ID int64) error {
+	err := contextError(ctx)
+	if err != nil {
+		return err
+	}
+
+	repository.store.mutex.Lock()
+	defer repository.store.mutex.Unlock()
+
+	repository.store.deleteTask(ID)
+
+	return nil
+}
+
+func (repository *TasksRepository) DeleteCompleted(ctx context.Context) error {
+	err := contextError(ctx)
+	if err != nil {
+		return err
+	}
+
+	repository.store.mutex.Lock()
+	defer repository.store.mutex.Unlock()
+
+	for ID, task := range repository.store.tasks {
+		if task.Done {
+			repository.store.deleteTask(ID)
+		}
+	}
+
+	return nil
+}
+
+func (repository *TasksRepository) FindByID(ctx context.Context, ID int64) (models.Task, error) {
+	err := contextError(ctx)
+	if err != nil {
+		return models.Task{}, err
+	}
+
+	repository.store.mutex.Lock()
+	defer repository.store.mutex.Unlock()
+
+	task, exist := repository.store.tasks[ID]
+	if !exist {
+		repository.logger.Debugw(
+			`Repositories -> Memory -> TasksRepository -> FindByID`,
+			"error", types.ErrNotFound.Error(), "ID", ID,
+		)
+		return models.Task{}, types.ErrNotFound
+	}
+
+	return task, nil
+}
+
+func (repository *TasksRepository) GetActiveTasksWithoutDatetimeForUser(ctx context.Context, userID int64) ([]models.Task, error) {
+	err := contextError(ctx)
+	if err != nil {
+		return []models.Task{}, err
+	}
+
+	repository.store.mutex.Lock()
+	defer repository.store.mutex.Unlock()
+
+	return repository.selectTasks(func(task models.Task) bool {
+		return task.UserID == userID && !task.Done && task.Datetime == nil
+	}, byDatetimeAndTitle, 0), nil
+}
+
+// searchWords ("Купить молоко!") -> ["купить", "молоко"], only letters and digits are kept
+func searchWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// searchRank (task, queryWords) -> 0 if some word of the query doesn't start any word of the task.
+// Words of the title weigh more than words of the description, like weights A and B of search_vector.
+// Unlike full-text search of PostgreSQL, words aren't stemmed and stop words aren't dropped
+func searchRank(task models.Task, queryWords []string) float64 {
+	titleWords, descriptionWords := searchWords(task.Title), searchWords(task.Description)
+
+	var rank float64
+	for _, queryWord := range queryWords {
+		switch {
+		case hasWordWithPrefix(titleWords, queryWord):
+			rank += 1
+		case hasWordWithPrefix(descriptionWords, queryWord):
+			rank += 0.4
+		default:
+			return 0
+		}
+	}
+
+	return rank
+}
+
+func hasWordWithPrefix(words []string, prefix string) bool {
+	for _, word := range words {
+		if strings.HasPrefix(word, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Search (ctx, userID, query, filters) -> tasks with words starting with all words of the query, the most relevant first
+func (repository *TasksRepository) Search(ctx context.Context, userID int64, query string, filters types.TasksSearchFilters) ([]models.Task, error) {
+	queryWords := searchWords(query)
+	if len(queryWords) == 0 {
+		return []models.Task{}, nil
+	}
+
+	err := contextError(ctx)
+	if err != nil {
+		return []models.Task{}, err
+	}
+
+	repository.store.mutex.Lock()
+	defer repository.store.mutex.Unlock()
+
+	ranks := map[int64]float64{}
+	tasks := repository.selectTasks(func(task models.Task) bool {
+		if task.UserID != userID {
+			return false
+		}
+		if filters.Done != nil && task.Done != *filters.Done {
+			return false
+		}
+		if filters.From != nil && (task.Datetime == nil || task.Datetime.Before(*filters.From)) {
+			return false
+		}
+		if filters.To != nil && (task.Datetime == nil || task.Datetime.After(*filters.To)) {
+			return false
+		}
+
+		ranks[task.ID] = searchRank(task, queryWords)
+		return ranks[task.ID] > 0
+	}, func(a, b models.Task) bool {
+		if ranks[a.ID] != ranks[b.ID] {
+			return ranks[a.ID] > ranks[b.ID]
+		}
+		//Новые задачи первыми
+		return a.ID > b.ID
+	}, filters.Limit)
+
+	return tasks, nil
+}
+
+// activeOrderLess -> the (datetime NULLS LAST, title, id) order of active tasks
+func activeOrderLess(a, b types.TasksCursor) bool {
+	if !timeEqual(a.Datetime, b.Datetime) {
+		return timeBefore(a.Datetime, b.Datetime)
+	}
+	if a.Title != b.Title {
+		return a.Title < b.Title
+	}
+
+	return a.ID < b.ID
+}
+
+func taskCursor(task models.Task) types.TasksCursor {
+	return types.TasksCursor{Datetime: task.Datetime, Title: task.Title, ID: task.ID}
+}
+
+// GetActiveForUserPage (ctx, userID, page) -> keyset pagination over active tasks in the (datetime, title, id) order.
+// Tasks are always returned in ascending order, for page.Before too.
+func (repository *TasksRepository) GetActiveForUserPage(ctx context.Context, userID int64, page types.TasksPageParams) ([]models.Task, error) {
+	err := contextError(ctx)
+	if err != nil {
+		return []models.Task{}, err
+	}
+
+	repository.store.mutex.Lock()
+	defer repository.store.mutex.Unlock()
+
+	cursor, backward := page.After, false
+	if page.Before != nil {
+		cursor, backward = page.Before, true
+	}
+
+	tasks := repository.selectTasks(func(task models.Task) bool {
+		if task.Done || task.UserID != userID {
+			return false
+		}
+		if cursor == nil {
+			return true
+		}
+		if backward {
+			return activeOrderLess(taskCursor(task), *cursor)
+		}
+		return activeOrderLess(*cursor, taskCursor(task))
+	}, func(a, b models.Task) bool {
+		if backward {
+			return activeOrderLess(taskCursor(b), taskCursor(a))
+		}
+		return activeOrderLess(taskCursor(a), taskCursor(b))
+	}, page.Limit)
+
+	if backward {
+		for i, j := 0, len(tasks)-1; i < j; i, j = i+1, j-1 {
+			tasks[i], tasks[j] = tasks[j], tasks[i]
+		}
+	}
+
+	return tasks, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchesFilter (task, filter) -> all set conditions of the filter hold for the task, the mutex must be held
+func (repository *TasksRepository) matchesFilter(task models.Task, filter types.TasksFilter, textWords []string) bool {
+	if filter.Done != nil && task.Done != *filter.Done {
+		return false
+	}
+	if filter.DueFrom != nil && (task.Datetime == nil || task.Datetime.Before(*filter.DueFrom)) {
+		return false
+	}
+	if filter.DueTo != nil && (task.Datetime == nil || task.Datetime.After(*filter.DueTo)) {
+		return false
+	}
+	if filter.WithoutDue && task.Datetime != nil {
+		return false
+	}
+	if filter.WithDue && task.Datetime == nil {
+		return false
+	}
+
+	tags := repository.store.tags[task.ID]
+	for _, tag := range filter.Tags {
+		if !containsString(tags, tag) {
+			return false
+		}
+	}
+	for _, tag := range filter.ExcludedTags {
+		if containsString(tags, tag) {
+			return false
+		}
+	}
+
+	if filter.PriorityFrom != nil && task.Priority < *filter.PriorityFrom {
+		return false
+	}
+	if filter.PriorityTo != nil && task.Priority > *filter.PriorityTo {
+		return false
+	}
+	if len(textWords) > 0 && searchRank(task, textWords) == 0 {
+		return false
+	}
+
+	return true
+}
+
+// sortPriority (priority) -> tasks without priority go after all others
+func sortPriority(priority int) int {
+	if priority == 0 {
+		return 32767
+	}
+
+	return priority
+}
+
+// Filter (ctx, userID, filter) -> tasks matching all conditions of the filter
+func (repository *TasksRepository) Filter(ctx context.Context, userID int64, filter types.TasksFilter) ([]models.Task, error) {
+	err := contextError(ctx)
+	if err != nil {
+		return []models.Task{}, err
+	}
+
+	repository.store.mutex.Lock()
+	defer repository.store.mutex.Unlock()
+
+	var less func(a, b models.Task) bool
+	switch filter.SortBy {
+	case types.TasksSortByCreated:
+		less = func(a, b models.Task) bool { return a.CreatedAt.Before(b.CreatedAt) }
+	case types.TasksSortByTitle:
+		less = func(a, b models.Task) bool { return a.Title < b.Title }
+	case types.TasksSortByPriority:
+		less = func(a, b models.Task) bool { return sortPriority(a.Priority) < sortPriority(b.Priority) }
+	default:
+		less = func(a, b models.Task) bool { return timeBefore(a.Datetime, b.Datetime) }
+	}
+
+	textWords := searchWords(filter.Text)
+	tasks := repository.selectTasks(func(task models.Task) bool {
+		return task.UserID == userID && repository.matchesFilter(task, filter, textWords)
+	}, less, 0)
+
+	//ID тоже сортируется по убыванию
+	if filter.SortDesc {
+		for i, j := 0, len(tasks)-1; i < j; i, j = i+1, j-1 {
+			tasks[i], tasks[j] = tasks[j], tasks[i]
+		}
+	}
+	if filter.Limit > 0 && uint(len(tasks)) > filter.Limit {
+		tasks = tasks[:filter.Limit]
+	}
+
+	return tasks, nil
+}
+
+// taskDeadline (task) -> tasks without time are stored at 00:00 and are overdue only after the end of the day.
+// The store has no settings, so the time is in UTC like for users without settings
+func taskDeadline(task models.Task) time.Time {
+	datetime := task.Datetime.UTC()
+	if datetime.Hour() == 0 && datetime.Minute() == 0 && datetime.Second() == 0 && datetime.Nanosecond()/1000 == 0 {
+		return datetime.AddDate(0, 0, 1)
+	}
+
+	return datetime
+}
+
+// GetOverdue (ctx, now, filters) -> not completed tasks with the deadline before now, the most overdue first
+func (repository *TasksRepository) GetOverdue(ctx context.Context, now time.Time, filters types.TasksOverdueFilters) ([]models.Task, error) {
+	err := contextError(ctx)
+	if err != nil {
+		return []models.Task{}, err
+	}
+
+	repository.store.mutex.Lock()
+	defer repository.store.mutex.Unlock()
+
+	return repository.selectTasks(func(task models.Task) bool {
+		if task.Done || task.Datetime == nil || !taskDeadline(task).Before(now) {
+			return false
+		}
+		if filters.UserID != 0 && task.UserID != filters.UserID {
+			return false
+		}
+		//Зависимостей в хранилище нет, поэтому WithoutActiveBlockers ничего не отсеивает
+		if filters.WithoutBotBlocked && repository.store.isBotBlocked(task.UserID) {
+			return false
+		}
+		return true
+	}, func(a, b models.Task) bool {
+		return a.Datetime.Before(*b.Datetime)
+	}, 0), nil
+}
+
+// CountForUser (ctx, userID) -> numbers of active and completed tasks of the user
+func (repository *TasksRepository) CountForUser(ctx context.Context, userID int64) (int64, int64, error) {
+	err := contextError(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	repository.store.mutex.Lock()
+	defer repository.store.mutex.Unlock()
+
+	var active, completed int64
+	for _, task := range repository.store.tasks {
+		if task.UserID != userID {
+			continue
+		}
+		if task.Done {
+			completed++
+		} else {
+			active++
+		}
+	}
+
+	return active, completed, nil
+}
@@ -0,0 +1,339 @@
+package memory
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"sort"
+	"tg_todo_bot/src/models"
+	"tg_todo_bot/src/repositories/types"
+	"time"
+)
+
+type UsersRepository struct {
+	logger *zap.SugaredLogger
+	store  *Store
+}
+
+func NewUsersRepository(
+	logger *zap.SugaredLogger,
+	store *Store,
+) *UsersRepository {
+	return &UsersRepository{
+		logger: logger,
+		store:  store,
+	}
+}
+
+func (repository *UsersRepository) Create(ctx context.Context, user models.User) (models.User, error) {
+	err := contextError(ctx)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	repository.store.mutex.Lock()
+	defer repository.store.mutex.Unlock()
+
+	for _, stored := range repository.store.users {
+		//Нарушение уникальности telegram_id
+		if stored.TelegramID == user.TelegramID {
+			repository.logger.Debugw(
+				`Repositories -> Memory -> UsersRepository -> Create`,
+				"error", types.ErrAlreadyExist.Error(), "telegramID", user.TelegramID,
+			)
+			return models.User{}, types.ErrAlreadyExist
+		}
+	}
+
+	repository.store.lastUserID++
+	user.ID = repository.store.lastUserID
+
+	//Остальные колонки при создании не заполняются
+	repository.store.users[user.ID] = models.User{
+		ID:         user.ID,
+		TelegramID: user.TelegramID,
+		CreatedAt:  time.Now(),
+	}
+
+	return user, nil
+}
+
+// selectUsers (match) -> copies of the stored users by ID, the mutex must be held
+func (repository *UsersRepository) selectUsers(match func(user models.User) bool) []models.User {
+	var users []models.User
+	for _, user := range repository.store.users {
+		if match(user) {
+			users = append(users, user)
+		}
+	}
+
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].ID < users[j].ID
+	})
+
+	return users
+}
+
+func (repository *UsersRepository) FindByTelegramID(ctx context.Context, telegramID int64) (models.User, error) {
+	err := contextError(ctx)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	repository.store.mutex.Lock()
+	defer repository.store.mutex.Unlock()
+
+	for _, user := range repository.store.users {
+		if user.TelegramID == telegramID {
+			return user, nil
+		}
+	}
+
+	repository.logger.Debugw(
+		`Repositories -> Memory -> UsersRepository -> FindByTelegramID`,
+		"error", types.ErrNotFound.Error(), "telegramID", telegramID,
+	)
+	return models.User{}, types.ErrNotFound
+}
+
+func (repository *UsersRepository) FindByID(ctx context.Context, ID int64) (models.User, error) {
+	err := contextError(ctx)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	repository.store.mutex.Lock()
+	defer repository.store.mutex.Unlock()
+
+	user, exist := repository.store.users[ID]
+	if !exist {
+		repository.logger.Debugw(
+			`Repositories -> Memory -> UsersRepository -> FindByID`,
+			"error", types.ErrNotFound.Error(), "ID", ID,
+		)
+		return models.User{}, types.ErrNotFound
+	}
+
+	return user, nil
+}
+
+// deleteUser -> the user with its tasks and outbox messages, the mutex must be held.
+// Returns the number of deleted rows by table
+func (repository *UsersRepository) deleteUser(userID int64) map[string]int64 {
+	deleted := map[string]int64{"notifications": 0, "task_tags": 0, "tasks": 0, "outbox": 0, "users": 0}
+
+	if _, exist := repository.store.users[userID]; !exist {
+		return deleted
+	}
+
+	for taskID, task := range repository.store.tasks {
+		if task.UserID != userID {
+			continue
+		}
+		for _, notification := range repository.store.notifications {
+			if notification.TaskID == taskID {
+				deleted["notifications"]++
+			}
+		}
+		deleted["task_tags"] += int64(len(repository.store.tags[taskID]))
+		deleted["tasks"]++
+
+		repository.store.deleteTask(taskID)
+	}
+
+	for dedupKey, message := range repository.store.outbox {
+		if message.UserID == userID {
+			delete(repository.store.outbox, dedupKey)
+			deleted["outbox"]++
+		}
+	}
+
+	delete(repository.store.users, userID)
+	deleted["users"]++
+
+	return deleted
+}
+
+func (repository *UsersRepository) DeleteByTelegramID(ctx context.Context, telegramID int64) error {
+	err := contextError(ctx)
+	if err != nil {
+		return err
+	}
+
+	repository.store.mutex.Lock()
+	defer repository.store.mutex.Unlock()
+
+	for ID, user := range repository.store.users {
+		if user.TelegramID == telegramID {
+			repository.deleteUser(ID)
+		}
+	}
+
+	return nil
+}
+
+// SetDeletionRequestedAt (ctx, userID, requestedAt) -> nil requestedAt cancels the deletion, types.ErrNotFound if there is no user
+func (repository *UsersRepository) SetDeletionRequestedAt(ctx context.Context, userID int64, requestedAt *time.Time) error {
+	return repository.update(ctx, "SetDeletionRequestedAt", userID, func(user *models.User) {
+		user.DeletionRequestedAt = requestedAt
+	})
+}
+
+// GetDeletionRequestedBefore (ctx, requestedBefore) -> users who requested the deletion before the time, the oldest first
+func (repository *UsersRepository) GetDeletionRequestedBefore(ctx context.Context, requestedBefore time.Time) ([]models.User, error) {
+	err := contextError(ctx)
+	if err != nil {
+		return []models.User{}, err
+	}
+
+	repository.store.mutex.Lock()
+	defer repository.store.mutex.Unlock()
+
+	users := repository.selectUsers(func(user models.User) bool {
+		return user.DeletionRequestedAt != nil && !user.DeletionRequestedAt.After(requestedBefore)
+	})
+	sort.SliceStable(users, func(i, j int) bool {
+		return users[i].DeletionRequestedAt.Before(*users[j].DeletionRequestedAt)
+	})
+
+	return users, nil
+}
+
+// SetLastSeenAt (ctx, userID, seenAt) -> types.ErrNotFound if there is no user
+func (repository *UsersRepository) SetLastSeenAt(ctx context.Context, userID int64, seenAt time.Time) error {
+	return repository.update(ctx, "SetLastSeenAt", userID, func(user *models.User) {
+		user.LastSeenAt = &seenAt
+	})
+}
+
+// SetBotBlockedAt (ctx, userID, blockedAt) -> nil blockedAt makes the user reachable again,
+// types.ErrNotFound if there is no user
+func (repository *UsersRepository) SetBotBlockedAt(ctx context.Context, userID int64, blockedAt *time.Time) error {
+	return repository.update(ctx, "SetBotBlockedAt", userID, func(user *models.User) {
+		user.BotBlockedAt = blockedAt
+	})
+}
+
+// SetBan (ctx, userID, bannedAt, reason) -> nil bannedAt unbans the user and clears the reason,
+// types.ErrNotFound if there is no user
+func (repository *UsersRepository) SetBan(ctx context.Context, userID int64, bannedAt *time.Time, reason string) error {
+	if bannedAt == nil {
+		reason = ""
+	}
+
+	return repository.update(ctx, "SetBan", userID, func(user *models.User) {
+		user.BannedAt = bannedAt
+		user.BanReason = reason
+	})
+}
+
+func (repository *UsersRepository) update(ctx context.Context, method string, userID int64, change func(user *models.User)) error {
+	err := contextError(ctx)
+	if err != nil {
+		return err
+	}
+
+	repository.store.mutex.Lock()
+	defer repository.store.mutex.Unlock()
+
+	user, exist := repository.store.users[userID]
+	if !exist {
+		repository.logger.Debugw(
+			`Repositories -> Memory -> UsersRepository -> `+method,
+			"error", types.ErrNotFound.Error(), "userID", userID,
+		)
+		return types.ErrNotFound
+	}
+
+	change(&user)
+	repository.store.users[userID] = user
+
+	return nil
+}
+
+// GetPage (ctx, afterUserID, limit) -> users with ID greater than afterUserID, by ID
+func (repository *UsersRepository) GetPage(ctx context.Context, afterUserID int64, limit uint) ([]models.User, error) {
+	err := contextError(ctx)
+	if err != nil {
+		return []models.User{}, err
+	}
+
+	repository.store.mutex.Lock()
+	defer repository.store.mutex.Unlock()
+
+	users := repository.selectUsers(func(user models.User) bool {
+		return user.ID > afterUserID
+	})
+	if limit > 0 && uint(len(users)) > limit {
+		users = users[:limit]
+	}
+
+	return users, nil
+}
+
+// Count (ctx, filter) -> number of users matching the filter
+func (repository *UsersRepository) Count(ctx context.Context, filter types.UsersCountFilter) (int64, error) {
+	err := contextError(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	repository.store.mutex.Lock()
+	defer repository.store.mutex.Unlock()
+
+	users := repository.selectUsers(func(user models.User) bool {
+		if filter.CreatedFrom != nil && user.CreatedAt.Before(*filter.CreatedFrom) {
+			return false
+		}
+		if filter.SeenFrom != nil && (user.LastSeenAt == nil || user.LastSeenAt.Before(*filter.SeenFrom)) {
+			return false
+		}
+		if filter.Banned && user.BannedAt == nil {
+			return false
+		}
+		if filter.DeletionRequested && user.DeletionRequestedAt == nil {
+			return false
+		}
+		if filter.BotBlocked && user.BotBlockedAt == nil {
+			return false
+		}
+		return true
+	})
+
+	return int64(len(users)), nil
+}
+
+// Purge (ctx, userID) -> deletes the user and all rows of the user, returns the number of deleted rows
+// by table. Only tables kept by the store are in the result. ErrNotFound if there is no such user
+func (repository *UsersRepository) Purge(ctx context.Context, userID int64) (map[string]int64, error) {
+	return repository.purge(ctx, userID, nil)
+}
+
+// PurgeRequestedBefore (ctx, userID, requestedBefore) -> like Purge, but only if the user requested the deletion
+// before the time. ErrNotFound if there is no such user or the deletion isn't requested anymore
+func (repository *UsersRepository) PurgeRequestedBefore(ctx context.Context, userID int64, requestedBefore time.Time) (map[string]int64, error) {
+	return repository.purge(ctx, userID, &requestedBefore)
+}
+
+func (repository *UsersRepository) purge(ctx context.Context, userID int64, requestedBefore *time.Time) (map[string]int64, error) {
+	err := contextError(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	repository.store.mutex.Lock()
+	defer repository.store.mutex.Unlock()
+
+	if requestedBefore != nil {
+		user, exist := repository.store.users[userID]
+		if !exist || user.DeletionRequestedAt == nil || user.DeletionRequestedAt.After(*requestedBefore) {
+			return nil, types.ErrNotFound
+		}
+	}
+
+	deleted := repository.deleteUser(userID)
+	if deleted["users"] == 0 {
+		return nil, types.ErrNotFound
+	}
+
+	return deleted, nil
+}
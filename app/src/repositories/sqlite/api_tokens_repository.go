@@ -0,0 +1,136 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"go.uber.org/zap"
+	"tg_todo_bot/src/models"
+	"time"
+)
+
+type ApiTokensRepository struct {
+	logger       *zap.SugaredLogger
+	dbInstance   querier
+	queryTimeout time.Duration
+}
+
+func NewApiTokensRepository(
+	logger *zap.SugaredLogger,
+	dbInstance *sql.DB,
+	queryTimeout time.Duration,
+) *ApiTokensRepository {
+	return &ApiTokensRepository{
+		logger:       logger,
+		dbInstance:   dbInstance,
+		queryTimeout: queryTimeout,
+	}
+}
+
+// Save (ctx, token) -> the user has one token per scope, the new one replaces the previous
+func (repository *ApiTokensRepository) Save(ctx context.Context, token models.ApiToken) (models.ApiToken, error) {
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	query := dialect().
+		Insert("api_tokens").
+		Rows(
+			goqu.Record{
+				"user_id":    token.UserID,
+				"scope":      token.Scope,
+				"token_hash": token.TokenHash,
+				"created_at": timestamp(time.Now()),
+			},
+		).
+		OnConflict(
+			goqu.DoUpdate("user_id, scope", goqu.Record{
+				"token_hash": goqu.I("excluded.token_hash"),
+				"created_at": goqu.I("excluded.created_at"),
+			}),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	_, err := repository.dbInstance.ExecContext(ctx, sql, args...)
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> ApiTokensRepository -> Save -> repository.dbInstance.ExecContext(sql, args...)`,
+			"error", err.Error(), "SQL", sql,
+		)
+		return models.ApiToken{}, err
+	}
+
+	//Заменённый токен сохраняет ID прежнего
+	return repository.findOne(ctx, "Save", goqu.Ex{"user_id": token.UserID, "scope": token.Scope})
+}
+
+func (repository *ApiTokensRepository) FindByHash(ctx context.Context, tokenHash string) (models.ApiToken, error) {
+	return repository.findOne(ctx, "FindByHash", goqu.Ex{"token_hash": tokenHash})
+}
+
+// findOne (ctx, method, where) -> the token matching where, errors are logged for the method without arguments,
+// they have the hash of the token
+func (repository *ApiTokensRepository) findOne(ctx context.Context, method string, where exp.Ex) (models.ApiToken, error) {
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	query := dialect().
+		From("api_tokens").
+		Select(
+			goqu.C("id"),
+			goqu.C("user_id"),
+			goqu.C("scope"),
+			goqu.C("token_hash"),
+			goqu.C("created_at"),
+		).
+		Where(where)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	var token models.ApiToken
+	err := repository.dbInstance.QueryRowContext(ctx, sql, args...).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.Scope,
+		&token.TokenHash,
+		timeScanner{dest: &token.CreatedAt},
+	)
+	if err != nil {
+		err = queryError(ctx, notFoundError(err))
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> ApiTokensRepository -> `+method+` -> row.Scan()`,
+			"error", err.Error(), "SQL", sql,
+		)
+		return models.ApiToken{}, err
+	}
+
+	return token, nil
+}
+
+func (repository *ApiTokensRepository) DeleteForUser(ctx context.Context, userID int64, scope string) error {
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	query := dialect().
+		Delete("api_tokens").
+		Where(
+			goqu.C("user_id").Eq(userID),
+			goqu.C("scope").Eq(scope),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	_, err := repository.dbInstance.ExecContext(ctx, sql, args...)
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> ApiTokensRepository -> DeleteForUser -> repository.dbInstance.ExecContext(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,291 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"tg_todo_bot/src/models"
+	"tg_todo_bot/src/repositories/types"
+	"time"
+)
+
+type BroadcastsRepository struct {
+	logger       *zap.SugaredLogger
+	dbInstance   querier
+	queryTimeout time.Duration
+}
+
+func NewBroadcastsRepository(
+	logger *zap.SugaredLogger,
+	dbInstance *sql.DB,
+	queryTimeout time.Duration,
+) *BroadcastsRepository {
+	return &BroadcastsRepository{
+		logger:       logger,
+		dbInstance:   dbInstance,
+		queryTimeout: queryTimeout,
+	}
+}
+
+func (repository *BroadcastsRepository) Create(ctx context.Context, broadcast models.Broadcast) (models.Broadcast, error) {
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	now := time.Now()
+	query := dialect().
+		Insert("broadcasts").
+		Rows(
+			goqu.Record{
+				"text":       broadcast.Text,
+				"status":     broadcast.Status,
+				"created_at": timestamp(now),
+			},
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	result, err := repository.dbInstance.ExecContext(ctx, sql, args...)
+	if err == nil {
+		broadcast.ID, err = result.LastInsertId()
+	}
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> BroadcastsRepository -> Create -> repository.dbInstance.ExecContext(sql, args...)`,
+			"error", err.Error(), "SQL", sql,
+		)
+		return models.Broadcast{}, err
+	}
+	broadcast.CreatedAt = now
+
+	return broadcast, nil
+}
+
+func (repository *BroadcastsRepository) selectAllCols() *goqu.SelectDataset {
+	return dialect().
+		From("broadcasts").
+		Select(
+			goqu.C("id"),
+			goqu.C("text"),
+			goqu.C("status"),
+			goqu.C("last_user_id"),
+			goqu.C("sent"),
+			goqu.C("failed"),
+			goqu.C("created_at"),
+			goqu.C("finished_at"),
+		)
+}
+
+func scanBroadcast(row scanner) (models.Broadcast, error) {
+	var broadcast models.Broadcast
+	err := row.Scan(
+		&broadcast.ID,
+		&broadcast.Text,
+		&broadcast.Status,
+		&broadcast.LastUserID,
+		&broadcast.Sent,
+		&broadcast.Failed,
+		timeScanner{dest: &broadcast.CreatedAt},
+		nullTimeScanner{dest: &broadcast.FinishedAt},
+	)
+
+	return broadcast, err
+}
+
+func (repository *BroadcastsRepository) FindByID(ctx context.Context, broadcastID int64) (models.Broadcast, error) {
+	query := repository.selectAllCols().
+		Where(
+			goqu.C("id").Eq(broadcastID),
+		)
+
+	return repository.findOne(ctx, "FindByID", query)
+}
+
+// FindLatest -> the last created broadcast, types.ErrNotFound if there were none
+func (repository *BroadcastsRepository) FindLatest(ctx context.Context) (models.Broadcast, error) {
+	query := repository.selectAllCols().
+		Order(
+			goqu.C("id").Desc(),
+		).
+		Limit(1)
+
+	return repository.findOne(ctx, "FindLatest", query)
+}
+
+func (repository *BroadcastsRepository) findOne(ctx context.Context, method string, query *goqu.SelectDataset) (models.Broadcast, error) {
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	broadcast, err := scanBroadcast(repository.dbInstance.QueryRowContext(ctx, sql, args...))
+	if err != nil {
+		err = queryError(ctx, notFoundError(err))
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> BroadcastsRepository -> `+method+` -> row.Scan()`,
+			"error", err.Error(),
+		)
+		return models.Broadcast{}, err
+	}
+
+	return broadcast, nil
+}
+
+// Claim (ctx, status, now, lease) -> the oldest broadcast in the status which nobody sends, it's leased till
+// now+lease. types.ErrNotFound if there is none. The broadcast is read and leased in one transaction,
+// SQLite has only one writer at a time
+func (repository *BroadcastsRepository) Claim(ctx context.Context, status string, now time.Time, lease time.Duration) (models.Broadcast, error) {
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	tx, err := begin(ctx, repository.dbInstance)
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> BroadcastsRepository -> Claim -> begin(ctx, repository.dbInstance)`,
+			"error", err.Error(),
+		)
+		return models.Broadcast{}, err
+	}
+	//После Commit откат ничего не делает
+	defer tx.Rollback()
+
+	query := repository.selectAllCols().
+		Where(
+			goqu.C("status").Eq(status),
+			goqu.Or(
+				goqu.C("locked_until").IsNull(),
+				goqu.C("locked_until").Lte(timestamp(now)),
+			),
+		).
+		Order(
+			goqu.C("id").Asc(),
+		).
+		Limit(1)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	broadcast, err := scanBroadcast(tx.QueryRowContext(ctx, sql, args...))
+	if err != nil {
+		err = queryError(ctx, notFoundError(err))
+		if !errors.Is(err, types.ErrNotFound) {
+			repository.logger.Debugw(
+				`Repositories -> SQLite -> BroadcastsRepository -> Claim -> row.Scan()`,
+				"error", err.Error(), "SQL", sql, "args", args,
+			)
+		}
+		return models.Broadcast{}, err
+	}
+
+	leaseQuery := dialect().
+		Update("broadcasts").
+		Set(
+			goqu.Record{
+				"locked_until": timestamp(now.Add(lease)),
+			},
+		).
+		Where(
+			goqu.C("id").Eq(broadcast.ID),
+		)
+
+	sql, args, _ = leaseQuery.Prepared(true).ToSQL()
+
+	_, err = tx.ExecContext(ctx, sql, args...)
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> BroadcastsRepository -> Claim -> tx.ExecContext(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return models.Broadcast{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> BroadcastsRepository -> Claim -> tx.Commit()`,
+			"error", err.Error(),
+		)
+		return models.Broadcast{}, err
+	}
+
+	return broadcast, nil
+}
+
+// UpdateProgress (ctx, broadcast, lockedUntil) -> saves LastUserID, Sent and Failed and extends the lease,
+// the status isn't touched, so a broadcast cancelled meanwhile stays cancelled
+func (repository *BroadcastsRepository) UpdateProgress(ctx context.Context, broadcast models.Broadcast, lockedUntil time.Time) error {
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	query := dialect().
+		Update("broadcasts").
+		Set(
+			goqu.Record{
+				"last_user_id": broadcast.LastUserID,
+				"sent":         broadcast.Sent,
+				"failed":       broadcast.Failed,
+				"locked_until": timestamp(lockedUntil),
+			},
+		).
+		Where(
+			goqu.C("id").Eq(broadcast.ID),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	_, err := repository.dbInstance.ExecContext(ctx, sql, args...)
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> BroadcastsRepository -> UpdateProgress -> repository.dbInstance.ExecContext(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return err
+	}
+
+	return nil
+}
+
+// UpdateStatus (ctx, broadcastID, fromStatuses, status, finishedAt) -> changes the status only if the current one
+// is one of fromStatuses, types.ErrNotFound otherwise
+func (repository *BroadcastsRepository) UpdateStatus(ctx context.Context, broadcastID int64, fromStatuses []string, status string, finishedAt *time.Time) error {
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	query := dialect().
+		Update("broadcasts").
+		Set(
+			goqu.Record{
+				"status":      status,
+				"finished_at": nullTimestamp(finishedAt),
+			},
+		).
+		Where(
+			goqu.C("id").Eq(broadcastID),
+			goqu.C("status").In(fromStatuses),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	result, err := repository.dbInstance.ExecContext(ctx, sql, args...)
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> BroadcastsRepository -> UpdateStatus -> repository.dbInstance.ExecContext(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return err
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return types.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,166 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"github.com/doug-martin/goqu/v9"
+	"go.uber.org/zap"
+	"tg_todo_bot/src/models"
+	"time"
+)
+
+type CalDAVObjectsRepository struct {
+	logger       *zap.SugaredLogger
+	dbInstance   querier
+	queryTimeout time.Duration
+}
+
+func NewCalDAVObjectsRepository(
+	logger *zap.SugaredLogger,
+	dbInstance *sql.DB,
+	queryTimeout time.Duration,
+) *CalDAVObjectsRepository {
+	return &CalDAVObjectsRepository{
+		logger:       logger,
+		dbInstance:   dbInstance,
+		queryTimeout: queryTimeout,
+	}
+}
+
+// Save (ctx, object) -> creates the object or replaces the name and UID of the task's object
+func (repository *CalDAVObjectsRepository) Save(ctx context.Context, object models.CalDAVObject) error {
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	query := dialect().
+		Insert("caldav_objects").
+		Rows(
+			goqu.Record{
+				"task_id": object.TaskID,
+				"user_id": object.UserID,
+				"name":    object.Name,
+				"uid":     object.UID,
+			},
+		).
+		OnConflict(
+			goqu.DoUpdate("task_id", goqu.Record{
+				"name": goqu.I("excluded.name"),
+				"uid":  goqu.I("excluded.uid"),
+			}),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	_, err := repository.dbInstance.ExecContext(ctx, sql, args...)
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> CalDAVObjectsRepository -> Save -> repository.dbInstance.ExecContext(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return err
+	}
+
+	return nil
+}
+
+func (repository *CalDAVObjectsRepository) selectAllCols() *goqu.SelectDataset {
+	return dialect().
+		From("caldav_objects").
+		Select(
+			goqu.C("task_id"),
+			goqu.C("user_id"),
+			goqu.C("name"),
+			goqu.C("uid"),
+		)
+}
+
+func scanCalDAVObject(row scanner) (models.CalDAVObject, error) {
+	var object models.CalDAVObject
+	err := row.Scan(
+		&object.TaskID,
+		&object.UserID,
+		&object.Name,
+		&object.UID,
+	)
+
+	return object, err
+}
+
+func (repository *CalDAVObjectsRepository) FindByName(ctx context.Context, userID int64, name string) (models.CalDAVObject, error) {
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	query := repository.selectAllCols().
+		Where(
+			goqu.C("user_id").Eq(userID),
+			goqu.C("name").Eq(name),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	object, err := scanCalDAVObject(repository.dbInstance.QueryRowContext(ctx, sql, args...))
+	if err != nil {
+		err = queryError(ctx, notFoundError(err))
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> CalDAVObjectsRepository -> FindByName -> row.Scan()`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return models.CalDAVObject{}, err
+	}
+
+	return object, nil
+}
+
+func (repository *CalDAVObjectsRepository) FindByTasksIDs(ctx context.Context, tasksIDs []int64) (map[int64]models.CalDAVObject, error) {
+	if len(tasksIDs) == 0 {
+		return map[int64]models.CalDAVObject{}, nil
+	}
+
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	query := repository.selectAllCols().
+		Where(
+			goqu.C("task_id").In(tasksIDs),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	rows, err := repository.dbInstance.QueryContext(ctx, sql, args...)
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> CalDAVObjectsRepository -> FindByTasksIDs -> repository.dbInstance.QueryContext(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return map[int64]models.CalDAVObject{}, err
+	}
+	defer rows.Close()
+
+	objects := map[int64]models.CalDAVObject{}
+	for rows.Next() {
+		object, err := scanCalDAVObject(rows)
+		if err != nil {
+			repository.logger.Debugw(
+				`Repositories -> SQLite -> CalDAVObjectsRepository -> FindByTasksIDs -> rows.Scan()`,
+				"error", err.Error(),
+			)
+			return map[int64]models.CalDAVObject{}, err
+		}
+
+		objects[object.TaskID] = object
+	}
+
+	err = rows.Err()
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> CalDAVObjectsRepository -> FindByTasksIDs -> rows.Err()`,
+			"error", err.Error(),
+		)
+		return map[int64]models.CalDAVObject{}, err
+	}
+
+	return objects, nil
+}
@@ -0,0 +1,55 @@
+package sqlite
+
+import (
+	"database/sql"
+	"go.uber.org/zap"
+	"testing"
+	"tg_todo_bot/kernel/db"
+	"tg_todo_bot/kernel/migrate"
+	"tg_todo_bot/src/repositories/contract"
+)
+
+// openMigrated -> a new in-memory database with all migrations of the SQLite schema
+func openMigrated(t *testing.T) *sql.DB {
+	dbInstance, err := db.NewSQLite(":memory:").Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		dbInstance.Close()
+	})
+
+	m, err := migrate.NewSQLiteMigrateInstance(dbInstance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = m.Up()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return dbInstance
+}
+
+func getRepositories(t *testing.T) contract.Repositories {
+	logger := zap.NewNop().Sugar()
+	dbInstance := openMigrated(t)
+
+	return contract.Repositories{
+		Tasks:         NewTasksRepository(logger, dbInstance, 0),
+		Notifications: NewNotificationsRepository(logger, dbInstance, 0),
+		Users:         NewUsersRepository(logger, dbInstance, 0),
+	}
+}
+
+func TestTasksRepositoryContract(t *testing.T) {
+	contract.TestTasksRepository(t, getRepositories(t))
+}
+
+func TestNotificationsRepositoryContract(t *testing.T) {
+	contract.TestNotificationsRepository(t, getRepositories(t))
+}
+
+func TestUsersRepositoryContract(t *testing.T) {
+	contract.TestUsersRepository(t, getRepositories(t))
+}
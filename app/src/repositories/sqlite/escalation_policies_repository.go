@@ -0,0 +1,232 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"github.com/doug-martin/goqu/v9"
+	"go.uber.org/zap"
+	"tg_todo_bot/src/models"
+	"time"
+)
+
+type EscalationPoliciesRepository struct {
+	logger       *zap.SugaredLogger
+	dbInstance   querier
+	queryTimeout time.Duration
+}
+
+func NewEscalationPoliciesRepository(
+	logger *zap.SugaredLogger,
+	dbInstance *sql.DB,
+	queryTimeout time.Duration,
+) *EscalationPoliciesRepository {
+	return &EscalationPoliciesRepository{
+		logger:       logger,
+		dbInstance:   dbInstance,
+		queryTimeout: queryTimeout,
+	}
+}
+
+// Save (ctx, policy) -> replaces the user's default policy or the task's policy if TaskID is set
+func (repository *EscalationPoliciesRepository) Save(ctx context.Context, policy models.EscalationPolicy) (models.EscalationPolicy, error) {
+	var err error
+	if policy.TaskID != nil {
+		err = repository.DeleteForTask(ctx, *policy.TaskID)
+	} else {
+		err = repository.DeleteForUser(ctx, policy.UserID)
+	}
+	if err != nil {
+		return models.EscalationPolicy{}, err
+	}
+
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	now := time.Now()
+	query := dialect().
+		Insert("escalation_policies").
+		Rows(
+			goqu.Record{
+				"user_id":         policy.UserID,
+				"task_id":         policy.TaskID,
+				"overdue_after":   policy.OverdueAfter,
+				"repeat_interval": policy.RepeatInterval,
+				"max_reminders":   policy.MaxReminders,
+				"created_at":      timestamp(now),
+			},
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	result, err := repository.dbInstance.ExecContext(ctx, sql, args...)
+	if err == nil {
+		policy.ID, err = result.LastInsertId()
+	}
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> EscalationPoliciesRepository -> Save -> repository.dbInstance.ExecContext(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return models.EscalationPolicy{}, err
+	}
+	policy.CreatedAt = now
+
+	return policy, nil
+}
+
+func (repository *EscalationPoliciesRepository) selectAllCols() *goqu.SelectDataset {
+	return dialect().
+		From("escalation_policies").
+		Select(
+			goqu.C("id"),
+			goqu.C("user_id"),
+			goqu.C("task_id"),
+			goqu.C("overdue_after"),
+			goqu.C("repeat_interval"),
+			goqu.C("max_reminders"),
+			goqu.C("created_at"),
+		)
+}
+
+func (repository *EscalationPoliciesRepository) findAll(ctx context.Context, query *goqu.SelectDataset) ([]models.EscalationPolicy, error) {
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	rows, err := repository.dbInstance.QueryContext(ctx, sql, args...)
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> EscalationPoliciesRepository -> findAll -> repository.dbInstance.QueryContext(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return []models.EscalationPolicy{}, err
+	}
+	defer rows.Close()
+
+	var policies []models.EscalationPolicy
+	for rows.Next() {
+		var policy models.EscalationPolicy
+		err = rows.Scan(
+			&policy.ID,
+			&policy.UserID,
+			&policy.TaskID,
+			&policy.OverdueAfter,
+			&policy.RepeatInterval,
+			&policy.MaxReminders,
+			timeScanner{dest: &policy.CreatedAt},
+		)
+		if err != nil {
+			repository.logger.Debugw(
+				`Repositories -> SQLite -> EscalationPoliciesRepository -> findAll -> rows.Scan()`,
+				"error", err.Error(),
+			)
+			return []models.EscalationPolicy{}, err
+		}
+
+		policies = append(policies, policy)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> EscalationPoliciesRepository -> findAll -> rows.Err()`,
+			"error", err.Error(),
+		)
+		return []models.EscalationPolicy{}, err
+	}
+
+	return policies, nil
+}
+
+// FindForUsers (ctx, usersIDs) -> return map[UserID]DefaultPolicy
+func (repository *EscalationPoliciesRepository) FindForUsers(ctx context.Context, usersIDs []int64) (map[int64]models.EscalationPolicy, error) {
+	if len(usersIDs) == 0 {
+		return map[int64]models.EscalationPolicy{}, nil
+	}
+
+	policies, err := repository.findAll(ctx, repository.selectAllCols().
+		Where(
+			goqu.C("user_id").In(usersIDs),
+			goqu.C("task_id").IsNull(),
+		),
+	)
+	if err != nil {
+		return map[int64]models.EscalationPolicy{}, err
+	}
+
+	usersPoliciesMap := map[int64]models.EscalationPolicy{}
+	for _, policy := range policies {
+		usersPoliciesMap[policy.UserID] = policy
+	}
+
+	return usersPoliciesMap, nil
+}
+
+// FindForTasks (ctx, tasksIDs) -> return map[TaskID]Policy, only for tasks with their own policy
+func (repository *EscalationPoliciesRepository) FindForTasks(ctx context.Context, tasksIDs []int64) (map[int64]models.EscalationPolicy, error) {
+	if len(tasksIDs) == 0 {
+		return map[int64]models.EscalationPolicy{}, nil
+	}
+
+	policies, err := repository.findAll(ctx, repository.selectAllCols().
+		Where(
+			goqu.C("task_id").In(tasksIDs),
+		),
+	)
+	if err != nil {
+		return map[int64]models.EscalationPolicy{}, err
+	}
+
+	tasksPoliciesMap := map[int64]models.EscalationPolicy{}
+	for _, policy := range policies {
+		tasksPoliciesMap[*policy.TaskID] = policy
+	}
+
+	return tasksPoliciesMap, nil
+}
+
+// DeleteForUser (ctx, userID) -> deletes the user's default policy, policies of tasks stay
+func (repository *EscalationPoliciesRepository) DeleteForUser(ctx context.Context, userID int64) error {
+	query := dialect().
+		Delete("escalation_policies").
+		Where(
+			goqu.C("user_id").Eq(userID),
+			goqu.C("task_id").IsNull(),
+		)
+
+	return repository.exec(ctx, "DeleteForUser", query.Prepared(true))
+}
+
+func (repository *EscalationPoliciesRepository) DeleteForTask(ctx context.Context, taskID int64) error {
+	query := dialect().
+		Delete("escalation_policies").
+		Where(
+			goqu.C("task_id").Eq(taskID),
+		)
+
+	return repository.exec(ctx, "DeleteForTask", query.Prepared(true))
+}
+
+// exec (ctx, method, query) -> runs the statement, errors are logged for the method
+func (repository *EscalationPoliciesRepository) exec(ctx context.Context, method string, query *goqu.DeleteDataset) error {
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	sql, args, _ := query.ToSQL()
+
+	_, err := repository.dbInstance.ExecContext(ctx, sql, args...)
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> EscalationPoliciesRepository -> `+method+` -> repository.dbInstance.ExecContext(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return err
+	}
+
+	return nil
+}
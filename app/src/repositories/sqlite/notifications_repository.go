@@ -0,0 +1,362 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"github.com/doug-martin/goqu/v9"
+	"go.uber.org/zap"
+	"tg_todo_bot/src/models"
+	"tg_todo_bot/src/repositories/types"
+	"time"
+)
+
+type NotificationsRepository struct {
+	logger       *zap.SugaredLogger
+	dbInstance   querier
+	queryTimeout time.Duration
+}
+
+func NewNotificationsRepository(
+	logger *zap.SugaredLogger,
+	dbInstance *sql.DB,
+	queryTimeout time.Duration,
+) *NotificationsRepository {
+	return &NotificationsRepository{
+		logger:       logger,
+		dbInstance:   dbInstance,
+		queryTimeout: queryTimeout,
+	}
+}
+
+func (repository *NotificationsRepository) Create(ctx context.Context, notification models.Notification) (models.Notification, error) {
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	query := dialect().
+		Insert("notifications").
+		Rows(
+			goqu.Record{
+				"task_id":         notification.TaskID,
+				"notify_at":       timestamp(notification.NotifyAt),
+				"repeat_interval": notification.RepeatInterval,
+				"created_at":      timestamp(time.Now()),
+			},
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	result, err := repository.dbInstance.ExecContext(ctx, sql, args...)
+	if err == nil {
+		notification.ID, err = result.LastInsertId()
+	}
+	if err != nil {
+		//Нарушение уникальности
+		if isUniqueViolation(err) {
+			err = types.ErrAlreadyExist
+		}
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> NotificationsRepository -> Create -> repository.dbInstance.ExecContext(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return models.Notification{}, err
+	}
+
+	return notification, nil
+}
+
+func (repository *NotificationsRepository) Update(ctx context.Context, notification models.Notification) error {
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	query := dialect().
+		Update("notifications").
+		Set(
+			goqu.Record{
+				"task_id":         notification.TaskID,
+				"notify_at":       timestamp(notification.NotifyAt),
+				"repeat_interval": notification.RepeatInterval,
+			},
+		).
+		Where(
+			goqu.C("id").Eq(notification.ID),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	_, err := repository.dbInstance.ExecContext(ctx, sql, args...)
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> NotificationsRepository -> Update -> repository.dbInstance.ExecContext(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return err
+	}
+
+	return nil
+}
+
+// Fire (ctx, notification, nextNotifyAt, message) -> moves the notification to nextNotifyAt and saves the reminder
+// to the outbox in one transaction. The notification is moved only from notification.NotifyAt:
+// types.ErrNotFound if it's deleted or changed meanwhile
+func (repository *NotificationsRepository) Fire(ctx context.Context, notification models.Notification, nextNotifyAt time.Time, message models.OutboxMessage) error {
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	tx, err := begin(ctx, repository.dbInstance)
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> NotificationsRepository -> Fire -> begin(ctx, repository.dbInstance)`,
+			"error", err.Error(),
+		)
+		return err
+	}
+	//После Commit откат ничего не делает
+	defer tx.Rollback()
+
+	query := dialect().
+		Update("notifications").
+		Set(
+			goqu.Record{
+				"notify_at": timestamp(nextNotifyAt),
+			},
+		).
+		Where(
+			goqu.C("id").Eq(notification.ID),
+			goqu.C("notify_at").Eq(timestamp(notification.NotifyAt)),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	result, err := tx.ExecContext(ctx, sql, args...)
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> NotificationsRepository -> Fire -> tx.ExecContext(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return err
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return types.ErrNotFound
+	}
+
+	outboxQuery := dialect().
+		Insert("outbox").
+		Rows(
+			goqu.Record{
+				"user_id":         message.UserID,
+				"kind":            message.Kind,
+				"dedup_key":       message.DedupKey,
+				"payload":         string(message.Payload),
+				"next_attempt_at": nullTimestamp(message.NextAttemptAt),
+				"created_at":      timestamp(time.Now()),
+			},
+		).
+		OnConflict(goqu.DoNothing())
+
+	sql, args, _ = outboxQuery.Prepared(true).ToSQL()
+
+	result, err = tx.ExecContext(ctx, sql, args...)
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> NotificationsRepository -> Fire -> tx.ExecContext(sql, args...)`,
+			"error", err.Error(), "dedupKey", message.DedupKey,
+		)
+		return err
+	}
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if inserted == 0 {
+		//Такое срабатывание уже в outbox, напоминание всё равно переносится
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> NotificationsRepository -> Fire -> the message is already in the outbox`,
+			"dedupKey", message.DedupKey,
+		)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> NotificationsRepository -> Fire -> tx.Commit()`,
+			"error", err.Error(),
+		)
+		return err
+	}
+
+	return nil
+}
+
+func (repository *NotificationsRepository) DeleteByID(ctx context.Context, ID int64) error {
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	query := dialect().
+		Delete("notifications").
+		Where(
+			goqu.C("id").Eq(ID),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	_, err := repository.dbInstance.ExecContext(ctx, sql, args...)
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> NotificationsRepository -> DeleteByID -> repository.dbInstance.ExecContext(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return err
+	}
+
+	return nil
+}
+
+func (repository *NotificationsRepository) selectAllCols() *goqu.SelectDataset {
+	return dialect().
+		From("notifications").
+		Select(
+			goqu.C("id"),
+			goqu.C("task_id"),
+			goqu.C("notify_at"),
+			goqu.C("repeat_interval"),
+			goqu.C("created_at"),
+		)
+}
+
+// query (ctx, method, query) -> the notifications selected by the query, errors are logged for the method
+func (repository *NotificationsRepository) query(ctx context.Context, method string, query *goqu.SelectDataset) ([]models.Notification, error) {
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	rows, err := repository.dbInstance.QueryContext(ctx, sql, args...)
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> NotificationsRepository -> `+method+` -> repository.dbInstance.QueryContext(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return []models.Notification{}, err
+	}
+	defer rows.Close()
+
+	var notifications []models.Notification
+	for rows.Next() {
+		notification, err := scanNotification(rows)
+		if err != nil {
+			repository.logger.Debugw(
+				`Repositories -> SQLite -> NotificationsRepository -> `+method+` -> rows.Scan()`,
+				"error", err.Error(),
+			)
+			return []models.Notification{}, err
+		}
+
+		notifications = append(notifications, notification)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> NotificationsRepository -> `+method+` -> rows.Err()`,
+			"error", err.Error(),
+		)
+		return []models.Notification{}, err
+	}
+
+	return notifications, nil
+}
+
+func (repository *NotificationsRepository) FindByTasksIDs(ctx context.Context, tasksIds []int64) (map[int64]models.Notification, error) {
+	tasksNotificationsMap := map[int64]models.Notification{}
+	if len(tasksIds) == 0 {
+		return tasksNotificationsMap, nil
+	}
+
+	query := repository.selectAllCols().
+		Where(
+			goqu.C("task_id").In(tasksIds),
+		)
+
+	notifications, err := repository.query(ctx, "FindByTasksIDs", query)
+	if err != nil {
+		return map[int64]models.Notification{}, err
+	}
+
+	for _, notification := range notifications {
+		tasksNotificationsMap[notification.TaskID] = notification
+	}
+
+	return tasksNotificationsMap, nil
+}
+
+// GetUpcoming (ctx, upcomingTo) -> return notifications, except ones for tasks blocked by not completed tasks
+// and ones of users who blocked the bot
+func (repository *NotificationsRepository) GetUpcoming(ctx context.Context, upcomingTo time.Time) ([]models.Notification, error) {
+	activeBlockers := dialect().
+		From(goqu.T("task_dependencies").As("d")).
+		InnerJoin(
+			goqu.T("tasks").As("t"),
+			goqu.On(goqu.I("t.id").Eq(goqu.I("d.blocked_by_task_id"))),
+		).
+		Select(goqu.L("1")).
+		Where(
+			goqu.I("d.task_id").Eq(goqu.I("notifications.task_id")),
+			goqu.I("t.done").IsFalse(),
+		)
+
+	query := repository.selectAllCols().
+		Where(
+			goqu.C("notify_at").Lte(timestamp(upcomingTo)),
+			goqu.L("NOT EXISTS ?", activeBlockers),
+			//Напоминания тем, кто заблокировал бота, не отправляются, пока он снова не напишет
+			notInQuery(
+				goqu.C("task_id"),
+				dialect().
+					From("tasks").
+					Select("id").
+					Where(inQuery(goqu.C("user_id"), botBlockedUsers())),
+			),
+		).
+		Order(
+			goqu.C("notify_at").Asc(),
+			goqu.C("id").Asc(),
+		)
+
+	return repository.query(ctx, "GetUpcoming", query)
+}
+
+func (repository *NotificationsRepository) FindByID(ctx context.Context, ID int64) (models.Notification, error) {
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	query := repository.selectAllCols().
+		Where(
+			goqu.C("id").Eq(ID),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	notification, err := scanNotification(repository.dbInstance.QueryRowContext(ctx, sql, args...))
+	if err != nil {
+		err = queryError(ctx, notFoundError(err))
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> NotificationsRepository -> FindByID -> row.Scan()`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return models.Notification{}, err
+	}
+
+	return notification, nil
+}
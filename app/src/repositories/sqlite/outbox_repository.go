@@ -0,0 +1,200 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"github.com/doug-martin/goqu/v9"
+	"go.uber.org/zap"
+	"tg_todo_bot/src/models"
+	"time"
+)
+
+type OutboxRepository struct {
+	logger       *zap.SugaredLogger
+	dbInstance   querier
+	queryTimeout time.Duration
+}
+
+func NewOutboxRepository(
+	logger *zap.SugaredLogger,
+	dbInstance *sql.DB,
+	queryTimeout time.Duration,
+) *OutboxRepository {
+	return &OutboxRepository{
+		logger:       logger,
+		dbInstance:   dbInstance,
+		queryTimeout: queryTimeout,
+	}
+}
+
+// Claim (ctx, now, lease, limit) -> due messages, the oldest first. Their next attempt is moved by lease,
+// so if the process dies before Update they are sent again after the lease.
+// The messages are read and moved in one transaction, SQLite has only one writer at a time
+func (repository *OutboxRepository) Claim(ctx context.Context, now time.Time, lease time.Duration, limit uint) ([]models.OutboxMessage, error) {
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	tx, err := begin(ctx, repository.dbInstance)
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> OutboxRepository -> Claim -> begin(ctx, repository.dbInstance)`,
+			"error", err.Error(),
+		)
+		return []models.OutboxMessage{}, err
+	}
+	//После Commit откат ничего не делает
+	defer tx.Rollback()
+
+	query := dialect().
+		From("outbox").
+		Select(
+			goqu.C("id"),
+			goqu.C("user_id"),
+			goqu.C("kind"),
+			goqu.C("dedup_key"),
+			goqu.C("payload"),
+			goqu.C("attempts"),
+			goqu.C("next_attempt_at"),
+			goqu.C("last_error"),
+			goqu.C("sent_at"),
+			goqu.C("created_at"),
+		).
+		Where(
+			goqu.C("next_attempt_at").Lte(timestamp(now)),
+		).
+		Order(
+			goqu.C("next_attempt_at").Asc(),
+			goqu.C("id").Asc(),
+		).
+		Limit(limit)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	rows, err := tx.QueryContext(ctx, sql, args...)
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> OutboxRepository -> Claim -> tx.QueryContext(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return []models.OutboxMessage{}, err
+	}
+	defer rows.Close()
+
+	var messages []models.OutboxMessage
+	var messagesIDs []int64
+	for rows.Next() {
+		var message models.OutboxMessage
+		err = rows.Scan(
+			&message.ID,
+			&message.UserID,
+			&message.Kind,
+			&message.DedupKey,
+			&message.Payload,
+			&message.Attempts,
+			nullTimeScanner{dest: &message.NextAttemptAt},
+			&message.LastError,
+			nullTimeScanner{dest: &message.SentAt},
+			timeScanner{dest: &message.CreatedAt},
+		)
+		if err != nil {
+			repository.logger.Debugw(
+				`Repositories -> SQLite -> OutboxRepository -> Claim -> rows.Scan()`,
+				"error", err.Error(),
+			)
+			return []models.OutboxMessage{}, err
+		}
+
+		messages = append(messages, message)
+		messagesIDs = append(messagesIDs, message.ID)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> OutboxRepository -> Claim -> rows.Err()`,
+			"error", err.Error(),
+		)
+		return []models.OutboxMessage{}, err
+	}
+	rows.Close()
+
+	if len(messages) == 0 {
+		return messages, nil
+	}
+
+	leasedUntil := now.Add(lease)
+	updateQuery := dialect().
+		Update("outbox").
+		Set(
+			goqu.Record{
+				"next_attempt_at": timestamp(leasedUntil),
+			},
+		).
+		Where(
+			goqu.C("id").In(messagesIDs),
+		)
+
+	sql, args, _ = updateQuery.Prepared(true).ToSQL()
+
+	_, err = tx.ExecContext(ctx, sql, args...)
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> OutboxRepository -> Claim -> tx.ExecContext(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return []models.OutboxMessage{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> OutboxRepository -> Claim -> tx.Commit()`,
+			"error", err.Error(),
+		)
+		return []models.OutboxMessage{}, err
+	}
+
+	for i := range messages {
+		messages[i].NextAttemptAt = &leasedUntil
+	}
+
+	return messages, nil
+}
+
+// Update (ctx, message) -> saves the result of a send attempt
+func (repository *OutboxRepository) Update(ctx context.Context, message models.OutboxMessage) error {
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	query := dialect().
+		Update("outbox").
+		Set(
+			goqu.Record{
+				"attempts":        message.Attempts,
+				"next_attempt_at": nullTimestamp(message.NextAttemptAt),
+				"last_error":      message.LastError,
+				"sent_at":         nullTimestamp(message.SentAt),
+			},
+		).
+		Where(
+			goqu.C("id").Eq(message.ID),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	_, err := repository.dbInstance.ExecContext(ctx, sql, args...)
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> OutboxRepository -> Update -> repository.dbInstance.ExecContext(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,143 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"github.com/doug-martin/goqu/v9"
+	"go.uber.org/zap"
+	"math"
+	"tg_todo_bot/src/repositories/types"
+	"time"
+)
+
+type RateLimitsRepository struct {
+	logger       *zap.SugaredLogger
+	dbInstance   querier
+	queryTimeout time.Duration
+}
+
+func NewRateLimitsRepository(
+	logger *zap.SugaredLogger,
+	dbInstance *sql.DB,
+	queryTimeout time.Duration,
+) *RateLimitsRepository {
+	return &RateLimitsRepository{
+		logger:       logger,
+		dbInstance:   dbInstance,
+		queryTimeout: queryTimeout,
+	}
+}
+
+// Take (ctx, userID, bucket, limit) -> takes a token from the bucket of the user if there is a whole one,
+// returns whether it's taken and the tokens left. With SQLite the bot runs as one process, so the time is its own
+func (repository *RateLimitsRepository) Take(ctx context.Context, userID int64, bucket string, limit types.RateLimit) (bool, float64, error) {
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	tx, err := begin(ctx, repository.dbInstance)
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> RateLimitsRepository -> Take -> begin(ctx, repository.dbInstance)`,
+			"error", err.Error(),
+		)
+		return false, 0, err
+	}
+	//После Commit откат ничего не делает
+	defer tx.Rollback()
+
+	now := time.Now()
+
+	//Новое ведро создаётся полным
+	insertQuery := dialect().
+		Insert("rate_limits").
+		Rows(
+			goqu.Record{
+				"user_id":    userID,
+				"bucket":     bucket,
+				"tokens":     limit.Capacity,
+				"updated_at": timestamp(now),
+			},
+		).
+		OnConflict(goqu.DoNothing())
+
+	sql, args, _ := insertQuery.Prepared(true).ToSQL()
+
+	_, err = tx.ExecContext(ctx, sql, args...)
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> RateLimitsRepository -> Take -> tx.ExecContext(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return false, 0, err
+	}
+
+	selectQuery := dialect().
+		From("rate_limits").
+		Select(
+			goqu.C("tokens"),
+			goqu.C("updated_at"),
+		).
+		Where(
+			goqu.C("user_id").Eq(userID),
+			goqu.C("bucket").Eq(bucket),
+		)
+
+	sql, args, _ = selectQuery.Prepared(true).ToSQL()
+
+	var tokens float64
+	var updatedAt time.Time
+	err = tx.QueryRowContext(ctx, sql, args...).Scan(&tokens, timeScanner{dest: &updatedAt})
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> RateLimitsRepository -> Take -> tx.QueryRowContext(sql, args...).Scan()`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return false, 0, err
+	}
+
+	elapsedSeconds := now.Sub(updatedAt).Seconds()
+	tokens = math.Min(limit.Capacity, tokens+math.Max(0, elapsedSeconds)*limit.PerSecond)
+	taken := tokens >= 1
+	if taken {
+		tokens--
+	}
+
+	updateQuery := dialect().
+		Update("rate_limits").
+		Set(
+			goqu.Record{
+				"tokens":     tokens,
+				"updated_at": timestamp(now),
+			},
+		).
+		Where(
+			goqu.C("user_id").Eq(userID),
+			goqu.C("bucket").Eq(bucket),
+		)
+
+	sql, args, _ = updateQuery.Prepared(true).ToSQL()
+
+	_, err = tx.ExecContext(ctx, sql, args...)
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> RateLimitsRepository -> Take -> tx.ExecContext(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return false, 0, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> RateLimitsRepository -> Take -> tx.Commit()`,
+			"error", err.Error(),
+		)
+		return false, 0, err
+	}
+
+	return taken, tokens, nil
+}
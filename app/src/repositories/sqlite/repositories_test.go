@@ -0,0 +1,277 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"go.uber.org/zap"
+	"testing"
+	"tg_todo_bot/src/models"
+	"tg_todo_bot/src/repositories/types"
+	"time"
+)
+
+// createTaskForTest (t, dbInstance) -> a new user with a task of them
+func createTaskForTest(t *testing.T, dbInstance *sql.DB) (models.User, models.Task) {
+	logger := zap.NewNop().Sugar()
+	user, err := NewUsersRepository(logger, dbInstance, 0).Create(context.Background(), models.User{TelegramID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	task, err := NewTasksRepository(logger, dbInstance, 0).Create(context.Background(), models.Task{Title: "Test", UserID: user.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return user, task
+}
+
+func TestUnitOfWork(t *testing.T) {
+	logger := zap.NewNop().Sugar()
+	dbInstance := openMigrated(t)
+	user, _ := createTaskForTest(t, dbInstance)
+	unitOfWork := NewUnitOfWork(logger, dbInstance, 0)
+
+	failure := errors.New("failure")
+	err := unitOfWork.Do(context.Background(), func(tx types.TxRepositories) error {
+		_, err := tx.Tasks.Create(context.Background(), models.Task{Title: "Rolled back", UserID: user.ID})
+		if err != nil {
+			return err
+		}
+
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("got %v, expected the error of fn", err)
+	}
+
+	var created models.Task
+	err = unitOfWork.Do(context.Background(), func(tx types.TxRepositories) error {
+		//Вложенная транзакция репозитория идёт через SAVEPOINT
+		tasks, err := tx.Tasks.(*TasksRepository).CreateMany(context.Background(), []models.Task{{Title: "Committed", UserID: user.ID}})
+		if err != nil {
+			return err
+		}
+		created = tasks[0]
+
+		return tx.TaskTags.SetForTask(context.Background(), created.ID, []string{"work"})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	active, _, err := NewTasksRepository(logger, dbInstance, 0).CountForUser(context.Background(), user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if active != 2 {
+		t.Fatalf("got %d active tasks, expected the committed one to be added only", active)
+	}
+
+	tags, err := NewTaskTagsRepository(logger, dbInstance, 0).FindByTasksIDs(context.Background(), []int64{created.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags[created.ID]) != 1 || tags[created.ID][0] != "work" {
+		t.Fatalf("got tags %v", tags[created.ID])
+	}
+}
+
+func TestUpserts(t *testing.T) {
+	logger := zap.NewNop().Sugar()
+	dbInstance := openMigrated(t)
+	user, task := createTaskForTest(t, dbInstance)
+	ctx := context.Background()
+
+	filters := NewSavedFiltersRepository(logger, dbInstance, 0)
+	for _, query := range []string{"#work", "#home"} {
+		_, err := filters.Save(ctx, models.SavedFilter{UserID: user.ID, Name: "mine", Query: query})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	filter, err := filters.FindByName(ctx, user.ID, "mine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filter.Query != "#home" {
+		t.Fatalf("got filter query %s, expected the last saved one", filter.Query)
+	}
+
+	tokens := NewApiTokensRepository(logger, dbInstance, 0)
+	for _, hash := range []string{"first", "second"} {
+		_, err = tokens.Save(ctx, models.ApiToken{UserID: user.ID, Scope: "api", TokenHash: hash})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err = tokens.FindByHash(ctx, "first")
+	if !errors.Is(err, types.ErrNotFound) {
+		t.Fatalf("got %v, expected the replaced token not to be found", err)
+	}
+	token, err := tokens.FindByHash(ctx, "second")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.UserID != user.ID {
+		t.Fatalf("got token of user %d", token.UserID)
+	}
+
+	objects := NewCalDAVObjectsRepository(logger, dbInstance, 0)
+	for _, name := range []string{"a.ics", "b.ics"} {
+		err = objects.Save(ctx, models.CalDAVObject{TaskID: task.ID, UserID: user.ID, Name: name, UID: name})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	object, err := objects.FindByName(ctx, user.ID, "b.ics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if object.TaskID != task.ID {
+		t.Fatalf("got object of task %d", object.TaskID)
+	}
+
+	escalations := NewTaskEscalationsRepository(logger, dbInstance, 0)
+	deadline := time.Date(2100, 1, 1, 12, 0, 0, 0, time.UTC)
+	for level := 1; level <= 2; level++ {
+		err = escalations.Save(ctx, models.TaskEscalation{TaskID: task.ID, Deadline: deadline, Level: level, EscalatedAt: deadline})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	found, err := escalations.FindByTasksIDs(ctx, []int64{task.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found[task.ID].Level != 2 || !found[task.ID].Deadline.Equal(deadline) {
+		t.Fatalf("got escalation %+v", found[task.ID])
+	}
+}
+
+func TestClaims(t *testing.T) {
+	logger := zap.NewNop().Sugar()
+	dbInstance := openMigrated(t)
+	user, _ := createTaskForTest(t, dbInstance)
+	ctx := context.Background()
+	now := time.Date(2100, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	webhook, err := NewWebhooksRepository(logger, dbInstance, 0).Create(ctx, models.Webhook{
+		UserID: user.ID,
+		URL:    "https://example.com/hook",
+		Secret: "secret",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deliveries := NewWebhookDeliveriesRepository(logger, dbInstance, 0)
+	due, err := deliveries.Create(ctx, models.WebhookDelivery{
+		WebhookID:     webhook.ID,
+		EventType:     "task.created",
+		Payload:       []byte(`{}`),
+		NextAttemptAt: &now,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claimed, err := deliveries.Claim(ctx, now, time.Minute, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(claimed) != 1 || claimed[0].ID != due.ID || claimed[0].Webhook == nil || claimed[0].Webhook.URL != webhook.URL {
+		t.Fatalf("got deliveries %+v", claimed)
+	}
+	claimed, err = deliveries.Claim(ctx, now, time.Minute, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(claimed) != 0 {
+		t.Fatal("claimed delivery is returned again before the lease ends")
+	}
+
+	err = NewNotificationsRepository(logger, dbInstance, 0).Fire(ctx, models.Notification{}, now, models.OutboxMessage{
+		UserID:        user.ID,
+		Kind:          "reminder",
+		DedupKey:      "test",
+		Payload:       []byte(`{}`),
+		NextAttemptAt: &now,
+	})
+	if !errors.Is(err, types.ErrNotFound) {
+		t.Fatalf("got %v, expected the missing notification not to be fired", err)
+	}
+
+	rateLimits := NewRateLimitsRepository(logger, dbInstance, 0)
+	limit := types.RateLimit{Capacity: 1, PerSecond: 0.0001}
+	taken, _, err := rateLimits.Take(ctx, user.ID, "test", limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !taken {
+		t.Fatal("expected a token to be taken from a new bucket")
+	}
+	taken, _, err = rateLimits.Take(ctx, user.ID, "test", limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if taken {
+		t.Fatal("expected the bucket to be empty")
+	}
+}
+
+func TestBroadcastStatus(t *testing.T) {
+	repository := NewBroadcastsRepository(zap.NewNop().Sugar(), openMigrated(t), 0)
+	ctx := context.Background()
+
+	broadcast, err := repository.Create(ctx, models.Broadcast{Text: "Hello", Status: "running"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2100, 1, 1, 11, 0, 0, 0, time.UTC)
+	claimed, err := repository.Claim(ctx, "running", now, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claimed.ID != broadcast.ID {
+		t.Fatalf("got broadcast %d, expected %d", claimed.ID, broadcast.ID)
+	}
+	_, err = repository.Claim(ctx, "running", now, time.Minute)
+	if !errors.Is(err, types.ErrNotFound) {
+		t.Fatalf("got %v, expected the claimed broadcast not to be claimed again before the lease ends", err)
+	}
+
+	//Сохранённый прогресс продлевает аренду
+	err = repository.UpdateProgress(ctx, claimed, now.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = repository.Claim(ctx, "running", now.Add(30*time.Minute), time.Minute)
+	if !errors.Is(err, types.ErrNotFound) {
+		t.Fatalf("got %v, expected the lease to be extended", err)
+	}
+	claimed, err = repository.Claim(ctx, "running", now.Add(2*time.Hour), time.Minute)
+	if err != nil || claimed.ID != broadcast.ID {
+		t.Fatalf("got %+v, %v, expected the broadcast to be claimed after the lease", claimed, err)
+	}
+
+	finishedAt := time.Date(2100, 1, 1, 12, 0, 0, 0, time.UTC)
+	err = repository.UpdateStatus(ctx, broadcast.ID, []string{"running"}, "done", &finishedAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = repository.UpdateStatus(ctx, broadcast.ID, []string{"running"}, "cancelled", nil)
+	if !errors.Is(err, types.ErrNotFound) {
+		t.Fatalf("got %v, expected a finished broadcast not to be cancelled", err)
+	}
+
+	latest, err := repository.FindLatest(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest.Status != "done" || latest.FinishedAt == nil || !latest.FinishedAt.Equal(finishedAt) {
+		t.Fatalf("got broadcast %+v", latest)
+	}
+}
@@ -0,0 +1,191 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"github.com/doug-martin/goqu/v9"
+	"go.uber.org/zap"
+	"tg_todo_bot/src/models"
+	"time"
+)
+
+type SavedFiltersRepository struct {
+	logger       *zap.SugaredLogger
+	dbInstance   querier
+	queryTimeout time.Duration
+}
+
+func NewSavedFiltersRepository(
+	logger *zap.SugaredLogger,
+	dbInstance *sql.DB,
+	queryTimeout time.Duration,
+) *SavedFiltersRepository {
+	return &SavedFiltersRepository{
+		logger:       logger,
+		dbInstance:   dbInstance,
+		queryTimeout: queryTimeout,
+	}
+}
+
+// Save (ctx, filter) -> creates the filter or replaces the query of the user's filter with the same name
+func (repository *SavedFiltersRepository) Save(ctx context.Context, filter models.SavedFilter) (models.SavedFilter, error) {
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	query := dialect().
+		Insert("saved_filters").
+		Rows(
+			goqu.Record{
+				"user_id":    filter.UserID,
+				"name":       filter.Name,
+				"query":      filter.Query,
+				"created_at": timestamp(time.Now()),
+			},
+		).
+		OnConflict(
+			goqu.DoUpdate("user_id, name", goqu.Record{"query": goqu.I("excluded.query")}),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	_, err := repository.dbInstance.ExecContext(ctx, sql, args...)
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> SavedFiltersRepository -> Save -> repository.dbInstance.ExecContext(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return models.SavedFilter{}, err
+	}
+
+	//У заменённого фильтра остаются его ID и время создания
+	return repository.FindByName(ctx, filter.UserID, filter.Name)
+}
+
+func (repository *SavedFiltersRepository) selectAllCols() *goqu.SelectDataset {
+	return dialect().
+		From("saved_filters").
+		Select(
+			goqu.C("id"),
+			goqu.C("user_id"),
+			goqu.C("name"),
+			goqu.C("query"),
+			goqu.C("created_at"),
+		)
+}
+
+func scanSavedFilter(row scanner) (models.SavedFilter, error) {
+	var filter models.SavedFilter
+	err := row.Scan(
+		&filter.ID,
+		&filter.UserID,
+		&filter.Name,
+		&filter.Query,
+		timeScanner{dest: &filter.CreatedAt},
+	)
+
+	return filter, err
+}
+
+func (repository *SavedFiltersRepository) FindByName(ctx context.Context, userID int64, name string) (models.SavedFilter, error) {
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	query := repository.selectAllCols().
+		Where(
+			goqu.C("user_id").Eq(userID),
+			goqu.C("name").Eq(name),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	filter, err := scanSavedFilter(repository.dbInstance.QueryRowContext(ctx, sql, args...))
+	if err != nil {
+		err = queryError(ctx, notFoundError(err))
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> SavedFiltersRepository -> FindByName -> row.Scan()`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return models.SavedFilter{}, err
+	}
+
+	return filter, nil
+}
+
+func (repository *SavedFiltersRepository) GetAllForUser(ctx context.Context, userID int64) ([]models.SavedFilter, error) {
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	query := repository.selectAllCols().
+		Where(
+			goqu.C("user_id").Eq(userID),
+		).
+		Order(
+			goqu.C("name").Asc(),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	rows, err := repository.dbInstance.QueryContext(ctx, sql, args...)
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> SavedFiltersRepository -> GetAllForUser -> repository.dbInstance.QueryContext(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return []models.SavedFilter{}, err
+	}
+	defer rows.Close()
+
+	var filters []models.SavedFilter
+	for rows.Next() {
+		filter, err := scanSavedFilter(rows)
+		if err != nil {
+			repository.logger.Debugw(
+				`Repositories -> SQLite -> SavedFiltersRepository -> GetAllForUser -> rows.Scan()`,
+				"error", err.Error(),
+			)
+			return []models.SavedFilter{}, err
+		}
+
+		filters = append(filters, filter)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> SavedFiltersRepository -> GetAllForUser -> rows.Err()`,
+			"error", err.Error(),
+		)
+		return []models.SavedFilter{}, err
+	}
+
+	return filters, nil
+}
+
+func (repository *SavedFiltersRepository) DeleteByName(ctx context.Context, userID int64, name string) error {
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	query := dialect().
+		Delete("saved_filters").
+		Where(
+			goqu.C("user_id").Eq(userID),
+			goqu.C("name").Eq(name),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	_, err := repository.dbInstance.ExecContext(ctx, sql, args...)
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> SavedFiltersRepository -> DeleteByName -> repository.dbInstance.ExecContext(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return err
+	}
+
+	return nil
+}
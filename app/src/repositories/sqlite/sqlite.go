@@ -0,0 +1,239 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"github.com/doug-martin/goqu/v9"
+	_ "github.com/doug-martin/goqu/v9/dialect/sqlite3"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/pkg/errors"
+	"modernc.org/sqlite"
+	sqlite_lib "modernc.org/sqlite/lib"
+	"tg_todo_bot/src/models"
+	"tg_todo_bot/src/repositories/types"
+	"time"
+)
+
+// querier -> both *sql.DB and *sql.Tx, so a repository works the same in and out of a transaction
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// txQuerier -> a transaction or a savepoint in it
+type txQuerier interface {
+	querier
+	Commit() error
+	Rollback() error
+}
+
+// begin (ctx, dbInstance) -> a new transaction of *sql.DB. In a transaction of a unit of work a savepoint is started
+// instead, like Begin of pgx does, so methods with their own transactions can run in a unit of work
+func begin(ctx context.Context, dbInstance querier) (txQuerier, error) {
+	if pool, ok := dbInstance.(*sql.DB); ok {
+		return pool.BeginTx(ctx, nil)
+	}
+
+	_, err := dbInstance.ExecContext(ctx, "SAVEPOINT nested")
+	if err != nil {
+		return nil, err
+	}
+
+	return &savepoint{querier: dbInstance, ctx: ctx}, nil
+}
+
+// savepoint -> Commit releases the savepoint and Rollback returns to it, only the first of them does anything
+type savepoint struct {
+	querier
+	ctx  context.Context
+	done bool
+}
+
+func (sp *savepoint) Commit() error {
+	if sp.done {
+		return sql.ErrTxDone
+	}
+	sp.done = true
+
+	_, err := sp.ExecContext(sp.ctx, "RELEASE SAVEPOINT nested")
+	return err
+}
+
+func (sp *savepoint) Rollback() error {
+	if sp.done {
+		return sql.ErrTxDone
+	}
+	sp.done = true
+
+	_, err := sp.ExecContext(sp.ctx, "ROLLBACK TO SAVEPOINT nested")
+	if err != nil {
+		return err
+	}
+
+	_, err = sp.ExecContext(sp.ctx, "RELEASE SAVEPOINT nested")
+	return err
+}
+
+func dialect() goqu.DialectWrapper {
+	return goqu.Dialect("sqlite3")
+}
+
+// withTimeout (ctx, timeout) -> the deadline of all queries of one call of a repository, 0 timeout means no deadline
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
+
+// queryError (ctx, err) -> types.TimeoutError if the query was interrupted by the deadline of ctx,
+// the error of the driver is kept
+func queryError(ctx context.Context, err error) error {
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return &types.TimeoutError{Err: err}
+	}
+
+	return err
+}
+
+// notFoundError (err) -> types.ErrNotFound for a query of one row which found nothing
+func notFoundError(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return types.ErrNotFound
+	}
+
+	return err
+}
+
+func isUniqueViolation(err error) bool {
+	var sqliteError *sqlite.Error
+	return errors.As(err, &sqliteError) && sqliteError.Code() == sqlite_lib.SQLITE_CONSTRAINT_UNIQUE
+}
+
+// Наибольшее INTEGER, задачи без datetime сортируются после всех дат
+const infinity = "9223372036854775807"
+
+// timestamp (t) -> microseconds since the Unix epoch, times are stored as numbers to be compared and sorted as numbers
+func timestamp(t time.Time) int64 {
+	return t.UnixMicro()
+}
+
+// nullTimestamp (t) -> NULL for nil
+func nullTimestamp(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+
+	return timestamp(*t)
+}
+
+// timeScanner -> reads a column written by timestamp
+type timeScanner struct {
+	dest *time.Time
+}
+
+func (scanner timeScanner) Scan(value interface{}) error {
+	micros, ok := value.(int64)
+	if !ok {
+		return errors.Errorf("unexpected time value %v", value)
+	}
+	*scanner.dest = time.UnixMicro(micros)
+
+	return nil
+}
+
+// nullTimeScanner -> reads a column written by nullTimestamp
+type nullTimeScanner struct {
+	dest **time.Time
+}
+
+func (scanner nullTimeScanner) Scan(value interface{}) error {
+	if value == nil {
+		*scanner.dest = nil
+		return nil
+	}
+
+	var t time.Time
+	err := timeScanner{dest: &t}.Scan(value)
+	if err != nil {
+		return err
+	}
+	*scanner.dest = &t
+
+	return nil
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// taskDest (task, extra) -> destinations of the columns of TasksRepository.selectAllCols, then extra ones
+func taskDest(task *models.Task, extra ...interface{}) []interface{} {
+	return append([]interface{}{
+		&task.ID,
+		&task.Title,
+		&task.Description,
+		nullTimeScanner{dest: &task.Datetime},
+		&task.Done,
+		&task.Priority,
+		&task.UserID,
+		timeScanner{dest: &task.CreatedAt},
+	}, extra...)
+}
+
+func scanTask(row scanner) (models.Task, error) {
+	var task models.Task
+	err := row.Scan(taskDest(&task)...)
+
+	return task, err
+}
+
+func scanNotification(row scanner) (models.Notification, error) {
+	var notification models.Notification
+	err := row.Scan(
+		&notification.ID,
+		&notification.TaskID,
+		timeScanner{dest: &notification.NotifyAt},
+		&notification.RepeatInterval,
+		timeScanner{dest: &notification.CreatedAt},
+	)
+
+	return notification, err
+}
+
+func scanUser(row scanner) (models.User, error) {
+	var user models.User
+	err := row.Scan(
+		&user.ID,
+		&user.TelegramID,
+		timeScanner{dest: &user.CreatedAt},
+		nullTimeScanner{dest: &user.DeletionRequestedAt},
+		nullTimeScanner{dest: &user.LastSeenAt},
+		nullTimeScanner{dest: &user.BannedAt},
+		&user.BanReason,
+		nullTimeScanner{dest: &user.BotBlockedAt},
+	)
+
+	return user, err
+}
+
+// inQuery (column, subquery) -> "column IN (subquery)". goqu wraps a subquery of IN into two pairs of parentheses,
+// which SQLite takes for the value of the first row of the subquery
+func inQuery(column exp.Expression, subquery *goqu.SelectDataset) exp.LiteralExpression {
+	return goqu.L("? IN ?", column, subquery)
+}
+
+// notInQuery (column, subquery) -> "column NOT IN (subquery)", see inQuery
+func notInQuery(column exp.Expression, subquery *goqu.SelectDataset) exp.LiteralExpression {
+	return goqu.L("? NOT IN ?", column, subquery)
+}
+
+// botBlockedUsers -> IDs of users who blocked the bot, scheduled messages aren't sent to them
+func botBlockedUsers() *goqu.SelectDataset {
+	return dialect().
+		From("users").
+		Select("id").
+		Where(goqu.C("bot_blocked_at").IsNotNull())
+}
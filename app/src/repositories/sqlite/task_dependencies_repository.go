@@ -0,0 +1,232 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"github.com/doug-martin/goqu/v9"
+	"go.uber.org/zap"
+	"tg_todo_bot/src/models"
+	"tg_todo_bot/src/repositories/types"
+	"time"
+)
+
+type TaskDependenciesRepository struct {
+	logger       *zap.SugaredLogger
+	dbInstance   querier
+	queryTimeout time.Duration
+}
+
+func NewTaskDependenciesRepository(
+	logger *zap.SugaredLogger,
+	dbInstance *sql.DB,
+	queryTimeout time.Duration,
+) *TaskDependenciesRepository {
+	return &TaskDependenciesRepository{
+		logger:       logger,
+		dbInstance:   dbInstance,
+		queryTimeout: queryTimeout,
+	}
+}
+
+func (repository *TaskDependenciesRepository) Create(ctx context.Context, dependency models.TaskDependency) (models.TaskDependency, error) {
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	now := time.Now()
+	query := dialect().
+		Insert("task_dependencies").
+		Rows(
+			goqu.Record{
+				"task_id":            dependency.TaskID,
+				"blocked_by_task_id": dependency.BlockedByTaskID,
+				"created_at":         timestamp(now),
+			},
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	_, err := repository.dbInstance.ExecContext(ctx, sql, args...)
+	if err != nil {
+		//Нарушение уникальности
+		if isUniqueViolation(err) {
+			err = types.ErrAlreadyExist
+		}
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> TaskDependenciesRepository -> Create -> repository.dbInstance.ExecContext(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return models.TaskDependency{}, err
+	}
+
+	dependency.CreatedAt = now
+
+	return dependency, nil
+}
+
+func (repository *TaskDependenciesRepository) Delete(ctx context.Context, taskID, blockedByTaskID int64) error {
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	query := dialect().
+		Delete("task_dependencies").
+		Where(
+			goqu.C("task_id").Eq(taskID),
+			goqu.C("blocked_by_task_id").Eq(blockedByTaskID),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	_, err := repository.dbInstance.ExecContext(ctx, sql, args...)
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> TaskDependenciesRepository -> Delete -> repository.dbInstance.ExecContext(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return err
+	}
+
+	return nil
+}
+
+// FindBlockersIDs (ctx, tasksIDs) -> return map[TaskID][]BlockedByTaskID including completed blockers
+func (repository *TaskDependenciesRepository) FindBlockersIDs(ctx context.Context, tasksIDs []int64) (map[int64][]int64, error) {
+	if len(tasksIDs) == 0 {
+		return map[int64][]int64{}, nil
+	}
+
+	query := dialect().
+		From("task_dependencies").
+		Select(
+			goqu.C("task_id"),
+			goqu.C("blocked_by_task_id"),
+		).
+		Where(
+			goqu.C("task_id").In(tasksIDs),
+		).
+		Order(
+			goqu.C("blocked_by_task_id").Asc(),
+		)
+
+	return repository.queryBlockersIDs(ctx, "FindBlockersIDs", query)
+}
+
+// FindActiveBlockersIDs (ctx, tasksIDs) -> return map[TaskID][]BlockedByTaskID only for not completed blockers
+func (repository *TaskDependenciesRepository) FindActiveBlockersIDs(ctx context.Context, tasksIDs []int64) (map[int64][]int64, error) {
+	if len(tasksIDs) == 0 {
+		return map[int64][]int64{}, nil
+	}
+
+	query := dialect().
+		From(goqu.T("task_dependencies").As("d")).
+		InnerJoin(
+			goqu.T("tasks").As("t"),
+			goqu.On(goqu.I("t.id").Eq(goqu.I("d.blocked_by_task_id"))),
+		).
+		Select(
+			goqu.I("d.task_id"),
+			goqu.I("d.blocked_by_task_id"),
+		).
+		Where(
+			goqu.I("d.task_id").In(tasksIDs),
+			goqu.I("t.done").IsFalse(),
+		).
+		Order(
+			goqu.I("d.blocked_by_task_id").Asc(),
+		)
+
+	return repository.queryBlockersIDs(ctx, "FindActiveBlockersIDs", query)
+}
+
+func (repository *TaskDependenciesRepository) queryBlockersIDs(ctx context.Context, method string, query *goqu.SelectDataset) (map[int64][]int64, error) {
+	blockersMap := map[int64][]int64{}
+
+	pairs, err := repository.queryIDs(ctx, method, query, 2)
+	if err != nil {
+		return map[int64][]int64{}, err
+	}
+	for _, pair := range pairs {
+		blockersMap[pair[0]] = append(blockersMap[pair[0]], pair[1])
+	}
+
+	return blockersMap, nil
+}
+
+// FindDependentTasksIDs (ctx, blockedByTaskID) -> return IDs of tasks blocked by the given one
+func (repository *TaskDependenciesRepository) FindDependentTasksIDs(ctx context.Context, blockedByTaskID int64) ([]int64, error) {
+	query := dialect().
+		From("task_dependencies").
+		Select(
+			goqu.C("task_id"),
+		).
+		Where(
+			goqu.C("blocked_by_task_id").Eq(blockedByTaskID),
+		).
+		Order(
+			goqu.C("task_id").Asc(),
+		)
+
+	rows, err := repository.queryIDs(ctx, "FindDependentTasksIDs", query, 1)
+	if err != nil {
+		return []int64{}, err
+	}
+
+	var tasksIDs []int64
+	for _, row := range rows {
+		tasksIDs = append(tasksIDs, row[0])
+	}
+
+	return tasksIDs, nil
+}
+
+// queryIDs (ctx, method, query, columns) -> rows of the query of columns IDs each, errors are logged for the method
+func (repository *TaskDependenciesRepository) queryIDs(ctx context.Context, method string, query *goqu.SelectDataset, columns int) ([][]int64, error) {
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	rows, err := repository.dbInstance.QueryContext(ctx, sql, args...)
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> TaskDependenciesRepository -> `+method+` -> repository.dbInstance.QueryContext(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return [][]int64{}, err
+	}
+	defer rows.Close()
+
+	var result [][]int64
+	for rows.Next() {
+		row := make([]int64, columns)
+		dest := make([]interface{}, columns)
+		for i := range row {
+			dest[i] = &row[i]
+		}
+
+		err = rows.Scan(dest...)
+		if err != nil {
+			repository.logger.Debugw(
+				`Repositories -> SQLite -> TaskDependenciesRepository -> `+method+` -> rows.Scan()`,
+				"error", err.Error(),
+			)
+			return [][]int64{}, err
+		}
+
+		result = append(result, row)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> TaskDependenciesRepository -> `+method+` -> rows.Err()`,
+			"error", err.Error(),
+		)
+		return [][]int64{}, err
+	}
+
+	return result, nil
+}
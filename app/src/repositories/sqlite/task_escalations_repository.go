@@ -0,0 +1,133 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"github.com/doug-martin/goqu/v9"
+	"go.uber.org/zap"
+	"tg_todo_bot/src/models"
+	"time"
+)
+
+type TaskEscalationsRepository struct {
+	logger       *zap.SugaredLogger
+	dbInstance   querier
+	queryTimeout time.Duration
+}
+
+func NewTaskEscalationsRepository(
+	logger *zap.SugaredLogger,
+	dbInstance *sql.DB,
+	queryTimeout time.Duration,
+) *TaskEscalationsRepository {
+	return &TaskEscalationsRepository{
+		logger:       logger,
+		dbInstance:   dbInstance,
+		queryTimeout: queryTimeout,
+	}
+}
+
+// Save (ctx, escalation) -> creates or replaces the last escalation of the task
+func (repository *TaskEscalationsRepository) Save(ctx context.Context, escalation models.TaskEscalation) error {
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	query := dialect().
+		Insert("task_escalations").
+		Rows(
+			goqu.Record{
+				"task_id":      escalation.TaskID,
+				"deadline":     timestamp(escalation.Deadline),
+				"level":        escalation.Level,
+				"escalated_at": timestamp(escalation.EscalatedAt),
+			},
+		).
+		OnConflict(
+			goqu.DoUpdate("task_id", goqu.Record{
+				"deadline":     goqu.I("excluded.deadline"),
+				"level":        goqu.I("excluded.level"),
+				"escalated_at": goqu.I("excluded.escalated_at"),
+			}),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	_, err := repository.dbInstance.ExecContext(ctx, sql, args...)
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> TaskEscalationsRepository -> Save -> repository.dbInstance.ExecContext(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return err
+	}
+
+	return nil
+}
+
+// FindByTasksIDs (ctx, tasksIDs) -> return map[TaskID]TaskEscalation
+func (repository *TaskEscalationsRepository) FindByTasksIDs(ctx context.Context, tasksIDs []int64) (map[int64]models.TaskEscalation, error) {
+	if len(tasksIDs) == 0 {
+		return map[int64]models.TaskEscalation{}, nil
+	}
+
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	query := dialect().
+		From("task_escalations").
+		Select(
+			goqu.C("task_id"),
+			goqu.C("deadline"),
+			goqu.C("level"),
+			goqu.C("escalated_at"),
+		).
+		Where(
+			goqu.C("task_id").In(tasksIDs),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	rows, err := repository.dbInstance.QueryContext(ctx, sql, args...)
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> TaskEscalationsRepository -> FindByTasksIDs -> repository.dbInstance.QueryContext(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return map[int64]models.TaskEscalation{}, err
+	}
+	defer rows.Close()
+
+	escalationsMap := map[int64]models.TaskEscalation{}
+	for rows.Next() {
+		var escalation models.TaskEscalation
+		err = rows.Scan(
+			&escalation.TaskID,
+			timeScanner{dest: &escalation.Deadline},
+			&escalation.Level,
+			timeScanner{dest: &escalation.EscalatedAt},
+		)
+		if err != nil {
+			repository.logger.Debugw(
+				`Repositories -> SQLite -> TaskEscalationsRepository -> FindByTasksIDs -> rows.Scan()`,
+				"error", err.Error(),
+			)
+			return map[int64]models.TaskEscalation{}, err
+		}
+
+		escalationsMap[escalation.TaskID] = escalation
+	}
+
+	err = rows.Err()
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> TaskEscalationsRepository -> FindByTasksIDs -> rows.Err()`,
+			"error", err.Error(),
+		)
+		return map[int64]models.TaskEscalation{}, err
+	}
+
+	return escalationsMap, nil
+}
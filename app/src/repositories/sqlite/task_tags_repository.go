@@ -0,0 +1,150 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"github.com/doug-martin/goqu/v9"
+	"go.uber.org/zap"
+	"time"
+)
+
+type TaskTagsRepository struct {
+	logger       *zap.SugaredLogger
+	dbInstance   querier
+	queryTimeout time.Duration
+}
+
+func NewTaskTagsRepository(
+	logger *zap.SugaredLogger,
+	dbInstance *sql.DB,
+	queryTimeout time.Duration,
+) *TaskTagsRepository {
+	return &TaskTagsRepository{
+		logger:       logger,
+		dbInstance:   dbInstance,
+		queryTimeout: queryTimeout,
+	}
+}
+
+// SetForTask (ctx, taskID, tags) -> replaces all tags of the task
+func (repository *TaskTagsRepository) SetForTask(ctx context.Context, taskID int64, tags []string) error {
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	deleteQuery := dialect().
+		Delete("task_tags").
+		Where(
+			goqu.C("task_id").Eq(taskID),
+		)
+	if len(tags) > 0 {
+		deleteQuery = deleteQuery.Where(
+			goqu.C("tag").NotIn(tags),
+		)
+	}
+
+	sql, args, _ := deleteQuery.Prepared(true).ToSQL()
+
+	_, err := repository.dbInstance.ExecContext(ctx, sql, args...)
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> TaskTagsRepository -> SetForTask -> repository.dbInstance.ExecContext(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return err
+	}
+
+	if len(tags) == 0 {
+		return nil
+	}
+
+	var rows []interface{}
+	for _, tag := range tags {
+		rows = append(rows, goqu.Record{
+			"task_id": taskID,
+			"tag":     tag,
+		})
+	}
+	insertQuery := dialect().
+		Insert("task_tags").
+		Rows(rows...).
+		OnConflict(goqu.DoNothing())
+
+	sql, args, _ = insertQuery.Prepared(true).ToSQL()
+
+	_, err = repository.dbInstance.ExecContext(ctx, sql, args...)
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> TaskTagsRepository -> SetForTask -> repository.dbInstance.ExecContext(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return err
+	}
+
+	return nil
+}
+
+// FindByTasksIDs (ctx, tasksIDs) -> return map[TaskID][]Tag
+func (repository *TaskTagsRepository) FindByTasksIDs(ctx context.Context, tasksIDs []int64) (map[int64][]string, error) {
+	if len(tasksIDs) == 0 {
+		return map[int64][]string{}, nil
+	}
+
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	query := dialect().
+		From("task_tags").
+		Select(
+			goqu.C("task_id"),
+			goqu.C("tag"),
+		).
+		Where(
+			goqu.C("task_id").In(tasksIDs),
+		).
+		Order(
+			goqu.C("tag").Asc(),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	rows, err := repository.dbInstance.QueryContext(ctx, sql, args...)
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> TaskTagsRepository -> FindByTasksIDs -> repository.dbInstance.QueryContext(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return map[int64][]string{}, err
+	}
+	defer rows.Close()
+
+	tasksTagsMap := map[int64][]string{}
+	for rows.Next() {
+		var taskID int64
+		var tag string
+		err = rows.Scan(&taskID, &tag)
+		if err != nil {
+			repository.logger.Debugw(
+				`Repositories -> SQLite -> TaskTagsRepository -> FindByTasksIDs -> rows.Scan()`,
+				"error", err.Error(),
+			)
+			return map[int64][]string{}, err
+		}
+
+		tasksTagsMap[taskID] = append(tasksTagsMap[taskID], tag)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> TaskTagsRepository -> FindByTasksIDs -> rows.Err()`,
+			"error", err.Error(),
+		)
+		return map[int64][]string{}, err
+	}
+
+	return tasksTagsMap, nil
+}
@@ -0,0 +1,707 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"go.uber.org/zap"
+	"strings"
+	"tg_todo_bot/src/models"
+	"tg_todo_bot/src/repositories/types"
+	"time"
+	"unicode"
+)
+
+type TasksRepository struct {
+	logger       *zap.SugaredLogger
+	dbInstance   querier
+	queryTimeout time.Duration
+}
+
+func NewTasksRepository(
+	logger *zap.SugaredLogger,
+	dbInstance *sql.DB,
+	queryTimeout time.Duration,
+) *TasksRepository {
+	return &TasksRepository{
+		logger:       logger,
+		dbInstance:   dbInstance,
+		queryTimeout: queryTimeout,
+	}
+}
+
+func (repository *TasksRepository) insert(ctx context.Context, querier querier, task models.Task, createdAt time.Time) (int64, error) {
+	query := dialect().
+		Insert("tasks").
+		Rows(
+			goqu.Record{
+				"title":       task.Title,
+				"description": task.Description,
+				"datetime":    nullTimestamp(task.Datetime),
+				"done":        task.Done,
+				"priority":    task.Priority,
+				"user_id":     task.UserID,
+				"created_at":  timestamp(createdAt),
+			},
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	result, err := querier.ExecContext(ctx, sql, args...)
+	if err != nil {
+		return 0, queryError(ctx, err)
+	}
+
+	return result.LastInsertId()
+}
+
+func (repository *TasksRepository) Create(ctx context.Context, task models.Task) (models.Task, error) {
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	ID, err := repository.insert(ctx, repository.dbInstance, task, time.Now())
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> TasksRepository -> Create -> repository.insert()`,
+			"error", err.Error(),
+		)
+		return models.Task{}, err
+	}
+	task.ID = ID
+
+	return task, nil
+}
+
+// CreateMany (ctx, tasks) -> creates the tasks with their tags in one transaction, nothing is created if any insert fails
+func (repository *TasksRepository) CreateMany(ctx context.Context, tasks []models.Task) ([]models.Task, error) {
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	tx, err := begin(ctx, repository.dbInstance)
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> TasksRepository -> CreateMany -> begin(ctx, repository.dbInstance)`,
+			"error", err.Error(),
+		)
+		return []models.Task{}, err
+	}
+	//После Commit откат ничего не делает
+	defer tx.Rollback()
+
+	now := time.Now()
+	created := make([]models.Task, 0, len(tasks))
+	for _, task := range tasks {
+		task.ID, err = repository.insert(ctx, tx, task, now)
+		if err != nil {
+			repository.logger.Debugw(
+				`Repositories -> SQLite -> TasksRepository -> CreateMany -> repository.insert()`,
+				"error", err.Error(),
+			)
+			return []models.Task{}, err
+		}
+		task.CreatedAt = now
+
+		if len(task.Tags) > 0 {
+			var rows []interface{}
+			for _, tag := range task.Tags {
+				rows = append(rows, goqu.Record{
+					"task_id": task.ID,
+					"tag":     tag,
+				})
+			}
+			tagsQuery := dialect().
+				Insert("task_tags").
+				Rows(rows...).
+				OnConflict(goqu.DoNothing())
+
+			sql, args, _ := tagsQuery.Prepared(true).ToSQL()
+
+			_, err = tx.ExecContext(ctx, sql, args...)
+			if err != nil {
+				err = queryError(ctx, err)
+				repository.logger.Debugw(
+					`Repositories -> SQLite -> TasksRepository -> CreateMany -> tx.ExecContext(sql, args...)`,
+					"error", err.Error(), "SQL", sql, "args", args,
+				)
+				return []models.Task{}, err
+			}
+		}
+
+		created = append(created, task)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> TasksRepository -> CreateMany -> tx.Commit()`,
+			"error", err.Error(),
+		)
+		return []models.Task{}, err
+	}
+
+	return created, nil
+}
+
+func (repository *TasksRepository) selectAllCols() *goqu.SelectDataset {
+	return dialect().
+		From("tasks").
+		Select(
+			goqu.I("tasks.id"),
+			goqu.I("tasks.title"),
+			goqu.I("tasks.description"),
+			goqu.I("tasks.datetime"),
+			goqu.I("tasks.done"),
+			goqu.I("tasks.priority"),
+			goqu.I("tasks.user_id"),
+			goqu.I("tasks.created_at"),
+		)
+}
+
+// query (ctx, method, query) -> the tasks selected by the query, errors are logged for the method
+func (repository *TasksRepository) query(ctx context.Context, method string, query *goqu.SelectDataset) ([]models.Task, error) {
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	rows, err := repository.dbInstance.QueryContext(ctx, sql, args...)
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> TasksRepository -> `+method+` -> repository.dbInstance.QueryContext(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return []models.Task{}, err
+	}
+	defer rows.Close()
+
+	var tasks []models.Task
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			repository.logger.Debugw(
+				`Repositories -> SQLite -> TasksRepository -> `+method+` -> rows.Scan()`,
+				"error", err.Error(),
+			)
+			return []models.Task{}, err
+		}
+
+		tasks = append(tasks, task)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> TasksRepository -> `+method+` -> rows.Err()`,
+			"error", err.Error(),
+		)
+		return []models.Task{}, err
+	}
+
+	return tasks, nil
+}
+
+// exec (ctx, method, query) -> runs the statement, errors are logged for the method
+func (repository *TasksRepository) exec(ctx context.Context, method string, query exp.SQLExpression) error {
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	sql, args, _ := query.ToSQL()
+
+	_, err := repository.dbInstance.ExecContext(ctx, sql, args...)
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> TasksRepository -> `+method+` -> repository.dbInstance.ExecContext(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return err
+	}
+
+	return nil
+}
+
+// Задачи без datetime сортируются после всех дат, как NULL при сортировке по возрастанию в PostgreSQL
+const tasksOrderDatetime = "COALESCE(tasks.datetime, " + infinity + ")"
+
+func (repository *TasksRepository) SearchActiveByDatetimeForUser(ctx context.Context, from, to *time.Time, userID int64) ([]models.Task, error) {
+	if from == nil && to == nil {
+		err := fmt.Errorf(`"from" and "to" are empty`)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> TasksRepository -> SearchActiveByDatetimeForUser`,
+			"error", err.Error(),
+		)
+		return []models.Task{}, err
+	}
+
+	query := repository.selectAllCols().
+		Order(
+			goqu.I("tasks.datetime").Asc(),
+			goqu.I("tasks.title").Asc(),
+		).
+		Where(
+			goqu.I("tasks.done").IsFalse(),
+			goqu.I("tasks.user_id").Eq(userID),
+		)
+
+	if from != nil {
+		query = query.Where(
+			goqu.I("tasks.datetime").Gte(timestamp(*from)),
+		)
+	}
+	if to != nil {
+		query = query.Where(
+			goqu.I("tasks.datetime").Lte(timestamp(*to)),
+		)
+	}
+
+	return repository.query(ctx, "SearchActiveByDatetimeForUser", query)
+}
+
+// GetPageForUser (ctx, userID, afterTaskID, limit) -> active and completed tasks with ID greater than afterTaskID, by ID
+func (repository *TasksRepository) GetPageForUser(ctx context.Context, userID, afterTaskID int64, limit uint) ([]models.Task, error) {
+	query := repository.selectAllCols().
+		Order(
+			goqu.I("tasks.id").Asc(),
+		).
+		Where(
+			goqu.I("tasks.user_id").Eq(userID),
+			goqu.I("tasks.id").Gt(afterTaskID),
+		).
+		Limit(limit)
+
+	return repository.query(ctx, "GetPageForUser", query)
+}
+
+func (repository *TasksRepository) GetAllActiveForUser(ctx context.Context, userID int64) ([]models.Task, error) {
+	query := repository.selectAllCols().
+		Order(
+			goqu.L(tasksOrderDatetime).Asc(),
+			goqu.I("tasks.title").Asc(),
+		).
+		Where(
+			goqu.I("tasks.done").IsFalse(),
+			goqu.I("tasks.user_id").Eq(userID),
+		)
+
+	return repository.query(ctx, "GetAllActiveForUser", query)
+}
+
+func (repository *TasksRepository) Update(ctx context.Context, model models.Task) error {
+	query := dialect().
+		Update("tasks").
+		Set(
+			goqu.Record{
+				"title":       model.Title,
+				"description": model.Description,
+				"datetime":    nullTimestamp(model.Datetime),
+				"done":        model.Done,
+				"priority":    model.Priority,
+				"user_id":     model.UserID,
+			},
+		).
+		Where(
+			goqu.C("id").Eq(model.ID),
+		)
+
+	return repository.exec(ctx, "Update", query.Prepared(true))
+}
+
+func (repository *TasksRepository) DeleteByID(ctx context.Context, ID int64) error {
+	query := dialect().
+		Delete("tasks").
+		Where(
+			goqu.C("id").Eq(ID),
+		)
+
+	return repository.exec(ctx, "DeleteByID", query.Prepared(true))
+}
+
+func (repository *TasksRepository) DeleteCompleted(ctx context.Context) error {
+	query := dialect().
+		Delete("tasks").
+		Where(
+			goqu.C("done").IsTrue(),
+		)
+
+	return repository.exec(ctx, "DeleteCompleted", query.Prepared(true))
+}
+
+func (repository *TasksRepository) FindByID(ctx context.Context, ID int64) (models.Task, error) {
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	query := repository.selectAllCols().
+		Where(
+			goqu.I("tasks.id").Eq(ID),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	task, err := scanTask(repository.dbInstance.QueryRowContext(ctx, sql, args...))
+	if err != nil {
+		err = queryError(ctx, notFoundError(err))
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> TasksRepository -> FindByID -> row.Scan()`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return models.Task{}, err
+	}
+
+	return task, nil
+}
+
+// FindByIDForUpdate (ctx, ID) -> FindByID, SQLite has no row locks. The database has one connection,
+// so no other transaction runs until this one ends
+func (repository *TasksRepository) FindByIDForUpdate(ctx context.Context, ID int64) (models.Task, error) {
+	return repository.FindByID(ctx, ID)
+}
+
+func (repository *TasksRepository) GetActiveTasksWithoutDatetimeForUser(ctx context.Context, userID int64) ([]models.Task, error) {
+	query := repository.selectAllCols().
+		Where(
+			goqu.I("tasks.user_id").Eq(userID),
+			goqu.I("tasks.done").IsFalse(),
+			goqu.I("tasks.datetime").IsNull(),
+		).
+		Order(
+			goqu.I("tasks.title").Asc(),
+		)
+
+	return repository.query(ctx, "GetActiveTasksWithoutDatetimeForUser", query)
+}
+
+// prefixMatchQuery ("купить мол") -> `"купить"* "мол"*`, only letters and digits are kept
+func prefixMatchQuery(query string) string {
+	words := strings.FieldsFunc(query, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for i, word := range words {
+		words[i] = `"` + word + `"*`
+	}
+
+	return strings.Join(words, " ")
+}
+
+// Search (ctx, userID, query, filters) -> full-text search over title and description, the most relevant first.
+// Words of the title weigh more like in PostgreSQL, but words aren't stemmed
+func (repository *TasksRepository) Search(ctx context.Context, userID int64, query string, filters types.TasksSearchFilters) ([]models.Task, error) {
+	matchQuery := prefixMatchQuery(query)
+	if matchQuery == "" {
+		return []models.Task{}, nil
+	}
+
+	selectQuery := repository.selectAllCols().
+		InnerJoin(
+			goqu.T("tasks_fts"),
+			goqu.On(goqu.I("tasks_fts.rowid").Eq(goqu.I("tasks.id"))),
+		).
+		Where(
+			goqu.I("tasks.user_id").Eq(userID),
+			goqu.L("tasks_fts MATCH ?", matchQuery),
+		).
+		Order(
+			//bm25 тем меньше, чем задача релевантнее, веса как у A и B в ts_rank
+			goqu.L("bm25(tasks_fts, 1.0, 0.4)").Asc(),
+			goqu.I("tasks.id").Desc(),
+		)
+
+	if filters.Done != nil {
+		selectQuery = selectQuery.Where(
+			goqu.I("tasks.done").Eq(*filters.Done),
+		)
+	}
+	if filters.From != nil {
+		selectQuery = selectQuery.Where(
+			goqu.I("tasks.datetime").Gte(timestamp(*filters.From)),
+		)
+	}
+	if filters.To != nil {
+		selectQuery = selectQuery.Where(
+			goqu.I("tasks.datetime").Lte(timestamp(*filters.To)),
+		)
+	}
+	if filters.Limit > 0 {
+		selectQuery = selectQuery.Limit(filters.Limit)
+	}
+
+	return repository.query(ctx, "Search", selectQuery)
+}
+
+// GetActiveForUserPage (ctx, userID, page) -> keyset pagination over active tasks in the (datetime, title, id) order.
+// Tasks are always returned in ascending order, for page.Before too.
+func (repository *TasksRepository) GetActiveForUserPage(ctx context.Context, userID int64, page types.TasksPageParams) ([]models.Task, error) {
+	query := repository.selectAllCols().
+		Where(
+			goqu.I("tasks.done").IsFalse(),
+			goqu.I("tasks.user_id").Eq(userID),
+		)
+
+	cursor, backward := page.After, false
+	if page.Before != nil {
+		cursor, backward = page.Before, true
+	}
+
+	if cursor != nil {
+		operator := ">"
+		if backward {
+			operator = "<"
+		}
+		query = query.Where(
+			goqu.L(
+				"("+tasksOrderDatetime+", tasks.title, tasks.id) "+operator+" (COALESCE(?, "+infinity+"), ?, ?)",
+				nullTimestamp(cursor.Datetime), cursor.Title, cursor.ID,
+			),
+		)
+	}
+
+	if backward {
+		query = query.Order(
+			goqu.L(tasksOrderDatetime).Desc(),
+			goqu.I("tasks.title").Desc(),
+			goqu.I("tasks.id").Desc(),
+		)
+	} else {
+		query = query.Order(
+			goqu.L(tasksOrderDatetime).Asc(),
+			goqu.I("tasks.title").Asc(),
+			goqu.I("tasks.id").Asc(),
+		)
+	}
+
+	if page.Limit > 0 {
+		query = query.Limit(page.Limit)
+	}
+
+	tasks, err := repository.query(ctx, "GetActiveForUserPage", query)
+	if err != nil {
+		return []models.Task{}, err
+	}
+
+	if backward {
+		for i, j := 0, len(tasks)-1; i < j; i, j = i+1, j-1 {
+			tasks[i], tasks[j] = tasks[j], tasks[i]
+		}
+	}
+
+	return tasks, nil
+}
+
+func (repository *TasksRepository) tagExists(tags []string) exp.LiteralExpression {
+	tagsQuery := dialect().
+		From("task_tags").
+		Select(goqu.L("1")).
+		Where(
+			goqu.I("task_tags.task_id").Eq(goqu.I("tasks.id")),
+			goqu.I("task_tags.tag").In(tags),
+		)
+
+	return goqu.L("EXISTS ?", tagsQuery)
+}
+
+func (repository *TasksRepository) filterQuery(userID int64, filter types.TasksFilter) *goqu.SelectDataset {
+	query := repository.selectAllCols().
+		Where(
+			goqu.I("tasks.user_id").Eq(userID),
+		)
+
+	if filter.Done != nil {
+		query = query.Where(goqu.I("tasks.done").Eq(*filter.Done))
+	}
+	if filter.DueFrom != nil {
+		query = query.Where(goqu.I("tasks.datetime").Gte(timestamp(*filter.DueFrom)))
+	}
+	if filter.DueTo != nil {
+		query = query.Where(goqu.I("tasks.datetime").Lte(timestamp(*filter.DueTo)))
+	}
+	if filter.WithoutDue {
+		query = query.Where(goqu.I("tasks.datetime").IsNull())
+	}
+	if filter.WithDue {
+		query = query.Where(goqu.I("tasks.datetime").IsNotNull())
+	}
+	for _, tag := range filter.Tags {
+		query = query.Where(repository.tagExists([]string{tag}))
+	}
+	if len(filter.ExcludedTags) > 0 {
+		query = query.Where(goqu.L("NOT ?", repository.tagExists(filter.ExcludedTags)))
+	}
+	if filter.PriorityFrom != nil {
+		query = query.Where(goqu.I("tasks.priority").Gte(*filter.PriorityFrom))
+	}
+	if filter.PriorityTo != nil {
+		query = query.Where(goqu.I("tasks.priority").Lte(*filter.PriorityTo))
+	}
+	if matchQuery := prefixMatchQuery(filter.Text); matchQuery != "" {
+		query = query.Where(goqu.L(
+			"tasks.id IN (SELECT rowid FROM tasks_fts WHERE tasks_fts MATCH ?)",
+			matchQuery,
+		))
+	}
+
+	var sortExpression exp.Orderable
+	switch filter.SortBy {
+	case types.TasksSortByCreated:
+		sortExpression = goqu.I("tasks.created_at")
+	case types.TasksSortByTitle:
+		sortExpression = goqu.I("tasks.title")
+	case types.TasksSortByPriority:
+		//Задачи без приоритета в конце
+		sortExpression = goqu.L("COALESCE(NULLIF(tasks.priority, 0), 32767)")
+	default:
+		sortExpression = goqu.L(tasksOrderDatetime)
+	}
+	if filter.SortDesc {
+		query = query.Order(sortExpression.Desc(), goqu.I("tasks.id").Desc())
+	} else {
+		query = query.Order(sortExpression.Asc(), goqu.I("tasks.id").Asc())
+	}
+
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	return query
+}
+
+// Filter (ctx, userID, filter) -> tasks matching all conditions of the filter
+func (repository *TasksRepository) Filter(ctx context.Context, userID int64, filter types.TasksFilter) ([]models.Task, error) {
+	return repository.query(ctx, "Filter", repository.filterQuery(userID, filter))
+}
+
+// Часовой пояс владельца задачи, UTC без настроек
+const tasksUserTimezone = "COALESCE((SELECT timezone FROM user_settings WHERE user_settings.user_id = tasks.user_id), 'UTC')"
+
+// taskDeadline (task, timezone) -> tasks without time are stored at 00:00 of the user and are overdue only after the end of the day.
+// SQLite doesn't know time zones, so the deadline is found here and not in the query
+func taskDeadline(task models.Task, timezone string) time.Time {
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		location = time.UTC
+	}
+
+	datetime := task.Datetime.In(location)
+	if datetime.Hour() == 0 && datetime.Minute() == 0 && datetime.Second() == 0 && datetime.Nanosecond() == 0 {
+		return datetime.AddDate(0, 0, 1)
+	}
+
+	return datetime
+}
+
+// GetOverdue (ctx, now, filters) -> not completed tasks with the deadline before now, the most overdue first
+func (repository *TasksRepository) GetOverdue(ctx context.Context, now time.Time, filters types.TasksOverdueFilters) ([]models.Task, error) {
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	query := repository.selectAllCols().
+		SelectAppend(goqu.L(tasksUserTimezone)).
+		Where(
+			goqu.I("tasks.done").IsFalse(),
+			//Дедлайн не раньше datetime, остальные задачи отсеиваются после запроса
+			goqu.I("tasks.datetime").Lt(timestamp(now)),
+		).
+		Order(
+			goqu.I("tasks.datetime").Asc(),
+			goqu.I("tasks.id").Asc(),
+		)
+
+	if filters.UserID != 0 {
+		query = query.Where(goqu.I("tasks.user_id").Eq(filters.UserID))
+	}
+	if filters.WithoutActiveBlockers {
+		activeBlockers := dialect().
+			From(goqu.T("task_dependencies").As("d")).
+			InnerJoin(
+				goqu.T("tasks").As("t"),
+				goqu.On(goqu.I("t.id").Eq(goqu.I("d.blocked_by_task_id"))),
+			).
+			Select(goqu.L("1")).
+			Where(
+				goqu.I("d.task_id").Eq(goqu.I("tasks.id")),
+				goqu.I("t.done").IsFalse(),
+			)
+		query = query.Where(goqu.L("NOT EXISTS ?", activeBlockers))
+	}
+	if filters.WithoutBotBlocked {
+		query = query.Where(notInQuery(goqu.I("tasks.user_id"), botBlockedUsers()))
+	}
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	rows, err := repository.dbInstance.QueryContext(ctx, sql, args...)
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> TasksRepository -> GetOverdue -> repository.dbInstance.QueryContext(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return []models.Task{}, err
+	}
+	defer rows.Close()
+
+	var tasks []models.Task
+	for rows.Next() {
+		var task models.Task
+		var timezone string
+		err = rows.Scan(taskDest(&task, &timezone)...)
+		if err != nil {
+			repository.logger.Debugw(
+				`Repositories -> SQLite -> TasksRepository -> GetOverdue -> rows.Scan()`,
+				"error", err.Error(),
+			)
+			return []models.Task{}, err
+		}
+
+		if taskDeadline(task, timezone).Before(now) {
+			tasks = append(tasks, task)
+		}
+	}
+
+	err = rows.Err()
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> TasksRepository -> GetOverdue -> rows.Err()`,
+			"error", err.Error(),
+		)
+		return []models.Task{}, err
+	}
+
+	return tasks, nil
+}
+
+// CountForUser (ctx, userID) -> numbers of active and completed tasks of the user
+func (repository *TasksRepository) CountForUser(ctx context.Context, userID int64) (int64, int64, error) {
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	query := dialect().
+		From("tasks").
+		Select(
+			goqu.L("COUNT(*) FILTER (WHERE done = 0)"),
+			goqu.L("COUNT(*) FILTER (WHERE done = 1)"),
+		).
+		Where(
+			goqu.C("user_id").Eq(userID),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	var active, completed int64
+	err := repository.dbInstance.QueryRowContext(ctx, sql, args...).Scan(&active, &completed)
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> TasksRepository -> CountForUser -> row.Scan()`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return 0, 0, err
+	}
+
+	return active, completed, nil
+}
@@ -0,0 +1,66 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"go.uber.org/zap"
+	"tg_todo_bot/src/repositories/types"
+	"time"
+)
+
+// UnitOfWork runs calls of several repositories in one transaction
+type UnitOfWork struct {
+	logger       *zap.SugaredLogger
+	dbInstance   *sql.DB
+	queryTimeout time.Duration
+}
+
+func NewUnitOfWork(
+	logger *zap.SugaredLogger,
+	dbInstance *sql.DB,
+	queryTimeout time.Duration,
+) *UnitOfWork {
+	return &UnitOfWork{
+		logger:       logger,
+		dbInstance:   dbInstance,
+		queryTimeout: queryTimeout,
+	}
+}
+
+// Do (ctx, fn) -> fn gets repositories bound to one transaction, it's committed if fn returns nil
+// and rolled back otherwise. The error of fn is returned as is
+func (unitOfWork *UnitOfWork) Do(ctx context.Context, fn func(repositories types.TxRepositories) error) error {
+	//Дедлайны получают запросы репозиториев, транзакция живёт, пока жив ctx
+	tx, err := unitOfWork.dbInstance.BeginTx(ctx, nil)
+	if err != nil {
+		unitOfWork.logger.Debugw(
+			`Repositories -> SQLite -> UnitOfWork -> Do -> unitOfWork.dbInstance.BeginTx()`,
+			"error", err.Error(),
+		)
+		return err
+	}
+	//После Commit откат ничего не делает
+	defer tx.Rollback()
+
+	err = fn(types.TxRepositories{
+		Tasks:         &TasksRepository{logger: unitOfWork.logger, dbInstance: tx, queryTimeout: unitOfWork.queryTimeout},
+		TaskTags:      &TaskTagsRepository{logger: unitOfWork.logger, dbInstance: tx, queryTimeout: unitOfWork.queryTimeout},
+		Notifications: &NotificationsRepository{logger: unitOfWork.logger, dbInstance: tx, queryTimeout: unitOfWork.queryTimeout},
+		Users:         &UsersRepository{logger: unitOfWork.logger, dbInstance: tx, queryTimeout: unitOfWork.queryTimeout},
+		CalDAVObjects: &CalDAVObjectsRepository{logger: unitOfWork.logger, dbInstance: tx, queryTimeout: unitOfWork.queryTimeout},
+	})
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		unitOfWork.logger.Debugw(
+			`Repositories -> SQLite -> UnitOfWork -> Do -> tx.Commit()`,
+			"error", err.Error(),
+		)
+		return err
+	}
+
+	return nil
+}
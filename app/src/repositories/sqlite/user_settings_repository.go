@@ -0,0 +1,266 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"tg_todo_bot/src/models"
+	"time"
+)
+
+// Дата последнего дайджеста хранится текстом, как её выводит DATE в PostgreSQL
+const digestDateLayout = "2006-01-02"
+
+type UserSettingsRepository struct {
+	logger       *zap.SugaredLogger
+	dbInstance   querier
+	queryTimeout time.Duration
+}
+
+func NewUserSettingsRepository(
+	logger *zap.SugaredLogger,
+	dbInstance *sql.DB,
+	queryTimeout time.Duration,
+) *UserSettingsRepository {
+	return &UserSettingsRepository{
+		logger:       logger,
+		dbInstance:   dbInstance,
+		queryTimeout: queryTimeout,
+	}
+}
+
+// Save (ctx, settings) -> creates or replaces settings of the user, LastDigestOn isn't changed
+func (repository *UserSettingsRepository) Save(ctx context.Context, settings models.UserSettings) (models.UserSettings, error) {
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	now := time.Now()
+	record := goqu.Record{
+		"language":        settings.Language,
+		"timezone":        settings.Timezone,
+		"reminder_offset": settings.ReminderOffset,
+		"repeat_interval": settings.RepeatInterval,
+		"list_sort":       settings.ListSort,
+		"digest_time":     settings.DigestMinute,
+		"updated_at":      timestamp(now),
+	}
+
+	insertRecord := goqu.Record{"user_id": settings.UserID}
+	updateRecord := goqu.Record{}
+	for column, value := range record {
+		insertRecord[column] = value
+		updateRecord[column] = goqu.I("excluded." + column)
+	}
+
+	query := dialect().
+		Insert("user_settings").
+		Rows(insertRecord).
+		OnConflict(
+			goqu.DoUpdate("user_id", updateRecord),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	_, err := repository.dbInstance.ExecContext(ctx, sql, args...)
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> UserSettingsRepository -> Save -> repository.dbInstance.ExecContext(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return models.UserSettings{}, err
+	}
+	settings.UpdatedAt = now
+
+	return settings, nil
+}
+
+func (repository *UserSettingsRepository) selectAllCols() *goqu.SelectDataset {
+	return dialect().
+		From("user_settings").
+		Select(
+			goqu.C("user_id"),
+			goqu.C("language"),
+			goqu.C("timezone"),
+			goqu.C("reminder_offset"),
+			goqu.C("repeat_interval"),
+			goqu.C("list_sort"),
+			goqu.C("digest_time"),
+			goqu.C("last_digest_on"),
+			goqu.C("updated_at"),
+		)
+}
+
+// nullDateScanner -> reads a date written with digestDateLayout
+type nullDateScanner struct {
+	dest **time.Time
+}
+
+func (scanner nullDateScanner) Scan(value interface{}) error {
+	if value == nil {
+		*scanner.dest = nil
+		return nil
+	}
+
+	text, ok := value.(string)
+	if !ok {
+		return errors.Errorf("unexpected date value %v", value)
+	}
+	date, err := time.Parse(digestDateLayout, text)
+	if err != nil {
+		return err
+	}
+	*scanner.dest = &date
+
+	return nil
+}
+
+func scanUserSettings(row scanner) (models.UserSettings, error) {
+	var settings models.UserSettings
+	err := row.Scan(
+		&settings.UserID,
+		&settings.Language,
+		&settings.Timezone,
+		&settings.ReminderOffset,
+		&settings.RepeatInterval,
+		&settings.ListSort,
+		&settings.DigestMinute,
+		nullDateScanner{dest: &settings.LastDigestOn},
+		timeScanner{dest: &settings.UpdatedAt},
+	)
+
+	return settings, err
+}
+
+func (repository *UserSettingsRepository) FindByUserID(ctx context.Context, userID int64) (models.UserSettings, error) {
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	query := repository.selectAllCols().
+		Where(
+			goqu.C("user_id").Eq(userID),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	settings, err := scanUserSettings(repository.dbInstance.QueryRowContext(ctx, sql, args...))
+	if err != nil {
+		err = queryError(ctx, notFoundError(err))
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> UserSettingsRepository -> FindByUserID -> row.Scan()`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return models.UserSettings{}, err
+	}
+
+	return settings, nil
+}
+
+func (repository *UserSettingsRepository) findAll(ctx context.Context, query *goqu.SelectDataset) ([]models.UserSettings, error) {
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	rows, err := repository.dbInstance.QueryContext(ctx, sql, args...)
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> UserSettingsRepository -> findAll -> repository.dbInstance.QueryContext(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return []models.UserSettings{}, err
+	}
+	defer rows.Close()
+
+	var settingsList []models.UserSettings
+	for rows.Next() {
+		settings, err := scanUserSettings(rows)
+		if err != nil {
+			repository.logger.Debugw(
+				`Repositories -> SQLite -> UserSettingsRepository -> findAll -> rows.Scan()`,
+				"error", err.Error(),
+			)
+			return []models.UserSettings{}, err
+		}
+
+		settingsList = append(settingsList, settings)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> UserSettingsRepository -> findAll -> rows.Err()`,
+			"error", err.Error(),
+		)
+		return []models.UserSettings{}, err
+	}
+
+	return settingsList, nil
+}
+
+// FindByUsersIDs (ctx, usersIDs) -> return map[UserID]UserSettings, users without settings are missed
+func (repository *UserSettingsRepository) FindByUsersIDs(ctx context.Context, usersIDs []int64) (map[int64]models.UserSettings, error) {
+	if len(usersIDs) == 0 {
+		return map[int64]models.UserSettings{}, nil
+	}
+
+	settingsList, err := repository.findAll(ctx, repository.selectAllCols().
+		Where(
+			goqu.C("user_id").In(usersIDs),
+		),
+	)
+	if err != nil {
+		return map[int64]models.UserSettings{}, err
+	}
+
+	usersSettingsMap := map[int64]models.UserSettings{}
+	for _, settings := range settingsList {
+		usersSettingsMap[settings.UserID] = settings
+	}
+
+	return usersSettingsMap, nil
+}
+
+// GetWithDigest -> settings of users who turned the digest on and didn't block the bot
+func (repository *UserSettingsRepository) GetWithDigest(ctx context.Context) ([]models.UserSettings, error) {
+	return repository.findAll(ctx, repository.selectAllCols().
+		Where(
+			goqu.C("digest_time").IsNotNull(),
+			notInQuery(goqu.C("user_id"), botBlockedUsers()),
+		),
+	)
+}
+
+func (repository *UserSettingsRepository) SetLastDigestOn(ctx context.Context, userID int64, date time.Time) error {
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	query := dialect().
+		Update("user_settings").
+		Set(
+			goqu.Record{
+				"last_digest_on": date.Format(digestDateLayout),
+			},
+		).
+		Where(
+			goqu.C("user_id").Eq(userID),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	_, err := repository.dbInstance.ExecContext(ctx, sql, args...)
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> UserSettingsRepository -> SetLastDigestOn -> repository.dbInstance.ExecContext(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return err
+	}
+
+	return nil
+}
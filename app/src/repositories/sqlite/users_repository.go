@@ -0,0 +1,469 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"tg_todo_bot/src/models"
+	"tg_todo_bot/src/repositories/types"
+	"time"
+)
+
+type UsersRepository struct {
+	logger       *zap.SugaredLogger
+	dbInstance   querier
+	queryTimeout time.Duration
+}
+
+func NewUsersRepository(
+	logger *zap.SugaredLogger,
+	dbInstance *sql.DB,
+	queryTimeout time.Duration,
+) *UsersRepository {
+	return &UsersRepository{
+		logger:       logger,
+		dbInstance:   dbInstance,
+		queryTimeout: queryTimeout,
+	}
+}
+
+func (repository *UsersRepository) Create(ctx context.Context, user models.User) (models.User, error) {
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	query := dialect().
+		Insert("users").
+		Rows(
+			goqu.Record{
+				"telegram_id": user.TelegramID,
+				"created_at":  timestamp(time.Now()),
+			},
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	result, err := repository.dbInstance.ExecContext(ctx, sql, args...)
+	if err == nil {
+		user.ID, err = result.LastInsertId()
+	}
+	if err != nil {
+		//Нарушение уникальности
+		if isUniqueViolation(err) {
+			err = types.ErrAlreadyExist
+		}
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> UsersRepository -> Create -> repository.dbInstance.ExecContext(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return models.User{}, err
+	}
+
+	return user, nil
+}
+
+func (repository *UsersRepository) selectAllCols() *goqu.SelectDataset {
+	return dialect().
+		From("users").
+		Select(
+			goqu.C("id"),
+			goqu.C("telegram_id"),
+			goqu.C("created_at"),
+			goqu.C("deletion_requested_at"),
+			goqu.C("last_seen_at"),
+			goqu.C("banned_at"),
+			goqu.C("ban_reason"),
+			goqu.C("bot_blocked_at"),
+		)
+}
+
+// find (ctx, method, query) -> the only user selected by the query, types.ErrNotFound if there is none
+func (repository *UsersRepository) find(ctx context.Context, method string, query *goqu.SelectDataset) (models.User, error) {
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	user, err := scanUser(repository.dbInstance.QueryRowContext(ctx, sql, args...))
+	if err != nil {
+		err = queryError(ctx, notFoundError(err))
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> UsersRepository -> `+method+` -> row.Scan()`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return models.User{}, err
+	}
+
+	return user, nil
+}
+
+// query (ctx, method, query) -> the users selected by the query, errors are logged for the method
+func (repository *UsersRepository) query(ctx context.Context, method string, query *goqu.SelectDataset) ([]models.User, error) {
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	rows, err := repository.dbInstance.QueryContext(ctx, sql, args...)
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> UsersRepository -> `+method+` -> repository.dbInstance.QueryContext(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return []models.User{}, err
+	}
+	defer rows.Close()
+
+	var users []models.User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			repository.logger.Debugw(
+				`Repositories -> SQLite -> UsersRepository -> `+method+` -> rows.Scan()`,
+				"error", err.Error(),
+			)
+			return []models.User{}, err
+		}
+		users = append(users, user)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> UsersRepository -> `+method+` -> rows.Err()`,
+			"error", err.Error(),
+		)
+		return []models.User{}, err
+	}
+
+	return users, nil
+}
+
+func (repository *UsersRepository) FindByTelegramID(ctx context.Context, telegramID int64) (models.User, error) {
+	query := repository.selectAllCols().
+		Where(
+			goqu.C("telegram_id").Eq(telegramID),
+		)
+
+	return repository.find(ctx, "FindByTelegramID", query)
+}
+
+func (repository *UsersRepository) FindByID(ctx context.Context, ID int64) (models.User, error) {
+	query := repository.selectAllCols().
+		Where(
+			goqu.C("id").Eq(ID),
+		)
+
+	return repository.find(ctx, "FindByID", query)
+}
+
+func (repository *UsersRepository) DeleteByTelegramID(ctx context.Context, telegramID int64) error {
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	query := dialect().
+		Delete("users").
+		Where(
+			goqu.C("telegram_id").Eq(telegramID),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	_, err := repository.dbInstance.ExecContext(ctx, sql, args...)
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> UsersRepository -> DeleteByTelegramID -> repository.dbInstance.ExecContext(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return err
+	}
+
+	return nil
+}
+
+// SetDeletionRequestedAt (ctx, userID, requestedAt) -> nil requestedAt cancels the deletion, types.ErrNotFound if there is no user
+func (repository *UsersRepository) SetDeletionRequestedAt(ctx context.Context, userID int64, requestedAt *time.Time) error {
+	return repository.update(ctx, "SetDeletionRequestedAt", userID, goqu.Record{
+		"deletion_requested_at": nullTimestamp(requestedAt),
+	})
+}
+
+// GetDeletionRequestedBefore (ctx, requestedBefore) -> users who requested the deletion before the time, the oldest first
+func (repository *UsersRepository) GetDeletionRequestedBefore(ctx context.Context, requestedBefore time.Time) ([]models.User, error) {
+	query := repository.selectAllCols().
+		Where(
+			goqu.C("deletion_requested_at").Lte(timestamp(requestedBefore)),
+		).
+		Order(
+			goqu.C("deletion_requested_at").Asc(),
+		)
+
+	return repository.query(ctx, "GetDeletionRequestedBefore", query)
+}
+
+// SetLastSeenAt (ctx, userID, seenAt) -> types.ErrNotFound if there is no user
+func (repository *UsersRepository) SetLastSeenAt(ctx context.Context, userID int64, seenAt time.Time) error {
+	return repository.update(ctx, "SetLastSeenAt", userID, goqu.Record{
+		"last_seen_at": timestamp(seenAt),
+	})
+}
+
+// SetBotBlockedAt (ctx, userID, blockedAt) -> nil blockedAt makes the user reachable again,
+// types.ErrNotFound if there is no user
+func (repository *UsersRepository) SetBotBlockedAt(ctx context.Context, userID int64, blockedAt *time.Time) error {
+	return repository.update(ctx, "SetBotBlockedAt", userID, goqu.Record{
+		"bot_blocked_at": nullTimestamp(blockedAt),
+	})
+}
+
+// SetBan (ctx, userID, bannedAt, reason) -> nil bannedAt unbans the user and clears the reason,
+// types.ErrNotFound if there is no user
+func (repository *UsersRepository) SetBan(ctx context.Context, userID int64, bannedAt *time.Time, reason string) error {
+	if bannedAt == nil {
+		reason = ""
+	}
+
+	return repository.update(ctx, "SetBan", userID, goqu.Record{
+		"banned_at":  nullTimestamp(bannedAt),
+		"ban_reason": reason,
+	})
+}
+
+func (repository *UsersRepository) update(ctx context.Context, method string, userID int64, record goqu.Record) error {
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	query := dialect().
+		Update("users").
+		Set(record).
+		Where(
+			goqu.C("id").Eq(userID),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	result, err := repository.dbInstance.ExecContext(ctx, sql, args...)
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> UsersRepository -> `+method+` -> repository.dbInstance.ExecContext(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return err
+	}
+
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return types.ErrNotFound
+	}
+
+	return nil
+}
+
+// GetPage (ctx, afterUserID, limit) -> users with ID greater than afterUserID, by ID
+func (repository *UsersRepository) GetPage(ctx context.Context, afterUserID int64, limit uint) ([]models.User, error) {
+	query := repository.selectAllCols().
+		Where(
+			goqu.C("id").Gt(afterUserID),
+		).
+		Order(
+			goqu.C("id").Asc(),
+		).
+		Limit(limit)
+
+	return repository.query(ctx, "GetPage", query)
+}
+
+// Count (ctx, filter) -> number of users matching the filter
+func (repository *UsersRepository) Count(ctx context.Context, filter types.UsersCountFilter) (int64, error) {
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	query := dialect().
+		From("users").
+		Select(goqu.COUNT("*"))
+
+	if filter.CreatedFrom != nil {
+		query = query.Where(goqu.C("created_at").Gte(timestamp(*filter.CreatedFrom)))
+	}
+	if filter.SeenFrom != nil {
+		query = query.Where(goqu.C("last_seen_at").Gte(timestamp(*filter.SeenFrom)))
+	}
+	if filter.Banned {
+		query = query.Where(goqu.C("banned_at").IsNotNull())
+	}
+	if filter.DeletionRequested {
+		query = query.Where(goqu.C("deletion_requested_at").IsNotNull())
+	}
+	if filter.BotBlocked {
+		query = query.Where(goqu.C("bot_blocked_at").IsNotNull())
+	}
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	var count int64
+	err := repository.dbInstance.QueryRowContext(ctx, sql, args...).Scan(&count)
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> UsersRepository -> Count -> row.Scan()`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return 0, err
+	}
+
+	return count, nil
+}
+
+type userDataTable struct {
+	name  string
+	where exp.Expression
+}
+
+// userDataTables (userID) -> tables of the SQLite schema with rows of the user, children before parents:
+// a row deleted by a cascade wouldn't be counted in the table it belongs to
+func userDataTables(userID int64) []userDataTable {
+	userTasks := dialect().
+		From("tasks").
+		Select("id").
+		Where(goqu.C("user_id").Eq(userID))
+
+	return []userDataTable{
+		{name: "notifications", where: inQuery(goqu.C("task_id"), userTasks)},
+		{name: "task_tags", where: inQuery(goqu.C("task_id"), userTasks)},
+		{name: "task_dependencies", where: goqu.Or(
+			inQuery(goqu.C("task_id"), userTasks),
+			inQuery(goqu.C("blocked_by_task_id"), userTasks),
+		)},
+		{name: "tasks", where: goqu.C("user_id").Eq(userID)},
+		{name: "user_settings", where: goqu.C("user_id").Eq(userID)},
+		{name: "outbox", where: goqu.C("user_id").Eq(userID)},
+		{name: "users", where: goqu.C("id").Eq(userID)},
+	}
+}
+
+// Purge (ctx, userID) -> deletes the user and all rows of the user table by table in one transaction and
+// returns the number of deleted rows by table. Before the commit every table is checked to have no rows
+// of the user left, otherwise nothing is deleted. ErrNotFound if there is no such user
+func (repository *UsersRepository) Purge(ctx context.Context, userID int64) (map[string]int64, error) {
+	return repository.purge(ctx, userID, nil)
+}
+
+// PurgeRequestedBefore (ctx, userID, requestedBefore) -> like Purge, but only if the user requested the deletion
+// before the time. ErrNotFound if there is no such user or the deletion isn't requested anymore
+func (repository *UsersRepository) PurgeRequestedBefore(ctx context.Context, userID int64, requestedBefore time.Time) (map[string]int64, error) {
+	return repository.purge(ctx, userID, &requestedBefore)
+}
+
+func (repository *UsersRepository) purge(ctx context.Context, userID int64, requestedBefore *time.Time) (map[string]int64, error) {
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	tx, err := begin(ctx, repository.dbInstance)
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> UsersRepository -> Purge -> begin(ctx, repository.dbInstance)`,
+			"error", err.Error(),
+		)
+		return nil, err
+	}
+	//После Commit откат ничего не делает
+	defer tx.Rollback()
+
+	//Соединение одно, поэтому отмена удаления не может попасть между проверкой и удалением
+	if requestedBefore != nil {
+		query := dialect().
+			From("users").
+			Select("id").
+			Where(
+				goqu.C("id").Eq(userID),
+				goqu.C("deletion_requested_at").Lte(timestamp(*requestedBefore)),
+			)
+
+		sql, args, _ := query.Prepared(true).ToSQL()
+
+		var lockedID int64
+		err = notFoundError(tx.QueryRowContext(ctx, sql, args...).Scan(&lockedID))
+		if errors.Is(err, types.ErrNotFound) {
+			return nil, err
+		}
+		if err != nil {
+			err = queryError(ctx, err)
+			repository.logger.Debugw(
+				`Repositories -> SQLite -> UsersRepository -> Purge -> tx.QueryRowContext(sql, args...).Scan()`,
+				"error", err.Error(), "SQL", sql, "args", args,
+			)
+			return nil, err
+		}
+	}
+
+	deleted := map[string]int64{}
+	for _, table := range userDataTables(userID) {
+		query := dialect().
+			Delete(table.name).
+			Where(table.where)
+
+		sql, args, _ := query.Prepared(true).ToSQL()
+
+		result, err := tx.ExecContext(ctx, sql, args...)
+		if err != nil {
+			err = queryError(ctx, err)
+			repository.logger.Debugw(
+				`Repositories -> SQLite -> UsersRepository -> Purge -> tx.ExecContext(sql, args...)`,
+				"error", err.Error(), "SQL", sql, "args", args,
+			)
+			return nil, err
+		}
+		deleted[table.name], err = result.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if deleted["users"] == 0 {
+		return nil, types.ErrNotFound
+	}
+
+	for _, table := range userDataTables(userID) {
+		query := dialect().
+			From(table.name).
+			Select(goqu.COUNT("*")).
+			Where(table.where)
+
+		sql, args, _ := query.Prepared(true).ToSQL()
+
+		var left int64
+		err = tx.QueryRowContext(ctx, sql, args...).Scan(&left)
+		if err != nil {
+			err = queryError(ctx, err)
+			repository.logger.Debugw(
+				`Repositories -> SQLite -> UsersRepository -> Purge -> tx.QueryRowContext(sql, args...).Scan()`,
+				"error", err.Error(), "SQL", sql, "args", args,
+			)
+			return nil, err
+		}
+		if left > 0 {
+			return nil, errors.Errorf("%d rows of the user are left in %s", left, table.name)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> UsersRepository -> Purge -> tx.Commit()`,
+			"error", err.Error(),
+		)
+		return nil, err
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,257 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"github.com/doug-martin/goqu/v9"
+	"go.uber.org/zap"
+	"tg_todo_bot/src/models"
+	"time"
+)
+
+type WebhookDeliveriesRepository struct {
+	logger       *zap.SugaredLogger
+	dbInstance   querier
+	queryTimeout time.Duration
+}
+
+func NewWebhookDeliveriesRepository(
+	logger *zap.SugaredLogger,
+	dbInstance *sql.DB,
+	queryTimeout time.Duration,
+) *WebhookDeliveriesRepository {
+	return &WebhookDeliveriesRepository{
+		logger:       logger,
+		dbInstance:   dbInstance,
+		queryTimeout: queryTimeout,
+	}
+}
+
+func (repository *WebhookDeliveriesRepository) Create(ctx context.Context, delivery models.WebhookDelivery) (models.WebhookDelivery, error) {
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	now := time.Now()
+	query := dialect().
+		Insert("webhook_deliveries").
+		Rows(
+			goqu.Record{
+				"webhook_id":      delivery.WebhookID,
+				"event_type":      delivery.EventType,
+				"payload":         string(delivery.Payload),
+				"attempts":        delivery.Attempts,
+				"next_attempt_at": nullTimestamp(delivery.NextAttemptAt),
+				"last_error":      delivery.LastError,
+				"delivered_at":    nullTimestamp(delivery.DeliveredAt),
+				"created_at":      timestamp(now),
+			},
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	result, err := repository.dbInstance.ExecContext(ctx, sql, args...)
+	if err == nil {
+		delivery.ID, err = result.LastInsertId()
+	}
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> WebhookDeliveriesRepository -> Create -> repository.dbInstance.ExecContext(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return models.WebhookDelivery{}, err
+	}
+	delivery.CreatedAt = now
+
+	return delivery, nil
+}
+
+// Update (ctx, delivery) -> saves the result of a delivery attempt
+func (repository *WebhookDeliveriesRepository) Update(ctx context.Context, delivery models.WebhookDelivery) error {
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	query := dialect().
+		Update("webhook_deliveries").
+		Set(
+			goqu.Record{
+				"attempts":        delivery.Attempts,
+				"next_attempt_at": nullTimestamp(delivery.NextAttemptAt),
+				"last_error":      delivery.LastError,
+				"delivered_at":    nullTimestamp(delivery.DeliveredAt),
+			},
+		).
+		Where(
+			goqu.C("id").Eq(delivery.ID),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	_, err := repository.dbInstance.ExecContext(ctx, sql, args...)
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> WebhookDeliveriesRepository -> Update -> repository.dbInstance.ExecContext(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return err
+	}
+
+	return nil
+}
+
+// Claim (ctx, now, lease, limit) -> due deliveries, the oldest first, with their webhooks. Their next attempt is moved
+// by lease, so if the process dies before Update they are sent again after the lease.
+// The deliveries are read and moved in one transaction, SQLite has only one writer at a time
+func (repository *WebhookDeliveriesRepository) Claim(
+	ctx context.Context,
+	now time.Time,
+	lease time.Duration,
+	limit uint,
+) ([]models.WebhookDelivery, error) {
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	tx, err := begin(ctx, repository.dbInstance)
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> WebhookDeliveriesRepository -> Claim -> begin(ctx, repository.dbInstance)`,
+			"error", err.Error(),
+		)
+		return []models.WebhookDelivery{}, err
+	}
+	//После Commit откат ничего не делает
+	defer tx.Rollback()
+
+	query := dialect().
+		From(goqu.T("webhook_deliveries").As("d")).
+		InnerJoin(
+			goqu.T("webhooks").As("w"),
+			goqu.On(goqu.I("w.id").Eq(goqu.I("d.webhook_id"))),
+		).
+		Select(
+			goqu.I("d.id"),
+			goqu.I("d.webhook_id"),
+			goqu.I("d.event_type"),
+			goqu.I("d.payload"),
+			goqu.I("d.attempts"),
+			goqu.I("d.next_attempt_at"),
+			goqu.I("d.last_error"),
+			goqu.I("d.delivered_at"),
+			goqu.I("d.created_at"),
+			goqu.I("w.user_id"),
+			goqu.I("w.url"),
+			goqu.I("w.secret"),
+			goqu.I("w.created_at"),
+		).
+		Where(
+			goqu.I("d.next_attempt_at").Lte(timestamp(now)),
+		).
+		Order(
+			goqu.I("d.next_attempt_at").Asc(),
+			goqu.I("d.id").Asc(),
+		).
+		Limit(limit)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	rows, err := tx.QueryContext(ctx, sql, args...)
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> WebhookDeliveriesRepository -> Claim -> tx.QueryContext(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return []models.WebhookDelivery{}, err
+	}
+	defer rows.Close()
+
+	var deliveries []models.WebhookDelivery
+	var deliveriesIDs []int64
+	for rows.Next() {
+		var delivery models.WebhookDelivery
+		var webhook models.Webhook
+		err = rows.Scan(
+			&delivery.ID,
+			&delivery.WebhookID,
+			&delivery.EventType,
+			&delivery.Payload,
+			&delivery.Attempts,
+			nullTimeScanner{dest: &delivery.NextAttemptAt},
+			&delivery.LastError,
+			nullTimeScanner{dest: &delivery.DeliveredAt},
+			timeScanner{dest: &delivery.CreatedAt},
+			&webhook.UserID,
+			&webhook.URL,
+			&webhook.Secret,
+			timeScanner{dest: &webhook.CreatedAt},
+		)
+		if err != nil {
+			repository.logger.Debugw(
+				`Repositories -> SQLite -> WebhookDeliveriesRepository -> Claim -> rows.Scan()`,
+				"error", err.Error(),
+			)
+			return []models.WebhookDelivery{}, err
+		}
+
+		webhook.ID = delivery.WebhookID
+		delivery.Webhook = &webhook
+		deliveries = append(deliveries, delivery)
+		deliveriesIDs = append(deliveriesIDs, delivery.ID)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> WebhookDeliveriesRepository -> Claim -> rows.Err()`,
+			"error", err.Error(),
+		)
+		return []models.WebhookDelivery{}, err
+	}
+	rows.Close()
+
+	if len(deliveries) == 0 {
+		return deliveries, nil
+	}
+
+	leasedUntil := now.Add(lease)
+	updateQuery := dialect().
+		Update("webhook_deliveries").
+		Set(
+			goqu.Record{
+				"next_attempt_at": timestamp(leasedUntil),
+			},
+		).
+		Where(
+			goqu.C("id").In(deliveriesIDs),
+		)
+
+	sql, args, _ = updateQuery.Prepared(true).ToSQL()
+
+	_, err = tx.ExecContext(ctx, sql, args...)
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> WebhookDeliveriesRepository -> Claim -> tx.ExecContext(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return []models.WebhookDelivery{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> WebhookDeliveriesRepository -> Claim -> tx.Commit()`,
+			"error", err.Error(),
+		)
+		return []models.WebhookDelivery{}, err
+	}
+
+	for i := range deliveries {
+		deliveries[i].NextAttemptAt = &leasedUntil
+	}
+
+	return deliveries, nil
+}
@@ -0,0 +1,166 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"github.com/doug-martin/goqu/v9"
+	"go.uber.org/zap"
+	"tg_todo_bot/src/models"
+	"tg_todo_bot/src/repositories/types"
+	"time"
+)
+
+type WebhooksRepository struct {
+	logger       *zap.SugaredLogger
+	dbInstance   querier
+	queryTimeout time.Duration
+}
+
+func NewWebhooksRepository(
+	logger *zap.SugaredLogger,
+	dbInstance *sql.DB,
+	queryTimeout time.Duration,
+) *WebhooksRepository {
+	return &WebhooksRepository{
+		logger:       logger,
+		dbInstance:   dbInstance,
+		queryTimeout: queryTimeout,
+	}
+}
+
+func (repository *WebhooksRepository) Create(ctx context.Context, webhook models.Webhook) (models.Webhook, error) {
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	now := time.Now()
+	query := dialect().
+		Insert("webhooks").
+		Rows(
+			goqu.Record{
+				"user_id":    webhook.UserID,
+				"url":        webhook.URL,
+				"secret":     webhook.Secret,
+				"created_at": timestamp(now),
+			},
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	result, err := repository.dbInstance.ExecContext(ctx, sql, args...)
+	if err == nil {
+		webhook.ID, err = result.LastInsertId()
+	}
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> WebhooksRepository -> Create -> repository.dbInstance.ExecContext(sql, args...)`,
+			"error", err.Error(), "SQL", sql,
+		)
+		return models.Webhook{}, err
+	}
+	webhook.CreatedAt = now
+
+	return webhook, nil
+}
+
+func (repository *WebhooksRepository) GetAllForUser(ctx context.Context, userID int64) ([]models.Webhook, error) {
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	query := dialect().
+		From("webhooks").
+		Select(
+			goqu.C("id"),
+			goqu.C("user_id"),
+			goqu.C("url"),
+			goqu.C("secret"),
+			goqu.C("created_at"),
+		).
+		Where(
+			goqu.C("user_id").Eq(userID),
+		).
+		Order(
+			goqu.C("id").Asc(),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	rows, err := repository.dbInstance.QueryContext(ctx, sql, args...)
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> WebhooksRepository -> GetAllForUser -> repository.dbInstance.QueryContext(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return []models.Webhook{}, err
+	}
+	defer rows.Close()
+
+	var webhooks []models.Webhook
+	for rows.Next() {
+		var webhook models.Webhook
+		err = rows.Scan(
+			&webhook.ID,
+			&webhook.UserID,
+			&webhook.URL,
+			&webhook.Secret,
+			timeScanner{dest: &webhook.CreatedAt},
+		)
+		if err != nil {
+			repository.logger.Debugw(
+				`Repositories -> SQLite -> WebhooksRepository -> GetAllForUser -> rows.Scan()`,
+				"error", err.Error(),
+			)
+			return []models.Webhook{}, err
+		}
+
+		webhooks = append(webhooks, webhook)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> WebhooksRepository -> GetAllForUser -> rows.Err()`,
+			"error", err.Error(),
+		)
+		return []models.Webhook{}, err
+	}
+
+	return webhooks, nil
+}
+
+// DeleteForUser (ctx, userID, webhookID) -> types.ErrNotFound if the user has no such webhook
+func (repository *WebhooksRepository) DeleteForUser(ctx context.Context, userID, webhookID int64) error {
+	ctx, cancel := withTimeout(ctx, repository.queryTimeout)
+	defer cancel()
+
+	query := dialect().
+		Delete("webhooks").
+		Where(
+			goqu.C("id").Eq(webhookID),
+			goqu.C("user_id").Eq(userID),
+		)
+
+	sql, args, _ := query.Prepared(true).ToSQL()
+
+	result, err := repository.dbInstance.ExecContext(ctx, sql, args...)
+	if err != nil {
+		err = queryError(ctx, err)
+		repository.logger.Debugw(
+			`Repositories -> SQLite -> WebhooksRepository -> DeleteForUser -> repository.dbInstance.ExecContext(sql, args...)`,
+			"error", err.Error(), "SQL", sql, "args", args,
+		)
+		return err
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return types.ErrNotFound
+	}
+
+	return nil
+}
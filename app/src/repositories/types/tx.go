@@ -0,0 +1,43 @@
+package types
+
+import (
+	"context"
+	"tg_todo_bot/src/models"
+)
+
+// TxRepositories -> repositories bound to one transaction of a unit of work, only methods
+// which services call together with others are listed
+type TxRepositories struct {
+	Tasks         TasksTxRepositoryI
+	TaskTags      TaskTagsTxRepositoryI
+	Notifications NotificationsTxRepositoryI
+	Users         UsersTxRepositoryI
+	CalDAVObjects CalDAVObjectsTxRepositoryI
+}
+
+type TasksTxRepositoryI interface {
+	Create(ctx context.Context, task models.Task) (models.Task, error)
+	Update(ctx context.Context, model models.Task) error
+	FindByID(ctx context.Context, ID int64) (models.Task, error)
+	// FindByIDForUpdate (ctx, ID) -> the task locked till the end of the transaction, so it isn't changed between
+	// reading and saving it
+	FindByIDForUpdate(ctx context.Context, ID int64) (models.Task, error)
+}
+
+type TaskTagsTxRepositoryI interface {
+	SetForTask(ctx context.Context, taskID int64, tags []string) error
+}
+
+type NotificationsTxRepositoryI interface {
+	Create(ctx context.Context, notification models.Notification) (models.Notification, error)
+	Update(ctx context.Context, notification models.Notification) error
+	DeleteByID(ctx context.Context, ID int64) error
+}
+
+type CalDAVObjectsTxRepositoryI interface {
+	Save(ctx context.Context, object models.CalDAVObject) error
+}
+
+type UsersTxRepositoryI interface {
+	FindByID(ctx context.Context, ID int64) (models.User, error)
+}
@@ -1,8 +1,100 @@
 package types
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 var (
 	ErrNotFound     = fmt.Errorf("not found")
 	ErrAlreadyExist = fmt.Errorf("already exist")
+	ErrTimeout      = fmt.Errorf("query timeout")
 )
+
+// TimeoutError -> a query didn't finish before the deadline of its context, errors.Is(err, ErrTimeout) is true for it,
+// the error of the driver is kept
+type TimeoutError struct {
+	Err error
+}
+
+func (err *TimeoutError) Error() string {
+	return ErrTimeout.Error() + ": " + err.Err.Error()
+}
+
+func (err *TimeoutError) Unwrap() error {
+	return err.Err
+}
+
+func (err *TimeoutError) Is(target error) bool {
+	return target == ErrTimeout
+}
+
+// TasksSearchFilters -> nil fields aren't applied
+type TasksSearchFilters struct {
+	Done  *bool
+	From  *time.Time
+	To    *time.Time
+	Limit uint
+}
+
+// TasksOverdueFilters -> UserID 0 means tasks of all users
+type TasksOverdueFilters struct {
+	UserID                int64
+	WithoutActiveBlockers bool
+	WithoutBotBlocked     bool //skip tasks of users who blocked the bot
+}
+
+// TasksCursor -> position of a task in the (datetime NULLS LAST, title, id) order
+type TasksCursor struct {
+	Datetime *time.Time
+	Title    string
+	ID       int64
+}
+
+// TasksPageParams -> at most one of After and Before is expected, without both the first page is returned
+type TasksPageParams struct {
+	After  *TasksCursor
+	Before *TasksCursor
+	Limit  uint
+}
+
+type TasksSortField string
+
+const (
+	TasksSortByDue      TasksSortField = "due"
+	TasksSortByCreated  TasksSortField = "created"
+	TasksSortByTitle    TasksSortField = "title"
+	TasksSortByPriority TasksSortField = "prio"
+)
+
+// TasksFilter -> all set conditions are combined with AND, nil and empty fields aren't applied
+type TasksFilter struct {
+	Done         *bool
+	DueFrom      *time.Time
+	DueTo        *time.Time
+	WithoutDue   bool
+	WithDue      bool
+	Tags         []string
+	ExcludedTags []string
+	PriorityFrom *int
+	PriorityTo   *int
+	Text         string
+	SortBy       TasksSortField
+	SortDesc     bool
+	Limit        uint
+}
+
+// UsersCountFilter -> all set conditions are combined with AND, nil and false fields aren't applied
+type UsersCountFilter struct {
+	CreatedFrom       *time.Time
+	SeenFrom          *time.Time
+	Banned            bool
+	DeletionRequested bool
+	BotBlocked        bool
+}
+
+// RateLimit -> token bucket of Capacity tokens, PerSecond tokens are added back every second
+type RateLimit struct {
+	Capacity  float64
+	PerSecond float64
+}
@@ -0,0 +1,82 @@
+package scheduler
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"tg_todo_bot/src/models"
+	services_types "tg_todo_bot/src/services/types"
+	"time"
+)
+
+const accountDeletionsCheckInterval = time.Hour
+
+// AccountDeletionsJob purges accounts whose grace period after /deleteme is over,
+// the user gets an export of all tasks right before the deletion
+type AccountDeletionsJob struct {
+	logger       *zap.SugaredLogger
+	usersService UsersServiceI
+	sender       SenderI
+}
+
+func NewAccountDeletionsJob(
+	logger *zap.SugaredLogger,
+	usersService UsersServiceI,
+	sender SenderI,
+) *AccountDeletionsJob {
+	return &AccountDeletionsJob{
+		logger:       logger,
+		usersService: usersService,
+		sender:       sender,
+	}
+}
+
+func (job *AccountDeletionsJob) Run(ctx context.Context) {
+	ticker := time.NewTicker(accountDeletionsCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			job.tick(ctx, time.Now())
+		}
+	}
+}
+
+func (job *AccountDeletionsJob) tick(ctx context.Context, now time.Time) {
+	users, err := job.usersService.GetPurgeDue(ctx, now)
+	if err != nil {
+		job.logger.Errorw(
+			"Scheduler -> AccountDeletionsJob -> tick -> job.usersService.GetPurgeDue(ctx, now)",
+			"error", err.Error(),
+		)
+		return
+	}
+
+	for _, user := range users {
+		err = job.purge(ctx, user, now)
+		if errors.Is(err, services_types.ErrNotFound) {
+			job.logger.Infow("Scheduler -> AccountDeletionsJob -> tick -> the deletion is cancelled", "userID", user.ID)
+			continue
+		}
+		if err != nil {
+			job.logger.Errorw(
+				"Scheduler -> AccountDeletionsJob -> tick -> job.purge(ctx, user, now)",
+				"error", err.Error(), "userID", user.ID,
+			)
+		}
+	}
+}
+
+// purge (ctx, user, now) -> the account is kept until the export is sent, the next tick tries again.
+// services_types.ErrNotFound if the user cancelled the deletion meanwhile, the account is kept then
+func (job *AccountDeletionsJob) purge(ctx context.Context, user models.User, now time.Time) error {
+	err := job.sender.SendFinalExport(ctx, user)
+	if err != nil {
+		return err
+	}
+
+	return job.usersService.Purge(ctx, user.ID, now)
+}
@@ -0,0 +1,147 @@
+package scheduler
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"tg_todo_bot/src/models"
+	broadcasts_types "tg_todo_bot/src/services/broadcasts/types"
+	services_types "tg_todo_bot/src/services/types"
+	"time"
+)
+
+const (
+	broadcastsCheckInterval = 10 * time.Second
+	broadcastsPageSize      = 100
+	//Telegram разрешает около 30 сообщений в секунду, остальное остаётся на ответы бота
+	broadcastSendInterval = 50 * time.Millisecond
+)
+
+// BroadcastsJob sends running broadcasts of the admin to all users. The progress is saved after every user,
+// so a broadcast interrupted by a restart continues from the next user instead of starting over
+type BroadcastsJob struct {
+	logger            *zap.SugaredLogger
+	usersService      UsersServiceI
+	broadcastsService BroadcastsServiceI
+	sender            SenderI
+}
+
+func NewBroadcastsJob(
+	logger *zap.SugaredLogger,
+	usersService UsersServiceI,
+	broadcastsService BroadcastsServiceI,
+	sender SenderI,
+) *BroadcastsJob {
+	return &BroadcastsJob{
+		logger:            logger,
+		usersService:      usersService,
+		broadcastsService: broadcastsService,
+		sender:            sender,
+	}
+}
+
+func (job *BroadcastsJob) Run(ctx context.Context) {
+	ticker := time.NewTicker(broadcastsCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			job.tick(ctx)
+		}
+	}
+}
+
+// tick -> sends running broadcasts one by one. A broadcast is claimed before sending,
+// so replicas don't send the same one, and a claim isn't held while another broadcast is sent
+func (job *BroadcastsJob) tick(ctx context.Context) {
+	for ctx.Err() == nil {
+		broadcast, err := job.broadcastsService.ClaimRunning(ctx, time.Now())
+		if errors.Is(err, services_types.ErrNotFound) {
+			return
+		}
+		if err != nil {
+			job.logger.Errorw(
+				"Scheduler -> BroadcastsJob -> tick -> job.broadcastsService.ClaimRunning(ctx, now)",
+				"error", err.Error(),
+			)
+			return
+		}
+
+		err = job.send(ctx, broadcast)
+		if err != nil {
+			job.logger.Errorw(
+				"Scheduler -> BroadcastsJob -> tick -> job.send(ctx, broadcast)",
+				"error", err.Error(), "broadcastID", broadcast.ID,
+			)
+		}
+	}
+}
+
+// send (ctx, broadcast) -> sends the broadcast page by page until all users are processed,
+// the broadcast is cancelled or the bot stops
+func (job *BroadcastsJob) send(ctx context.Context, broadcast models.Broadcast) error {
+	limiter := time.NewTicker(broadcastSendInterval)
+	defer limiter.Stop()
+
+	for {
+		//Рассылку могли отменить, пока отправлялась предыдущая страница
+		current, err := job.broadcastsService.FindByID(ctx, broadcast.ID)
+		if err != nil {
+			return err
+		}
+		if current.Status != broadcasts_types.StatusRunning {
+			return nil
+		}
+
+		users, err := job.usersService.GetPage(ctx, broadcast.LastUserID, broadcastsPageSize)
+		if err != nil {
+			return err
+		}
+		if len(users) == 0 {
+			job.logger.Infow("Scheduler -> BroadcastsJob -> send -> broadcast is finished",
+				"broadcastID", broadcast.ID, "sent", broadcast.Sent, "failed", broadcast.Failed,
+			)
+			return job.broadcastsService.Finish(ctx, broadcast.ID, time.Now())
+		}
+
+		for _, user := range users {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-limiter.C:
+			}
+
+			job.sendToUser(ctx, &broadcast, user)
+
+			broadcast.LastUserID = user.ID
+			err = job.broadcastsService.SaveProgress(ctx, broadcast)
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// sendToUser (ctx, broadcast, user) -> counts the result in broadcast, banned users, users who blocked the bot
+// and accounts waiting for deletion are skipped without counting
+func (job *BroadcastsJob) sendToUser(ctx context.Context, broadcast *models.Broadcast, user models.User) {
+	if user.BannedAt != nil || user.BotBlockedAt != nil || user.DeletionRequestedAt != nil {
+		return
+	}
+
+	err := job.sender.SendBroadcast(ctx, user, broadcast.Text)
+	if err != nil {
+		//Обычно пользователь просто заблокировал бота
+		job.logger.Debugw(
+			"Scheduler -> BroadcastsJob -> sendToUser -> job.sender.SendBroadcast(ctx, user, text)",
+			"error", err.Error(), "broadcastID", broadcast.ID, "userID", user.ID,
+		)
+		broadcast.Failed++
+		return
+	}
+
+	broadcast.Sent++
+}
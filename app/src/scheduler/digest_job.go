@@ -0,0 +1,82 @@
+package scheduler
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"tg_todo_bot/src/models"
+	"time"
+)
+
+const digestCheckInterval = time.Minute
+
+// DigestJob sends the list for today at the time chosen by the user in /settings
+type DigestJob struct {
+	logger          *zap.SugaredLogger
+	usersService    UsersServiceI
+	settingsService SettingsServiceI
+	sender          SenderI
+}
+
+func NewDigestJob(
+	logger *zap.SugaredLogger,
+	usersService UsersServiceI,
+	settingsService SettingsServiceI,
+	sender SenderI,
+) *DigestJob {
+	return &DigestJob{
+		logger:          logger,
+		usersService:    usersService,
+		settingsService: settingsService,
+		sender:          sender,
+	}
+}
+
+func (job *DigestJob) Run(ctx context.Context) {
+	ticker := time.NewTicker(digestCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			job.tick(ctx, time.Now())
+		}
+	}
+}
+
+func (job *DigestJob) tick(ctx context.Context, now time.Time) {
+	settingsList, err := job.settingsService.GetDigestDue(ctx, now)
+	if err != nil {
+		job.logger.Errorw(
+			"Scheduler -> DigestJob -> tick -> job.settingsService.GetDigestDue(now)",
+			"error", err.Error(),
+		)
+		return
+	}
+
+	for _, settings := range settingsList {
+		err = job.send(ctx, settings, now)
+		if err != nil {
+			job.logger.Errorw(
+				"Scheduler -> DigestJob -> tick -> job.send(ctx, settings, now)",
+				"error", err.Error(), "userID", settings.UserID,
+			)
+		}
+	}
+}
+
+func (job *DigestJob) send(ctx context.Context, settings models.UserSettings, now time.Time) error {
+	user, err := job.usersService.FindByID(ctx, settings.UserID)
+	if err != nil {
+		return err
+	}
+	user.Settings = &settings
+
+	err = job.sender.SendDigest(ctx, user)
+	if err != nil {
+		return err
+	}
+
+	return job.settingsService.MarkDigestSent(ctx, settings, now)
+}
@@ -0,0 +1,82 @@
+package scheduler
+
+import (
+	"context"
+	"go.uber.org/zap"
+	escalations_types "tg_todo_bot/src/services/escalations/types"
+	"time"
+)
+
+const escalationsCheckInterval = 5 * time.Minute
+
+// EscalationsJob reminds about overdue tasks according to escalation policies.
+// There are no shared lists yet, so escalations go to the owner of the task only.
+type EscalationsJob struct {
+	logger             *zap.SugaredLogger
+	usersService       UsersServiceI
+	escalationsService EscalationsServiceI
+	sender             SenderI
+}
+
+func NewEscalationsJob(
+	logger *zap.SugaredLogger,
+	usersService UsersServiceI,
+	escalationsService EscalationsServiceI,
+	sender SenderI,
+) *EscalationsJob {
+	return &EscalationsJob{
+		logger:             logger,
+		usersService:       usersService,
+		escalationsService: escalationsService,
+		sender:             sender,
+	}
+}
+
+func (job *EscalationsJob) Run(ctx context.Context) {
+	ticker := time.NewTicker(escalationsCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			job.tick(ctx, time.Now())
+		}
+	}
+}
+
+func (job *EscalationsJob) tick(ctx context.Context, now time.Time) {
+	escalations, err := job.escalationsService.GetDue(ctx, now)
+	if err != nil {
+		job.logger.Errorw(
+			"Scheduler -> EscalationsJob -> tick -> job.escalationsService.GetDue(ctx, now)",
+			"error", err.Error(),
+		)
+		return
+	}
+
+	for _, escalation := range escalations {
+		err = job.escalate(ctx, escalation, now)
+		if err != nil {
+			job.logger.Errorw(
+				"Scheduler -> EscalationsJob -> tick -> job.escalate(ctx, escalation, now)",
+				"error", err.Error(), "taskID", escalation.Task.ID, "level", escalation.Level,
+			)
+		}
+	}
+}
+
+func (job *EscalationsJob) escalate(ctx context.Context, escalation escalations_types.Escalation, now time.Time) error {
+	user, err := job.usersService.FindByID(ctx, escalation.Task.UserID)
+	if err != nil {
+		return err
+	}
+
+	err = job.sender.SendEscalation(ctx, user, escalation)
+	if err != nil {
+		return err
+	}
+
+	return job.escalationsService.MarkEscalated(ctx, escalation, now)
+}
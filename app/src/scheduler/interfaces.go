@@ -0,0 +1,62 @@
+package scheduler
+
+import (
+	"context"
+	"tg_todo_bot/src/models"
+	escalations_types "tg_todo_bot/src/services/escalations/types"
+	"time"
+)
+
+type UsersServiceI interface {
+	FindByID(ctx context.Context, userID int64) (models.User, error)
+	GetPurgeDue(ctx context.Context, now time.Time) ([]models.User, error)
+	Purge(ctx context.Context, userID int64, now time.Time) error
+	GetPage(ctx context.Context, afterUserID int64, limit uint) ([]models.User, error)
+}
+
+type TasksServiceI interface {
+	FindByID(ctx context.Context, taskID int64) (models.Task, error)
+}
+
+type NotificationsServiceI interface {
+	GetUpcoming(ctx context.Context, upcomingTo time.Time) ([]models.Notification, error)
+	Fire(ctx context.Context, notification models.Notification, task models.Task, nextNotifyAt, now time.Time) error
+	DeleteByID(ctx context.Context, notificationID int64) error
+}
+
+// SenderI -> texts are built by the bot in the user's language
+type SenderI interface {
+	SendReminder(ctx context.Context, user models.User, task models.Task) error
+	SendEscalation(ctx context.Context, user models.User, escalation escalations_types.Escalation) error
+	SendDigest(ctx context.Context, user models.User) error
+	SendFinalExport(ctx context.Context, user models.User) error
+	SendBroadcast(ctx context.Context, user models.User, text string) error
+}
+
+type OutboxServiceI interface {
+	Claim(ctx context.Context, now time.Time) ([]models.OutboxMessage, error)
+	MarkSent(ctx context.Context, message models.OutboxMessage, now time.Time) error
+	MarkFailed(ctx context.Context, message models.OutboxMessage, sendErr error, now time.Time) error
+	Skip(ctx context.Context, message models.OutboxMessage, reason string) error
+}
+
+type EscalationsServiceI interface {
+	GetDue(ctx context.Context, now time.Time) ([]escalations_types.Escalation, error)
+	MarkEscalated(ctx context.Context, escalation escalations_types.Escalation, now time.Time) error
+}
+
+type SettingsServiceI interface {
+	GetDigestDue(ctx context.Context, now time.Time) ([]models.UserSettings, error)
+	MarkDigestSent(ctx context.Context, settings models.UserSettings, now time.Time) error
+}
+
+type WebhooksServiceI interface {
+	DeliverDue(ctx context.Context, now time.Time) error
+}
+
+type BroadcastsServiceI interface {
+	FindByID(ctx context.Context, broadcastID int64) (models.Broadcast, error)
+	ClaimRunning(ctx context.Context, now time.Time) (models.Broadcast, error)
+	SaveProgress(ctx context.Context, broadcast models.Broadcast) error
+	Finish(ctx context.Context, broadcastID int64, now time.Time) error
+}
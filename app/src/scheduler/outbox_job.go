@@ -0,0 +1,133 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"tg_todo_bot/src/models"
+	"tg_todo_bot/src/sendqueue"
+	outbox_types "tg_todo_bot/src/services/outbox/types"
+	services_types "tg_todo_bot/src/services/types"
+	"time"
+)
+
+const outboxCheckInterval = 5 * time.Second
+
+// OutboxJob sends messages saved to the outbox and marks them sent. A message sent right before the process dies
+// isn't marked and is sent again after the claim expires, so delivery is at least once
+type OutboxJob struct {
+	logger        *zap.SugaredLogger
+	usersService  UsersServiceI
+	tasksService  TasksServiceI
+	outboxService OutboxServiceI
+	sender        SenderI
+}
+
+func NewOutboxJob(
+	logger *zap.SugaredLogger,
+	usersService UsersServiceI,
+	tasksService TasksServiceI,
+	outboxService OutboxServiceI,
+	sender SenderI,
+) *OutboxJob {
+	return &OutboxJob{
+		logger:        logger,
+		usersService:  usersService,
+		tasksService:  tasksService,
+		outboxService: outboxService,
+		sender:        sender,
+	}
+}
+
+func (job *OutboxJob) Run(ctx context.Context) {
+	ticker := time.NewTicker(outboxCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			job.tick(ctx, time.Now())
+		}
+	}
+}
+
+func (job *OutboxJob) tick(ctx context.Context, now time.Time) {
+	messages, err := job.outboxService.Claim(ctx, now)
+	if err != nil {
+		job.logger.Errorw(
+			"Scheduler -> OutboxJob -> tick -> job.outboxService.Claim(now)",
+			"error", err.Error(),
+		)
+		return
+	}
+
+	for _, message := range messages {
+		err = job.relay(ctx, message)
+		if err != nil {
+			job.logger.Errorw(
+				"Scheduler -> OutboxJob -> tick -> job.relay(ctx, message)",
+				"error", err.Error(), "messageID", message.ID,
+			)
+		}
+	}
+}
+
+// relay (ctx, message) -> sends the message and saves the result, the error is only about saving it
+func (job *OutboxJob) relay(ctx context.Context, message models.OutboxMessage) error {
+	skipReason, err := job.send(ctx, message)
+	switch {
+	case skipReason != "":
+		return job.outboxService.Skip(ctx, message, skipReason)
+	case errors.Is(err, sendqueue.ErrBlocked):
+		//Повторы не помогут, напоминания пользователя и так на паузе, пока он снова не напишет боту
+		return job.outboxService.Skip(ctx, message, err.Error())
+	case err != nil:
+		job.logger.Warnw(
+			"Scheduler -> OutboxJob -> relay -> job.send(ctx, message)",
+			"error", err.Error(), "messageID", message.ID, "kind", message.Kind,
+		)
+		return job.outboxService.MarkFailed(ctx, message, err, time.Now())
+	default:
+		return job.outboxService.MarkSent(ctx, message, time.Now())
+	}
+}
+
+// send (ctx, message) -> the reason to skip the message if it isn't needed anymore, or the error of sending
+func (job *OutboxJob) send(ctx context.Context, message models.OutboxMessage) (string, error) {
+	switch message.Kind {
+	case outbox_types.KindReminder:
+		return job.sendReminder(ctx, message)
+	default:
+		return "unknown kind " + message.Kind, nil
+	}
+}
+
+func (job *OutboxJob) sendReminder(ctx context.Context, message models.OutboxMessage) (string, error) {
+	var payload outbox_types.ReminderPayload
+	err := json.Unmarshal(message.Payload, &payload)
+	if err != nil {
+		return "invalid payload: " + err.Error(), nil
+	}
+
+	task, err := job.tasksService.FindByID(ctx, payload.TaskID)
+	if errors.Is(err, services_types.ErrNotFound) {
+		return "the task is deleted", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	//Задачу закрыли, пока напоминание ждало отправки
+	if task.Done {
+		return "the task is completed", nil
+	}
+
+	user, err := job.usersService.FindByID(ctx, message.UserID)
+	if err != nil {
+		return "", err
+	}
+
+	return "", job.sender.SendReminder(ctx, user, task)
+}
@@ -0,0 +1,93 @@
+package scheduler
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"tg_todo_bot/src/models"
+	"time"
+)
+
+const remindersCheckInterval = time.Minute
+
+// RemindersJob moves due notifications to the next repeat and saves the reminders to the outbox in one transaction,
+// OutboxJob sends them. Notifications of blocked tasks aren't returned by GetUpcoming, so they wait until the task is unblocked.
+type RemindersJob struct {
+	logger               *zap.SugaredLogger
+	tasksService         TasksServiceI
+	notificationsService NotificationsServiceI
+}
+
+func NewRemindersJob(
+	logger *zap.SugaredLogger,
+	tasksService TasksServiceI,
+	notificationsService NotificationsServiceI,
+) *RemindersJob {
+	return &RemindersJob{
+		logger:               logger,
+		tasksService:         tasksService,
+		notificationsService: notificationsService,
+	}
+}
+
+func (job *RemindersJob) Run(ctx context.Context) {
+	ticker := time.NewTicker(remindersCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			job.tick(ctx, time.Now())
+		}
+	}
+}
+
+func (job *RemindersJob) tick(ctx context.Context, now time.Time) {
+	notifications, err := job.notificationsService.GetUpcoming(ctx, now)
+	if err != nil {
+		job.logger.Errorw(
+			"Scheduler -> RemindersJob -> tick -> job.notificationsService.GetUpcoming(ctx, now)",
+			"error", err.Error(),
+		)
+		return
+	}
+
+	for _, notification := range notifications {
+		err = job.remind(ctx, notification, now)
+		if err != nil {
+			job.logger.Errorw(
+				"Scheduler -> RemindersJob -> tick -> job.remind(ctx, notification, now)",
+				"error", err.Error(), "notification", notification,
+			)
+		}
+	}
+}
+
+func (job *RemindersJob) remind(ctx context.Context, notification models.Notification, now time.Time) error {
+	task, err := job.tasksService.FindByID(ctx, notification.TaskID)
+	if err != nil {
+		return err
+	}
+
+	if task.Done {
+		return job.notificationsService.DeleteByID(ctx, notification.ID)
+	}
+
+	return job.notificationsService.Fire(ctx, notification, task, nextNotifyAt(notification, now), now)
+}
+
+// nextNotifyAt (notification, now) -> first repeat of the notification after now
+func nextNotifyAt(notification models.Notification, now time.Time) time.Time {
+	if notification.RepeatInterval <= 0 {
+		return now.Add(time.Hour)
+	}
+
+	next := notification.NotifyAt
+	if !next.After(now) {
+		repeats := now.Sub(next)/notification.RepeatInterval + 1
+		next = next.Add(repeats * notification.RepeatInterval)
+	}
+
+	return next
+}
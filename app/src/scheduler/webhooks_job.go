@@ -0,0 +1,45 @@
+package scheduler
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"time"
+)
+
+const webhooksCheckInterval = 15 * time.Second
+
+// WebhooksJob sends queued webhook deliveries, retries are scheduled by the webhooks service
+type WebhooksJob struct {
+	logger          *zap.SugaredLogger
+	webhooksService WebhooksServiceI
+}
+
+func NewWebhooksJob(
+	logger *zap.SugaredLogger,
+	webhooksService WebhooksServiceI,
+) *WebhooksJob {
+	return &WebhooksJob{
+		logger:          logger,
+		webhooksService: webhooksService,
+	}
+}
+
+func (job *WebhooksJob) Run(ctx context.Context) {
+	ticker := time.NewTicker(webhooksCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := job.webhooksService.DeliverDue(ctx, time.Now())
+			if err != nil {
+				job.logger.Errorw(
+					"Scheduler -> WebhooksJob -> Run -> job.webhooksService.DeliverDue(now)",
+					"error", err.Error(),
+				)
+			}
+		}
+	}
+}
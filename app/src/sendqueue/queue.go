@@ -0,0 +1,230 @@
+package sendqueue
+
+import (
+	"context"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// maxTrackedChats -> above it chats without reserved messages are forgotten, so the map doesn't grow forever
+const maxTrackedChats = 10000
+
+// ErrBlocked -> the chat doesn't accept messages from the bot: the user blocked the bot or deleted the account,
+// or the bot was removed from the group. Telegram answers such requests with 403
+var ErrBlocked = errors.New("the bot is blocked in the chat")
+
+// BlockedError -> errors.Is(err, ErrBlocked) is true for it, the error of Telegram is kept
+type BlockedError struct {
+	Err error
+}
+
+func (err *BlockedError) Error() string {
+	return ErrBlocked.Error() + ": " + err.Err.Error()
+}
+
+func (err *BlockedError) Unwrap() error {
+	return err.Err
+}
+
+func (err *BlockedError) Is(target error) bool {
+	return target == ErrBlocked
+}
+
+// Limits -> Telegram allows about 30 messages a second to all chats and about a message a second to one chat
+type Limits struct {
+	PerSecond    float64       //messages to all chats together
+	ChatInterval time.Duration //between messages to one chat
+	ChatBurst    int           //messages to one chat sent without waiting, like an answer and an edit of a keyboard
+	MaxAttempts  int
+	Backoff      time.Duration //before the first retry, doubled for every next one
+}
+
+var DefaultLimits = Limits{
+	PerSecond:    30,
+	ChatInterval: time.Second,
+	ChatBurst:    3,
+	MaxAttempts:  5,
+	Backoff:      time.Second,
+}
+
+type APII interface {
+	Send(chattable tgbotapi.Chattable) (tgbotapi.Message, error)
+}
+
+// Queue sends messages in the order of Send calls at the rate Telegram allows. Every call reserves the earliest
+// time free both for all chats and for its chat and sleeps until then, so callers wait in line without a worker.
+// Network errors and errors of Telegram servers are retried with backoff, 429 pauses all sending for retry_after.
+// A caller whose context is done stops waiting and gets the error of the context
+type Queue struct {
+	logger *zap.SugaredLogger
+	api    APII
+	limits Limits
+
+	mutex       sync.Mutex
+	nextAt      time.Time           //the earliest time of the next message to any chat
+	chatsNextAt map[int64]time.Time //the same for one chat, ChatBurst messages may go before it
+	pausedUntil time.Time           //set by 429 of Telegram
+}
+
+func NewQueue(
+	logger *zap.SugaredLogger,
+	api APII,
+	limits Limits,
+) *Queue {
+	return &Queue{
+		logger:      logger,
+		api:         api,
+		limits:      limits,
+		chatsNextAt: map[int64]time.Time{},
+	}
+}
+
+// Send (ctx, chatID, chattable) -> the message is sent when the limits allow and retried on temporary errors,
+// *BlockedError if the chat doesn't accept messages from the bot, the error of ctx if it's done while waiting
+func (queue *Queue) Send(ctx context.Context, chatID int64, chattable tgbotapi.Chattable) (tgbotapi.Message, error) {
+	return queue.send(ctx, chatID, chattable, queue.limits.MaxAttempts)
+}
+
+// SendOnce (ctx, chatID, chattable) -> like Send, but without retries for requests which can't be repeated,
+// like uploads from a reader
+func (queue *Queue) SendOnce(ctx context.Context, chatID int64, chattable tgbotapi.Chattable) (tgbotapi.Message, error) {
+	return queue.send(ctx, chatID, chattable, 1)
+}
+
+func (queue *Queue) send(ctx context.Context, chatID int64, chattable tgbotapi.Chattable, maxAttempts int) (tgbotapi.Message, error) {
+	backoff := queue.limits.Backoff
+
+	for attempt := 1; ; attempt++ {
+		err := queue.wait(ctx, chatID)
+		if err != nil {
+			return tgbotapi.Message{}, err
+		}
+
+		message, err := queue.api.Send(chattable)
+		if err == nil {
+			return message, nil
+		}
+
+		var apiErr *tgbotapi.Error
+		var urlErr *url.Error
+		delay := backoff
+		switch {
+		case errors.As(err, &apiErr) && apiErr.Code == http.StatusForbidden:
+			return tgbotapi.Message{}, &BlockedError{Err: err}
+		case errors.As(err, &apiErr) && apiErr.Code == http.StatusTooManyRequests:
+			//Лимит превышен для всего бота, остальные сообщения тоже ждут
+			if retryAfter := time.Duration(apiErr.RetryAfter) * time.Second; retryAfter > delay {
+				delay = retryAfter
+			}
+			queue.pause(delay)
+			delay = 0
+		case errors.As(err, &apiErr) && apiErr.Code >= http.StatusInternalServerError:
+		case errors.As(err, &urlErr):
+		default:
+			//Ошибку запроса повтор не исправит, а ошибка разбора ответа значит, что сообщение уже отправлено
+			return tgbotapi.Message{}, err
+		}
+
+		if attempt >= maxAttempts {
+			return tgbotapi.Message{}, err
+		}
+
+		queue.logger.Warnw("SendQueue -> send -> retry",
+			"error", err.Error(), "chatID", chatID, "attempt", attempt,
+		)
+		err = sleep(ctx, delay)
+		if err != nil {
+			return tgbotapi.Message{}, err
+		}
+		backoff *= 2
+	}
+}
+
+// wait (ctx, chatID) -> sleeps until the reserved time, a pause set meanwhile makes it reserve again.
+// The error of ctx if it's done first, the reserved time is lost then
+func (queue *Queue) wait(ctx context.Context, chatID int64) error {
+	for {
+		err := ctx.Err()
+		if err != nil {
+			return err
+		}
+
+		queue.mutex.Lock()
+		now := time.Now()
+		sendAt := queue.reserve(chatID, now)
+		queue.mutex.Unlock()
+
+		err = sleep(ctx, sendAt.Sub(now))
+		if err != nil {
+			return err
+		}
+
+		queue.mutex.Lock()
+		paused := queue.pausedUntil.After(time.Now())
+		queue.mutex.Unlock()
+		if !paused {
+			return nil
+		}
+	}
+}
+
+// sleep (ctx, duration) -> the error of ctx if it's done before the duration is over
+func sleep(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// reserve (chatID, now) -> the earliest time a message to the chat fits the limits, the time is taken by the caller.
+// Called under the mutex
+func (queue *Queue) reserve(chatID int64, now time.Time) time.Time {
+	chatNextAt := queue.chatsNextAt[chatID]
+	chatTolerance := time.Duration(queue.limits.ChatBurst-1) * queue.limits.ChatInterval
+
+	sendAt := latest(now, queue.pausedUntil, queue.nextAt, chatNextAt.Add(-chatTolerance))
+
+	queue.nextAt = sendAt.Add(time.Duration(float64(time.Second) / queue.limits.PerSecond))
+	queue.chatsNextAt[chatID] = latest(sendAt, chatNextAt).Add(queue.limits.ChatInterval)
+
+	if len(queue.chatsNextAt) > maxTrackedChats {
+		for id, nextAt := range queue.chatsNextAt {
+			if nextAt.Before(now) {
+				delete(queue.chatsNextAt, id)
+			}
+		}
+	}
+
+	return sendAt
+}
+
+func (queue *Queue) pause(duration time.Duration) {
+	queue.mutex.Lock()
+	defer queue.mutex.Unlock()
+
+	pausedUntil := time.Now().Add(duration)
+	if pausedUntil.After(queue.pausedUntil) {
+		queue.pausedUntil = pausedUntil
+	}
+}
+
+func latest(times ...time.Time) time.Time {
+	result := times[0]
+	for _, t := range times[1:] {
+		if t.After(result) {
+			result = t
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,172 @@
+package sendqueue
+
+import (
+	"context"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeAPI -> answers with errors in turn, then successfully
+type fakeAPI struct {
+	mutex  sync.Mutex
+	errors []error
+	calls  int
+}
+
+func (api *fakeAPI) Send(chattable tgbotapi.Chattable) (tgbotapi.Message, error) {
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+
+	api.calls++
+	if len(api.errors) == 0 {
+		return tgbotapi.Message{MessageID: api.calls}, nil
+	}
+	err := api.errors[0]
+	api.errors = api.errors[1:]
+
+	return tgbotapi.Message{}, err
+}
+
+var testLimits = Limits{
+	PerSecond:    1000,
+	ChatInterval: 50 * time.Millisecond,
+	ChatBurst:    1,
+	MaxAttempts:  3,
+	Backoff:      time.Millisecond,
+}
+
+func TestSendErrors(t *testing.T) {
+	networkErr := &url.Error{Op: "Post", URL: "https://api.telegram.org", Err: errors.New("connection reset")}
+
+	testCases := []struct {
+		name     string
+		errors   []error
+		once     bool
+		expected error //nil if the message is sent
+		calls    int
+	}{
+		{name: "sent", calls: 1},
+		{name: "flood control", errors: []error{&tgbotapi.Error{Code: http.StatusTooManyRequests}}, calls: 2},
+		{name: "server error", errors: []error{&tgbotapi.Error{Code: http.StatusBadGateway}}, calls: 2},
+		{name: "network error", errors: []error{networkErr, networkErr}, calls: 3},
+		{name: "too many attempts", errors: []error{networkErr, networkErr, networkErr}, expected: networkErr, calls: 3},
+		{name: "once", errors: []error{networkErr}, once: true, expected: networkErr, calls: 1},
+		{name: "blocked", errors: []error{&tgbotapi.Error{Code: http.StatusForbidden, Message: "Forbidden: bot was blocked by the user"}}, expected: ErrBlocked, calls: 1},
+		{name: "bad request", errors: []error{&tgbotapi.Error{Code: http.StatusBadRequest}}, expected: &tgbotapi.Error{Code: http.StatusBadRequest}, calls: 1},
+	}
+
+	for _, testCase := range testCases {
+		api := &fakeAPI{errors: testCase.errors}
+		queue := NewQueue(zap.NewNop().Sugar(), api, testLimits)
+
+		var err error
+		if testCase.once {
+			_, err = queue.SendOnce(context.Background(), 1, tgbotapi.NewMessage(1, "text"))
+		} else {
+			_, err = queue.Send(context.Background(), 1, tgbotapi.NewMessage(1, "text"))
+		}
+
+		var apiErr *tgbotapi.Error
+		switch {
+		case testCase.expected == nil && err != nil:
+			t.Fatalf("%s: got %v, expected the message to be sent", testCase.name, err)
+		case testCase.expected == ErrBlocked && !errors.Is(err, ErrBlocked):
+			t.Fatalf("%s: got %v, expected ErrBlocked", testCase.name, err)
+		case testCase.expected != nil && testCase.expected != ErrBlocked && err == nil:
+			t.Fatalf("%s: got no error, expected %v", testCase.name, testCase.expected)
+		}
+		if testCase.expected == ErrBlocked && (!errors.As(err, &apiErr) || apiErr.Code != http.StatusForbidden) {
+			t.Fatalf("%s: the error of Telegram is lost: %v", testCase.name, err)
+		}
+		if api.calls != testCase.calls {
+			t.Fatalf("%s: got %d calls, expected %d", testCase.name, api.calls, testCase.calls)
+		}
+	}
+}
+
+func TestSendRate(t *testing.T) {
+	queue := NewQueue(zap.NewNop().Sugar(), &fakeAPI{}, testLimits)
+
+	//Разные чаты не ждут друг друга
+	start := time.Now()
+	for chatID := int64(1); chatID <= 3; chatID++ {
+		_, err := queue.Send(context.Background(), chatID, tgbotapi.NewMessage(chatID, "text"))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed >= testLimits.ChatInterval {
+		t.Fatalf("messages to different chats took %s", elapsed)
+	}
+
+	//Третье сообщение в чат 1 уходит не раньше чем через два интервала после первого
+	_, err := queue.Send(context.Background(), 1, tgbotapi.NewMessage(1, "text"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = queue.Send(context.Background(), 1, tgbotapi.NewMessage(1, "text"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 2*testLimits.ChatInterval {
+		t.Fatalf("3 messages to one chat took only %s", elapsed)
+	}
+}
+
+func TestPauseOnFloodControl(t *testing.T) {
+	limits := testLimits
+	limits.Backoff = 30 * time.Millisecond
+	queue := NewQueue(zap.NewNop().Sugar(), &fakeAPI{errors: []error{&tgbotapi.Error{Code: http.StatusTooManyRequests}}}, limits)
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		_, err := queue.Send(context.Background(), 1, tgbotapi.NewMessage(1, "text"))
+		done <- err
+	}()
+	time.Sleep(5 * time.Millisecond)
+
+	//429 в чат 1 останавливает отправку и в другие чаты
+	_, err := queue.Send(context.Background(), 2, tgbotapi.NewMessage(2, "text"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < limits.Backoff {
+		t.Fatalf("chat 2 got the message after %s, expected a pause of %s", elapsed, limits.Backoff)
+	}
+
+	err = <-done
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSendStopsWithContext(t *testing.T) {
+	limits := testLimits
+	limits.Backoff = time.Hour
+	queue := NewQueue(zap.NewNop().Sugar(), &fakeAPI{errors: []error{&tgbotapi.Error{Code: http.StatusTooManyRequests}}}, limits)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	//Пауза после 429 длиннее теста, отправка прерывается контекстом
+	start := time.Now()
+	_, err := queue.Send(ctx, 1, tgbotapi.NewMessage(1, "text"))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, expected context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Send returned after %s", elapsed)
+	}
+
+	_, err = queue.Send(ctx, 2, tgbotapi.NewMessage(2, "text"))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Send with a done context: got %v, expected context.DeadlineExceeded", err)
+	}
+}
@@ -0,0 +1,16 @@
+package broadcasts
+
+import (
+	"context"
+	"tg_todo_bot/src/models"
+	"time"
+)
+
+type BroadcastsRepositoryI interface {
+	Create(ctx context.Context, broadcast models.Broadcast) (models.Broadcast, error)
+	FindByID(ctx context.Context, broadcastID int64) (models.Broadcast, error)
+	FindLatest(ctx context.Context) (models.Broadcast, error)
+	Claim(ctx context.Context, status string, now time.Time, lease time.Duration) (models.Broadcast, error)
+	UpdateProgress(ctx context.Context, broadcast models.Broadcast, lockedUntil time.Time) error
+	UpdateStatus(ctx context.Context, broadcastID int64, fromStatuses []string, status string, finishedAt *time.Time) error
+}
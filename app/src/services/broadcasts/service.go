@@ -0,0 +1,168 @@
+package broadcasts
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"tg_todo_bot/src/models"
+	repositories_types "tg_todo_bot/src/repositories/types"
+	"tg_todo_bot/src/services/broadcasts/types"
+	services_types "tg_todo_bot/src/services/types"
+	"time"
+)
+
+// claimLease -> a claimed broadcast is taken by another replica if its progress isn't saved for this long
+const claimLease = time.Minute
+
+type Service struct {
+	logger               *zap.SugaredLogger
+	broadcastsRepository BroadcastsRepositoryI
+}
+
+func NewService(
+	logger *zap.SugaredLogger,
+	broadcastsRepository BroadcastsRepositoryI,
+) *Service {
+	return &Service{
+		logger:               logger,
+		broadcastsRepository: broadcastsRepository,
+	}
+}
+
+// Create (ctx, text) -> a draft broadcast, it isn't sent until Start
+func (service *Service) Create(ctx context.Context, text string) (models.Broadcast, error) {
+	service.logger.Info("Services -> Broadcasts -> Create")
+
+	err := validateText(text)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Broadcasts -> Create -> validateText(text)",
+			"error", err.Error(),
+		)
+		return models.Broadcast{}, services_types.InvalidParams(err)
+	}
+
+	broadcast, err := service.broadcastsRepository.Create(ctx, models.Broadcast{Text: text, Status: types.StatusDraft})
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Broadcasts -> Create -> service.broadcastsRepository.Create(ctx, broadcast)",
+			"error", err.Error(),
+		)
+		return models.Broadcast{}, err
+	}
+
+	return broadcast, nil
+}
+
+func (service *Service) FindByID(ctx context.Context, broadcastID int64) (models.Broadcast, error) {
+	service.logger.Info("Services -> Broadcasts -> FindByID")
+
+	broadcast, err := service.broadcastsRepository.FindByID(ctx, broadcastID)
+	if err != nil {
+		if errors.Is(err, repositories_types.ErrNotFound) {
+			return models.Broadcast{}, services_types.ErrNotFound
+		}
+		service.logger.Errorw(
+			"Services -> Broadcasts -> FindByID -> service.broadcastsRepository.FindByID(ctx, broadcastID)",
+			"error", err.Error(), "broadcastID", broadcastID,
+		)
+		return models.Broadcast{}, err
+	}
+
+	return broadcast, nil
+}
+
+// FindLatest -> the last created broadcast, services_types.ErrNotFound if there were none
+func (service *Service) FindLatest(ctx context.Context) (models.Broadcast, error) {
+	service.logger.Info("Services -> Broadcasts -> FindLatest")
+
+	broadcast, err := service.broadcastsRepository.FindLatest(ctx)
+	if err != nil {
+		if errors.Is(err, repositories_types.ErrNotFound) {
+			return models.Broadcast{}, services_types.ErrNotFound
+		}
+		service.logger.Errorw(
+			"Services -> Broadcasts -> FindLatest -> service.broadcastsRepository.FindLatest(ctx)",
+			"error", err.Error(),
+		)
+		return models.Broadcast{}, err
+	}
+
+	return broadcast, nil
+}
+
+// ClaimRunning (ctx, now) -> the oldest running broadcast which no replica sends, services_types.ErrNotFound
+// if there is none. It's held by the caller while SaveProgress is called within claimLease
+func (service *Service) ClaimRunning(ctx context.Context, now time.Time) (models.Broadcast, error) {
+	service.logger.Info("Services -> Broadcasts -> ClaimRunning")
+
+	broadcast, err := service.broadcastsRepository.Claim(ctx, types.StatusRunning, now, claimLease)
+	if err != nil {
+		if errors.Is(err, repositories_types.ErrNotFound) {
+			return models.Broadcast{}, services_types.ErrNotFound
+		}
+		service.logger.Errorw(
+			"Services -> Broadcasts -> ClaimRunning -> service.broadcastsRepository.Claim(ctx, status, now, claimLease)",
+			"error", err.Error(),
+		)
+		return models.Broadcast{}, err
+	}
+
+	return broadcast, nil
+}
+
+// Start (ctx, broadcastID) -> the draft is picked up by the scheduler,
+// services_types.ErrNotFound if there is no such draft
+func (service *Service) Start(ctx context.Context, broadcastID int64) error {
+	service.logger.Info("Services -> Broadcasts -> Start")
+
+	return service.updateStatus(ctx, "Start", broadcastID, []string{types.StatusDraft}, types.StatusRunning, nil)
+}
+
+// Cancel (ctx, broadcastID, now) -> stops a draft or a running broadcast,
+// services_types.ErrNotFound if it is already finished
+func (service *Service) Cancel(ctx context.Context, broadcastID int64, now time.Time) error {
+	service.logger.Info("Services -> Broadcasts -> Cancel")
+
+	fromStatuses := []string{types.StatusDraft, types.StatusRunning}
+
+	return service.updateStatus(ctx, "Cancel", broadcastID, fromStatuses, types.StatusCancelled, &now)
+}
+
+// Finish (ctx, broadcastID, now) -> marks the running broadcast as sent to everyone
+func (service *Service) Finish(ctx context.Context, broadcastID int64, now time.Time) error {
+	service.logger.Info("Services -> Broadcasts -> Finish")
+
+	return service.updateStatus(ctx, "Finish", broadcastID, []string{types.StatusRunning}, types.StatusDone, &now)
+}
+
+func (service *Service) updateStatus(ctx context.Context, method string, broadcastID int64, fromStatuses []string, status string, finishedAt *time.Time) error {
+	err := service.broadcastsRepository.UpdateStatus(ctx, broadcastID, fromStatuses, status, finishedAt)
+	if err != nil {
+		if errors.Is(err, repositories_types.ErrNotFound) {
+			return services_types.ErrNotFound
+		}
+		service.logger.Errorw(
+			"Services -> Broadcasts -> "+method+" -> service.broadcastsRepository.UpdateStatus(ctx, broadcastID, fromStatuses, status, finishedAt)",
+			"error", err.Error(), "broadcastID", broadcastID,
+		)
+		return err
+	}
+
+	return nil
+}
+
+// SaveProgress (ctx, broadcast) -> remembers the last processed user, so the broadcast resumes after a restart,
+// and extends the claim of the broadcast
+func (service *Service) SaveProgress(ctx context.Context, broadcast models.Broadcast) error {
+	err := service.broadcastsRepository.UpdateProgress(ctx, broadcast, time.Now().Add(claimLease))
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Broadcasts -> SaveProgress -> service.broadcastsRepository.UpdateProgress(ctx, broadcast, lockedUntil)",
+			"error", err.Error(), "broadcastID", broadcast.ID,
+		)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,9 @@
+package types
+
+// Statuses of broadcasts
+const (
+	StatusDraft     = "draft"     //created by the admin, nothing is sent until the preview is confirmed
+	StatusRunning   = "running"   //sent by the scheduler, survives restarts of the bot
+	StatusDone      = "done"      //all users have been processed
+	StatusCancelled = "cancelled" //stopped by the admin, the progress is kept
+)
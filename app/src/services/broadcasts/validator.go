@@ -0,0 +1,24 @@
+package broadcasts
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Ограничение Telegram на длину текста сообщения
+const maxTextLength = 4096
+
+func validateText(text string) error {
+	if strings.TrimSpace(text) == "" {
+		err := fmt.Errorf("text is required")
+		return err
+	}
+
+	if utf8.RuneCountInString(text) > maxTextLength {
+		err := fmt.Errorf("text must be at most %d characters", maxTextLength)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,12 @@
+package caldav
+
+import (
+	"context"
+	"tg_todo_bot/src/models"
+)
+
+type CalDAVObjectsRepositoryI interface {
+	Save(ctx context.Context, object models.CalDAVObject) error
+	FindByName(ctx context.Context, userID int64, name string) (models.CalDAVObject, error)
+	FindByTasksIDs(ctx context.Context, tasksIDs []int64) (map[int64]models.CalDAVObject, error)
+}
@@ -0,0 +1,85 @@
+package caldav
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"tg_todo_bot/src/models"
+	repositories_types "tg_todo_bot/src/repositories/types"
+	services_types "tg_todo_bot/src/services/types"
+)
+
+// Service -> names and UIDs of tasks created or renamed by CalDAV clients, the tasks themselves are in the tasks service
+type Service struct {
+	logger                  *zap.SugaredLogger
+	calDAVObjectsRepository CalDAVObjectsRepositoryI
+}
+
+func NewService(
+	logger *zap.SugaredLogger,
+	calDAVObjectsRepository CalDAVObjectsRepositoryI,
+) *Service {
+	return &Service{
+		logger:                  logger,
+		calDAVObjectsRepository: calDAVObjectsRepository,
+	}
+}
+
+func (service *Service) Save(ctx context.Context, object models.CalDAVObject) error {
+	service.logger.Info("Services -> CalDAV -> Save")
+
+	err := validateObject(object)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> CalDAV -> Save -> validateObject(object)",
+			"error", err.Error(), "object", object,
+		)
+		return services_types.InvalidParams(err)
+	}
+
+	err = service.calDAVObjectsRepository.Save(ctx, object)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> CalDAV -> Save -> service.calDAVObjectsRepository.Save(ctx, object)",
+			"error", err.Error(), "object", object,
+		)
+		return err
+	}
+
+	return nil
+}
+
+// FindByName (ctx, userID, name) -> services_types.ErrNotFound if no client gave this name to a task of the user
+func (service *Service) FindByName(ctx context.Context, userID int64, name string) (models.CalDAVObject, error) {
+	service.logger.Info("Services -> CalDAV -> FindByName")
+
+	object, err := service.calDAVObjectsRepository.FindByName(ctx, userID, name)
+	if err != nil {
+		if errors.Is(err, repositories_types.ErrNotFound) {
+			return models.CalDAVObject{}, services_types.ErrNotFound
+		}
+		service.logger.Errorw(
+			"Services -> CalDAV -> FindByName -> service.calDAVObjectsRepository.FindByName(ctx, userID, name)",
+			"error", err.Error(), "userID", userID, "name", name,
+		)
+		return models.CalDAVObject{}, err
+	}
+
+	return object, nil
+}
+
+// FindByTasksIDs (ctx, tasksIDs) -> objects by task ID, tasks created in the bot have none
+func (service *Service) FindByTasksIDs(ctx context.Context, tasksIDs []int64) (map[int64]models.CalDAVObject, error) {
+	service.logger.Info("Services -> CalDAV -> FindByTasksIDs")
+
+	objects, err := service.calDAVObjectsRepository.FindByTasksIDs(ctx, tasksIDs)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> CalDAV -> FindByTasksIDs -> service.calDAVObjectsRepository.FindByTasksIDs(ctx, tasksIDs)",
+			"error", err.Error(), "tasksIDs", tasksIDs,
+		)
+		return map[int64]models.CalDAVObject{}, err
+	}
+
+	return objects, nil
+}
@@ -0,0 +1,41 @@
+package caldav
+
+import (
+	"fmt"
+	"strings"
+	"tg_todo_bot/src/models"
+)
+
+const maxNameLength = 255
+
+func validateObject(object models.CalDAVObject) error {
+	var emptyRequiredFields []string
+
+	if object.TaskID == 0 {
+		emptyRequiredFields = append(emptyRequiredFields, "TaskID")
+	}
+
+	if object.UserID == 0 {
+		emptyRequiredFields = append(emptyRequiredFields, "UserID")
+	}
+
+	if object.Name == "" {
+		emptyRequiredFields = append(emptyRequiredFields, "Name")
+	}
+
+	if object.UID == "" {
+		emptyRequiredFields = append(emptyRequiredFields, "UID")
+	}
+
+	if len(emptyRequiredFields) > 0 {
+		err := fmt.Errorf("some required fields are empty: [%s]", strings.Join(emptyRequiredFields, ", "))
+		return err
+	}
+
+	if len(object.Name) > maxNameLength || strings.Contains(object.Name, "/") {
+		err := fmt.Errorf("name must be at most %d characters without '/'", maxNameLength)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,29 @@
+package escalations
+
+import (
+	"context"
+	"tg_todo_bot/src/models"
+	repositories_types "tg_todo_bot/src/repositories/types"
+	"time"
+)
+
+type TasksRepositoryI interface {
+	GetOverdue(ctx context.Context, now time.Time, filters repositories_types.TasksOverdueFilters) ([]models.Task, error)
+}
+
+type EscalationPoliciesRepositoryI interface {
+	Save(ctx context.Context, policy models.EscalationPolicy) (models.EscalationPolicy, error)
+	FindForUsers(ctx context.Context, usersIDs []int64) (map[int64]models.EscalationPolicy, error)
+	FindForTasks(ctx context.Context, tasksIDs []int64) (map[int64]models.EscalationPolicy, error)
+	DeleteForUser(ctx context.Context, userID int64) error
+	DeleteForTask(ctx context.Context, taskID int64) error
+}
+
+type TaskEscalationsRepositoryI interface {
+	Save(ctx context.Context, escalation models.TaskEscalation) error
+	FindByTasksIDs(ctx context.Context, tasksIDs []int64) (map[int64]models.TaskEscalation, error)
+}
+
+type UserSettingsRepositoryI interface {
+	FindByUsersIDs(ctx context.Context, usersIDs []int64) (map[int64]models.UserSettings, error)
+}
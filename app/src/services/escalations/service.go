@@ -0,0 +1,275 @@
+package escalations
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"tg_todo_bot/src/models"
+	repositories_types "tg_todo_bot/src/repositories/types"
+	"tg_todo_bot/src/services/escalations/types"
+	settings_types "tg_todo_bot/src/services/settings/types"
+	tasks_types "tg_todo_bot/src/services/tasks/types"
+	services_types "tg_todo_bot/src/services/types"
+	"time"
+)
+
+// DefaultPolicy is used for users without their own policy
+var DefaultPolicy = models.EscalationPolicy{
+	OverdueAfter:   time.Hour,
+	RepeatInterval: 24 * time.Hour,
+	MaxReminders:   3,
+}
+
+type Service struct {
+	logger                       *zap.SugaredLogger
+	tasksRepository              TasksRepositoryI
+	escalationPoliciesRepository EscalationPoliciesRepositoryI
+	taskEscalationsRepository    TaskEscalationsRepositoryI
+	userSettingsRepository       UserSettingsRepositoryI
+}
+
+func NewService(
+	logger *zap.SugaredLogger,
+	tasksRepository TasksRepositoryI,
+	escalationPoliciesRepository EscalationPoliciesRepositoryI,
+	taskEscalationsRepository TaskEscalationsRepositoryI,
+	userSettingsRepository UserSettingsRepositoryI,
+) *Service {
+	return &Service{
+		logger:                       logger,
+		tasksRepository:              tasksRepository,
+		escalationPoliciesRepository: escalationPoliciesRepository,
+		taskEscalationsRepository:    taskEscalationsRepository,
+		userSettingsRepository:       userSettingsRepository,
+	}
+}
+
+// SetPolicy (ctx, params) -> replaces the user's default policy or the policy of the task
+func (service *Service) SetPolicy(ctx context.Context, params types.PolicyParams) error {
+	service.logger.Info("Services -> Escalations -> SetPolicy")
+
+	err := validatePolicyParams(params)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Escalations -> SetPolicy -> validatePolicyParams(params)",
+			"error", err.Error(), "params", params,
+		)
+		return services_types.InvalidParams(err)
+	}
+
+	policyModel := models.EscalationPolicy{
+		UserID:         params.UserID,
+		OverdueAfter:   params.OverdueAfter,
+		RepeatInterval: params.RepeatInterval,
+		MaxReminders:   params.MaxReminders,
+	}
+	if params.TaskID != 0 {
+		policyModel.TaskID = &params.TaskID
+	}
+
+	_, err = service.escalationPoliciesRepository.Save(ctx, policyModel)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Escalations -> SetPolicy -> service.escalationPoliciesRepository.Save(ctx, policyModel)",
+			"error", err.Error(), "policyModel", policyModel,
+		)
+		return err
+	}
+
+	return nil
+}
+
+// ResetPolicy (ctx, userID, taskID) -> the task falls back to the user's policy, the user (taskID 0) to DefaultPolicy
+func (service *Service) ResetPolicy(ctx context.Context, userID, taskID int64) error {
+	service.logger.Info("Services -> Escalations -> ResetPolicy")
+
+	var err error
+	if taskID != 0 {
+		err = service.escalationPoliciesRepository.DeleteForTask(ctx, taskID)
+	} else {
+		err = service.escalationPoliciesRepository.DeleteForUser(ctx, userID)
+	}
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Escalations -> ResetPolicy -> service.escalationPoliciesRepository.Delete",
+			"error", err.Error(), "userID", userID, "taskID", taskID,
+		)
+		return err
+	}
+
+	return nil
+}
+
+// GetPolicy (ctx, userID, taskID) -> the policy applied to the task, or to the user's tasks if taskID is 0
+func (service *Service) GetPolicy(ctx context.Context, userID, taskID int64) (models.EscalationPolicy, error) {
+	service.logger.Info("Services -> Escalations -> GetPolicy")
+
+	tasksPolicies, err := service.escalationPoliciesRepository.FindForTasks(ctx, []int64{taskID})
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Escalations -> GetPolicy -> service.escalationPoliciesRepository.FindForTasks(ctx, tasksIDs)",
+			"error", err.Error(), "taskID", taskID,
+		)
+		return models.EscalationPolicy{}, err
+	}
+
+	usersPolicies, err := service.escalationPoliciesRepository.FindForUsers(ctx, []int64{userID})
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Escalations -> GetPolicy -> service.escalationPoliciesRepository.FindForUsers(ctx, usersIDs)",
+			"error", err.Error(), "userID", userID,
+		)
+		return models.EscalationPolicy{}, err
+	}
+
+	return resolvePolicy(tasksPolicies, usersPolicies, userID, taskID), nil
+}
+
+func resolvePolicy(tasksPolicies, usersPolicies map[int64]models.EscalationPolicy, userID, taskID int64) models.EscalationPolicy {
+	if policy, exist := tasksPolicies[taskID]; exist && taskID != 0 {
+		return policy
+	}
+
+	if policy, exist := usersPolicies[userID]; exist {
+		return policy
+	}
+
+	return DefaultPolicy
+}
+
+// GetDue (ctx, now) -> escalations to send for overdue tasks of all users, blocked tasks
+// and tasks of users who blocked the bot aren't escalated
+func (service *Service) GetDue(ctx context.Context, now time.Time) ([]types.Escalation, error) {
+	service.logger.Info("Services -> Escalations -> GetDue")
+
+	tasks, err := service.tasksRepository.GetOverdue(ctx, now, repositories_types.TasksOverdueFilters{
+		WithoutActiveBlockers: true,
+		WithoutBotBlocked:     true,
+	})
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Escalations -> GetDue -> service.tasksRepository.GetOverdue(ctx, now, filters)",
+			"error", err.Error(),
+		)
+		return []types.Escalation{}, err
+	}
+
+	var tasksIDs, usersIDs []int64
+	for _, task := range tasks {
+		tasksIDs = append(tasksIDs, task.ID)
+		usersIDs = append(usersIDs, task.UserID)
+	}
+
+	tasksPolicies, err := service.escalationPoliciesRepository.FindForTasks(ctx, tasksIDs)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Escalations -> GetDue -> service.escalationPoliciesRepository.FindForTasks(ctx, tasksIDs)",
+			"error", err.Error(),
+		)
+		return []types.Escalation{}, err
+	}
+
+	usersPolicies, err := service.escalationPoliciesRepository.FindForUsers(ctx, usersIDs)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Escalations -> GetDue -> service.escalationPoliciesRepository.FindForUsers(ctx, usersIDs)",
+			"error", err.Error(),
+		)
+		return []types.Escalation{}, err
+	}
+
+	lastEscalations, err := service.taskEscalationsRepository.FindByTasksIDs(ctx, tasksIDs)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Escalations -> GetDue -> service.taskEscalationsRepository.FindByTasksIDs(ctx, tasksIDs)",
+			"error", err.Error(),
+		)
+		return []types.Escalation{}, err
+	}
+
+	usersSettings, err := service.userSettingsRepository.FindByUsersIDs(ctx, usersIDs)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Escalations -> GetDue -> service.userSettingsRepository.FindByUsersIDs(ctx, usersIDs)",
+			"error", err.Error(),
+		)
+		return []types.Escalation{}, err
+	}
+
+	var escalations []types.Escalation
+	for _, task := range tasks {
+		settings, exist := usersSettings[task.UserID]
+		if !exist {
+			settings = settings_types.Default(task.UserID)
+		}
+
+		deadline := tasks_types.Deadline(task, settings_types.Location(settings))
+		if deadline == nil {
+			continue
+		}
+
+		policy := resolvePolicy(tasksPolicies, usersPolicies, task.UserID, task.ID)
+
+		var lastEscalation *models.TaskEscalation
+		if escalation, exist := lastEscalations[task.ID]; exist {
+			lastEscalation = &escalation
+		}
+
+		level := dueLevel(policy, lastEscalation, *deadline, now)
+		if level == 0 {
+			continue
+		}
+
+		escalations = append(escalations, types.Escalation{
+			Task:      task,
+			Level:     level,
+			Final:     level == policy.MaxReminders,
+			OverdueBy: now.Sub(*deadline),
+			Deadline:  *deadline,
+		})
+	}
+
+	return escalations, nil
+}
+
+// dueLevel (policy, lastEscalation, deadline, now) -> level of the escalation to send now, 0 if nothing is due.
+// The last escalation doesn't count if the deadline of the task was moved since then.
+func dueLevel(policy models.EscalationPolicy, lastEscalation *models.TaskEscalation, deadline, now time.Time) int {
+	if lastEscalation == nil || !lastEscalation.Deadline.Equal(deadline) {
+		if policy.MaxReminders > 0 && now.Sub(deadline) >= policy.OverdueAfter {
+			return 1
+		}
+		return 0
+	}
+
+	if lastEscalation.Level >= policy.MaxReminders {
+		return 0
+	}
+
+	if now.Sub(lastEscalation.EscalatedAt) < policy.RepeatInterval {
+		return 0
+	}
+
+	return lastEscalation.Level + 1
+}
+
+// MarkEscalated (ctx, escalation, now) -> remembers the sent escalation, so it isn't sent again
+func (service *Service) MarkEscalated(ctx context.Context, escalation types.Escalation, now time.Time) error {
+	service.logger.Info("Services -> Escalations -> MarkEscalated")
+
+	escalationModel := models.TaskEscalation{
+		TaskID:      escalation.Task.ID,
+		Deadline:    escalation.Deadline,
+		Level:       escalation.Level,
+		EscalatedAt: now,
+	}
+	err := service.taskEscalationsRepository.Save(ctx, escalationModel)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Escalations -> MarkEscalated -> service.taskEscalationsRepository.Save(ctx, escalationModel)",
+			"error", err.Error(), "escalationModel", escalationModel,
+		)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package escalations
+
+import (
+	"testing"
+	"tg_todo_bot/src/models"
+	"time"
+)
+
+func TestDueLevel(t *testing.T) {
+	deadline := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	policy := models.EscalationPolicy{
+		OverdueAfter:   time.Hour,
+		RepeatInterval: 24 * time.Hour,
+		MaxReminders:   2,
+	}
+
+	testCases := []struct {
+		name           string
+		policy         models.EscalationPolicy
+		lastEscalation *models.TaskEscalation
+		now            time.Time
+		expected       int
+	}{
+		{
+			name:     "not overdue enough",
+			policy:   policy,
+			now:      deadline.Add(30 * time.Minute),
+			expected: 0,
+		},
+		{
+			name:     "first escalation",
+			policy:   policy,
+			now:      deadline.Add(time.Hour),
+			expected: 1,
+		},
+		{
+			name:     "turned off",
+			policy:   models.EscalationPolicy{OverdueAfter: time.Hour, RepeatInterval: time.Hour},
+			now:      deadline.Add(48 * time.Hour),
+			expected: 0,
+		},
+		{
+			name:   "repeat interval isn't passed",
+			policy: policy,
+			lastEscalation: &models.TaskEscalation{
+				Deadline: deadline, Level: 1, EscalatedAt: deadline.Add(time.Hour),
+			},
+			now:      deadline.Add(10 * time.Hour),
+			expected: 0,
+		},
+		{
+			name:   "second escalation",
+			policy: policy,
+			lastEscalation: &models.TaskEscalation{
+				Deadline: deadline, Level: 1, EscalatedAt: deadline.Add(time.Hour),
+			},
+			now:      deadline.Add(25 * time.Hour),
+			expected: 2,
+		},
+		{
+			name:   "all reminders are sent",
+			policy: policy,
+			lastEscalation: &models.TaskEscalation{
+				Deadline: deadline, Level: 2, EscalatedAt: deadline.Add(25 * time.Hour),
+			},
+			now:      deadline.Add(100 * time.Hour),
+			expected: 0,
+		},
+		{
+			name:   "deadline was moved",
+			policy: policy,
+			lastEscalation: &models.TaskEscalation{
+				Deadline: deadline.Add(-48 * time.Hour), Level: 2, EscalatedAt: deadline.Add(-time.Hour),
+			},
+			now:      deadline.Add(2 * time.Hour),
+			expected: 1,
+		},
+	}
+
+	for _, testCase := range testCases {
+		level := dueLevel(testCase.policy, testCase.lastEscalation, deadline, testCase.now)
+		if level != testCase.expected {
+			t.Fatalf("%s: got level %d, expected %d", testCase.name, level, testCase.expected)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package types
+
+import (
+	"tg_todo_bot/src/models"
+	"time"
+)
+
+// PolicyParams -> TaskID is 0 for the user's default policy, MaxReminders 0 turns escalation off
+type PolicyParams struct {
+	UserID         int64
+	TaskID         int64
+	OverdueAfter   time.Duration
+	RepeatInterval time.Duration
+	MaxReminders   int
+}
+
+// Escalation -> reminder number Level (starting from 1) about the overdue task
+type Escalation struct {
+	Task      models.Task
+	Level     int
+	Final     bool
+	OverdueBy time.Duration
+	Deadline  time.Time
+}
@@ -0,0 +1,36 @@
+package escalations
+
+import (
+	"fmt"
+	"tg_todo_bot/src/services/escalations/types"
+	"time"
+)
+
+const (
+	maxReminders      = 10
+	minRepeatInterval = 10 * time.Minute
+)
+
+func validatePolicyParams(params types.PolicyParams) error {
+	if params.UserID == 0 {
+		err := fmt.Errorf("some required fields are empty: [UserID]")
+		return err
+	}
+
+	if params.OverdueAfter < 0 {
+		err := fmt.Errorf("overdue after must not be negative")
+		return err
+	}
+
+	if params.RepeatInterval < minRepeatInterval {
+		err := fmt.Errorf("repeat interval must be at least %s", minRepeatInterval)
+		return err
+	}
+
+	if params.MaxReminders < 0 || params.MaxReminders > maxReminders {
+		err := fmt.Errorf("max reminders must be between 0 and %d", maxReminders)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,13 @@
+package filters
+
+import (
+	"context"
+	"tg_todo_bot/src/models"
+)
+
+type SavedFiltersRepositoryI interface {
+	Save(ctx context.Context, filter models.SavedFilter) (models.SavedFilter, error)
+	FindByName(ctx context.Context, userID int64, name string) (models.SavedFilter, error)
+	GetAllForUser(ctx context.Context, userID int64) ([]models.SavedFilter, error)
+	DeleteByName(ctx context.Context, userID int64, name string) error
+}
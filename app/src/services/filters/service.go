@@ -0,0 +1,108 @@
+package filters
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"strings"
+	"tg_todo_bot/src/models"
+	repositories_types "tg_todo_bot/src/repositories/types"
+	"tg_todo_bot/src/services/filters/types"
+	services_types "tg_todo_bot/src/services/types"
+)
+
+type Service struct {
+	logger                 *zap.SugaredLogger
+	savedFiltersRepository SavedFiltersRepositoryI
+}
+
+func NewService(
+	logger *zap.SugaredLogger,
+	savedFiltersRepository SavedFiltersRepositoryI,
+) *Service {
+	return &Service{
+		logger:                 logger,
+		savedFiltersRepository: savedFiltersRepository,
+	}
+}
+
+// Save (ctx, params) -> creates a named filter or replaces the query of the existing one.
+// Returns *tasks_types.FilterSyntaxError wrapped into services_types.InvalidParamsError if the query can't be parsed.
+func (service *Service) Save(ctx context.Context, params types.SaveParams) error {
+	service.logger.Info("Services -> Filters -> Save")
+
+	params.Name = strings.ToLower(params.Name)
+
+	err := validateSaveParams(params)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Filters -> Save -> validateSaveParams(params)",
+			"error", err.Error(), "params", params,
+		)
+		return services_types.InvalidParams(err)
+	}
+
+	filterModel := models.SavedFilter{
+		UserID: params.UserID,
+		Name:   params.Name,
+		Query:  params.Query,
+	}
+	_, err = service.savedFiltersRepository.Save(ctx, filterModel)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Filters -> Save -> service.savedFiltersRepository.Save(ctx, filterModel)",
+			"error", err.Error(), "filterModel", filterModel,
+		)
+		return err
+	}
+
+	return nil
+}
+
+func (service *Service) FindByName(ctx context.Context, userID int64, name string) (models.SavedFilter, error) {
+	service.logger.Info("Services -> Filters -> FindByName")
+
+	filterModel, err := service.savedFiltersRepository.FindByName(ctx, userID, strings.ToLower(name))
+	if err != nil {
+		if errors.Is(err, repositories_types.ErrNotFound) {
+			err = services_types.ErrNotFound
+		}
+		service.logger.Errorw(
+			"Services -> Filters -> FindByName -> service.savedFiltersRepository.FindByName(ctx, userID, name)",
+			"error", err.Error(), "userID", userID, "name", name,
+		)
+		return models.SavedFilter{}, err
+	}
+
+	return filterModel, nil
+}
+
+func (service *Service) GetAllForUser(ctx context.Context, userID int64) ([]models.SavedFilter, error) {
+	service.logger.Info("Services -> Filters -> GetAllForUser")
+
+	filters, err := service.savedFiltersRepository.GetAllForUser(ctx, userID)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Filters -> GetAllForUser -> service.savedFiltersRepository.GetAllForUser(ctx, userID)",
+			"error", err.Error(), "userID", userID,
+		)
+		return []models.SavedFilter{}, err
+	}
+
+	return filters, nil
+}
+
+func (service *Service) DeleteByName(ctx context.Context, userID int64, name string) error {
+	service.logger.Info("Services -> Filters -> DeleteByName")
+
+	err := service.savedFiltersRepository.DeleteByName(ctx, userID, strings.ToLower(name))
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Filters -> DeleteByName -> service.savedFiltersRepository.DeleteByName(ctx, userID, name)",
+			"error", err.Error(), "userID", userID, "name", name,
+		)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,7 @@
+package types
+
+type SaveParams struct {
+	UserID int64
+	Name   string
+	Query  string
+}
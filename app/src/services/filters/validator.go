@@ -0,0 +1,51 @@
+package filters
+
+import (
+	"fmt"
+	"strings"
+	"tg_todo_bot/src/services/filters/types"
+	"tg_todo_bot/src/services/tasks/filter"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+const maxNameLength = 64
+
+func validateSaveParams(params types.SaveParams) error {
+	var emptyRequiredFields []string
+
+	if params.UserID == 0 {
+		emptyRequiredFields = append(emptyRequiredFields, "UserID")
+	}
+
+	if params.Name == "" {
+		emptyRequiredFields = append(emptyRequiredFields, "Name")
+	}
+
+	if params.Query == "" {
+		emptyRequiredFields = append(emptyRequiredFields, "Query")
+	}
+
+	if len(emptyRequiredFields) > 0 {
+		err := fmt.Errorf("some required fields are empty: [%s]", strings.Join(emptyRequiredFields, ", "))
+		return err
+	}
+
+	if utf8.RuneCountInString(params.Name) > maxNameLength {
+		err := fmt.Errorf("name must be at most %d characters", maxNameLength)
+		return err
+	}
+
+	for _, r := range params.Name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-' {
+			err := fmt.Errorf("name can contain only letters, digits, '_' and '-'")
+			return err
+		}
+	}
+
+	//Невалидный запрос сохранять бессмысленно
+	_, err := filter.Parse(params.Query, time.Now())
+
+	return err
+}
@@ -1,14 +1,22 @@
 package notifications
 
 import (
+	"context"
 	"tg_todo_bot/src/models"
+	services_types "tg_todo_bot/src/services/types"
 	"time"
 )
 
 type NotificationsRepositoryI interface {
-	Create(notification models.Notification) (models.Notification, error)
-	Update(notification models.Notification) error
-	DeleteByID(ID int64) error
-	GetUpcoming(upcomingTo time.Time) ([]models.Notification, error)
-	FindByID(ID int64) (models.Notification, error)
+	Create(ctx context.Context, notification models.Notification) (models.Notification, error)
+	Update(ctx context.Context, notification models.Notification) error
+	Fire(ctx context.Context, notification models.Notification, nextNotifyAt time.Time, message models.OutboxMessage) error
+	DeleteByID(ctx context.Context, ID int64) error
+	GetUpcoming(ctx context.Context, upcomingTo time.Time) ([]models.Notification, error)
+	FindByID(ctx context.Context, ID int64) (models.Notification, error)
+	FindByTasksIDs(ctx context.Context, tasksIDs []int64) (map[int64]models.Notification, error)
+}
+
+type EventsPublisherI interface {
+	Publish(ctx context.Context, event services_types.Event) error
 }
@@ -1,11 +1,14 @@
 package notifications
 
 import (
+	"context"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 	"tg_todo_bot/src/models"
 	repositories_types "tg_todo_bot/src/repositories/types"
 	"tg_todo_bot/src/services/notifications/types"
+	outbox_types "tg_todo_bot/src/services/outbox/types"
+	settings_types "tg_todo_bot/src/services/settings/types"
 	services_types "tg_todo_bot/src/services/types"
 	"time"
 )
@@ -13,19 +16,22 @@ import (
 type Service struct {
 	logger                  *zap.SugaredLogger
 	notificationsRepository NotificationsRepositoryI
+	eventsPublisher         EventsPublisherI
 }
 
 func NewService(
 	logger *zap.SugaredLogger,
 	notificationsRepository NotificationsRepositoryI,
+	eventsPublisher EventsPublisherI,
 ) *Service {
 	return &Service{
 		logger:                  logger,
 		notificationsRepository: notificationsRepository,
+		eventsPublisher:         eventsPublisher,
 	}
 }
 
-func (service *Service) Create(params types.CreateParams) error {
+func (service *Service) Create(ctx context.Context, params types.CreateParams) error {
 	service.logger.Info("Services -> Notifications -> Create")
 
 	err := validateCreateParams(params)
@@ -34,11 +40,12 @@ func (service *Service) Create(params types.CreateParams) error {
 			"Services -> Notifications -> Create -> validateCreateParams(params)",
 			"error", err.Error(), "params", params,
 		)
-		return err
+		return services_types.InvalidParams(err)
 	}
 
+	//Интервал из настроек пользователя передаёт вызывающий код
 	if params.RepeatInterval == 0 {
-		params.RepeatInterval = time.Hour
+		params.RepeatInterval = settings_types.DefaultRepeatInterval
 	}
 
 	if params.RepeatInterval < time.Minute {
@@ -51,10 +58,10 @@ func (service *Service) Create(params types.CreateParams) error {
 		RepeatInterval: params.RepeatInterval,
 	}
 
-	notificationModel, err = service.notificationsRepository.Create(notificationModel)
+	notificationModel, err = service.notificationsRepository.Create(ctx, notificationModel)
 	if err != nil {
 		service.logger.Errorw(
-			"Services -> Notifications -> Create -> service.notificationsRepository.Create(notificationModel)",
+			"Services -> Notifications -> Create -> service.notificationsRepository.Create(ctx, notificationModel)",
 			"error", err.Error(), "notificationModel", notificationModel,
 		)
 		return err
@@ -63,7 +70,7 @@ func (service *Service) Create(params types.CreateParams) error {
 	return nil
 }
 
-func (service *Service) Update(params types.UpdateParams) error {
+func (service *Service) Update(ctx context.Context, params types.UpdateParams) error {
 	service.logger.Info("Services -> Notifications -> Update")
 
 	err := validateUpdateParams(params)
@@ -72,16 +79,16 @@ func (service *Service) Update(params types.UpdateParams) error {
 			"Services -> Notifications -> Update -> validateUpdateParams(params)",
 			"error", err.Error(), "params", params,
 		)
-		return err
+		return services_types.InvalidParams(err)
 	}
 
-	notificationModel, err := service.notificationsRepository.FindByID(params.NotificationID)
+	notificationModel, err := service.notificationsRepository.FindByID(ctx, params.NotificationID)
 	if err != nil {
 		if errors.Is(err, repositories_types.ErrNotFound) {
 			err = services_types.ErrNotFound
 		}
 		service.logger.Errorw(
-			"Services -> Notifications -> Update -> service.notificationsRepository.FindByID(notificationID)",
+			"Services -> Notifications -> Update -> service.notificationsRepository.FindByID(ctx, notificationID)",
 			"error", err.Error(), "notificationID", params.NotificationID, "params", params,
 		)
 		return err
@@ -93,7 +100,7 @@ func (service *Service) Update(params types.UpdateParams) error {
 
 	if params.RepeatInterval.IsSet {
 		if params.RepeatInterval.Value == 0 {
-			params.RepeatInterval.Value = time.Hour
+			params.RepeatInterval.Value = settings_types.DefaultRepeatInterval
 		}
 
 		if params.RepeatInterval.Value < time.Minute {
@@ -103,10 +110,10 @@ func (service *Service) Update(params types.UpdateParams) error {
 		notificationModel.RepeatInterval = params.RepeatInterval.Value
 	}
 
-	err = service.notificationsRepository.Update(notificationModel)
+	err = service.notificationsRepository.Update(ctx, notificationModel)
 	if err != nil {
 		service.logger.Errorw(
-			"Services -> Notifications -> Update -> service.notificationsRepository.Update(notificationModel)",
+			"Services -> Notifications -> Update -> service.notificationsRepository.Update(ctx, notificationModel)",
 			"error", err.Error(), "notificationModel", notificationModel, "params", params,
 		)
 		return err
@@ -115,13 +122,67 @@ func (service *Service) Update(params types.UpdateParams) error {
 	return nil
 }
 
-func (service *Service) DeleteByID(notificationID int64) error {
+// Fire (ctx, notification, task, nextNotifyAt, now) -> moves the notification to its next repeat and saves the reminder
+// to the outbox in one transaction, then publishes reminder.fired. Nothing is done if the notification
+// was changed or fired by another replica meanwhile
+func (service *Service) Fire(ctx context.Context, notification models.Notification, task models.Task, nextNotifyAt, now time.Time) error {
+	service.logger.Info("Services -> Notifications -> Fire")
+
+	message, err := outbox_types.NewReminder(task.UserID, notification, now)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Notifications -> Fire -> outbox_types.NewReminder(userID, notification, now)",
+			"error", err.Error(), "notification", notification,
+		)
+		return err
+	}
+
+	err = service.notificationsRepository.Fire(ctx, notification, nextNotifyAt, message)
+	if errors.Is(err, repositories_types.ErrNotFound) {
+		service.logger.Infow(
+			"Services -> Notifications -> Fire -> the notification is changed meanwhile",
+			"notificationID", notification.ID,
+		)
+		return nil
+	}
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Notifications -> Fire -> service.notificationsRepository.Fire(ctx, notification, nextNotifyAt, message)",
+			"error", err.Error(), "notification", notification,
+		)
+		return err
+	}
+
+	if service.eventsPublisher == nil {
+		return nil
+	}
+
+	//В событии время сработавшего напоминания, а не следующего
+	event := services_types.Event{
+		Type:         services_types.EventReminderFired,
+		UserID:       task.UserID,
+		Task:         task,
+		Notification: &notification,
+		OccurredAt:   now,
+	}
+	err = service.eventsPublisher.Publish(ctx, event)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Notifications -> Fire -> service.eventsPublisher.Publish(ctx, event)",
+			"error", err.Error(), "event", event,
+		)
+	}
+
+	return nil
+}
+
+func (service *Service) DeleteByID(ctx context.Context, notificationID int64) error {
 	service.logger.Info("Services -> Notifications -> DeleteByID")
 
-	err := service.notificationsRepository.DeleteByID(notificationID)
+	err := service.notificationsRepository.DeleteByID(ctx, notificationID)
 	if err != nil {
 		service.logger.Errorw(
-			"Services -> Notifications -> DeleteByID -> service.notificationsRepository.DeleteByID(notificationID)",
+			"Services -> Notifications -> DeleteByID -> service.notificationsRepository.DeleteByID(ctx, notificationID)",
 			"error", err.Error(), "notificationID", notificationID,
 		)
 		return err
@@ -130,17 +191,17 @@ func (service *Service) DeleteByID(notificationID int64) error {
 	return nil
 }
 
-func (service *Service) GetUpcoming(upcomingTo time.Time) ([]models.Notification, error) {
+func (service *Service) GetUpcoming(ctx context.Context, upcomingTo time.Time) ([]models.Notification, error) {
 	service.logger.Info("Services -> Notifications -> GetUpcoming")
 
 	if upcomingTo.IsZero() {
 		upcomingTo = time.Now().Add(time.Minute)
 	}
 
-	notifications, err := service.notificationsRepository.GetUpcoming(upcomingTo)
+	notifications, err := service.notificationsRepository.GetUpcoming(ctx, upcomingTo)
 	if err != nil {
 		service.logger.Errorw(
-			"Services -> Notifications -> GetUpcoming -> service.notificationsRepository.GetUpcoming(upcomingTo)",
+			"Services -> Notifications -> GetUpcoming -> service.notificationsRepository.GetUpcoming(ctx, upcomingTo)",
 			"error", err.Error(), "upcomingTo", upcomingTo,
 		)
 		return []models.Notification{}, err
@@ -148,3 +209,24 @@ func (service *Service) GetUpcoming(upcomingTo time.Time) ([]models.Notification
 
 	return notifications, nil
 }
+
+// FindByTaskID (ctx, taskID) -> notification of the task, services_types.ErrNotFound if the task has none
+func (service *Service) FindByTaskID(ctx context.Context, taskID int64) (models.Notification, error) {
+	service.logger.Info("Services -> Notifications -> FindByTaskID")
+
+	notifications, err := service.notificationsRepository.FindByTasksIDs(ctx, []int64{taskID})
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Notifications -> FindByTaskID -> service.notificationsRepository.FindByTasksIDs(ctx, tasksIDs)",
+			"error", err.Error(), "taskID", taskID,
+		)
+		return models.Notification{}, err
+	}
+
+	notification, exist := notifications[taskID]
+	if !exist {
+		return models.Notification{}, services_types.ErrNotFound
+	}
+
+	return notification, nil
+}
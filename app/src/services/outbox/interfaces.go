@@ -0,0 +1,12 @@
+package outbox
+
+import (
+	"context"
+	"tg_todo_bot/src/models"
+	"time"
+)
+
+type OutboxRepositoryI interface {
+	Claim(ctx context.Context, now time.Time, lease time.Duration, limit uint) ([]models.OutboxMessage, error)
+	Update(ctx context.Context, message models.OutboxMessage) error
+}
@@ -0,0 +1,136 @@
+package outbox
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"tg_todo_bot/src/models"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	maxSendAttempts    = 6
+	firstRetryDelay    = 30 * time.Second
+	maxRetryDelay      = time.Hour
+	claimLease         = 5 * time.Minute //longer than sending of a batch, including waits of the send queue
+	messagesBatchSize  = 100
+	maxLastErrorLength = 512
+)
+
+// Service -> messages of the bot saved together with the changes they are about. A message is marked sent
+// only after it's sent, so it's delivered at least once: if the process dies in between, it's sent again
+type Service struct {
+	logger           *zap.SugaredLogger
+	outboxRepository OutboxRepositoryI
+}
+
+func NewService(
+	logger *zap.SugaredLogger,
+	outboxRepository OutboxRepositoryI,
+) *Service {
+	return &Service{
+		logger:           logger,
+		outboxRepository: outboxRepository,
+	}
+}
+
+// Claim (ctx, now) -> due messages taken for sending, every one has to be passed to MarkSent, MarkFailed or Skip
+func (service *Service) Claim(ctx context.Context, now time.Time) ([]models.OutboxMessage, error) {
+	service.logger.Info("Services -> Outbox -> Claim")
+
+	messages, err := service.outboxRepository.Claim(ctx, now, claimLease, messagesBatchSize)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Outbox -> Claim -> service.outboxRepository.Claim(ctx, now, lease, limit)",
+			"error", err.Error(), "now", now,
+		)
+		return []models.OutboxMessage{}, err
+	}
+
+	return messages, nil
+}
+
+func (service *Service) MarkSent(ctx context.Context, message models.OutboxMessage, now time.Time) error {
+	service.logger.Info("Services -> Outbox -> MarkSent")
+
+	message.Attempts++
+	message.SentAt = &now
+	message.NextAttemptAt = nil
+	message.LastError = ""
+
+	return service.update(ctx, message)
+}
+
+// MarkFailed (ctx, message, sendErr, now) -> the message is retried with exponential backoff until it's out of attempts
+func (service *Service) MarkFailed(ctx context.Context, message models.OutboxMessage, sendErr error, now time.Time) error {
+	service.logger.Info("Services -> Outbox -> MarkFailed")
+
+	message.Attempts++
+	message.LastError = truncateError(sendErr.Error())
+
+	if message.Attempts >= maxSendAttempts {
+		service.logger.Errorw(
+			"Services -> Outbox -> MarkFailed -> out of attempts",
+			"messageID", message.ID, "kind", message.Kind, "userID", message.UserID, "error", message.LastError,
+		)
+		message.NextAttemptAt = nil
+		return service.update(ctx, message)
+	}
+
+	nextAttemptAt := now.Add(retryDelay(message.Attempts))
+	message.NextAttemptAt = &nextAttemptAt
+
+	return service.update(ctx, message)
+}
+
+// Skip (ctx, message, reason) -> the message isn't needed anymore, like a reminder of a completed task,
+// or can't be sent at all, like to a user who blocked the bot
+func (service *Service) Skip(ctx context.Context, message models.OutboxMessage, reason string) error {
+	service.logger.Info("Services -> Outbox -> Skip")
+
+	message.NextAttemptAt = nil
+	message.LastError = truncateError(reason)
+
+	return service.update(ctx, message)
+}
+
+func (service *Service) update(ctx context.Context, message models.OutboxMessage) error {
+	err := service.outboxRepository.Update(ctx, message)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Outbox -> update -> service.outboxRepository.Update(ctx, message)",
+			"error", err.Error(), "messageID", message.ID,
+		)
+		return err
+	}
+
+	return nil
+}
+
+// truncateError (text) -> at most maxLastErrorLength bytes, cut on a rune boundary: the text is saved to a text column
+func truncateError(text string) string {
+	if len(text) <= maxLastErrorLength {
+		return text
+	}
+
+	end := maxLastErrorLength
+	for end > 0 && !utf8.RuneStart(text[end]) {
+		end--
+	}
+
+	return text[:end]
+}
+
+// retryDelay (attempts) -> 30s, 1m, 2m... after the failed attempt number attempts, at most maxRetryDelay
+func retryDelay(attempts int) time.Duration {
+	delay := firstRetryDelay
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+
+	return delay
+}
@@ -0,0 +1,165 @@
+package outbox
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"strings"
+	"testing"
+	"tg_todo_bot/src/models"
+	"tg_todo_bot/src/services/outbox/types"
+	"time"
+	"unicode/utf8"
+)
+
+type fakeOutboxRepository struct {
+	messages map[int64]models.OutboxMessage
+}
+
+func (repository *fakeOutboxRepository) Claim(ctx context.Context, now time.Time, lease time.Duration, limit uint) ([]models.OutboxMessage, error) {
+	var messages []models.OutboxMessage
+	for id, message := range repository.messages {
+		if message.NextAttemptAt == nil || message.NextAttemptAt.After(now) {
+			continue
+		}
+
+		leasedUntil := now.Add(lease)
+		message.NextAttemptAt = &leasedUntil
+		repository.messages[id] = message
+		messages = append(messages, message)
+	}
+
+	return messages, nil
+}
+
+func (repository *fakeOutboxRepository) Update(ctx context.Context, message models.OutboxMessage) error {
+	repository.messages[message.ID] = message
+
+	return nil
+}
+
+func TestRetryDelay(t *testing.T) {
+	testCases := []struct {
+		attempts int
+		expected time.Duration
+	}{
+		{attempts: 1, expected: 30 * time.Second},
+		{attempts: 2, expected: time.Minute},
+		{attempts: 4, expected: 4 * time.Minute},
+		{attempts: 10, expected: time.Hour},
+	}
+
+	for _, testCase := range testCases {
+		delay := retryDelay(testCase.attempts)
+		if delay != testCase.expected {
+			t.Fatalf("%d attempts: got %s, expected %s", testCase.attempts, delay, testCase.expected)
+		}
+	}
+}
+
+func TestTruncateError(t *testing.T) {
+	//Кириллица занимает два байта, нечётная граница попадает в середину руны
+	text := "x" + strings.Repeat("ошибка", maxLastErrorLength)
+
+	truncated := truncateError(text)
+	if !utf8.ValidString(truncated) {
+		t.Fatalf("got invalid UTF-8 %q", truncated[len(truncated)-4:])
+	}
+	if len(truncated) != maxLastErrorLength-1 {
+		t.Fatalf("got %d bytes, expected %d", len(truncated), maxLastErrorLength-1)
+	}
+
+	if truncateError("short") != "short" {
+		t.Fatal("a short text must be kept")
+	}
+}
+
+func TestMessageLifecycle(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	message, err := types.NewReminder(7, models.Notification{ID: 3, TaskID: 5, NotifyAt: now}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	message.ID = 1
+
+	repository := &fakeOutboxRepository{messages: map[int64]models.OutboxMessage{message.ID: message}}
+	service := NewService(zap.NewNop().Sugar(), repository)
+
+	messages, err := service.Claim(context.Background(), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 1 {
+		t.Fatalf("expected the due message, got %+v", messages)
+	}
+
+	//Пока сообщение захвачено, его не берут повторно
+	messages, err = service.Claim(context.Background(), now.Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 0 {
+		t.Fatalf("the message is claimed, got %+v", messages)
+	}
+
+	err = service.MarkFailed(context.Background(), message, errors.New("timeout"), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	failed := repository.messages[message.ID]
+	if failed.Attempts != 1 || failed.LastError != "timeout" || failed.NextAttemptAt == nil || !failed.NextAttemptAt.Equal(now.Add(firstRetryDelay)) {
+		t.Fatalf("unexpected failed message %+v", failed)
+	}
+
+	err = service.MarkSent(context.Background(), failed, now.Add(firstRetryDelay))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sent := repository.messages[message.ID]
+	if sent.Attempts != 2 || sent.LastError != "" || sent.NextAttemptAt != nil || sent.SentAt == nil {
+		t.Fatalf("unexpected sent message %+v", sent)
+	}
+}
+
+func TestMarkFailedOutOfAttempts(t *testing.T) {
+	now := time.Now()
+	message := models.OutboxMessage{ID: 1, Kind: types.KindReminder, Attempts: maxSendAttempts - 1, NextAttemptAt: &now}
+	repository := &fakeOutboxRepository{messages: map[int64]models.OutboxMessage{message.ID: message}}
+	service := NewService(zap.NewNop().Sugar(), repository)
+
+	err := service.MarkFailed(context.Background(), message, errors.New("Bad Request: chat not found"), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	failed := repository.messages[message.ID]
+	if failed.NextAttemptAt != nil || failed.SentAt != nil || failed.Attempts != maxSendAttempts {
+		t.Fatalf("the message must be given up, got %+v", failed)
+	}
+}
+
+func TestNewReminderDedupKey(t *testing.T) {
+	now := time.Now()
+	notification := models.Notification{ID: 3, TaskID: 5, NotifyAt: now}
+
+	first, err := types.NewReminder(7, notification, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := types.NewReminder(7, notification, now.Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.DedupKey != again.DedupKey {
+		t.Fatalf("the same firing: got %q and %q", first.DedupKey, again.DedupKey)
+	}
+
+	notification.NotifyAt = now.Add(time.Hour)
+	next, err := types.NewReminder(7, notification, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.DedupKey == first.DedupKey {
+		t.Fatalf("the next firing must have another key, got %q", next.DedupKey)
+	}
+}
@@ -0,0 +1,36 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"tg_todo_bot/src/models"
+	"time"
+)
+
+// Kinds of outbox messages, the relay decides by the kind how to send the payload
+const (
+	KindReminder = "reminder"
+)
+
+// ReminderPayload -> the task is loaded again when the reminder is sent, so the text has the current title
+type ReminderPayload struct {
+	TaskID   int64     `json:"task_id"`
+	NotifyAt time.Time `json:"notify_at"` //the time the reminder fired at
+}
+
+// NewReminder (userID, notification, now) -> the outbox message for one firing of the notification,
+// the dedup key is the same for the same firing
+func NewReminder(userID int64, notification models.Notification, now time.Time) (models.OutboxMessage, error) {
+	payload, err := json.Marshal(ReminderPayload{TaskID: notification.TaskID, NotifyAt: notification.NotifyAt})
+	if err != nil {
+		return models.OutboxMessage{}, err
+	}
+
+	return models.OutboxMessage{
+		UserID:        userID,
+		Kind:          KindReminder,
+		DedupKey:      fmt.Sprintf("%s:%d:%d", KindReminder, notification.ID, notification.NotifyAt.Unix()),
+		Payload:       payload,
+		NextAttemptAt: &now,
+	}, nil
+}
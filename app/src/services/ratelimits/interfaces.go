@@ -0,0 +1,10 @@
+package ratelimits
+
+import (
+	"context"
+	"tg_todo_bot/src/repositories/types"
+)
+
+type RateLimitsRepositoryI interface {
+	Take(ctx context.Context, userID int64, bucket string, limit types.RateLimit) (bool, float64, error)
+}
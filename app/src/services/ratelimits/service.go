@@ -0,0 +1,69 @@
+package ratelimits
+
+import (
+	"context"
+	"go.uber.org/zap"
+	repositories_types "tg_todo_bot/src/repositories/types"
+	"tg_todo_bot/src/services/ratelimits/types"
+	services_types "tg_todo_bot/src/services/types"
+	"time"
+)
+
+type Service struct {
+	logger               *zap.SugaredLogger
+	rateLimitsRepository RateLimitsRepositoryI
+	limits               map[string]types.Limit
+}
+
+// NewService (logger, rateLimitsRepository, limits) -> limits by bucket, buckets without a limit aren't limited.
+// types.ThrottleNoticesLimit is added if limits don't set it
+func NewService(
+	logger *zap.SugaredLogger,
+	rateLimitsRepository RateLimitsRepositoryI,
+	limits map[string]types.Limit,
+) *Service {
+	if _, exist := limits[types.BucketThrottleNotices]; !exist {
+		limits[types.BucketThrottleNotices] = types.ThrottleNoticesLimit
+	}
+
+	return &Service{
+		logger:               logger,
+		rateLimitsRepository: rateLimitsRepository,
+		limits:               limits,
+	}
+}
+
+// Take (ctx, userID, bucket) -> nil if the request is allowed, *services_types.RateLimitedError if the bucket is empty.
+// Errors of the database are logged and the request is allowed: limits protect the bot, they shouldn't stop it
+func (service *Service) Take(ctx context.Context, userID int64, bucket string) error {
+	limit, exist := service.limits[bucket]
+	if !exist || limit.Burst <= 0 || limit.PerMinute <= 0 {
+		return nil
+	}
+
+	perSecond := limit.PerMinute / 60
+	taken, tokens, err := service.rateLimitsRepository.Take(
+		ctx,
+		userID,
+		bucket,
+		repositories_types.RateLimit{Capacity: float64(limit.Burst), PerSecond: perSecond},
+	)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> RateLimits -> Take -> service.rateLimitsRepository.Take(ctx, userID, bucket, limit)",
+			"error", err.Error(), "userID", userID, "bucket", bucket,
+		)
+		return nil
+	}
+	if taken {
+		return nil
+	}
+
+	//Через это время в ведре наберётся целый токен
+	retryAfter := time.Duration((1 - tokens) / perSecond * float64(time.Second))
+	service.logger.Infow("Services -> RateLimits -> Take -> throttled",
+		"userID", userID, "bucket", bucket, "retryAfter", retryAfter,
+	)
+
+	return &services_types.RateLimitedError{RetryAfter: retryAfter}
+}
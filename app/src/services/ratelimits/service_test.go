@@ -0,0 +1,71 @@
+package ratelimits
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"testing"
+	repositories_types "tg_todo_bot/src/repositories/types"
+	"tg_todo_bot/src/services/ratelimits/types"
+	services_types "tg_todo_bot/src/services/types"
+	"time"
+)
+
+// fakeRateLimitsRepository -> the bucket always has the given tokens, calls are counted
+type fakeRateLimitsRepository struct {
+	tokens float64
+	err    error
+	calls  int
+}
+
+func (repository *fakeRateLimitsRepository) Take(ctx context.Context, userID int64, bucket string, limit repositories_types.RateLimit) (bool, float64, error) {
+	repository.calls++
+	if repository.tokens >= 1 {
+		return true, repository.tokens - 1, repository.err
+	}
+
+	return false, repository.tokens, repository.err
+}
+
+func TestTake(t *testing.T) {
+	testCases := []struct {
+		name       string
+		repository *fakeRateLimitsRepository
+		bucket     string
+		retryAfter time.Duration //0 if the request is allowed
+		calls      int
+	}{
+		{name: "allowed", repository: &fakeRateLimitsRepository{tokens: 5}, bucket: types.BucketCommands, calls: 1},
+		{name: "throttled", repository: &fakeRateLimitsRepository{tokens: 0.5}, bucket: types.BucketCommands, retryAfter: time.Second, calls: 1},
+		{name: "empty bucket", repository: &fakeRateLimitsRepository{tokens: 0}, bucket: types.BucketCommands, retryAfter: 2 * time.Second, calls: 1},
+		{name: "limit is off", repository: &fakeRateLimitsRepository{tokens: 0}, bucket: types.BucketTasks, calls: 0},
+		{name: "unknown bucket", repository: &fakeRateLimitsRepository{tokens: 0}, bucket: "unknown", calls: 0},
+		{name: "database error", repository: &fakeRateLimitsRepository{tokens: 0, err: errors.New("connection refused")}, bucket: types.BucketCommands, calls: 1},
+	}
+
+	for _, testCase := range testCases {
+		//30 запросов в минуту — токен каждые 2 секунды
+		service := NewService(zap.NewNop().Sugar(), testCase.repository, map[string]types.Limit{
+			types.BucketCommands: {Burst: 10, PerMinute: 30},
+			types.BucketTasks:    {Burst: 10, PerMinute: 0},
+		})
+
+		err := service.Take(context.Background(), 1, testCase.bucket)
+
+		var rateLimitedErr *services_types.RateLimitedError
+		switch {
+		case testCase.retryAfter == 0 && err != nil:
+			t.Fatalf("%s: got %v, expected the request to be allowed", testCase.name, err)
+		case testCase.retryAfter != 0 && !errors.As(err, &rateLimitedErr):
+			t.Fatalf("%s: got %v, expected RateLimitedError", testCase.name, err)
+		case testCase.retryAfter != 0 && rateLimitedErr.RetryAfter != testCase.retryAfter:
+			t.Fatalf("%s: got retry after %s, expected %s", testCase.name, rateLimitedErr.RetryAfter, testCase.retryAfter)
+		}
+		if testCase.retryAfter != 0 && !errors.Is(err, services_types.ErrRateLimited) {
+			t.Fatalf("%s: expected errors.Is(err, ErrRateLimited)", testCase.name)
+		}
+		if testCase.repository.calls != testCase.calls {
+			t.Fatalf("%s: got %d calls of the repository, expected %d", testCase.name, testCase.repository.calls, testCase.calls)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package types
+
+// Buckets of rate limits, every user has an own bucket of each kind
+const (
+	BucketCommands        = "commands"         //commands and buttons of the bot
+	BucketTasks           = "tasks"            //new tasks from the bot, the API and CalDAV
+	BucketThrottleNotices = "throttle_notices" //answers about throttling, so a flood isn't answered with a flood
+)
+
+// Limit -> Burst requests at once, then PerMinute requests a minute. Zero Burst or PerMinute turns the limit off
+type Limit struct {
+	Burst     int
+	PerMinute float64
+}
+
+// ThrottleNoticesLimit -> a throttled user is told about it at most once a minute
+var ThrottleNoticesLimit = Limit{Burst: 1, PerMinute: 1}
@@ -0,0 +1,15 @@
+package settings
+
+import (
+	"context"
+	"tg_todo_bot/src/models"
+	"time"
+)
+
+type UserSettingsRepositoryI interface {
+	Save(ctx context.Context, settings models.UserSettings) (models.UserSettings, error)
+	FindByUserID(ctx context.Context, userID int64) (models.UserSettings, error)
+	FindByUsersIDs(ctx context.Context, usersIDs []int64) (map[int64]models.UserSettings, error)
+	GetWithDigest(ctx context.Context) ([]models.UserSettings, error)
+	SetLastDigestOn(ctx context.Context, userID int64, date time.Time) error
+}
@@ -0,0 +1,151 @@
+package settings
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"tg_todo_bot/src/models"
+	repositories_types "tg_todo_bot/src/repositories/types"
+	"tg_todo_bot/src/services/settings/types"
+	services_types "tg_todo_bot/src/services/types"
+	"time"
+)
+
+type Service struct {
+	logger                 *zap.SugaredLogger
+	userSettingsRepository UserSettingsRepositoryI
+}
+
+func NewService(
+	logger *zap.SugaredLogger,
+	userSettingsRepository UserSettingsRepositoryI,
+) *Service {
+	return &Service{
+		logger:                 logger,
+		userSettingsRepository: userSettingsRepository,
+	}
+}
+
+// Get (ctx, userID) -> settings of the user, defaults if the user hasn't changed anything
+func (service *Service) Get(ctx context.Context, userID int64) (models.UserSettings, error) {
+	service.logger.Info("Services -> Settings -> Get")
+
+	settings, err := service.userSettingsRepository.FindByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repositories_types.ErrNotFound) {
+			return types.Default(userID), nil
+		}
+		service.logger.Errorw(
+			"Services -> Settings -> Get -> service.userSettingsRepository.FindByUserID(ctx, userID)",
+			"error", err.Error(), "userID", userID,
+		)
+		return models.UserSettings{}, err
+	}
+
+	return settings, nil
+}
+
+func (service *Service) Update(ctx context.Context, params types.UpdateParams) (models.UserSettings, error) {
+	service.logger.Info("Services -> Settings -> Update")
+
+	err := validateUpdateParams(params)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Settings -> Update -> validateUpdateParams(params)",
+			"error", err.Error(), "params", params,
+		)
+		return models.UserSettings{}, services_types.InvalidParams(err)
+	}
+
+	settings, err := service.Get(ctx, params.UserID)
+	if err != nil {
+		return models.UserSettings{}, err
+	}
+
+	if params.Language.IsSet {
+		settings.Language = params.Language.Value
+	}
+	if params.Timezone.IsSet {
+		settings.Timezone = params.Timezone.Value
+	}
+	if params.ReminderOffset.IsSet {
+		settings.ReminderOffset = params.ReminderOffset.Value
+	}
+	if params.RepeatInterval.IsSet {
+		settings.RepeatInterval = params.RepeatInterval.Value
+	}
+	if params.ListSort.IsSet {
+		settings.ListSort = params.ListSort.Value
+	}
+	if params.DigestMinute.IsSet {
+		settings.DigestMinute = params.DigestMinute.Value
+	}
+
+	settings, err = service.userSettingsRepository.Save(ctx, settings)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Settings -> Update -> service.userSettingsRepository.Save(ctx, settings)",
+			"error", err.Error(), "settings", settings,
+		)
+		return models.UserSettings{}, err
+	}
+
+	return settings, nil
+}
+
+// GetDigestDue (ctx, now) -> settings of users whose digest time has come today in their timezone
+// and the digest hasn't been sent yet
+func (service *Service) GetDigestDue(ctx context.Context, now time.Time) ([]models.UserSettings, error) {
+	service.logger.Info("Services -> Settings -> GetDigestDue")
+
+	settingsList, err := service.userSettingsRepository.GetWithDigest(ctx)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Settings -> GetDigestDue -> service.userSettingsRepository.GetWithDigest(ctx)",
+			"error", err.Error(),
+		)
+		return []models.UserSettings{}, err
+	}
+
+	var dueSettings []models.UserSettings
+	for _, settings := range settingsList {
+		if isDigestDue(settings, now) {
+			dueSettings = append(dueSettings, settings)
+		}
+	}
+
+	return dueSettings, nil
+}
+
+func isDigestDue(settings models.UserSettings, now time.Time) bool {
+	if settings.DigestMinute == nil {
+		return false
+	}
+
+	localNow := now.In(types.Location(settings))
+	if localNow.Hour()*60+localNow.Minute() < *settings.DigestMinute {
+		return false
+	}
+
+	//DATE приходит из базы как полночь UTC
+	today := time.Date(localNow.Year(), localNow.Month(), localNow.Day(), 0, 0, 0, 0, time.UTC)
+
+	return settings.LastDigestOn == nil || settings.LastDigestOn.Before(today)
+}
+
+// MarkDigestSent (ctx, settings, now) -> the digest won't be sent again until the next day in the user's timezone
+func (service *Service) MarkDigestSent(ctx context.Context, settings models.UserSettings, now time.Time) error {
+	service.logger.Info("Services -> Settings -> MarkDigestSent")
+
+	localNow := now.In(types.Location(settings))
+	err := service.userSettingsRepository.SetLastDigestOn(ctx, settings.UserID, localNow)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Settings -> MarkDigestSent -> service.userSettingsRepository.SetLastDigestOn(ctx, userID, date)",
+			"error", err.Error(), "userID", settings.UserID,
+		)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package settings
+
+import (
+	"testing"
+	"tg_todo_bot/src/models"
+	"time"
+)
+
+func TestIsDigestDue(t *testing.T) {
+	digestMinute := 9 * 60
+	yesterday := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	today := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		settings models.UserSettings
+		now      time.Time
+		expected bool
+	}{
+		{
+			name:     "turned off",
+			settings: models.UserSettings{Timezone: "UTC"},
+			now:      time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC),
+			expected: false,
+		},
+		{
+			name:     "too early",
+			settings: models.UserSettings{Timezone: "UTC", DigestMinute: &digestMinute},
+			now:      time.Date(2026, 10, 19, 8, 59, 0, 0, time.UTC),
+			expected: false,
+		},
+		{
+			name:     "first digest",
+			settings: models.UserSettings{Timezone: "UTC", DigestMinute: &digestMinute},
+			now:      time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC),
+			expected: true,
+		},
+		{
+			name:     "sent yesterday",
+			settings: models.UserSettings{Timezone: "UTC", DigestMinute: &digestMinute, LastDigestOn: &yesterday},
+			now:      time.Date(2026, 10, 19, 9, 1, 0, 0, time.UTC),
+			expected: true,
+		},
+		{
+			name:     "already sent today",
+			settings: models.UserSettings{Timezone: "UTC", DigestMinute: &digestMinute, LastDigestOn: &today},
+			now:      time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC),
+			expected: false,
+		},
+		{
+			//06:00 UTC — уже 09:00 в Москве
+			name:     "user's timezone",
+			settings: models.UserSettings{Timezone: "Europe/Moscow", DigestMinute: &digestMinute, LastDigestOn: &yesterday},
+			now:      time.Date(2026, 10, 19, 6, 0, 0, 0, time.UTC),
+			expected: true,
+		},
+		{
+			//23:00 UTC 18 октября — уже 19 октября во Владивостоке
+			name:     "next day in user's timezone",
+			settings: models.UserSettings{Timezone: "Asia/Vladivostok", DigestMinute: &digestMinute, LastDigestOn: &yesterday},
+			now:      time.Date(2026, 10, 18, 23, 0, 0, 0, time.UTC),
+			expected: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		due := isDigestDue(testCase.settings, testCase.now)
+		if due != testCase.expected {
+			t.Fatalf("%s: got %v, expected %v", testCase.name, due, testCase.expected)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package types
+
+import (
+	"tg_todo_bot/src/models"
+	"time"
+)
+
+const (
+	LanguageRu = "ru"
+	LanguageEn = "en"
+
+	DefaultLanguage       = LanguageRu
+	DefaultTimezone       = "UTC"
+	DefaultRepeatInterval = time.Hour
+	DefaultListSort       = "due"
+)
+
+// Languages -> supported languages of the bot
+var Languages = []string{LanguageRu, LanguageEn}
+
+// ListSorts -> values of the list sort order, the same as "sort:" of the filter query
+var ListSorts = []string{"due", "prio", "created", "title"}
+
+// Default (userID) -> settings of the user who hasn't changed anything
+func Default(userID int64) models.UserSettings {
+	return models.UserSettings{
+		UserID:         userID,
+		Language:       DefaultLanguage,
+		Timezone:       DefaultTimezone,
+		RepeatInterval: DefaultRepeatInterval,
+		ListSort:       DefaultListSort,
+	}
+}
+
+// Location (settings) -> location of the user's timezone, UTC if it can't be loaded
+func Location(settings models.UserSettings) *time.Location {
+	location, err := time.LoadLocation(settings.Timezone)
+	if err != nil || settings.Timezone == "" {
+		return time.UTC
+	}
+
+	return location
+}
+
+type UpdateParams struct {
+	UserID   int64
+	Language struct {
+		Value string
+		IsSet bool
+	}
+	Timezone struct {
+		Value string
+		IsSet bool
+	}
+	ReminderOffset struct {
+		Value time.Duration
+		IsSet bool
+	}
+	RepeatInterval struct {
+		Value time.Duration
+		IsSet bool
+	}
+	ListSort struct {
+		Value string
+		IsSet bool
+	}
+	DigestMinute struct {
+		Value *int
+		IsSet bool
+	}
+}
@@ -0,0 +1,71 @@
+package settings
+
+import (
+	"fmt"
+	"tg_todo_bot/src/services/settings/types"
+	"time"
+)
+
+const (
+	maxReminderOffset = 7 * 24 * time.Hour
+	minRepeatInterval = time.Minute
+	minutesInDay      = 24 * 60
+)
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func validateUpdateParams(params types.UpdateParams) error {
+	if params.UserID == 0 {
+		err := fmt.Errorf("some required fields are empty: [UserID]")
+		return err
+	}
+
+	if params.Language.IsSet && !contains(types.Languages, params.Language.Value) {
+		err := fmt.Errorf("unsupported language '%s'", params.Language.Value)
+		return err
+	}
+
+	if params.Timezone.IsSet {
+		//Пустая строка для LoadLocation означает UTC, а не ошибку
+		if params.Timezone.Value == "" {
+			err := fmt.Errorf("timezone is empty")
+			return err
+		}
+		_, err := time.LoadLocation(params.Timezone.Value)
+		if err != nil {
+			return err
+		}
+	}
+
+	if params.ReminderOffset.IsSet && (params.ReminderOffset.Value < 0 || params.ReminderOffset.Value > maxReminderOffset) {
+		err := fmt.Errorf("reminder offset must be between 0 and %s", maxReminderOffset)
+		return err
+	}
+
+	if params.RepeatInterval.IsSet && params.RepeatInterval.Value < minRepeatInterval {
+		err := fmt.Errorf("repeat interval must be at least %s", minRepeatInterval)
+		return err
+	}
+
+	if params.ListSort.IsSet && !contains(types.ListSorts, params.ListSort.Value) {
+		err := fmt.Errorf("unsupported list sort '%s'", params.ListSort.Value)
+		return err
+	}
+
+	if params.DigestMinute.IsSet && params.DigestMinute.Value != nil {
+		if *params.DigestMinute.Value < 0 || *params.DigestMinute.Value >= minutesInDay {
+			err := fmt.Errorf("digest time must be between 00:00 and 23:59")
+			return err
+		}
+	}
+
+	return nil
+}
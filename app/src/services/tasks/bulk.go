@@ -0,0 +1,50 @@
+package tasks
+
+import (
+	"context"
+	"tg_todo_bot/src/models"
+	"tg_todo_bot/src/services/tasks/types"
+	services_types "tg_todo_bot/src/services/types"
+)
+
+// CreateMany (ctx, params) -> creates all tasks or none of them, every task is validated before anything is created
+func (service *Service) CreateMany(ctx context.Context, params []types.CreateParams) ([]models.Task, error) {
+	service.logger.Info("Services -> Tasks -> CreateMany")
+
+	err := validateCreateManyParams(params)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Tasks -> CreateMany -> validateCreateManyParams(params)",
+			"error", err.Error(), "params", params,
+		)
+		return []models.Task{}, services_types.InvalidParams(err)
+	}
+
+	tasksModels := make([]models.Task, 0, len(params))
+	for _, taskParams := range params {
+		tasksModels = append(tasksModels, models.Task{
+			Title:       taskParams.Title,
+			Description: taskParams.Description,
+			Datetime:    taskParams.Datetime,
+			Done:        false,
+			Priority:    taskParams.Priority,
+			UserID:      taskParams.UserID,
+			Tags:        normalizeTags(taskParams.Tags),
+		})
+	}
+
+	tasksModels, err = service.tasksRepository.CreateMany(ctx, tasksModels)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Tasks -> CreateMany -> service.tasksRepository.CreateMany(ctx, tasksModels)",
+			"error", err.Error(), "count", len(params),
+		)
+		return []models.Task{}, err
+	}
+
+	for _, task := range tasksModels {
+		service.publish(ctx, services_types.EventTaskCreated, task)
+	}
+
+	return tasksModels, nil
+}
@@ -0,0 +1,72 @@
+package tasks
+
+import (
+	"context"
+	"tg_todo_bot/src/models"
+	repositories_types "tg_todo_bot/src/repositories/types"
+	"time"
+)
+
+// maxCalendarTasks -> calendar apps download all tasks on every refresh, so they are limited
+const maxCalendarTasks = 1000
+
+// GetDatedForUser (ctx, userID, from) -> tasks with datetime after from, completed ones too, the earliest first
+func (service *Service) GetDatedForUser(ctx context.Context, userID int64, from time.Time) ([]models.Task, error) {
+	service.logger.Info("Services -> Tasks -> GetDatedForUser")
+
+	tasksFilter := repositories_types.TasksFilter{
+		WithDue: true,
+		DueFrom: &from,
+		SortBy:  repositories_types.TasksSortByDue,
+		Limit:   maxCalendarTasks,
+	}
+	tasks, err := service.tasksRepository.Filter(ctx, userID, tasksFilter)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Tasks -> GetDatedForUser -> service.tasksRepository.Filter(ctx, userID, tasksFilter)",
+			"error", err.Error(), "userID", userID, "tasksFilter", tasksFilter,
+		)
+		return []models.Task{}, err
+	}
+
+	err = service.setRelations(ctx, tasks)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Tasks -> GetDatedForUser -> service.setRelations(ctx, tasks)",
+			"error", err.Error(), "tasks", tasks,
+		)
+		return []models.Task{}, err
+	}
+
+	return tasks, nil
+}
+
+// GetAllForUser (ctx, userID) -> active and completed tasks, the newest first, for CalDAV clients which sync all of them
+func (service *Service) GetAllForUser(ctx context.Context, userID int64) ([]models.Task, error) {
+	service.logger.Info("Services -> Tasks -> GetAllForUser")
+
+	tasksFilter := repositories_types.TasksFilter{
+		SortBy:   repositories_types.TasksSortByCreated,
+		SortDesc: true,
+		Limit:    maxCalendarTasks,
+	}
+	tasks, err := service.tasksRepository.Filter(ctx, userID, tasksFilter)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Tasks -> GetAllForUser -> service.tasksRepository.Filter(ctx, userID, tasksFilter)",
+			"error", err.Error(), "userID", userID,
+		)
+		return []models.Task{}, err
+	}
+
+	err = service.setRelations(ctx, tasks)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Tasks -> GetAllForUser -> service.setRelations(ctx, tasks)",
+			"error", err.Error(), "tasks", tasks,
+		)
+		return []models.Task{}, err
+	}
+
+	return tasks, nil
+}
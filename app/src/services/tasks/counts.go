@@ -0,0 +1,21 @@
+package tasks
+
+import (
+	"context"
+	"tg_todo_bot/src/services/tasks/types"
+)
+
+func (service *Service) CountForUser(ctx context.Context, userID int64) (types.Counts, error) {
+	service.logger.Info("Services -> Tasks -> CountForUser")
+
+	active, completed, err := service.tasksRepository.CountForUser(ctx, userID)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Tasks -> CountForUser -> service.tasksRepository.CountForUser(ctx, userID)",
+			"error", err.Error(), "userID", userID,
+		)
+		return types.Counts{}, err
+	}
+
+	return types.Counts{Active: active, Completed: completed}, nil
+}
@@ -0,0 +1,230 @@
+package tasks
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"tg_todo_bot/src/models"
+	repositories_types "tg_todo_bot/src/repositories/types"
+	"tg_todo_bot/src/services/tasks/types"
+	services_types "tg_todo_bot/src/services/types"
+)
+
+// AddDependency (ctx, params) -> task params.TaskID becomes blocked by task params.BlockedByTaskID
+func (service *Service) AddDependency(ctx context.Context, params types.DependencyParams) error {
+	service.logger.Info("Services -> Tasks -> AddDependency")
+
+	err := validateDependencyParams(params)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Tasks -> AddDependency -> validateDependencyParams(params)",
+			"error", err.Error(), "params", params,
+		)
+		return services_types.InvalidParams(err)
+	}
+
+	task, err := service.FindByID(ctx, params.TaskID)
+	if err != nil {
+		return err
+	}
+
+	blocker, err := service.FindByID(ctx, params.BlockedByTaskID)
+	if err != nil {
+		return err
+	}
+
+	if task.UserID != blocker.UserID {
+		err = services_types.ErrNotFound
+		service.logger.Errorw(
+			"Services -> Tasks -> AddDependency -> tasks belong to different users",
+			"error", err.Error(), "params", params,
+		)
+		return err
+	}
+
+	hasCycle, err := service.hasDependencyPath(ctx, params.BlockedByTaskID, params.TaskID)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Tasks -> AddDependency -> service.hasDependencyPath(ctx, blockedByTaskID, taskID)",
+			"error", err.Error(), "params", params,
+		)
+		return err
+	}
+	if hasCycle {
+		err = services_types.ErrDependencyCycle
+		service.logger.Errorw(
+			"Services -> Tasks -> AddDependency -> service.hasDependencyPath(ctx, blockedByTaskID, taskID)",
+			"error", err.Error(), "params", params,
+		)
+		return err
+	}
+
+	dependency := models.TaskDependency{
+		TaskID:          params.TaskID,
+		BlockedByTaskID: params.BlockedByTaskID,
+	}
+	_, err = service.taskDependenciesRepository.Create(ctx, dependency)
+	if err != nil {
+		if errors.Is(err, repositories_types.ErrAlreadyExist) {
+			return nil
+		}
+		service.logger.Errorw(
+			"Services -> Tasks -> AddDependency -> service.taskDependenciesRepository.Create(ctx, dependency)",
+			"error", err.Error(), "dependency", dependency,
+		)
+		return err
+	}
+
+	return nil
+}
+
+func (service *Service) RemoveDependency(ctx context.Context, params types.DependencyParams) error {
+	service.logger.Info("Services -> Tasks -> RemoveDependency")
+
+	err := validateDependencyParams(params)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Tasks -> RemoveDependency -> validateDependencyParams(params)",
+			"error", err.Error(), "params", params,
+		)
+		return services_types.InvalidParams(err)
+	}
+
+	err = service.taskDependenciesRepository.Delete(ctx, params.TaskID, params.BlockedByTaskID)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Tasks -> RemoveDependency -> service.taskDependenciesRepository.Delete(ctx, taskID, blockedByTaskID)",
+			"error", err.Error(), "params", params,
+		)
+		return err
+	}
+
+	return nil
+}
+
+// hasDependencyPath (ctx, fromTaskID, toTaskID) -> true if fromTaskID is blocked by toTaskID directly or transitively
+func (service *Service) hasDependencyPath(ctx context.Context, fromTaskID, toTaskID int64) (bool, error) {
+	visited := map[int64]bool{fromTaskID: true}
+	frontier := []int64{fromTaskID}
+
+	for len(frontier) > 0 {
+		blockersMap, err := service.taskDependenciesRepository.FindBlockersIDs(ctx, frontier)
+		if err != nil {
+			return false, err
+		}
+
+		frontier = nil
+		for _, blockersIDs := range blockersMap {
+			for _, blockerID := range blockersIDs {
+				if blockerID == toTaskID {
+					return true, nil
+				}
+				if !visited[blockerID] {
+					visited[blockerID] = true
+					frontier = append(frontier, blockerID)
+				}
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// Complete (ctx, taskID) -> marks the task as done and returns tasks which became unblocked by it
+func (service *Service) Complete(ctx context.Context, taskID int64) ([]models.Task, error) {
+	service.logger.Info("Services -> Tasks -> Complete")
+
+	//Задача читается с блокировкой, чтобы одновременные Complete не завершили её дважды
+	var completed bool
+	err := service.unitOfWork.Do(ctx, func(repositories repositories_types.TxRepositories) error {
+		task, err := repositories.Tasks.FindByIDForUpdate(ctx, taskID)
+		if err != nil {
+			return errors.Wrap(err, "repositories.Tasks.FindByIDForUpdate(taskID)")
+		}
+
+		if task.Done {
+			return nil
+		}
+
+		task.Done = true
+		err = repositories.Tasks.Update(ctx, task)
+		if err != nil {
+			return errors.Wrap(err, "repositories.Tasks.Update(task)")
+		}
+		completed = true
+
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, repositories_types.ErrNotFound) {
+			err = services_types.ErrNotFound
+		}
+		service.logger.Errorw(
+			"Services -> Tasks -> Complete -> service.unitOfWork.Do(ctx, fn)",
+			"error", err.Error(), "taskID", taskID,
+		)
+		return []models.Task{}, err
+	}
+
+	if !completed {
+		return []models.Task{}, nil
+	}
+
+	service.publishByID(ctx, services_types.EventTaskCompleted, taskID)
+
+	dependentTasksIDs, err := service.taskDependenciesRepository.FindDependentTasksIDs(ctx, taskID)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Tasks -> Complete -> service.taskDependenciesRepository.FindDependentTasksIDs(ctx, taskID)",
+			"error", err.Error(), "taskID", taskID,
+		)
+		return []models.Task{}, err
+	}
+
+	activeBlockersMap, err := service.taskDependenciesRepository.FindActiveBlockersIDs(ctx, dependentTasksIDs)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Tasks -> Complete -> service.taskDependenciesRepository.FindActiveBlockersIDs(ctx, dependentTasksIDs)",
+			"error", err.Error(), "dependentTasksIDs", dependentTasksIDs,
+		)
+		return []models.Task{}, err
+	}
+
+	var unblockedTasks []models.Task
+	for _, dependentTaskID := range dependentTasksIDs {
+		if len(activeBlockersMap[dependentTaskID]) > 0 {
+			continue
+		}
+
+		dependentTask, err := service.FindByID(ctx, dependentTaskID)
+		if err != nil {
+			return []models.Task{}, err
+		}
+		if !dependentTask.Done {
+			unblockedTasks = append(unblockedTasks, dependentTask)
+		}
+	}
+
+	return unblockedTasks, nil
+}
+
+func (service *Service) setBlockers(ctx context.Context, tasks []models.Task) error {
+	var tasksIDs []int64
+	for _, task := range tasks {
+		tasksIDs = append(tasksIDs, task.ID)
+	}
+
+	activeBlockersMap, err := service.taskDependenciesRepository.FindActiveBlockersIDs(ctx, tasksIDs)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Tasks -> setBlockers -> service.taskDependenciesRepository.FindActiveBlockersIDs(ctx, tasksIDs)",
+			"error", err.Error(), "tasksIDs", tasksIDs,
+		)
+		return err
+	}
+
+	for i, task := range tasks {
+		tasks[i].BlockedBy = activeBlockersMap[task.ID]
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package tasks
+
+import (
+	"context"
+	"tg_todo_bot/src/models"
+	services_types "tg_todo_bot/src/services/types"
+	"time"
+)
+
+// publish (ctx, eventType, task) -> the change is already saved, so a failed publish is only logged
+func (service *Service) publish(ctx context.Context, eventType string, task models.Task) {
+	if service.eventsPublisher == nil {
+		return
+	}
+
+	event := services_types.Event{
+		Type:       eventType,
+		UserID:     task.UserID,
+		Task:       task,
+		OccurredAt: time.Now(),
+	}
+	err := service.eventsPublisher.Publish(ctx, event)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Tasks -> publish -> service.eventsPublisher.Publish(ctx, event)",
+			"error", err.Error(), "event", event,
+		)
+	}
+}
+
+// publishByID (ctx, eventType, taskID) -> publish with the task read again, with its tags, blockers and notification
+func (service *Service) publishByID(ctx context.Context, eventType string, taskID int64) {
+	if service.eventsPublisher == nil {
+		return
+	}
+
+	task, err := service.FindByID(ctx, taskID)
+	if err != nil {
+		return
+	}
+
+	service.publish(ctx, eventType, task)
+}
@@ -0,0 +1,49 @@
+package tasks
+
+import (
+	"context"
+	"tg_todo_bot/src/models"
+)
+
+// exportPageSize -> tasks are loaded by pages, so accounts of any size are exported in constant memory
+const exportPageSize = 200
+
+// ForEachPageForUser (ctx, userID, handle) -> calls handle for all active and completed tasks of the user with relations,
+// page by page in the order of creation. An error of handle stops the iteration and is returned as is
+func (service *Service) ForEachPageForUser(ctx context.Context, userID int64, handle func(tasks []models.Task) error) error {
+	service.logger.Info("Services -> Tasks -> ForEachPageForUser")
+
+	var afterTaskID int64
+	for {
+		tasks, err := service.tasksRepository.GetPageForUser(ctx, userID, afterTaskID, exportPageSize)
+		if err != nil {
+			service.logger.Errorw(
+				"Services -> Tasks -> ForEachPageForUser -> service.tasksRepository.GetPageForUser(ctx, userID, afterTaskID, limit)",
+				"error", err.Error(), "userID", userID, "afterTaskID", afterTaskID,
+			)
+			return err
+		}
+		if len(tasks) == 0 {
+			return nil
+		}
+
+		err = service.setRelations(ctx, tasks)
+		if err != nil {
+			service.logger.Errorw(
+				"Services -> Tasks -> ForEachPageForUser -> service.setRelations(ctx, tasks)",
+				"error", err.Error(), "userID", userID, "afterTaskID", afterTaskID,
+			)
+			return err
+		}
+
+		err = handle(tasks)
+		if err != nil {
+			return err
+		}
+
+		if len(tasks) < exportPageSize {
+			return nil
+		}
+		afterTaskID = tasks[len(tasks)-1].ID
+	}
+}
@@ -0,0 +1,280 @@
+// Package filter parses the tasks filter query language, e.g. "due:<7d tag:work prio:1 -done sort:created".
+//
+//	done / -done / done:any     only completed / only active (default) / both
+//	due:<7d due:>=2w due:<=12h  relative to now, units: h, d, w
+//	due:20.10.2026 due:<20.10.2026
+//	due:today due:tomorrow due:overdue due:none due:any
+//	tag:work -tag:home #work    the task has (hasn't) the tag
+//	prio:1 prio:<=2 !1          priority, 1 is the highest
+//	sort:due sort:-created      sort:title, sort:prio, "-" for descending order
+//
+// Other words are used as the full-text search query.
+package filter
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"strconv"
+	"strings"
+	repositories_types "tg_todo_bot/src/repositories/types"
+	"tg_todo_bot/src/services/tasks/types"
+	"time"
+)
+
+const dateLayout = "02.01.2006"
+
+var sortFields = map[string]repositories_types.TasksSortField{
+	"due":     repositories_types.TasksSortByDue,
+	"created": repositories_types.TasksSortByCreated,
+	"title":   repositories_types.TasksSortByTitle,
+	"prio":    repositories_types.TasksSortByPriority,
+}
+
+// Parse (query, now) -> filter for not completed tasks if the query doesn't say otherwise
+func Parse(query string, now time.Time) (repositories_types.TasksFilter, error) {
+	done := false
+	filter := repositories_types.TasksFilter{Done: &done}
+
+	var textWords []string
+	for _, token := range strings.Fields(query) {
+		key, value, hasValue := strings.Cut(token, ":")
+		lowerKey := strings.ToLower(key)
+
+		var err error
+		switch {
+		case lowerKey == "done" && !hasValue:
+			done := true
+			filter.Done = &done
+		case lowerKey == "-done" && !hasValue:
+			done := false
+			filter.Done = &done
+		case lowerKey == "done":
+			if strings.ToLower(value) != "any" {
+				err = syntaxError(token, "expected done:any")
+			}
+			filter.Done = nil
+		case lowerKey == "due":
+			err = parseDue(&filter, value, now)
+		case lowerKey == "tag":
+			err = appendTag(&filter.Tags, value)
+		case lowerKey == "-tag":
+			err = appendTag(&filter.ExcludedTags, value)
+		case strings.HasPrefix(token, "#") && !hasValue:
+			err = appendTag(&filter.Tags, token[1:])
+		case lowerKey == "prio":
+			err = parsePriority(&filter, value)
+		case strings.HasPrefix(token, "!") && !hasValue:
+			err = parsePriority(&filter, token[1:])
+		case lowerKey == "sort":
+			err = parseSort(&filter, value)
+		case hasValue && isKey(key):
+			err = syntaxError(token, "unknown filter")
+		default:
+			textWords = append(textWords, token)
+		}
+
+		if err != nil {
+			var syntaxErr *types.FilterSyntaxError
+			if errors.As(err, &syntaxErr) && syntaxErr.Token == "" {
+				syntaxErr.Token = token
+			}
+			return repositories_types.TasksFilter{}, err
+		}
+	}
+
+	filter.Text = strings.Join(textWords, " ")
+
+	return filter, nil
+}
+
+func syntaxError(token, reason string) error {
+	return &types.FilterSyntaxError{Token: token, Reason: reason}
+}
+
+// isKey -> "key" and "-key" look like filters, "10:30" or "http://" don't
+func isKey(key string) bool {
+	key = strings.TrimPrefix(key, "-")
+	if key == "" {
+		return false
+	}
+
+	for _, r := range key {
+		if r < 'a' || r > 'z' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func appendTag(tags *[]string, tag string) error {
+	tag = NormalizeTag(tag)
+	if tag == "" {
+		return syntaxError("", "empty tag")
+	}
+
+	*tags = append(*tags, tag)
+
+	return nil
+}
+
+// NormalizeTag ("#Work") -> "work"
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+}
+
+// splitOperator ("<=7d") -> ("<=", "7d")
+func splitOperator(value string) (string, string) {
+	for _, operator := range []string{"<=", ">=", "<", ">", "="} {
+		if strings.HasPrefix(value, operator) {
+			return operator, value[len(operator):]
+		}
+	}
+
+	return "", value
+}
+
+func parseDue(filter *repositories_types.TasksFilter, value string, now time.Time) error {
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	switch strings.ToLower(value) {
+	case "none":
+		filter.WithoutDue = true
+		return nil
+	case "any":
+		filter.WithDue = true
+		return nil
+	case "today":
+		setDueRange(filter, startOfDay, endOfDay(startOfDay))
+		return nil
+	case "tomorrow":
+		tomorrow := startOfDay.AddDate(0, 0, 1)
+		setDueRange(filter, tomorrow, endOfDay(tomorrow))
+		return nil
+	case "overdue":
+		dueTo := now
+		filter.DueTo = &dueTo
+		return nil
+	}
+
+	operator, operand := splitOperator(value)
+
+	if date, err := time.ParseInLocation(dateLayout, operand, now.Location()); err == nil {
+		switch operator {
+		case "<":
+			dueTo := date.Add(-time.Nanosecond)
+			filter.DueTo = &dueTo
+		case "<=":
+			dueTo := endOfDay(date)
+			filter.DueTo = &dueTo
+		case ">":
+			dueFrom := endOfDay(date).Add(time.Nanosecond)
+			filter.DueFrom = &dueFrom
+		case ">=":
+			filter.DueFrom = &date
+		default:
+			setDueRange(filter, date, endOfDay(date))
+		}
+		return nil
+	}
+
+	offset, err := parseRelativeDuration(operand)
+	if err != nil {
+		return syntaxError("", err.Error())
+	}
+
+	moment := now.Add(offset)
+	switch operator {
+	case ">", ">=":
+		filter.DueFrom = &moment
+	default:
+		filter.DueTo = &moment
+	}
+
+	return nil
+}
+
+func setDueRange(filter *repositories_types.TasksFilter, from, to time.Time) {
+	filter.DueFrom = &from
+	filter.DueTo = &to
+}
+
+func endOfDay(startOfDay time.Time) time.Time {
+	return startOfDay.AddDate(0, 0, 1).Add(-time.Nanosecond)
+}
+
+// parseRelativeDuration ("7d") -> 7 * 24h, units: h, d, w
+func parseRelativeDuration(value string) (time.Duration, error) {
+	if len(value) < 2 {
+		return 0, fmt.Errorf("expected duration like 7d")
+	}
+
+	units := map[byte]time.Duration{
+		'h': time.Hour,
+		'd': 24 * time.Hour,
+		'w': 7 * 24 * time.Hour,
+	}
+	unit, exist := units[value[len(value)-1]]
+	if !exist {
+		return 0, fmt.Errorf("unknown duration unit, expected h, d or w")
+	}
+
+	amount, err := strconv.Atoi(value[:len(value)-1])
+	if err != nil {
+		return 0, fmt.Errorf("expected duration like 7d")
+	}
+
+	return time.Duration(amount) * unit, nil
+}
+
+func parsePriority(filter *repositories_types.TasksFilter, value string) error {
+	operator, operand := splitOperator(value)
+
+	if strings.ToLower(operand) == "none" && operator == "" {
+		none := 0
+		filter.PriorityFrom = &none
+		filter.PriorityTo = &none
+		return nil
+	}
+
+	priority, err := strconv.Atoi(operand)
+	if err != nil || priority < 1 {
+		return syntaxError("", "expected priority like 1")
+	}
+
+	//Задачи без приоритета (0) не попадают в диапазон
+	highest := 1
+	switch operator {
+	case "<":
+		to := priority - 1
+		filter.PriorityFrom = &highest
+		filter.PriorityTo = &to
+	case "<=":
+		filter.PriorityFrom = &highest
+		filter.PriorityTo = &priority
+	case ">":
+		from := priority + 1
+		filter.PriorityFrom = &from
+	case ">=":
+		filter.PriorityFrom = &priority
+	default:
+		filter.PriorityFrom = &priority
+		filter.PriorityTo = &priority
+	}
+
+	return nil
+}
+
+func parseSort(filter *repositories_types.TasksFilter, value string) error {
+	field := strings.ToLower(strings.TrimPrefix(value, "-"))
+
+	sortBy, exist := sortFields[field]
+	if !exist {
+		return syntaxError("", "unknown sort field, expected due, created, title or prio")
+	}
+
+	filter.SortBy = sortBy
+	filter.SortDesc = strings.HasPrefix(value, "-")
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package filter
+
+import (
+	"github.com/pkg/errors"
+	"reflect"
+	"testing"
+	repositories_types "tg_todo_bot/src/repositories/types"
+	"tg_todo_bot/src/services/tasks/types"
+	"time"
+)
+
+func TestParseFilter(t *testing.T) {
+	now := time.Date(2026, 10, 19, 15, 30, 0, 0, time.UTC)
+	weekLater := now.Add(7 * 24 * time.Hour)
+	startOfToday := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	endOfToday := startOfToday.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	startOfDate := time.Date(2026, 10, 25, 0, 0, 0, 0, time.UTC)
+	active, completed := false, true
+	one, two := 1, 2
+
+	testCases := []struct {
+		query    string
+		expected repositories_types.TasksFilter
+	}{
+		{
+			query:    "",
+			expected: repositories_types.TasksFilter{Done: &active},
+		},
+		{
+			query: "due:<7d tag:work prio:1 -done sort:created",
+			expected: repositories_types.TasksFilter{
+				Done:         &active,
+				DueTo:        &weekLater,
+				Tags:         []string{"work"},
+				PriorityFrom: &one,
+				PriorityTo:   &one,
+				SortBy:       repositories_types.TasksSortByCreated,
+			},
+		},
+		{
+			query: "done due:today #Home -tag:shop sort:-prio",
+			expected: repositories_types.TasksFilter{
+				Done:         &completed,
+				DueFrom:      &startOfToday,
+				DueTo:        &endOfToday,
+				Tags:         []string{"home"},
+				ExcludedTags: []string{"shop"},
+				SortBy:       repositories_types.TasksSortByPriority,
+				SortDesc:     true,
+			},
+		},
+		{
+			query: "done:any due:>=25.10.2026 prio:<=2 купить молоко",
+			expected: repositories_types.TasksFilter{
+				DueFrom:      &startOfDate,
+				PriorityFrom: &one,
+				PriorityTo:   &two,
+				Text:         "купить молоко",
+			},
+		},
+		{
+			query: "due:none !2 в 10:30",
+			expected: repositories_types.TasksFilter{
+				Done:         &active,
+				WithoutDue:   true,
+				PriorityFrom: &two,
+				PriorityTo:   &two,
+				Text:         "в 10:30",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		filter, err := Parse(testCase.query, now)
+		if err != nil {
+			t.Fatalf("query '%s': %s", testCase.query, err)
+		}
+
+		if !reflect.DeepEqual(filter, testCase.expected) {
+			t.Fatalf("query '%s': got %+v, expected %+v", testCase.query, filter, testCase.expected)
+		}
+	}
+}
+
+func TestParseFilterErrors(t *testing.T) {
+	testCases := map[string]string{
+		"due:<7y":        "due:<7y",
+		"prio:high":      "prio:high",
+		"sort:color tag": "sort:color",
+		"tags:work":      "tags:work",
+		"tag:":           "tag:",
+		"done:maybe":     "done:maybe",
+	}
+
+	for query, expectedToken := range testCases {
+		_, err := Parse(query, time.Now())
+
+		var syntaxErr *types.FilterSyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Fatalf("query '%s': expected syntax error, got %v", query, err)
+		}
+		if syntaxErr.Token != expectedToken {
+			t.Fatalf("query '%s': expected token '%s', got '%s'", query, expectedToken, syntaxErr.Token)
+		}
+	}
+}
@@ -1,21 +1,57 @@
 package tasks
 
 import (
+	"context"
 	"tg_todo_bot/src/models"
+	repositories_types "tg_todo_bot/src/repositories/types"
+	services_types "tg_todo_bot/src/services/types"
 	"time"
 )
 
 type TasksRepositoryI interface {
-	Create(task models.Task) (models.Task, error)
-	SearchActiveByDatetimeForUser(from, to *time.Time, userID int64) ([]models.Task, error)
-	GetAllActiveForUser(userID int64) ([]models.Task, error)
-	Update(model models.Task) error
-	DeleteByID(ID int64) error
-	DeleteCompleted() error
-	FindByID(ID int64) (models.Task, error)
-	GetActiveTasksWithoutDatetimeForUser(userID int64) ([]models.Task, error)
+	Create(ctx context.Context, task models.Task) (models.Task, error)
+	CreateMany(ctx context.Context, tasks []models.Task) ([]models.Task, error)
+	SearchActiveByDatetimeForUser(ctx context.Context, from, to *time.Time, userID int64) ([]models.Task, error)
+	GetAllActiveForUser(ctx context.Context, userID int64) ([]models.Task, error)
+	GetPageForUser(ctx context.Context, userID, afterTaskID int64, limit uint) ([]models.Task, error)
+	CountForUser(ctx context.Context, userID int64) (int64, int64, error)
+	Update(ctx context.Context, model models.Task) error
+	DeleteByID(ctx context.Context, ID int64) error
+	DeleteCompleted(ctx context.Context) error
+	FindByID(ctx context.Context, ID int64) (models.Task, error)
+	GetActiveTasksWithoutDatetimeForUser(ctx context.Context, userID int64) ([]models.Task, error)
+	Search(ctx context.Context, userID int64, query string, filters repositories_types.TasksSearchFilters) ([]models.Task, error)
+	GetActiveForUserPage(ctx context.Context, userID int64, page repositories_types.TasksPageParams) ([]models.Task, error)
+	Filter(ctx context.Context, userID int64, filter repositories_types.TasksFilter) ([]models.Task, error)
+	GetOverdue(ctx context.Context, now time.Time, filters repositories_types.TasksOverdueFilters) ([]models.Task, error)
 }
 
 type NotificationsRepositoryI interface {
-	FindByTasksIDs(tasksIDs []int64) (map[int64]models.Notification, error)
+	FindByTasksIDs(ctx context.Context, tasksIDs []int64) (map[int64]models.Notification, error)
+}
+
+type TaskDependenciesRepositoryI interface {
+	Create(ctx context.Context, dependency models.TaskDependency) (models.TaskDependency, error)
+	Delete(ctx context.Context, taskID, blockedByTaskID int64) error
+	FindBlockersIDs(ctx context.Context, tasksIDs []int64) (map[int64][]int64, error)
+	FindActiveBlockersIDs(ctx context.Context, tasksIDs []int64) (map[int64][]int64, error)
+	FindDependentTasksIDs(ctx context.Context, blockedByTaskID int64) ([]int64, error)
+}
+
+type TaskTagsRepositoryI interface {
+	SetForTask(ctx context.Context, taskID int64, tags []string) error
+	FindByTasksIDs(ctx context.Context, tasksIDs []int64) (map[int64][]string, error)
+}
+
+// UnitOfWorkI -> fn gets repositories bound to one transaction, nothing of it is saved if fn returns an error
+type UnitOfWorkI interface {
+	Do(ctx context.Context, fn func(repositories repositories_types.TxRepositories) error) error
+}
+
+type EventsPublisherI interface {
+	Publish(ctx context.Context, event services_types.Event) error
+}
+
+type RateLimiterI interface {
+	Take(ctx context.Context, userID int64, bucket string) error
 }
@@ -0,0 +1,33 @@
+package tasks
+
+import (
+	"context"
+	"tg_todo_bot/src/models"
+	repositories_types "tg_todo_bot/src/repositories/types"
+	"time"
+)
+
+// GetOverdueForUser (ctx, userID, now) -> not completed tasks with the deadline before now, the most overdue first
+func (service *Service) GetOverdueForUser(ctx context.Context, userID int64, now time.Time) ([]models.Task, error) {
+	service.logger.Info("Services -> Tasks -> GetOverdueForUser")
+
+	tasks, err := service.tasksRepository.GetOverdue(ctx, now, repositories_types.TasksOverdueFilters{UserID: userID})
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Tasks -> GetOverdueForUser -> service.tasksRepository.GetOverdue(ctx, now, filters)",
+			"error", err.Error(), "userID", userID,
+		)
+		return []models.Task{}, err
+	}
+
+	err = service.setRelations(ctx, tasks)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Tasks -> GetOverdueForUser -> service.setRelations(ctx, tasks)",
+			"error", err.Error(), "tasks", tasks,
+		)
+		return []models.Task{}, err
+	}
+
+	return tasks, nil
+}
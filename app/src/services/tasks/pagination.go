@@ -0,0 +1,105 @@
+package tasks
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	repositories_types "tg_todo_bot/src/repositories/types"
+	"tg_todo_bot/src/services/tasks/types"
+	services_types "tg_todo_bot/src/services/types"
+)
+
+// GetActiveForUserPage (ctx, params) -> page of active tasks ordered by datetime, title and id.
+// If the cursor task doesn't exist anymore the first page is returned.
+func (service *Service) GetActiveForUserPage(ctx context.Context, params types.PageParams) (types.TasksPage, error) {
+	service.logger.Info("Services -> Tasks -> GetActiveForUserPage")
+
+	err := validatePageParams(params)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Tasks -> GetActiveForUserPage -> validatePageParams(params)",
+			"error", err.Error(), "params", params,
+		)
+		return types.TasksPage{}, services_types.InvalidParams(err)
+	}
+
+	pageParams := repositories_types.TasksPageParams{Limit: params.Limit + 1}
+	backward := params.BeforeTaskID != 0
+
+	cursorTaskID := params.AfterTaskID
+	if backward {
+		cursorTaskID = params.BeforeTaskID
+	}
+	if cursorTaskID != 0 {
+		cursor, err := service.getCursor(ctx, params.UserID, cursorTaskID)
+		if err != nil {
+			return types.TasksPage{}, err
+		}
+
+		if cursor == nil {
+			backward = false
+		} else if backward {
+			pageParams.Before = cursor
+		} else {
+			pageParams.After = cursor
+		}
+	}
+
+	tasks, err := service.tasksRepository.GetActiveForUserPage(ctx, params.UserID, pageParams)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Tasks -> GetActiveForUserPage -> service.tasksRepository.GetActiveForUserPage(ctx, userID, pageParams)",
+			"error", err.Error(), "params", params, "pageParams", pageParams,
+		)
+		return types.TasksPage{}, err
+	}
+
+	page := types.TasksPage{}
+	hasMore := uint(len(tasks)) > params.Limit
+	if backward {
+		if hasMore {
+			tasks = tasks[1:]
+		}
+		page.HasPrev = hasMore
+		page.HasNext = true
+	} else {
+		if hasMore {
+			tasks = tasks[:params.Limit]
+		}
+		page.HasPrev = pageParams.After != nil
+		page.HasNext = hasMore
+	}
+
+	err = service.setRelations(ctx, tasks)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Tasks -> GetActiveForUserPage -> service.setRelations(ctx, tasks)",
+			"error", err.Error(), "tasks", tasks,
+		)
+		return types.TasksPage{}, err
+	}
+
+	page.Tasks = tasks
+
+	return page, nil
+}
+
+// getCursor (ctx, userID, taskID) -> nil if the task was deleted or belongs to another user
+func (service *Service) getCursor(ctx context.Context, userID, taskID int64) (*repositories_types.TasksCursor, error) {
+	task, err := service.FindByID(ctx, taskID)
+	if err != nil {
+		if errors.Is(err, services_types.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if task.UserID != userID {
+		return nil, nil
+	}
+
+	return &repositories_types.TasksCursor{
+		Datetime: task.Datetime,
+		Title:    task.Title,
+		ID:       task.ID,
+	}, nil
+}
@@ -1,34 +1,55 @@
 package tasks
 
 import (
+	"context"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
+	"tg_todo_bot/src/ical"
 	"tg_todo_bot/src/models"
 	repositories_types "tg_todo_bot/src/repositories/types"
+	ratelimits_types "tg_todo_bot/src/services/ratelimits/types"
+	"tg_todo_bot/src/services/tasks/filter"
 	"tg_todo_bot/src/services/tasks/types"
 	services_types "tg_todo_bot/src/services/types"
 	"time"
 )
 
 type Service struct {
-	logger                  *zap.SugaredLogger
-	tasksRepository         TasksRepositoryI
-	notificationsRepository NotificationsRepositoryI
+	logger                     *zap.SugaredLogger
+	tasksRepository            TasksRepositoryI
+	notificationsRepository    NotificationsRepositoryI
+	taskDependenciesRepository TaskDependenciesRepositoryI
+	taskTagsRepository         TaskTagsRepositoryI
+	unitOfWork                 UnitOfWorkI
+	eventsPublisher            EventsPublisherI
+	rateLimiter                RateLimiterI
 }
 
 func NewService(
 	logger *zap.SugaredLogger,
 	tasksRepository TasksRepositoryI,
 	notificationsRepository NotificationsRepositoryI,
+	taskDependenciesRepository TaskDependenciesRepositoryI,
+	taskTagsRepository TaskTagsRepositoryI,
+	unitOfWork UnitOfWorkI,
+	eventsPublisher EventsPublisherI,
+	rateLimiter RateLimiterI,
 ) *Service {
 	return &Service{
-		logger:                  logger,
-		tasksRepository:         tasksRepository,
-		notificationsRepository: notificationsRepository,
+		logger:                     logger,
+		tasksRepository:            tasksRepository,
+		notificationsRepository:    notificationsRepository,
+		taskDependenciesRepository: taskDependenciesRepository,
+		taskTagsRepository:         taskTagsRepository,
+		unitOfWork:                 unitOfWork,
+		eventsPublisher:            eventsPublisher,
+		rateLimiter:                rateLimiter,
 	}
 }
 
-func (service *Service) Create(params types.CreateParams) error {
+// Create (ctx, params) -> *services_types.RateLimitedError if the user creates tasks too often.
+// CreateMany isn't limited: an import is one command
+func (service *Service) Create(ctx context.Context, params types.CreateParams) (models.Task, error) {
 	service.logger.Info("Services -> Tasks -> Create")
 
 	err := validateCreateParams(params)
@@ -37,7 +58,12 @@ func (service *Service) Create(params types.CreateParams) error {
 			"Services -> Tasks -> Create -> validateCreateParams(params)",
 			"error", err.Error(), "params", params,
 		)
-		return err
+		return models.Task{}, services_types.InvalidParams(err)
+	}
+
+	err = service.rateLimiter.Take(ctx, params.UserID, ratelimits_types.BucketTasks)
+	if err != nil {
+		return models.Task{}, err
 	}
 
 	taskModel := models.Task{
@@ -45,20 +71,67 @@ func (service *Service) Create(params types.CreateParams) error {
 		Description: params.Description,
 		Datetime:    params.Datetime,
 		Done:        false,
+		Priority:    params.Priority,
 		UserID:      params.UserID,
 	}
-	taskModel, err = service.tasksRepository.Create(taskModel)
+	err = service.unitOfWork.Do(ctx, func(repositories repositories_types.TxRepositories) error {
+		taskModel, err = repositories.Tasks.Create(ctx, taskModel)
+		if err != nil {
+			return errors.Wrap(err, "repositories.Tasks.Create(taskModel)")
+		}
+
+		if len(params.Tags) > 0 {
+			taskModel.Tags = normalizeTags(params.Tags)
+			err = repositories.TaskTags.SetForTask(ctx, taskModel.ID, taskModel.Tags)
+			if err != nil {
+				return errors.Wrap(err, "repositories.TaskTags.SetForTask(taskID, tags)")
+			}
+		}
+
+		if params.Notification != nil {
+			notification, err := repositories.Notifications.Create(ctx, models.Notification{
+				TaskID:         taskModel.ID,
+				NotifyAt:       params.Notification.NotifyAt,
+				RepeatInterval: params.Notification.RepeatInterval,
+			})
+			if err != nil {
+				return errors.Wrap(err, "repositories.Notifications.Create(notification)")
+			}
+			taskModel.Notification = &notification
+		}
+
+		if params.CalDAVObject != nil {
+			uid := params.CalDAVObject.UID
+			if uid == "" {
+				uid = ical.TaskUID(taskModel.ID)
+			}
+			err = repositories.CalDAVObjects.Save(ctx, models.CalDAVObject{
+				TaskID: taskModel.ID,
+				UserID: taskModel.UserID,
+				Name:   params.CalDAVObject.Name,
+				UID:    uid,
+			})
+			if err != nil {
+				return errors.Wrap(err, "repositories.CalDAVObjects.Save(object)")
+			}
+		}
+
+		return nil
+	})
 	if err != nil {
 		service.logger.Errorw(
-			"Services -> Tasks -> Create -> service.tasksRepository.Create(taskModel)",
-			"error", err.Error(), "params", params, "taskModel", taskModel,
+			"Services -> Tasks -> Create -> service.unitOfWork.Do(ctx, fn)",
+			"error", err.Error(), "params", params,
 		)
-		return err
+		return models.Task{}, err
 	}
 
-	return nil
+	service.publish(ctx, services_types.EventTaskCreated, taskModel)
+
+	return taskModel, nil
 }
-func (service *Service) Update(params types.UpdateParams) error {
+
+func (service *Service) Update(ctx context.Context, params types.UpdateParams) error {
 	service.logger.Info("Services -> Tasks -> Update")
 
 	err := validateUpdateParams(params)
@@ -67,49 +140,88 @@ func (service *Service) Update(params types.UpdateParams) error {
 			"Services -> Tasks -> Update -> validateUpdateParams(params)",
 			"error", err.Error(), "params", params,
 		)
-		return err
+		return services_types.InvalidParams(err)
 	}
 
-	task, err := service.tasksRepository.FindByID(params.TaskID)
+	//Задача читается и сохраняется в одной транзакции, чтобы не затереть изменения, сделанные между ними
+	err = service.unitOfWork.Do(ctx, func(repositories repositories_types.TxRepositories) error {
+		task, err := repositories.Tasks.FindByIDForUpdate(ctx, params.TaskID)
+		if err != nil {
+			return errors.Wrap(err, "repositories.Tasks.FindByIDForUpdate(taskID)")
+		}
+
+		if params.UserID.IsSet && params.UserID.Value != task.UserID {
+			_, err = repositories.Users.FindByID(ctx, params.UserID.Value)
+			if errors.Is(err, repositories_types.ErrNotFound) {
+				return services_types.InvalidParams(errors.New("the new owner of the task doesn't exist"))
+			}
+			if err != nil {
+				return errors.Wrap(err, "repositories.Users.FindByID(userID)")
+			}
+			task.UserID = params.UserID.Value
+		}
+		if params.Title.IsSet {
+			task.Title = params.Title.Value
+		}
+		if params.Description.IsSet {
+			task.Description = params.Description.Value
+		}
+		if params.Datetime.IsSet {
+			task.Datetime = params.Datetime.Value
+		}
+		if params.Priority.IsSet {
+			task.Priority = params.Priority.Value
+		}
+		task.Done = params.Done
+
+		err = repositories.Tasks.Update(ctx, task)
+		if err != nil {
+			return errors.Wrap(err, "repositories.Tasks.Update(task)")
+		}
+
+		if params.Tags.IsSet {
+			err = repositories.TaskTags.SetForTask(ctx, task.ID, normalizeTags(params.Tags.Value))
+			if err != nil {
+				return errors.Wrap(err, "repositories.TaskTags.SetForTask(taskID, tags)")
+			}
+		}
+
+		if params.CalDAVObject != nil {
+			uid := params.CalDAVObject.UID
+			if uid == "" {
+				uid = ical.TaskUID(task.ID)
+			}
+			err = repositories.CalDAVObjects.Save(ctx, models.CalDAVObject{
+				TaskID: task.ID,
+				UserID: task.UserID,
+				Name:   params.CalDAVObject.Name,
+				UID:    uid,
+			})
+			if err != nil {
+				return errors.Wrap(err, "repositories.CalDAVObjects.Save(object)")
+			}
+		}
+
+		return nil
+	})
 	if err != nil {
 		if errors.Is(err, repositories_types.ErrNotFound) {
 			err = services_types.ErrNotFound
 		}
 		service.logger.Errorw(
-			"Services -> Tasks -> Update -> service.tasksRepository.FindByID(taskID)",
-			"error", err.Error(), "params", params, "taskID", params.TaskID,
+			"Services -> Tasks -> Update -> service.unitOfWork.Do(ctx, fn)",
+			"error", err.Error(), "params", params,
 		)
 		return err
 	}
 
-	if params.UserID.IsSet {
-		task.UserID = params.UserID.Value
-	}
-	if params.Title.IsSet {
-		task.Title = params.Title.Value
-	}
-	if params.Description.IsSet {
-		task.Description = params.Description.Value
-	}
-	if params.Datetime.IsSet {
-		task.Datetime = params.Datetime.Value
-	}
-	task.Done = params.Done
-
-	err = service.tasksRepository.Update(task)
-	if err != nil {
-		service.logger.Errorw(
-			"Services -> Tasks -> Update -> service.tasksRepository.Update(task)",
-			"error", err.Error(), "task", task,
-		)
-		return err
-	}
+	service.publishByID(ctx, services_types.EventTaskUpdated, params.TaskID)
 
 	return nil
 }
 
-//SearchByDateForUser (params) -> return map[DateWithoutTime][]models.Task
-func (service *Service) SearchByDateForUser(params types.SearchByDateForUserParams) (map[time.Time][]models.Task, error) {
+//SearchByDateForUser (ctx, params) -> return map[DateWithoutTime][]models.Task
+func (service *Service) SearchByDateForUser(ctx context.Context, params types.SearchByDateForUserParams) (map[time.Time][]models.Task, error) {
 	service.logger.Info("Services -> Tasks -> SearchByDateForUser")
 
 	err := validateSearchByDateForUserParams(params)
@@ -118,28 +230,23 @@ func (service *Service) SearchByDateForUser(params types.SearchByDateForUserPara
 			"Services -> Tasks -> SearchByDateForUser -> validateSearchByDateForUserParams(params)",
 			"error", err.Error(), "params", params,
 		)
-		return map[time.Time][]models.Task{}, err
+		return map[time.Time][]models.Task{}, services_types.InvalidParams(err)
 	}
 
-	tasks, err := service.tasksRepository.SearchActiveByDatetimeForUser(params.From, params.To, params.UserID)
+	tasks, err := service.tasksRepository.SearchActiveByDatetimeForUser(ctx, params.From, params.To, params.UserID)
 	if err != nil {
 		service.logger.Errorw(
-			"Services -> Tasks -> SearchByDateForUser -> service.tasksRepository.SearchActiveByDatetimeForUser(from, to, userID)",
+			"Services -> Tasks -> SearchByDateForUser -> service.tasksRepository.SearchActiveByDatetimeForUser(ctx, from, to, userID)",
 			"error", err.Error(), "from", params.From, "to", params.To, "userID", params.UserID,
 		)
 		return map[time.Time][]models.Task{}, err
 	}
 
-	var tasksIDs []int64
-	for _, task := range tasks {
-		tasksIDs = append(tasksIDs, task.ID)
-	}
-
-	tasksNotificationsMap, err := service.notificationsRepository.FindByTasksIDs(tasksIDs)
+	err = service.setRelations(ctx, tasks)
 	if err != nil {
 		service.logger.Errorw(
-			"Services -> Tasks -> SearchByDateForUser -> service.notificationsRepository.FindByTasksIDs(tasksIDs)",
-			"error", err.Error(), "tasksIDs", tasksIDs,
+			"Services -> Tasks -> SearchByDateForUser -> service.setRelations(ctx, tasks)",
+			"error", err.Error(), "tasks", tasks,
 		)
 		return map[time.Time][]models.Task{}, err
 	}
@@ -147,39 +254,30 @@ func (service *Service) SearchByDateForUser(params types.SearchByDateForUserPara
 	dateTasksMap := map[time.Time][]models.Task{}
 
 	for _, task := range tasks {
-		notification, exist := tasksNotificationsMap[task.ID]
-		if exist {
-			task.Notification = &notification
-		}
-
 		y, m, d := task.Datetime.Date()
-		taskDate := time.Date(y, m, d, 0, 0, 0, 0, nil)
-		if tasksByDate, exist := dateTasksMap[taskDate]; exist {
-			tasksByDate = append(tasksByDate, task)
-		} else {
-			dateTasksMap[taskDate] = []models.Task{task}
-		}
+		taskDate := time.Date(y, m, d, 0, 0, 0, 0, task.Datetime.Location())
+		dateTasksMap[taskDate] = append(dateTasksMap[taskDate], task)
 	}
 
 	return dateTasksMap, nil
 }
 
-func (service *Service) GetAllActiveForUser(userID int64) ([]models.Task, error) {
+func (service *Service) GetAllActiveForUser(ctx context.Context, userID int64) ([]models.Task, error) {
 	service.logger.Info("Services -> Tasks -> GetAllActiveForUser")
 
-	tasks, err := service.tasksRepository.GetAllActiveForUser(userID)
+	tasks, err := service.tasksRepository.GetAllActiveForUser(ctx, userID)
 	if err != nil {
 		service.logger.Errorw(
-			"Services -> Tasks -> SearchByDateForUser -> service.tasksRepository.GetAllActiveForUser(userID)",
+			"Services -> Tasks -> SearchByDateForUser -> service.tasksRepository.GetAllActiveForUser(ctx, userID)",
 			"error", err.Error(), "userID", userID,
 		)
 		return []models.Task{}, err
 	}
 
-	err = service.setNotifications(tasks)
+	err = service.setRelations(ctx, tasks)
 	if err != nil {
 		service.logger.Errorw(
-			"Services -> Tasks -> SearchByDateForUser -> service.setNotifications(tasks)",
+			"Services -> Tasks -> GetAllActiveForUser -> service.setRelations(ctx, tasks)",
 			"error", err.Error(), "tasks", tasks,
 		)
 		return []models.Task{}, err
@@ -188,16 +286,57 @@ func (service *Service) GetAllActiveForUser(userID int64) ([]models.Task, error)
 	return tasks, nil
 }
 
-func (service *Service) setNotifications(tasks []models.Task) error {
+// setRelations (ctx, tasks) -> loads notifications, active blockers and tags of the tasks
+func (service *Service) setRelations(ctx context.Context, tasks []models.Task) error {
+	err := service.setNotifications(ctx, tasks)
+	if err != nil {
+		return err
+	}
+
+	err = service.setBlockers(ctx, tasks)
+	if err != nil {
+		return err
+	}
+
+	return service.setTags(ctx, tasks)
+}
+
+func (service *Service) setTags(ctx context.Context, tasks []models.Task) error {
+	var tasksIDs []int64
+	for _, task := range tasks {
+		tasksIDs = append(tasksIDs, task.ID)
+	}
+
+	tasksTagsMap, err := service.taskTagsRepository.FindByTasksIDs(ctx, tasksIDs)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Tasks -> setTags -> service.taskTagsRepository.FindByTasksIDs(ctx, tasksIDs)",
+			"error", err.Error(), "tasksIDs", tasksIDs,
+		)
+		return err
+	}
+
+	for i, task := range tasks {
+		tasks[i].Tags = tasksTagsMap[task.ID]
+	}
+
+	return nil
+}
+
+func (service *Service) setNotifications(ctx context.Context, tasks []models.Task) error {
 	var tasksIDs []int64
 	for _, task := range tasks {
 		tasksIDs = append(tasksIDs, task.ID)
 	}
 
-	tasksNotificationsMap, err := service.notificationsRepository.FindByTasksIDs(tasksIDs)
+	if len(tasksIDs) == 0 {
+		return nil
+	}
+
+	tasksNotificationsMap, err := service.notificationsRepository.FindByTasksIDs(ctx, tasksIDs)
 	if err != nil {
 		service.logger.Errorw(
-			"Services -> Tasks -> getAndSetNotifications -> service.notificationsRepository.FindByTasksIDs(tasksIDs)",
+			"Services -> Tasks -> getAndSetNotifications -> service.notificationsRepository.FindByTasksIDs(ctx, tasksIDs)",
 			"error", err.Error(), "tasksIDs", tasksIDs,
 		)
 		return err
@@ -213,22 +352,22 @@ func (service *Service) setNotifications(tasks []models.Task) error {
 	return nil
 }
 
-func (service *Service) GetActiveTasksWithoutDatetimeForUser(userID int64) ([]models.Task, error) {
+func (service *Service) GetActiveTasksWithoutDatetimeForUser(ctx context.Context, userID int64) ([]models.Task, error) {
 	service.logger.Info("Services -> Tasks -> GetAllActiveForUser")
 
-	tasks, err := service.tasksRepository.GetActiveTasksWithoutDatetimeForUser(userID)
+	tasks, err := service.tasksRepository.GetActiveTasksWithoutDatetimeForUser(ctx, userID)
 	if err != nil {
 		service.logger.Errorw(
-			"Services -> Tasks -> GetActiveTasksWithoutDatetimeForUser -> service.tasksRepository.GetActiveTasksWithoutDatetimeForUser(userID)",
+			"Services -> Tasks -> GetActiveTasksWithoutDatetimeForUser -> service.tasksRepository.GetActiveTasksWithoutDatetimeForUser(ctx, userID)",
 			"error", err.Error(), "userID", userID,
 		)
 		return []models.Task{}, err
 	}
 
-	err = service.setNotifications(tasks)
+	err = service.setRelations(ctx, tasks)
 	if err != nil {
 		service.logger.Errorw(
-			"Services -> Tasks -> GetActiveTasksWithoutDatetimeForUser -> service.setNotifications(tasks)",
+			"Services -> Tasks -> GetActiveTasksWithoutDatetimeForUser -> service.setRelations(ctx, tasks)",
 			"error", err.Error(), "tasks", tasks,
 		)
 		return []models.Task{}, err
@@ -237,28 +376,40 @@ func (service *Service) GetActiveTasksWithoutDatetimeForUser(userID int64) ([]mo
 	return tasks, nil
 }
 
-func (service *Service) DeleteByID(taskID int64) error {
+func (service *Service) DeleteByID(ctx context.Context, taskID int64) error {
 	service.logger.Info("Services -> Tasks -> DeleteByID")
 
-	err := service.tasksRepository.DeleteByID(taskID)
+	//Задача читается до удаления, чтобы отправить её в событии
+	task, err := service.FindByID(ctx, taskID)
+	if err != nil {
+		if errors.Is(err, services_types.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	err = service.tasksRepository.DeleteByID(ctx, taskID)
 	if err != nil {
 		service.logger.Errorw(
-			"Services -> Tasks -> GetActiveTasksWithoutDatetimeForUser -> service.tasksRepository.DeleteByID(taskID)",
+			"Services -> Tasks -> GetActiveTasksWithoutDatetimeForUser -> service.tasksRepository.DeleteByID(ctx, taskID)",
 			"error", err.Error(), "taskID", taskID,
 		)
 		return err
 	}
 
+	service.publish(ctx, services_types.EventTaskDeleted, task)
+
 	return nil
 }
 
-func (service *Service) DeleteCompleted() error {
+// DeleteCompleted -> cleanup of old completed tasks, task.deleted isn't published for them
+func (service *Service) DeleteCompleted(ctx context.Context) error {
 	service.logger.Info("Services -> Tasks -> DeleteCompleted")
 
-	err := service.tasksRepository.DeleteCompleted()
+	err := service.tasksRepository.DeleteCompleted(ctx)
 	if err != nil {
 		service.logger.Errorw(
-			"Services -> Tasks -> DeleteCompleted -> service.tasksRepository.DeleteCompleted()",
+			"Services -> Tasks -> DeleteCompleted -> service.tasksRepository.DeleteCompleted(ctx)",
 			"error", err.Error(),
 		)
 		return err
@@ -267,20 +418,147 @@ func (service *Service) DeleteCompleted() error {
 	return nil
 }
 
-func (service *Service) FindByID(taskID int64) (models.Task, error) {
+// FindByID (ctx, taskID) -> the task with notification, active blockers and tags
+func (service *Service) FindByID(ctx context.Context, taskID int64) (models.Task, error) {
 	service.logger.Info("Services -> Tasks -> FindByID")
 
-	task, err := service.tasksRepository.FindByID(taskID)
+	task, err := service.tasksRepository.FindByID(ctx, taskID)
 	if err != nil {
 		if errors.Is(err, repositories_types.ErrNotFound) {
 			err = services_types.ErrNotFound
 		}
 		service.logger.Errorw(
-			"Services -> Tasks -> FindByID -> service.tasksRepository.FindByID(taskID)",
+			"Services -> Tasks -> FindByID -> service.tasksRepository.FindByID(ctx, taskID)",
 			"error", err.Error(),
 		)
 		return models.Task{}, err
 	}
 
-	return task, nil
+	tasks := []models.Task{task}
+	err = service.setRelations(ctx, tasks)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Tasks -> FindByID -> service.setRelations(ctx, tasks)",
+			"error", err.Error(), "taskID", taskID,
+		)
+		return models.Task{}, err
+	}
+
+	return tasks[0], nil
+}
+
+func (service *Service) Search(ctx context.Context, params types.SearchParams) ([]models.Task, error) {
+	service.logger.Info("Services -> Tasks -> Search")
+
+	err := validateSearchParams(params)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Tasks -> Search -> validateSearchParams(params)",
+			"error", err.Error(), "params", params,
+		)
+		return []models.Task{}, services_types.InvalidParams(err)
+	}
+
+	filters := repositories_types.TasksSearchFilters{
+		Done:  params.Done,
+		From:  params.From,
+		To:    params.To,
+		Limit: params.Limit,
+	}
+	tasks, err := service.tasksRepository.Search(ctx, params.UserID, params.Query, filters)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Tasks -> Search -> service.tasksRepository.Search(ctx, userID, query, filters)",
+			"error", err.Error(), "params", params,
+		)
+		return []models.Task{}, err
+	}
+
+	err = service.setRelations(ctx, tasks)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Tasks -> Search -> service.setRelations(ctx, tasks)",
+			"error", err.Error(), "tasks", tasks,
+		)
+		return []models.Task{}, err
+	}
+
+	return tasks, nil
+}
+
+// normalizeTags (["#Work", "work", ""]) -> ["work"]
+func normalizeTags(tags []string) []string {
+	var normalized []string
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		tag = filter.NormalizeTag(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+
+	return normalized
+}
+
+// Filter (ctx, params) -> tasks matching the filter query, see the filter package for the syntax.
+// Returns *types.FilterSyntaxError wrapped into services_types.InvalidParamsError if the query can't be parsed.
+func (service *Service) Filter(ctx context.Context, params types.FilterParams) (types.TasksPage, error) {
+	service.logger.Info("Services -> Tasks -> Filter")
+
+	err := validateFilterParams(params)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Tasks -> Filter -> validateFilterParams(params)",
+			"error", err.Error(), "params", params,
+		)
+		return types.TasksPage{}, services_types.InvalidParams(err)
+	}
+
+	now := time.Now()
+	if params.Location != nil {
+		now = now.In(params.Location)
+	}
+
+	tasksFilter, err := filter.Parse(params.Query, now)
+	if err != nil {
+		service.logger.Infow(
+			"Services -> Tasks -> Filter -> filter.Parse(query, now)",
+			"error", err.Error(), "params", params,
+		)
+		return types.TasksPage{}, services_types.InvalidParams(err)
+	}
+	if tasksFilter.SortBy == "" {
+		tasksFilter.SortBy = repositories_types.TasksSortField(params.DefaultSort)
+	}
+	tasksFilter.Limit = params.Limit + 1
+
+	tasks, err := service.tasksRepository.Filter(ctx, params.UserID, tasksFilter)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Tasks -> Filter -> service.tasksRepository.Filter(ctx, userID, tasksFilter)",
+			"error", err.Error(), "params", params, "tasksFilter", tasksFilter,
+		)
+		return types.TasksPage{}, err
+	}
+
+	page := types.TasksPage{}
+	if uint(len(tasks)) > params.Limit {
+		tasks = tasks[:params.Limit]
+		page.HasNext = true
+	}
+
+	err = service.setRelations(ctx, tasks)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Tasks -> Filter -> service.setRelations(ctx, tasks)",
+			"error", err.Error(), "tasks", tasks,
+		)
+		return types.TasksPage{}, err
+	}
+
+	page.Tasks = tasks
+
+	return page, nil
 }
@@ -1,12 +1,30 @@
 package types
 
-import "time"
+import (
+	"fmt"
+	"tg_todo_bot/src/models"
+	"time"
+)
 
 type CreateParams struct {
-	Title       string
-	Description string
-	Datetime    *time.Time
-	UserID      int64
+	Title        string
+	Description  string
+	Datetime     *time.Time
+	Priority     int
+	Tags         []string
+	UserID       int64
+	Notification *NotificationParams //the reminder is created in the same transaction as the task
+	CalDAVObject *CalDAVObjectParams //the name a CalDAV client gave the task, saved in the same transaction
+}
+
+type NotificationParams struct {
+	NotifyAt       time.Time
+	RepeatInterval time.Duration
+}
+
+type CalDAVObjectParams struct {
+	Name string
+	UID  string //empty - ical.TaskUID of the new task
 }
 
 type UpdateParams struct {
@@ -27,7 +45,16 @@ type UpdateParams struct {
 		Value int64
 		IsSet bool
 	}
-	Done bool
+	Priority struct {
+		Value int
+		IsSet bool
+	}
+	Tags struct {
+		Value []string
+		IsSet bool
+	}
+	Done         bool
+	CalDAVObject *CalDAVObjectParams //the name a CalDAV client gave the task, saved in the same transaction
 }
 
 type SearchByDateForUserParams struct {
@@ -35,3 +62,80 @@ type SearchByDateForUserParams struct {
 	To     *time.Time
 	UserID int64
 }
+
+type DependencyParams struct {
+	TaskID          int64
+	BlockedByTaskID int64
+}
+
+type SearchParams struct {
+	UserID int64
+	Query  string
+	Done   *bool
+	From   *time.Time
+	To     *time.Time
+	Limit  uint
+}
+
+// PageParams -> AfterTaskID and BeforeTaskID are IDs of the last/first task of the neighbour page
+type PageParams struct {
+	UserID       int64
+	AfterTaskID  int64
+	BeforeTaskID int64
+	Limit        uint
+}
+
+type TasksPage struct {
+	Tasks   []models.Task
+	HasPrev bool
+	HasNext bool
+}
+
+// FilterSyntaxError -> Token is the part of the filter query which can't be parsed
+type FilterSyntaxError struct {
+	Token  string
+	Reason string
+}
+
+func (err *FilterSyntaxError) Error() string {
+	return fmt.Sprintf("filter token '%s': %s", err.Token, err.Reason)
+}
+
+// FilterParams -> dates of the query are in Location (time.Local if nil),
+// DefaultSort is used if the query has no "sort:"
+type FilterParams struct {
+	UserID      int64
+	Query       string
+	Location    *time.Location
+	DefaultSort string
+	Limit       uint
+}
+
+// Deadline (task, location) -> nil for tasks without datetime,
+// tasks without time (00:00 in the user's location) are due by the end of the day
+func Deadline(task models.Task, location *time.Location) *time.Time {
+	if task.Datetime == nil {
+		return nil
+	}
+
+	deadline := task.Datetime.In(location)
+	hour, minute, second := deadline.Clock()
+	if hour == 0 && minute == 0 && second == 0 {
+		deadline = deadline.AddDate(0, 0, 1)
+	}
+
+	return &deadline
+}
+
+// IsOverdue (task, now) -> not completed task with the deadline before now, now is in the user's location
+func IsOverdue(task models.Task, now time.Time) bool {
+	deadline := Deadline(task, now.Location())
+
+	return !task.Done && deadline != nil && deadline.Before(now)
+}
+
+// Counts -> numbers of tasks of a user for the admin
+type Counts struct {
+	Active    int64
+	Completed int64
+}
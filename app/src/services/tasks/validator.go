@@ -6,6 +6,14 @@ import (
 	"tg_todo_bot/src/services/tasks/types"
 )
 
+const maxPriority = 3
+
+// maxCalDAVNameLength -> the same limit as in the caldav service
+const maxCalDAVNameLength = 255
+
+// maxBulkTasks -> limit of CreateMany, a single transaction shouldn't hold the tasks table for long
+const maxBulkTasks = 1000
+
 func validateCreateParams(params types.CreateParams) error {
 	var emptyRequiredFields []string
 
@@ -17,11 +25,74 @@ func validateCreateParams(params types.CreateParams) error {
 		emptyRequiredFields = append(emptyRequiredFields, "Title")
 	}
 
+	if params.Notification != nil && params.Notification.NotifyAt.IsZero() {
+		emptyRequiredFields = append(emptyRequiredFields, "Notification.NotifyAt")
+	}
+
+	if params.CalDAVObject != nil && params.CalDAVObject.Name == "" {
+		emptyRequiredFields = append(emptyRequiredFields, "CalDAVObject.Name")
+	}
+
 	if len(emptyRequiredFields) > 0 {
 		err := fmt.Errorf("some required fields are empty: [%s]", strings.Join(emptyRequiredFields, ", "))
 		return err
 	}
 
+	if params.Notification != nil && params.Notification.RepeatInterval < 0 {
+		err := fmt.Errorf("repeat interval of the notification can't be negative")
+		return err
+	}
+
+	if params.CalDAVObject != nil {
+		err := validateCalDAVName(params.CalDAVObject.Name)
+		if err != nil {
+			return err
+		}
+	}
+
+	return validatePriority(params.Priority)
+}
+
+func validateCreateManyParams(params []types.CreateParams) error {
+	if len(params) == 0 {
+		err := fmt.Errorf("no tasks to create")
+		return err
+	}
+
+	if len(params) > maxBulkTasks {
+		err := fmt.Errorf("at most %d tasks can be created at once", maxBulkTasks)
+		return err
+	}
+
+	for i, taskParams := range params {
+		err := validateCreateParams(taskParams)
+		if err == nil && taskParams.Notification != nil {
+			err = fmt.Errorf("notifications aren't created with many tasks")
+		}
+		if err != nil {
+			err = fmt.Errorf("task %d: %w", i+1, err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validatePriority(priority int) error {
+	if priority < 0 || priority > maxPriority {
+		err := fmt.Errorf("priority must be between 0 and %d", maxPriority)
+		return err
+	}
+
+	return nil
+}
+
+func validateCalDAVName(name string) error {
+	if len(name) > maxCalDAVNameLength || strings.Contains(name, "/") {
+		err := fmt.Errorf("name of the CalDAV object must be at most %d characters without '/'", maxCalDAVNameLength)
+		return err
+	}
+
 	return nil
 }
 
@@ -41,11 +112,26 @@ func validateUpdateParams(params types.UpdateParams) error {
 		emptyFields = append(emptyFields, "userID")
 	}
 
+	if params.CalDAVObject != nil && params.CalDAVObject.Name == "" {
+		emptyFields = append(emptyFields, "calDAVObject.name")
+	}
+
 	if len(emptyFields) > 0 {
 		err := fmt.Errorf("some fields are empty: [%s]", strings.Join(emptyFields, ", "))
 		return err
 	}
 
+	if params.CalDAVObject != nil {
+		err := validateCalDAVName(params.CalDAVObject.Name)
+		if err != nil {
+			return err
+		}
+	}
+
+	if params.Priority.IsSet {
+		return validatePriority(params.Priority.Value)
+	}
+
 	return nil
 }
 
@@ -62,3 +148,69 @@ func validateSearchByDateForUserParams(params types.SearchByDateForUserParams) e
 
 	return nil
 }
+
+func validateDependencyParams(params types.DependencyParams) error {
+	var emptyRequiredFields []string
+
+	if params.TaskID == 0 {
+		emptyRequiredFields = append(emptyRequiredFields, "TaskID")
+	}
+
+	if params.BlockedByTaskID == 0 {
+		emptyRequiredFields = append(emptyRequiredFields, "BlockedByTaskID")
+	}
+
+	if len(emptyRequiredFields) > 0 {
+		err := fmt.Errorf("some required fields are empty: [%s]", strings.Join(emptyRequiredFields, ", "))
+		return err
+	}
+
+	if params.TaskID == params.BlockedByTaskID {
+		err := fmt.Errorf("task can't be blocked by itself")
+		return err
+	}
+
+	return nil
+}
+
+func validateSearchParams(params types.SearchParams) error {
+	if params.UserID == 0 {
+		err := fmt.Errorf("UserID can't be empty")
+		return err
+	}
+
+	return nil
+}
+
+func validatePageParams(params types.PageParams) error {
+	if params.UserID == 0 {
+		err := fmt.Errorf("UserID can't be empty")
+		return err
+	}
+
+	if params.Limit == 0 {
+		err := fmt.Errorf("Limit can't be empty")
+		return err
+	}
+
+	if params.AfterTaskID != 0 && params.BeforeTaskID != 0 {
+		err := fmt.Errorf("only one of ['AfterTaskID', 'BeforeTaskID'] can be set")
+		return err
+	}
+
+	return nil
+}
+
+func validateFilterParams(params types.FilterParams) error {
+	if params.UserID == 0 {
+		err := fmt.Errorf("UserID can't be empty")
+		return err
+	}
+
+	if params.Limit == 0 {
+		err := fmt.Errorf("Limit can't be empty")
+		return err
+	}
+
+	return nil
+}
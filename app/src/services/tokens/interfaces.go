@@ -0,0 +1,12 @@
+package tokens
+
+import (
+	"context"
+	"tg_todo_bot/src/models"
+)
+
+type ApiTokensRepositoryI interface {
+	Save(ctx context.Context, token models.ApiToken) (models.ApiToken, error)
+	FindByHash(ctx context.Context, tokenHash string) (models.ApiToken, error)
+	DeleteForUser(ctx context.Context, userID int64, scope string) error
+}
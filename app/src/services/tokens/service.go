@@ -0,0 +1,118 @@
+package tokens
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"tg_todo_bot/src/models"
+	repositories_types "tg_todo_bot/src/repositories/types"
+	services_types "tg_todo_bot/src/services/types"
+)
+
+const tokenBytes = 32
+
+type Service struct {
+	logger              *zap.SugaredLogger
+	apiTokensRepository ApiTokensRepositoryI
+}
+
+func NewService(
+	logger *zap.SugaredLogger,
+	apiTokensRepository ApiTokensRepositoryI,
+) *Service {
+	return &Service{
+		logger:              logger,
+		apiTokensRepository: apiTokensRepository,
+	}
+}
+
+// Issue (ctx, userID, scope) -> a new token of the user, the previous token of the scope stops working
+func (service *Service) Issue(ctx context.Context, userID int64, scope string) (string, error) {
+	service.logger.Info("Services -> Tokens -> Issue")
+
+	err := validateIssueParams(userID, scope)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Tokens -> Issue -> validateIssueParams(userID, scope)",
+			"error", err.Error(), "userID", userID, "scope", scope,
+		)
+		return "", services_types.InvalidParams(err)
+	}
+
+	randomBytes := make([]byte, tokenBytes)
+	_, err = rand.Read(randomBytes)
+	if err != nil {
+		service.logger.Errorw("Services -> Tokens -> Issue -> rand.Read(randomBytes)", "error", err.Error())
+		return "", err
+	}
+	token := hex.EncodeToString(randomBytes)
+
+	tokenModel := models.ApiToken{
+		UserID:    userID,
+		Scope:     scope,
+		TokenHash: hashToken(token),
+	}
+	_, err = service.apiTokensRepository.Save(ctx, tokenModel)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Tokens -> Issue -> service.apiTokensRepository.Save(ctx, tokenModel)",
+			"error", err.Error(), "userID", userID, "scope", scope,
+		)
+		return "", err
+	}
+
+	return token, nil
+}
+
+// Authenticate (ctx, token, scope) -> ID of the token's owner,
+// services_types.ErrNotFound if the token is unknown, revoked or issued for another scope
+func (service *Service) Authenticate(ctx context.Context, token, scope string) (int64, error) {
+	service.logger.Info("Services -> Tokens -> Authenticate")
+
+	if token == "" {
+		return 0, services_types.ErrNotFound
+	}
+
+	tokenModel, err := service.apiTokensRepository.FindByHash(ctx, hashToken(token))
+	if err != nil {
+		if errors.Is(err, repositories_types.ErrNotFound) {
+			return 0, services_types.ErrNotFound
+		}
+		service.logger.Errorw(
+			"Services -> Tokens -> Authenticate -> service.apiTokensRepository.FindByHash(ctx, tokenHash)",
+			"error", err.Error(),
+		)
+		return 0, err
+	}
+
+	if tokenModel.Scope != scope {
+		return 0, services_types.ErrNotFound
+	}
+
+	return tokenModel.UserID, nil
+}
+
+// Revoke (ctx, userID, scope) -> the user's token of the scope stops working
+func (service *Service) Revoke(ctx context.Context, userID int64, scope string) error {
+	service.logger.Info("Services -> Tokens -> Revoke")
+
+	err := service.apiTokensRepository.DeleteForUser(ctx, userID, scope)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Tokens -> Revoke -> service.apiTokensRepository.DeleteForUser(ctx, userID, scope)",
+			"error", err.Error(), "userID", userID, "scope", scope,
+		)
+		return err
+	}
+
+	return nil
+}
+
+// hashToken (token) -> hex of SHA-256, the token is long and random, so salt and a slow hash aren't needed
+func hashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
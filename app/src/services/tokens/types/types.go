@@ -0,0 +1,10 @@
+package types
+
+// Scopes of tokens, a token of one scope doesn't work for another
+const (
+	ScopeApi      = "api"      //REST API, full access to the user's tasks
+	ScopeCalendar = "calendar" //read-only iCalendar feed, the token is a part of the feed URL
+	ScopeCalDAV   = "caldav"   //password of CalDAV clients, they edit tasks like the REST API
+)
+
+var Scopes = []string{ScopeApi, ScopeCalendar, ScopeCalDAV}
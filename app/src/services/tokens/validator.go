@@ -0,0 +1,23 @@
+package tokens
+
+import (
+	"fmt"
+	"tg_todo_bot/src/services/tokens/types"
+)
+
+func validateIssueParams(userID int64, scope string) error {
+	if userID == 0 {
+		err := fmt.Errorf("userID is required")
+		return err
+	}
+
+	for _, knownScope := range types.Scopes {
+		if scope == knownScope {
+			return nil
+		}
+	}
+
+	err := fmt.Errorf("unknown scope '%s'", scope)
+
+	return err
+}
@@ -0,0 +1,26 @@
+package types
+
+import (
+	"tg_todo_bot/src/models"
+	"time"
+)
+
+// Types of domain events
+const (
+	EventTaskCreated   = "task.created"
+	EventTaskUpdated   = "task.updated"
+	EventTaskCompleted = "task.completed"
+	EventTaskDeleted   = "task.deleted"
+	EventReminderFired = "reminder.fired"
+)
+
+// Event -> a change made by a service, published after the change is saved.
+// Task is its state after the change (before it for task.deleted),
+// Notification is set for reminder.fired only
+type Event struct {
+	Type         string
+	UserID       int64
+	Task         models.Task
+	Notification *models.Notification
+	OccurredAt   time.Time
+}
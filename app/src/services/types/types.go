@@ -1,5 +1,54 @@
 package types
 
-import "fmt"
+import (
+	"fmt"
+	repositories_types "tg_todo_bot/src/repositories/types"
+	"time"
+)
 
-var ErrNotFound = fmt.Errorf("not found")
+var (
+	ErrNotFound        = fmt.Errorf("not found")
+	ErrDependencyCycle = fmt.Errorf("dependency cycle")
+	ErrInvalidParams   = fmt.Errorf("invalid params")
+	ErrRateLimited     = fmt.Errorf("rate limited")
+)
+
+// ErrTimeout -> a query didn't finish in time. It's the error of repositories as is, so services don't map it
+var ErrTimeout = repositories_types.ErrTimeout
+
+// InvalidParamsError -> error of a validator, errors.Is(err, ErrInvalidParams) is true for it,
+// so callers can tell wrong input from internal errors
+type InvalidParamsError struct {
+	Err error
+}
+
+func (err *InvalidParamsError) Error() string {
+	return err.Err.Error()
+}
+
+func (err *InvalidParamsError) Unwrap() error {
+	return err.Err
+}
+
+func (err *InvalidParamsError) Is(target error) bool {
+	return target == ErrInvalidParams
+}
+
+// InvalidParams (err) -> err of a validator wrapped into InvalidParamsError
+func InvalidParams(err error) error {
+	return &InvalidParamsError{Err: err}
+}
+
+// RateLimitedError -> the user runs out of a rate limit, errors.Is(err, ErrRateLimited) is true for it.
+// RetryAfter is the time until the next request is allowed
+type RateLimitedError struct {
+	RetryAfter time.Duration
+}
+
+func (err *RateLimitedError) Error() string {
+	return fmt.Sprintf("rate limited, retry after %s", err.RetryAfter)
+}
+
+func (err *RateLimitedError) Is(target error) bool {
+	return target == ErrRateLimited
+}
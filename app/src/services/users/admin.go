@@ -0,0 +1,172 @@
+package users
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"tg_todo_bot/src/models"
+	repositories_types "tg_todo_bot/src/repositories/types"
+	services_types "tg_todo_bot/src/services/types"
+	"tg_todo_bot/src/services/users/types"
+	"time"
+)
+
+// lastSeenPrecision -> LastSeenAt is updated at most this often, so not every update writes to the database
+const lastSeenPrecision = 10 * time.Minute
+
+// TouchLastSeen (ctx, user, now) -> marks the user as active and resumes reminders if the user blocked the bot before,
+// LastSeenAt isn't updated if the user was seen recently
+func (service *Service) TouchLastSeen(ctx context.Context, user models.User, now time.Time) error {
+	seenRecently := user.LastSeenAt != nil && now.Sub(*user.LastSeenAt) < lastSeenPrecision
+	if seenRecently && user.BotBlockedAt == nil {
+		return nil
+	}
+
+	service.logger.Info("Services -> Users -> TouchLastSeen")
+
+	//Пользователь снова пишет боту, значит разблокировал его
+	if user.BotBlockedAt != nil {
+		err := service.usersRepository.SetBotBlockedAt(ctx, user.ID, nil)
+		if err != nil {
+			service.logger.Errorw(
+				"Services -> Users -> TouchLastSeen -> service.usersRepository.SetBotBlockedAt(ctx, userID, nil)",
+				"error", err.Error(), "userID", user.ID,
+			)
+			return err
+		}
+	}
+
+	if seenRecently {
+		return nil
+	}
+
+	err := service.usersRepository.SetLastSeenAt(ctx, user.ID, now)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Users -> TouchLastSeen -> service.usersRepository.SetLastSeenAt(ctx, userID, now)",
+			"error", err.Error(), "userID", user.ID,
+		)
+		return err
+	}
+
+	return nil
+}
+
+// MarkBotBlocked (ctx, telegramID, now) -> the user blocked the bot, reminders, escalations and digests are paused
+// until TouchLastSeen, services_types.ErrNotFound if there is no user
+func (service *Service) MarkBotBlocked(ctx context.Context, telegramID int64, now time.Time) error {
+	service.logger.Info("Services -> Users -> MarkBotBlocked")
+
+	user, err := service.FindByTelegramID(ctx, telegramID)
+	if err != nil {
+		return err
+	}
+	if user.BotBlockedAt != nil {
+		return nil
+	}
+
+	err = service.usersRepository.SetBotBlockedAt(ctx, user.ID, &now)
+	if err != nil {
+		if errors.Is(err, repositories_types.ErrNotFound) {
+			err = services_types.ErrNotFound
+		}
+		service.logger.Errorw(
+			"Services -> Users -> MarkBotBlocked -> service.usersRepository.SetBotBlockedAt(ctx, userID, now)",
+			"error", err.Error(), "userID", user.ID,
+		)
+		return err
+	}
+
+	return nil
+}
+
+// Ban (ctx, userID, reason, now) -> updates of the user are ignored until Unban, the reason is only for the admin
+func (service *Service) Ban(ctx context.Context, userID int64, reason string, now time.Time) error {
+	service.logger.Info("Services -> Users -> Ban")
+
+	err := service.usersRepository.SetBan(ctx, userID, &now, reason)
+	if err != nil {
+		if errors.Is(err, repositories_types.ErrNotFound) {
+			err = services_types.ErrNotFound
+		}
+		service.logger.Errorw(
+			"Services -> Users -> Ban -> service.usersRepository.SetBan(ctx, userID, now, reason)",
+			"error", err.Error(), "userID", userID,
+		)
+		return err
+	}
+
+	return nil
+}
+
+func (service *Service) Unban(ctx context.Context, userID int64) error {
+	service.logger.Info("Services -> Users -> Unban")
+
+	err := service.usersRepository.SetBan(ctx, userID, nil, "")
+	if err != nil {
+		if errors.Is(err, repositories_types.ErrNotFound) {
+			err = services_types.ErrNotFound
+		}
+		service.logger.Errorw(
+			`Services -> Users -> Unban -> service.usersRepository.SetBan(userID, nil, "")`,
+			"error", err.Error(), "userID", userID,
+		)
+		return err
+	}
+
+	return nil
+}
+
+// GetStats (ctx, now) -> numbers of all, new and active users
+func (service *Service) GetStats(ctx context.Context, now time.Time) (types.Stats, error) {
+	service.logger.Info("Services -> Users -> GetStats")
+
+	type usersCount struct {
+		filter repositories_types.UsersCountFilter
+		value  *int64
+	}
+
+	var stats types.Stats
+	counts := []usersCount{
+		{filter: repositories_types.UsersCountFilter{}, value: &stats.Total},
+		{filter: repositories_types.UsersCountFilter{Banned: true}, value: &stats.Banned},
+		{filter: repositories_types.UsersCountFilter{BotBlocked: true}, value: &stats.BotBlocked},
+		{filter: repositories_types.UsersCountFilter{DeletionRequested: true}, value: &stats.DeletionRequested},
+	}
+	for i, period := range types.StatsPeriods {
+		from := now.Add(-period)
+		counts = append(counts,
+			usersCount{filter: repositories_types.UsersCountFilter{CreatedFrom: &from}, value: &stats.New[i]},
+			usersCount{filter: repositories_types.UsersCountFilter{SeenFrom: &from}, value: &stats.Active[i]},
+		)
+	}
+
+	for _, count := range counts {
+		value, err := service.usersRepository.Count(ctx, count.filter)
+		if err != nil {
+			service.logger.Errorw(
+				"Services -> Users -> GetStats -> service.usersRepository.Count(ctx, filter)",
+				"error", err.Error(), "filter", count.filter,
+			)
+			return types.Stats{}, err
+		}
+		*count.value = value
+	}
+
+	return stats, nil
+}
+
+// GetPage (ctx, afterUserID, limit) -> users by ID for broadcasts
+func (service *Service) GetPage(ctx context.Context, afterUserID int64, limit uint) ([]models.User, error) {
+	service.logger.Info("Services -> Users -> GetPage")
+
+	users, err := service.usersRepository.GetPage(ctx, afterUserID, limit)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Users -> GetPage -> service.usersRepository.GetPage(ctx, afterUserID, limit)",
+			"error", err.Error(), "afterUserID", afterUserID,
+		)
+		return []models.User{}, err
+	}
+
+	return users, nil
+}
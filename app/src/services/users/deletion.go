@@ -0,0 +1,104 @@
+package users
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"tg_todo_bot/src/models"
+	repositories_types "tg_todo_bot/src/repositories/types"
+	services_types "tg_todo_bot/src/services/types"
+	"tg_todo_bot/src/services/users/types"
+	"time"
+)
+
+// RequestDeletion (ctx, userID, now) -> the account is purged after types.DeletionGracePeriod unless the deletion is cancelled
+func (service *Service) RequestDeletion(ctx context.Context, userID int64, now time.Time) error {
+	service.logger.Info("Services -> Users -> RequestDeletion")
+
+	err := service.setDeletionRequestedAt(ctx, userID, &now)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Users -> RequestDeletion -> service.setDeletionRequestedAt(ctx, userID, now)",
+			"error", err.Error(), "userID", userID,
+		)
+		return err
+	}
+
+	return nil
+}
+
+func (service *Service) CancelDeletion(ctx context.Context, userID int64) error {
+	service.logger.Info("Services -> Users -> CancelDeletion")
+
+	err := service.setDeletionRequestedAt(ctx, userID, nil)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Users -> CancelDeletion -> service.setDeletionRequestedAt(ctx, userID, nil)",
+			"error", err.Error(), "userID", userID,
+		)
+		return err
+	}
+
+	return nil
+}
+
+func (service *Service) setDeletionRequestedAt(ctx context.Context, userID int64, requestedAt *time.Time) error {
+	err := service.usersRepository.SetDeletionRequestedAt(ctx, userID, requestedAt)
+	if errors.Is(err, repositories_types.ErrNotFound) {
+		return services_types.ErrNotFound
+	}
+
+	return err
+}
+
+// GetPurgeDue (ctx, now) -> users whose grace period is over
+func (service *Service) GetPurgeDue(ctx context.Context, now time.Time) ([]models.User, error) {
+	service.logger.Info("Services -> Users -> GetPurgeDue")
+
+	users, err := service.usersRepository.GetDeletionRequestedBefore(ctx, now.Add(-types.DeletionGracePeriod))
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Users -> GetPurgeDue -> service.usersRepository.GetDeletionRequestedBefore(ctx, requestedBefore)",
+			"error", err.Error(),
+		)
+		return []models.User{}, err
+	}
+
+	return users, nil
+}
+
+// Purge (ctx, userID, now) -> deletes the user with tasks, reminders, settings, tokens and webhooks if the grace period
+// is over. The deletion requested is checked again in the transaction of the purge, so a deletion cancelled after
+// GetPurgeDue isn't lost: services_types.ErrNotFound then. The number of deleted rows by table is logged,
+// the deletion is rolled back if any rows of the user are left
+func (service *Service) Purge(ctx context.Context, userID int64, now time.Time) error {
+	service.logger.Info("Services -> Users -> Purge")
+
+	requestedBefore := now.Add(-types.DeletionGracePeriod)
+	return service.purge(ctx, userID, &requestedBefore)
+}
+
+// purge (ctx, userID, requestedBefore) -> nil requestedBefore deletes the user whether the deletion is requested or not
+func (service *Service) purge(ctx context.Context, userID int64, requestedBefore *time.Time) error {
+	var deleted map[string]int64
+	var err error
+	if requestedBefore != nil {
+		deleted, err = service.usersRepository.PurgeRequestedBefore(ctx, userID, *requestedBefore)
+	} else {
+		deleted, err = service.usersRepository.Purge(ctx, userID)
+	}
+	if err != nil {
+		if errors.Is(err, repositories_types.ErrNotFound) {
+			return services_types.ErrNotFound
+		}
+		service.logger.Errorw(
+			"Services -> Users -> purge -> service.usersRepository.Purge(ctx, userID)",
+			"error", err.Error(), "userID", userID,
+		)
+		return err
+	}
+
+	//Запись для аудита: что и сколько удалено, без данных пользователя
+	service.logger.Infow("Services -> Users -> purge -> account deleted", "userID", userID, "deletedRows", deleted)
+
+	return nil
+}
@@ -1,9 +1,23 @@
 package users
 
-import "tg_todo_bot/src/models"
+import (
+	"context"
+	"tg_todo_bot/src/models"
+	repositories_types "tg_todo_bot/src/repositories/types"
+	"time"
+)
 
 type UsersRepositoryI interface {
-	Create(user models.User) (models.User, error)
-	FindByTelegramID(telegramID int64) (models.User, error)
-	DeleteByTelegramID(telegramID int64) error
+	Create(ctx context.Context, user models.User) (models.User, error)
+	FindByTelegramID(ctx context.Context, telegramID int64) (models.User, error)
+	FindByID(ctx context.Context, ID int64) (models.User, error)
+	SetDeletionRequestedAt(ctx context.Context, userID int64, requestedAt *time.Time) error
+	GetDeletionRequestedBefore(ctx context.Context, requestedBefore time.Time) ([]models.User, error)
+	Purge(ctx context.Context, userID int64) (map[string]int64, error)
+	PurgeRequestedBefore(ctx context.Context, userID int64, requestedBefore time.Time) (map[string]int64, error)
+	SetLastSeenAt(ctx context.Context, userID int64, seenAt time.Time) error
+	SetBan(ctx context.Context, userID int64, bannedAt *time.Time, reason string) error
+	SetBotBlockedAt(ctx context.Context, userID int64, blockedAt *time.Time) error
+	GetPage(ctx context.Context, afterUserID int64, limit uint) ([]models.User, error)
+	Count(ctx context.Context, filter repositories_types.UsersCountFilter) (int64, error)
 }
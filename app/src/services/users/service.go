@@ -1,6 +1,7 @@
 package users
 
 import (
+	"context"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 	"tg_todo_bot/src/models"
@@ -24,7 +25,7 @@ func NewService(
 	}
 }
 
-func (service *Service) Create(params types.CreateParams) error {
+func (service *Service) Create(ctx context.Context, params types.CreateParams) error {
 	service.logger.Info("Services -> Users -> Create")
 
 	err := validateCreateParams(params)
@@ -33,20 +34,20 @@ func (service *Service) Create(params types.CreateParams) error {
 			"Services -> Users -> Create -> validateCreateParams(params)",
 			"error", err.Error(), "params", params,
 		)
-		return err
+		return services_types.InvalidParams(err)
 	}
 
 	userModes := models.User{
 		TelegramID: params.TelegramID,
 	}
 
-	userModes, err = service.usersRepository.Create(userModes)
+	userModes, err = service.usersRepository.Create(ctx, userModes)
 	if err != nil {
 		if errors.Is(err, repositories_types.ErrAlreadyExist) {
 			return nil
 		}
 		service.logger.Errorw(
-			"Services -> Users -> Create -> service.usersRepository.Create(userModes)",
+			"Services -> Users -> Create -> service.usersRepository.Create(ctx, userModes)",
 			"error", err.Error(), "userModes", userModes, "params", params,
 		)
 		return err
@@ -55,16 +56,16 @@ func (service *Service) Create(params types.CreateParams) error {
 	return nil
 }
 
-func (service *Service) FindByTelegramID(telegramID int64) (models.User, error) {
+func (service *Service) FindByTelegramID(ctx context.Context, telegramID int64) (models.User, error) {
 	service.logger.Info("Services -> Users -> FindByTelegramID")
 
-	userModel, err := service.usersRepository.FindByTelegramID(telegramID)
+	userModel, err := service.usersRepository.FindByTelegramID(ctx, telegramID)
 	if err != nil {
 		if errors.Is(err, repositories_types.ErrNotFound) {
 			err = services_types.ErrNotFound
 		}
 		service.logger.Errorw(
-			"Services -> Users -> FindByTelegramID -> service.usersRepository.FindByTelegramID(telegramID)",
+			"Services -> Users -> FindByTelegramID -> service.usersRepository.FindByTelegramID(ctx, telegramID)",
 			"error", err.Error(), "telegramID", telegramID,
 		)
 		return models.User{}, err
@@ -73,17 +74,39 @@ func (service *Service) FindByTelegramID(telegramID int64) (models.User, error)
 	return userModel, nil
 }
 
-func (service *Service) DeleteByTelegramID(telegramID int64) error {
+func (service *Service) FindByID(ctx context.Context, userID int64) (models.User, error) {
+	service.logger.Info("Services -> Users -> FindByID")
+
+	userModel, err := service.usersRepository.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repositories_types.ErrNotFound) {
+			err = services_types.ErrNotFound
+		}
+		service.logger.Errorw(
+			"Services -> Users -> FindByID -> service.usersRepository.FindByID(ctx, userID)",
+			"error", err.Error(), "userID", userID,
+		)
+		return models.User{}, err
+	}
+
+	return userModel, nil
+}
+
+// DeleteByTelegramID (ctx, telegramID) -> deletes the account right away without the grace period, see Purge
+func (service *Service) DeleteByTelegramID(ctx context.Context, telegramID int64) error {
 	service.logger.Info("Services -> Users -> DeleteByTelegramID")
 
-	err := service.usersRepository.DeleteByTelegramID(telegramID)
+	userModel, err := service.usersRepository.FindByTelegramID(ctx, telegramID)
 	if err != nil {
+		if errors.Is(err, repositories_types.ErrNotFound) {
+			return nil
+		}
 		service.logger.Errorw(
-			"Services -> Users -> DeleteByTelegramID -> service.usersRepository.DeleteByTelegramID(telegramID)",
+			"Services -> Users -> DeleteByTelegramID -> service.usersRepository.FindByTelegramID(ctx, telegramID)",
 			"error", err.Error(), "telegramID", telegramID,
 		)
 		return err
 	}
 
-	return nil
+	return service.purge(ctx, userModel.ID, nil)
 }
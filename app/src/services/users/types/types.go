@@ -1,5 +1,36 @@
 package types
 
+import (
+	"tg_todo_bot/src/models"
+	"time"
+)
+
+// DeletionGracePeriod -> the account is purged this long after /deleteme, until then the deletion can be cancelled
+const DeletionGracePeriod = 7 * 24 * time.Hour
+
 type CreateParams struct {
 	TelegramID int64
 }
+
+// PurgeAt (user) -> when the account will be purged, nil if the deletion isn't requested
+func PurgeAt(user models.User) *time.Time {
+	if user.DeletionRequestedAt == nil {
+		return nil
+	}
+
+	purgeAt := user.DeletionRequestedAt.Add(DeletionGracePeriod)
+	return &purgeAt
+}
+
+// Stats -> numbers of users for the admin, by the last day, week and month
+type Stats struct {
+	Total             int64
+	Banned            int64
+	BotBlocked        int64 //users who blocked the bot
+	DeletionRequested int64
+	New               [3]int64
+	Active            [3]int64 //users who wrote to the bot in the period
+}
+
+// StatsPeriods -> periods of Stats.New and Stats.Active
+var StatsPeriods = [3]time.Duration{24 * time.Hour, 7 * 24 * time.Hour, 30 * 24 * time.Hour}
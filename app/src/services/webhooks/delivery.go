@@ -0,0 +1,156 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"tg_todo_bot/src/models"
+	"time"
+)
+
+const (
+	maxDeliveryAttempts = 8
+	firstRetryDelay     = time.Minute
+	maxRetryDelay       = 6 * time.Hour
+	deliveriesBatchSize = 100
+	//Партия отправляется за deliveriesBatchSize / deliveriesConcurrency таймаутов получателя, аренда дольше
+	deliveriesConcurrency = 10
+	claimLease            = 10 * time.Minute
+	maxLastErrorLength    = 512
+)
+
+// DeliverDue (ctx, now) -> sends deliveries whose attempt is due, failed ones are retried with exponential backoff.
+// Up to deliveriesConcurrency receivers are called at once, so one slow receiver doesn't hold the whole batch
+func (service *Service) DeliverDue(ctx context.Context, now time.Time) error {
+	deliveries, err := service.webhookDeliveriesRepository.Claim(ctx, now, claimLease, deliveriesBatchSize)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Webhooks -> DeliverDue -> service.webhookDeliveriesRepository.Claim(ctx, now, lease, limit)",
+			"error", err.Error(), "now", now,
+		)
+		return err
+	}
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	var firstErr error
+	slots := make(chan struct{}, deliveriesConcurrency)
+	for _, delivery := range deliveries {
+		slots <- struct{}{}
+		wg.Add(1)
+
+		go func(delivery models.WebhookDelivery) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+
+			delivery = service.attempt(ctx, delivery, now)
+
+			err := service.webhookDeliveriesRepository.Update(ctx, delivery)
+			if err != nil {
+				service.logger.Errorw(
+					"Services -> Webhooks -> DeliverDue -> service.webhookDeliveriesRepository.Update(ctx, delivery)",
+					"error", err.Error(), "deliveryID", delivery.ID,
+				)
+
+				mutex.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mutex.Unlock()
+			}
+		}(delivery)
+	}
+	wg.Wait()
+
+	//Не сохранённые доставки отправятся ещё раз после аренды
+	return firstErr
+}
+
+// attempt (ctx, delivery, now) -> the delivery with the result of one more attempt
+func (service *Service) attempt(ctx context.Context, delivery models.WebhookDelivery, now time.Time) models.WebhookDelivery {
+	delivery.Attempts++
+
+	err := service.send(ctx, delivery)
+	if err == nil {
+		delivery.DeliveredAt = &now
+		delivery.NextAttemptAt = nil
+		delivery.LastError = ""
+		return delivery
+	}
+
+	delivery.LastError = err.Error()
+	if len(delivery.LastError) > maxLastErrorLength {
+		delivery.LastError = delivery.LastError[:maxLastErrorLength]
+	}
+
+	if delivery.Attempts >= maxDeliveryAttempts {
+		service.logger.Errorw(
+			"Services -> Webhooks -> attempt -> out of attempts",
+			"deliveryID", delivery.ID, "webhookID", delivery.WebhookID, "error", delivery.LastError,
+		)
+		delivery.NextAttemptAt = nil
+		return delivery
+	}
+
+	nextAttemptAt := now.Add(retryDelay(delivery.Attempts))
+	delivery.NextAttemptAt = &nextAttemptAt
+
+	return delivery
+}
+
+// send (ctx, delivery) -> nil if the receiver answered with 2xx
+func (service *Service) send(ctx context.Context, delivery models.WebhookDelivery) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "tg_todo_bot-webhooks")
+	request.Header.Set("X-Webhook-Event", delivery.EventType)
+	request.Header.Set("X-Webhook-Delivery", strconv.FormatInt(delivery.ID, 10))
+	request.Header.Set("X-Webhook-Signature", Signature(delivery.Webhook.Secret, delivery.Payload))
+
+	response, err := service.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, 64*1024))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("receiver answered %s", response.Status)
+	}
+
+	return nil
+}
+
+// Signature (secret, payload) -> value of the X-Webhook-Signature header, "sha256=" and hex of HMAC-SHA256 of the body
+func Signature(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// retryDelay (attempts) -> 1m, 2m, 4m... after the failed attempt number attempts, at most maxRetryDelay
+func retryDelay(attempts int) time.Duration {
+	delay := firstRetryDelay
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+
+	return delay
+}
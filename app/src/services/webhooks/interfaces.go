@@ -0,0 +1,19 @@
+package webhooks
+
+import (
+	"context"
+	"tg_todo_bot/src/models"
+	"time"
+)
+
+type WebhooksRepositoryI interface {
+	Create(ctx context.Context, webhook models.Webhook) (models.Webhook, error)
+	GetAllForUser(ctx context.Context, userID int64) ([]models.Webhook, error)
+	DeleteForUser(ctx context.Context, userID, webhookID int64) error
+}
+
+type WebhookDeliveriesRepositoryI interface {
+	Create(ctx context.Context, delivery models.WebhookDelivery) (models.WebhookDelivery, error)
+	Update(ctx context.Context, delivery models.WebhookDelivery) error
+	Claim(ctx context.Context, now time.Time, lease time.Duration, limit uint) ([]models.WebhookDelivery, error)
+}
@@ -0,0 +1,74 @@
+package webhooks
+
+import (
+	"tg_todo_bot/src/models"
+	services_types "tg_todo_bot/src/services/types"
+	"time"
+)
+
+type eventPayload struct {
+	Event        string               `json:"event"`
+	OccurredAt   time.Time            `json:"occurred_at"`
+	Task         taskPayload          `json:"task"`
+	Notification *notificationPayload `json:"notification,omitempty"`
+}
+
+type taskPayload struct {
+	ID          int64      `json:"id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Datetime    *time.Time `json:"datetime"`
+	Done        bool       `json:"done"`
+	Priority    int        `json:"priority"`
+	Tags        []string   `json:"tags"`
+	BlockedBy   []int64    `json:"blocked_by"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+type notificationPayload struct {
+	ID             int64     `json:"id"`
+	NotifyAt       time.Time `json:"notify_at"`
+	RepeatInterval int64     `json:"repeat_interval"` //seconds
+}
+
+// newEventPayload (event) -> JSON body of the delivery, the same fields as in the REST API
+func newEventPayload(event services_types.Event) eventPayload {
+	payload := eventPayload{
+		Event:      event.Type,
+		OccurredAt: event.OccurredAt,
+		Task:       newTaskPayload(event.Task),
+	}
+
+	if event.Notification != nil {
+		payload.Notification = &notificationPayload{
+			ID:             event.Notification.ID,
+			NotifyAt:       event.Notification.NotifyAt,
+			RepeatInterval: int64(event.Notification.RepeatInterval / time.Second),
+		}
+	}
+
+	return payload
+}
+
+func newTaskPayload(task models.Task) taskPayload {
+	payload := taskPayload{
+		ID:          task.ID,
+		Title:       task.Title,
+		Description: task.Description,
+		Datetime:    task.Datetime,
+		Done:        task.Done,
+		Priority:    task.Priority,
+		Tags:        task.Tags,
+		BlockedBy:   task.BlockedBy,
+		CreatedAt:   task.CreatedAt,
+	}
+
+	if payload.Tags == nil {
+		payload.Tags = []string{}
+	}
+	if payload.BlockedBy == nil {
+		payload.BlockedBy = []int64{}
+	}
+
+	return payload
+}
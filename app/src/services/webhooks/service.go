@@ -0,0 +1,165 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"net/http"
+	"tg_todo_bot/src/models"
+	repositories_types "tg_todo_bot/src/repositories/types"
+	services_types "tg_todo_bot/src/services/types"
+	"tg_todo_bot/src/services/webhooks/types"
+)
+
+const (
+	maxWebhooksPerUser = 5
+	secretBytes        = 32
+)
+
+// Service -> webhooks of users. Events are saved as deliveries first and sent by DeliverDue,
+// so a slow or broken receiver doesn't slow down the bot and gets the event after it's back
+type Service struct {
+	logger                      *zap.SugaredLogger
+	webhooksRepository          WebhooksRepositoryI
+	webhookDeliveriesRepository WebhookDeliveriesRepositoryI
+	httpClient                  *http.Client
+}
+
+func NewService(
+	logger *zap.SugaredLogger,
+	webhooksRepository WebhooksRepositoryI,
+	webhookDeliveriesRepository WebhookDeliveriesRepositoryI,
+	httpClient *http.Client,
+) *Service {
+	return &Service{
+		logger:                      logger,
+		webhooksRepository:          webhooksRepository,
+		webhookDeliveriesRepository: webhookDeliveriesRepository,
+		httpClient:                  httpClient,
+	}
+}
+
+// Register (ctx, params) -> the new webhook with the secret which signs its payloads
+func (service *Service) Register(ctx context.Context, params types.RegisterParams) (models.Webhook, error) {
+	service.logger.Info("Services -> Webhooks -> Register")
+
+	err := validateRegisterParams(ctx, params)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Webhooks -> Register -> validateRegisterParams(ctx, params)",
+			"error", err.Error(), "params", params,
+		)
+		return models.Webhook{}, services_types.InvalidParams(err)
+	}
+
+	webhooks, err := service.GetAllForUser(ctx, params.UserID)
+	if err != nil {
+		return models.Webhook{}, err
+	}
+	if len(webhooks) >= maxWebhooksPerUser {
+		err = fmt.Errorf("a user can have at most %d webhooks", maxWebhooksPerUser)
+		return models.Webhook{}, services_types.InvalidParams(err)
+	}
+
+	randomBytes := make([]byte, secretBytes)
+	_, err = rand.Read(randomBytes)
+	if err != nil {
+		service.logger.Errorw("Services -> Webhooks -> Register -> rand.Read(randomBytes)", "error", err.Error())
+		return models.Webhook{}, err
+	}
+
+	webhookModel := models.Webhook{
+		UserID: params.UserID,
+		URL:    params.URL,
+		Secret: hex.EncodeToString(randomBytes),
+	}
+	webhookModel, err = service.webhooksRepository.Create(ctx, webhookModel)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Webhooks -> Register -> service.webhooksRepository.Create(ctx, webhookModel)",
+			"error", err.Error(), "params", params,
+		)
+		return models.Webhook{}, err
+	}
+
+	return webhookModel, nil
+}
+
+func (service *Service) GetAllForUser(ctx context.Context, userID int64) ([]models.Webhook, error) {
+	service.logger.Info("Services -> Webhooks -> GetAllForUser")
+
+	webhooks, err := service.webhooksRepository.GetAllForUser(ctx, userID)
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Webhooks -> GetAllForUser -> service.webhooksRepository.GetAllForUser(ctx, userID)",
+			"error", err.Error(), "userID", userID,
+		)
+		return []models.Webhook{}, err
+	}
+
+	return webhooks, nil
+}
+
+// DeleteForUser (ctx, userID, webhookID) -> services_types.ErrNotFound if the user has no such webhook
+func (service *Service) DeleteForUser(ctx context.Context, userID, webhookID int64) error {
+	service.logger.Info("Services -> Webhooks -> DeleteForUser")
+
+	err := service.webhooksRepository.DeleteForUser(ctx, userID, webhookID)
+	if err != nil {
+		if errors.Is(err, repositories_types.ErrNotFound) {
+			return services_types.ErrNotFound
+		}
+		service.logger.Errorw(
+			"Services -> Webhooks -> DeleteForUser -> service.webhooksRepository.DeleteForUser(ctx, userID, webhookID)",
+			"error", err.Error(), "userID", userID, "webhookID", webhookID,
+		)
+		return err
+	}
+
+	return nil
+}
+
+// Publish (ctx, event) -> queues a delivery of the event to every webhook of its user
+func (service *Service) Publish(ctx context.Context, event services_types.Event) error {
+	service.logger.Info("Services -> Webhooks -> Publish")
+
+	webhooks, err := service.GetAllForUser(ctx, event.UserID)
+	if err != nil {
+		return err
+	}
+	if len(webhooks) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(newEventPayload(event))
+	if err != nil {
+		service.logger.Errorw(
+			"Services -> Webhooks -> Publish -> json.Marshal(payload)",
+			"error", err.Error(), "event", event,
+		)
+		return err
+	}
+
+	for _, webhook := range webhooks {
+		delivery := models.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			EventType:     event.Type,
+			Payload:       payload,
+			NextAttemptAt: &event.OccurredAt,
+		}
+		_, err = service.webhookDeliveriesRepository.Create(ctx, delivery)
+		if err != nil {
+			service.logger.Errorw(
+				"Services -> Webhooks -> Publish -> service.webhookDeliveriesRepository.Create(ctx, delivery)",
+				"error", err.Error(), "webhookID", webhook.ID, "event", event.Type,
+			)
+			return err
+		}
+	}
+
+	return nil
+}
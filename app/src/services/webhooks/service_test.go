@@ -0,0 +1,354 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"tg_todo_bot/src/models"
+	services_types "tg_todo_bot/src/services/types"
+	"tg_todo_bot/src/services/webhooks/types"
+	"time"
+)
+
+type fakeWebhooksRepository struct {
+	webhooks []models.Webhook
+}
+
+func (repository *fakeWebhooksRepository) Create(ctx context.Context, webhook models.Webhook) (models.Webhook, error) {
+	webhook.ID = int64(len(repository.webhooks) + 1)
+	repository.webhooks = append(repository.webhooks, webhook)
+
+	return webhook, nil
+}
+
+func (repository *fakeWebhooksRepository) GetAllForUser(ctx context.Context, userID int64) ([]models.Webhook, error) {
+	var webhooks []models.Webhook
+	for _, webhook := range repository.webhooks {
+		if webhook.UserID == userID {
+			webhooks = append(webhooks, webhook)
+		}
+	}
+
+	return webhooks, nil
+}
+
+func (repository *fakeWebhooksRepository) DeleteForUser(ctx context.Context, userID, webhookID int64) error {
+	return nil
+}
+
+type fakeWebhookDeliveriesRepository struct {
+	mutex      sync.Mutex
+	webhooks   *fakeWebhooksRepository
+	deliveries []models.WebhookDelivery
+}
+
+func (repository *fakeWebhookDeliveriesRepository) Create(ctx context.Context, delivery models.WebhookDelivery) (models.WebhookDelivery, error) {
+	delivery.ID = int64(len(repository.deliveries) + 1)
+	repository.deliveries = append(repository.deliveries, delivery)
+
+	return delivery, nil
+}
+
+func (repository *fakeWebhookDeliveriesRepository) Update(ctx context.Context, delivery models.WebhookDelivery) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	delivery.Webhook = nil
+	repository.deliveries[delivery.ID-1] = delivery
+
+	return nil
+}
+
+func (repository *fakeWebhookDeliveriesRepository) Claim(
+	ctx context.Context,
+	now time.Time,
+	lease time.Duration,
+	limit uint,
+) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	for i, delivery := range repository.deliveries {
+		if delivery.NextAttemptAt != nil && !delivery.NextAttemptAt.After(now) {
+			leasedUntil := now.Add(lease)
+			repository.deliveries[i].NextAttemptAt = &leasedUntil
+
+			webhook := repository.webhooks.webhooks[delivery.WebhookID-1]
+			delivery.Webhook = &webhook
+			deliveries = append(deliveries, delivery)
+		}
+	}
+
+	return deliveries, nil
+}
+
+// receiver -> local webhook receiver which checks signatures and answers with status
+type receiver struct {
+	mutex    sync.Mutex
+	secret   string
+	status   int
+	received []eventPayload
+	invalid  int
+}
+
+func (receiver *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	receiver.mutex.Lock()
+	defer receiver.mutex.Unlock()
+
+	if r.Header.Get("X-Webhook-Signature") != Signature(receiver.secret, body) {
+		receiver.invalid++
+	}
+
+	var payload eventPayload
+	_ = json.Unmarshal(body, &payload)
+	receiver.received = append(receiver.received, payload)
+
+	w.WriteHeader(receiver.status)
+}
+
+func newTestService(t *testing.T, status int) (*Service, *fakeWebhookDeliveriesRepository, *receiver) {
+	webhooksRepository := &fakeWebhooksRepository{}
+	deliveriesRepository := &fakeWebhookDeliveriesRepository{webhooks: webhooksRepository}
+	service := NewService(zap.NewNop().Sugar(), webhooksRepository, deliveriesRepository, http.DefaultClient)
+
+	testReceiver := &receiver{status: status}
+	server := httptest.NewServer(testReceiver)
+	t.Cleanup(server.Close)
+
+	//Register не принимает локальные адреса, поэтому получатель добавляется напрямую
+	webhook, err := webhooksRepository.Create(context.Background(), models.Webhook{UserID: 1, URL: server.URL, Secret: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testReceiver.secret = webhook.Secret
+
+	return service, deliveriesRepository, testReceiver
+}
+
+func publishTestEvent(t *testing.T, service *Service, now time.Time) {
+	err := service.Publish(context.Background(), services_types.Event{
+		Type:       services_types.EventTaskCreated,
+		UserID:     1,
+		Task:       models.Task{ID: 7, Title: "Chores", UserID: 1},
+		OccurredAt: now,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDeliverDue(t *testing.T) {
+	service, deliveriesRepository, testReceiver := newTestService(t, http.StatusNoContent)
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	publishTestEvent(t, service, now)
+	//Событие другого пользователя не доставляется
+	err := service.Publish(context.Background(), services_types.Event{Type: services_types.EventTaskDeleted, UserID: 2, OccurredAt: now})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = service.DeliverDue(context.Background(), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(testReceiver.received) != 1 || testReceiver.invalid != 0 {
+		t.Fatalf("got %d deliveries with %d invalid signatures, expected 1 valid", len(testReceiver.received), testReceiver.invalid)
+	}
+	payload := testReceiver.received[0]
+	if payload.Event != services_types.EventTaskCreated || payload.Task.ID != 7 || payload.Task.Tags == nil {
+		t.Fatalf("got payload %+v", payload)
+	}
+
+	delivery := deliveriesRepository.deliveries[0]
+	if delivery.DeliveredAt == nil || delivery.NextAttemptAt != nil || delivery.Attempts != 1 {
+		t.Fatalf("got delivery %+v, expected delivered with the first attempt", delivery)
+	}
+
+	//Доставленное событие не отправляется повторно
+	err = service.DeliverDue(context.Background(), now.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(testReceiver.received) != 1 {
+		t.Fatalf("got %d deliveries, expected 1", len(testReceiver.received))
+	}
+}
+
+func TestDeliverDueRetries(t *testing.T) {
+	service, deliveriesRepository, testReceiver := newTestService(t, http.StatusInternalServerError)
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	publishTestEvent(t, service, now)
+
+	for attempt := 1; attempt <= maxDeliveryAttempts; attempt++ {
+		err := service.DeliverDue(context.Background(), now)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		delivery := deliveriesRepository.deliveries[0]
+		if delivery.Attempts != attempt || delivery.DeliveredAt != nil || delivery.LastError == "" {
+			t.Fatalf("attempt %d: got delivery %+v", attempt, delivery)
+		}
+		if attempt == maxDeliveryAttempts {
+			if delivery.NextAttemptAt != nil {
+				t.Fatalf("attempt %d: got next attempt at %s, expected none", attempt, delivery.NextAttemptAt)
+			}
+			break
+		}
+
+		expected := now.Add(retryDelay(attempt))
+		if delivery.NextAttemptAt == nil || !delivery.NextAttemptAt.Equal(expected) {
+			t.Fatalf("attempt %d: got next attempt at %v, expected %s", attempt, delivery.NextAttemptAt, expected)
+		}
+
+		//До следующей попытки доставка не повторяется
+		err = service.DeliverDue(context.Background(), expected.Add(-time.Second))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(testReceiver.received) != attempt {
+			t.Fatalf("attempt %d: got %d requests, expected %d", attempt, len(testReceiver.received), attempt)
+		}
+
+		now = expected
+	}
+
+	err := service.DeliverDue(context.Background(), now.Add(24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(testReceiver.received) != maxDeliveryAttempts {
+		t.Fatalf("got %d requests, expected %d", len(testReceiver.received), maxDeliveryAttempts)
+	}
+}
+
+func TestDeliverDueLimitsConcurrency(t *testing.T) {
+	webhooksRepository := &fakeWebhooksRepository{}
+	deliveriesRepository := &fakeWebhookDeliveriesRepository{webhooks: webhooksRepository}
+	service := NewService(zap.NewNop().Sugar(), webhooksRepository, deliveriesRepository, http.DefaultClient)
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	var inFlight, maxInFlight, received int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if current <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, current) {
+				break
+			}
+		}
+
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&received, 1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	_, err := webhooksRepository.Create(context.Background(), models.Webhook{UserID: 1, URL: server.URL, Secret: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	const eventsCount = 3 * deliveriesConcurrency
+	for i := 0; i < eventsCount; i++ {
+		publishTestEvent(t, service, now)
+	}
+
+	err = service.DeliverDue(context.Background(), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if received != eventsCount {
+		t.Fatalf("got %d deliveries, expected %d", received, eventsCount)
+	}
+	if maxInFlight > deliveriesConcurrency {
+		t.Fatalf("got %d deliveries at once, expected at most %d", maxInFlight, deliveriesConcurrency)
+	}
+	for _, delivery := range deliveriesRepository.deliveries {
+		if delivery.DeliveredAt == nil {
+			t.Fatalf("got delivery %+v, expected delivered", delivery)
+		}
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	testCases := []struct {
+		attempts int
+		expected time.Duration
+	}{
+		{attempts: 1, expected: time.Minute},
+		{attempts: 2, expected: 2 * time.Minute},
+		{attempts: 5, expected: 16 * time.Minute},
+		{attempts: 9, expected: 256 * time.Minute},
+		{attempts: 10, expected: maxRetryDelay},
+		{attempts: 100, expected: maxRetryDelay},
+	}
+
+	for _, testCase := range testCases {
+		delay := retryDelay(testCase.attempts)
+		if delay != testCase.expected {
+			t.Fatalf("%d attempts: got %s, expected %s", testCase.attempts, delay, testCase.expected)
+		}
+	}
+}
+
+func TestRegisterValidatesURL(t *testing.T) {
+	service, _, _ := newTestService(t, http.StatusOK)
+
+	for _, url := range []string{
+		"",
+		"example.com/hook",
+		"ftp://example.com/hook",
+		"http://",
+		"http://127.0.0.1/hook",
+		"http://127.0.0.1:8080/hook",
+		"http://[::1]/hook",
+		"http://10.0.0.1/hook",
+		"http://192.168.1.1/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://0.0.0.0/hook",
+	} {
+		_, err := service.Register(context.Background(), types.RegisterParams{UserID: 1, URL: url})
+		if !errors.Is(err, services_types.ErrInvalidParams) {
+			t.Fatalf("%q: got %v, expected ErrInvalidParams", url, err)
+		}
+	}
+}
+
+func TestHTTPClientRefusesNonPublicAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	//Адрес проверяется при подключении, даже если хост прошёл валидацию при регистрации
+	response, err := NewHTTPClient(time.Second).Post(server.URL, "application/json", nil)
+	if err == nil {
+		response.Body.Close()
+		t.Fatal("connection to 127.0.0.1 succeeded, expected an error")
+	}
+}
+
+func TestHTTPClientDoesNotFollowRedirects(t *testing.T) {
+	client := NewHTTPClient(time.Second)
+	request, err := http.NewRequest(http.MethodPost, "http://example.com/hook", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = client.CheckRedirect(request, []*http.Request{request})
+	if !errors.Is(err, http.ErrUseLastResponse) {
+		t.Fatalf("got %v, expected http.ErrUseLastResponse", err)
+	}
+}
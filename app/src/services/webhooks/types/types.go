@@ -0,0 +1,6 @@
+package types
+
+type RegisterParams struct {
+	UserID int64
+	URL    string
+}
@@ -0,0 +1,66 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"tg_todo_bot/src/services/webhooks/types"
+)
+
+const maxURLLength = 2048
+
+func validateRegisterParams(ctx context.Context, params types.RegisterParams) error {
+	var emptyRequiredFields []string
+
+	if params.UserID == 0 {
+		emptyRequiredFields = append(emptyRequiredFields, "UserID")
+	}
+
+	if params.URL == "" {
+		emptyRequiredFields = append(emptyRequiredFields, "URL")
+	}
+
+	if len(emptyRequiredFields) > 0 {
+		err := fmt.Errorf("some required fields are empty: [%s]", strings.Join(emptyRequiredFields, ", "))
+		return err
+	}
+
+	if len(params.URL) > maxURLLength {
+		err := fmt.Errorf("url must be at most %d characters", maxURLLength)
+		return err
+	}
+
+	webhookURL, err := url.Parse(params.URL)
+	if err != nil || (webhookURL.Scheme != "http" && webhookURL.Scheme != "https") || webhookURL.Host == "" {
+		err = fmt.Errorf("url must be an absolute http or https URL")
+		return err
+	}
+
+	//Адреса проверяются ещё раз при подключении, здесь пользователь сразу узнаёт об ошибке
+	addresses, err := net.DefaultResolver.LookupIPAddr(ctx, webhookURL.Hostname())
+	if err != nil {
+		err = fmt.Errorf("can't resolve the host of the url: %w", err)
+		return err
+	}
+	for _, address := range addresses {
+		if !isPublicIP(address.IP) {
+			err = fmt.Errorf("url must point to a public address, %s isn't", address.IP)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isPublicIP (ip) -> false for loopback, private, link-local, multicast and unspecified addresses
+func isPublicIP(ip net.IP) bool {
+	return !ip.IsLoopback() &&
+		!ip.IsPrivate() &&
+		!ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() &&
+		!ip.IsMulticast() &&
+		!ip.IsUnspecified()
+}